@@ -0,0 +1,231 @@
+// Package alerts evaluates alert rules against polled quotes and snapshots
+// and delivers triggered alerts to a notifier.
+//
+// An alert fires once when its rule's condition becomes true and re-arms
+// when the condition clears. A cooldown additionally suppresses alerts that
+// flap around their threshold.
+//
+// # Basic Usage
+//
+//	engine := alerts.NewEngine(notify.NewWebhook(url, secret), alerts.WithCooldown(time.Hour))
+//	engine.Add(alerts.PriceAbove{Symbol: "AAPL", Price: 250})
+//	engine.Add(alerts.PercentMove{Symbol: "TSLA", Percent: 5})
+//	engine.Add(alerts.PortfolioBelow{Value: 10000})
+//
+//	quotes, err := client.GetQuotes(ctx, engine.Symbols()...)
+//	if err != nil {
+//		return err
+//	}
+//	fired, err := engine.ObserveQuotes(ctx, quotes.Data)
+package alerts
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+	"github.com/adjaecent/unofficial-stockal-api/events"
+	"github.com/adjaecent/unofficial-stockal-api/notify"
+)
+
+// now returns the time observations are recorded at; tests replace it.
+var now = time.Now
+
+// Result is the outcome of evaluating a rule.
+type Result int
+
+const (
+	// Unknown means the state lacks the data the rule needs; the rule's
+	// fired status is left unchanged.
+	Unknown Result = iota
+	// Clear means the rule's condition does not hold.
+	Clear
+	// Triggered means the rule's condition holds.
+	Triggered
+)
+
+// State is the latest market and account data observed by an Engine.
+type State struct {
+	// Time is when the state was last updated
+	Time time.Time
+	// Quotes holds the latest quote for each symbol, keyed by upper-case symbol
+	Quotes map[string]stockal.Quote
+	// Snapshot is the latest account snapshot, or nil if none has been observed
+	Snapshot *stockal.Snapshot
+}
+
+// quote returns the latest quote for symbol.
+func (s State) quote(symbol string) (stockal.Quote, bool) {
+	q, ok := s.Quotes[strings.ToUpper(symbol)]
+	return q, ok
+}
+
+// Rule is an alert condition.
+type Rule interface {
+	// Key identifies the rule for deduplication and cooldowns. Rules with the
+	// same key are treated as the same rule.
+	Key() string
+	// Evaluate checks the rule against s, returning the alert to send if it triggered.
+	Evaluate(s State) (Result, events.Alert)
+}
+
+// symbolRule is implemented by rules that watch a single symbol's quote.
+type symbolRule interface {
+	symbol() string
+}
+
+func (r PriceAbove) symbol() string  { return r.Symbol }
+func (r PriceBelow) symbol() string  { return r.Symbol }
+func (r PercentMove) symbol() string { return r.Symbol }
+
+// Option configures an Engine.
+type Option func(*Engine)
+
+// WithCooldown sets the minimum time between two alerts from the same rule,
+// even if its condition clears and triggers again in between.
+func WithCooldown(d time.Duration) Option {
+	return func(e *Engine) {
+		e.cooldown = d
+	}
+}
+
+// WithAccount names the account the alerts concern in the events delivered
+// to the notifier.
+func WithAccount(name string) Option {
+	return func(e *Engine) {
+		e.account = name
+	}
+}
+
+// Engine evaluates rules and notifies when they trigger. It is safe for
+// concurrent use.
+type Engine struct {
+	notifier notify.Notifier
+	account  string
+	cooldown time.Duration
+
+	mu     sync.Mutex
+	rules  []Rule
+	state  State
+	status map[string]*ruleStatus
+}
+
+type ruleStatus struct {
+	fired     bool
+	lastFired time.Time
+}
+
+// NewEngine creates an Engine delivering alerts to n, which may be nil to only
+// collect them.
+func NewEngine(n notify.Notifier, opts ...Option) *Engine {
+	e := &Engine{
+		notifier: n,
+		state:    State{Quotes: map[string]stockal.Quote{}},
+		status:   map[string]*ruleStatus{},
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
+
+// Add registers a rule. Adding a rule whose key is already registered has no effect.
+func (e *Engine) Add(r Rule) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if _, ok := e.status[r.Key()]; ok {
+		return
+	}
+	e.rules = append(e.rules, r)
+	e.status[r.Key()] = &ruleStatus{}
+}
+
+// Remove unregisters the rule with the given key.
+func (e *Engine) Remove(key string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for i, r := range e.rules {
+		if r.Key() == key {
+			e.rules = append(e.rules[:i], e.rules[i+1:]...)
+			delete(e.status, key)
+			return
+		}
+	}
+}
+
+// Symbols returns the symbols whose quotes the registered rules need.
+func (e *Engine) Symbols() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	var symbols []string
+	seen := map[string]bool{}
+	for _, r := range e.rules {
+		if sr, ok := r.(symbolRule); ok {
+			if s := strings.ToUpper(sr.symbol()); !seen[s] {
+				seen[s] = true
+				symbols = append(symbols, s)
+			}
+		}
+	}
+	return symbols
+}
+
+// ObserveQuotes records quotes and evaluates the rules, returning the alerts
+// that fired. Notifier errors are returned after every alert has been attempted.
+func (e *Engine) ObserveQuotes(ctx context.Context, quotes []stockal.Quote) ([]events.Alert, error) {
+	e.mu.Lock()
+	for _, q := range quotes {
+		e.state.Quotes[strings.ToUpper(q.Symbol)] = q
+	}
+	e.state.Time = now()
+	fired := e.evaluate()
+	e.mu.Unlock()
+	return fired, e.deliver(ctx, fired)
+}
+
+// ObserveSnapshot records an account snapshot and evaluates the rules like ObserveQuotes.
+func (e *Engine) ObserveSnapshot(ctx context.Context, s *stockal.Snapshot) ([]events.Alert, error) {
+	e.mu.Lock()
+	e.state.Snapshot = s
+	e.state.Time = now()
+	fired := e.evaluate()
+	e.mu.Unlock()
+	return fired, e.deliver(ctx, fired)
+}
+
+// evaluate checks every rule against the current state. e.mu must be held.
+func (e *Engine) evaluate() []events.Alert {
+	var fired []events.Alert
+	for _, r := range e.rules {
+		st := e.status[r.Key()]
+		result, alert := r.Evaluate(e.state)
+		switch result {
+		case Clear:
+			st.fired = false
+		case Triggered:
+			if st.fired || e.state.Time.Sub(st.lastFired) < e.cooldown {
+				continue
+			}
+			st.fired = true
+			st.lastFired = e.state.Time
+			fired = append(fired, alert)
+		}
+	}
+	return fired
+}
+
+func (e *Engine) deliver(ctx context.Context, alerts []events.Alert) error {
+	if e.notifier == nil {
+		return nil
+	}
+	var errs []error
+	for _, a := range alerts {
+		if err := e.notifier.Notify(ctx, events.NewAlertEvent(e.account, a)); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,158 @@
+package alerts
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+	"github.com/adjaecent/unofficial-stockal-api/events"
+)
+
+// clock replaces now with a clock that starts at a fixed time and only moves
+// when advanced.
+func clock(t *testing.T) func(time.Duration) {
+	at := time.Date(2024, time.May, 1, 14, 30, 0, 0, time.UTC)
+	now = func() time.Time { return at }
+	t.Cleanup(func() { now = time.Now })
+	return func(d time.Duration) { at = at.Add(d) }
+}
+
+// recorder is a notifier that records the events it receives.
+type recorder struct {
+	events []events.Event
+	err    error
+}
+
+func (r *recorder) Notify(ctx context.Context, e events.Event) error {
+	r.events = append(r.events, e)
+	return r.err
+}
+
+func quote(symbol string, price, priorClose float64) []stockal.Quote {
+	return []stockal.Quote{{Symbol: symbol, Price: price, PriorClose: priorClose}}
+}
+
+// observe feeds the engine one quote per price, a minute apart, and returns
+// how many alerts fired after each.
+func observe(t *testing.T, e *Engine, advance func(time.Duration), prices ...float64) []int {
+	t.Helper()
+	var fired []int
+	for _, p := range prices {
+		advance(time.Minute)
+		alerts, err := e.ObserveQuotes(context.Background(), quote("AAPL", p, 190))
+		if err != nil {
+			t.Fatalf("ObserveQuotes: %v", err)
+		}
+		fired = append(fired, len(alerts))
+	}
+	return fired
+}
+
+func TestEngineFlapping(t *testing.T) {
+	advance := clock(t)
+	e := NewEngine(nil)
+	e.Add(PriceAbove{Symbol: "AAPL", Price: 200})
+
+	// Fires once on crossing, stays quiet while above, and re-arms on
+	// clearing, so every crossing fires without a cooldown
+	got := observe(t, e, advance, 199, 201, 202, 200, 199.99, 200.5, 199, 201)
+	if want := []int{0, 1, 0, 0, 0, 1, 0, 1}; !slices.Equal(got, want) {
+		t.Errorf("fired %v, want %v", got, want)
+	}
+}
+
+func TestEngineCooldown(t *testing.T) {
+	advance := clock(t)
+	e := NewEngine(nil, WithCooldown(10*time.Minute))
+	e.Add(PriceAbove{Symbol: "AAPL", Price: 200})
+
+	// The second crossing falls within the cooldown and is suppressed; the
+	// condition still holds once the cooldown ends, so it fires then
+	got := observe(t, e, advance, 201, 199, 201, 202, 203, 204, 205, 206, 207, 208, 209, 210)
+	if want := []int{1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1, 0}; !slices.Equal(got, want) {
+		t.Errorf("fired %v, want %v", got, want)
+	}
+}
+
+func TestEngineUnknown(t *testing.T) {
+	clock(t)
+	e := NewEngine(nil)
+	e.Add(PercentMove{Symbol: "AAPL", Percent: 5})
+	e.Add(PortfolioBelow{Value: 1000})
+	ctx := context.Background()
+
+	// Without a snapshot the portfolio rule is unknown and does not fire
+	fired, err := e.ObserveQuotes(ctx, quote("AAPL", 205, 200))
+	if err != nil || len(fired) != 0 {
+		t.Fatalf("ObserveQuotes = %+v, %v; want nothing fired", fired, err)
+	}
+	if fired, _ = e.ObserveQuotes(ctx, quote("AAPL", 220, 200)); len(fired) != 1 {
+		t.Fatalf("fired %+v, want the move", fired)
+	}
+
+	// A quote without a prior close leaves the fired move as it was, so it
+	// does not fire again once the prior close is back
+	if fired, _ = e.ObserveQuotes(ctx, quote("AAPL", 200, 0)); len(fired) != 0 {
+		t.Errorf("fired %+v on an unknown move", fired)
+	}
+	if fired, _ = e.ObserveQuotes(ctx, quote("AAPL", 221, 200)); len(fired) != 0 {
+		t.Errorf("fired %+v again after an unknown move", fired)
+	}
+	if fired, _ = e.ObserveQuotes(ctx, quote("AAPL", 200, 200)); len(fired) != 0 {
+		t.Errorf("fired %+v on clearing", fired)
+	}
+	if fired, _ = e.ObserveQuotes(ctx, quote("AAPL", 190, 200)); len(fired) != 1 {
+		t.Errorf("fired %+v, want the move again after clearing", fired)
+	}
+}
+
+func TestEngineAddDuplicate(t *testing.T) {
+	clock(t)
+	e := NewEngine(nil)
+	e.Add(PriceAbove{Symbol: "AAPL", Price: 200})
+	e.Add(PriceAbove{Symbol: "aapl", Price: 200})
+	e.Add(PriceBelow{Symbol: "AAPL", Price: 150})
+
+	if got := e.Symbols(); !slices.Equal(got, []string{"AAPL"}) {
+		t.Errorf("Symbols = %v, want [AAPL]", got)
+	}
+	fired, err := e.ObserveQuotes(context.Background(), quote("AAPL", 201, 190))
+	if err != nil || len(fired) != 1 {
+		t.Fatalf("ObserveQuotes = %+v, %v; want one alert", fired, err)
+	}
+
+	// Adding the rule again keeps its fired status; removing it forgets it
+	e.Add(PriceAbove{Symbol: "AAPL", Price: 200})
+	if fired, _ = e.ObserveQuotes(context.Background(), quote("AAPL", 202, 190)); len(fired) != 0 {
+		t.Errorf("fired %+v after adding a duplicate", fired)
+	}
+	e.Remove(PriceAbove{Symbol: "AAPL", Price: 200}.Key())
+	e.Add(PriceAbove{Symbol: "AAPL", Price: 200})
+	if fired, _ = e.ObserveQuotes(context.Background(), quote("AAPL", 203, 190)); len(fired) != 1 {
+		t.Errorf("fired %+v after removing and adding the rule, want one alert", fired)
+	}
+}
+
+func TestEngineNotify(t *testing.T) {
+	clock(t)
+	n := &recorder{err: errors.New("unavailable")}
+	e := NewEngine(n, WithAccount("personal"))
+	e.Add(PriceAbove{Symbol: "AAPL", Price: 200})
+	e.Add(PriceAbove{Symbol: "AAPL", Price: 180})
+
+	fired, err := e.ObserveQuotes(context.Background(), quote("AAPL", 201, 190))
+	if len(fired) != 2 || err == nil {
+		t.Fatalf("ObserveQuotes = %+v, %v; want both alerts and the notifier's error", fired, err)
+	}
+	if len(n.events) != 2 {
+		t.Fatalf("notified %d events, want every alert attempted", len(n.events))
+	}
+	for _, ev := range n.events {
+		if ev.Type != events.TypeAlertTriggered || ev.Account != "personal" || ev.Key != "AAPL" {
+			t.Errorf("notified %+v, want an AAPL alert for personal", ev)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package alerts
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+	"github.com/adjaecent/unofficial-stockal-api/events"
+)
+
+// minTradeAmount is the smallest rebalancing trade worth suggesting, in dollars.
+const minTradeAmount = 1
+
+// AllocationDrift triggers when the portfolio's allocation leaves its
+// configured bands. The alert lists the market orders, by dollar amount, that
+// would bring it back to target.
+//
+//	alerts.AllocationDrift{
+//		MaxHoldingPercent: 15,
+//		CategoryTargets:   map[string]float64{"stock": 70, "etf": 30},
+//		Band:              5,
+//	}
+//
+// Weights are computed over the market value of holdings; cash is ignored.
+type AllocationDrift struct {
+	// MaxHoldingPercent is the largest share of the portfolio any single
+	// holding may have, in percent (0 disables the check)
+	MaxHoldingPercent float64
+	// CategoryTargets maps holding categories (e.g., "stock", "etf") to their
+	// target share of the portfolio, in percent
+	CategoryTargets map[string]float64
+	// Band is how far, in percentage points, a category may drift from its
+	// target before the rule triggers
+	Band float64
+}
+
+// Key implements Rule.
+func (r AllocationDrift) Key() string {
+	var targets []string
+	for c, t := range r.CategoryTargets {
+		targets = append(targets, fmt.Sprintf("%s=%g", c, t))
+	}
+	slices.Sort(targets)
+	return fmt.Sprintf("allocation max %g%% targets %s band %g", r.MaxHoldingPercent, strings.Join(targets, ","), r.Band)
+}
+
+// Evaluate implements Rule.
+func (r AllocationDrift) Evaluate(s State) (Result, events.Alert) {
+	if s.Snapshot == nil {
+		return Unknown, events.Alert{}
+	}
+
+	var total float64
+	values := map[string]float64{}
+	categories := map[string]float64{}
+	categoryOf := map[string]string{}
+	for _, h := range s.Snapshot.Holdings {
+		v := h.CurrentValue()
+		total += v
+		values[h.Symbol] += v
+		categories[string(h.Category)] += v
+		categoryOf[h.Symbol] = string(h.Category)
+	}
+	if total <= 0 {
+		return Unknown, events.Alert{}
+	}
+
+	var problems []string
+	var worst float64
+	adjust := map[string]float64{}
+
+	// Move each drifted category back to its target, spreading the change
+	// across its holdings in proportion to their value.
+	for _, c := range sortedKeys(r.CategoryTargets) {
+		target := r.CategoryTargets[c]
+		weight := categories[c] / total * 100
+		drift := weight - target
+		if math.Abs(drift) <= r.Band {
+			continue
+		}
+		worst = max(worst, math.Abs(drift))
+		problems = append(problems, fmt.Sprintf("%s is %.1f%% of the portfolio (target %g%% ±%g)", c, weight, target, r.Band))
+
+		delta := target*total/100 - categories[c]
+		if categories[c] == 0 {
+			problems = append(problems, fmt.Sprintf("no %s holdings to buy", c))
+			continue
+		}
+		for symbol, v := range values {
+			if categoryOf[symbol] == c {
+				adjust[symbol] += delta * v / categories[c]
+			}
+		}
+	}
+
+	// Trim concentrated holdings down to the limit.
+	if r.MaxHoldingPercent > 0 {
+		limit := r.MaxHoldingPercent * total / 100
+		for _, symbol := range sortedKeys(values) {
+			if weight := values[symbol] / total * 100; weight > r.MaxHoldingPercent {
+				worst = max(worst, weight-r.MaxHoldingPercent)
+				problems = append(problems, fmt.Sprintf("%s is %.1f%% of the portfolio (max %g%%)", symbol, weight, r.MaxHoldingPercent))
+			}
+			if after := values[symbol] + adjust[symbol]; after > limit {
+				adjust[symbol] -= after - limit
+			}
+		}
+	}
+
+	if len(problems) == 0 {
+		return Clear, events.Alert{}
+	}
+
+	trades := rebalanceTrades(adjust)
+	message := "Allocation drift: " + strings.Join(problems, "; ")
+	if len(trades) > 0 {
+		parts := make([]string, len(trades))
+		for i, t := range trades {
+			parts[i] = fmt.Sprintf("%s $%.2f %s", t.Side, t.Amount, t.Symbol)
+		}
+		message += ". Rebalance: " + strings.Join(parts, ", ")
+	}
+	return Triggered, events.Alert{
+		Condition: "allocation drift",
+		Threshold: worst,
+		Price:     total,
+		Message:   message,
+		Trades:    trades,
+	}
+}
+
+// rebalanceTrades turns per-symbol dollar adjustments into market orders,
+// sells first and largest first.
+func rebalanceTrades(adjust map[string]float64) []stockal.OrderRequest {
+	var trades []stockal.OrderRequest
+	for symbol, delta := range adjust {
+		if math.Abs(delta) < minTradeAmount {
+			continue
+		}
+		t := stockal.OrderRequest{Symbol: symbol, Side: stockal.OrderSideBuy, Type: stockal.OrderTypeMarket, Amount: math.Round(delta*100) / 100}
+		if delta < 0 {
+			t.Side, t.Amount = stockal.OrderSideSell, -t.Amount
+		}
+		trades = append(trades, t)
+	}
+	slices.SortFunc(trades, func(a, b stockal.OrderRequest) int {
+		if a.Side != b.Side {
+			return cmp.Compare(b.Side, a.Side) // "sell" before "buy"
+		}
+		return cmp.Or(cmp.Compare(b.Amount, a.Amount), cmp.Compare(a.Symbol, b.Symbol))
+	})
+	return trades
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
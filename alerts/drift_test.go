@@ -0,0 +1,91 @@
+package alerts
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+)
+
+func holding(symbol string, category stockal.Category, value float64) stockal.Holding {
+	return stockal.Holding{Symbol: symbol, Category: category, TotalUnit: value / 100, Price: 100}
+}
+
+func TestAllocationDrift(t *testing.T) {
+	targets := map[string]float64{"stock": 70, "etf": 30}
+	tests := []struct {
+		name     string
+		rule     AllocationDrift
+		holdings []stockal.Holding
+		result   Result
+		trades   []stockal.OrderRequest
+		message  string
+	}{
+		{
+			name:     "within bands",
+			rule:     AllocationDrift{CategoryTargets: targets, Band: 5},
+			holdings: []stockal.Holding{holding("AAPL", stockal.CategoryStock, 740), holding("VOO", stockal.CategoryETF, 260)},
+			result:   Clear,
+		},
+		{
+			name: "category drift",
+			rule: AllocationDrift{CategoryTargets: targets, Band: 5},
+			holdings: []stockal.Holding{
+				holding("AAPL", stockal.CategoryStock, 600), holding("MSFT", stockal.CategoryStock, 400),
+				holding("VOO", stockal.CategoryETF, 1000),
+			},
+			result: Triggered,
+			trades: []stockal.OrderRequest{
+				{Symbol: "VOO", Side: stockal.OrderSideSell, Type: stockal.OrderTypeMarket, Amount: 400},
+				{Symbol: "AAPL", Side: stockal.OrderSideBuy, Type: stockal.OrderTypeMarket, Amount: 240},
+				{Symbol: "MSFT", Side: stockal.OrderSideBuy, Type: stockal.OrderTypeMarket, Amount: 160},
+			},
+			message: "etf is 50.0% of the portfolio (target 30% ±5)",
+		},
+		{
+			name: "concentrated holding",
+			rule: AllocationDrift{MaxHoldingPercent: 50},
+			holdings: []stockal.Holding{
+				holding("AAPL", stockal.CategoryStock, 3000), holding("MSFT", stockal.CategoryStock, 500),
+				holding("GOOG", stockal.CategoryStock, 500),
+			},
+			result:  Triggered,
+			trades:  []stockal.OrderRequest{{Symbol: "AAPL", Side: stockal.OrderSideSell, Type: stockal.OrderTypeMarket, Amount: 1000}},
+			message: "AAPL is 75.0% of the portfolio (max 50%)",
+		},
+		{
+			name:     "missing category",
+			rule:     AllocationDrift{CategoryTargets: targets, Band: 5},
+			holdings: []stockal.Holding{holding("AAPL", stockal.CategoryStock, 1000)},
+			result:   Triggered,
+			trades:   []stockal.OrderRequest{{Symbol: "AAPL", Side: stockal.OrderSideSell, Type: stockal.OrderTypeMarket, Amount: 300}},
+			message:  "no etf holdings to buy",
+		},
+		{
+			name:   "no holdings",
+			rule:   AllocationDrift{CategoryTargets: targets, Band: 5},
+			result: Unknown,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, alert := tt.rule.Evaluate(State{Snapshot: &stockal.Snapshot{Holdings: tt.holdings}})
+			if result != tt.result {
+				t.Fatalf("Evaluate = %v, %+v; want %v", result, alert, tt.result)
+			}
+			if !slices.Equal(alert.Trades, tt.trades) {
+				t.Errorf("trades %+v, want %+v", alert.Trades, tt.trades)
+			}
+			if !strings.Contains(alert.Message, tt.message) {
+				t.Errorf("message %q, want it to mention %q", alert.Message, tt.message)
+			}
+		})
+	}
+}
+
+func TestAllocationDriftWithoutSnapshot(t *testing.T) {
+	if result, _ := (AllocationDrift{MaxHoldingPercent: 20}).Evaluate(State{}); result != Unknown {
+		t.Errorf("Evaluate = %v, want Unknown", result)
+	}
+}
@@ -0,0 +1,148 @@
+package alerts
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/adjaecent/unofficial-stockal-api/events"
+)
+
+// PriceAbove triggers when a symbol trades at or above Price.
+type PriceAbove struct {
+	Symbol string
+	Price  float64
+}
+
+// Key implements Rule.
+func (r PriceAbove) Key() string {
+	return fmt.Sprintf("%s above %g", strings.ToUpper(r.Symbol), r.Price)
+}
+
+// Evaluate implements Rule.
+func (r PriceAbove) Evaluate(s State) (Result, events.Alert) {
+	q, ok := s.quote(r.Symbol)
+	if !ok {
+		return Unknown, events.Alert{}
+	}
+	if q.Price < r.Price {
+		return Clear, events.Alert{}
+	}
+	return Triggered, events.Alert{
+		Symbol:    q.Symbol,
+		Condition: "above",
+		Threshold: r.Price,
+		Price:     q.Price,
+		Message:   fmt.Sprintf("%s is $%.2f, at or above $%.2f", q.Symbol, q.Price, r.Price),
+	}
+}
+
+// PriceBelow triggers when a symbol trades at or below Price.
+type PriceBelow struct {
+	Symbol string
+	Price  float64
+}
+
+// Key implements Rule.
+func (r PriceBelow) Key() string {
+	return fmt.Sprintf("%s below %g", strings.ToUpper(r.Symbol), r.Price)
+}
+
+// Evaluate implements Rule.
+func (r PriceBelow) Evaluate(s State) (Result, events.Alert) {
+	q, ok := s.quote(r.Symbol)
+	if !ok {
+		return Unknown, events.Alert{}
+	}
+	if q.Price > r.Price {
+		return Clear, events.Alert{}
+	}
+	return Triggered, events.Alert{
+		Symbol:    q.Symbol,
+		Condition: "below",
+		Threshold: r.Price,
+		Price:     q.Price,
+		Message:   fmt.Sprintf("%s is $%.2f, at or below $%.2f", q.Symbol, q.Price, r.Price),
+	}
+}
+
+// PercentMove triggers when a symbol has moved by at least Percent (in either
+// direction) since the prior close.
+type PercentMove struct {
+	Symbol  string
+	Percent float64
+}
+
+// Key implements Rule.
+func (r PercentMove) Key() string {
+	return fmt.Sprintf("%s moves %g%%", strings.ToUpper(r.Symbol), r.Percent)
+}
+
+// Evaluate implements Rule.
+func (r PercentMove) Evaluate(s State) (Result, events.Alert) {
+	q, ok := s.quote(r.Symbol)
+	if !ok || q.PriorClose == 0 {
+		return Unknown, events.Alert{}
+	}
+	move := (q.Price - q.PriorClose) / q.PriorClose * 100
+	if math.Abs(move) < r.Percent {
+		return Clear, events.Alert{}
+	}
+	return Triggered, events.Alert{
+		Symbol:    q.Symbol,
+		Condition: "move",
+		Threshold: r.Percent,
+		Price:     q.Price,
+		Message:   fmt.Sprintf("%s moved %+.2f%% to $%.2f", q.Symbol, move, q.Price),
+	}
+}
+
+// PortfolioAbove triggers when the total portfolio value is at or above Value.
+type PortfolioAbove struct {
+	Value float64
+}
+
+// Key implements Rule.
+func (r PortfolioAbove) Key() string { return fmt.Sprintf("portfolio above %g", r.Value) }
+
+// Evaluate implements Rule.
+func (r PortfolioAbove) Evaluate(s State) (Result, events.Alert) {
+	if s.Snapshot == nil {
+		return Unknown, events.Alert{}
+	}
+	value := s.Snapshot.Summary.PortfolioSummary.TotalCurrentValue
+	if value < r.Value {
+		return Clear, events.Alert{}
+	}
+	return Triggered, events.Alert{
+		Condition: "portfolio above",
+		Threshold: r.Value,
+		Price:     value,
+		Message:   fmt.Sprintf("Portfolio value is $%.2f, at or above $%.2f", value, r.Value),
+	}
+}
+
+// PortfolioBelow triggers when the total portfolio value is at or below Value.
+type PortfolioBelow struct {
+	Value float64
+}
+
+// Key implements Rule.
+func (r PortfolioBelow) Key() string { return fmt.Sprintf("portfolio below %g", r.Value) }
+
+// Evaluate implements Rule.
+func (r PortfolioBelow) Evaluate(s State) (Result, events.Alert) {
+	if s.Snapshot == nil {
+		return Unknown, events.Alert{}
+	}
+	value := s.Snapshot.Summary.PortfolioSummary.TotalCurrentValue
+	if value > r.Value {
+		return Clear, events.Alert{}
+	}
+	return Triggered, events.Alert{
+		Condition: "portfolio below",
+		Threshold: r.Value,
+		Price:     value,
+		Message:   fmt.Sprintf("Portfolio value is $%.2f, at or below $%.2f", value, r.Value),
+	}
+}
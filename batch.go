@@ -0,0 +1,236 @@
+package stockal
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// orderCSVColumns maps accepted CSV header names to OrderRequest fields.
+var orderCSVColumns = map[string]string{
+	"symbol":      "symbol",
+	"side":        "side",
+	"type":        "type",
+	"quantity":    "quantity",
+	"qty":         "quantity",
+	"amount":      "amount",
+	"limit":       "limit",
+	"limitprice":  "limit",
+	"limit_price": "limit",
+}
+
+// ReadOrderCSV reads order requests from CSV with a header row.
+//
+// The columns may appear in any order and are matched case-insensitively:
+// symbol and side are required; quantity (or qty) and amount give the size;
+// limit (or limitPrice) makes a limit order; type may be given explicitly and
+// defaults to market, or limit when a limit price is set. Lines starting
+// with # are ignored. Every order is validated, and errors name the offending
+// line.
+//
+//	symbol,side,qty,amount,limit
+//	VOO,buy,,500,
+//	AAPL,buy,2,,180
+func ReadOrderCSV(r io.Reader) ([]OrderRequest, error) {
+	cr := csv.NewReader(r)
+	cr.Comment = '#'
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%w: CSV is empty", ErrInvalidOrder)
+	}
+	if err != nil {
+		return nil, err
+	}
+	index := map[string]int{}
+	for i, name := range header {
+		field, ok := orderCSVColumns[strings.ToLower(strings.TrimSpace(name))]
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown CSV column %q", ErrInvalidOrder, name)
+		}
+		index[field] = i
+	}
+	for _, required := range []string{"symbol", "side"} {
+		if _, ok := index[required]; !ok {
+			return nil, fmt.Errorf("%w: CSV has no %s column", ErrInvalidOrder, required)
+		}
+	}
+
+	var orders []OrderRequest
+	for {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := cr.FieldPos(0)
+		field := func(name string) string {
+			if i, ok := index[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		number := func(name string) (float64, error) {
+			s := strings.TrimPrefix(field(name), "$")
+			if s == "" {
+				return 0, nil
+			}
+			v, err := strconv.ParseFloat(s, 64)
+			if err != nil {
+				return 0, fmt.Errorf("%w: line %d: invalid %s %q", ErrInvalidOrder, line, name, field(name))
+			}
+			return v, nil
+		}
+
+		order := OrderRequest{
+			Symbol: strings.ToUpper(field("symbol")),
+			Side:   OrderSide(strings.ToLower(field("side"))),
+			Type:   OrderType(strings.ToLower(field("type"))),
+		}
+		if order.Quantity, err = number("quantity"); err != nil {
+			return nil, err
+		}
+		if order.Amount, err = number("amount"); err != nil {
+			return nil, err
+		}
+		if order.LimitPrice, err = number("limit"); err != nil {
+			return nil, err
+		}
+		if order.Type == "" {
+			order.Type = OrderTypeMarket
+			if order.LimitPrice > 0 {
+				order.Type = OrderTypeLimit
+			}
+		}
+		if err := order.Validate(); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		orders = append(orders, order)
+	}
+	if len(orders) == 0 {
+		return nil, fmt.Errorf("%w: CSV has no orders", ErrInvalidOrder)
+	}
+	return orders, nil
+}
+
+// BatchError is returned by PlaceOrders when an order in the batch fails.
+// The orders before it were placed; the ones after it were not attempted.
+type BatchError struct {
+	// Index is the position of the failed order in the batch
+	Index int
+	// Order is the request that failed
+	Order OrderRequest
+	// Err is the error from placing the order
+	Err error
+}
+
+func (e *BatchError) Error() string {
+	return fmt.Sprintf("order %d (%s %s): %v", e.Index+1, e.Order.Side, e.Order.Symbol, e.Err)
+}
+
+func (e *BatchError) Unwrap() error {
+	return e.Err
+}
+
+// PartialError is returned by batch operations that stopped part way because
+// their context was cancelled or its deadline passed, along with the results
+// of the parts that completed, so that callers can resume with the rest
+// rather than start over.
+type PartialError struct {
+	// Operation names the batch operation, e.g. "place orders"
+	Operation string
+	// Completed lists the parts that completed, e.g. orders or symbols
+	Completed []string
+	// Remaining lists the parts that did not complete, when they are known.
+	// A part in progress when the operation stopped may have taken effect
+	// regardless, as an order may have reached the exchange.
+	Remaining []string
+	// Err is the context's error
+	Err error
+}
+
+func (e *PartialError) Error() string {
+	if e.Remaining == nil {
+		return fmt.Sprintf("%s stopped after %d parts: %v", e.Operation, len(e.Completed), e.Err)
+	}
+	return fmt.Sprintf("%s stopped after %d of %d parts: %v",
+		e.Operation, len(e.Completed), len(e.Completed)+len(e.Remaining), e.Err)
+}
+
+func (e *PartialError) Unwrap() error {
+	return e.Err
+}
+
+// stopped returns a *PartialError if ctx is done, or nil.
+func stopped(ctx context.Context, operation string, completed, remaining []string) error {
+	if ctx.Err() == nil {
+		return nil
+	}
+	return &PartialError{Operation: operation, Completed: completed, Remaining: remaining, Err: context.Cause(ctx)}
+}
+
+// PlaceOrders validates every order, then places them one at a time in
+// order, stopping at the first failure. It returns the orders placed so far
+// and, on failure, a *BatchError. If ctx is cancelled, it stops before the
+// next order and returns a *PartialError naming the orders placed and not.
+func PlaceOrders(ctx context.Context, trader Trader, orders []OrderRequest) ([]Order, error) {
+	for i, order := range orders {
+		if err := order.Validate(); err != nil {
+			return nil, &BatchError{Index: i, Order: order, Err: err}
+		}
+	}
+
+	names := make([]string, len(orders))
+	for i, order := range orders {
+		names[i] = fmt.Sprintf("order %d (%s %s)", i+1, order.Side, order.Symbol)
+	}
+	placed := make([]Order, 0, len(orders))
+	for i, order := range orders {
+		if err := stopped(ctx, "place orders", names[:i], names[i:]); err != nil {
+			return placed, err
+		}
+		resp, err := trader.Place(ctx, order)
+		if err != nil {
+			if err := stopped(ctx, "place orders", names[:i], names[i:]); err != nil {
+				return placed, err
+			}
+			return placed, &BatchError{Index: i, Order: order, Err: err}
+		}
+		placed = append(placed, resp.Data)
+	}
+	return placed, nil
+}
+
+// GetQuotesInBatches retrieves quotes for any number of symbols with
+// GetQuotes, batchSize symbols at a time, so that long watchlists do not make
+// overlong requests. It stops at the first failure, returning the quotes
+// retrieved so far; if ctx is cancelled, the error is a *PartialError naming
+// the symbols retrieved and not.
+func GetQuotesInBatches(ctx context.Context, md MarketData, batchSize int, symbols ...string) ([]Quote, error) {
+	if batchSize <= 0 {
+		return nil, fmt.Errorf("batch size must be positive, got %d", batchSize)
+	}
+	quotes := []Quote{}
+	for start := 0; start < len(symbols); start += batchSize {
+		if err := stopped(ctx, "get quotes", symbols[:start], symbols[start:]); err != nil {
+			return quotes, err
+		}
+		resp, err := md.GetQuotes(ctx, symbols[start:min(start+batchSize, len(symbols))]...)
+		if err != nil {
+			if err := stopped(ctx, "get quotes", symbols[:start], symbols[start:]); err != nil {
+				return quotes, err
+			}
+			return quotes, err
+		}
+		quotes = append(quotes, resp.Data...)
+	}
+	return quotes, nil
+}
@@ -0,0 +1,92 @@
+package stockal_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+)
+
+// newCancellingClient returns a logged-in client for a server that answers
+// the first request with body, then cancels the returned context while the
+// second is in flight.
+func newCancellingClient(t *testing.T, body func(*http.Request) string) (stockal.StockalClient, context.Context) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	var served atomic.Int32
+	client := newHandlerClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if served.Add(1) > 1 {
+			cancel()
+			<-ctx.Done()
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(body(r)))
+	})
+	return client, ctx
+}
+
+func TestPlaceOrdersCancelled(t *testing.T) {
+	client, ctx := newCancellingClient(t, func(*http.Request) string {
+		return `{"code":200,"message":"Success","data":{"orderID":"o1","symbol":"AAPL"}}`
+	})
+	orders := []stockal.OrderRequest{
+		{Symbol: "AAPL", Side: stockal.OrderSideBuy, Type: stockal.OrderTypeMarket, Quantity: 1},
+		{Symbol: "VOO", Side: stockal.OrderSideBuy, Type: stockal.OrderTypeMarket, Quantity: 1},
+		{Symbol: "QQQ", Side: stockal.OrderSideSell, Type: stockal.OrderTypeMarket, Quantity: 1},
+	}
+
+	placed, err := stockal.PlaceOrders(ctx, client.Orders(), orders)
+	var partial *stockal.PartialError
+	if !errors.As(err, &partial) || !errors.Is(err, context.Canceled) {
+		t.Fatalf("PlaceOrders error = %v, want a PartialError wrapping context.Canceled", err)
+	}
+	if len(placed) != 1 || placed[0].ID != "o1" {
+		t.Errorf("placed %+v, want the first order", placed)
+	}
+	if len(partial.Completed) != 1 || len(partial.Remaining) != 2 || !strings.Contains(partial.Remaining[0], "VOO") {
+		t.Errorf("got completed %q, remaining %q", partial.Completed, partial.Remaining)
+	}
+}
+
+func TestGetQuotesInBatches(t *testing.T) {
+	var requests []string
+	quotes := func(r *http.Request) string {
+		symbols := r.URL.Query().Get("symbols")
+		requests = append(requests, symbols)
+		var data []string
+		for _, symbol := range strings.Split(symbols, ",") {
+			data = append(data, `{"symbol":"`+symbol+`","price":1}`)
+		}
+		return `{"code":200,"message":"Success","data":[` + strings.Join(data, ",") + `]}`
+	}
+	client := newHandlerClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(quotes(r)))
+	})
+
+	got, err := stockal.GetQuotesInBatches(context.Background(), client, 2, "AAPL", "VOO", "QQQ")
+	if err != nil {
+		t.Fatalf("GetQuotesInBatches: %v", err)
+	}
+	if len(got) != 3 || !slices.Equal(requests, []string{"AAPL,VOO", "QQQ"}) {
+		t.Errorf("got %d quotes from requests %q", len(got), requests)
+	}
+
+	requests = nil
+	cancelling, ctx := newCancellingClient(t, quotes)
+	got, err = stockal.GetQuotesInBatches(ctx, cancelling, 2, "AAPL", "VOO", "QQQ")
+	var partial *stockal.PartialError
+	if !errors.As(err, &partial) {
+		t.Fatalf("GetQuotesInBatches error = %v, want a PartialError", err)
+	}
+	if len(got) != 2 || !slices.Equal(partial.Completed, []string{"AAPL", "VOO"}) || !slices.Equal(partial.Remaining, []string{"QQQ"}) {
+		t.Errorf("got %d quotes, completed %q, remaining %q", len(got), partial.Completed, partial.Remaining)
+	}
+}
@@ -0,0 +1,7 @@
+//go:build js && wasm
+
+package stockal
+
+// inBrowser reports whether requests go through the browser's fetch API,
+// which sets Origin, Referer, User-Agent and the Sec-Fetch headers itself.
+const inBrowser = true
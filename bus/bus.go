@@ -0,0 +1,181 @@
+// Package bus connects the producers of account events, such as pollers,
+// the alert engine and order trackers, to any number of subscribers, so that
+// each producer publishes an event once without knowing where it goes:
+//
+//	b := bus.New(bus.WithErrorHandler(func(subscriber string, e events.Event, err error) {
+//		log.Printf("%s: %s: %v", subscriber, e.Type, err)
+//	}))
+//	b.SubscribeSink("kafka", kafka.New("http://localhost:8082"))
+//	b.SubscribeNotifier("telegram", notify.NewTelegram(token, chatID))
+//	b.Subscribe("log", bus.Log(log.Default()))
+//
+//	engine := alerts.NewEngine(b, alerts.WithAccount("personal"))
+//	err := b.Publish(ctx, events.NewSnapshotEvent("personal", snapshot))
+//
+// Every producer, sink and notifier shares the events.Event envelope, so
+// subscribers receive each event as it was published. Notifiers are
+// subscribed to snapshots, filled orders and triggered alerts.
+//
+// A Bus is itself an events.Sink and a notify.Notifier, so it can stand in
+// for either.
+package bus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"sync"
+
+	"github.com/adjaecent/unofficial-stockal-api/events"
+	"github.com/adjaecent/unofficial-stockal-api/notify"
+)
+
+// Handler receives published events.
+type Handler func(ctx context.Context, e events.Event) error
+
+// Bus delivers published events to its subscribers. It is safe for
+// concurrent use.
+type Bus struct {
+	onError func(subscriber string, e events.Event, err error)
+
+	mu     sync.RWMutex
+	subs   []*subscription
+	sinks  []events.Sink
+	nextID int
+}
+
+var (
+	_ events.Sink     = (*Bus)(nil)
+	_ notify.Notifier = (*Bus)(nil)
+)
+
+type subscription struct {
+	id      int
+	name    string
+	types   []events.Type
+	handler Handler
+}
+
+// Option configures a Bus.
+type Option func(*Bus)
+
+// WithErrorHandler sets a function called with every failed delivery. It
+// may be called concurrently when events are published concurrently.
+func WithErrorHandler(h func(subscriber string, e events.Event, err error)) Option {
+	return func(b *Bus) {
+		b.onError = h
+	}
+}
+
+// New creates a Bus without subscribers.
+func New(opts ...Option) *Bus {
+	b := &Bus{}
+	for _, opt := range opts {
+		opt(b)
+	}
+	return b
+}
+
+// Subscribe calls h with every published event of the given types, or of
+// any type if none are given. name identifies the subscriber in errors. The
+// returned function cancels the subscription.
+func (b *Bus) Subscribe(name string, h Handler, types ...events.Type) (cancel func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.nextID++
+	id := b.nextID
+	b.subs = append(b.subs, &subscription{id: id, name: name, types: types, handler: h})
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		b.subs = slices.DeleteFunc(b.subs, func(s *subscription) bool { return s.id == id })
+	}
+}
+
+// SubscribeSink publishes events of the given types, or all events, to sink.
+// Close closes the sink.
+func (b *Bus) SubscribeSink(name string, sink events.Sink, types ...events.Type) (cancel func()) {
+	b.mu.Lock()
+	b.sinks = append(b.sinks, sink)
+	b.mu.Unlock()
+	return b.Subscribe(name, func(ctx context.Context, e events.Event) error {
+		return sink.Publish(ctx, e)
+	}, types...)
+}
+
+// SubscribeNotifier delivers snapshots, filled orders and triggered alerts
+// to n.
+func (b *Bus) SubscribeNotifier(name string, n notify.Notifier) (cancel func()) {
+	return b.Subscribe(name, n.Notify, events.TypeSnapshotTaken, events.TypeOrderFilled, events.TypeAlertTriggered)
+}
+
+// Publish delivers each event to every subscriber of its type in turn, in
+// the order they subscribed, and returns once all have handled it. Failed
+// deliveries are reported to the error handler and joined in the result; they
+// do not stop delivery to other subscribers.
+func (b *Bus) Publish(ctx context.Context, evs ...events.Event) error {
+	b.mu.RLock()
+	subs := slices.Clone(b.subs)
+	b.mu.RUnlock()
+
+	var errs []error
+	for _, e := range evs {
+		for _, s := range subs {
+			if len(s.types) > 0 && !slices.Contains(s.types, e.Type) {
+				continue
+			}
+			if err := s.handler(ctx, e); err != nil {
+				if b.onError != nil {
+					b.onError(s.name, e, err)
+				}
+				errs = append(errs, fmt.Errorf("%s: %w", s.name, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Close closes the sinks subscribed with SubscribeSink.
+func (b *Bus) Close() error {
+	b.mu.Lock()
+	sinks := b.sinks
+	b.sinks = nil
+	b.mu.Unlock()
+
+	var errs []error
+	for _, s := range sinks {
+		if err := s.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Notify publishes event, so that producers written against notify.Notifier,
+// such as the alert engine, feed the bus.
+func (b *Bus) Notify(ctx context.Context, event events.Event) error {
+	return b.Publish(ctx, event)
+}
+
+// Log returns a Handler that prints one line per event to l.
+func Log(l *log.Logger) Handler {
+	return func(ctx context.Context, e events.Event) error {
+		switch data := e.Data.(type) {
+		case *events.Snapshot:
+			l.Printf("%s %s: value %.2f, %d holdings", e.Type, e.Account, data.TotalValue, len(data.Holdings))
+		case *events.PriceTick:
+			l.Printf("%s %s: %.2f", e.Type, data.Symbol, data.Price)
+		case *events.OrderUpdate:
+			l.Printf("%s %s: %s %s %s", e.Type, data.OrderID, data.Side, data.Symbol, data.Status)
+		case *events.Transaction:
+			l.Printf("%s %s: %s %.2f", e.Type, data.TransactionID, data.Symbol, data.Amount)
+		case *events.Alert:
+			l.Printf("%s: %s", e.Type, data.Message)
+		default:
+			l.Printf("%s %s", e.Type, e.Key)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,166 @@
+package bus_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+	"github.com/adjaecent/unofficial-stockal-api/alerts"
+	"github.com/adjaecent/unofficial-stockal-api/bus"
+	"github.com/adjaecent/unofficial-stockal-api/events"
+)
+
+// recorder is a sink and notifier that records what it receives.
+type recorder struct {
+	events []events.Event
+	err    error
+	closed bool
+}
+
+func (r *recorder) Publish(ctx context.Context, evs ...events.Event) error {
+	r.events = append(r.events, evs...)
+	return r.err
+}
+
+func (r *recorder) Notify(ctx context.Context, e events.Event) error {
+	return r.Publish(ctx, e)
+}
+
+func (r *recorder) Close() error {
+	r.closed = true
+	return nil
+}
+
+func (r *recorder) types() []events.Type {
+	var types []events.Type
+	for _, e := range r.events {
+		types = append(types, e.Type)
+	}
+	return types
+}
+
+func snapshot() *stockal.Snapshot {
+	return &stockal.Snapshot{TakenAt: time.Date(2024, time.May, 1, 16, 0, 0, 0, time.UTC)}
+}
+
+func TestPublish(t *testing.T) {
+	b := bus.New()
+	var order []string
+	b.Subscribe("all", func(ctx context.Context, e events.Event) error {
+		order = append(order, "all "+string(e.Type))
+		return nil
+	})
+	b.Subscribe("alerts", func(ctx context.Context, e events.Event) error {
+		order = append(order, "alerts "+string(e.Type))
+		return nil
+	}, events.TypeAlertTriggered)
+
+	err := b.Publish(context.Background(),
+		events.NewSnapshotEvent("personal", snapshot()),
+		events.NewAlertEvent("personal", events.Alert{Symbol: "AAPL", Condition: "above", Threshold: 200, Price: 201}))
+	if err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	want := []string{"all snapshot.taken", "all alert.triggered", "alerts alert.triggered"}
+	if !slices.Equal(order, want) {
+		t.Errorf("delivered %q, want %q", order, want)
+	}
+}
+
+func TestPublishErrors(t *testing.T) {
+	var failed []string
+	b := bus.New(bus.WithErrorHandler(func(subscriber string, e events.Event, err error) {
+		failed = append(failed, subscriber)
+	}))
+	broken := &recorder{err: errors.New("unavailable")}
+	working := &recorder{}
+	b.SubscribeSink("broken", broken)
+	b.SubscribeSink("working", working)
+
+	err := b.Publish(context.Background(), events.NewSnapshotEvent("personal", snapshot()))
+	if err == nil || !strings.Contains(err.Error(), "broken: unavailable") {
+		t.Errorf("Publish = %v, want the broken sink's error", err)
+	}
+	if !slices.Equal(failed, []string{"broken"}) {
+		t.Errorf("error handler called for %q, want the broken sink", failed)
+	}
+	if len(working.events) != 1 {
+		t.Errorf("working sink received %d events, want 1", len(working.events))
+	}
+}
+
+func TestCancel(t *testing.T) {
+	b := bus.New()
+	r := &recorder{}
+	cancel := b.SubscribeSink("sink", r)
+	cancel()
+	if err := b.Publish(context.Background(), events.NewSnapshotEvent("personal", snapshot())); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	if len(r.events) != 0 {
+		t.Errorf("cancelled subscriber received %d events", len(r.events))
+	}
+	if err := b.Close(); err != nil || !r.closed {
+		t.Errorf("Close = %v, closed %v; want the sink closed", err, r.closed)
+	}
+}
+
+func TestSubscribeNotifier(t *testing.T) {
+	b := bus.New()
+	n := &recorder{}
+	b.SubscribeNotifier("notifier", n)
+
+	snap := events.NewSnapshotEvent("personal", snapshot())
+	err := b.Publish(context.Background(),
+		snap,
+		events.NewPriceTickEvent(stockal.Quote{Symbol: "AAPL", Price: 190}),
+		events.NewOrderEvent("personal", stockal.Order{ID: "o1", Status: stockal.OrderStatusNew}, ""),
+		events.NewOrderFilledEvent("personal", stockal.Order{ID: "o1", Status: stockal.OrderStatusFilled}))
+	if err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	want := []events.Type{events.TypeSnapshotTaken, events.TypeOrderFilled}
+	if got := n.types(); !slices.Equal(got, want) {
+		t.Fatalf("notifier received %v, want %v", got, want)
+	}
+	if got := n.events[0]; got.ID != snap.ID || !got.Time.Equal(snap.Time) || got.Source != snap.Source {
+		t.Errorf("notifier received %+v, want the published event %+v", got, snap)
+	}
+}
+
+func TestAlertEngine(t *testing.T) {
+	b := bus.New()
+	n := &recorder{}
+	b.SubscribeNotifier("notifier", n)
+
+	engine := alerts.NewEngine(b, alerts.WithAccount("personal"))
+	engine.Add(alerts.PriceAbove{Symbol: "AAPL", Price: 200})
+	if _, err := engine.ObserveQuotes(context.Background(), []stockal.Quote{{Symbol: "AAPL", Price: 201}}); err != nil {
+		t.Fatalf("ObserveQuotes: %v", err)
+	}
+	if len(n.events) != 1 {
+		t.Fatalf("notifier received %d events, want the alert", len(n.events))
+	}
+	e := n.events[0]
+	if a, ok := e.Data.(*events.Alert); e.Type != events.TypeAlertTriggered || e.Account != "personal" || !ok || a.Price != 201 {
+		t.Errorf("notifier received %+v, want the alert for personal", e)
+	}
+}
+
+func TestLog(t *testing.T) {
+	var buf bytes.Buffer
+	b := bus.New()
+	b.Subscribe("log", bus.Log(log.New(&buf, "", 0)))
+	if err := b.Publish(context.Background(), events.NewPriceTickEvent(stockal.Quote{Symbol: "AAPL", Price: 190.5})); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	if got, want := buf.String(), "price.tick AAPL: 190.50\n"; got != want {
+		t.Errorf("logged %q, want %q", got, want)
+	}
+}
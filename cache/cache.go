@@ -0,0 +1,94 @@
+// Package cache stores serialized API responses for a limited time, so that
+// services built on the client do not call the Stockal API for every request.
+//
+// Cache is implemented by Memory, for a single process, and by backends in
+// subpackages, such as cache/redis, that several processes can share:
+//
+//	c, err := redis.Open("redis://localhost:6379/0")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	defer c.Close()
+//
+//	if err := c.Set(ctx, "summary", body, 30*time.Second); err != nil {
+//		log.Print(err)
+//	}
+//
+// cachetest.TestCache checks a backend against the Cache contract.
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrMiss is returned by Get when a key is not cached or has expired.
+var ErrMiss = errors.New("cache miss")
+
+// Cache holds values for a limited time.
+type Cache interface {
+	// Get returns the value stored under key, or ErrMiss.
+	Get(ctx context.Context, key string) ([]byte, error)
+	// Set stores value under key until ttl has passed.
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+}
+
+// Memory is a Cache kept in process memory. The zero value is ready to use.
+type Memory struct {
+	mu      sync.Mutex
+	entries map[string]memoryEntry
+	// swept is the number of entries after the last sweep of expired ones
+	swept int
+}
+
+type memoryEntry struct {
+	value   []byte
+	expires time.Time
+}
+
+var _ Cache = (*Memory)(nil)
+
+// NewMemory returns an empty in-memory cache.
+func NewMemory() *Memory {
+	return &Memory{}
+}
+
+// Get implements Cache.
+func (m *Memory) Get(ctx context.Context, key string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, ok := m.entries[key]
+	if !ok {
+		return nil, ErrMiss
+	}
+	if !time.Now().Before(e.expires) {
+		delete(m.entries, key)
+		return nil, ErrMiss
+	}
+	return e.value, nil
+}
+
+// Set implements Cache.
+func (m *Memory) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	now := time.Now()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.entries == nil {
+		m.entries = map[string]memoryEntry{}
+	}
+	m.entries[key] = memoryEntry{value: value, expires: now.Add(ttl)}
+
+	// Drop expired entries whenever the cache has doubled in size, so that
+	// keys that are never read again do not accumulate.
+	if len(m.entries) > 2*m.swept {
+		for k, e := range m.entries {
+			if !now.Before(e.expires) {
+				delete(m.entries, k)
+			}
+		}
+		m.swept = len(m.entries)
+	}
+	return nil
+}
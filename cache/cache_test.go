@@ -0,0 +1,16 @@
+package cache_test
+
+import (
+	"testing"
+
+	"github.com/adjaecent/unofficial-stockal-api/cache"
+	"github.com/adjaecent/unofficial-stockal-api/cache/cachetest"
+)
+
+func TestMemory(t *testing.T) {
+	cachetest.TestCache(t, cache.NewMemory())
+}
+
+func TestMemoryZeroValue(t *testing.T) {
+	cachetest.TestCache(t, &cache.Memory{})
+}
@@ -0,0 +1,72 @@
+// Package cachetest checks cache.Cache implementations against the behavior
+// the interface documents.
+//
+//	func TestCache(t *testing.T) {
+//		c, err := mycache.Open(addr)
+//		if err != nil {
+//			t.Fatal(err)
+//		}
+//		defer c.Close()
+//		cachetest.TestCache(t, c)
+//	}
+package cachetest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/adjaecent/unofficial-stockal-api/cache"
+)
+
+// expiry is the TTL of the entries that the test waits to expire.
+const expiry = 50 * time.Millisecond
+
+// TestCache stores, replaces and expires entries in c, which must be empty,
+// and checks that Get returns them until their TTL has passed and
+// cache.ErrMiss after.
+func TestCache(t *testing.T, c cache.Cache) {
+	t.Helper()
+	ctx := context.Background()
+
+	if value, err := c.Get(ctx, "summary"); !errors.Is(err, cache.ErrMiss) {
+		t.Errorf("Get of an unset key = %q, %v; want cache.ErrMiss", value, err)
+	}
+
+	if err := c.Set(ctx, "summary", []byte(`{"cash":100}`), time.Minute); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if err := c.Set(ctx, "portfolio", []byte(`{"holdings":[]}`), time.Minute); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if value, err := c.Get(ctx, "summary"); err != nil || string(value) != `{"cash":100}` {
+		t.Errorf("Get = %q, %v; want the stored value", value, err)
+	}
+
+	if err := c.Set(ctx, "summary", []byte(`{"cash":50}`), time.Minute); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if value, err := c.Get(ctx, "summary"); err != nil || string(value) != `{"cash":50}` {
+		t.Errorf("Get after replacing = %q, %v; want the new value", value, err)
+	}
+	if value, err := c.Get(ctx, "portfolio"); err != nil || string(value) != `{"holdings":[]}` {
+		t.Errorf("Get of another key = %q, %v; want its own value", value, err)
+	}
+
+	if err := c.Set(ctx, "quotes", []byte(`[]`), expiry); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if err := c.Set(ctx, "portfolio", []byte(`{"holdings":[]}`), expiry); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	time.Sleep(2 * expiry)
+	for _, key := range []string{"quotes", "portfolio"} {
+		if value, err := c.Get(ctx, key); !errors.Is(err, cache.ErrMiss) {
+			t.Errorf("Get of expired %s = %q, %v; want cache.ErrMiss", key, value, err)
+		}
+	}
+	if _, err := c.Get(ctx, "summary"); err != nil {
+		t.Errorf("Get of an unexpired key = %v", err)
+	}
+}
@@ -0,0 +1,90 @@
+// Package redis implements cache.Cache with Redis, so that several processes
+// share cached responses and their expiry.
+package redis
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+
+	"github.com/adjaecent/unofficial-stockal-api/cache"
+)
+
+// DefaultPrefix is prepended to every key unless WithPrefix is used.
+const DefaultPrefix = "stockal:"
+
+// Cache stores values in Redis keys that expire on the server, so that every
+// process sees the same TTL.
+type Cache struct {
+	client goredis.UniversalClient
+	prefix string
+	// owned is set when Close should close the client
+	owned bool
+}
+
+var _ cache.Cache = (*Cache)(nil)
+
+// Option configures a Cache.
+type Option func(*Cache)
+
+// WithPrefix sets the prefix added to keys, to keep them apart from other
+// data in the same database.
+func WithPrefix(prefix string) Option {
+	return func(c *Cache) {
+		c.prefix = prefix
+	}
+}
+
+// New returns a cache using client, which the caller remains responsible for
+// closing.
+func New(client goredis.UniversalClient, opts ...Option) *Cache {
+	c := &Cache{client: client, prefix: DefaultPrefix}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Open connects to the Redis server at url, such as
+// "redis://:password@localhost:6379/0" or "rediss://..." for TLS, and checks
+// that it answers.
+func Open(url string, opts ...Option) (*Cache, error) {
+	options, err := goredis.ParseURL(url)
+	if err != nil {
+		return nil, err
+	}
+	client := goredis.NewClient(options)
+	ctx, cancel := context.WithTimeout(context.Background(), client.Options().DialTimeout)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, err
+	}
+	c := New(client, opts...)
+	c.owned = true
+	return c, nil
+}
+
+// Get implements cache.Cache.
+func (c *Cache) Get(ctx context.Context, key string) ([]byte, error) {
+	value, err := c.client.Get(ctx, c.prefix+key).Bytes()
+	if errors.Is(err, goredis.Nil) {
+		return nil, cache.ErrMiss
+	}
+	return value, err
+}
+
+// Set implements cache.Cache.
+func (c *Cache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return c.client.Set(ctx, c.prefix+key, value, ttl).Err()
+}
+
+// Close closes the connection if the cache was created with Open.
+func (c *Cache) Close() error {
+	if !c.owned {
+		return nil
+	}
+	return c.client.Close()
+}
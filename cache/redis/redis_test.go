@@ -0,0 +1,176 @@
+package redis_test
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/adjaecent/unofficial-stockal-api/cache/cachetest"
+	"github.com/adjaecent/unofficial-stockal-api/cache/redis"
+)
+
+// server is a fake Redis server answering the RESP2 commands the cache sends.
+// Commands it does not know, such as HELLO, get an error, as from an older
+// server.
+type server struct {
+	ln net.Listener
+
+	mu      sync.Mutex
+	db      string
+	entries map[string]entry
+}
+
+type entry struct {
+	value   string
+	expires time.Time
+}
+
+func newServer(t *testing.T) *server {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &server{ln: ln, entries: map[string]entry{}}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	t.Cleanup(func() { ln.Close() })
+	return s
+}
+
+func (s *server) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	for {
+		args, err := readCommand(r)
+		if err != nil {
+			return
+		}
+		io.WriteString(conn, s.do(args))
+	}
+}
+
+// readCommand reads a command sent as an array of bulk strings.
+func readCommand(r *bufio.Reader) ([]string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, "*")))
+	if err != nil {
+		return nil, err
+	}
+	args := make([]string, n)
+	for i := range args {
+		if line, err = r.ReadString('\n'); err != nil {
+			return nil, err
+		}
+		size, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, "$")))
+		if err != nil {
+			return nil, err
+		}
+		arg := make([]byte, size+2)
+		if _, err := io.ReadFull(r, arg); err != nil {
+			return nil, err
+		}
+		args[i] = string(arg[:size])
+	}
+	return args, nil
+}
+
+func (s *server) do(args []string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch strings.ToUpper(args[0]) {
+	case "PING":
+		return "+PONG\r\n"
+	case "SELECT":
+		s.db = args[1]
+		return "+OK\r\n"
+	case "GET":
+		e, ok := s.entries[args[1]]
+		if !ok || !e.expires.IsZero() && !time.Now().Before(e.expires) {
+			return "$-1\r\n"
+		}
+		return fmt.Sprintf("$%d\r\n%s\r\n", len(e.value), e.value)
+	case "SET":
+		e := entry{value: args[2]}
+		if len(args) == 5 {
+			n, _ := strconv.Atoi(args[4])
+			unit := time.Second
+			if strings.EqualFold(args[3], "px") {
+				unit = time.Millisecond
+			}
+			e.expires = time.Now().Add(time.Duration(n) * unit)
+		}
+		s.entries[args[1]] = e
+		return "+OK\r\n"
+	}
+	return fmt.Sprintf("-ERR unknown command '%s'\r\n", args[0])
+}
+
+func (s *server) keys() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var keys []string
+	for key := range s.entries {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+func TestCache(t *testing.T) {
+	s := newServer(t)
+	c, err := redis.Open("redis://" + s.ln.Addr().String() + "/2")
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer c.Close()
+
+	cachetest.TestCache(t, c)
+
+	if want := []string{"stockal:portfolio", "stockal:quotes", "stockal:summary"}; !slices.Equal(s.keys(), want) {
+		t.Errorf("server holds %q, want %q", s.keys(), want)
+	}
+	if s.db != "2" {
+		t.Errorf("selected database %q, want the URL's 2", s.db)
+	}
+}
+
+func TestPrefix(t *testing.T) {
+	s := newServer(t)
+	c, err := redis.Open("redis://"+s.ln.Addr().String(), redis.WithPrefix("app:"))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer c.Close()
+
+	if err := c.Set(context.Background(), "summary", []byte("{}"), time.Minute); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if keys := s.keys(); !slices.Equal(keys, []string{"app:summary"}) {
+		t.Errorf("server holds %q, want the key under the prefix", keys)
+	}
+}
+
+func TestOpenInvalidURL(t *testing.T) {
+	if _, err := redis.Open("http://localhost:6379"); err == nil {
+		t.Error("Open accepted an http URL")
+	}
+}
@@ -0,0 +1,44 @@
+package stockal
+
+import (
+	"context"
+	"time"
+)
+
+// CorporateEventKind identifies a kind of scheduled corporate event.
+type CorporateEventKind string
+
+// Corporate event kinds.
+const (
+	// EventExDividend is the first day a stock trades without its next dividend
+	EventExDividend CorporateEventKind = "ex-dividend"
+	// EventDividendPayment is the day a dividend is paid
+	EventDividendPayment CorporateEventKind = "dividend-payment"
+	// EventEarnings is the day a company reports its results
+	EventEarnings CorporateEventKind = "earnings"
+)
+
+// CorporateEvent is a dated event concerning a stock, such as a dividend or
+// an earnings report.
+type CorporateEvent struct {
+	// Symbol is the stock symbol
+	Symbol string `json:"symbol"`
+	// Kind is the kind of event
+	Kind CorporateEventKind `json:"kind"`
+	// Date is the day of the event, at midnight UTC
+	Date time.Time `json:"date"`
+	// Amount is the dividend per share for dividend events, or the consensus
+	// earnings-per-share estimate for earnings; zero when unknown
+	Amount float64 `json:"amount,omitempty"`
+	// Currency is the ISO 4217 code of Amount
+	Currency string `json:"currency,omitempty"`
+}
+
+// CorporateEventProvider is a source of corporate event calendars, which the
+// Stockal API does not offer.
+//
+// CorporateEvents returns the events of the given symbols dated within
+// [from, to], ordered by date.
+type CorporateEventProvider interface {
+	CorporateEvents(ctx context.Context, from, to time.Time, symbols ...string) ([]CorporateEvent, error)
+}
@@ -0,0 +1,161 @@
+package stockal
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ErrUnsupportedInterval is returned when a candle source cannot provide bars
+// of the requested interval.
+var ErrUnsupportedInterval = errors.New("unsupported candle interval")
+
+// Interval is the duration covered by one candle.
+type Interval string
+
+// Candle intervals.
+const (
+	Interval1Min Interval = "1m"
+	Interval5Min Interval = "5m"
+	IntervalDay  Interval = "1d"
+	IntervalWeek Interval = "1w"
+)
+
+// IsKnown reports whether i is one of the declared intervals.
+func (i Interval) IsKnown() bool {
+	switch i {
+	case Interval1Min, Interval5Min, IntervalDay, IntervalWeek:
+		return true
+	}
+	return false
+}
+
+// Candle is an OHLCV bar.
+type Candle struct {
+	// Time is the start of the bar
+	Time time.Time `json:"time"`
+	// Open is the first traded price in the bar
+	Open float64 `json:"open"`
+	// High is the highest traded price in the bar
+	High float64 `json:"high"`
+	// Low is the lowest traded price in the bar
+	Low float64 `json:"low"`
+	// Close is the last traded price in the bar
+	Close float64 `json:"close"`
+	// Volume is the number of shares traded in the bar
+	Volume int64 `json:"volume"`
+}
+
+// CandleProvider is a source of historical candles, such as a market data
+// vendor with deeper history than Stockal.
+//
+// Candles returns the bars of symbol starting within [from, to], oldest
+// first. Sources return ErrUnsupportedInterval for intervals they lack.
+type CandleProvider interface {
+	Candles(ctx context.Context, symbol string, interval Interval, from, to time.Time) ([]Candle, error)
+}
+
+// UnmarshalJSON decodes a candle, reading its time as ParseTime does, since
+// the API sends Unix timestamps as well as RFC 3339 times.
+func (c *Candle) UnmarshalJSON(data []byte) error {
+	type plain Candle
+	var v struct {
+		plain
+		Time json.RawMessage `json:"time"`
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*c = Candle(v.plain)
+	if raw := bytes.TrimSpace(v.Time); len(raw) > 0 && string(raw) != "null" {
+		var s string
+		if err := json.Unmarshal(raw, &s); err != nil {
+			s = string(raw)
+		}
+		t, err := ParseTime(s)
+		if err != nil {
+			return fmt.Errorf("candle time: %w", err)
+		}
+		c.Time = t
+	}
+	return nil
+}
+
+// CandleReader is implemented by clients that can read historical prices.
+type CandleReader interface {
+	GetCandles(ctx context.Context, symbol string, interval Interval, from, to time.Time) (*CandlesResponse, error)
+}
+
+// CandlesResponse represents the response from the candles API.
+type CandlesResponse struct {
+	// Code is the HTTP response code
+	Code int `json:"code"`
+	// Message is the response message (usually "Success")
+	Message string `json:"message"`
+	// Data contains the bars, oldest first
+	Data []Candle `json:"data"`
+}
+
+func (r *CandlesResponse) normalize() {
+	if r.Data == nil {
+		r.Data = []Candle{}
+	}
+}
+
+// GetCandles retrieves the OHLCV bars of symbol starting within [from, to],
+// oldest first. A zero from or to leaves that end of the range to the API.
+// Intervals other than the declared ones return ErrUnsupportedInterval.
+//
+// The API's bars outside the range are dropped, so that the result always
+// covers what was asked for and no more.
+//
+// Example:
+//
+//	to := time.Now()
+//	candles, err := client.GetCandles(ctx, "AAPL", stockal.IntervalDay, to.AddDate(0, -6, 0), to)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	for _, c := range candles.Data {
+//		fmt.Printf("%s %.2f\n", c.Time.Format(time.DateOnly), c.Close)
+//	}
+func (c *Client) GetCandles(ctx context.Context, symbol string, interval Interval, from, to time.Time) (*CandlesResponse, error) {
+	symbol = strings.ToUpper(strings.TrimSpace(symbol))
+	switch {
+	case symbol == "":
+		return nil, fmt.Errorf("%w: a symbol is required", ErrInvalidParams)
+	case !interval.IsKnown():
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedInterval, interval)
+	case !from.IsZero() && !to.IsZero() && from.After(to):
+		return nil, fmt.Errorf("%w: empty date range %s to %s", ErrInvalidParams,
+			from.Format(time.RFC3339), to.Format(time.RFC3339))
+	}
+
+	query := url.Values{"symbol": {symbol}, "interval": {string(interval)}}
+	if !from.IsZero() {
+		query.Set("from", from.UTC().Format(time.RFC3339))
+	}
+	if !to.IsZero() {
+		query.Set("to", to.UTC().Format(time.RFC3339))
+	}
+	var candlesResp CandlesResponse
+	if err := c.do(ctx, "GET", "/v2/market/candles?"+query.Encode(), nil, &candlesResp, "candles"); err != nil {
+		return nil, err
+	}
+
+	candles := candlesResp.Data[:0]
+	for _, candle := range candlesResp.Data {
+		if (from.IsZero() || !candle.Time.Before(from)) && (to.IsZero() || !candle.Time.After(to)) {
+			candles = append(candles, candle)
+		}
+	}
+	slices.SortStableFunc(candles, func(a, b Candle) int { return a.Time.Compare(b.Time) })
+	candlesResp.Data = candles
+	return &candlesResp, nil
+}
@@ -0,0 +1,44 @@
+package stockal_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+)
+
+func TestGetCandles(t *testing.T) {
+	var path, query string
+	client := newHandlerClient(t, func(w http.ResponseWriter, r *http.Request) {
+		path, query = r.URL.Path, r.URL.RawQuery
+		// Out of order, with one bar outside the range and times in both forms
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"code":200,"message":"Success","data":[` +
+			`{"time":"2024-03-05T00:00:00Z","open":170,"high":172,"low":169,"close":171,"volume":1000},` +
+			`{"time":1709510400,"open":168,"high":171,"low":167,"close":170,"volume":900},` +
+			`{"time":1709856000000,"open":175,"high":176,"low":174,"close":175,"volume":800}]}`))
+	})
+
+	from := time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 3, 6, 0, 0, 0, 0, time.UTC)
+	resp, err := client.GetCandles(context.Background(), "aapl", stockal.IntervalDay, from, to)
+	if err != nil {
+		t.Fatalf("GetCandles: %v", err)
+	}
+	if path != "/v2/market/candles" || query != "from=2024-03-04T00%3A00%3A00Z&interval=1d&symbol=AAPL&to=2024-03-06T00%3A00%3A00Z" {
+		t.Errorf("sent %s?%s", path, query)
+	}
+	if len(resp.Data) != 2 || !resp.Data[0].Time.Equal(from) || resp.Data[0].Close != 170 || resp.Data[1].Volume != 1000 {
+		t.Errorf("got %+v, want the two bars in range, oldest first", resp.Data)
+	}
+
+	if _, err := client.GetCandles(context.Background(), "AAPL", "1h", from, to); !errors.Is(err, stockal.ErrUnsupportedInterval) {
+		t.Errorf("GetCandles(1h) error = %v, want ErrUnsupportedInterval", err)
+	}
+	if _, err := client.GetCandles(context.Background(), "AAPL", stockal.IntervalDay, to, from); !errors.Is(err, stockal.ErrInvalidParams) {
+		t.Errorf("GetCandles(to, from) error = %v, want ErrInvalidParams", err)
+	}
+}
@@ -0,0 +1,366 @@
+// Command stockal-events publishes portfolio snapshots, price ticks, order
+// lifecycle events and new transactions to Kafka and NATS, writes portfolio
+// values and prices to InfluxDB, annotates large trades, deposits and
+// dividends in Grafana, updates Home Assistant
+// sensors over MQTT and keeps Notion databases of holdings and daily totals.
+//
+// It logs in with the STOCKAL_USERNAME and STOCKAL_PASSWORD environment
+// variables and polls the API while the US market is open (orders and
+// transactions are polled around the clock):
+//
+//	STOCKAL_USERNAME=alice STOCKAL_PASSWORD=... stockal-events -kafka-rest http://localhost:8082
+//	STOCKAL_USERNAME=alice STOCKAL_PASSWORD=... stockal-events -nats nats://localhost:4222 -nats-jetstream
+//	INFLUX_TOKEN=... stockal-events -influx http://localhost:8086 -influx-org home -influx-bucket stockal
+//	GRAFANA_TOKEN=... stockal-events -grafana http://localhost:3000 -grafana-min-trade 1000
+//	MQTT_PASSWORD=... stockal-events -mqtt tcp://homeassistant.local:1883 -mqtt-user stockal
+//	NOTION_TOKEN=... stockal-events -notion-holdings 5c8f… -notion-summary a31e… -notion-summary-map "value=Net Worth"
+//
+// Every event is published once on a bus (package bus) that each output
+// subscribes to; -log-events also logs them, which helps when trying the
+// command out.
+//
+// Price ticks cover the portfolio's holdings and any symbols given with
+// -symbols. Set an interval to 0 to disable that kind of event.
+//
+// Credentials and tokens missing from the environment are read from the
+// backend given with -secrets or STOCKAL_SECRETS, such as a directory of
+// files or AWS Secrets Manager; see package secrets.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	natsgo "github.com/nats-io/nats.go"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+	"github.com/adjaecent/unofficial-stockal-api/bus"
+	"github.com/adjaecent/unofficial-stockal-api/events"
+	"github.com/adjaecent/unofficial-stockal-api/events/grafana"
+	"github.com/adjaecent/unofficial-stockal-api/events/homeassistant"
+	"github.com/adjaecent/unofficial-stockal-api/events/influx"
+	"github.com/adjaecent/unofficial-stockal-api/events/kafka"
+	"github.com/adjaecent/unofficial-stockal-api/events/nats"
+	"github.com/adjaecent/unofficial-stockal-api/events/notion"
+	"github.com/adjaecent/unofficial-stockal-api/poller"
+	"github.com/adjaecent/unofficial-stockal-api/secrets"
+)
+
+// Environment variables holding the credentials.
+const (
+	envUsername     = "STOCKAL_USERNAME"
+	envPassword     = "STOCKAL_PASSWORD"
+	envInfluxToken  = "INFLUX_TOKEN"
+	envGrafanaToken = "GRAFANA_TOKEN"
+	envMQTTPassword = "MQTT_PASSWORD"
+	envNotionToken  = "NOTION_TOKEN"
+)
+
+func main() {
+	var (
+		kafkaREST        = flag.String("kafka-rest", "", "Kafka REST proxy URL")
+		natsURL          = flag.String("nats", "", "NATS server URL")
+		natsCreds        = flag.String("nats-creds", "", "NATS user credentials file")
+		natsJetStream    = flag.Bool("nats-jetstream", false, "publish through JetStream and wait for storage")
+		natsKeySubjects  = flag.Bool("nats-key-subjects", false, "append the symbol, order ID or account to NATS subjects")
+		influxURL        = flag.String("influx", "", "InfluxDB URL; the token is read from "+envInfluxToken)
+		influxOrg        = flag.String("influx-org", "", "InfluxDB organization")
+		influxBucket     = flag.String("influx-bucket", "stockal", "InfluxDB bucket, or database/retention-policy for InfluxDB 1.8")
+		grafanaURL       = flag.String("grafana", "", "Grafana URL to annotate filled orders, deposits and dividends in; the token is read from "+envGrafanaToken)
+		grafanaDashboard = flag.String("grafana-dashboard", "", "UID of the dashboard to attach annotations to (default: organization-wide)")
+		grafanaMinTrade  = flag.Float64("grafana-min-trade", 0, "only annotate fills worth at least this many USD")
+		mqttURL          = flag.String("mqtt", "", "MQTT broker URL to publish Home Assistant sensors to")
+		mqttUser         = flag.String("mqtt-user", "", "MQTT username; the password is read from "+envMQTTPassword)
+		notionHoldings   = flag.String("notion-holdings", "", "ID of a Notion database to keep a row per holding in; the token is read from "+envNotionToken)
+		notionSummary    = flag.String("notion-summary", "", "ID of a Notion database to keep a row of totals per day in")
+		notionHoldingMap = flag.String("notion-holdings-map", "", "comma-separated field=Property overrides of the Notion holdings columns")
+		notionSummaryMap = flag.String("notion-summary-map", "", "comma-separated field=Property overrides of the Notion summary columns")
+		logEvents        = flag.Bool("log-events", false, "log every published event")
+		account          = flag.String("account", "default", "account name set on events")
+		symbols          = flag.String("symbols", "", "comma-separated symbols to publish ticks for besides the holdings")
+		snapshotInterval = flag.Duration("snapshot-interval", 15*time.Minute, "how often to publish snapshots")
+		quoteInterval    = flag.Duration("quote-interval", time.Minute, "how often to poll quotes for price ticks")
+		orderInterval    = flag.Duration("order-interval", time.Minute, "how often to poll orders for changes")
+		txInterval       = flag.Duration("transaction-interval", 15*time.Minute, "how often to poll for new transactions, such as deposits and dividends")
+		baseURL          = flag.String("base-url", stockal.BaseURL, "Stockal API base URL")
+		timeout          = flag.Duration("timeout", stockal.DefaultTimeout, "HTTP timeout for API requests")
+		topics           = events.DefaultTopics
+		secretsSpec      = flag.String("secrets", os.Getenv(secrets.EnvSpec), "where to read credentials from besides the environment: file:DIR, aws-sm:PREFIX, aws-sm-json:SECRET or gcp-sm:PROJECT")
+	)
+	flag.StringVar(&topics.Snapshots, "topic-snapshots", topics.Snapshots, "topic for snapshot events")
+	flag.StringVar(&topics.PriceTicks, "topic-prices", topics.PriceTicks, "topic for price ticks")
+	flag.StringVar(&topics.Orders, "topic-orders", topics.Orders, "topic for order events")
+	flag.StringVar(&topics.Alerts, "topic-alerts", topics.Alerts, "topic for triggered alerts")
+	flag.StringVar(&topics.Transactions, "topic-transactions", topics.Transactions, "topic for deposits, dividends and other transactions")
+	flag.Parse()
+
+	secret := secrets.Lookup(*secretsSpec)
+	username, password := secret(envUsername), secret(envPassword)
+	if username == "" || password == "" {
+		log.Fatalf("set %s and %s", envUsername, envPassword)
+	}
+	var (
+		sinks   []events.Sink
+		targets []string
+	)
+	if *kafkaREST != "" {
+		sinks = append(sinks, kafka.New(*kafkaREST, kafka.WithTopics(topics)))
+		targets = append(targets, *kafkaREST)
+	}
+	if *natsURL != "" {
+		options := []nats.Option{nats.WithTopics(topics)}
+		if *natsCreds != "" {
+			options = append(options, nats.WithNATSOptions(natsgo.UserCredentials(*natsCreds)))
+		}
+		if *natsJetStream {
+			options = append(options, nats.WithJetStream())
+		}
+		if *natsKeySubjects {
+			options = append(options, nats.WithKeySubjects())
+		}
+		sink, err := nats.Connect(*natsURL, options...)
+		if err != nil {
+			log.Fatal(err)
+		}
+		sinks = append(sinks, sink)
+		targets = append(targets, *natsURL)
+	}
+	if *influxURL != "" {
+		sinks = append(sinks, influx.New(*influxURL, *influxBucket,
+			influx.WithOrg(*influxOrg), influx.WithToken(secret(envInfluxToken))))
+		targets = append(targets, *influxURL)
+	}
+	if *grafanaURL != "" {
+		sinks = append(sinks, grafana.New(*grafanaURL, grafana.WithToken(secret(envGrafanaToken)),
+			grafana.WithDashboard(*grafanaDashboard), grafana.WithMinTradeValue(*grafanaMinTrade)))
+		targets = append(targets, *grafanaURL)
+	}
+	if *mqttURL != "" {
+		var options []homeassistant.Option
+		if *mqttUser != "" {
+			options = append(options, homeassistant.WithCredentials(*mqttUser, secret(envMQTTPassword)))
+		}
+		sink, err := homeassistant.Connect(*mqttURL, options...)
+		if err != nil {
+			log.Fatal(err)
+		}
+		sinks = append(sinks, sink)
+		targets = append(targets, *mqttURL)
+	}
+	if *notionHoldings != "" || *notionSummary != "" {
+		var options []notion.Option
+		if *notionHoldings != "" {
+			mapping, err := parseMapping(*notionHoldingMap)
+			if err != nil {
+				log.Fatalf("invalid -notion-holdings-map: %v", err)
+			}
+			options = append(options, notion.WithHoldings(*notionHoldings, mapping))
+		}
+		if *notionSummary != "" {
+			mapping, err := parseMapping(*notionSummaryMap)
+			if err != nil {
+				log.Fatalf("invalid -notion-summary-map: %v", err)
+			}
+			options = append(options, notion.WithSummary(*notionSummary, mapping))
+		}
+		sinks = append(sinks, notion.New(secret(envNotionToken), options...))
+		targets = append(targets, "Notion")
+	}
+	if len(sinks) == 0 && !*logEvents {
+		log.Fatal("set -kafka-rest, -nats, -influx, -grafana, -mqtt, -notion-holdings or -log-events")
+	}
+	b := bus.New()
+	for i, sink := range sinks {
+		b.SubscribeSink(targets[i], sink)
+	}
+	if *logEvents {
+		b.Subscribe("log", bus.Log(log.Default()))
+		targets = append(targets, "the log")
+	}
+	defer b.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	p := &publisher{
+		client:   stockal.NewClient(stockal.WithBaseURL(*baseURL), stockal.WithTimeout(*timeout)),
+		username: username,
+		password: password,
+		account:  *account,
+		bus:      b,
+		orders:   events.NewOrderTracker(*account),
+		prices:   events.NewPriceTracker(),
+		txs:      events.NewTransactionTracker(*account),
+	}
+	for _, s := range strings.Split(*symbols, ",") {
+		if s = strings.ToUpper(strings.TrimSpace(s)); s != "" {
+			p.extra = append(p.extra, s)
+		}
+	}
+
+	tasks := poller.New(poller.WithErrorHandler(func(task string, err error) {
+		log.Printf("%s: %v", task, err)
+	}))
+	if *snapshotInterval > 0 {
+		tasks.Add(poller.Task{Name: "snapshot", Schedule: poller.DuringMarketHours(poller.Every(*snapshotInterval)), Immediate: true, Run: p.snapshot})
+	}
+	if *quoteInterval > 0 {
+		tasks.Add(poller.Task{Name: "quotes", Schedule: poller.DuringMarketHours(poller.Every(*quoteInterval)), Immediate: true, Run: p.quotes})
+	}
+	if *orderInterval > 0 {
+		tasks.Add(poller.Task{Name: "orders", Schedule: poller.Every(*orderInterval), Immediate: true, Run: p.ordersTask})
+	}
+	if *txInterval > 0 {
+		tasks.Add(poller.Task{Name: "transactions", Schedule: poller.Every(*txInterval), Immediate: true, Run: p.transactions})
+	}
+
+	log.Printf("publishing to %s", strings.Join(targets, ", "))
+	if err := tasks.Run(ctx); err != nil && ctx.Err() == nil {
+		log.Fatal(err)
+	}
+}
+
+// parseMapping parses "field=Property,..." into a Notion mapping. An empty
+// property drops the field.
+func parseMapping(s string) (notion.Mapping, error) {
+	mapping := notion.Mapping{}
+	for _, entry := range strings.Split(s, ",") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		field, property, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("%q is not field=Property", entry)
+		}
+		mapping[strings.TrimSpace(field)] = strings.TrimSpace(property)
+	}
+	return mapping, nil
+}
+
+// publisher polls the API and publishes what changed.
+type publisher struct {
+	client             stockal.StockalClient
+	username, password string
+	account            string
+	extra              []string
+	bus                *bus.Bus
+	orders             *events.OrderTracker
+	prices             *events.PriceTracker
+	txs                *events.TransactionTracker
+
+	mu sync.Mutex
+	// holdings are the symbols held at the last snapshot or quote poll
+	holdings []string
+}
+
+// call runs f, logging in again and retrying once if it fails, e.g. because
+// the session has expired.
+func (p *publisher) call(ctx context.Context, f func() error) error {
+	if err := f(); err == nil || ctx.Err() != nil {
+		return err
+	}
+	p.mu.Lock()
+	_, err := p.client.Auth().Login(ctx, p.username, p.password)
+	p.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	return f()
+}
+
+func (p *publisher) snapshot(ctx context.Context) error {
+	var snapshot *stockal.Snapshot
+	err := p.call(ctx, func() (err error) {
+		snapshot, err = stockal.TakeSnapshot(ctx, p.client)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	p.setHoldings(snapshot.Holdings)
+	return p.bus.Publish(ctx, events.NewSnapshotEvent(p.account, snapshot))
+}
+
+func (p *publisher) quotes(ctx context.Context) error {
+	p.mu.Lock()
+	symbols := p.holdings
+	p.mu.Unlock()
+	if symbols == nil {
+		var portfolio *stockal.PortfolioDetailResponse
+		err := p.call(ctx, func() (err error) {
+			portfolio, err = p.client.Portfolio().Detail(ctx)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		symbols = p.setHoldings(portfolio.Data.Holdings)
+	}
+	symbols = append(symbols[:len(symbols):len(symbols)], p.extra...)
+	if len(symbols) == 0 {
+		return nil
+	}
+
+	var quotes *stockal.QuotesResponse
+	err := p.call(ctx, func() (err error) {
+		quotes, err = p.client.GetQuotes(ctx, symbols...)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if ticks := p.prices.Update(quotes.Data); len(ticks) > 0 {
+		return p.bus.Publish(ctx, ticks...)
+	}
+	return nil
+}
+
+func (p *publisher) ordersTask(ctx context.Context) error {
+	var orders *stockal.OrderListResponse
+	err := p.call(ctx, func() (err error) {
+		orders, err = p.client.Orders().List(ctx)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if changes := p.orders.Update(orders.Data.Orders); len(changes) > 0 {
+		return p.bus.Publish(ctx, changes...)
+	}
+	return nil
+}
+
+func (p *publisher) transactions(ctx context.Context) error {
+	var txs *stockal.TransactionListResponse
+	err := p.call(ctx, func() (err error) {
+		txs, err = p.client.GetTransactions(ctx, stockal.TransactionOptions{})
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if added := p.txs.Update(txs.Data.Transactions); len(added) > 0 {
+		return p.bus.Publish(ctx, added...)
+	}
+	return nil
+}
+
+// setHoldings records the held symbols, not counting extra ones, and returns them.
+func (p *publisher) setHoldings(holdings []stockal.Holding) []string {
+	symbols := []string{}
+	for _, h := range holdings {
+		symbols = append(symbols, h.Symbol)
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.holdings = symbols
+	return symbols
+}
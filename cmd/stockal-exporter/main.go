@@ -0,0 +1,137 @@
+// Command stockal-exporter serves Stockal portfolio metrics for Prometheus.
+//
+// It logs in with the STOCKAL_USERNAME and STOCKAL_PASSWORD environment
+// variables, refreshes the account summary and holdings on an interval and
+// exposes them on /metrics:
+//
+//	STOCKAL_USERNAME=alice STOCKAL_PASSWORD=... stockal-exporter -listen :9877 -interval 5m
+//
+// A matching Prometheus scrape configuration:
+//
+//	scrape_configs:
+//	  - job_name: stockal
+//	    static_configs:
+//	      - targets: ["localhost:9877"]
+//
+// Credentials and tokens missing from the environment are read from the
+// backend given with -secrets or STOCKAL_SECRETS, such as a directory of
+// files or AWS Secrets Manager; see package secrets.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+	"github.com/adjaecent/unofficial-stockal-api/secrets"
+)
+
+// Environment variables holding the credentials.
+const (
+	envUsername = "STOCKAL_USERNAME"
+	envPassword = "STOCKAL_PASSWORD"
+)
+
+func main() {
+	var (
+		listen      = flag.String("listen", ":9877", "address to serve /metrics on")
+		interval    = flag.Duration("interval", 5*time.Minute, "how often to refresh portfolio data")
+		baseURL     = flag.String("base-url", stockal.BaseURL, "Stockal API base URL")
+		timeout     = flag.Duration("timeout", stockal.DefaultTimeout, "HTTP timeout for API requests")
+		secretsSpec = flag.String("secrets", os.Getenv(secrets.EnvSpec), "where to read credentials from besides the environment: file:DIR, aws-sm:PREFIX, aws-sm-json:SECRET or gcp-sm:PROJECT")
+	)
+	flag.Parse()
+
+	secret := secrets.Lookup(*secretsSpec)
+	username, password := secret(envUsername), secret(envPassword)
+	if username == "" || password == "" {
+		log.Fatalf("set %s and %s", envUsername, envPassword)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	m := newMetrics()
+	client := stockal.NewClient(stockal.WithBaseURL(*baseURL), stockal.WithTimeout(*timeout), stockal.WithRateLimitHook(m.rateLimitHit))
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(
+		m,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+
+	e := &exporter{client: client, username: username, password: password, metrics: m}
+	go e.run(ctx, *interval)
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<html><body><a href="/metrics">Metrics</a></body></html>`))
+	})
+	server := &http.Server{Addr: *listen, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+	go func() {
+		<-ctx.Done()
+		shutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(shutdown)
+	}()
+
+	log.Printf("serving metrics on %s", *listen)
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Fatal(err)
+	}
+}
+
+// exporter refreshes metrics from the API.
+type exporter struct {
+	client             stockal.StockalClient
+	username, password string
+	metrics            *metrics
+}
+
+// run refreshes the metrics immediately and then on every interval until ctx is cancelled.
+func (e *exporter) run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := e.refresh(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("refresh failed: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// refresh takes a snapshot and updates the metrics, logging in again if the
+// session has expired.
+func (e *exporter) refresh(ctx context.Context) error {
+	snapshot, err := stockal.TakeSnapshot(ctx, e.client)
+	if err != nil {
+		if _, loginErr := e.client.Auth().Login(ctx, e.username, e.password); loginErr != nil {
+			e.metrics.failed()
+			return loginErr
+		}
+		snapshot, err = stockal.TakeSnapshot(ctx, e.client)
+	}
+	if err != nil {
+		e.metrics.failed()
+		return err
+	}
+	e.metrics.update(snapshot)
+	return nil
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+	"github.com/adjaecent/unofficial-stockal-api/stockaltest"
+)
+
+func TestRefresh(t *testing.T) {
+	client := &stockaltest.MockClient{}
+	e := &exporter{client: client, username: "me", password: "s3cret", metrics: newMetrics()}
+	if err := e.refresh(context.Background()); err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+	if up, value := testutil.ToFloat64(e.metrics.up), testutil.ToFloat64(e.metrics.portfolioValue.WithLabelValues("total")); up != 1 || value != 7789.14 {
+		t.Errorf("up %v, portfolio value %v; want 1, the fixture's 7789.14", up, value)
+	}
+	if n := testutil.CollectAndCount(e.metrics.holdingValue); n != 3 {
+		t.Errorf("%d holding values, want AAPL's, MSFT's and VOO's", n)
+	}
+	if logins := client.CallsTo("Auth.Login"); len(logins) != 0 {
+		t.Errorf("logged in %d times with a session, want none", len(logins))
+	}
+}
+
+func TestRefreshLogin(t *testing.T) {
+	client := &stockaltest.MockClient{}
+	expired := true
+	client.AccountSummaryFunc = func(ctx context.Context) (*stockal.AccountSummaryResponse, error) {
+		if expired {
+			return nil, stockal.ErrTokenExpired
+		}
+		return stockaltest.AccountSummary(), nil
+	}
+	client.AuthLoginFunc = func(ctx context.Context, username, password string) (*stockal.LoginResponse, error) {
+		expired = false
+		return stockaltest.Session(), nil
+	}
+	e := &exporter{client: client, username: "me", password: "s3cret", metrics: newMetrics()}
+	if err := e.refresh(context.Background()); err != nil {
+		t.Fatalf("refresh after the session expired: %v", err)
+	}
+	if logins := client.CallsTo("Auth.Login"); len(logins) != 1 || testutil.ToFloat64(e.metrics.up) != 1 {
+		t.Errorf("logged in %d times, up %v; want one login and the metrics updated", len(logins), testutil.ToFloat64(e.metrics.up))
+	}
+}
+
+func TestRefreshFail(t *testing.T) {
+	loginErr := errors.New("invalid credentials")
+	tests := []struct {
+		name  string
+		login error
+		want  error
+	}{
+		{name: "login fails", login: loginErr, want: loginErr},
+		{name: "still failing after login", want: stockal.ErrServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &stockaltest.MockClient{
+				AccountSummaryFunc: func(ctx context.Context) (*stockal.AccountSummaryResponse, error) {
+					return nil, stockal.ErrServerError
+				},
+				AuthLoginFunc: func(ctx context.Context, username, password string) (*stockal.LoginResponse, error) {
+					if tt.login != nil {
+						return nil, tt.login
+					}
+					return stockaltest.Session(), nil
+				},
+			}
+			e := &exporter{client: client, username: "me", password: "s3cret", metrics: newMetrics()}
+			if err := e.refresh(context.Background()); !errors.Is(err, tt.want) {
+				t.Errorf("refresh = %v, want %v", err, tt.want)
+			}
+			if up, failures := testutil.ToFloat64(e.metrics.up), testutil.ToFloat64(e.metrics.refreshFailures); up != 0 || failures != 1 {
+				t.Errorf("up %v, %v failures; want 0, 1", up, failures)
+			}
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"log"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+)
+
+const namespace = "stockal"
+
+// metrics holds the exported gauges. It is a prometheus.Collector so that a
+// scrape always sees one consistent snapshot.
+type metrics struct {
+	mu sync.Mutex
+
+	up               prometheus.Gauge
+	lastRefresh      prometheus.Gauge
+	refreshFailures  prometheus.Counter
+	rateLimited      *prometheus.CounterVec
+	portfolioValue   *prometheus.GaugeVec
+	portfolioInvest  *prometheus.GaugeVec
+	cash             *prometheus.GaugeVec
+	unsettled        prometheus.Gauge
+	holdingValue     *prometheus.GaugeVec
+	holdingInvest    *prometheus.GaugeVec
+	holdingGain      *prometheus.GaugeVec
+	holdingUnits     *prometheus.GaugeVec
+	holdingPrice     *prometheus.GaugeVec
+	holdingDayChange *prometheus.GaugeVec
+}
+
+func newMetrics() *metrics {
+	gauge := func(name, help string) prometheus.Gauge {
+		return prometheus.NewGauge(prometheus.GaugeOpts{Namespace: namespace, Name: name, Help: help})
+	}
+	gaugeVec := func(name, help string, labels ...string) *prometheus.GaugeVec {
+		return prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: namespace, Name: name, Help: help}, labels)
+	}
+
+	return &metrics{
+		up:          gauge("up", "Whether the last refresh from the Stockal API succeeded."),
+		lastRefresh: gauge("last_refresh_timestamp_seconds", "Unix time of the last successful refresh."),
+		refreshFailures: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace, Name: "refresh_failures_total", Help: "Number of failed refreshes.",
+		}),
+		rateLimited: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace, Name: "rate_limited_total", Help: "Number of requests the Stockal API rejected as rate-limited, by whether they were retried.",
+		}, []string{"outcome"}),
+		portfolioValue:   gaugeVec("portfolio_value_dollars", "Current market value of the portfolio.", "portfolio"),
+		portfolioInvest:  gaugeVec("portfolio_invested_dollars", "Amount invested in the portfolio.", "portfolio"),
+		cash:             gaugeVec("cash_dollars", "Cash balances by kind.", "kind"),
+		unsettled:        gauge("unsettled_dollars", "Funds awaiting settlement."),
+		holdingValue:     gaugeVec("holding_value_dollars", "Current market value of a holding.", "symbol", "category"),
+		holdingInvest:    gaugeVec("holding_invested_dollars", "Amount invested in a holding.", "symbol", "category"),
+		holdingGain:      gaugeVec("holding_gain_dollars", "Unrealized profit or loss of a holding.", "symbol", "category"),
+		holdingUnits:     gaugeVec("holding_units", "Number of units held.", "symbol", "category"),
+		holdingPrice:     gaugeVec("holding_price_dollars", "Current price per unit.", "symbol", "category"),
+		holdingDayChange: gaugeVec("holding_day_change_dollars", "Change in a holding's value since the prior close.", "symbol", "category"),
+	}
+}
+
+func (m *metrics) collectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		m.up, m.lastRefresh, m.refreshFailures, m.rateLimited,
+		m.portfolioValue, m.portfolioInvest, m.cash, m.unsettled,
+		m.holdingValue, m.holdingInvest, m.holdingGain, m.holdingUnits, m.holdingPrice, m.holdingDayChange,
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (m *metrics) Describe(ch chan<- *prometheus.Desc) {
+	for _, c := range m.collectors() {
+		c.Describe(ch)
+	}
+}
+
+// Collect implements prometheus.Collector.
+func (m *metrics) Collect(ch chan<- prometheus.Metric) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, c := range m.collectors() {
+		c.Collect(ch)
+	}
+}
+
+// rateLimitHit counts and logs a rate-limited request; it is the client's
+// rate limit hook.
+func (m *metrics) rateLimitHit(e stockal.RateLimitEvent) {
+	if e.Wait > 0 {
+		m.rateLimited.WithLabelValues("retried").Inc()
+		log.Printf("rate limited on %s %s; retrying in %s", e.Method, e.Endpoint, e.Wait)
+		return
+	}
+	m.rateLimited.WithLabelValues("failed").Inc()
+	log.Printf("rate limited on %s %s: %v", e.Method, e.Endpoint, e.Err)
+}
+
+// update replaces the metrics with the values in snapshot.
+func (m *metrics) update(s *stockal.Snapshot) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	p := s.Summary.PortfolioSummary
+	for name, portfolio := range map[string]stockal.Portfolio{
+		"stock": p.StockPortfolio,
+		"stack": p.StackPortfolio,
+		"etf":   p.ETFPortfolio,
+		"total": {CurrentValue: p.TotalCurrentValue, InvestmentAmount: p.TotalInvestmentAmount},
+	} {
+		m.portfolioValue.WithLabelValues(name).Set(portfolio.CurrentValue)
+		m.portfolioInvest.WithLabelValues(name).Set(portfolio.InvestmentAmount)
+	}
+
+	a := s.Summary.AccountSummary
+	m.cash.WithLabelValues("balance").Set(a.CashBalance)
+	m.cash.WithLabelValues("available_for_trade").Set(a.CashAvailableForTrade)
+	m.cash.WithLabelValues("available_for_withdrawal").Set(a.CashAvailableForWithdrawal)
+	m.unsettled.Set(s.Summary.UnsettledAmount)
+
+	// Reset so that holdings which were sold stop being exported.
+	for _, v := range []*prometheus.GaugeVec{m.holdingValue, m.holdingInvest, m.holdingGain, m.holdingUnits, m.holdingPrice, m.holdingDayChange} {
+		v.Reset()
+	}
+	for _, h := range s.Holdings {
+		m.holdingValue.WithLabelValues(h.Symbol, string(h.Category)).Set(h.CurrentValue())
+		m.holdingInvest.WithLabelValues(h.Symbol, string(h.Category)).Set(h.TotalInvestment)
+		m.holdingGain.WithLabelValues(h.Symbol, string(h.Category)).Set(h.UnrealizedGain())
+		m.holdingUnits.WithLabelValues(h.Symbol, string(h.Category)).Set(h.TotalUnit)
+		m.holdingPrice.WithLabelValues(h.Symbol, string(h.Category)).Set(h.Price)
+		if h.PriorClose != 0 {
+			m.holdingDayChange.WithLabelValues(h.Symbol, string(h.Category)).Set(h.DayChange())
+		}
+	}
+
+	m.up.Set(1)
+	m.lastRefresh.SetToCurrentTime()
+}
+
+// failed records a failed refresh. Previous values are kept so that dashboards
+// do not gap on a transient outage; alert on stockal_up instead.
+func (m *metrics) failed() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.up.Set(0)
+	m.refreshFailures.Inc()
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+)
+
+// snapshot returns a snapshot holding AAPL, which rose, and VOO, which has no
+// prior close.
+func snapshot() *stockal.Snapshot {
+	s := &stockal.Snapshot{TakenAt: time.Date(2024, 5, 1, 20, 0, 0, 0, time.UTC)}
+	s.Summary.PortfolioSummary = stockal.PortfolioSummary{
+		StockPortfolio:        stockal.Portfolio{CurrentValue: 1900, InvestmentAmount: 1500},
+		ETFPortfolio:          stockal.Portfolio{CurrentValue: 1000, InvestmentAmount: 1100},
+		TotalCurrentValue:     2900,
+		TotalInvestmentAmount: 2600,
+	}
+	s.Summary.AccountSummary = stockal.AccountSummary{CashBalance: 300, CashAvailableForTrade: 250, CashAvailableForWithdrawal: 200}
+	s.Summary.UnsettledAmount = 50
+	s.Holdings = []stockal.Holding{
+		{Symbol: "AAPL", Category: stockal.CategoryStock, TotalUnit: 10, Price: 190, PriorClose: 180, TotalInvestment: 1500},
+		{Symbol: "VOO", Category: stockal.CategoryETF, TotalUnit: 2, Price: 500, TotalInvestment: 1100},
+	}
+	return s
+}
+
+// registry returns a registry exporting m.
+func registry(m *metrics) *prometheus.Registry {
+	r := prometheus.NewRegistry()
+	r.MustRegister(m)
+	return r
+}
+
+func TestUpdate(t *testing.T) {
+	m := newMetrics()
+	r := registry(m)
+	if n, err := testutil.GatherAndCount(r); err != nil || n != 4 {
+		t.Errorf("%d metrics before a refresh, %v; want up, the refresh time and failures, and unsettled funds", n, err)
+	}
+	before := time.Now()
+	m.update(snapshot())
+
+	want := `# HELP stockal_cash_dollars Cash balances by kind.
+# TYPE stockal_cash_dollars gauge
+stockal_cash_dollars{kind="available_for_trade"} 250
+stockal_cash_dollars{kind="available_for_withdrawal"} 200
+stockal_cash_dollars{kind="balance"} 300
+# HELP stockal_holding_day_change_dollars Change in a holding's value since the prior close.
+# TYPE stockal_holding_day_change_dollars gauge
+stockal_holding_day_change_dollars{category="stock",symbol="AAPL"} 100
+# HELP stockal_holding_gain_dollars Unrealized profit or loss of a holding.
+# TYPE stockal_holding_gain_dollars gauge
+stockal_holding_gain_dollars{category="etf",symbol="VOO"} -100
+stockal_holding_gain_dollars{category="stock",symbol="AAPL"} 400
+# HELP stockal_holding_invested_dollars Amount invested in a holding.
+# TYPE stockal_holding_invested_dollars gauge
+stockal_holding_invested_dollars{category="etf",symbol="VOO"} 1100
+stockal_holding_invested_dollars{category="stock",symbol="AAPL"} 1500
+# HELP stockal_holding_price_dollars Current price per unit.
+# TYPE stockal_holding_price_dollars gauge
+stockal_holding_price_dollars{category="etf",symbol="VOO"} 500
+stockal_holding_price_dollars{category="stock",symbol="AAPL"} 190
+# HELP stockal_holding_units Number of units held.
+# TYPE stockal_holding_units gauge
+stockal_holding_units{category="etf",symbol="VOO"} 2
+stockal_holding_units{category="stock",symbol="AAPL"} 10
+# HELP stockal_holding_value_dollars Current market value of a holding.
+# TYPE stockal_holding_value_dollars gauge
+stockal_holding_value_dollars{category="etf",symbol="VOO"} 1000
+stockal_holding_value_dollars{category="stock",symbol="AAPL"} 1900
+# HELP stockal_portfolio_invested_dollars Amount invested in the portfolio.
+# TYPE stockal_portfolio_invested_dollars gauge
+stockal_portfolio_invested_dollars{portfolio="etf"} 1100
+stockal_portfolio_invested_dollars{portfolio="stack"} 0
+stockal_portfolio_invested_dollars{portfolio="stock"} 1500
+stockal_portfolio_invested_dollars{portfolio="total"} 2600
+# HELP stockal_portfolio_value_dollars Current market value of the portfolio.
+# TYPE stockal_portfolio_value_dollars gauge
+stockal_portfolio_value_dollars{portfolio="etf"} 1000
+stockal_portfolio_value_dollars{portfolio="stack"} 0
+stockal_portfolio_value_dollars{portfolio="stock"} 1900
+stockal_portfolio_value_dollars{portfolio="total"} 2900
+# HELP stockal_refresh_failures_total Number of failed refreshes.
+# TYPE stockal_refresh_failures_total counter
+stockal_refresh_failures_total 0
+# HELP stockal_unsettled_dollars Funds awaiting settlement.
+# TYPE stockal_unsettled_dollars gauge
+stockal_unsettled_dollars 50
+# HELP stockal_up Whether the last refresh from the Stockal API succeeded.
+# TYPE stockal_up gauge
+stockal_up 1
+`
+	// All but the refresh time, which is checked below
+	names := []string{"stockal_cash_dollars", "stockal_holding_day_change_dollars", "stockal_holding_gain_dollars",
+		"stockal_holding_invested_dollars", "stockal_holding_price_dollars", "stockal_holding_units",
+		"stockal_holding_value_dollars", "stockal_portfolio_invested_dollars", "stockal_portfolio_value_dollars",
+		"stockal_rate_limited_total", "stockal_refresh_failures_total", "stockal_unsettled_dollars", "stockal_up"}
+	if err := testutil.GatherAndCompare(r, strings.NewReader(want), names...); err != nil {
+		t.Error(err)
+	}
+	if refreshed := testutil.ToFloat64(m.lastRefresh); refreshed < float64(before.Unix()) || refreshed > float64(time.Now().Unix()+1) {
+		t.Errorf("last refresh %v, want now", refreshed)
+	}
+
+	// Holdings sold stop being exported
+	s := snapshot()
+	s.Holdings = s.Holdings[:1]
+	m.update(s)
+	if n := testutil.CollectAndCount(m.holdingValue); n != 1 {
+		t.Errorf("%d holding values exported, want only AAPL's", n)
+	}
+}
+
+func TestFailed(t *testing.T) {
+	m := newMetrics()
+	m.update(snapshot())
+	m.failed()
+	m.failed()
+	if up, failures := testutil.ToFloat64(m.up), testutil.ToFloat64(m.refreshFailures); up != 0 || failures != 2 {
+		t.Errorf("up %v, %v failures; want 0, 2", up, failures)
+	}
+	// Values are kept through an outage
+	if value := testutil.ToFloat64(m.portfolioValue.WithLabelValues("total")); value != 2900 {
+		t.Errorf("portfolio value %v after a failure, want 2900", value)
+	}
+}
+
+func TestRateLimitHit(t *testing.T) {
+	m := newMetrics()
+	m.rateLimitHit(stockal.RateLimitEvent{Method: "GET", Endpoint: "/v2/portfolio", Wait: time.Second})
+	m.rateLimitHit(stockal.RateLimitEvent{Method: "GET", Endpoint: "/v2/portfolio", Wait: time.Second})
+	m.rateLimitHit(stockal.RateLimitEvent{Method: "GET", Endpoint: "/v2/portfolio", Err: &stockal.RateLimitError{RetryAfter: time.Minute}})
+
+	want := `# HELP stockal_rate_limited_total Number of requests the Stockal API rejected as rate-limited, by whether they were retried.
+# TYPE stockal_rate_limited_total counter
+stockal_rate_limited_total{outcome="failed"} 1
+stockal_rate_limited_total{outcome="retried"} 2
+`
+	if err := testutil.GatherAndCompare(registry(m), strings.NewReader(want), "stockal_rate_limited_total"); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,79 @@
+// Command stockal-mcp is a Model Context Protocol server that lets AI
+// assistants answer questions about a Stockal account.
+//
+// It speaks MCP over stdin and stdout and logs in with the STOCKAL_USERNAME
+// and STOCKAL_PASSWORD environment variables. Register it with an assistant
+// as a local (stdio) server, for example:
+//
+//	{"mcpServers": {"stockal": {"command": "stockal-mcp", "env": {...}}}}
+//
+// Tools:
+//
+//	get_portfolio     account totals, cash and holdings
+//	get_quote         latest quotes for up to 50 symbols
+//	get_transactions  executed trades, newest first
+//	preview_order     estimate an order's cost, with -order-tools
+//
+// The server never places, modifies or cancels orders. preview_order only
+// validates an order and estimates its cash impact, and is not offered unless
+// -order-tools is given.
+//
+// Credentials and tokens missing from the environment are read from the
+// backend given with -secrets or STOCKAL_SECRETS, such as a directory of
+// files or AWS Secrets Manager; see package secrets.
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+	"github.com/adjaecent/unofficial-stockal-api/secrets"
+)
+
+// Environment variables holding the account credentials.
+const (
+	envUsername = "STOCKAL_USERNAME"
+	envPassword = "STOCKAL_PASSWORD"
+)
+
+func main() {
+	var (
+		baseURL     = flag.String("base-url", stockal.BaseURL, "Stockal API base URL")
+		timeout     = flag.Duration("timeout", stockal.DefaultTimeout, "HTTP timeout for API requests")
+		orderTools  = flag.Bool("order-tools", false, "offer preview_order (orders are never submitted)")
+		secretsSpec = flag.String("secrets", os.Getenv(secrets.EnvSpec), "where to read credentials from besides the environment: file:DIR, aws-sm:PREFIX, aws-sm-json:SECRET or gcp-sm:PROJECT")
+	)
+	flag.Parse()
+	log.SetPrefix("stockal-mcp: ")
+
+	secret := secrets.Lookup(*secretsSpec)
+	username, password := secret(envUsername), secret(envPassword)
+	if username == "" || password == "" {
+		log.Fatalf("set %s and %s", envUsername, envPassword)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	tools := &tools{
+		client:   stockal.NewClient(stockal.WithBaseURL(*baseURL), stockal.WithTimeout(*timeout)),
+		username: username,
+		password: password,
+	}
+	server := mcp.NewServer(&mcp.Implementation{Name: "stockal", Title: "Stockal", Version: "1.0"}, &mcp.ServerOptions{
+		Instructions: "Read-only access to the user's Stockal (US stocks) brokerage account. " +
+			"Amounts are in US dollars. No tool can place, modify or cancel orders.",
+	})
+	tools.register(server, *orderTools)
+
+	if err := server.Run(ctx, &mcp.StdioTransport{}); err != nil && ctx.Err() == nil {
+		log.Fatal(err)
+	}
+}
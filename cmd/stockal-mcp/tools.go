@@ -0,0 +1,375 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+)
+
+// maxQuoteSymbols caps the symbols accepted by one get_quote call.
+const maxQuoteSymbols = 50
+
+// tools implements the MCP tools on top of one logged-in client.
+type tools struct {
+	username, password string
+
+	// mu serializes use of client, whose session is refreshed in place.
+	mu     sync.Mutex
+	client stockal.StockalClient
+}
+
+func (t *tools) register(server *mcp.Server, orderTools bool) {
+	readOnly := &mcp.ToolAnnotations{ReadOnlyHint: true}
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "get_portfolio",
+		Description: "Get the account's total value, invested amount, gain, cash available for trading and every holding with its value and gain.",
+		Annotations: readOnly,
+	}, t.getPortfolio)
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "get_quote",
+		Description: "Get the latest price, day range and change for up to 50 US stock or ETF symbols.",
+		Annotations: readOnly,
+	}, t.getQuote)
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "get_transactions",
+		Description: "List executed trades (purchases and sales), newest first, optionally for one symbol or since a date.",
+		Annotations: readOnly,
+	}, t.getTransactions)
+	if orderTools {
+		mcp.AddTool(server, &mcp.Tool{
+			Name: "preview_order",
+			Description: "Validate a buy or sell order and estimate its cost from the latest price, checking it against " +
+				"available cash or shares held. The order is NOT submitted; the user must place it themselves.",
+			Annotations: readOnly,
+		}, t.previewOrder)
+	}
+}
+
+type portfolioInput struct{}
+
+type portfolioOutput struct {
+	AsOf                  time.Time     `json:"asOf"`
+	TotalValue            float64       `json:"totalValue" jsonschema:"current value of all holdings in USD"`
+	TotalInvested         float64       `json:"totalInvested" jsonschema:"amount invested in current holdings in USD"`
+	TotalGain             float64       `json:"totalGain" jsonschema:"unrealized gain or loss in USD"`
+	CashAvailableForTrade float64       `json:"cashAvailableForTrade" jsonschema:"cash that can be used to buy, in USD"`
+	CashBalance           float64       `json:"cashBalance" jsonschema:"cash balance including unsettled funds, in USD"`
+	Holdings              []holdingView `json:"holdings"`
+}
+
+type holdingView struct {
+	Symbol           string  `json:"symbol"`
+	Company          string  `json:"company"`
+	Category         string  `json:"category" jsonschema:"stock, etf or stack"`
+	Units            float64 `json:"units"`
+	Price            float64 `json:"price"`
+	Value            float64 `json:"value"`
+	Invested         float64 `json:"invested"`
+	Gain             float64 `json:"gain"`
+	GainPercent      float64 `json:"gainPercent"`
+	DayChangePercent float64 `json:"dayChangePercent"`
+}
+
+func (t *tools) getPortfolio(ctx context.Context, _ *mcp.CallToolRequest, _ portfolioInput) (*mcp.CallToolResult, portfolioOutput, error) {
+	var snapshot *stockal.Snapshot
+	err := t.call(ctx, func(ctx context.Context) (err error) {
+		snapshot, err = stockal.TakeSnapshot(ctx, t.client)
+		return err
+	})
+	if err != nil {
+		return nil, portfolioOutput{}, err
+	}
+
+	totals := snapshot.Summary.PortfolioSummary
+	out := portfolioOutput{
+		AsOf:                  snapshot.TakenAt,
+		TotalValue:            totals.TotalCurrentValue,
+		TotalInvested:         totals.TotalInvestmentAmount,
+		TotalGain:             totals.TotalGain(),
+		CashAvailableForTrade: snapshot.Summary.AccountSummary.CashAvailableForTrade,
+		CashBalance:           snapshot.Summary.AccountSummary.CashBalance,
+		Holdings:              make([]holdingView, 0, len(snapshot.Holdings)),
+	}
+	for _, h := range snapshot.Holdings {
+		out.Holdings = append(out.Holdings, holdingView{
+			Symbol:           h.Symbol,
+			Company:          h.Company,
+			Category:         string(h.Category),
+			Units:            h.TotalUnit,
+			Price:            h.Price,
+			Value:            h.CurrentValue(),
+			Invested:         h.TotalInvestment,
+			Gain:             h.UnrealizedGain(),
+			GainPercent:      h.GainPercent(),
+			DayChangePercent: h.DayChangePercent(),
+		})
+	}
+	return nil, out, nil
+}
+
+type quoteInput struct {
+	Symbols []string `json:"symbols" jsonschema:"stock or ETF symbols, e.g. AAPL"`
+}
+
+type quoteOutput struct {
+	Quotes []stockal.Quote `json:"quotes"`
+	// Unknown lists requested symbols Stockal returned no quote for
+	Unknown []string `json:"unknown,omitempty"`
+}
+
+func (t *tools) getQuote(ctx context.Context, _ *mcp.CallToolRequest, in quoteInput) (*mcp.CallToolResult, quoteOutput, error) {
+	symbols, err := normalizeSymbols(in.Symbols)
+	if err != nil {
+		return nil, quoteOutput{}, err
+	}
+
+	var resp *stockal.QuotesResponse
+	err = t.call(ctx, func(ctx context.Context) (err error) {
+		resp, err = t.client.GetQuotes(ctx, symbols...)
+		return err
+	})
+	if err != nil {
+		return nil, quoteOutput{}, err
+	}
+
+	out := quoteOutput{Quotes: resp.Data}
+	for _, sym := range symbols {
+		if _, ok := resp.Quote(sym); !ok {
+			out.Unknown = append(out.Unknown, sym)
+		}
+	}
+	return nil, out, nil
+}
+
+type transactionsInput struct {
+	Symbol string `json:"symbol,omitempty" jsonschema:"only trades in this symbol"`
+	Since  string `json:"since,omitempty" jsonschema:"only trades on or after this date, YYYY-MM-DD"`
+	Limit  int    `json:"limit,omitempty" jsonschema:"maximum number of trades to return, default 50"`
+}
+
+type transactionsOutput struct {
+	Transactions []transaction `json:"transactions"`
+	// Truncated reports whether more trades matched than were returned
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+type transaction struct {
+	OrderID  string            `json:"orderId"`
+	Time     string            `json:"time"`
+	Symbol   string            `json:"symbol"`
+	Side     stockal.OrderSide `json:"side"`
+	Quantity float64           `json:"quantity" jsonschema:"shares traded"`
+	Price    float64           `json:"price" jsonschema:"price per share in USD"`
+	Amount   float64           `json:"amount" jsonschema:"value of the trade in USD"`
+}
+
+func (t *tools) getTransactions(ctx context.Context, _ *mcp.CallToolRequest, in transactionsInput) (*mcp.CallToolResult, transactionsOutput, error) {
+	var since time.Time
+	if in.Since != "" {
+		var err error
+		if since, err = time.Parse(time.DateOnly, in.Since); err != nil {
+			return nil, transactionsOutput{}, fmt.Errorf("invalid since %q (want YYYY-MM-DD)", in.Since)
+		}
+	}
+	limit := in.Limit
+	if limit <= 0 {
+		limit = 50
+	}
+
+	var trades []stockal.Transaction
+	err := t.call(ctx, func(ctx context.Context) (err error) {
+		trades, err = stockal.AllTransactions(ctx, t.client, stockal.TransactionOptions{
+			Types:  []stockal.TransactionType{stockal.TransactionBuy, stockal.TransactionSell},
+			Symbol: in.Symbol,
+			From:   since,
+		})
+		return err
+	})
+	if err != nil {
+		return nil, transactionsOutput{}, err
+	}
+
+	type executed struct {
+		transaction
+		at time.Time
+	}
+	var sorted []executed
+	for _, tx := range trades {
+		side := stockal.OrderSideBuy
+		if tx.Type == stockal.TransactionSell {
+			side = stockal.OrderSideSell
+		}
+		// A time that does not parse is passed on as sent, and sorts last
+		at := tx.Date.Raw
+		if !tx.Date.IsZero() {
+			at = tx.Date.UTC().Format(time.RFC3339)
+		}
+		sorted = append(sorted, executed{transaction{
+			OrderID:  tx.OrderID,
+			Time:     at,
+			Symbol:   tx.Symbol,
+			Side:     side,
+			Quantity: tx.Quantity,
+			Price:    tx.Price,
+			Amount:   math.Abs(tx.Amount),
+		}, tx.Date.Time})
+	}
+	slices.SortStableFunc(sorted, func(a, b executed) int { return b.at.Compare(a.at) })
+
+	out := transactionsOutput{Transactions: make([]transaction, len(sorted))}
+	for i, e := range sorted {
+		out.Transactions[i] = e.transaction
+	}
+	if len(out.Transactions) > limit {
+		out.Transactions, out.Truncated = out.Transactions[:limit], true
+	}
+	return nil, out, nil
+}
+
+type orderInput struct {
+	Symbol     string  `json:"symbol"`
+	Side       string  `json:"side" jsonschema:"buy or sell"`
+	Quantity   float64 `json:"quantity,omitempty" jsonschema:"number of shares; set this or amount"`
+	Amount     float64 `json:"amount,omitempty" jsonschema:"dollar amount; set this or quantity"`
+	LimitPrice float64 `json:"limitPrice,omitempty" jsonschema:"limit price; omit for a market order"`
+}
+
+type orderPreview struct {
+	Order stockal.OrderRequest `json:"order"`
+	// Submitted is always false; this server never places orders
+	Submitted     bool     `json:"submitted"`
+	LastPrice     float64  `json:"lastPrice,omitempty"`
+	EstimatedCost float64  `json:"estimatedCost" jsonschema:"estimated value of the order in USD"`
+	CashAvailable float64  `json:"cashAvailable"`
+	SharesHeld    float64  `json:"sharesHeld"`
+	Warnings      []string `json:"warnings,omitempty"`
+}
+
+func (t *tools) previewOrder(ctx context.Context, _ *mcp.CallToolRequest, in orderInput) (*mcp.CallToolResult, orderPreview, error) {
+	order := stockal.OrderRequest{
+		Symbol:     strings.ToUpper(strings.TrimSpace(in.Symbol)),
+		Side:       stockal.OrderSide(strings.ToLower(in.Side)),
+		Type:       stockal.OrderTypeMarket,
+		Quantity:   in.Quantity,
+		Amount:     in.Amount,
+		LimitPrice: in.LimitPrice,
+	}
+	if in.LimitPrice > 0 {
+		order.Type = stockal.OrderTypeLimit
+	}
+	if err := order.Validate(); err != nil {
+		return nil, orderPreview{}, err
+	}
+
+	var (
+		quotes    *stockal.QuotesResponse
+		summary   *stockal.AccountSummaryResponse
+		portfolio *stockal.PortfolioDetailResponse
+	)
+	err := t.call(ctx, func(ctx context.Context) (err error) {
+		if quotes, err = t.client.GetQuotes(ctx, order.Symbol); err != nil {
+			return err
+		}
+		if summary, err = t.client.Account().Summary(ctx); err != nil {
+			return err
+		}
+		portfolio, err = t.client.Portfolio().Detail(ctx)
+		return err
+	})
+	if err != nil {
+		return nil, orderPreview{}, err
+	}
+
+	out := orderPreview{Order: order, CashAvailable: summary.Data.AccountSummary.CashAvailableForTrade}
+	for _, h := range portfolio.Data.Holdings {
+		if strings.EqualFold(h.Symbol, order.Symbol) {
+			out.SharesHeld += h.TotalUnit
+			if h.SellOnly && order.Side == stockal.OrderSideBuy {
+				out.Warnings = append(out.Warnings, order.Symbol+" is sell-only for this account")
+			}
+		}
+	}
+	if q, ok := quotes.Quote(order.Symbol); ok {
+		out.LastPrice = q.Price
+	} else {
+		out.Warnings = append(out.Warnings, "no quote for "+order.Symbol+"; check the symbol")
+	}
+
+	price := out.LastPrice
+	if order.Type == stockal.OrderTypeLimit {
+		price = order.LimitPrice
+	}
+	switch {
+	case order.Amount > 0:
+		out.EstimatedCost = order.Amount
+	case price > 0:
+		out.EstimatedCost = order.Quantity * price
+	}
+
+	switch order.Side {
+	case stockal.OrderSideBuy:
+		if out.EstimatedCost > out.CashAvailable {
+			out.Warnings = append(out.Warnings, fmt.Sprintf("estimated cost $%.2f exceeds cash available $%.2f", out.EstimatedCost, out.CashAvailable))
+		}
+	case stockal.OrderSideSell:
+		shares := order.Quantity
+		if order.Amount > 0 && price > 0 {
+			shares = order.Amount / price
+		}
+		if shares > out.SharesHeld {
+			out.Warnings = append(out.Warnings, fmt.Sprintf("selling %g shares but %g are held", shares, out.SharesHeld))
+		}
+	}
+	return nil, out, nil
+}
+
+// call runs fn with the client, logging in first if there is no session and
+// again once if the session has expired.
+func (t *tools) call(ctx context.Context, fn func(context.Context) error) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	err := fn(ctx)
+	if err == nil || !sessionError(err) {
+		return err
+	}
+	if _, err := t.client.Auth().Login(ctx, t.username, t.password); err != nil {
+		return err
+	}
+	return fn(ctx)
+}
+
+// sessionError reports whether err means the client needs to log in. A
+// Cloudflare challenge is forbidden too, but logging in again does not pass it.
+func sessionError(err error) bool {
+	return errors.Is(err, stockal.ErrNotAuthenticated) || errors.Is(err, stockal.ErrTokenExpired) ||
+		errors.Is(err, stockal.ErrForbidden) && !errors.Is(err, stockal.ErrUpstreamUnavailable)
+}
+
+// normalizeSymbols upper-cases and deduplicates symbols.
+func normalizeSymbols(raw []string) ([]string, error) {
+	var symbols []string
+	for _, sym := range raw {
+		sym = strings.ToUpper(strings.TrimSpace(sym))
+		if sym != "" && !slices.Contains(symbols, sym) {
+			symbols = append(symbols, sym)
+		}
+	}
+	if len(symbols) == 0 {
+		return nil, errors.New("at least one symbol is required")
+	}
+	if len(symbols) > maxQuoteSymbols {
+		return nil, fmt.Errorf("at most %d symbols per call", maxQuoteSymbols)
+	}
+	return symbols, nil
+}
@@ -0,0 +1,311 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+	"github.com/adjaecent/unofficial-stockal-api/stockaltest"
+)
+
+// connect serves t's tools in memory and returns a client session to them.
+func connect(t *testing.T, tl *tools, orderTools bool) *mcp.ClientSession {
+	t.Helper()
+	ctx := context.Background()
+	server := mcp.NewServer(&mcp.Implementation{Name: "stockal", Version: "test"}, nil)
+	tl.register(server, orderTools)
+
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	ss, err := server.Connect(ctx, serverTransport, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ss.Close() })
+	client := mcp.NewClient(&mcp.Implementation{Name: "test", Version: "test"}, nil)
+	cs, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { cs.Close() })
+	return cs
+}
+
+func TestRegisterOrderTools(t *testing.T) {
+	for _, orderTools := range []bool{false, true} {
+		cs := connect(t, &tools{client: &stockaltest.MockClient{}}, orderTools)
+		list, err := cs.ListTools(context.Background(), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var names []string
+		for _, tool := range list.Tools {
+			names = append(names, tool.Name)
+			if tool.Annotations == nil || !tool.Annotations.ReadOnlyHint {
+				t.Errorf("%s is not marked read-only", tool.Name)
+			}
+		}
+		want := []string{"get_portfolio", "get_quote", "get_transactions"}
+		if orderTools {
+			want = append(want, "preview_order")
+		}
+		slices.Sort(names)
+		if !slices.Equal(names, want) {
+			t.Errorf("with order tools %v: tools %q, want %q", orderTools, names, want)
+		}
+	}
+
+	cs := connect(t, &tools{client: &stockaltest.MockClient{}}, false)
+	res, err := cs.CallTool(context.Background(), &mcp.CallToolParams{
+		Name:      "preview_order",
+		Arguments: map[string]any{"symbol": "AAPL", "side": "buy", "quantity": 1},
+	})
+	if err == nil && !res.IsError {
+		t.Error("preview_order ran without -order-tools")
+	}
+}
+
+func TestGetPortfolio(t *testing.T) {
+	tl := &tools{client: &stockaltest.MockClient{}}
+	_, out, err := tl.getPortfolio(context.Background(), nil, portfolioInput{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.TotalValue != 7789.14 || out.TotalInvested != 6850 || !near(out.TotalGain, 939.14) ||
+		out.CashAvailableForTrade != 1250.75 || out.CashBalance != 1350.75 {
+		t.Errorf("totals %+v, want the account summary's", out)
+	}
+	if len(out.Holdings) != 3 {
+		t.Fatalf("holdings %+v, want AAPL, MSFT and VOO", out.Holdings)
+	}
+	aapl := out.Holdings[0]
+	if aapl.Symbol != "AAPL" || aapl.Company != "Apple Inc." || aapl.Category != "stock" || aapl.Units != 10 ||
+		aapl.Price != 213.49 || !near(aapl.Value, 2134.9) || aapl.Invested != 1500 || !near(aapl.Gain, 634.9) ||
+		!near(aapl.GainPercent, 42.3267) || !near(aapl.DayChangePercent, 1.8171) {
+		t.Errorf("AAPL %+v, want its value and gains", aapl)
+	}
+	if voo := out.Holdings[2]; voo.Category != "etf" {
+		t.Errorf("VOO category %q, want etf", voo.Category)
+	}
+}
+
+func TestGetQuote(t *testing.T) {
+	cs := connect(t, &tools{client: &stockaltest.MockClient{}}, false)
+
+	res, err := cs.CallTool(context.Background(), &mcp.CallToolParams{
+		Name:      "get_quote",
+		Arguments: map[string]any{"symbols": []string{" aapl", "AAPL", "nope", "voo"}},
+	})
+	if err != nil || res.IsError {
+		t.Fatalf("get_quote = %+v, %v", res, err)
+	}
+	var out quoteOutput
+	data, _ := json.Marshal(res.StructuredContent)
+	if err := json.Unmarshal(data, &out); err != nil {
+		t.Fatalf("structured content %s: %v", data, err)
+	}
+	var symbols []string
+	for _, q := range out.Quotes {
+		symbols = append(symbols, q.Symbol)
+	}
+	if !slices.Equal(symbols, []string{"AAPL", "VOO"}) || !slices.Equal(out.Unknown, []string{"NOPE"}) {
+		t.Errorf("quotes for %q, unknown %q; want AAPL and VOO with NOPE unknown", symbols, out.Unknown)
+	}
+
+	var tooMany []string
+	for i := range maxQuoteSymbols + 1 {
+		tooMany = append(tooMany, fmt.Sprintf("S%d", i))
+	}
+	for _, symbols := range [][]string{nil, {" ", ""}, tooMany} {
+		res, err := cs.CallTool(context.Background(), &mcp.CallToolParams{
+			Name:      "get_quote",
+			Arguments: map[string]any{"symbols": symbols},
+		})
+		if err == nil && !res.IsError {
+			t.Errorf("get_quote of %d symbols succeeded", len(symbols))
+		}
+	}
+}
+
+func TestGetTransactions(t *testing.T) {
+	trades := []stockal.Transaction{
+		{ID: "t1", Type: stockal.TransactionBuy, Symbol: "MSFT", Quantity: 4, Price: 437.5, Amount: -1750, OrderID: "o1", Date: stockal.NewTime("2024-11-04T14:45:31Z")},
+		{ID: "t2", Type: stockal.TransactionSell, Symbol: "AAPL", Quantity: 2, Price: 210, Amount: 420, OrderID: "o2", Date: stockal.NewTime("2025-03-12T15:00:00Z")},
+		{ID: "t3", Type: stockal.TransactionBuy, Symbol: "VOO", Quantity: 1, Price: 505, Amount: -505, OrderID: "o3", Date: stockal.NewTime("sometime")},
+		{ID: "t4", Type: stockal.TransactionBuy, Symbol: "VOO", Quantity: 0.9901, Price: 505, Amount: -500, OrderID: "o4", Date: stockal.NewTime("2025-03-10T14:31:07Z")},
+	}
+	var opts stockal.TransactionOptions
+	tl := &tools{client: &stockaltest.MockClient{
+		GetTransactionsFunc: func(ctx context.Context, o stockal.TransactionOptions) (*stockal.TransactionListResponse, error) {
+			opts = o
+			resp := &stockal.TransactionListResponse{}
+			resp.Data.Transactions, resp.Data.TotalRecords = trades, len(trades)
+			return resp, nil
+		},
+	}}
+
+	_, out, err := tl.getTransactions(context.Background(), nil, transactionsInput{Symbol: "VOO", Since: "2024-01-31", Limit: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opts.Symbol != "VOO" || opts.From.Format("2006-01-02") != "2024-01-31" ||
+		!slices.Equal(opts.Types, []stockal.TransactionType{stockal.TransactionBuy, stockal.TransactionSell}) {
+		t.Errorf("options %+v, want trades in VOO since 31 January", opts)
+	}
+	var ids []string
+	for _, tx := range out.Transactions {
+		ids = append(ids, tx.OrderID)
+	}
+	if !slices.Equal(ids, []string{"o2", "o4", "o1"}) || !out.Truncated {
+		t.Errorf("orders %q, truncated %v; want the newest three of four", ids, out.Truncated)
+	}
+	if sell := out.Transactions[0]; sell.Side != stockal.OrderSideSell || sell.Amount != 420 || sell.Time != "2025-03-12T15:00:00Z" {
+		t.Errorf("sale %+v", sell)
+	}
+	if buy := out.Transactions[2]; buy.Side != stockal.OrderSideBuy || buy.Amount != 1750 {
+		t.Errorf("purchase %+v, want a positive amount", buy)
+	}
+
+	_, out, err = tl.getTransactions(context.Background(), nil, transactionsInput{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(out.Transactions) != 4 || out.Truncated || out.Transactions[3].Time != "sometime" {
+		t.Errorf("transactions %+v, want all four with the unparsed time last", out.Transactions)
+	}
+
+	if _, _, err := tl.getTransactions(context.Background(), nil, transactionsInput{Since: "31/01/2024"}); err == nil {
+		t.Error("getTransactions accepted an invalid since")
+	}
+}
+
+func TestPreviewOrder(t *testing.T) {
+	tests := []struct {
+		name     string
+		in       orderInput
+		sellOnly bool
+		cost     float64
+		warnings []string
+	}{
+		{
+			name: "buy",
+			in:   orderInput{Symbol: " aapl ", Side: "BUY", Quantity: 5},
+			cost: 1067.45,
+		},
+		{
+			name:     "buy over cash",
+			in:       orderInput{Symbol: "AAPL", Side: "buy", Quantity: 10, LimitPrice: 200},
+			cost:     2000,
+			warnings: []string{"estimated cost $2000.00 exceeds cash available $1250.75"},
+		},
+		{
+			name: "buy amount",
+			in:   orderInput{Symbol: "VOO", Side: "buy", Amount: 500},
+			cost: 500,
+		},
+		{
+			name:     "sell over held",
+			in:       orderInput{Symbol: "MSFT", Side: "sell", Amount: 3885.6},
+			cost:     3885.6,
+			warnings: []string{"selling 10 shares but 4 are held"},
+		},
+		{
+			name:     "sell-only",
+			in:       orderInput{Symbol: "MSFT", Side: "buy", Quantity: 1},
+			sellOnly: true,
+			cost:     388.56,
+			warnings: []string{"MSFT is sell-only for this account"},
+		},
+		{
+			name:     "unknown symbol",
+			in:       orderInput{Symbol: "NOPE", Side: "buy", Quantity: 1},
+			warnings: []string{"no quote for NOPE; check the symbol"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &stockaltest.MockClient{
+				PortfolioDetailFunc: func(ctx context.Context) (*stockal.PortfolioDetailResponse, error) {
+					portfolio := stockaltest.Portfolio()
+					portfolio.Data.Holdings[1].SellOnly = tt.sellOnly
+					return portfolio, nil
+				},
+			}
+			_, out, err := (&tools{client: client}).previewOrder(context.Background(), nil, tt.in)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if out.Submitted || !near(out.EstimatedCost, tt.cost) || out.CashAvailable != 1250.75 {
+				t.Errorf("preview %+v, want an unsubmitted order costing %v", out, tt.cost)
+			}
+			if !slices.Equal(out.Warnings, tt.warnings) {
+				t.Errorf("warnings %q, want %q", out.Warnings, tt.warnings)
+			}
+			if len(client.CallsTo("Orders.Place")) != 0 {
+				t.Error("previewOrder placed the order")
+			}
+		})
+	}
+
+	for _, in := range []orderInput{{Symbol: "AAPL", Side: "hold", Quantity: 1}, {Symbol: "AAPL", Side: "buy"}, {Side: "buy", Quantity: 1}} {
+		if _, _, err := (&tools{client: &stockaltest.MockClient{}}).previewOrder(context.Background(), nil, in); err == nil {
+			t.Errorf("previewOrder(%+v) accepted an invalid order", in)
+		}
+	}
+}
+
+func TestCallLogsInAgain(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		logins int
+	}{
+		{name: "expired", err: stockal.ErrTokenExpired, logins: 1},
+		{name: "not authenticated", err: stockal.ErrNotAuthenticated, logins: 1},
+		{name: "cloudflare", err: &stockal.UpstreamError{StatusCode: 403, ContentType: "text/html", Body: "Just a moment..."}},
+		{name: "server error", err: &stockal.APIError{Code: 500, Message: "oops", StatusCode: 500}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var logins int
+			client := &stockaltest.MockClient{
+				AccountSummaryFunc: func(ctx context.Context) (*stockal.AccountSummaryResponse, error) {
+					if logins == 0 {
+						return nil, tt.err
+					}
+					return stockaltest.AccountSummary(), nil
+				},
+				AuthLoginFunc: func(ctx context.Context, username, password string) (*stockal.LoginResponse, error) {
+					if username != "alice" || password != "hunter2" {
+						return nil, errors.New("wrong credentials")
+					}
+					logins++
+					return stockaltest.Session(), nil
+				},
+			}
+			tl := &tools{client: client, username: "alice", password: "hunter2"}
+			_, _, err := tl.getPortfolio(context.Background(), nil, portfolioInput{})
+			if logins != tt.logins {
+				t.Errorf("logged in %d times, want %d", logins, tt.logins)
+			}
+			if tt.logins > 0 && err != nil {
+				t.Errorf("getPortfolio = %v, want it to succeed after logging in", err)
+			}
+			if tt.logins == 0 && (err == nil || !strings.Contains(err.Error(), tt.err.Error())) {
+				t.Errorf("getPortfolio = %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
+
+// near reports whether got rounds to want at four decimal places.
+func near(got, want float64) bool {
+	return math.Abs(got-want) < 5e-5
+}
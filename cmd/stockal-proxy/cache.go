@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+
+	"github.com/adjaecent/unofficial-stockal-api/cache"
+)
+
+// responseCache memoizes upstream results as JSON in a cache.Cache for a fixed
+// time. Concurrent misses for the same key in this process share one upstream
+// call.
+type responseCache struct {
+	ttl   time.Duration
+	store cache.Cache
+	group singleflight.Group
+}
+
+func newResponseCache(store cache.Cache, ttl time.Duration) *responseCache {
+	return &responseCache{ttl: ttl, store: store}
+}
+
+// get decodes the cached value for key into dst, calling fetch if it is
+// missing or stale. Errors are not cached, and a failing store only costs an
+// upstream call.
+func (c *responseCache) get(ctx context.Context, key string, dst any, fetch func() (any, error)) error {
+	data, err := c.store.Get(ctx, key)
+	if err == nil {
+		if err := json.Unmarshal(data, dst); err == nil {
+			return nil
+		}
+	} else if !errors.Is(err, cache.ErrMiss) {
+		log.Printf("cache: get %s: %v", key, err)
+	}
+
+	v, err, _ := c.group.Do(key, func() (any, error) {
+		v, err := fetch()
+		if err != nil {
+			return nil, err
+		}
+		data, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		if err := c.store.Set(context.WithoutCancel(ctx), key, data, c.ttl); err != nil {
+			log.Printf("cache: set %s: %v", key, err)
+		}
+		return data, nil
+	})
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(v.([]byte), dst)
+}
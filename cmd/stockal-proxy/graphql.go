@@ -0,0 +1,235 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/relay"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+)
+
+// graphqlSchema describes the /graphql endpoint. Each top-level field and
+// Holding.quote is resolved only when selected, so a query pays only for the
+// upstream calls it needs.
+const graphqlSchema = `
+schema {
+	query: Query
+}
+
+type Query {
+	# Account cash balances and portfolio totals.
+	summary: Summary!
+	# Holdings, optionally limited to the given symbols.
+	holdings(symbols: [String!]): [Holding!]!
+	# A single holding, or null if it is not held.
+	holding(symbol: String!): Holding
+	# Latest quotes for up to 50 symbols.
+	quotes(symbols: [String!]!): [Quote!]!
+}
+
+type Summary {
+	cashBalance: Float!
+	cashAvailableForTrade: Float!
+	cashAvailableForWithdrawal: Float!
+	unsettledAmount: Float!
+	totalValue: Float!
+	totalInvested: Float!
+	totalGain: Float!
+}
+
+type Holding {
+	symbol: String!
+	company: String!
+	category: String!
+	units: Float!
+	price: Float!
+	priorClose: Float!
+	value: Float!
+	invested: Float!
+	gain: Float!
+	# The latest quote, fetched in one batch for every holding in the query.
+	quote: Quote
+}
+
+type Quote {
+	symbol: String!
+	price: Float!
+	open: Float!
+	high: Float!
+	low: Float!
+	priorClose: Float!
+	volume: Float!
+	# RFC 3339 time of the last trade.
+	time: String
+}
+`
+
+// graphqlHandler returns the /graphql endpoint backed by s.
+func (s *server) graphqlHandler() *relay.Handler {
+	schema := graphql.MustParseSchema(graphqlSchema, &queryResolver{s: s},
+		graphql.MaxDepth(5),
+		graphql.MaxParallelism(4),
+	)
+	return &relay.Handler{Schema: schema}
+}
+
+type queryResolver struct {
+	s *server
+}
+
+func (q *queryResolver) Summary(ctx context.Context) (*summaryResolver, error) {
+	data, err := q.s.summary(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &summaryResolver{data}, nil
+}
+
+func (q *queryResolver) Holdings(ctx context.Context, args struct{ Symbols *[]string }) ([]*holdingResolver, error) {
+	data, err := q.s.portfolio(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var want map[string]bool
+	if args.Symbols != nil {
+		want = map[string]bool{}
+		for _, sym := range *args.Symbols {
+			want[strings.ToUpper(sym)] = true
+		}
+	}
+
+	quotes := &quoteLoader{s: q.s}
+	var holdings []*holdingResolver
+	for _, h := range data.Holdings {
+		if want == nil || want[h.Symbol] {
+			quotes.symbols = append(quotes.symbols, h.Symbol)
+			holdings = append(holdings, &holdingResolver{h: h, quotes: quotes})
+		}
+	}
+	return holdings, nil
+}
+
+func (q *queryResolver) Holding(ctx context.Context, args struct{ Symbol string }) (*holdingResolver, error) {
+	symbols := []string{args.Symbol}
+	holdings, err := q.Holdings(ctx, struct{ Symbols *[]string }{&symbols})
+	if err != nil || len(holdings) == 0 {
+		return nil, err
+	}
+	return holdings[0], nil
+}
+
+func (q *queryResolver) Quotes(ctx context.Context, args struct{ Symbols []string }) ([]*quoteResolver, error) {
+	symbols, err := parseSymbols(args.Symbols)
+	if err != nil {
+		return nil, err
+	}
+	quotes, err := q.s.quotes(ctx, symbols)
+	if err != nil {
+		return nil, err
+	}
+	resolvers := make([]*quoteResolver, len(quotes))
+	for i := range quotes {
+		resolvers[i] = &quoteResolver{quotes[i]}
+	}
+	return resolvers, nil
+}
+
+type summaryResolver struct {
+	d *stockal.AccountSummaryData
+}
+
+func (r *summaryResolver) CashBalance() float64 { return r.d.AccountSummary.CashBalance }
+func (r *summaryResolver) CashAvailableForTrade() float64 {
+	return r.d.AccountSummary.CashAvailableForTrade
+}
+func (r *summaryResolver) CashAvailableForWithdrawal() float64 {
+	return r.d.AccountSummary.CashAvailableForWithdrawal
+}
+func (r *summaryResolver) UnsettledAmount() float64 { return r.d.UnsettledAmount }
+func (r *summaryResolver) TotalValue() float64      { return r.d.PortfolioSummary.TotalCurrentValue }
+func (r *summaryResolver) TotalInvested() float64   { return r.d.PortfolioSummary.TotalInvestmentAmount }
+func (r *summaryResolver) TotalGain() float64       { return r.TotalValue() - r.TotalInvested() }
+
+type holdingResolver struct {
+	h      stockal.Holding
+	quotes *quoteLoader
+}
+
+func (r *holdingResolver) Symbol() string      { return r.h.Symbol }
+func (r *holdingResolver) Company() string     { return r.h.Company }
+func (r *holdingResolver) Category() string    { return string(r.h.Category) }
+func (r *holdingResolver) Units() float64      { return r.h.TotalUnit }
+func (r *holdingResolver) Price() float64      { return r.h.Price }
+func (r *holdingResolver) PriorClose() float64 { return r.h.PriorClose }
+func (r *holdingResolver) Value() float64      { return r.h.CurrentValue() }
+func (r *holdingResolver) Invested() float64   { return r.h.TotalInvestment }
+func (r *holdingResolver) Gain() float64       { return r.Value() - r.h.TotalInvestment }
+
+func (r *holdingResolver) Quote(ctx context.Context) (*quoteResolver, error) {
+	return r.quotes.load(ctx, r.h.Symbol)
+}
+
+// quoteLoader fetches quotes for every holding in a result the first time any
+// of them is asked for its quote, instead of one upstream call per holding.
+type quoteLoader struct {
+	s       *server
+	symbols []string
+
+	once   sync.Once
+	quotes map[string]stockal.Quote
+	err    error
+}
+
+func (l *quoteLoader) load(ctx context.Context, symbol string) (*quoteResolver, error) {
+	l.once.Do(func() {
+		l.quotes = map[string]stockal.Quote{}
+		for start := 0; start < len(l.symbols); start += maxQuoteSymbols {
+			batch, err := parseSymbols(l.symbols[start:min(start+maxQuoteSymbols, len(l.symbols))])
+			if err != nil {
+				l.err = err
+				return
+			}
+			quotes, err := l.s.quotes(ctx, batch)
+			if err != nil {
+				l.err = err
+				return
+			}
+			for _, q := range quotes {
+				l.quotes[q.Symbol] = q
+			}
+		}
+	})
+	if l.err != nil {
+		return nil, l.err
+	}
+	if q, ok := l.quotes[symbol]; ok {
+		return &quoteResolver{q}, nil
+	}
+	return nil, nil
+}
+
+type quoteResolver struct {
+	q stockal.Quote
+}
+
+func (r *quoteResolver) Symbol() string      { return r.q.Symbol }
+func (r *quoteResolver) Price() float64      { return r.q.Price }
+func (r *quoteResolver) Open() float64       { return r.q.Open }
+func (r *quoteResolver) High() float64       { return r.q.High }
+func (r *quoteResolver) Low() float64        { return r.q.Low }
+func (r *quoteResolver) PriorClose() float64 { return r.q.PriorClose }
+func (r *quoteResolver) Volume() float64     { return float64(r.q.Volume) }
+
+func (r *quoteResolver) Time() *string {
+	traded := r.q.TradedAt()
+	if traded.IsZero() {
+		return nil
+	}
+	t := traded.Format(time.RFC3339)
+	return &t
+}
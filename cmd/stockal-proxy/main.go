@@ -0,0 +1,121 @@
+// Command stockal-proxy serves read-only Stockal account data as plain JSON
+// for applications that cannot use the Go client directly.
+//
+// It logs in with the STOCKAL_USERNAME and STOCKAL_PASSWORD environment
+// variables and requires callers to present one of the keys listed in
+// STOCKAL_PROXY_API_KEYS (comma-separated), either as "Authorization: Bearer
+// <key>" or in the X-API-Key header:
+//
+//	STOCKAL_PROXY_API_KEYS=k1,k2 stockal-proxy -listen :8080 -ttl 30s
+//	curl -H "X-API-Key: k1" localhost:8080/portfolio
+//
+// Endpoints:
+//
+//	GET /summary                 account and portfolio totals
+//	GET /portfolio               holdings
+//	GET /quotes?symbols=AAPL,MSFT  latest quotes
+//	GET /healthz                 liveness, without authentication
+//	POST /graphql                GraphQL queries, with -graphql
+//
+// The GraphQL schema exposes the same data as a graph; a holding's quote is
+// fetched only when a query selects it. Responses are cached for -ttl so that many clients do not multiply the load
+// on the upstream API. With -redis the cache lives in Redis and is shared by
+// every instance pointed at the same server:
+//
+//	stockal-proxy -redis redis://localhost:6379/0 -ttl 15s
+//
+// Credentials and tokens missing from the environment are read from the
+// backend given with -secrets or STOCKAL_SECRETS, such as a directory of
+// files or AWS Secrets Manager; see package secrets.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+	"github.com/adjaecent/unofficial-stockal-api/cache"
+	"github.com/adjaecent/unofficial-stockal-api/cache/redis"
+	"github.com/adjaecent/unofficial-stockal-api/secrets"
+)
+
+// Environment variables holding the credentials and API keys.
+const (
+	envUsername = "STOCKAL_USERNAME"
+	envPassword = "STOCKAL_PASSWORD"
+	envAPIKeys  = "STOCKAL_PROXY_API_KEYS"
+)
+
+func main() {
+	var (
+		listen      = flag.String("listen", ":8080", "address to listen on")
+		ttl         = flag.Duration("ttl", 30*time.Second, "how long responses are cached")
+		baseURL     = flag.String("base-url", stockal.BaseURL, "Stockal API base URL")
+		timeout     = flag.Duration("timeout", stockal.DefaultTimeout, "HTTP timeout for API requests")
+		corsOrigin  = flag.String("cors-origin", "", "allow browser requests from this origin (* for any)")
+		graphql     = flag.Bool("graphql", false, "serve a GraphQL endpoint on /graphql")
+		redisURL    = flag.String("redis", "", "share the response cache through the Redis server at this URL")
+		redisKeys   = flag.String("redis-prefix", redis.DefaultPrefix, "prefix for cache keys in Redis")
+		secretsSpec = flag.String("secrets", os.Getenv(secrets.EnvSpec), "where to read credentials from besides the environment: file:DIR, aws-sm:PREFIX, aws-sm-json:SECRET or gcp-sm:PROJECT")
+	)
+	flag.Parse()
+
+	secret := secrets.Lookup(*secretsSpec)
+	username, password := secret(envUsername), secret(envPassword)
+	if username == "" || password == "" {
+		log.Fatalf("set %s and %s", envUsername, envPassword)
+	}
+	var keys []string
+	for _, k := range strings.Split(secret(envAPIKeys), ",") {
+		if k = strings.TrimSpace(k); k != "" {
+			keys = append(keys, k)
+		}
+	}
+	if len(keys) == 0 {
+		log.Fatalf("set %s to at least one API key", envAPIKeys)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var store cache.Cache = cache.NewMemory()
+	if *redisURL != "" {
+		rc, err := redis.Open(*redisURL, redis.WithPrefix(*redisKeys))
+		if err != nil {
+			log.Fatalf("redis: %v", err)
+		}
+		defer rc.Close()
+		store = rc
+	}
+
+	srv := &server{
+		client:     stockal.NewClient(stockal.WithBaseURL(*baseURL), stockal.WithTimeout(*timeout)),
+		username:   username,
+		password:   password,
+		apiKeys:    keys,
+		corsOrigin: *corsOrigin,
+		graphql:    *graphql,
+		cache:      newResponseCache(store, *ttl),
+	}
+	httpServer := &http.Server{Addr: *listen, Handler: srv.routes(), ReadHeaderTimeout: 10 * time.Second}
+
+	go func() {
+		<-ctx.Done()
+		shutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		httpServer.Shutdown(shutdown)
+	}()
+
+	log.Printf("listening on %s", *listen)
+	if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Fatal(err)
+	}
+}
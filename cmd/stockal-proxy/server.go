@@ -0,0 +1,244 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+)
+
+// maxQuoteSymbols caps the symbols accepted by one /quotes request.
+const maxQuoteSymbols = 50
+
+// server serves the proxy endpoints.
+type server struct {
+	username, password string
+	apiKeys            []string
+	corsOrigin         string
+	graphql            bool
+	cache              *responseCache
+	client             stockal.StockalClient
+
+	// loginMu keeps requests that find the session expired at the same time
+	// from logging in together.
+	loginMu sync.Mutex
+}
+
+func (s *server) routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+	})
+	mux.Handle("GET /summary", s.authorized(s.handleSummary))
+	mux.Handle("GET /portfolio", s.authorized(s.handlePortfolio))
+	mux.Handle("GET /quotes", s.authorized(s.handleQuotes))
+	if s.graphql {
+		mux.Handle("POST /graphql", s.authorized(s.graphqlHandler().ServeHTTP))
+	}
+	return s.cors(mux)
+}
+
+// authorized rejects requests without a valid API key.
+func (s *server) authorized(next http.HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get("X-API-Key")
+		if bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+			key = bearer
+		}
+		for _, k := range s.apiKeys {
+			if subtle.ConstantTimeCompare([]byte(key), []byte(k)) == 1 {
+				next(w, r)
+				return
+			}
+		}
+		writeError(w, http.StatusUnauthorized, "missing or invalid API key")
+	})
+}
+
+// cors adds CORS headers for the configured origin and answers preflight requests.
+func (s *server) cors(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.corsOrigin != "" {
+			w.Header().Set("Access-Control-Allow-Origin", s.corsOrigin)
+			w.Header().Set("Access-Control-Allow-Headers", "Authorization, Content-Type, X-API-Key")
+			w.Header().Set("Access-Control-Allow-Methods", "GET, POST")
+			w.Header().Add("Vary", "Origin")
+			if r.Method == http.MethodOptions {
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *server) handleSummary(w http.ResponseWriter, r *http.Request) {
+	s.serve(w, r, func(ctx context.Context) (any, error) { return s.summary(ctx) })
+}
+
+func (s *server) handlePortfolio(w http.ResponseWriter, r *http.Request) {
+	s.serve(w, r, func(ctx context.Context) (any, error) { return s.portfolio(ctx) })
+}
+
+func (s *server) handleQuotes(w http.ResponseWriter, r *http.Request) {
+	symbols, err := parseSymbols(strings.Split(r.URL.Query().Get("symbols"), ","))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	s.serve(w, r, func(ctx context.Context) (any, error) { return s.quotes(ctx, symbols) })
+}
+
+// serve writes the result of fetch as JSON.
+func (s *server) serve(w http.ResponseWriter, r *http.Request, fetch func(context.Context) (any, error)) {
+	v, err := fetch(r.Context())
+	if err != nil {
+		log.Printf("%s: %v", r.URL.Path, err)
+		writeUpstreamError(w, err)
+		return
+	}
+	w.Header().Set("Cache-Control", "private, max-age="+strconv.Itoa(int(s.cache.ttl.Seconds())))
+	writeJSON(w, http.StatusOK, v)
+}
+
+// summary returns the cached account summary.
+func (s *server) summary(ctx context.Context) (*stockal.AccountSummaryData, error) {
+	var data stockal.AccountSummaryData
+	err := s.load(ctx, "summary", &data, func(ctx context.Context) (any, error) {
+		resp, err := s.client.Account().Summary(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return &resp.Data, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+// portfolio returns the cached portfolio detail.
+func (s *server) portfolio(ctx context.Context) (*stockal.PortfolioDetailData, error) {
+	var data stockal.PortfolioDetailData
+	err := s.load(ctx, "portfolio", &data, func(ctx context.Context) (any, error) {
+		resp, err := s.client.Portfolio().Detail(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return &resp.Data, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+// quotes returns cached quotes for symbols, which must come from parseSymbols.
+func (s *server) quotes(ctx context.Context, symbols []string) ([]stockal.Quote, error) {
+	var quotes []stockal.Quote
+	err := s.load(ctx, "quotes:"+strings.Join(symbols, ","), &quotes, func(ctx context.Context) (any, error) {
+		resp, err := s.client.GetQuotes(ctx, symbols...)
+		if err != nil {
+			return nil, err
+		}
+		return resp.Data, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return quotes, nil
+}
+
+// parseSymbols normalizes, deduplicates and sorts symbols, so that the same
+// set of symbols shares a cache entry.
+func parseSymbols(raw []string) ([]string, error) {
+	var symbols []string
+	seen := map[string]bool{}
+	for _, sym := range raw {
+		sym = strings.ToUpper(strings.TrimSpace(sym))
+		if sym != "" && !seen[sym] {
+			seen[sym] = true
+			symbols = append(symbols, sym)
+		}
+	}
+	if len(symbols) == 0 {
+		return nil, errors.New("symbols are required")
+	}
+	if len(symbols) > maxQuoteSymbols {
+		return nil, fmt.Errorf("at most %d symbols per request", maxQuoteSymbols)
+	}
+	sort.Strings(symbols)
+	return symbols, nil
+}
+
+// load decodes the cached result of fetch into dst, logging in again once if
+// the call fails because the session is missing or has expired.
+func (s *server) load(ctx context.Context, key string, dst any, fetch func(context.Context) (any, error)) error {
+	return s.cache.get(ctx, key, dst, func() (any, error) {
+		// Use a context independent of the first caller, whose result is shared.
+		ctx := context.WithoutCancel(ctx)
+		v, err := fetch(ctx)
+		if err == nil || !sessionError(err) {
+			return v, err
+		}
+		if err := s.login(ctx); err != nil {
+			return nil, err
+		}
+		return fetch(ctx)
+	})
+}
+
+// login logs the client in again with the proxy's credentials.
+func (s *server) login(ctx context.Context) error {
+	s.loginMu.Lock()
+	defer s.loginMu.Unlock()
+	_, err := s.client.Auth().Login(ctx, s.username, s.password)
+	return err
+}
+
+// sessionError reports whether err means the client needs to log in. A
+// Cloudflare challenge is forbidden too, but logging in again does not pass it.
+func sessionError(err error) bool {
+	return errors.Is(err, stockal.ErrNotAuthenticated) || errors.Is(err, stockal.ErrTokenExpired) ||
+		errors.Is(err, stockal.ErrForbidden) && !errors.Is(err, stockal.ErrUpstreamUnavailable)
+}
+
+// writeUpstreamError maps client errors to proxy responses without leaking
+// upstream details to callers.
+func writeUpstreamError(w http.ResponseWriter, err error) {
+	var rateLimit *stockal.RateLimitError
+	switch {
+	case errors.As(err, &rateLimit):
+		if rateLimit.RetryAfter > 0 {
+			w.Header().Set("Retry-After", strconv.Itoa(int(rateLimit.RetryAfter.Seconds())))
+		}
+		writeError(w, http.StatusTooManyRequests, "upstream rate limit exceeded")
+	case errors.Is(err, context.DeadlineExceeded):
+		writeError(w, http.StatusGatewayTimeout, "upstream request timed out")
+	case errors.Is(err, stockal.ErrMaintenance):
+		writeError(w, http.StatusServiceUnavailable, "upstream under maintenance")
+	default:
+		writeError(w, http.StatusBadGateway, "upstream request failed")
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("write response: %v", err)
+	}
+}
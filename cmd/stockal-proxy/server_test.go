@@ -0,0 +1,248 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+	"github.com/adjaecent/unofficial-stockal-api/cache"
+	"github.com/adjaecent/unofficial-stockal-api/stockaltest"
+)
+
+// newTestServer returns a proxy for client accepting the API keys "k1" and
+// "k2", allowing origin for CORS if it is not empty.
+func newTestServer(t *testing.T, client stockal.StockalClient, origin string) *httptest.Server {
+	t.Helper()
+	s := &server{
+		username:   "alice",
+		password:   "hunter2",
+		apiKeys:    []string{"k1", "k2"},
+		corsOrigin: origin,
+		cache:      newResponseCache(cache.NewMemory(), time.Minute),
+		client:     client,
+	}
+	srv := httptest.NewServer(s.routes())
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// do sends a request to srv with the given headers, returning the response
+// and its body.
+func do(t *testing.T, srv *httptest.Server, method, path string, header map[string]string) (*http.Response, string) {
+	t.Helper()
+	req, err := http.NewRequest(method, srv.URL+path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	resp, err := srv.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp, string(body)
+}
+
+func TestAuthorized(t *testing.T) {
+	srv := newTestServer(t, &stockaltest.MockClient{}, "")
+
+	tests := []struct {
+		name   string
+		path   string
+		header map[string]string
+		status int
+	}{
+		{name: "no key", path: "/summary", status: http.StatusUnauthorized},
+		{name: "X-API-Key", path: "/summary", header: map[string]string{"X-API-Key": "k1"}, status: http.StatusOK},
+		{name: "second key", path: "/portfolio", header: map[string]string{"X-API-Key": "k2"}, status: http.StatusOK},
+		{name: "bearer", path: "/quotes?symbols=AAPL", header: map[string]string{"Authorization": "Bearer k2"}, status: http.StatusOK},
+		{name: "bearer wins", path: "/summary", header: map[string]string{"Authorization": "Bearer wrong", "X-API-Key": "k1"}, status: http.StatusUnauthorized},
+		{name: "wrong key", path: "/summary", header: map[string]string{"X-API-Key": "k3"}, status: http.StatusUnauthorized},
+		{name: "key prefix", path: "/summary", header: map[string]string{"X-API-Key": "k"}, status: http.StatusUnauthorized},
+		{name: "key with suffix", path: "/summary", header: map[string]string{"X-API-Key": "k1k1"}, status: http.StatusUnauthorized},
+		{name: "basic auth", path: "/summary", header: map[string]string{"Authorization": "Basic azE6"}, status: http.StatusUnauthorized},
+		{name: "health without key", path: "/healthz", status: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, body := do(t, srv, http.MethodGet, tt.path, tt.header)
+			if resp.StatusCode != tt.status {
+				t.Errorf("status %d (%s), want %d", resp.StatusCode, body, tt.status)
+			}
+			if tt.status == http.StatusUnauthorized && !strings.Contains(body, "missing or invalid API key") {
+				t.Errorf("body %s, want the reason", body)
+			}
+		})
+	}
+}
+
+func TestCORS(t *testing.T) {
+	srv := newTestServer(t, &stockaltest.MockClient{}, "https://dashboard.example.com")
+
+	// Browsers send preflight requests without credentials
+	resp, _ := do(t, srv, http.MethodOptions, "/summary", map[string]string{
+		"Origin":                         "https://dashboard.example.com",
+		"Access-Control-Request-Method":  "GET",
+		"Access-Control-Request-Headers": "X-API-Key",
+	})
+	if resp.StatusCode != http.StatusNoContent {
+		t.Errorf("preflight status %d, want 204", resp.StatusCode)
+	}
+	for header, want := range map[string]string{
+		"Access-Control-Allow-Origin":  "https://dashboard.example.com",
+		"Access-Control-Allow-Headers": "Authorization, Content-Type, X-API-Key",
+		"Access-Control-Allow-Methods": "GET, POST",
+		"Vary":                         "Origin",
+	} {
+		if got := resp.Header.Get(header); got != want {
+			t.Errorf("preflight %s = %q, want %q", header, got, want)
+		}
+	}
+
+	resp, _ = do(t, srv, http.MethodGet, "/summary", map[string]string{"X-API-Key": "k1"})
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Access-Control-Allow-Origin") != "https://dashboard.example.com" {
+		t.Errorf("status %d, headers %v; want the CORS headers on responses too", resp.StatusCode, resp.Header)
+	}
+
+	// Without an allowed origin there is no CORS, nor preflight answer
+	srv = newTestServer(t, &stockaltest.MockClient{}, "")
+	resp, _ = do(t, srv, http.MethodOptions, "/summary", map[string]string{"Origin": "https://dashboard.example.com"})
+	if resp.StatusCode == http.StatusNoContent || resp.Header.Get("Access-Control-Allow-Origin") != "" {
+		t.Errorf("preflight without an origin: status %d, headers %v", resp.StatusCode, resp.Header)
+	}
+}
+
+func TestUpstreamErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		status     int
+		retryAfter string
+		message    string
+	}{
+		{
+			name:       "rate limited",
+			err:        &stockal.RateLimitError{RetryAfter: 30 * time.Second, Limit: -1, Remaining: -1},
+			status:     http.StatusTooManyRequests,
+			retryAfter: "30",
+			message:    "upstream rate limit exceeded",
+		},
+		{
+			name:    "rate limited without a wait",
+			err:     &stockal.RateLimitError{Limit: -1, Remaining: -1},
+			status:  http.StatusTooManyRequests,
+			message: "upstream rate limit exceeded",
+		},
+		{
+			name:    "timeout",
+			err:     context.DeadlineExceeded,
+			status:  http.StatusGatewayTimeout,
+			message: "upstream request timed out",
+		},
+		{
+			name:    "maintenance",
+			err:     &stockal.APIError{Code: 503, Message: "Service unavailable", StatusCode: 503},
+			status:  http.StatusServiceUnavailable,
+			message: "upstream under maintenance",
+		},
+		{
+			name:    "server error",
+			err:     &stockal.APIError{Code: 500, Message: "database password rejected for user admin", StatusCode: 500},
+			status:  http.StatusBadGateway,
+			message: "upstream request failed",
+		},
+		{
+			name:    "cloudflare challenge",
+			err:     &stockal.UpstreamError{StatusCode: 403, ContentType: "text/html", Body: "Just a moment..."},
+			status:  http.StatusBadGateway,
+			message: "upstream request failed",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logins := 0
+			client := &stockaltest.MockClient{
+				AccountSummaryFunc: func(ctx context.Context) (*stockal.AccountSummaryResponse, error) {
+					return nil, tt.err
+				},
+				AuthLoginFunc: func(ctx context.Context, username, password string) (*stockal.LoginResponse, error) {
+					logins++
+					return stockaltest.Session(), nil
+				},
+			}
+			srv := newTestServer(t, client, "")
+
+			resp, body := do(t, srv, http.MethodGet, "/summary", map[string]string{"X-API-Key": "k1"})
+			if resp.StatusCode != tt.status || !strings.Contains(body, tt.message) {
+				t.Errorf("response %d %s, want %d %q", resp.StatusCode, body, tt.status, tt.message)
+			}
+			if got := resp.Header.Get("Retry-After"); got != tt.retryAfter {
+				t.Errorf("Retry-After %q, want %q", got, tt.retryAfter)
+			}
+			if strings.Contains(body, tt.err.Error()) {
+				t.Errorf("body %s leaks the upstream error", body)
+			}
+			if logins != 0 {
+				t.Errorf("logged in %d times, want none for errors a login does not fix", logins)
+			}
+		})
+	}
+}
+
+func TestSessionExpired(t *testing.T) {
+	var logins, calls int
+	client := &stockaltest.MockClient{
+		AccountSummaryFunc: func(ctx context.Context) (*stockal.AccountSummaryResponse, error) {
+			calls++
+			if logins == 0 {
+				return nil, stockal.ErrTokenExpired
+			}
+			return stockaltest.AccountSummary(), nil
+		},
+		AuthLoginFunc: func(ctx context.Context, username, password string) (*stockal.LoginResponse, error) {
+			if username != "alice" || password != "hunter2" {
+				return nil, errors.New("wrong credentials")
+			}
+			logins++
+			return stockaltest.Session(), nil
+		},
+	}
+	srv := newTestServer(t, client, "")
+
+	for range 2 {
+		resp, body := do(t, srv, http.MethodGet, "/summary", map[string]string{"X-API-Key": "k1"})
+		if resp.StatusCode != http.StatusOK || resp.Header.Get("Cache-Control") != "private, max-age=60" {
+			t.Fatalf("response %d %s, headers %v; want the summary", resp.StatusCode, body, resp.Header)
+		}
+	}
+	if logins != 1 || calls != 2 {
+		t.Errorf("logged in %d times for %d calls, want the session renewed once and the second request cached", logins, calls)
+	}
+}
+
+func TestQuotesValidation(t *testing.T) {
+	var tooMany []string
+	for i := range maxQuoteSymbols + 1 {
+		tooMany = append(tooMany, fmt.Sprintf("S%d", i))
+	}
+	srv := newTestServer(t, &stockaltest.MockClient{}, "")
+	for _, path := range []string{"/quotes", "/quotes?symbols=,%20,", "/quotes?symbols=" + strings.Join(tooMany, ",")} {
+		resp, body := do(t, srv, http.MethodGet, path, map[string]string{"X-API-Key": "k1"})
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("%s: response %d %s, want 400", path, resp.StatusCode, body)
+		}
+	}
+}
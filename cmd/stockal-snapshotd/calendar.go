@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+	"github.com/adjaecent/unofficial-stockal-api/export"
+)
+
+// envAlphaVantageKey holds the API key used to look up dividend and earnings dates.
+const envAlphaVantageKey = "ALPHAVANTAGE_API_KEY"
+
+// calendar keeps an iCalendar file of the holdings' upcoming dividend and
+// earnings dates.
+type calendar struct {
+	provider stockal.CorporateEventProvider
+	path     string
+	days     int
+
+	// updated is when the file was last written; corporate calendars change
+	// rarely and free API keys allow few requests, so it is rewritten at most
+	// once a day
+	updated time.Time
+}
+
+// refresh rewrites the calendar for the snapshot's holdings if it is more than
+// a day old. Failures are logged so that the daemon keeps running.
+func (c *calendar) refresh(ctx context.Context, snapshot *stockal.Snapshot) {
+	if time.Since(c.updated) < 24*time.Hour {
+		return
+	}
+	symbols := make([]string, 0, len(snapshot.Holdings))
+	for _, h := range snapshot.Holdings {
+		symbols = append(symbols, h.Symbol)
+	}
+
+	now := time.Now().UTC()
+	from := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	events, err := c.provider.CorporateEvents(ctx, from, from.AddDate(0, 0, c.days), symbols...)
+	if err != nil {
+		log.Printf("calendar update failed: %v", err)
+		return
+	}
+	if err := c.write(events); err != nil {
+		log.Printf("calendar update failed: %v", err)
+		return
+	}
+	c.updated = time.Now()
+	log.Printf("wrote %d calendar events to %s", len(events), c.path)
+}
+
+// write replaces the file atomically, so that a web server publishing it never
+// serves a partial calendar.
+func (c *calendar) write(events []stockal.CorporateEvent) error {
+	f, err := os.CreateTemp(filepath.Dir(c.path), ".stockal-*.ics")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if err := export.WriteICS(f, "Stockal holdings", events); err != nil {
+		f.Close()
+		return err
+	}
+	// CreateTemp makes the file private; calendars are meant to be served.
+	if err := f.Chmod(0o644); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), c.path)
+}
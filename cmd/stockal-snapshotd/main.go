@@ -0,0 +1,388 @@
+// Command stockal-snapshotd records portfolio snapshots to a SQLite database,
+// to PostgreSQL with --postgres or to S3-compatible object storage with --s3,
+// on a schedule and queries the recorded history.
+//
+// The daemon logs in with the STOCKAL_USERNAME and STOCKAL_PASSWORD
+// environment variables:
+//
+//	stockal-snapshotd run                                  # weekdays after the US close
+//	stockal-snapshotd run --schedule "@every 1h" --retain-days 365
+//	stockal-snapshotd list --since 2025-01-01
+//	stockal-snapshotd holding AAPL
+//	stockal-snapshotd transactions --since 2025-01-01
+//	stockal-snapshotd show 42
+//	stockal-snapshotd run --s3 s3://my-bucket/stockal/     # credentials from the AWS environment
+//
+// With SQLite or PostgreSQL, each run also records the account's transactions
+// (trades, deposits, dividends and fees) since the previous run.
+//
+// A PostgreSQL database can be shared by several users' daemons; each reads and
+// writes the snapshots of its --account, which defaults to STOCKAL_USERNAME.
+// The connection string may also be given in STOCKAL_POSTGRES_URL:
+//
+//	stockal-snapshotd run --postgres postgres://stockal@db.lan/stockal
+//
+// With --ics, the daemon also keeps an iCalendar file of the ex-dividend,
+// dividend payment and earnings dates of the current holdings, looked up with
+// the Alpha Vantage API key in ALPHAVANTAGE_API_KEY. Serve the file over HTTPS
+// to subscribe to it from Google Calendar:
+//
+//	stockal-snapshotd run --ics /var/www/stockal/holdings.ics --calendar-days 120
+//
+// Schedules use cron syntax (minute hour day-of-month month day-of-week),
+// optionally prefixed with CRON_TZ=<zone>, or descriptors such as @daily and
+// "@every 30m".
+//
+// Extensions imported in plugins.go can add history stores, selected with
+// --store by URL scheme; see package registry.
+//
+// Credentials and tokens missing from the environment are read from the
+// backend given with --secrets or STOCKAL_SECRETS, such as a directory of
+// files or AWS Secrets Manager; see package secrets.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+	"github.com/adjaecent/unofficial-stockal-api/history"
+	"github.com/adjaecent/unofficial-stockal-api/history/postgres"
+	"github.com/adjaecent/unofficial-stockal-api/history/s3"
+	"github.com/adjaecent/unofficial-stockal-api/history/sqlite"
+	"github.com/adjaecent/unofficial-stockal-api/registry"
+	"github.com/adjaecent/unofficial-stockal-api/secrets"
+)
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := newRootCmd().ExecuteContext(ctx); err != nil {
+		os.Exit(1)
+	}
+}
+
+// options are the flags shared by all commands.
+type options struct {
+	dbPath      string
+	postgresURL string
+	account     string
+	s3URL       string
+	s3PathStyle bool
+	storeURL    string
+	json        bool
+	secretsSpec string
+
+	// provider resolves secrets, opened on first use
+	provider stockal.SecretsProvider
+}
+
+// envPostgres is the secret holding the default for --postgres, keeping
+// passwords out of the process list.
+const envPostgres = "STOCKAL_POSTGRES_URL"
+
+func newRootCmd() *cobra.Command {
+	opts := &options{}
+	root := &cobra.Command{
+		Use:          "stockal-snapshotd",
+		Short:        "Record and query Stockal portfolio history",
+		SilenceUsage: true,
+	}
+	root.PersistentFlags().StringVar(&opts.dbPath, "db", defaultDBPath(), "SQLite database file")
+	root.PersistentFlags().StringVar(&opts.postgresURL, "postgres", "", "store snapshots in this PostgreSQL database instead (default: the "+envPostgres+" secret)")
+	root.PersistentFlags().StringVar(&opts.account, "account", os.Getenv(envUsername), "account whose snapshots are used in a PostgreSQL database")
+	root.PersistentFlags().StringVar(&opts.s3URL, "s3", "", "store snapshots in S3-compatible storage instead, as s3://bucket/prefix/")
+	root.PersistentFlags().BoolVar(&opts.s3PathStyle, "s3-path-style", false, "use path-style bucket addressing, as MinIO requires")
+	root.PersistentFlags().StringVar(&opts.storeURL, "store", "", "store snapshots in a backend added by an extension, selected by the URL's scheme")
+	root.PersistentFlags().BoolVar(&opts.json, "json", false, "print results as JSON")
+	root.PersistentFlags().StringVar(&opts.secretsSpec, "secrets", os.Getenv(secrets.EnvSpec), "where to read credentials from besides the environment: file:DIR, aws-sm:PREFIX, aws-sm-json:SECRET or gcp-sm:PROJECT")
+
+	root.AddCommand(
+		newRunCmd(opts),
+		newListCmd(opts),
+		newShowCmd(opts),
+		newHoldingCmd(opts),
+		newTransactionsCmd(opts),
+		newPruneCmd(opts),
+	)
+	return root
+}
+
+// defaultDBPath returns ~/.config/stockal/history.db, next to the stockalctl configuration.
+func defaultDBPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "stockal-history.db"
+	}
+	return filepath.Join(dir, "stockal", "history.db")
+}
+
+// secret returns the secret name, or "" if it is not set.
+func (o *options) secret(ctx context.Context, name string) (string, error) {
+	if o.provider == nil {
+		provider, err := secrets.Open(ctx, o.secretsSpec)
+		if err != nil {
+			return "", err
+		}
+		o.provider = provider
+	}
+	return secrets.Get(ctx, o.provider, name)
+}
+
+// open opens the history store: the database given by --postgres, the bucket
+// given by --s3, the extension's store given by --store, or else the SQLite
+// database, creating its directory if needed. Without any of these, a
+// connection string among the secrets is used.
+func (o *options) open(ctx context.Context) (history.Store, error) {
+	if o.storeURL != "" {
+		if o.postgresURL != "" || o.s3URL != "" {
+			return nil, errors.New("--store cannot be used with --postgres or --s3")
+		}
+		return registry.OpenStore(ctx, o.storeURL)
+	}
+	postgresURL := o.postgresURL
+	if postgresURL == "" && o.s3URL == "" {
+		var err error
+		if postgresURL, err = o.secret(ctx, envPostgres); err != nil {
+			return nil, err
+		}
+	}
+	if postgresURL != "" && o.s3URL != "" {
+		return nil, errors.New("--postgres and --s3 cannot be used together")
+	}
+	if postgresURL != "" {
+		return postgres.Open(postgresURL, postgres.WithAccount(o.account))
+	}
+	if o.s3URL != "" {
+		u, err := url.Parse(o.s3URL)
+		if err != nil || u.Scheme != "s3" || u.Host == "" {
+			return nil, fmt.Errorf("invalid --s3 %q (want s3://bucket/prefix/)", o.s3URL)
+		}
+		opts := []s3.Option{s3.WithPrefix(strings.TrimPrefix(u.Path, "/"))}
+		if o.s3PathStyle {
+			opts = append(opts, s3.WithPathStyle())
+		}
+		return s3.Open(u.Host, opts...)
+	}
+	if err := os.MkdirAll(filepath.Dir(o.dbPath), 0o700); err != nil {
+		return nil, err
+	}
+	return sqlite.Open(o.dbPath)
+}
+
+func newListCmd(opts *options) *cobra.Command {
+	var since, until string
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List recorded snapshots",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			from, to, err := parseRange(since, until)
+			if err != nil {
+				return err
+			}
+			store, err := opts.open(cmd.Context())
+			if err != nil {
+				return err
+			}
+			defer store.Close()
+
+			entries, err := store.List(cmd.Context(), from, to)
+			if err != nil {
+				return err
+			}
+			if opts.json {
+				return writeJSON(cmd.OutOrStdout(), entries)
+			}
+
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', tabwriter.AlignRight)
+			fmt.Fprintln(w, "ID\tTAKEN AT\tVALUE\tINVESTED\tGAIN\tCASH\tHOLDINGS\t")
+			for _, e := range entries {
+				fmt.Fprintf(w, "%d\t%s\t%.2f\t%.2f\t%.2f\t%.2f\t%d\t\n", e.ID, e.TakenAt.Local().Format(time.DateTime),
+					e.TotalValue, e.TotalInvested, e.TotalValue-e.TotalInvested, e.Cash, e.Holdings)
+			}
+			return w.Flush()
+		},
+	}
+	cmd.Flags().StringVar(&since, "since", "", "only snapshots on or after this date (YYYY-MM-DD)")
+	cmd.Flags().StringVar(&until, "until", "", "only snapshots before this date (YYYY-MM-DD)")
+	return cmd
+}
+
+func newShowCmd(opts *options) *cobra.Command {
+	return &cobra.Command{
+		Use:   "show <id>",
+		Short: "Print a recorded snapshot as JSON",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := strconv.ParseInt(args[0], 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid snapshot ID %q", args[0])
+			}
+			store, err := opts.open(cmd.Context())
+			if err != nil {
+				return err
+			}
+			defer store.Close()
+
+			snapshot, err := store.Get(cmd.Context(), id)
+			if errors.Is(err, history.ErrNotFound) {
+				return fmt.Errorf("no snapshot with ID %d", id)
+			}
+			if err != nil {
+				return err
+			}
+			return writeJSON(cmd.OutOrStdout(), snapshot)
+		},
+	}
+}
+
+func newHoldingCmd(opts *options) *cobra.Command {
+	var since, until string
+
+	cmd := &cobra.Command{
+		Use:   "holding <symbol>",
+		Short: "Show the recorded history of one holding",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			from, to, err := parseRange(since, until)
+			if err != nil {
+				return err
+			}
+			store, err := opts.open(cmd.Context())
+			if err != nil {
+				return err
+			}
+			defer store.Close()
+
+			points, err := store.Holding(cmd.Context(), args[0], from, to)
+			if err != nil {
+				return err
+			}
+			if opts.json {
+				return writeJSON(cmd.OutOrStdout(), points)
+			}
+
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', tabwriter.AlignRight)
+			fmt.Fprintln(w, "TAKEN AT\tUNITS\tPRICE\tVALUE\tINVESTED\tGAIN\t")
+			for _, p := range points {
+				fmt.Fprintf(w, "%s\t%.4f\t%.2f\t%.2f\t%.2f\t%.2f\t\n", p.TakenAt.Local().Format(time.DateTime),
+					p.Units, p.Price, p.Value, p.Invested, p.Value-p.Invested)
+			}
+			return w.Flush()
+		},
+	}
+	cmd.Flags().StringVar(&since, "since", "", "only snapshots on or after this date (YYYY-MM-DD)")
+	cmd.Flags().StringVar(&until, "until", "", "only snapshots before this date (YYYY-MM-DD)")
+	return cmd
+}
+
+func newTransactionsCmd(opts *options) *cobra.Command {
+	var since, until string
+
+	cmd := &cobra.Command{
+		Use:   "transactions",
+		Short: "List recorded transactions",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			from, to, err := parseRange(since, until)
+			if err != nil {
+				return err
+			}
+			store, err := opts.open(cmd.Context())
+			if err != nil {
+				return err
+			}
+			defer store.Close()
+
+			txStore, ok := store.(history.TransactionStore)
+			if !ok {
+				return errors.New("this store does not keep transactions")
+			}
+			transactions, err := txStore.Transactions(cmd.Context(), from, to)
+			if err != nil {
+				return err
+			}
+			if opts.json {
+				return writeJSON(cmd.OutOrStdout(), transactions)
+			}
+
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "DATE\tTYPE\tSYMBOL\tQUANTITY\tAMOUNT\tDESCRIPTION")
+			for _, t := range transactions {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%g\t%.2f\t%s\n", t.Date, t.Type, t.Symbol, t.Quantity, t.CashFlow(), t.Description)
+			}
+			return w.Flush()
+		},
+	}
+	cmd.Flags().StringVar(&since, "since", "", "only transactions on or after this date (YYYY-MM-DD)")
+	cmd.Flags().StringVar(&until, "until", "", "only transactions before this date (YYYY-MM-DD)")
+	return cmd
+}
+
+func newPruneCmd(opts *options) *cobra.Command {
+	var days int
+
+	cmd := &cobra.Command{
+		Use:   "prune",
+		Short: "Delete old snapshots",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if days <= 0 {
+				return errors.New("--older-than-days must be positive")
+			}
+			store, err := opts.open(cmd.Context())
+			if err != nil {
+				return err
+			}
+			defer store.Close()
+
+			n, err := store.Prune(cmd.Context(), time.Now().AddDate(0, 0, -days))
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.ErrOrStderr(), "Deleted %d snapshots\n", n)
+			return nil
+		},
+	}
+	cmd.Flags().IntVar(&days, "older-than-days", 0, "delete snapshots older than this many days")
+	cmd.MarkFlagRequired("older-than-days")
+	return cmd
+}
+
+// parseRange parses optional YYYY-MM-DD bounds in local time.
+func parseRange(since, until string) (from, to time.Time, err error) {
+	if since != "" {
+		if from, err = time.ParseInLocation(time.DateOnly, since, time.Local); err != nil {
+			return from, to, fmt.Errorf("invalid --since %q (want YYYY-MM-DD)", since)
+		}
+	}
+	if until != "" {
+		if to, err = time.ParseInLocation(time.DateOnly, until, time.Local); err != nil {
+			return from, to, fmt.Errorf("invalid --until %q (want YYYY-MM-DD)", until)
+		}
+	}
+	return from, to, nil
+}
+
+func writeJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
@@ -0,0 +1,8 @@
+package main
+
+// Extensions are linked into stockal-snapshotd by importing them here for
+// their registrations (see package registry), for example:
+//
+//	import _ "example.com/stockal-dynamodb"
+//
+// Their history stores can then be selected with --store.
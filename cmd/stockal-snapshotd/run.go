@@ -0,0 +1,192 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"github.com/spf13/cobra"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+	"github.com/adjaecent/unofficial-stockal-api/history"
+	"github.com/adjaecent/unofficial-stockal-api/providers/alphavantage"
+)
+
+// Environment variables holding the credentials.
+const (
+	envUsername = "STOCKAL_USERNAME"
+	envPassword = "STOCKAL_PASSWORD"
+)
+
+// defaultSchedule takes a snapshot shortly after the US market closes on weekdays.
+const defaultSchedule = "CRON_TZ=America/New_York 5 16 * * 1-5"
+
+func newRunCmd(opts *options) *cobra.Command {
+	var (
+		schedule   string
+		retainDays int
+		now        bool
+		baseURL    string
+		icsPath    string
+		icsDays    int
+	)
+
+	cmd := &cobra.Command{
+		Use:   "run",
+		Short: "Take snapshots on a schedule until interrupted",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			username, err := opts.secret(ctx, envUsername)
+			if err != nil {
+				return err
+			}
+			password, err := opts.secret(ctx, envPassword)
+			if err != nil {
+				return err
+			}
+			if username == "" || password == "" {
+				return fmt.Errorf("set %s and %s", envUsername, envPassword)
+			}
+			var cal *calendar
+			if icsPath != "" {
+				apiKey, err := opts.secret(ctx, envAlphaVantageKey)
+				if err != nil {
+					return err
+				}
+				if apiKey == "" {
+					return fmt.Errorf("--ics needs an Alpha Vantage API key in %s", envAlphaVantageKey)
+				}
+				cal = &calendar{provider: alphavantage.New(apiKey), path: icsPath, days: icsDays}
+			}
+			sched, err := cron.ParseStandard(schedule)
+			if err != nil {
+				return fmt.Errorf("invalid --schedule: %w", err)
+			}
+
+			store, err := opts.open(ctx)
+			if err != nil {
+				return err
+			}
+			defer store.Close()
+
+			r := &recorder{
+				client:     stockal.NewClient(stockal.WithBaseURL(baseURL)),
+				username:   username,
+				password:   password,
+				store:      store,
+				retainDays: retainDays,
+				calendar:   cal,
+			}
+			if now {
+				r.record(ctx)
+			}
+
+			for {
+				next := sched.Next(time.Now())
+				log.Printf("next snapshot at %s", next.Local().Format(time.RFC1123))
+				select {
+				case <-ctx.Done():
+					return nil
+				case <-time.After(time.Until(next)):
+				}
+				r.record(ctx)
+			}
+		},
+	}
+	cmd.Flags().StringVar(&schedule, "schedule", defaultSchedule, "cron schedule for snapshots")
+	cmd.Flags().IntVar(&retainDays, "retain-days", 0, "delete snapshots older than this many days (0 keeps everything)")
+	cmd.Flags().BoolVar(&now, "now", false, "also take a snapshot immediately")
+	cmd.Flags().StringVar(&baseURL, "base-url", stockal.BaseURL, "Stockal API base URL")
+	cmd.Flags().StringVar(&icsPath, "ics", "", "keep an iCalendar file of the holdings' dividend and earnings dates at this path")
+	cmd.Flags().IntVar(&icsDays, "calendar-days", 90, "days ahead covered by the --ics calendar")
+	return cmd
+}
+
+// recorder takes snapshots and stores them.
+type recorder struct {
+	client             stockal.StockalClient
+	username, password string
+	store              history.Store
+	retainDays         int
+	// calendar, if set, is refreshed after each snapshot
+	calendar *calendar
+	// transactionsSince is where the next transaction read starts; zero reads
+	// them all
+	transactionsSince time.Time
+}
+
+// transactionOverlap is how far before the last run transactions are read
+// again, to catch those the API posts late.
+const transactionOverlap = 7 * 24 * time.Hour
+
+// record takes and stores one snapshot, then prunes old ones. Failures are
+// logged so that the daemon keeps running.
+func (r *recorder) record(ctx context.Context) {
+	snapshot, err := r.snapshot(ctx)
+	if err != nil {
+		if !errors.Is(err, context.Canceled) {
+			log.Printf("snapshot failed: %v", err)
+		}
+		return
+	}
+	id, err := r.store.Save(ctx, snapshot)
+	if err != nil {
+		log.Printf("save failed: %v", err)
+		return
+	}
+	log.Printf("saved snapshot %d: %d holdings, value %.2f", id, len(snapshot.Holdings),
+		snapshot.Summary.PortfolioSummary.TotalCurrentValue)
+
+	if txStore, ok := r.store.(history.TransactionStore); ok {
+		r.recordTransactions(ctx, txStore)
+	}
+
+	if r.calendar != nil {
+		r.calendar.refresh(ctx, snapshot)
+	}
+
+	if r.retainDays > 0 {
+		n, err := r.store.Prune(ctx, time.Now().AddDate(0, 0, -r.retainDays))
+		if err != nil {
+			log.Printf("prune failed: %v", err)
+		} else if n > 0 {
+			log.Printf("pruned %d snapshots older than %d days", n, r.retainDays)
+		}
+	}
+}
+
+// recordTransactions stores the transactions since the last run, logging
+// failures.
+func (r *recorder) recordTransactions(ctx context.Context, store history.TransactionStore) {
+	started := time.Now()
+	transactions, err := stockal.AllTransactions(ctx, r.client, stockal.TransactionOptions{From: r.transactionsSince})
+	if err != nil {
+		log.Printf("reading transactions failed: %v", err)
+		return
+	}
+	added, err := store.SaveTransactions(ctx, transactions)
+	if err != nil {
+		log.Printf("saving transactions failed: %v", err)
+		return
+	}
+	if added > 0 {
+		log.Printf("saved %d new transactions", added)
+	}
+	r.transactionsSince = started.Add(-transactionOverlap)
+}
+
+// snapshot takes a snapshot, logging in first if there is no valid session.
+func (r *recorder) snapshot(ctx context.Context) (*stockal.Snapshot, error) {
+	snapshot, err := stockal.TakeSnapshot(ctx, r.client)
+	if err == nil {
+		return snapshot, nil
+	}
+	if _, err := r.client.Auth().Login(ctx, r.username, r.password); err != nil {
+		return nil, err
+	}
+	return stockal.TakeSnapshot(ctx, r.client)
+}
@@ -0,0 +1,299 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gen2brain/beeep"
+	"github.com/spf13/cobra"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+	"github.com/adjaecent/unofficial-stockal-api/alerts"
+	"github.com/adjaecent/unofficial-stockal-api/events"
+)
+
+// alertColumns are the CSV columns for triggered alerts, named after the
+// events.Alert JSON fields.
+var alertColumns = []string{"symbol", "condition", "threshold", "price", "message"}
+
+// writeAlert prints a triggered alert in the selected output format: its
+// message, a JSON object on one line, or a CSV row, after the header if first.
+func (o *globalOptions) writeAlert(w io.Writer, alert events.Alert, first bool) error {
+	switch o.output {
+	case formatJSON:
+		return json.NewEncoder(w).Encode(alert)
+	case formatCSV:
+		cw := csv.NewWriter(w)
+		if first {
+			cw.Write(alertColumns)
+		}
+		cw.Write([]string{alert.Symbol, alert.Condition, num(alert.Threshold), num(alert.Price), alert.Message})
+		cw.Flush()
+		return cw.Error()
+	}
+	_, err := fmt.Fprintln(w, alert.Message)
+	return err
+}
+
+// exitAlertTriggered is the exit status of "alert run --script" when an alert fires.
+const exitAlertTriggered = 2
+
+// Alert conditions.
+const (
+	conditionAbove = "above"
+	conditionBelow = "below"
+)
+
+// alertRule is a price threshold for a symbol.
+type alertRule struct {
+	ID        int     `json:"id"`
+	Symbol    string  `json:"symbol"`
+	Condition string  `json:"condition"`
+	Price     float64 `json:"price"`
+}
+
+// rule returns the alerts engine rule for r.
+func (r alertRule) rule() alerts.Rule {
+	if r.Condition == conditionAbove {
+		return alerts.PriceAbove{Symbol: r.Symbol, Price: r.Price}
+	}
+	return alerts.PriceBelow{Symbol: r.Symbol, Price: r.Price}
+}
+
+func (r alertRule) String() string {
+	return fmt.Sprintf("%s %s %s", r.Symbol, r.Condition, money(r.Price))
+}
+
+// alertsPath returns the file holding alert rules, next to the configuration file.
+func (o *globalOptions) alertsPath() string {
+	return filepath.Join(filepath.Dir(o.configPath), "alerts.json")
+}
+
+func loadAlerts(path string) ([]alertRule, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read alerts: %w", err)
+	}
+
+	var rules []alertRule
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("parse alerts %s: %w", path, err)
+	}
+	return rules, nil
+}
+
+func saveAlerts(path string, rules []alertRule) error {
+	data, err := json.MarshalIndent(rules, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o600)
+}
+
+func newAlertCmd(opts *globalOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "alert",
+		Short: "Manage and run price alerts",
+	}
+	cmd.AddCommand(
+		newAlertAddCmd(opts),
+		newAlertListCmd(opts),
+		newAlertRemoveCmd(opts),
+		newAlertRunCmd(opts),
+	)
+	return cmd
+}
+
+func newAlertAddCmd(opts *globalOptions) *cobra.Command {
+	var above, below float64
+
+	cmd := &cobra.Command{
+		Use:     "add <symbol>",
+		Short:   "Add a price alert",
+		Example: "  stockalctl alert add AAPL --above 250\n  stockalctl alert add TSLA --below 150",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rule := alertRule{Symbol: strings.ToUpper(args[0]), Condition: conditionAbove, Price: above}
+			if cmd.Flags().Changed("below") {
+				rule.Condition, rule.Price = conditionBelow, below
+			}
+			if rule.Price <= 0 {
+				return errors.New("alert price must be positive")
+			}
+
+			path := opts.alertsPath()
+			rules, err := loadAlerts(path)
+			if err != nil {
+				return err
+			}
+			for _, r := range rules {
+				rule.ID = max(rule.ID, r.ID)
+			}
+			rule.ID++
+			rules = append(rules, rule)
+			if err := saveAlerts(path, rules); err != nil {
+				return err
+			}
+
+			fmt.Fprintf(cmd.ErrOrStderr(), "Added alert %d: %s\n", rule.ID, rule)
+			return nil
+		},
+	}
+	cmd.Flags().Float64Var(&above, "above", 0, "alert when the price rises to or above this value")
+	cmd.Flags().Float64Var(&below, "below", 0, "alert when the price falls to or below this value")
+	cmd.MarkFlagsMutuallyExclusive("above", "below")
+	cmd.MarkFlagsOneRequired("above", "below")
+	return cmd
+}
+
+func newAlertListCmd(opts *globalOptions) *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List price alerts",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rules, err := loadAlerts(opts.alertsPath())
+			if err != nil {
+				return err
+			}
+
+			records := make([][]string, 0, len(rules))
+			for _, r := range rules {
+				records = append(records, []string{strconv.Itoa(r.ID), r.Symbol, r.Condition, num(r.Price)})
+			}
+			return opts.write(cmd.OutOrStdout(), result{
+				value:   rules,
+				columns: []string{"id", "symbol", "condition", "price"},
+				records: records,
+				table: func(w io.Writer) error {
+					t := newTable(w, "ID", "SYMBOL", "CONDITION", "PRICE")
+					for _, r := range rules {
+						t.row(strconv.Itoa(r.ID), r.Symbol, r.Condition, money(r.Price))
+					}
+					return t.flush()
+				},
+			})
+		},
+	}
+}
+
+func newAlertRemoveCmd(opts *globalOptions) *cobra.Command {
+	return &cobra.Command{
+		Use:   "remove <id>",
+		Short: "Remove a price alert",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := strconv.Atoi(args[0])
+			if err != nil {
+				return fmt.Errorf("invalid alert ID %q", args[0])
+			}
+
+			path := opts.alertsPath()
+			rules, err := loadAlerts(path)
+			if err != nil {
+				return err
+			}
+			for i, r := range rules {
+				if r.ID == id {
+					return saveAlerts(path, append(rules[:i], rules[i+1:]...))
+				}
+			}
+			return fmt.Errorf("no alert with ID %d", id)
+		},
+	}
+}
+
+func newAlertRunCmd(opts *globalOptions) *cobra.Command {
+	var (
+		interval time.Duration
+		script   bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "run",
+		Short: "Poll quotes and notify when alerts trigger",
+		Long: "Poll quotes in the foreground and send a desktop notification when an alert triggers.\n" +
+			"Each alert fires once and re-arms when its condition clears. Triggered alerts are\n" +
+			"also posted to the profile's notifiers.\n\n" +
+			"With --script, triggered alerts are printed and the command exits with status 2\n" +
+			"instead of sending notifications. With --output json each triggered alert is\n" +
+			"printed as a JSON object on its own line, and with --output csv as a row.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rules, err := loadAlerts(opts.alertsPath())
+			if err != nil {
+				return err
+			}
+			if len(rules) == 0 {
+				return errors.New("no alerts configured; add one with \"stockalctl alert add\"")
+			}
+
+			client, err := opts.session(cmd)
+			if err != nil {
+				return err
+			}
+
+			engine := alerts.NewEngine(opts.bus(cmd.Context(), cmd.ErrOrStderr()), alerts.WithAccount(opts.profileKey))
+			for _, r := range rules {
+				engine.Add(r.rule())
+			}
+			symbols := engine.Symbols()
+
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+
+			printed := 0
+			for first := true; ; first = false {
+				if first || stockal.IsMarketOpen(time.Now()) {
+					quotes, err := client.GetQuotes(cmd.Context(), symbols...)
+					if err != nil {
+						fmt.Fprintf(cmd.ErrOrStderr(), "quote refresh failed: %v\n", err)
+					} else {
+						fired, err := engine.ObserveQuotes(cmd.Context(), quotes.Data)
+						if err != nil {
+							fmt.Fprintf(cmd.ErrOrStderr(), "notify failed: %v\n", err)
+						}
+						for _, alert := range fired {
+							if err := opts.writeAlert(cmd.OutOrStdout(), alert, printed == 0); err != nil {
+								return err
+							}
+							printed++
+							if !script {
+								if err := beeep.Notify("Stockal alert", alert.Message, ""); err != nil {
+									fmt.Fprintf(cmd.ErrOrStderr(), "notification failed: %v\n", err)
+								}
+							}
+						}
+						if script && len(fired) > 0 {
+							return exitError{code: exitAlertTriggered}
+						}
+					}
+				}
+
+				select {
+				case <-cmd.Context().Done():
+					return nil
+				case <-ticker.C:
+				}
+			}
+		},
+	}
+	cmd.Flags().DurationVar(&interval, "interval", time.Minute, "how often to poll quotes")
+	cmd.Flags().BoolVar(&script, "script", false, "print triggers and exit with status 2 instead of notifying")
+	return cmd
+}
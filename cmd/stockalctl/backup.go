@@ -0,0 +1,386 @@
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/crypto/scrypt"
+	"golang.org/x/term"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+)
+
+// envBackupPassphrase holds the backup passphrase for non-interactive use.
+const envBackupPassphrase = "STOCKAL_BACKUP_PASSPHRASE"
+
+// backupMagic starts every backup archive and identifies its format version.
+const backupMagic = "STOCKALBAK1\n"
+
+// Names of the entries in a backup archive.
+const (
+	entryConfig      = "config.yaml"
+	entryAlerts      = "alerts.json"
+	entryREPLHistory = "repl_history"
+	entryHistoryDB   = "history.db"
+	entryHistoryWAL  = "history.db-wal"
+	entrySessions    = "sessions.json"
+)
+
+// backupFiles maps archive entries to their location on this machine.
+func (o *globalOptions) backupFiles(dbPath string) map[string]string {
+	return map[string]string{
+		entryConfig:      o.configPath,
+		entryAlerts:      o.alertsPath(),
+		entryREPLHistory: o.replHistoryPath(),
+		entryHistoryDB:   dbPath,
+		entryHistoryWAL:  dbPath + "-wal",
+	}
+}
+
+// defaultHistoryDBPath is the stockal-snapshotd database location.
+func defaultHistoryDBPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "stockal-history.db"
+	}
+	return filepath.Join(dir, "stockal", "history.db")
+}
+
+func newBackupCmd(opts *globalOptions) *cobra.Command {
+	var (
+		out    string
+		dbPath string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "backup",
+		Short: "Export local data to an encrypted archive",
+		Long: "Write the configuration, alert rules, REPL history, snapshot history database\n" +
+			"and saved sessions of every profile to one archive, encrypted with a passphrase.\n" +
+			"Restore it on another machine with \"stockalctl restore\".\n\n" +
+			"The passphrase is read from " + envBackupPassphrase + " or prompted for.\n" +
+			"Stop stockal-snapshotd first so the history database is copied consistently.",
+		Example: "  stockalctl backup --out stockal.bak",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var archive bytes.Buffer
+			gz := gzip.NewWriter(&archive)
+			tw := tar.NewWriter(gz)
+			var included []string
+
+			files := opts.backupFiles(dbPath)
+			for _, name := range slices.Sorted(maps.Keys(files)) {
+				data, err := os.ReadFile(files[name])
+				if errors.Is(err, fs.ErrNotExist) {
+					continue
+				}
+				if err != nil {
+					return err
+				}
+				if err := writeTarEntry(tw, name, data); err != nil {
+					return err
+				}
+				included = append(included, name)
+			}
+
+			sessions, err := opts.savedSessions(cmd)
+			if err != nil {
+				fmt.Fprintf(cmd.ErrOrStderr(), "warning: sessions not included: %v\n", err)
+			} else if len(sessions) > 0 {
+				data, err := json.Marshal(sessions)
+				if err != nil {
+					return err
+				}
+				if err := writeTarEntry(tw, entrySessions, data); err != nil {
+					return err
+				}
+				included = append(included, fmt.Sprintf("%s (%d profiles)", entrySessions, len(sessions)))
+			}
+			if err := tw.Close(); err != nil {
+				return err
+			}
+			if err := gz.Close(); err != nil {
+				return err
+			}
+			if len(included) == 0 {
+				return errors.New("nothing to back up")
+			}
+
+			passphrase, err := readPassphrase(cmd, true)
+			if err != nil {
+				return err
+			}
+			sealed, err := sealBackup(archive.Bytes(), passphrase)
+			if err != nil {
+				return err
+			}
+			if err := writeOutput(cmd.OutOrStdout(), out, sealed); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.ErrOrStderr(), "backed up %s\n", strings.Join(included, ", "))
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&out, "out", "-", "archive file (- for stdout)")
+	cmd.Flags().StringVar(&dbPath, "db", defaultHistoryDBPath(), "stockal-snapshotd database to include")
+	return cmd
+}
+
+func newRestoreCmd(opts *globalOptions) *cobra.Command {
+	var (
+		dbPath string
+		force  bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "restore <archive>",
+		Short: "Import local data from a backup archive",
+		Long: "Restore the files and sessions saved by \"stockalctl backup\". Existing files are\n" +
+			"not overwritten unless --force is given. Use - to read the archive from standard input.",
+		Example: "  stockalctl restore stockal.bak",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var (
+				sealed []byte
+				err    error
+			)
+			if args[0] == "-" {
+				sealed, err = io.ReadAll(cmd.InOrStdin())
+			} else {
+				sealed, err = os.ReadFile(args[0])
+			}
+			if err != nil {
+				return err
+			}
+
+			passphrase, err := readPassphrase(cmd, false)
+			if err != nil {
+				return err
+			}
+			archive, err := openBackup(sealed, passphrase)
+			if err != nil {
+				return err
+			}
+			entries, err := readTarEntries(archive)
+			if err != nil {
+				return err
+			}
+
+			files := opts.backupFiles(dbPath)
+			var conflicts []string
+			for name := range entries {
+				path, ok := files[name]
+				if !ok {
+					continue
+				}
+				if _, err := os.Stat(path); err == nil {
+					conflicts = append(conflicts, path)
+				}
+			}
+			if len(conflicts) > 0 && !force {
+				slices.Sort(conflicts)
+				return fmt.Errorf("would overwrite %s; use --force to replace", strings.Join(conflicts, ", "))
+			}
+
+			for _, name := range slices.Sorted(maps.Keys(entries)) {
+				path, ok := files[name]
+				if !ok {
+					continue
+				}
+				if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+					return err
+				}
+				if err := os.WriteFile(path, entries[name], 0o600); err != nil {
+					return err
+				}
+				fmt.Fprintf(cmd.ErrOrStderr(), "restored %s\n", path)
+			}
+			if data, ok := entries[entrySessions]; ok {
+				var sessions map[string]*stockal.LoginData
+				if err := json.Unmarshal(data, &sessions); err != nil {
+					return fmt.Errorf("invalid sessions in backup: %w", err)
+				}
+				for _, profile := range slices.Sorted(maps.Keys(sessions)) {
+					store, _, err := profileTokenStore(cmd.Context(), profile)
+					if err == nil {
+						err = store.Save(cmd.Context(), sessions[profile])
+					}
+					if err != nil {
+						fmt.Fprintf(cmd.ErrOrStderr(), "warning: session for profile %s not restored: %v\n", profile, err)
+						continue
+					}
+					fmt.Fprintf(cmd.ErrOrStderr(), "restored session for profile %s\n", profile)
+				}
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&dbPath, "db", defaultHistoryDBPath(), "where to restore the stockal-snapshotd database")
+	cmd.Flags().BoolVar(&force, "force", false, "overwrite existing files")
+	return cmd
+}
+
+// savedSessions returns the saved session of each configured profile that has
+// one, whether it is kept in the keyring or in a session file.
+func (o *globalOptions) savedSessions(cmd *cobra.Command) (map[string]*stockal.LoginData, error) {
+	cfg, err := loadConfig(o.configPath)
+	if err != nil {
+		return nil, err
+	}
+	profiles := append([]string{"default"}, slices.Collect(maps.Keys(cfg.Profiles))...)
+
+	sessions := map[string]*stockal.LoginData{}
+	for _, profile := range profiles {
+		_, token, err := profileTokenStore(cmd.Context(), profile)
+		if err != nil {
+			return nil, err
+		}
+		if token != nil {
+			sessions[profile] = token
+		}
+	}
+	return sessions, nil
+}
+
+// readPassphrase reads the backup passphrase from the environment or, on a
+// terminal, prompts for it; confirm asks for it twice.
+func readPassphrase(cmd *cobra.Command, confirm bool) ([]byte, error) {
+	if p := os.Getenv(envBackupPassphrase); p != "" {
+		return []byte(p), nil
+	}
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return nil, fmt.Errorf("set %s or run in a terminal to enter the passphrase", envBackupPassphrase)
+	}
+
+	fmt.Fprint(cmd.ErrOrStderr(), "Passphrase: ")
+	passphrase, err := term.ReadPassword(fd)
+	fmt.Fprintln(cmd.ErrOrStderr())
+	if err != nil {
+		return nil, err
+	}
+	if len(passphrase) == 0 {
+		return nil, errors.New("empty passphrase")
+	}
+	if confirm {
+		fmt.Fprint(cmd.ErrOrStderr(), "Repeat passphrase: ")
+		again, err := term.ReadPassword(fd)
+		fmt.Fprintln(cmd.ErrOrStderr())
+		if err != nil {
+			return nil, err
+		}
+		if !bytes.Equal(passphrase, again) {
+			return nil, errors.New("passphrases do not match")
+		}
+	}
+	return passphrase, nil
+}
+
+// sealBackup encrypts data with AES-256-GCM under a key derived from the
+// passphrase with scrypt. The output is the magic, salt, nonce and ciphertext.
+func sealBackup(data, passphrase []byte) ([]byte, error) {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	aead, err := backupCipher(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	out := append([]byte(backupMagic), salt...)
+	out = append(out, nonce...)
+	return aead.Seal(out, nonce, data, []byte(backupMagic)), nil
+}
+
+// openBackup decrypts an archive written by sealBackup.
+func openBackup(sealed, passphrase []byte) ([]byte, error) {
+	rest, ok := bytes.CutPrefix(sealed, []byte(backupMagic))
+	if !ok || len(rest) < 16 {
+		return nil, errors.New("not a stockalctl backup")
+	}
+	salt, rest := rest[:16], rest[16:]
+	aead, err := backupCipher(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	if len(rest) < aead.NonceSize() {
+		return nil, errors.New("backup is truncated")
+	}
+	nonce, ciphertext := rest[:aead.NonceSize()], rest[aead.NonceSize():]
+	data, err := aead.Open(nil, nonce, ciphertext, []byte(backupMagic))
+	if err != nil {
+		return nil, errors.New("wrong passphrase or corrupted backup")
+	}
+	return data, nil
+}
+
+func backupCipher(passphrase, salt []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key(passphrase, salt, 1<<15, 8, 1, 32)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func writeTarEntry(tw *tar.Writer, name string, data []byte) error {
+	err := tw.WriteHeader(&tar.Header{
+		Name:    name,
+		Mode:    0o600,
+		Size:    int64(len(data)),
+		ModTime: time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+	_, err = tw.Write(data)
+	return err
+}
+
+// readTarEntries returns the contents of a gzipped tar archive by entry name.
+func readTarEntries(archive []byte) (map[string][]byte, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(archive))
+	if err != nil {
+		return nil, err
+	}
+	tr := tar.NewReader(gz)
+	entries := map[string][]byte{}
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return entries, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, err
+		}
+		entries[hdr.Name] = data
+	}
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	gokeyring "github.com/zalando/go-keyring"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+)
+
+func TestBackupRoundTrip(t *testing.T) {
+	var archive bytes.Buffer
+	gz := gzip.NewWriter(&archive)
+	tw := tar.NewWriter(gz)
+	for name, data := range map[string]string{entryConfig: "profiles: {}\n", entryAlerts: "[]"} {
+		if err := writeTarEntry(tw, name, []byte(data)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	passphrase := []byte("correct horse battery staple")
+	sealed, err := sealBackup(archive.Bytes(), passphrase)
+	if err != nil {
+		t.Fatalf("sealBackup: %v", err)
+	}
+	if bytes.Contains(sealed, []byte("profiles")) {
+		t.Error("sealed backup contains the plaintext")
+	}
+	if again, err := sealBackup(archive.Bytes(), passphrase); err != nil {
+		t.Fatalf("sealBackup: %v", err)
+	} else if bytes.Equal(sealed, again) {
+		t.Error("sealing twice gave the same output; want a fresh salt and nonce")
+	}
+
+	opened, err := openBackup(sealed, passphrase)
+	if err != nil {
+		t.Fatalf("openBackup: %v", err)
+	}
+	entries, err := readTarEntries(opened)
+	if err != nil {
+		t.Fatalf("readTarEntries: %v", err)
+	}
+	if len(entries) != 2 || string(entries[entryConfig]) != "profiles: {}\n" || string(entries[entryAlerts]) != "[]" {
+		t.Errorf("restored entries %q, want the config and alerts", entries)
+	}
+}
+
+func TestOpenBackupFails(t *testing.T) {
+	passphrase := []byte("correct horse battery staple")
+	sealed, err := sealBackup([]byte("archive"), passphrase)
+	if err != nil {
+		t.Fatalf("sealBackup: %v", err)
+	}
+	tampered := bytes.Clone(sealed)
+	tampered[len(tampered)-1] ^= 1
+
+	tests := []struct {
+		name       string
+		sealed     []byte
+		passphrase []byte
+	}{
+		{name: "wrong passphrase", sealed: sealed, passphrase: []byte("Correct horse battery staple")},
+		{name: "tampered", sealed: tampered, passphrase: passphrase},
+		{name: "truncated", sealed: sealed[:len(backupMagic)+20], passphrase: passphrase},
+		{name: "not a backup", sealed: []byte("STOCKALBAK2\n" + string(sealed[len(backupMagic):])), passphrase: passphrase},
+		{name: "empty", passphrase: passphrase},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if data, err := openBackup(tt.sealed, tt.passphrase); err == nil {
+				t.Errorf("openBackup = %q, want an error", data)
+			}
+		})
+	}
+}
+
+func TestBackupFileSessions(t *testing.T) {
+	// Without a keyring, sessions are kept in files under the config directory
+	gokeyring.MockInitWithError(errors.New("no keyring"))
+	t.Cleanup(gokeyring.MockInit)
+	t.Setenv(envBackupPassphrase, "correct horse battery staple")
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	dir := t.TempDir()
+	opts := &globalOptions{configPath: filepath.Join(dir, "config.yaml")}
+	if err := os.WriteFile(opts.configPath, []byte("profiles:\n  work: {}\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	session := &stockal.LoginData{AccessToken: "work-token", RefreshToken: "work-refresh"}
+	if err := stockal.NewFileTokenStore(sessionFilePath("work")).Save(context.Background(), session); err != nil {
+		t.Fatal(err)
+	}
+
+	archive := filepath.Join(dir, "stockal.bak")
+	backup := newBackupCmd(opts)
+	backup.SetArgs([]string{"--out", archive, "--db", filepath.Join(dir, "history.db")})
+	backup.SetErr(&bytes.Buffer{})
+	if err := backup.ExecuteContext(context.Background()); err != nil {
+		t.Fatalf("backup: %v", err)
+	}
+
+	// Restore on a machine with a fresh config directory
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	opts = &globalOptions{configPath: filepath.Join(t.TempDir(), "config.yaml")}
+	restore := newRestoreCmd(opts)
+	restore.SetArgs([]string{archive, "--db", filepath.Join(dir, "restored.db")})
+	var stderr bytes.Buffer
+	restore.SetErr(&stderr)
+	if err := restore.ExecuteContext(context.Background()); err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+
+	restored, err := stockal.NewFileTokenStore(sessionFilePath("work")).Load(context.Background())
+	if err != nil || restored == nil || restored.AccessToken != "work-token" || restored.RefreshToken != "work-refresh" {
+		t.Errorf("restored session %+v, %v; want the backed up session (output %q)", restored, err, stderr.String())
+	}
+}
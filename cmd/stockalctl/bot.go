@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+	"github.com/adjaecent/unofficial-stockal-api/events"
+	"github.com/adjaecent/unofficial-stockal-api/notify"
+)
+
+// botHelp lists the commands the Telegram bot answers.
+const botHelp = "/summary - cash and portfolio totals\n" +
+	"/portfolio - holdings by value\n" +
+	"/quote SYMBOL... - latest prices"
+
+func newBotCmd(opts *globalOptions) *cobra.Command {
+	return &cobra.Command{
+		Use:   "bot",
+		Short: "Answer account questions from Telegram",
+		Long: "Run the profile's Telegram bot in the foreground, answering /summary, /portfolio\n" +
+			"and /quote SYMBOL. Only messages from the configured chat are answered.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			bot, err := opts.telegram(cmd.Context())
+			if err != nil {
+				return err
+			}
+			if bot == nil {
+				return errors.New("bot needs a telegram section in the profile")
+			}
+
+			client, err := opts.session(cmd)
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintln(cmd.ErrOrStderr(), "Telegram bot running; press Ctrl+C to stop")
+			return bot.Serve(cmd.Context(), func(ctx context.Context, command string, args []string) (string, error) {
+				return answerBot(ctx, client, command, args)
+			})
+		},
+	}
+}
+
+// answerBot handles one bot command.
+func answerBot(ctx context.Context, client stockal.StockalClient, command string, args []string) (string, error) {
+	switch command {
+	case "start", "help":
+		return botHelp, nil
+
+	case "summary":
+		resp, err := client.Account().Summary(ctx)
+		if err != nil {
+			return "", err
+		}
+		s := resp.Data
+		return fmt.Sprintf("Cash: %s\nInvested: %s\nValue: %s\nGain: %s",
+			money(s.AccountSummary.CashAvailableForTrade),
+			money(s.PortfolioSummary.TotalInvestmentAmount),
+			money(s.PortfolioSummary.TotalCurrentValue),
+			money(s.PortfolioSummary.TotalGain())), nil
+
+	case "portfolio":
+		snapshot, err := stockal.TakeSnapshot(ctx, client)
+		if err != nil {
+			return "", err
+		}
+		return notify.Text(events.NewSnapshotEvent("", snapshot)), nil
+
+	case "quote":
+		if len(args) == 0 {
+			return "Usage: /quote SYMBOL...", nil
+		}
+		for i := range args {
+			args[i] = strings.ToUpper(args[i])
+		}
+		quotes, err := client.GetQuotes(ctx, args...)
+		if err != nil {
+			return "", err
+		}
+		var lines []string
+		for _, symbol := range args {
+			q, ok := quotes.Quote(symbol)
+			if !ok {
+				lines = append(lines, symbol+": no quote")
+				continue
+			}
+			lines = append(lines, fmt.Sprintf("%s: %s (%s)", symbol, money(q.Price), percent(q.Price-q.PriorClose, q.PriorClose)))
+		}
+		return strings.Join(lines, "\n"), nil
+	}
+	return "Unknown command. Try:\n" + botHelp, nil
+}
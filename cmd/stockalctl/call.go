@@ -0,0 +1,123 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+	"github.com/adjaecent/unofficial-stockal-api/registry"
+)
+
+// endpointGroupInfo is an endpoint group as "call" lists it.
+type endpointGroupInfo struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// endpointInfo is an endpoint as "call GROUP" lists it.
+type endpointInfo struct {
+	Name        string `json:"name"`
+	Method      string `json:"method"`
+	Path        string `json:"path"`
+	Description string `json:"description"`
+}
+
+func newCallCmd(opts *globalOptions) *cobra.Command {
+	return &cobra.Command{
+		Use:   "call [group [endpoint [param=value...]]]",
+		Short: "Call an API endpoint added by an extension",
+		Long: "Call an endpoint of a group registered by an extension (see package registry)\n" +
+			"and print the response as JSON, whatever the output format. Parameters fill the\n" +
+			"endpoint's path and are sent as its query string or JSON body. Without an\n" +
+			"endpoint, the registered groups or a group's endpoints are listed.",
+		Example: "  stockalctl call\n  stockalctl call news\n  stockalctl call news get symbol=AAPL",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				groups := registry.EndpointGroups()
+				if len(groups) == 0 {
+					return errors.New("no endpoint groups registered; build stockalctl with an extension that adds some")
+				}
+				infos := make([]endpointGroupInfo, len(groups))
+				records := make([][]string, len(groups))
+				for i, g := range groups {
+					infos[i] = endpointGroupInfo{Name: g.Name, Description: g.Description}
+					records[i] = []string{g.Name, g.Description}
+				}
+				return opts.write(cmd.OutOrStdout(), result{
+					value:   infos,
+					columns: []string{"name", "description"},
+					records: records,
+					table: func(w io.Writer) error {
+						t := newTable(w, "GROUP", "DESCRIPTION")
+						for _, g := range groups {
+							t.row(g.Name, g.Description)
+						}
+						return t.flush()
+					},
+				})
+			}
+
+			group, ok := registry.Endpoints(args[0])
+			if !ok {
+				return fmt.Errorf("unknown endpoint group %q", args[0])
+			}
+			if len(args) == 1 {
+				infos := make([]endpointInfo, len(group.Endpoints))
+				records := make([][]string, len(group.Endpoints))
+				for i, e := range group.Endpoints {
+					infos[i] = endpointInfo{Name: e.Name, Method: e.Method, Path: e.Path, Description: e.Description}
+					records[i] = []string{e.Name, e.Method, e.Path, e.Description}
+				}
+				return opts.write(cmd.OutOrStdout(), result{
+					value:   infos,
+					columns: []string{"name", "method", "path", "description"},
+					records: records,
+					table: func(w io.Writer) error {
+						t := newTable(w, "ENDPOINT", "METHOD", "PATH", "DESCRIPTION")
+						for _, e := range group.Endpoints {
+							t.row(e.Name, e.Method, e.Path, e.Description)
+						}
+						return t.flush()
+					},
+				})
+			}
+
+			endpoint, ok := group.Endpoint(args[1])
+			if !ok {
+				return fmt.Errorf("unknown endpoint %q in group %s", args[1], group.Name)
+			}
+			if opts.output == formatCSV {
+				return errors.New("extension responses can only be printed as JSON")
+			}
+			params := map[string]string{}
+			for _, arg := range args[2:] {
+				k, v, ok := strings.Cut(arg, "=")
+				if !ok {
+					return fmt.Errorf("parameter %q is not param=value", arg)
+				}
+				params[k] = v
+			}
+
+			client, err := opts.session(cmd)
+			if err != nil {
+				return err
+			}
+			caller, ok := client.(stockal.Caller)
+			if !ok {
+				return errors.New("the client cannot call extension endpoints")
+			}
+			var resp json.RawMessage
+			if err := endpoint.Call(cmd.Context(), caller, params, &resp); err != nil {
+				return err
+			}
+			enc := json.NewEncoder(cmd.OutOrStdout())
+			enc.SetIndent("", "  ")
+			return enc.Encode(resp)
+		},
+	}
+}
@@ -0,0 +1,327 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+)
+
+// loginStatus is the machine-readable output of the login command. Tokens are
+// deliberately omitted so they do not end up in shell history or logs.
+type loginStatus struct {
+	Message            string `json:"message"`
+	ExpiryAccessToken  string `json:"expiryAccessToken"`
+	ExpiryRefreshToken string `json:"expiryRefreshToken"`
+}
+
+func newLoginCmd(opts *globalOptions) *cobra.Command {
+	return &cobra.Command{
+		Use:   "login",
+		Short: "Log in and save the session in the OS keyring or config directory",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			_, resp, err := opts.login(cmd)
+			if err != nil {
+				return err
+			}
+
+			status := loginStatus{
+				Message:            resp.Message,
+				ExpiryAccessToken:  resp.Data.ExpiryAccessToken.String(),
+				ExpiryRefreshToken: resp.Data.ExpiryRefreshToken.String(),
+			}
+			return opts.write(cmd.OutOrStdout(), result{
+				value:   status,
+				columns: []string{"message", "expiryAccessToken", "expiryRefreshToken"},
+				records: [][]string{{status.Message, status.ExpiryAccessToken, status.ExpiryRefreshToken}},
+				table: func(w io.Writer) error {
+					t := newTable(w, "FIELD", "VALUE")
+					t.row("Status", status.Message)
+					t.row("Access token expires", status.ExpiryAccessToken)
+					t.row("Refresh token expires", status.ExpiryRefreshToken)
+					return t.flush()
+				},
+			})
+		},
+	}
+}
+
+func newLogoutCmd(opts *globalOptions) *cobra.Command {
+	return &cobra.Command{
+		Use:   "logout",
+		Short: "Remove the saved session",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if store, _ := opts.tokenStore(cmd); store != nil {
+				if err := opts.newClient(store).Auth().Logout(cmd.Context()); err != nil {
+					return err
+				}
+			}
+			opts.client = nil
+			fmt.Fprintf(cmd.ErrOrStderr(), "Logged out of profile %q\n", opts.profileKey)
+			return nil
+		},
+	}
+}
+
+func newSummaryCmd(opts *globalOptions) *cobra.Command {
+	return &cobra.Command{
+		Use:   "summary",
+		Short: "Show cash balances and portfolio totals",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := opts.session(cmd)
+			if err != nil {
+				return err
+			}
+			summary, err := client.Account().Summary(cmd.Context())
+			if err != nil {
+				return err
+			}
+			return opts.write(cmd.OutOrStdout(), summaryResult(summary.Data))
+		},
+	}
+}
+
+func summaryResult(data stockal.AccountSummaryData) result {
+	account := data.AccountSummary
+	portfolio := data.PortfolioSummary
+
+	return result{
+		value: data,
+		columns: []string{
+			"utcTime", "accountSummary.cashAvailableForTrade", "accountSummary.cashAvailableForWithdrawal",
+			"accountSummary.cashBalance", "accountSummary.goodFaithViolations", "accountSummary.restricted",
+			"unsettledAmount", "portfolioSummary.stockPortfolio.currentValue", "portfolioSummary.etfPortfolio.currentValue",
+			"portfolioSummary.stackPortfolio.currentValue", "portfolioSummary.totalInvestmentAmount",
+			"portfolioSummary.totalCurrentValue",
+		},
+		records: [][]string{{
+			data.UTCTime.String(), num(account.CashAvailableForTrade), num(account.CashAvailableForWithdrawal),
+			num(account.CashBalance), account.GoodFaithViolations, strconv.FormatBool(account.Restricted),
+			num(data.UnsettledAmount), num(portfolio.StockPortfolio.CurrentValue), num(portfolio.ETFPortfolio.CurrentValue),
+			num(portfolio.StackPortfolio.CurrentValue), num(portfolio.TotalInvestmentAmount),
+			num(portfolio.TotalCurrentValue),
+		}},
+		table: func(w io.Writer) error {
+			t := newTable(w, "FIELD", "VALUE")
+			t.row("As of", asOf(data.AsOf()))
+			t.row("Cash available for trade", money(account.CashAvailableForTrade))
+			t.row("Cash available for withdrawal", money(account.CashAvailableForWithdrawal))
+			t.row("Cash balance", money(account.CashBalance))
+			t.row("Unsettled amount", money(data.UnsettledAmount))
+			t.row("Good faith violations", account.GoodFaithViolations)
+			t.row("Restricted", fmt.Sprint(account.Restricted))
+			t.row("Stocks", money(portfolio.StockPortfolio.CurrentValue))
+			t.row("ETFs", money(portfolio.ETFPortfolio.CurrentValue))
+			t.row("Stacks", money(portfolio.StackPortfolio.CurrentValue))
+			t.row("Total invested", money(portfolio.TotalInvestmentAmount))
+			t.row("Total value", money(portfolio.TotalCurrentValue))
+			t.row("Total gain/loss", money(portfolio.TotalGain()))
+			return t.flush()
+		},
+	}
+}
+
+func newPortfolioCmd(opts *globalOptions) *cobra.Command {
+	var (
+		watch       bool
+		interval    time.Duration
+		allProfiles bool
+		params      stockal.PortfolioParams
+		category    string
+		desc        bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "portfolio",
+		Short: "List all holdings",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if watch && opts.output != formatTable {
+				return errors.New("--watch only supports table output")
+			}
+			if allProfiles {
+				if cmd.Flags().Changed("profile") {
+					return errors.New("--all-profiles cannot be combined with --profile")
+				}
+				return opts.writeAllProfiles(cmd)
+			}
+
+			client, err := opts.session(cmd)
+			if err != nil {
+				return err
+			}
+			if watch {
+				return watchPortfolio(cmd.Context(), cmd.OutOrStdout(), client, interval)
+			}
+
+			if category != "" {
+				params.Category = stockal.Category(strings.ToLower(category))
+				if !params.Category.IsKnown() {
+					return fmt.Errorf("unknown category %q (want stock, etf or stack)", category)
+				}
+			}
+			if desc {
+				params.Order = stockal.SortDescending
+			}
+			portfolio, err := client.Portfolio().DetailWithParams(cmd.Context(), params)
+			if err != nil {
+				return err
+			}
+			return opts.write(cmd.OutOrStdout(), portfolioResult(portfolio.Data.Holdings))
+		},
+	}
+	cmd.Flags().BoolVar(&watch, "watch", false, "redraw the holdings table periodically")
+	cmd.Flags().DurationVar(&interval, "interval", 30*time.Second, "refresh interval for --watch")
+	cmd.Flags().BoolVar(&allProfiles, "all-profiles", false, "combine the holdings of every configured profile")
+	cmd.Flags().StringVar(&category, "category", "", "show only holdings of this category: stock, etf or stack")
+	cmd.Flags().StringVar(&params.Symbol, "symbol", "", "show only the holding of this symbol")
+	cmd.Flags().IntVar(&params.Limit, "limit", 0, "show at most this many holdings (0 for all)")
+	cmd.Flags().IntVar(&params.Offset, "offset", 0, "skip this many holdings")
+	cmd.Flags().StringVar(&params.SortBy, "sort", "", "sort holdings by this field, e.g. symbol")
+	cmd.Flags().BoolVar(&desc, "desc", false, "sort in descending order")
+	cmd.MarkFlagsMutuallyExclusive("watch", "all-profiles")
+	for _, flag := range []string{"category", "symbol", "limit", "offset", "sort", "desc"} {
+		cmd.MarkFlagsMutuallyExclusive("watch", flag)
+		cmd.MarkFlagsMutuallyExclusive("all-profiles", flag)
+	}
+	return cmd
+}
+
+func portfolioResult(holdings []stockal.Holding) result {
+	records := make([][]string, 0, len(holdings))
+	for _, h := range holdings {
+		records = append(records, holdingRecord(h))
+	}
+
+	return result{
+		value:   holdings,
+		columns: holdingColumns,
+		records: records,
+		table: func(w io.Writer) error {
+			return writePortfolio(w, holdings)
+		},
+	}
+}
+
+func writePortfolio(w io.Writer, holdings []stockal.Holding) error {
+	if len(holdings) == 0 {
+		_, err := fmt.Fprintln(w, "No holdings yet.")
+		return err
+	}
+	t := newTable(w, "SYMBOL", "COMPANY", "UNITS", "PRICE", "VALUE", "INVESTED", "GAIN/LOSS", "GAIN %")
+	for _, h := range holdings {
+		t.row(h.Symbol, h.Company, units(h.TotalUnit), money(h.Price), money(h.CurrentValue()),
+			money(h.TotalInvestment), money(h.UnrealizedGain()), percent(h.UnrealizedGain(), h.TotalInvestment))
+	}
+	return t.flush()
+}
+
+// watchPortfolio redraws the holdings table every interval until ctx is cancelled.
+// Refreshes are skipped while the US market is closed, since prices do not move,
+// and, when the API rate limits a refresh, until the wait it asks for has passed.
+// Meanwhile the last holdings fetched are shown. Until holdings have been fetched,
+// and once a rate limit wait is over, it refreshes even with the market closed.
+func watchPortfolio(ctx context.Context, w io.Writer, client stockal.PortfolioReader, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var (
+		holdings []stockal.Holding
+		// resume is when refreshing may start again after being rate limited
+		resume time.Time
+	)
+	for {
+		now := time.Now()
+		status := "market closed, showing last prices"
+		switch {
+		case now.Before(resume):
+			status = "rate limited, refreshing after " + resume.Format("15:04:05")
+		case holdings == nil || !resume.IsZero() || stockal.IsMarketOpen(now):
+			portfolio, err := client.Portfolio().Detail(ctx)
+			var rateLimit *stockal.RateLimitError
+			switch {
+			case errors.As(err, &rateLimit) && rateLimit.RetryAfter > 0:
+				resume = now.Add(rateLimit.RetryAfter)
+				status = "rate limited, refreshing after " + resume.Format("15:04:05")
+			case err != nil:
+				status = "refresh failed: " + err.Error()
+			default:
+				holdings, resume = portfolio.Data.Holdings, time.Time{}
+				if holdings == nil {
+					holdings = []stockal.Holding{}
+				}
+				status = "updated " + now.Format("15:04:05")
+			}
+		}
+
+		fmt.Fprint(w, "\033[H\033[2J")
+		if err := writePortfolio(w, holdings); err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "\n%s, refreshing every %s (ctrl+c to exit)\n", status, interval)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func newHoldingsCmd(opts *globalOptions) *cobra.Command {
+	return &cobra.Command{
+		Use:   "holdings <symbol>",
+		Short: "Show details for a single holding",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := opts.session(cmd)
+			if err != nil {
+				return err
+			}
+			portfolio, err := client.Portfolio().Detail(cmd.Context())
+			if err != nil {
+				return err
+			}
+
+			for _, h := range portfolio.Data.Holdings {
+				if strings.EqualFold(h.Symbol, args[0]) {
+					return opts.write(cmd.OutOrStdout(), holdingResult(h))
+				}
+			}
+			return fmt.Errorf("no holding found for symbol %q", args[0])
+		},
+	}
+}
+
+func holdingResult(h stockal.Holding) result {
+	return result{
+		value:   h,
+		columns: holdingColumns,
+		records: [][]string{holdingRecord(h)},
+		table: func(w io.Writer) error {
+			t := newTable(w, "FIELD", "VALUE")
+			t.row("Symbol", h.Symbol)
+			t.row("Company", h.Company)
+			t.row("Category", string(h.Category))
+			t.row("Units", units(h.TotalUnit))
+			t.row("Price", money(h.Price))
+			t.row("Prior close", money(h.PriorClose))
+			t.row("Value", money(h.CurrentValue()))
+			t.row("Invested", money(h.TotalInvestment))
+			t.row("Gain/loss", money(h.UnrealizedGain()))
+			t.row("Gain %", percent(h.UnrealizedGain(), h.TotalInvestment))
+			t.row("Sell only", fmt.Sprint(h.SellOnly))
+			return t.flush()
+		},
+	}
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+	"github.com/adjaecent/unofficial-stockal-api/stockaltest"
+)
+
+func TestWatchPortfolioRateLimited(t *testing.T) {
+	calls := 0
+	client := &stockaltest.MockClient{
+		PortfolioDetailFunc: func(ctx context.Context) (*stockal.PortfolioDetailResponse, error) {
+			calls++
+			return nil, &stockal.RateLimitError{RetryAfter: time.Hour, Limit: -1, Remaining: -1}
+		},
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	var out bytes.Buffer
+	if err := watchPortfolio(ctx, &out, client, time.Millisecond); err != nil {
+		t.Fatalf("watchPortfolio: %v", err)
+	}
+	// Whether or not the market is open, nothing is fetched for an hour
+	if calls != 1 {
+		t.Errorf("fetched the portfolio %d times, want once", calls)
+	}
+	if redraws := strings.Count(out.String(), "rate limited, refreshing after"); redraws < 2 {
+		t.Errorf("output %q, want every redraw to report the wait", out.String())
+	}
+}
+
+func TestWatchPortfolio(t *testing.T) {
+	client := &stockaltest.MockClient{}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var out bytes.Buffer
+	if err := watchPortfolio(ctx, &out, client, time.Minute); err != nil {
+		t.Fatalf("watchPortfolio: %v", err)
+	}
+	symbol := stockaltest.Portfolio().Data.Holdings[0].Symbol
+	if !strings.Contains(out.String(), symbol) || !strings.Contains(out.String(), "updated ") {
+		t.Errorf("output %q, want the fetched holdings", out.String())
+	}
+}
+
+func TestWatchPortfolioRetriesAfterRateLimit(t *testing.T) {
+	calls := 0
+	client := &stockaltest.MockClient{
+		PortfolioDetailFunc: func(ctx context.Context) (*stockal.PortfolioDetailResponse, error) {
+			calls++
+			if calls == 1 {
+				return nil, &stockal.RateLimitError{RetryAfter: 10 * time.Millisecond, Limit: -1, Remaining: -1}
+			}
+			return stockaltest.Portfolio(), nil
+		},
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	var out bytes.Buffer
+	if err := watchPortfolio(ctx, &out, client, time.Millisecond); err != nil {
+		t.Fatalf("watchPortfolio: %v", err)
+	}
+	// Whether or not the market is open, the holdings are fetched once the wait is over
+	if calls < 2 {
+		t.Errorf("fetched the portfolio %d times, want it retried after the wait", calls)
+	}
+	symbol := stockaltest.Portfolio().Data.Holdings[0].Symbol
+	if !strings.Contains(out.String(), symbol) {
+		t.Errorf("output %q, want the holdings fetched after the wait", out.String())
+	}
+}
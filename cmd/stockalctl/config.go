@@ -0,0 +1,173 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// envConfig overrides the configuration file location.
+const envConfig = "STOCKAL_CONFIG"
+
+// config is the stockalctl configuration file.
+//
+//	default_profile: personal
+//	profiles:
+//	  personal:
+//	    output: table
+//	  work:
+//	    base_url: https://api-v2.stockal.com
+//	    secrets: aws-sm:stockal/work/
+//	    tenant: https://partner.example.com
+//	    output: json
+//	    credentials:
+//	      username: alice
+//	      password_env: STOCKAL_WORK_PASSWORD
+//	    smtp:
+//	      host: smtp.example.com
+//	      username: alice@example.com
+//	      password_env: SMTP_PASSWORD
+//	      from: alice@example.com
+//	      to: [alice@example.com, bob@example.com]
+//	    webhooks:
+//	      - url: https://example.com/hooks/stockal
+//	        secret_env: STOCKAL_WEBHOOK_SECRET
+//	    telegram:
+//	      token_env: TELEGRAM_BOT_TOKEN
+//	      chat_id: "123456789"
+//	    slack:
+//	      webhook_url_env: SLACK_WEBHOOK_URL
+//	    discord:
+//	      webhook_url_env: DISCORD_WEBHOOK_URL
+//	    notifiers:
+//	      - type: ntfy
+//	        topic: stockal-alerts
+//	        token_env: NTFY_TOKEN
+type config struct {
+	DefaultProfile string             `yaml:"default_profile"`
+	Profiles       map[string]profile `yaml:"profiles"`
+}
+
+// profile holds the settings for one account.
+type profile struct {
+	// BaseURL overrides the API base URL
+	BaseURL string `yaml:"base_url"`
+	// Tenant is the partner-brand web origin the account belongs to
+	Tenant string `yaml:"tenant"`
+	// Output is the default output format
+	Output string `yaml:"output"`
+	// Secrets selects where secrets missing from the environment are read
+	// from, as a secrets.Open spec such as "file:/run/secrets"
+	Secrets string `yaml:"secrets"`
+	// Credentials tells stockalctl where to find the username and password
+	Credentials credentialsRef `yaml:"credentials"`
+	// SMTP configures email delivery of reports
+	SMTP smtpProfile `yaml:"smtp"`
+	// Webhooks receive account events from alert run and report
+	Webhooks []webhookProfile `yaml:"webhooks"`
+	// Telegram sends account events to a chat and configures the bot command
+	Telegram telegramProfile `yaml:"telegram"`
+	// Slack posts account events to a channel
+	Slack slackProfile `yaml:"slack"`
+	// Discord posts account events to a channel webhook
+	Discord discordProfile `yaml:"discord"`
+	// Notifiers send account events to notifiers added by extensions
+	Notifiers []notifierProfile `yaml:"notifiers"`
+}
+
+// credentialsRef references credentials without storing the password itself.
+// The *_env settings here and in the other sections name secrets: environment
+// variables, or secrets of the profile's secrets backend.
+type credentialsRef struct {
+	// Username is the login username; UsernameEnv is used if it is empty
+	Username string `yaml:"username"`
+	// UsernameEnv names the secret holding the username
+	UsernameEnv string `yaml:"username_env"`
+	// PasswordEnv names the secret holding the password
+	PasswordEnv string `yaml:"password_env"`
+}
+
+// resolve returns the username and password the reference points to.
+func (r credentialsRef) resolve(secret secretFunc) (username, password string, err error) {
+	usernameEnv, passwordEnv := r.UsernameEnv, r.PasswordEnv
+	if usernameEnv == "" {
+		usernameEnv = envUsername
+	}
+	if passwordEnv == "" {
+		passwordEnv = envPassword
+	}
+
+	username = r.Username
+	if username == "" {
+		if username, err = secret(usernameEnv); err != nil {
+			return "", "", err
+		}
+	}
+	if password, err = secret(passwordEnv); err != nil {
+		return "", "", err
+	}
+
+	switch {
+	case username == "" && password == "":
+		return "", "", fmt.Errorf("set %s and %s to log in", usernameEnv, passwordEnv)
+	case username == "":
+		return "", "", fmt.Errorf("set %s to log in", usernameEnv)
+	case password == "":
+		return "", "", fmt.Errorf("set %s to log in", passwordEnv)
+	}
+	return username, password, nil
+}
+
+// defaultConfigPath returns the configuration file location, normally
+// ~/.config/stockal/config.yaml.
+func defaultConfigPath() string {
+	if path := os.Getenv(envConfig); path != "" {
+		return path
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "stockal", "config.yaml")
+}
+
+// loadConfig reads the configuration file at path. A missing file yields an empty config.
+func loadConfig(path string) (*config, error) {
+	cfg := &config{}
+	if path == "" {
+		return cfg, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read config: %w", err)
+	}
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("parse config %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// profile returns the named profile, or the default profile when name is empty.
+// With no name and no default profile, an empty profile is returned.
+func (c *config) profile(name string) (profile, error) {
+	if name == "" {
+		name = c.DefaultProfile
+	}
+	if name == "" {
+		return profile{}, nil
+	}
+
+	p, ok := c.Profiles[name]
+	if !ok {
+		return profile{}, fmt.Errorf("profile %q not found in config", name)
+	}
+	return p, nil
+}
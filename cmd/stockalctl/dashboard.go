@@ -0,0 +1,352 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/spf13/cobra"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+)
+
+func newDashboardCmd(opts *globalOptions) *cobra.Command {
+	var refresh time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "dashboard",
+		Short: "Interactive dashboard with live-refreshing holdings",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if opts.output != formatTable {
+				return errors.New("dashboard is interactive and only supports table output")
+			}
+
+			client, err := opts.session(cmd)
+			if err != nil {
+				return err
+			}
+
+			m := newDashboardModel(cmd.Context(), client, refresh)
+			_, err = tea.NewProgram(m, tea.WithAltScreen(), tea.WithContext(cmd.Context())).Run()
+			return err
+		},
+	}
+	cmd.Flags().DurationVar(&refresh, "refresh", 30*time.Second, "how often to refresh prices")
+	return cmd
+}
+
+// sortColumn identifies a sortable holdings column.
+type sortColumn int
+
+const (
+	sortBySymbol sortColumn = iota
+	sortByValue
+	sortByDayChange
+	sortByGain
+	sortByGainPercent
+	numSortColumns
+)
+
+func (c sortColumn) String() string {
+	return [...]string{"symbol", "value", "day change", "gain", "gain %"}[c]
+}
+
+// dashboardRow is a holding with its derived figures precomputed for display and sorting.
+type dashboardRow struct {
+	holding      stockal.Holding
+	value        float64
+	dayChange    float64
+	dayChangePct float64
+	gain         float64
+	gainPct      float64
+}
+
+func newDashboardRow(h stockal.Holding) dashboardRow {
+	return dashboardRow{
+		holding:      h,
+		value:        h.CurrentValue(),
+		dayChange:    h.DayChange(),
+		dayChangePct: h.DayChangePercent(),
+		gain:         h.UnrealizedGain(),
+		gainPct:      h.GainPercent(),
+	}
+}
+
+// Messages delivered to the dashboard model.
+type (
+	dataMsg struct{ state *stockal.AccountState }
+	errMsg  struct{ err error }
+	tickMsg time.Time
+)
+
+var (
+	headerStyle   = lipgloss.NewStyle().Bold(true)
+	selectedStyle = lipgloss.NewStyle().Reverse(true)
+	gainStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("2"))
+	lossStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("1"))
+	helpStyle     = lipgloss.NewStyle().Faint(true)
+)
+
+type dashboardModel struct {
+	ctx     context.Context
+	client  stockal.PortfolioReader
+	refresh time.Duration
+
+	summary *stockal.AccountSummaryData
+	rows    []dashboardRow
+	updated time.Time
+	loading bool
+	err     error
+
+	sortBy sortColumn
+	desc   bool
+	cursor int
+	detail bool
+}
+
+func newDashboardModel(ctx context.Context, client stockal.PortfolioReader, refresh time.Duration) dashboardModel {
+	return dashboardModel{
+		ctx:     ctx,
+		client:  client,
+		refresh: refresh,
+		loading: true,
+		sortBy:  sortByValue,
+		desc:    true,
+	}
+}
+
+func (m dashboardModel) Init() tea.Cmd {
+	return m.fetch()
+}
+
+// fetch loads the account summary and portfolio in the background,
+// concurrently if the client can.
+func (m dashboardModel) fetch() tea.Cmd {
+	return func() tea.Msg {
+		if f, ok := m.client.(stockal.AccountFetcher); ok {
+			state, err := f.FetchAll(m.ctx)
+			if err != nil {
+				return errMsg{err}
+			}
+			return dataMsg{state}
+		}
+
+		snapshot, err := stockal.TakeSnapshot(m.ctx, m.client)
+		if err != nil {
+			return errMsg{err}
+		}
+		return dataMsg{&stockal.AccountState{FetchedAt: snapshot.TakenAt, Summary: snapshot.Summary, Holdings: snapshot.Holdings}}
+	}
+}
+
+func (m dashboardModel) tick() tea.Cmd {
+	return tea.Tick(m.refresh, func(t time.Time) tea.Msg { return tickMsg(t) })
+}
+
+func (m dashboardModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case dataMsg:
+		m.loading, m.err = false, nil
+		m.summary, m.updated = &msg.state.Summary, msg.state.FetchedAt.Local()
+		selected := m.selectedSymbol()
+		m.rows = make([]dashboardRow, 0, len(msg.state.Holdings))
+		for _, h := range msg.state.Holdings {
+			m.rows = append(m.rows, newDashboardRow(h))
+		}
+		m.sortRows(selected)
+		return m, m.tick()
+
+	case errMsg:
+		m.loading, m.err = false, msg.err
+		return m, m.tick()
+
+	case tickMsg:
+		m.loading = true
+		return m, m.fetch()
+
+	case tea.KeyMsg:
+		return m.handleKey(msg)
+	}
+	return m, nil
+}
+
+func (m dashboardModel) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "q", "ctrl+c":
+		return m, tea.Quit
+	case "up", "k":
+		if !m.detail && m.cursor > 0 {
+			m.cursor--
+		}
+	case "down", "j":
+		if !m.detail && m.cursor < len(m.rows)-1 {
+			m.cursor++
+		}
+	case "enter":
+		m.detail = len(m.rows) > 0
+	case "esc", "backspace":
+		m.detail = false
+	case "s":
+		m.sortBy = (m.sortBy + 1) % numSortColumns
+		m.sortRows(m.selectedSymbol())
+	case "o":
+		m.desc = !m.desc
+		m.sortRows(m.selectedSymbol())
+	case "r":
+		if !m.loading {
+			m.loading = true
+			return m, m.fetch()
+		}
+	}
+	return m, nil
+}
+
+// selectedSymbol returns the symbol under the cursor, or "" if there is none.
+func (m dashboardModel) selectedSymbol() string {
+	if m.cursor < len(m.rows) {
+		return m.rows[m.cursor].holding.Symbol
+	}
+	return ""
+}
+
+// sortRows orders the holdings by the selected column and moves the cursor to the selected symbol.
+func (m *dashboardModel) sortRows(selected string) {
+	key := func(r dashboardRow) float64 {
+		switch m.sortBy {
+		case sortByValue:
+			return r.value
+		case sortByDayChange:
+			return r.dayChangePct
+		case sortByGain:
+			return r.gain
+		case sortByGainPercent:
+			return r.gainPct
+		}
+		return 0
+	}
+	sort.SliceStable(m.rows, func(i, j int) bool {
+		if m.sortBy == sortBySymbol {
+			return (m.rows[i].holding.Symbol < m.rows[j].holding.Symbol) != m.desc
+		}
+		return (key(m.rows[i]) < key(m.rows[j])) != m.desc
+	})
+
+	m.cursor = 0
+	for i, r := range m.rows {
+		if r.holding.Symbol == selected {
+			m.cursor = i
+		}
+	}
+}
+
+func (m dashboardModel) View() string {
+	var b strings.Builder
+
+	b.WriteString(m.summaryView())
+	b.WriteString("\n\n")
+	if m.detail && m.cursor < len(m.rows) {
+		b.WriteString(m.detailView(m.rows[m.cursor]))
+	} else {
+		b.WriteString(m.holdingsView())
+	}
+	b.WriteString("\n\n")
+
+	status := "updated " + m.updated.Format("15:04:05")
+	if m.loading {
+		status = "refreshing..."
+	}
+	if m.err != nil {
+		status = lossStyle.Render("error: " + m.err.Error())
+	}
+	b.WriteString(helpStyle.Render(fmt.Sprintf("%s | sort: %s | ↑/↓ move  enter details  esc back  s sort  o order  r refresh  q quit", status, m.sortBy)))
+	return b.String()
+}
+
+func (m dashboardModel) summaryView() string {
+	if m.summary == nil {
+		return headerStyle.Render("Stockal dashboard") + "  loading..."
+	}
+
+	account := m.summary.AccountSummary
+	portfolio := m.summary.PortfolioSummary
+	gain := portfolio.TotalGain()
+
+	var dayChange float64
+	for _, r := range m.rows {
+		dayChange += r.dayChange
+	}
+
+	return fmt.Sprintf("%s\nValue %s  Invested %s  Gain %s  Day %s  Cash %s",
+		headerStyle.Render("Stockal dashboard"),
+		money(portfolio.TotalCurrentValue),
+		money(portfolio.TotalInvestmentAmount),
+		signed(gain, money(gain)),
+		signed(dayChange, money(dayChange)),
+		money(account.CashAvailableForTrade),
+	)
+}
+
+func (m dashboardModel) holdingsView() string {
+	var b strings.Builder
+	b.WriteString(headerStyle.Render(fmt.Sprintf("%-8s %-28s %12s %10s %12s %9s %12s %9s",
+		"SYMBOL", "COMPANY", "UNITS", "PRICE", "VALUE", "DAY %", "GAIN", "GAIN %")))
+
+	for i, r := range m.rows {
+		line := fmt.Sprintf("%-8s %-28s %12s %10s %12s %9s %12s %9s",
+			r.holding.Symbol, truncate(r.holding.Company, 28), units(r.holding.TotalUnit),
+			money(r.holding.Price), money(r.value), fmt.Sprintf("%.2f%%", r.dayChangePct),
+			money(r.gain), fmt.Sprintf("%.2f%%", r.gainPct))
+		if i == m.cursor {
+			line = selectedStyle.Render(line)
+		}
+		b.WriteString("\n" + line)
+	}
+	return b.String()
+}
+
+func (m dashboardModel) detailView(r dashboardRow) string {
+	h := r.holding
+	fields := [][2]string{
+		{"Company", h.Company},
+		{"Category", string(h.Category)},
+		{"Units", units(h.TotalUnit)},
+		{"Price", money(h.Price)},
+		{"Prior close", money(h.PriorClose)},
+		{"Day change", signed(r.dayChange, fmt.Sprintf("%s (%.2f%%)", money(r.dayChange), r.dayChangePct))},
+		{"Value", money(r.value)},
+		{"Invested", money(h.TotalInvestment)},
+		{"Gain/loss", signed(r.gain, fmt.Sprintf("%s (%.2f%%)", money(r.gain), r.gainPct))},
+		{"Sell only", fmt.Sprint(h.SellOnly)},
+	}
+
+	var b strings.Builder
+	b.WriteString(headerStyle.Render(h.Symbol))
+	for _, f := range fields {
+		fmt.Fprintf(&b, "\n%-12s %s", f[0], f[1])
+	}
+	return b.String()
+}
+
+// signed colors text green or red depending on the sign of v.
+func signed(v float64, text string) string {
+	switch {
+	case v > 0:
+		return gainStyle.Render(text)
+	case v < 0:
+		return lossStyle.Render(text)
+	}
+	return text
+}
+
+func truncate(s string, n int) string {
+	if r := []rune(s); len(r) > n {
+		return string(r[:n-1]) + "…"
+	}
+	return s
+}
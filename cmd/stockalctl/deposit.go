@@ -0,0 +1,196 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+)
+
+func newDepositCmd(opts *globalOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "deposit",
+		Short: "Fund the account from an Indian bank and track deposits",
+	}
+	cmd.AddCommand(
+		newDepositInstructionsCmd(opts),
+		newDepositCreateCmd(opts),
+		newDepositStatusCmd(opts),
+	)
+	return cmd
+}
+
+func newDepositInstructionsCmd(opts *globalOptions) *cobra.Command {
+	return &cobra.Command{
+		Use:   "instructions",
+		Short: "Show the bank details to send money to and the LRS limit left",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := opts.session(cmd)
+			if err != nil {
+				return err
+			}
+			resp, err := client.GetFundingInstructions(cmd.Context())
+			if err != nil {
+				return err
+			}
+			return opts.write(cmd.OutOrStdout(), fundingInstructionsResult(resp.Data))
+		},
+	}
+}
+
+func newDepositCreateCmd(opts *globalOptions) *cobra.Command {
+	var (
+		deposit stockal.DepositRequest
+		method  string
+		guard   confirmFlags
+	)
+
+	cmd := &cobra.Command{
+		Use:     "create",
+		Short:   "Announce a deposit before sending the money",
+		Example: "  stockalctl deposit create --amount 1000 --pan ABCDE1234F --bank HDFC",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			deposit.Method = stockal.DepositMethod(strings.ToLower(method))
+			if err := deposit.Validate(); err != nil {
+				return err
+			}
+			client, err := opts.session(cmd)
+			if err != nil {
+				return err
+			}
+			instructions, err := client.GetFundingInstructions(cmd.Context())
+			if err != nil {
+				return err
+			}
+
+			lrs := instructions.Data.LRS
+			t := newTable(cmd.ErrOrStderr(), "DEPOSIT", "")
+			t.row("Amount", money(deposit.Amount))
+			t.row("Method", string(deposit.Method))
+			t.row("PAN", strings.ToUpper(deposit.PAN))
+			if deposit.RemittingBank != "" {
+				t.row("From", deposit.RemittingBank)
+			}
+			t.row("LRS left "+lrs.FinancialYear, money(lrs.Remaining()))
+			if err := t.flush(); err != nil {
+				return err
+			}
+			if deposit.Amount > lrs.Remaining() {
+				fmt.Fprintln(cmd.ErrOrStderr(), "warning: the deposit exceeds what is left of the LRS limit this financial year")
+			}
+			if guard.dryRun {
+				return nil
+			}
+			if err := guard.confirm(cmd, "Create this deposit?"); err != nil {
+				return err
+			}
+
+			resp, err := client.CreateDeposit(cmd.Context(), deposit)
+			if err != nil {
+				return err
+			}
+			if err := opts.write(cmd.OutOrStdout(), depositResult(resp.Data)); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.ErrOrStderr(), "Send the money to %s quoting %q; see \"deposit instructions\".\n", instructions.Data.BankName, resp.Data.Reference)
+			return nil
+		},
+	}
+	cmd.Flags().Float64Var(&deposit.Amount, "amount", 0, "dollar amount to be remitted")
+	cmd.Flags().StringVar(&method, "method", string(stockal.DepositWire), "how the money is sent: wire or partner_bank")
+	cmd.Flags().StringVar(&deposit.PAN, "pan", "", "the remitter's PAN")
+	cmd.Flags().StringVar(&deposit.RemittingBank, "bank", "", "the Indian bank sending the money")
+	cmd.Flags().StringVar(&deposit.SourceOfFunds, "source", "", "where the money comes from, as declared on the A2 form")
+	cmd.Flags().StringVar(&deposit.PurposeCode, "purpose", stockal.PurposeCodeEquity, "the RBI purpose code of the remittance")
+	cmd.MarkFlagRequired("amount")
+	cmd.MarkFlagRequired("pan")
+	guard.register(cmd)
+	return cmd
+}
+
+func newDepositStatusCmd(opts *globalOptions) *cobra.Command {
+	return &cobra.Command{
+		Use:   "status <deposit-id>",
+		Short: "Show a deposit's progress",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := opts.session(cmd)
+			if err != nil {
+				return err
+			}
+			resp, err := client.GetDepositStatus(cmd.Context(), args[0])
+			if err != nil {
+				return err
+			}
+			return opts.write(cmd.OutOrStdout(), depositResult(resp.Data))
+		},
+	}
+}
+
+// fundingInstructionsColumns are the CSV columns for funding instructions,
+// named after the FundingInstructions JSON fields.
+var fundingInstructionsColumns = []string{"beneficiaryName", "bankName", "accountNumber", "routingNumber", "swiftCode", "reference"}
+
+func fundingInstructionsResult(in stockal.FundingInstructions) result {
+	return result{
+		value:   in,
+		columns: fundingInstructionsColumns,
+		records: [][]string{{in.BeneficiaryName, in.BankName, in.AccountNumber, in.RoutingNumber, in.SwiftCode, in.Reference}},
+		table: func(w io.Writer) error {
+			t := newTable(w, "FIELD", "VALUE")
+			t.row("Beneficiary", in.BeneficiaryName)
+			t.row("Bank", in.BankName)
+			if in.BankAddress != "" {
+				t.row("Bank address", in.BankAddress)
+			}
+			t.row("Account number", in.AccountNumber)
+			if in.RoutingNumber != "" {
+				t.row("Routing number", in.RoutingNumber)
+			}
+			t.row("SWIFT", in.SwiftCode)
+			t.row("Reference", in.Reference)
+			t.row("LRS remitted "+in.LRS.FinancialYear, money(in.LRS.Remitted))
+			t.row("LRS left", money(in.LRS.Remaining()))
+			return t.flush()
+		},
+	}
+}
+
+// depositColumns are the CSV columns for deposits, named after the Deposit
+// JSON fields.
+var depositColumns = []string{"depositID", "amount", "amountReceived", "method", "status", "reference", "createdAt", "creditedAt"}
+
+func depositResult(d stockal.Deposit) result {
+	return result{
+		value:   d,
+		columns: depositColumns,
+		records: [][]string{{d.ID, num(d.Amount), num(d.AmountReceived), string(d.Method), string(d.Status), d.Reference, d.CreatedAt.String(), d.CreditedAt.String()}},
+		table: func(w io.Writer) error {
+			t := newTable(w, "FIELD", "VALUE")
+			t.row("Deposit ID", d.ID)
+			t.row("Amount", money(d.Amount))
+			if d.AmountReceived != 0 {
+				t.row("Received", money(d.AmountReceived))
+			}
+			t.row("Method", string(d.Method))
+			t.row("Status", string(d.Status))
+			t.row("Reference", d.Reference)
+			if d.AmountINR != 0 {
+				t.row("Debited", fmt.Sprintf("₹%.2f", d.AmountINR))
+			}
+			if d.TCS != 0 {
+				t.row("TCS", fmt.Sprintf("₹%.2f", d.TCS))
+			}
+			t.row("Created", d.CreatedAt.String())
+			if !d.CreditedAt.IsZero() {
+				t.row("Credited", d.CreditedAt.String())
+			}
+			return t.flush()
+		},
+	}
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"io"
+	"strconv"
+
+	"github.com/spf13/cobra"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+)
+
+func newDividendsCmd(opts *globalOptions) *cobra.Command {
+	var (
+		list         stockal.DividendOptions
+		since, until string
+		byYear       bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "dividends",
+		Short: "List the dividends credited, with tax withheld and reinvestment",
+		Example: "  stockalctl dividends --since 2024-01-01\n" +
+			"  stockalctl dividends --by-year   # totals per calendar year and symbol, for Form 1042-S",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var err error
+			if list.From, err = parseDateFlag("since", since); err != nil {
+				return err
+			}
+			if list.To, err = parseDateFlag("until", until); err != nil {
+				return err
+			}
+			if !list.To.IsZero() {
+				// --until includes the day given
+				list.To = list.To.AddDate(0, 0, 1)
+			}
+			if err := list.Validate(); err != nil {
+				return err
+			}
+
+			client, err := opts.session(cmd)
+			if err != nil {
+				return err
+			}
+			resp, err := client.GetDividends(cmd.Context(), list)
+			if err != nil {
+				return err
+			}
+			if byYear {
+				return opts.write(cmd.OutOrStdout(), dividendYearsResult(stockal.DividendsByYear(resp.Data)))
+			}
+			return opts.write(cmd.OutOrStdout(), dividendsResult(resp.Data))
+		},
+	}
+	cmd.Flags().StringVar(&list.Symbol, "symbol", "", "show only dividends paid by this symbol")
+	cmd.Flags().StringVar(&since, "since", "", "show only dividends paid on or after this date (YYYY-MM-DD)")
+	cmd.Flags().StringVar(&until, "until", "", "show only dividends paid on or before this date (YYYY-MM-DD)")
+	cmd.Flags().BoolVar(&byYear, "by-year", false, "total the dividends per calendar year and symbol")
+	return cmd
+}
+
+// dividendColumns are the CSV columns for dividends, named after the Dividend
+// JSON fields.
+var dividendColumns = []string{
+	"dividendID", "payDate", "symbol", "quantity", "amount", "withholding", "reinvestment", "reinvestedQuantity",
+}
+
+func dividendsResult(dividends []stockal.Dividend) result {
+	records := make([][]string, 0, len(dividends))
+	for _, d := range dividends {
+		records = append(records, []string{
+			d.ID, d.PayDate.String(), d.Symbol, num(d.Quantity), num(d.Amount), num(d.Withholding), string(d.Reinvestment), num(d.ReinvestedQuantity),
+		})
+	}
+
+	return result{
+		value:   dividends,
+		columns: dividendColumns,
+		records: records,
+		table: func(w io.Writer) error {
+			t := newTable(w, "PAID", "SYMBOL", "GROSS", "WITHHELD", "NET", "REINVESTED")
+			for _, d := range dividends {
+				t.row(d.PayDate.String(), d.Symbol, money(d.Amount), money(d.Withholding), money(d.Net()), string(d.Reinvestment))
+			}
+			return t.flush()
+		},
+	}
+}
+
+// dividendYearColumns are the CSV columns for yearly dividend totals, one
+// record per year and symbol.
+var dividendYearColumns = []string{"year", "symbol", "amount", "withholding"}
+
+func dividendYearsResult(years []stockal.DividendYear) result {
+	var records [][]string
+	for _, y := range years {
+		for _, s := range y.Symbols {
+			records = append(records, []string{strconv.Itoa(y.Year), s.Symbol, num(s.Amount), num(s.Withholding)})
+		}
+	}
+
+	return result{
+		value:   years,
+		columns: dividendYearColumns,
+		records: records,
+		table: func(w io.Writer) error {
+			t := newTable(w, "YEAR", "SYMBOL", "GROSS", "WITHHELD")
+			for _, y := range years {
+				for _, s := range y.Symbols {
+					t.row(strconv.Itoa(y.Year), s.Symbol, money(s.Amount), money(s.Withholding))
+				}
+				t.row(strconv.Itoa(y.Year), "TOTAL", money(y.Amount), money(y.Withholding))
+			}
+			return t.flush()
+		},
+	}
+}
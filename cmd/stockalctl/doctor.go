@@ -0,0 +1,325 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+	"github.com/adjaecent/unofficial-stockal-api/tokenstore/keyring"
+)
+
+// Outcomes of a diagnostic check.
+const (
+	checkOK   = "ok"
+	checkWarn = "warn"
+	checkFail = "fail"
+	checkSkip = "skip"
+)
+
+// check is the outcome of one diagnostic.
+type check struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Detail string `json:"detail"`
+	// Hint suggests what to do about a warning or failure
+	Hint string `json:"hint,omitempty"`
+}
+
+// doctor runs diagnostics in order, recording each outcome.
+type doctor struct {
+	cmd    *cobra.Command
+	opts   *globalOptions
+	checks []check
+}
+
+func (d *doctor) add(name, status, detail, hint string) {
+	d.checks = append(d.checks, check{Name: name, Status: status, Detail: detail, Hint: hint})
+}
+
+func newDoctorCmd(opts *globalOptions) *cobra.Command {
+	return &cobra.Command{
+		Use:   "doctor",
+		Short: "Diagnose connectivity and session problems",
+		Long: "Check that the API host resolves and accepts TLS connections, that requests are\n" +
+			"not being stopped by a Cloudflare challenge, that the saved session is still valid\n" +
+			"and that an authenticated call succeeds and returns portfolio totals that match the\n" +
+			"holdings. Each problem comes with a suggested fix.\n" +
+			"The command exits with status 1 if any check fails.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			d := &doctor{cmd: cmd, opts: opts}
+			d.run(cmd.Context())
+
+			if err := opts.write(cmd.OutOrStdout(), checksResult(d.checks)); err != nil {
+				return err
+			}
+			for _, c := range d.checks {
+				if c.Status == checkFail {
+					return exitError{code: 1}
+				}
+			}
+			return nil
+		},
+	}
+}
+
+func (d *doctor) run(ctx context.Context) {
+	profile := d.opts.profileKey
+	d.add("config", checkOK, fmt.Sprintf("profile %s from %s", profile, d.opts.configPath), "")
+
+	base, err := url.Parse(d.opts.baseURL)
+	if err != nil || base.Host == "" {
+		d.add("base URL", checkFail, fmt.Sprintf("invalid base URL %q", d.opts.baseURL), "fix --base-url or the profile's base_url")
+		return
+	}
+	reachable := d.checkDNS(ctx, base) && d.checkTLS(ctx, base)
+	if reachable {
+		d.checkHTTP(ctx, base)
+	} else {
+		d.add("http", checkSkip, "host is not reachable", "")
+	}
+	hasSession := d.checkSession(ctx)
+	d.checkCredentials(hasSession)
+	if reachable {
+		d.checkAPI(ctx)
+	} else {
+		d.add("api", checkSkip, "host is not reachable", "")
+	}
+}
+
+func (d *doctor) checkDNS(ctx context.Context, base *url.URL) bool {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	addrs, err := net.DefaultResolver.LookupHost(ctx, base.Hostname())
+	if err != nil {
+		d.add("dns", checkFail, err.Error(), "check your network connection and DNS settings")
+		return false
+	}
+	d.add("dns", checkOK, fmt.Sprintf("%s resolves to %s", base.Hostname(), strings.Join(addrs, ", ")), "")
+	return true
+}
+
+func (d *doctor) checkTLS(ctx context.Context, base *url.URL) bool {
+	if base.Scheme != "https" {
+		d.add("tls", checkWarn, "base URL does not use HTTPS", "only use plain HTTP against local test servers")
+		return true
+	}
+	port := base.Port()
+	if port == "" {
+		port = "443"
+	}
+
+	dialer := &tls.Dialer{NetDialer: &net.Dialer{Timeout: 10 * time.Second}}
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(base.Hostname(), port))
+	if err != nil {
+		d.add("tls", checkFail, err.Error(), "a proxy or firewall may be intercepting HTTPS; try another network")
+		return false
+	}
+	defer conn.Close()
+
+	state := conn.(*tls.Conn).ConnectionState()
+	cert := state.PeerCertificates[0]
+	detail := fmt.Sprintf("%s, certificate from %s valid until %s",
+		tls.VersionName(state.Version), cert.Issuer.CommonName, cert.NotAfter.Format(time.DateOnly))
+	if time.Until(cert.NotAfter) < 7*24*time.Hour {
+		d.add("tls", checkWarn, detail, "the server certificate expires soon")
+		return true
+	}
+	d.add("tls", checkOK, detail, "")
+	return true
+}
+
+// checkHTTP fetches the base URL the way the client does and looks for a
+// Cloudflare challenge, which blocks API calls with an HTML page.
+func (d *doctor) checkHTTP(ctx context.Context, base *url.URL) {
+	ctx, cancel := context.WithTimeout(ctx, d.opts.timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, base.String(), nil)
+	if err != nil {
+		d.add("http", checkFail, err.Error(), "")
+		return
+	}
+	origin := d.opts.profile.Tenant
+	if origin == "" {
+		origin = stockal.DefaultOrigin
+	}
+	req.Header.Set("User-Agent", "stockalctl/1.0")
+	req.Header.Set("Origin", origin)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		d.add("http", checkFail, err.Error(), "the host accepts connections but not HTTP requests; try again later")
+		return
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+
+	detail := fmt.Sprintf("HTTP %d", resp.StatusCode)
+	if server := resp.Header.Get("Server"); server != "" {
+		detail += " from " + server
+	}
+	if cloudflareChallenge(resp, body) {
+		d.add("http", checkFail, detail+", Cloudflare challenge",
+			"Cloudflare is challenging this network; wait, switch networks or disable VPNs, and check the tenant origin")
+		return
+	}
+	d.add("http", checkOK, detail, "")
+}
+
+// cloudflareChallenge reports whether resp is a Cloudflare bot challenge.
+func cloudflareChallenge(resp *http.Response, body []byte) bool {
+	if resp.Header.Get("Cf-Mitigated") == "challenge" {
+		return true
+	}
+	if !strings.EqualFold(resp.Header.Get("Server"), "cloudflare") {
+		return false
+	}
+	page := string(body)
+	return (resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusServiceUnavailable) &&
+		(strings.Contains(page, "challenge-platform") || strings.Contains(page, "Just a moment"))
+}
+
+// checkSession reports on the saved session and whether it can still be used.
+func (d *doctor) checkSession(ctx context.Context) bool {
+	token, err := keyring.New(keyringService, d.opts.profileKey).Load(ctx)
+	if path := sessionFilePath(d.opts.profileKey); err != nil && path != "" {
+		// Without a keyring, sessions are kept in the config directory
+		if token, err = stockal.NewFileTokenStore(path).Load(ctx); err != nil {
+			err = fmt.Errorf("%w (keyring unavailable)", err)
+		}
+	}
+	switch {
+	case err != nil:
+		d.add("session", checkWarn, err.Error(), "sessions cannot be saved; every command will log in again")
+		return false
+	case token == nil:
+		d.add("session", checkWarn, "no saved session", "run \"stockalctl login\"")
+		return false
+	}
+
+	now := time.Now()
+	access, refresh := token.AccessTokenExpiry(), token.RefreshTokenExpiry()
+	switch {
+	case !refresh.IsZero() && refresh.Before(now):
+		d.add("session", checkFail, "session expired "+refresh.Format(time.RFC1123), "run \"stockalctl login\"")
+		return false
+	case !access.IsZero() && access.Before(now):
+		d.add("session", checkOK, "access token expired, will be refreshed (refresh token valid until "+formatExpiry(refresh)+")", "")
+	default:
+		d.add("session", checkOK, "access token valid until "+formatExpiry(access), "")
+	}
+	return true
+}
+
+func formatExpiry(t time.Time) string {
+	if t.IsZero() {
+		return "unknown"
+	}
+	return t.Local().Format(time.RFC1123)
+}
+
+func (d *doctor) checkCredentials(hasSession bool) {
+	_, _, err := d.opts.profile.Credentials.resolve(d.opts.secretFunc(d.cmd.Context()))
+	switch {
+	case err == nil:
+		d.add("credentials", checkOK, "username and password are set", "")
+	case hasSession:
+		d.add("credentials", checkOK, "not set; the saved session is used", "")
+	default:
+		d.add("credentials", checkFail, err.Error(), "set the credentials or run \"stockalctl login\"")
+	}
+}
+
+// checkAPI makes a lightweight authenticated call and classifies any failure.
+func (d *doctor) checkAPI(ctx context.Context) {
+	start := time.Now()
+	client, err := d.opts.session(d.cmd)
+	if err == nil {
+		_, err = client.Account().Summary(ctx)
+	}
+	elapsed := time.Since(start).Round(time.Millisecond)
+	if err == nil {
+		d.add("api", checkOK, fmt.Sprintf("account summary in %s", elapsed), "")
+		d.checkData(ctx, client)
+		return
+	}
+
+	var hint string
+	switch {
+	case errors.Is(err, stockal.ErrInvalidCredentials):
+		hint = "the username or password was rejected"
+	case errors.Is(err, stockal.ErrLoginIncomplete):
+		hint = "the API wants more than a password, such as a one-time password; log in on the website once"
+	case errors.Is(err, stockal.ErrRateLimited):
+		hint = "rate limited; wait before retrying"
+	case errors.Is(err, stockal.ErrMaintenance):
+		hint = "the API is down for maintenance; try again later"
+	case errors.Is(err, stockal.ErrUpstreamUnavailable):
+		hint = "the API returned a non-JSON page, usually a Cloudflare challenge"
+	case errors.Is(err, stockal.ErrMalformedResponse):
+		hint = "the API response changed shape; update stockalctl or report an issue"
+	case errors.Is(err, stockal.ErrTokenExpired), errors.Is(err, stockal.ErrForbidden):
+		hint = "the session was rejected; run \"stockalctl login\""
+	case errors.Is(err, stockal.ErrServerError):
+		hint = "the API failed to handle the request; try again later"
+	case errors.Is(err, context.DeadlineExceeded):
+		hint = "the API is slow to respond; try a larger --timeout"
+	}
+	d.add("api", checkFail, err.Error(), hint)
+}
+
+// checkData cross-checks the portfolio totals against the holdings, allowing
+// for prices moving between the two requests.
+func (d *doctor) checkData(ctx context.Context, client stockal.StockalClient) {
+	snapshot, err := stockal.TakeSnapshot(ctx, client)
+	if err != nil {
+		d.add("data", checkFail, err.Error(), "")
+		return
+	}
+	discrepancies := snapshot.Reconcile(stockal.WithRelativeTolerance(0.005))
+	if len(discrepancies) == 0 {
+		d.add("data", checkOK, fmt.Sprintf("portfolio totals match %d holdings", len(snapshot.Holdings)), "")
+		return
+	}
+	problems := make([]string, len(discrepancies))
+	for i, discrepancy := range discrepancies {
+		problems[i] = discrepancy.String()
+	}
+	d.add("data", checkWarn, strings.Join(problems, "; "),
+		"the API's totals disagree with its holdings; trust the holdings, and check again later")
+}
+
+func checksResult(checks []check) result {
+	records := make([][]string, 0, len(checks))
+	for _, c := range checks {
+		records = append(records, []string{c.Name, c.Status, c.Detail, c.Hint})
+	}
+
+	return result{
+		value:   checks,
+		columns: []string{"name", "status", "detail", "hint"},
+		records: records,
+		table: func(w io.Writer) error {
+			t := newTable(w, "CHECK", "STATUS", "DETAIL")
+			for _, c := range checks {
+				t.row(c.Name, c.Status, c.Detail)
+				if c.Hint != "" {
+					t.row("", "", "→ "+c.Hint)
+				}
+			}
+			return t.flush()
+		},
+	}
+}
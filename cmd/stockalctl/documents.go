@@ -0,0 +1,191 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+	"github.com/adjaecent/unofficial-stockal-api/tax"
+)
+
+func newDocumentsCmd(opts *globalOptions) *cobra.Command {
+	var (
+		period        string
+		confirmations bool
+		dir           string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "documents",
+		Short: "List and download account statements and trade confirmations",
+		Long: "List the account statements, or with --confirmations the trade confirmations,\n" +
+			"for a month (YYYY-MM) or a year (YYYY); the default is last month. With\n" +
+			"--download every document listed is saved into the directory given.",
+		Example: "  stockalctl documents --period 2024-03 --download statements/\n" +
+			"  stockalctl documents --period 2024 --confirmations",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			year, month, err := parsePeriod(period, time.Now())
+			if err != nil {
+				return err
+			}
+			client, err := opts.session(cmd)
+			if err != nil {
+				return err
+			}
+
+			list, download := client.GetStatements, client.DownloadStatement
+			if confirmations {
+				list, download = client.GetTradeConfirmations, client.DownloadTradeConfirmation
+			}
+			resp, err := list(cmd.Context(), year, month)
+			if err != nil {
+				return err
+			}
+			if dir != "" {
+				for _, d := range resp.Data {
+					path := filepath.Join(dir, d.FileName())
+					if err := saveDocument(cmd.Context(), download, d.ID, path); err != nil {
+						return err
+					}
+					fmt.Fprintf(cmd.ErrOrStderr(), "Wrote %s\n", path)
+				}
+			}
+			return opts.write(cmd.OutOrStdout(), documentsResult(resp.Data))
+		},
+	}
+	cmd.Flags().StringVar(&period, "period", "", "month (YYYY-MM) or year (YYYY) to list; default last month")
+	cmd.Flags().BoolVar(&confirmations, "confirmations", false, "list trade confirmations instead of statements")
+	cmd.Flags().StringVar(&dir, "download", "", "save the documents into this directory")
+	return cmd
+}
+
+func newTaxDocumentsCmd(opts *globalOptions) *cobra.Command {
+	var (
+		fyFlag string
+		year   int
+		dir    string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "documents",
+		Short: "List and download tax forms: 1042-S and realised gain/loss reports",
+		Long: "List the tax documents for a US tax year (--year), which is a calendar year,\n" +
+			"or for both calendar years an Indian financial year spans (--fy). With\n" +
+			"--download every document listed is saved into the directory given.",
+		Example: "  stockalctl tax documents --fy 2024-25 --download itr-2024-25/\n  stockalctl tax documents --year 2024",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			years := []int{year}
+			if fyFlag != "" {
+				fy, err := tax.ParseFinancialYear(fyFlag)
+				if err != nil {
+					return err
+				}
+				years = []int{fy.StartYear, fy.StartYear + 1}
+			}
+			client, err := opts.session(cmd)
+			if err != nil {
+				return err
+			}
+
+			var documents []stockal.Document
+			for _, y := range years {
+				resp, err := client.GetTaxDocuments(cmd.Context(), y)
+				if err != nil {
+					return err
+				}
+				documents = append(documents, resp.Data...)
+			}
+			if dir != "" {
+				for _, d := range documents {
+					path := filepath.Join(dir, d.FileName())
+					if err := saveDocument(cmd.Context(), client.DownloadTaxDocument, d.ID, path); err != nil {
+						return err
+					}
+					fmt.Fprintf(cmd.ErrOrStderr(), "Wrote %s\n", path)
+				}
+			}
+			return opts.write(cmd.OutOrStdout(), documentsResult(documents))
+		},
+	}
+	cmd.Flags().StringVar(&fyFlag, "fy", "", "Indian financial year, e.g. 2024-25")
+	cmd.Flags().IntVar(&year, "year", 0, "US tax year")
+	cmd.Flags().StringVar(&dir, "download", "", "save the documents into this directory")
+	cmd.MarkFlagsMutuallyExclusive("fy", "year")
+	cmd.MarkFlagsOneRequired("fy", "year")
+	return cmd
+}
+
+// parsePeriod parses a --period flag: a year, or a month of one. An empty
+// period is the month before now.
+func parsePeriod(period string, now time.Time) (int, time.Month, error) {
+	if period == "" {
+		last := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, -1, 0)
+		return last.Year(), last.Month(), nil
+	}
+	if t, err := time.Parse("2006-01", period); err == nil {
+		return t.Year(), t.Month(), nil
+	}
+	if year, err := strconv.Atoi(period); err == nil && year > 0 {
+		return year, 0, nil
+	}
+	return 0, 0, fmt.Errorf("invalid --period %q: want YYYY-MM or YYYY", period)
+}
+
+// saveDocument downloads a document to path, removing the file if the
+// download fails.
+func saveDocument(ctx context.Context, download func(context.Context, string, io.Writer) error, id, path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	err = download(ctx, id, f)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return errors.Join(err, os.Remove(path))
+	}
+	return nil
+}
+
+// documentColumns are the CSV columns for documents, named after the
+// Document JSON fields.
+var documentColumns = []string{"documentID", "name", "format", "date", "orderID", "form"}
+
+func documentsResult(documents []stockal.Document) result {
+	records := make([][]string, 0, len(documents))
+	for _, d := range documents {
+		records = append(records, []string{d.ID, d.Name, string(d.Format), d.Date.String(), d.OrderID, string(d.Form)})
+	}
+
+	return result{
+		value:   documents,
+		columns: documentColumns,
+		records: records,
+		table: func(w io.Writer) error {
+			t := newTable(w, "DOCUMENT ID", "DATE", "FORMAT", "NAME")
+			for _, d := range documents {
+				name := d.Name
+				switch {
+				case name != "":
+				case d.OrderID != "":
+					name = "order " + d.OrderID
+				case d.Form != "":
+					name = string(d.Form)
+				}
+				t.row(d.ID, d.Date.String(), string(d.Format), name)
+			}
+			return t.flush()
+		},
+	}
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+	"github.com/adjaecent/unofficial-stockal-api/export"
+)
+
+func newExportCmd(opts *globalOptions) *cobra.Command {
+	var (
+		format  string
+		out     string
+		account string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export positions and trades for personal finance software",
+		Long: "Write the account's current positions and transactions (trades, dividends,\n" +
+			"deposits, withdrawals and fees) as an OFX investment statement (GnuCash,\n" +
+			"Moneydance, Quicken) or a QIF investment account (Quicken and older tools).\n" +
+			"Transactions keep their IDs, so importing again skips duplicates.",
+		Example: "  stockalctl export --format ofx --out stockal.ofx\n  stockalctl export --format qif > stockal.qif",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var write func(io.Writer, *export.Statement) error
+			switch format {
+			case "ofx":
+				write = export.WriteOFX
+			case "qif":
+				write = export.WriteQIF
+			default:
+				return fmt.Errorf("unknown export format %q (want ofx or qif)", format)
+			}
+
+			client, err := opts.session(cmd)
+			if err != nil {
+				return err
+			}
+			snapshot, err := stockal.TakeSnapshot(cmd.Context(), client)
+			if err != nil {
+				return err
+			}
+			transactions, err := stockal.AllTransactions(cmd.Context(), client, stockal.TransactionOptions{})
+			if err != nil {
+				return err
+			}
+			statement, err := export.NewStatement(account, snapshot, transactions)
+			if err != nil {
+				return err
+			}
+
+			if out == "" || out == "-" {
+				return write(cmd.OutOrStdout(), statement)
+			}
+			f, err := os.Create(out)
+			if err != nil {
+				return err
+			}
+			if err := write(f, statement); err != nil {
+				f.Close()
+				return err
+			}
+			if err := f.Close(); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.ErrOrStderr(), "Wrote %s (%d positions, %d trades, %d other transactions)\n",
+				out, len(statement.Holdings), len(statement.Trades), len(statement.CashTransactions))
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&format, "format", "ofx", "output format: ofx or qif")
+	cmd.Flags().StringVar(&out, "out", "", "output file (default standard output)")
+	cmd.Flags().StringVar(&account, "account", "Stockal", "account name or number in the finance software")
+	return cmd
+}
@@ -0,0 +1,302 @@
+// Command stockalctl is a terminal client for the Stockal API.
+//
+// Credentials are read from the STOCKAL_USERNAME and STOCKAL_PASSWORD
+// environment variables, or from the variables named by the selected profile
+// in ~/.config/stockal/config.yaml (see --profile). With --secrets, or a
+// profile's secrets setting, secrets missing from the environment are read
+// from files or a cloud secret manager instead. After a successful login
+// the session tokens (never the password) are kept in the OS keyring, or in
+// the OS config directory where there is no keyring, and refreshed
+// automatically, so later commands do not need credentials until
+// "stockalctl logout" is run.
+//
+// Usage:
+//
+//	stockalctl login
+//	stockalctl logout
+//	stockalctl summary
+//	stockalctl portfolio
+//	stockalctl holdings AAPL
+//	stockalctl portfolio --output json | jq '.[].symbol'
+//	stockalctl --profile work summary
+//
+// Extensions imported in plugins.go can add commands, API endpoints and
+// notifiers; see package registry.
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+	"github.com/adjaecent/unofficial-stockal-api/registry"
+	"github.com/adjaecent/unofficial-stockal-api/secrets"
+	"github.com/adjaecent/unofficial-stockal-api/tokenstore/keyring"
+)
+
+// Environment variables holding the account credentials.
+const (
+	envUsername = "STOCKAL_USERNAME"
+	envPassword = "STOCKAL_PASSWORD"
+)
+
+// globalOptions holds flags shared by every command.
+type globalOptions struct {
+	configPath  string
+	profileName string
+	baseURL     string
+	timeout     time.Duration
+	output      outputFormat
+	secretsSpec string
+	debug       bool
+
+	// profile is the selected configuration profile, loaded before each command runs
+	profile profile
+	// profileKey names the profile's saved session
+	profileKey string
+
+	// client is the authenticated client, kept across commands run by the
+	// REPL; clientKey records the profile and base URL it belongs to
+	client    stockal.StockalClient
+	clientKey string
+
+	// secrets is the opened secrets backend; secretsKey records its spec
+	secrets    stockal.SecretsProvider
+	secretsKey string
+}
+
+// keyringService is the OS keyring service under which sessions are stored.
+const keyringService = "stockalctl"
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if err := newRootCmd().ExecuteContext(ctx); err != nil {
+		stop()
+		var exitErr exitError
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.code)
+		}
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
+}
+
+// exitError makes the command exit with a specific status without printing an error.
+type exitError struct {
+	code int
+}
+
+func (e exitError) Error() string {
+	return fmt.Sprintf("exit status %d", e.code)
+}
+
+func newRootCmd() *cobra.Command {
+	return newRootCmdWithOptions(&globalOptions{})
+}
+
+// newRootCmdWithOptions builds the command tree around opts. The REPL builds a
+// fresh tree for every line but keeps opts, and with it the session.
+func newRootCmdWithOptions(opts *globalOptions) *cobra.Command {
+	opts.output = formatTable
+
+	root := &cobra.Command{
+		Use:           "stockalctl",
+		Short:         "Check your Stockal account from the terminal",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			return opts.applyProfile(cmd)
+		},
+	}
+	root.PersistentFlags().StringVar(&opts.configPath, "config", defaultConfigPath(), "configuration file")
+	root.PersistentFlags().StringVarP(&opts.profileName, "profile", "p", "", "configuration profile to use (default: default_profile from the config)")
+	root.PersistentFlags().StringVar(&opts.baseURL, "base-url", stockal.BaseURL, "Stockal API base URL")
+	root.PersistentFlags().DurationVar(&opts.timeout, "timeout", stockal.DefaultTimeout, "timeout for each API call")
+	root.PersistentFlags().VarP(&opts.output, "output", "o", "output format: table, json or csv")
+	root.PersistentFlags().StringVar(&opts.secretsSpec, "secrets", os.Getenv(secrets.EnvSpec), "where to read passwords and tokens from besides the environment: file:DIR, aws-sm:PREFIX, aws-sm-json:SECRET or gcp-sm:PROJECT")
+	root.PersistentFlags().BoolVar(&opts.debug, "debug", false, "log every API request to stderr, with credentials redacted")
+
+	root.AddCommand(
+		newLoginCmd(opts),
+		newSummaryCmd(opts),
+		newPortfolioCmd(opts),
+		newHoldingsCmd(opts),
+		newDashboardCmd(opts),
+		newLogoutCmd(opts),
+		newOrderCmd(opts),
+		newTransactionsCmd(opts),
+		newDividendsCmd(opts),
+		newWatchlistCmd(opts),
+		newSearchCmd(opts),
+		newStackCmd(opts),
+		newDepositCmd(opts),
+		newWithdrawalCmd(opts),
+		newDocumentsCmd(opts),
+		newAlertCmd(opts),
+		newReportCmd(opts),
+		newBotCmd(opts),
+		newTaxCmd(opts),
+		newExportCmd(opts),
+		newReplCmd(opts),
+		newServeCmd(opts),
+		newBackupCmd(opts),
+		newRestoreCmd(opts),
+		newDoctorCmd(opts),
+		newCallCmd(opts),
+	)
+	for _, cmd := range registry.Commands(opts.session) {
+		for _, c := range root.Commands() {
+			if c.Name() == cmd.Name() {
+				panic(fmt.Sprintf("stockalctl: extension command %q clashes with a built-in command", cmd.Name()))
+			}
+		}
+		root.AddCommand(cmd)
+	}
+	return root
+}
+
+// applyProfile loads the selected profile and uses it for any flag not set explicitly.
+func (o *globalOptions) applyProfile(cmd *cobra.Command) error {
+	cfg, err := loadConfig(o.configPath)
+	if err != nil {
+		return err
+	}
+	o.profile, err = cfg.profile(o.profileName)
+	if err != nil {
+		return err
+	}
+	o.profileKey = o.profileName
+	if o.profileKey == "" {
+		o.profileKey = cfg.DefaultProfile
+	}
+	if o.profileKey == "" {
+		o.profileKey = "default"
+	}
+
+	flags := cmd.Flags()
+	if o.profile.BaseURL != "" && !flags.Changed("base-url") {
+		o.baseURL = o.profile.BaseURL
+	}
+	if o.profile.Secrets != "" && !flags.Changed("secrets") {
+		o.secretsSpec = o.profile.Secrets
+	}
+	if o.profile.Output != "" && !flags.Changed("output") {
+		if err := o.output.Set(o.profile.Output); err != nil {
+			return fmt.Errorf("profile output: %w", err)
+		}
+	}
+	return nil
+}
+
+// newClient creates a client configured from the global flags and profile.
+// store may be nil to keep the session in memory only.
+func (o *globalOptions) newClient(store stockal.TokenStore) stockal.StockalClient {
+	options := []stockal.ClientOption{
+		stockal.WithBaseURL(o.baseURL),
+		stockal.WithDefaultDeadline(o.timeout),
+		stockal.WithUserAgent("stockalctl/1.0"),
+	}
+	if o.profile.Tenant != "" {
+		options = append(options, stockal.WithOrigin(o.profile.Tenant))
+	}
+	if store != nil {
+		options = append(options, stockal.WithTokenStore(store))
+	}
+	if o.debug {
+		logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug}))
+		options = append(options, stockal.WithLogger(logger))
+	}
+	return stockal.NewClient(options...)
+}
+
+// tokenStore returns the store for the selected profile's session and the
+// session it holds. It returns nil (with a warning) if no store can be used.
+func (o *globalOptions) tokenStore(cmd *cobra.Command) (stockal.TokenStore, *stockal.LoginData) {
+	store, token, err := profileTokenStore(cmd.Context(), o.profileKey)
+	if err != nil {
+		fmt.Fprintf(cmd.ErrOrStderr(), "warning: session will not be saved: %v\n", err)
+		return nil, nil
+	}
+	return store, token
+}
+
+// profileTokenStore returns the store for a profile's session and the session
+// it holds: the OS keyring, or if that is unavailable, e.g. on a headless
+// machine, a file readable only by the user in the OS config directory.
+func profileTokenStore(ctx context.Context, profileKey string) (stockal.TokenStore, *stockal.LoginData, error) {
+	store := keyring.New(keyringService, profileKey)
+	token, err := store.Load(ctx)
+	if err == nil {
+		return store, token, nil
+	}
+	if path := sessionFilePath(profileKey); path != "" {
+		file := stockal.NewFileTokenStore(path)
+		if token, fileErr := file.Load(ctx); fileErr == nil {
+			return file, token, nil
+		}
+	}
+	return nil, nil, err
+}
+
+// sessionFilePath returns the file a profile's session is kept in when the
+// keyring is unavailable, normally ~/.config/stockal/sessions/PROFILE.json, or
+// "" if there is no config directory.
+func sessionFilePath(profileKey string) string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "stockal", "sessions", url.PathEscape(profileKey)+".json")
+}
+
+// login authenticates with the profile's credentials, saving the session in the keyring.
+func (o *globalOptions) login(cmd *cobra.Command) (stockal.StockalClient, *stockal.LoginResponse, error) {
+	store, _ := o.tokenStore(cmd)
+	return o.loginWithStore(cmd, store)
+}
+
+func (o *globalOptions) loginWithStore(cmd *cobra.Command, store stockal.TokenStore) (stockal.StockalClient, *stockal.LoginResponse, error) {
+	username, password, err := o.profile.Credentials.resolve(o.secretFunc(cmd.Context()))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	client := o.newClient(store)
+	resp, err := client.Auth().Login(cmd.Context(), username, password)
+	if err != nil {
+		return nil, nil, fmt.Errorf("login failed: %w", err)
+	}
+	o.client, o.clientKey = client, o.sessionKey()
+	return client, resp, nil
+}
+
+// sessionKey identifies the account a cached client is logged in to.
+func (o *globalOptions) sessionKey() string {
+	return o.profileKey + "\x00" + o.baseURL
+}
+
+// session returns an authenticated client, resuming the saved session when
+// there is one and logging in with the profile's credentials otherwise.
+func (o *globalOptions) session(cmd *cobra.Command) (stockal.StockalClient, error) {
+	if o.client != nil && o.clientKey == o.sessionKey() {
+		return o.client, nil
+	}
+	store, token := o.tokenStore(cmd)
+	if token != nil {
+		o.client, o.clientKey = o.newClient(store), o.sessionKey()
+		return o.client, nil
+	}
+	client, _, err := o.loginWithStore(cmd, store)
+	return client, err
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"maps"
+
+	"github.com/adjaecent/unofficial-stockal-api/bus"
+	"github.com/adjaecent/unofficial-stockal-api/events"
+	"github.com/adjaecent/unofficial-stockal-api/notify"
+	"github.com/adjaecent/unofficial-stockal-api/registry"
+)
+
+// webhookProfile is a webhook destination for account events. The signing
+// secret is read from the secret named by SecretEnv.
+type webhookProfile struct {
+	URL       string `yaml:"url"`
+	SecretEnv string `yaml:"secret_env"`
+}
+
+// telegramProfile is a Telegram bot destination for account events. The bot
+// token is read from the secret named by TokenEnv.
+type telegramProfile struct {
+	TokenEnv string `yaml:"token_env"`
+	ChatID   string `yaml:"chat_id"`
+}
+
+// telegram returns the profile's Telegram sink, or nil if it has none.
+func (o *globalOptions) telegram(ctx context.Context) (*notify.Telegram, error) {
+	t := o.profile.Telegram
+	if t.ChatID == "" {
+		return nil, nil
+	}
+	token, err := o.secretFunc(ctx)(t.TokenEnv)
+	if err != nil {
+		return nil, err
+	}
+	if token == "" {
+		return nil, fmt.Errorf("set %s to use the Telegram bot", t.TokenEnv)
+	}
+	return notify.NewTelegram(token, t.ChatID), nil
+}
+
+// slackProfile is a Slack destination for account events: either an incoming
+// webhook, or a bot token and channel. Both are read from the secrets the
+// profile names.
+type slackProfile struct {
+	WebhookURLEnv string `yaml:"webhook_url_env"`
+	TokenEnv      string `yaml:"token_env"`
+	Channel       string `yaml:"channel"`
+}
+
+// slack returns the profile's Slack sink, or nil if it has none.
+func (o *globalOptions) slack(ctx context.Context) (*notify.Slack, error) {
+	s := o.profile.Slack
+	secret := o.secretFunc(ctx)
+	switch {
+	case s.WebhookURLEnv != "":
+		url, err := secret(s.WebhookURLEnv)
+		if err != nil {
+			return nil, err
+		}
+		if url == "" {
+			return nil, fmt.Errorf("set %s to notify Slack", s.WebhookURLEnv)
+		}
+		return notify.NewSlackWebhook(url), nil
+	case s.Channel != "":
+		token, err := secret(s.TokenEnv)
+		if err != nil {
+			return nil, err
+		}
+		if token == "" {
+			return nil, fmt.Errorf("set %s to notify Slack", s.TokenEnv)
+		}
+		return notify.NewSlackBot(token, s.Channel), nil
+	}
+	return nil, nil
+}
+
+// discordProfile is a Discord channel webhook for account events. The webhook
+// URL is read from the secret named by WebhookURLEnv.
+type discordProfile struct {
+	WebhookURLEnv string `yaml:"webhook_url_env"`
+}
+
+// discord returns the profile's Discord sink, or nil if it has none.
+func (o *globalOptions) discord(ctx context.Context) (*notify.Discord, error) {
+	env := o.profile.Discord.WebhookURLEnv
+	if env == "" {
+		return nil, nil
+	}
+	url, err := o.secretFunc(ctx)(env)
+	if err != nil {
+		return nil, err
+	}
+	if url == "" {
+		return nil, fmt.Errorf("set %s to notify Discord", env)
+	}
+	return notify.NewDiscord(url), nil
+}
+
+// notifierProfile configures a notifier added by an extension: type names
+// the kind registered with registry.RegisterNotifier, and the other keys are
+// its settings.
+type notifierProfile map[string]string
+
+// notifier returns the notifier the profile entry configures.
+func (o *globalOptions) notifier(ctx context.Context, n notifierProfile) (notify.Notifier, error) {
+	kind := n["type"]
+	factory, ok := registry.Notifier(kind)
+	if !ok {
+		return nil, fmt.Errorf("unknown notifier type %q", kind)
+	}
+	settings := maps.Clone(n)
+	delete(settings, "type")
+	return factory(ctx, registry.NotifierConfig{Settings: settings, Secret: o.secretFunc(ctx)})
+}
+
+// bus returns an event bus with the profile's configured notifiers
+// subscribed, each under its own name so that failures say which one failed.
+// Notifiers that cannot be set up are reported on stderr and skipped.
+func (o *globalOptions) bus(ctx context.Context, stderr io.Writer) *bus.Bus {
+	b := bus.New()
+	for _, w := range o.profile.Webhooks {
+		var secret []byte
+		if w.SecretEnv != "" {
+			s, err := o.secretFunc(ctx)(w.SecretEnv)
+			if err != nil {
+				fmt.Fprintf(stderr, "warning: webhook %s: %v\n", w.URL, err)
+				continue
+			}
+			secret = []byte(s)
+		}
+		b.SubscribeNotifier("webhook "+w.URL, notify.NewWebhook(w.URL, secret))
+	}
+	if t, err := o.telegram(ctx); err != nil {
+		fmt.Fprintf(stderr, "warning: %v\n", err)
+	} else if t != nil {
+		b.SubscribeNotifier("telegram", t)
+	}
+	if s, err := o.slack(ctx); err != nil {
+		fmt.Fprintf(stderr, "warning: %v\n", err)
+	} else if s != nil {
+		b.SubscribeNotifier("slack", s)
+	}
+	if d, err := o.discord(ctx); err != nil {
+		fmt.Fprintf(stderr, "warning: %v\n", err)
+	} else if d != nil {
+		b.SubscribeNotifier("discord", d)
+	}
+	for _, n := range o.profile.Notifiers {
+		if notifier, err := o.notifier(ctx, n); err != nil {
+			fmt.Fprintf(stderr, "warning: %v\n", err)
+		} else {
+			b.SubscribeNotifier(n["type"], notifier)
+		}
+	}
+	return b
+}
+
+// publish delivers event on b, reporting failures on stderr.
+func publish(ctx context.Context, stderr io.Writer, b *bus.Bus, event events.Event) {
+	if err := b.Publish(ctx, event); err != nil {
+		fmt.Fprintf(stderr, "notify %s failed: %v\n", event.Type, err)
+	}
+}
@@ -0,0 +1,555 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+)
+
+// errAborted is returned when the user declines a confirmation prompt.
+var errAborted = errors.New("aborted")
+
+func newOrderCmd(opts *globalOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "order",
+		Short: "Place, cancel and inspect orders",
+	}
+	cmd.AddCommand(
+		newOrderPlaceCmd(opts, stockal.OrderSideBuy),
+		newOrderPlaceCmd(opts, stockal.OrderSideSell),
+		newOrderCancelCmd(opts),
+		newOrderModifyCmd(opts),
+		newOrderListCmd(opts),
+		newOrderStatusCmd(opts),
+		newOrderImportCmd(opts),
+	)
+	return cmd
+}
+
+// confirmFlags are the flags guarding commands that change the account.
+type confirmFlags struct {
+	yes    bool
+	dryRun bool
+}
+
+func (f *confirmFlags) register(cmd *cobra.Command) {
+	cmd.Flags().BoolVarP(&f.yes, "yes", "y", false, "do not ask for confirmation")
+	cmd.Flags().BoolVar(&f.dryRun, "dry-run", false, "show what would be done without doing it")
+}
+
+// confirm asks the user to confirm an action unless --yes was given.
+func (f *confirmFlags) confirm(cmd *cobra.Command, prompt string) error {
+	if f.yes {
+		return nil
+	}
+
+	fmt.Fprintf(cmd.ErrOrStderr(), "%s [y/N] ", prompt)
+	answer, err := bufio.NewReader(cmd.InOrStdin()).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return nil
+	}
+	return errAborted
+}
+
+func newOrderPlaceCmd(opts *globalOptions, side stockal.OrderSide) *cobra.Command {
+	var (
+		quantity float64
+		amount   float64
+		limit    float64
+		guard    confirmFlags
+	)
+
+	cmd := &cobra.Command{
+		Use:     string(side) + " <symbol>",
+		Short:   fmt.Sprintf("Place a %s order", side),
+		Example: fmt.Sprintf("  stockalctl order %s AAPL --qty 2 --limit 180\n  stockalctl order %s VOO --amount 500", side, side),
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			order := stockal.OrderRequest{
+				Symbol:     strings.ToUpper(args[0]),
+				Side:       side,
+				Type:       stockal.OrderTypeMarket,
+				Quantity:   quantity,
+				Amount:     amount,
+				LimitPrice: limit,
+			}
+			if limit > 0 {
+				order.Type = stockal.OrderTypeLimit
+			}
+			if err := order.Validate(); err != nil {
+				return err
+			}
+
+			client, err := opts.session(cmd)
+			if err != nil {
+				return err
+			}
+
+			if err := writeOrderPreview(cmd, client, order); err != nil {
+				return err
+			}
+			if guard.dryRun {
+				return nil
+			}
+			if err := guard.confirm(cmd, "Submit this order?"); err != nil {
+				return err
+			}
+
+			resp, err := client.Orders().Place(cmd.Context(), order)
+			if err != nil {
+				return err
+			}
+			return opts.write(cmd.OutOrStdout(), orderResult(resp.Data))
+		},
+	}
+	cmd.Flags().Float64Var(&quantity, "qty", 0, "number of shares")
+	cmd.Flags().Float64Var(&amount, "amount", 0, "dollar amount (instead of --qty)")
+	cmd.Flags().Float64Var(&limit, "limit", 0, "limit price (omit for a market order)")
+	cmd.MarkFlagsMutuallyExclusive("qty", "amount")
+	cmd.MarkFlagsOneRequired("qty", "amount")
+	guard.register(cmd)
+	return cmd
+}
+
+// writeOrderPreview prints the order and its estimated cost to stderr.
+// Market orders for a quantity are estimated from the latest price of an
+// existing holding, when there is one.
+func writeOrderPreview(cmd *cobra.Command, client stockal.PortfolioReader, order stockal.OrderRequest) error {
+	estimate := "unknown"
+	switch {
+	case order.Amount > 0:
+		estimate = money(order.Amount)
+	case order.Type == stockal.OrderTypeLimit:
+		estimate = money(order.Quantity*order.LimitPrice) + " at most"
+	default:
+		portfolio, err := client.Portfolio().Detail(cmd.Context())
+		if err != nil {
+			return err
+		}
+		for _, h := range portfolio.Data.Holdings {
+			if strings.EqualFold(h.Symbol, order.Symbol) {
+				estimate = "≈" + money(order.Quantity*h.Price) + " at " + money(h.Price)
+			}
+		}
+	}
+
+	size := units(order.Quantity) + " shares"
+	if order.Amount > 0 {
+		size = money(order.Amount)
+	}
+	price := "market"
+	if order.Type == stockal.OrderTypeLimit {
+		price = "limit " + money(order.LimitPrice)
+	}
+
+	t := newTable(cmd.ErrOrStderr(), "ORDER", "")
+	t.row("Symbol", order.Symbol)
+	t.row("Side", string(order.Side))
+	t.row("Size", size)
+	t.row("Price", price)
+	t.row("Estimated cost", estimate)
+	return t.flush()
+}
+
+func newOrderImportCmd(opts *globalOptions) *cobra.Command {
+	var guard confirmFlags
+
+	cmd := &cobra.Command{
+		Use:   "import <file.csv>",
+		Short: "Place a batch of orders from a CSV file",
+		Long: "Read orders from a CSV file, preview them with their total cash impact and\n" +
+			"place them in file order after confirmation. Placing stops at the first\n" +
+			"order that fails.\n\n" +
+			"The header names the columns: symbol and side are required, qty or amount\n" +
+			"gives the size, and limit makes a limit order. Use - to read standard input.",
+		Example: "  stockalctl order import model.csv --dry-run\n\n" +
+			"  # model.csv\n  symbol,side,qty,amount,limit\n  VOO,buy,,500,\n  AAPL,buy,2,,180",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			in := cmd.InOrStdin()
+			if args[0] != "-" {
+				f, err := os.Open(args[0])
+				if err != nil {
+					return err
+				}
+				defer f.Close()
+				in = f
+			}
+			orders, err := stockal.ReadOrderCSV(in)
+			if err != nil {
+				return err
+			}
+
+			client, err := opts.session(cmd)
+			if err != nil {
+				return err
+			}
+			if err := writeBatchPreview(cmd, client, orders); err != nil {
+				return err
+			}
+			if guard.dryRun {
+				return nil
+			}
+			if err := guard.confirm(cmd, fmt.Sprintf("Submit these %d orders?", len(orders))); err != nil {
+				return err
+			}
+
+			placed, err := stockal.PlaceOrders(cmd.Context(), client.Orders(), orders)
+			if len(placed) > 0 {
+				if werr := opts.write(cmd.OutOrStdout(), ordersResult(placed)); werr != nil {
+					return werr
+				}
+			}
+			var partial *stockal.PartialError
+			if errors.As(err, &partial) {
+				return fmt.Errorf("%w; not placed: %s", err, strings.Join(partial.Remaining, ", "))
+			}
+			if err != nil {
+				return fmt.Errorf("%w; %d of %d orders placed", err, len(placed), len(orders))
+			}
+			return nil
+		},
+	}
+	guard.register(cmd)
+	return cmd
+}
+
+// writeBatchPreview prints each order with its estimated value, then the
+// total cash impact against the cash available for trading, to stderr.
+// Quantity market orders are estimated from the latest quote.
+func writeBatchPreview(cmd *cobra.Command, client stockal.StockalClient, orders []stockal.OrderRequest) error {
+	var symbols []string
+	for _, o := range orders {
+		symbols = append(symbols, o.Symbol)
+	}
+	quotes, err := client.GetQuotes(cmd.Context(), symbols...)
+	if err != nil {
+		return err
+	}
+	summary, err := client.Account().Summary(cmd.Context())
+	if err != nil {
+		return err
+	}
+
+	var buys, sells float64
+	unknown := false
+	t := newTable(cmd.ErrOrStderr(), "#", "SYMBOL", "SIDE", "SIZE", "PRICE", "EST. VALUE")
+	for i, o := range orders {
+		size := units(o.Quantity) + " sh"
+		if o.Amount > 0 {
+			size = money(o.Amount)
+		}
+		price := "market"
+		if o.Type == stockal.OrderTypeLimit {
+			price = "limit " + money(o.LimitPrice)
+		}
+
+		var value float64
+		switch q, ok := quotes.Quote(o.Symbol); {
+		case o.Amount > 0:
+			value = o.Amount
+		case o.Type == stockal.OrderTypeLimit:
+			value = o.Quantity * o.LimitPrice
+		case ok:
+			value = o.Quantity * q.Price
+			price = "market ≈" + money(q.Price)
+		}
+		estimate := money(value)
+		if value == 0 {
+			estimate, unknown = "unknown", true
+		}
+		if o.Side == stockal.OrderSideBuy {
+			buys += value
+		} else {
+			sells += value
+		}
+		t.row(fmt.Sprint(i+1), o.Symbol, string(o.Side), size, price, estimate)
+	}
+	if err := t.flush(); err != nil {
+		return err
+	}
+
+	cash := summary.Data.AccountSummary.CashAvailableForTrade
+	w := cmd.ErrOrStderr()
+	fmt.Fprintln(w)
+	totals := newTable(w, "TOTAL", "")
+	totals.row("Buys", money(buys))
+	totals.row("Sells", money(sells))
+	totals.row("Net cash impact", money(sells-buys))
+	totals.row("Cash available", money(cash))
+	if err := totals.flush(); err != nil {
+		return err
+	}
+	if unknown {
+		fmt.Fprintln(w, "warning: some orders could not be priced and are not in the totals")
+	}
+	if buys > cash {
+		fmt.Fprintln(w, "warning: buys exceed the cash available for trade; sale proceeds may not settle in time")
+	}
+	return nil
+}
+
+func newOrderCancelCmd(opts *globalOptions) *cobra.Command {
+	var guard confirmFlags
+
+	cmd := &cobra.Command{
+		Use:   "cancel <order-id>",
+		Short: "Cancel an open order",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := opts.session(cmd)
+			if err != nil {
+				return err
+			}
+
+			current, err := client.Orders().Get(cmd.Context(), args[0])
+			if err != nil {
+				return err
+			}
+			if err := orderResult(current.Data).table(cmd.ErrOrStderr()); err != nil {
+				return err
+			}
+			if guard.dryRun {
+				return nil
+			}
+			if err := guard.confirm(cmd, "Cancel this order?"); err != nil {
+				return err
+			}
+
+			resp, err := client.Orders().Cancel(cmd.Context(), args[0])
+			if err != nil {
+				return err
+			}
+			return opts.write(cmd.OutOrStdout(), orderResult(resp.Data))
+		},
+	}
+	guard.register(cmd)
+	return cmd
+}
+
+func newOrderModifyCmd(opts *globalOptions) *cobra.Command {
+	var (
+		changes stockal.OrderChanges
+		guard   confirmFlags
+	)
+
+	cmd := &cobra.Command{
+		Use:   "modify <order-id>",
+		Short: "Change the quantity, amount or limit price of an open order",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := changes.Validate(); err != nil {
+				return err
+			}
+			client, err := opts.session(cmd)
+			if err != nil {
+				return err
+			}
+
+			current, err := client.Orders().Get(cmd.Context(), args[0])
+			if err != nil {
+				return err
+			}
+			// Statuses Stockal adds are left for it to judge
+			if status := current.Data.Status; status.IsKnown() && !status.IsOpen() {
+				return fmt.Errorf("order %s is %s and can no longer be modified", args[0], status)
+			}
+			if err := orderResult(current.Data).table(cmd.ErrOrStderr()); err != nil {
+				return err
+			}
+			if guard.dryRun {
+				return nil
+			}
+			if err := guard.confirm(cmd, "Modify this order?"); err != nil {
+				return err
+			}
+
+			resp, err := client.Orders().Modify(cmd.Context(), args[0], changes)
+			if err != nil {
+				return err
+			}
+			return opts.write(cmd.OutOrStdout(), orderResult(resp.Data))
+		},
+	}
+	cmd.Flags().Float64Var(&changes.Quantity, "qty", 0, "new number of shares")
+	cmd.Flags().Float64Var(&changes.Amount, "amount", 0, "new dollar amount")
+	cmd.Flags().Float64Var(&changes.LimitPrice, "limit", 0, "new limit price")
+	guard.register(cmd)
+	return cmd
+}
+
+func newOrderListCmd(opts *globalOptions) *cobra.Command {
+	var (
+		list         stockal.OrderListOptions
+		status       string
+		since, until string
+		all          bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List open and recent orders, or search the order history",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if status != "" {
+				list.Status = stockal.OrderStatus(strings.ToLower(status))
+				if !list.Status.IsKnown() {
+					return fmt.Errorf("unknown status %q (want new, partially_filled, filled, cancelled, rejected or expired)", status)
+				}
+			}
+			var err error
+			if list.From, err = parseDateFlag("since", since); err != nil {
+				return err
+			}
+			if list.To, err = parseDateFlag("until", until); err != nil {
+				return err
+			}
+			if !list.To.IsZero() {
+				// --until includes the day given
+				list.To = list.To.AddDate(0, 0, 1)
+			}
+			if err := list.Validate(); err != nil {
+				return err
+			}
+
+			client, err := opts.session(cmd)
+			if err != nil {
+				return err
+			}
+			if all {
+				orders, err := stockal.AllOrders(cmd.Context(), client.Orders(), list)
+				if err == nil || len(orders) > 0 {
+					if werr := opts.write(cmd.OutOrStdout(), ordersResult(orders)); werr != nil {
+						return werr
+					}
+				}
+				return err
+			}
+			resp, err := client.Orders().ListWithOptions(cmd.Context(), list)
+			if err != nil {
+				return err
+			}
+			if resp.Data.NextCursor != "" {
+				fmt.Fprintf(cmd.ErrOrStderr(), "More orders follow; continue with --cursor %s\n", resp.Data.NextCursor)
+			}
+			return opts.write(cmd.OutOrStdout(), ordersResult(resp.Data.Orders))
+		},
+	}
+	cmd.Flags().StringVar(&list.Symbol, "symbol", "", "show only orders for this symbol")
+	cmd.Flags().StringVar(&status, "status", "", "show only orders with this status, e.g. filled")
+	cmd.Flags().StringVar(&since, "since", "", "show only orders placed on or after this date (YYYY-MM-DD)")
+	cmd.Flags().StringVar(&until, "until", "", "show only orders placed on or before this date (YYYY-MM-DD)")
+	cmd.Flags().IntVar(&list.Limit, "limit", 0, "show at most this many orders")
+	cmd.Flags().IntVar(&list.Offset, "offset", 0, "skip this many orders")
+	cmd.Flags().StringVar(&list.Cursor, "cursor", "", "continue a listing from where a previous page ended")
+	cmd.Flags().BoolVar(&all, "all", false, "read every matching order, a page at a time")
+	cmd.MarkFlagsMutuallyExclusive("offset", "cursor")
+	return cmd
+}
+
+// parseDateFlag parses the value of a date flag as a local date, or returns
+// the zero time if it is empty.
+func parseDateFlag(name, value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.ParseInLocation(time.DateOnly, value, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid --%s %q: want a date such as 2024-03-31", name, value)
+	}
+	return t, nil
+}
+
+func newOrderStatusCmd(opts *globalOptions) *cobra.Command {
+	return &cobra.Command{
+		Use:   "status <order-id>",
+		Short: "Show the status of an order",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := opts.session(cmd)
+			if err != nil {
+				return err
+			}
+			resp, err := client.Orders().Get(cmd.Context(), args[0])
+			if err != nil {
+				return err
+			}
+			return opts.write(cmd.OutOrStdout(), orderResult(resp.Data))
+		},
+	}
+}
+
+// orderColumns are the CSV columns for orders, named after the Order JSON fields.
+var orderColumns = []string{
+	"orderID", "symbol", "side", "type", "status", "quantity", "amount", "limitPrice",
+	"filledQuantity", "averagePrice", "createdAt", "updatedAt", "filledAt",
+}
+
+func orderRecord(o stockal.Order) []string {
+	return []string{
+		o.ID, o.Symbol, string(o.Side), string(o.Type), string(o.Status), num(o.Quantity), num(o.Amount), num(o.LimitPrice),
+		num(o.FilledQuantity), num(o.AveragePrice), o.CreatedAt.String(), o.UpdatedAt.String(), o.FilledAt.String(),
+	}
+}
+
+func orderResult(o stockal.Order) result {
+	return result{
+		value:   o,
+		columns: orderColumns,
+		records: [][]string{orderRecord(o)},
+		table: func(w io.Writer) error {
+			t := newTable(w, "FIELD", "VALUE")
+			t.row("Order ID", o.ID)
+			t.row("Symbol", o.Symbol)
+			t.row("Side", string(o.Side))
+			t.row("Type", string(o.Type))
+			t.row("Status", string(o.Status))
+			t.row("Quantity", units(o.Quantity))
+			t.row("Amount", money(o.Amount))
+			t.row("Limit price", money(o.LimitPrice))
+			t.row("Filled", units(o.FilledQuantity))
+			t.row("Average price", money(o.AveragePrice))
+			t.row("Created", o.CreatedAt.String())
+			if !o.UpdatedAt.IsZero() {
+				t.row("Updated", o.UpdatedAt.String())
+			}
+			if !o.FilledAt.IsZero() {
+				t.row("Filled at", o.FilledAt.String())
+			}
+			return t.flush()
+		},
+	}
+}
+
+func ordersResult(orders []stockal.Order) result {
+	records := make([][]string, 0, len(orders))
+	for _, o := range orders {
+		records = append(records, orderRecord(o))
+	}
+
+	return result{
+		value:   orders,
+		columns: orderColumns,
+		records: records,
+		table: func(w io.Writer) error {
+			t := newTable(w, "ORDER ID", "SYMBOL", "SIDE", "TYPE", "STATUS", "QTY", "AMOUNT", "FILLED", "AVG PRICE", "CREATED")
+			for _, o := range orders {
+				t.row(o.ID, o.Symbol, string(o.Side), string(o.Type), string(o.Status), units(o.Quantity), money(o.Amount),
+					units(o.FilledQuantity), money(o.AveragePrice), o.CreatedAt.String())
+			}
+			return t.flush()
+		},
+	}
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+)
+
+// Supported values for --output.
+const (
+	formatTable = "table"
+	formatJSON  = "json"
+	formatCSV   = "csv"
+)
+
+// outputFormat is a pflag.Value accepting one of the supported output formats.
+type outputFormat string
+
+func (f *outputFormat) String() string { return string(*f) }
+
+func (f *outputFormat) Set(v string) error {
+	switch v {
+	case formatTable, formatJSON, formatCSV:
+		*f = outputFormat(v)
+		return nil
+	}
+	return fmt.Errorf("must be one of %s, %s, %s", formatTable, formatJSON, formatCSV)
+}
+
+func (f *outputFormat) Type() string { return "format" }
+
+// result is the output of a command, renderable in every supported format.
+// JSON and CSV use the library's JSON field names so they stay stable for scripts.
+type result struct {
+	// value is encoded as-is for JSON output
+	value any
+	// columns and records are written for CSV output
+	columns []string
+	records [][]string
+	// table writes the human-readable form
+	table func(w io.Writer) error
+}
+
+// write renders r to w in the selected output format.
+func (o *globalOptions) write(w io.Writer, r result) error {
+	switch o.output {
+	case formatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(r.value)
+	case formatCSV:
+		cw := csv.NewWriter(w)
+		if err := cw.Write(r.columns); err != nil {
+			return err
+		}
+		if err := cw.WriteAll(r.records); err != nil {
+			return err
+		}
+		return cw.Error()
+	default:
+		return r.table(w)
+	}
+}
+
+// holdingColumns are the CSV columns for holdings, named after the Holding JSON fields.
+var holdingColumns = []string{
+	"symbol", "ticker", "company", "category", "type", "status", "totalUnit", "totalInvestment",
+	"price", "close", "priorClose", "listed", "sellOnly", "Date",
+}
+
+func holdingRecord(h stockal.Holding) []string {
+	return []string{
+		h.Symbol, h.Ticker, h.Company, string(h.Category), string(h.Type), string(h.Status), num(h.TotalUnit), num(h.TotalInvestment),
+		num(h.Price), num(h.Close), num(h.PriorClose), strconv.FormatBool(h.Listed), strconv.FormatBool(h.SellOnly), h.Date.String(),
+	}
+}
+
+// num formats v for machine-readable output without rounding.
+func num(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
@@ -0,0 +1,10 @@
+package main
+
+// Extensions are linked into stockalctl by importing them here for their
+// registrations (see package registry), for example:
+//
+//	import _ "example.com/stockal-ntfy"
+//
+// Their commands are added to the command tree, their endpoint groups can be
+// used with "stockalctl call" and their notifiers in profiles' notifiers
+// section.
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"sync"
+
+	"github.com/spf13/cobra"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+)
+
+// profilePool returns a pool with a client for every configured profile. The
+// clients resume saved sessions; ensureLogin logs in one that has none.
+func (o *globalOptions) profilePool(cmd *cobra.Command) (pool *stockal.ClientPool, ensureLogin func(ctx context.Context, name string, client stockal.StockalClient) error, err error) {
+	cfg, err := loadConfig(o.configPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(cfg.Profiles) == 0 {
+		return nil, nil, fmt.Errorf("no profiles configured in %s", o.configPath)
+	}
+
+	pool = stockal.NewClientPool()
+	// needLogin holds the options of the profiles without a saved session
+	needLogin := map[string]*globalOptions{}
+	for _, name := range slices.Sorted(maps.Keys(cfg.Profiles)) {
+		p := *o
+		p.profileName, p.client = name, nil
+		if err := p.applyProfile(cmd); err != nil {
+			return nil, nil, err
+		}
+		store, token := p.tokenStore(cmd)
+		if token == nil {
+			needLogin[name] = &p
+		}
+		if err := pool.Add(name, p.newClient(store)); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	ensureLogin = func(ctx context.Context, name string, client stockal.StockalClient) error {
+		p, ok := needLogin[name]
+		if !ok {
+			return nil
+		}
+		username, password, err := p.profile.Credentials.resolve(p.secretFunc(ctx))
+		if err != nil {
+			return err
+		}
+		if _, err := client.Auth().Login(ctx, username, password); err != nil {
+			return fmt.Errorf("login failed: %w", err)
+		}
+		return nil
+	}
+	return pool, ensureLogin, nil
+}
+
+// writeAllProfiles prints the holdings of every profile with per-account and
+// combined totals. Profiles are logged in and fetched concurrently; those
+// that fail are reported on stderr after the others are printed.
+func (o *globalOptions) writeAllProfiles(cmd *cobra.Command) error {
+	pool, ensureLogin, err := o.profilePool(cmd)
+	if err != nil {
+		return err
+	}
+
+	var mu sync.Mutex
+	snapshots := map[string]*stockal.Snapshot{}
+	err = pool.Each(cmd.Context(), func(ctx context.Context, name string, client stockal.StockalClient) error {
+		if err := ensureLogin(ctx, name, client); err != nil {
+			return err
+		}
+		snapshot, err := stockal.TakeSnapshot(ctx, client)
+		if err != nil {
+			return err
+		}
+		mu.Lock()
+		snapshots[name] = snapshot
+		mu.Unlock()
+		return nil
+	})
+	if len(snapshots) == 0 {
+		return err
+	}
+
+	if werr := o.write(cmd.OutOrStdout(), allProfilesResult(pool.Names(), snapshots)); werr != nil {
+		return werr
+	}
+	if err != nil {
+		fmt.Fprintf(cmd.ErrOrStderr(), "warning: some profiles failed:\n%v\n", err)
+		return exitError{code: 1}
+	}
+	return nil
+}
+
+// accountHoldings are the holdings of one profile with their totals.
+type accountHoldings struct {
+	Profile       string            `json:"profile"`
+	Holdings      []stockal.Holding `json:"holdings"`
+	TotalValue    float64           `json:"totalValue"`
+	TotalInvested float64           `json:"totalInvested"`
+}
+
+// consolidatedHoldings are the holdings of several profiles with combined totals.
+type consolidatedHoldings struct {
+	Accounts      []accountHoldings `json:"accounts"`
+	TotalValue    float64           `json:"totalValue"`
+	TotalInvested float64           `json:"totalInvested"`
+}
+
+func allProfilesResult(names []string, snapshots map[string]*stockal.Snapshot) result {
+	var all consolidatedHoldings
+	var records [][]string
+	for _, name := range names {
+		snapshot, ok := snapshots[name]
+		if !ok {
+			continue
+		}
+		account := accountHoldings{Profile: name, Holdings: snapshot.Holdings}
+		for _, h := range snapshot.Holdings {
+			account.TotalValue += h.CurrentValue()
+			account.TotalInvested += h.TotalInvestment
+			records = append(records, append([]string{name}, holdingRecord(h)...))
+		}
+		all.Accounts = append(all.Accounts, account)
+		all.TotalValue += account.TotalValue
+		all.TotalInvested += account.TotalInvested
+	}
+
+	return result{
+		value:   all,
+		columns: append([]string{"profile"}, holdingColumns...),
+		records: records,
+		table: func(w io.Writer) error {
+			t := newTable(w, "ACCOUNT", "SYMBOL", "COMPANY", "UNITS", "PRICE", "VALUE", "INVESTED", "GAIN/LOSS", "GAIN %")
+			for _, a := range all.Accounts {
+				for _, h := range a.Holdings {
+					t.row(a.Profile, h.Symbol, h.Company, units(h.TotalUnit), money(h.Price), money(h.CurrentValue()),
+						money(h.TotalInvestment), money(h.UnrealizedGain()), percent(h.UnrealizedGain(), h.TotalInvestment))
+				}
+				gain := a.TotalValue - a.TotalInvested
+				t.row(a.Profile, "", "subtotal", "", "", money(a.TotalValue), money(a.TotalInvested), money(gain), percent(gain, a.TotalInvested))
+			}
+			gain := all.TotalValue - all.TotalInvested
+			t.row("ALL", "", "total", "", "", money(all.TotalValue), money(all.TotalInvested), money(gain), percent(gain, all.TotalInvested))
+			return t.flush()
+		},
+	}
+}
@@ -0,0 +1,245 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/chzyer/readline"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// symbolCommands take a stock symbol as their first argument, which the REPL
+// completes from the portfolio.
+var symbolCommands = map[string]bool{
+	"holdings":   true,
+	"order buy":  true,
+	"order sell": true,
+	"alert add":  true,
+}
+
+func newReplCmd(opts *globalOptions) *cobra.Command {
+	return &cobra.Command{
+		Use:   "repl",
+		Short: "Run commands interactively",
+		Long: "Start an interactive prompt that runs stockalctl commands without the\n" +
+			"\"stockalctl\" prefix, keeping one session for all of them. Commands and\n" +
+			"portfolio symbols complete with Tab, history is saved between runs, and\n" +
+			"Ctrl+C interrupts the running command. Type \"exit\" or press Ctrl+D to quit.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// Global flags given to "stockalctl repl" apply to every line.
+			var globals []string
+			cmd.InheritedFlags().VisitAll(func(f *pflag.Flag) {
+				if f.Changed {
+					globals = append(globals, "--"+f.Name+"="+f.Value.String())
+				}
+			})
+
+			r := &repl{opts: opts, globals: globals, stdout: cmd.OutOrStdout(), stderr: cmd.ErrOrStderr()}
+			return r.run(context.WithoutCancel(cmd.Context()), cmd)
+		},
+	}
+}
+
+// replHistoryPath returns the REPL history file, next to the configuration file.
+func (o *globalOptions) replHistoryPath() string {
+	return filepath.Join(filepath.Dir(o.configPath), "repl_history")
+}
+
+// repl is an interactive stockalctl session.
+type repl struct {
+	opts    *globalOptions
+	globals []string
+	stdout  io.Writer
+	stderr  io.Writer
+
+	mu      sync.Mutex
+	symbols []string
+}
+
+func (r *repl) run(ctx context.Context, cmd *cobra.Command) error {
+	prompt := "stockal> "
+	if r.opts.profileKey != "default" {
+		prompt = "stockal(" + r.opts.profileKey + ")> "
+	}
+
+	rl, err := readline.NewEx(&readline.Config{
+		Prompt:            prompt,
+		HistoryFile:       r.opts.replHistoryPath(),
+		HistorySearchFold: true,
+		AutoComplete:      r.completer(newRootCmdWithOptions(&globalOptions{})),
+		InterruptPrompt:   "^C",
+		EOFPrompt:         "exit",
+		Stdout:            r.stdout,
+		Stderr:            r.stderr,
+	})
+	if err != nil {
+		return err
+	}
+	defer rl.Close()
+
+	if _, err := r.opts.session(cmd); err != nil {
+		fmt.Fprintf(r.stderr, "warning: not logged in: %v\n", err)
+	} else {
+		go r.loadSymbols(ctx, cmd)
+	}
+	fmt.Fprintln(r.stderr, "Type \"help\" for commands, \"exit\" to quit.")
+
+	for {
+		line, err := rl.Readline()
+		if errors.Is(err, readline.ErrInterrupt) {
+			continue
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		args, err := splitArgs(line)
+		if err != nil {
+			fmt.Fprintln(r.stderr, "Error:", err)
+			continue
+		}
+		if len(args) == 0 {
+			continue
+		}
+		if args[0] == "exit" || args[0] == "quit" {
+			return nil
+		}
+		if args[0] == "repl" {
+			fmt.Fprintln(r.stderr, "Error: already in the REPL")
+			continue
+		}
+
+		r.execute(ctx, args)
+		if args[0] == "order" || args[0] == "login" {
+			go r.loadSymbols(ctx, cmd)
+		}
+	}
+}
+
+// execute runs one line as a stockalctl command. Ctrl+C cancels it without
+// leaving the REPL.
+func (r *repl) execute(ctx context.Context, args []string) {
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	defer stop()
+
+	root := newRootCmdWithOptions(r.opts)
+	root.SetArgs(append(slices.Clone(r.globals), args...))
+	root.SetOut(r.stdout)
+	root.SetErr(r.stderr)
+	if err := root.ExecuteContext(ctx); err != nil {
+		var exitErr exitError
+		if !errors.As(err, &exitErr) {
+			fmt.Fprintln(r.stderr, "Error:", err)
+		}
+	}
+}
+
+// loadSymbols refreshes the portfolio symbols offered for completion.
+func (r *repl) loadSymbols(ctx context.Context, cmd *cobra.Command) {
+	client, err := r.opts.session(cmd)
+	if err != nil {
+		return
+	}
+	portfolio, err := client.Portfolio().Detail(ctx)
+	if err != nil {
+		return
+	}
+
+	symbols := make([]string, 0, len(portfolio.Data.Holdings))
+	for _, h := range portfolio.Data.Holdings {
+		symbols = append(symbols, h.Symbol)
+	}
+	slices.Sort(symbols)
+	r.mu.Lock()
+	r.symbols = slices.Compact(symbols)
+	r.mu.Unlock()
+}
+
+// completer completes command names from the command tree and symbols for
+// commands that take one.
+func (r *repl) completer(root *cobra.Command) *readline.PrefixCompleter {
+	symbols := func(string) []string {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		return slices.Clone(r.symbols)
+	}
+
+	var items func(parent *cobra.Command, path string) []readline.PrefixCompleterInterface
+	items = func(parent *cobra.Command, path string) []readline.PrefixCompleterInterface {
+		var children []readline.PrefixCompleterInterface
+		for _, c := range parent.Commands() {
+			if c.Hidden || c.Name() == "repl" || c.Name() == "completion" {
+				continue
+			}
+			name := strings.TrimSpace(path + " " + c.Name())
+			sub := items(c, name)
+			if symbolCommands[name] {
+				sub = append(sub, readline.PcItemDynamic(symbols))
+			}
+			children = append(children, readline.PcItem(c.Name(), sub...))
+		}
+		return children
+	}
+
+	top := items(root, "")
+	top = append(top, readline.PcItem("help"), readline.PcItem("exit"))
+	return readline.NewPrefixCompleter(top...)
+}
+
+// splitArgs splits a line into arguments like a shell, honouring single and
+// double quotes and backslash escapes.
+func splitArgs(line string) ([]string, error) {
+	var (
+		args    []string
+		current strings.Builder
+		inArg   bool
+		quote   rune
+		escaped bool
+	)
+	for _, c := range line {
+		switch {
+		case escaped:
+			current.WriteRune(c)
+			escaped = false
+		case c == '\\' && quote != '\'':
+			escaped, inArg = true, true
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			} else {
+				current.WriteRune(c)
+			}
+		case c == '\'' || c == '"':
+			quote, inArg = c, true
+		case c == ' ' || c == '\t':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(c)
+			inArg = true
+		}
+	}
+	if quote != 0 {
+		return nil, errors.New("unterminated quote")
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+	return args, nil
+}
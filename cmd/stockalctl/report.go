@@ -0,0 +1,163 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+	"github.com/adjaecent/unofficial-stockal-api/events"
+	"github.com/adjaecent/unofficial-stockal-api/report"
+)
+
+// smtpProfile is the email configuration of a profile. The password is read
+// from the secret named by PasswordEnv.
+type smtpProfile struct {
+	report.SMTPConfig `yaml:",inline"`
+	PasswordEnv       string `yaml:"password_env"`
+}
+
+func (p smtpProfile) config(secret secretFunc) (report.SMTPConfig, error) {
+	cfg := p.SMTPConfig
+	if p.PasswordEnv != "" {
+		password, err := secret(p.PasswordEnv)
+		if err != nil {
+			return report.SMTPConfig{}, err
+		}
+		cfg.Password = password
+	}
+	return cfg, nil
+}
+
+func newReportCmd(opts *globalOptions) *cobra.Command {
+	var (
+		format  string
+		out     string
+		top     int
+		email   bool
+		dailyAt string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "report",
+		Short: "Generate a daily portfolio digest",
+		Long: "Generate a portfolio digest with the day's change and top movers as HTML or PDF.\n\n" +
+			"With --email the digest is sent to the recipients in the profile's smtp section.\n" +
+			"With --daily-at the command keeps running and produces a digest every weekday\n" +
+			"at the given local time. Each snapshot is also posted to the profile's notifiers.",
+		Example: "  stockalctl report --format pdf --out digest.pdf\n  stockalctl report --email --daily-at 16:30",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if format != "html" && format != "pdf" {
+				return fmt.Errorf("unknown report format %q (want html or pdf)", format)
+			}
+			var smtp report.SMTPConfig
+			if email {
+				if opts.profile.SMTP.Host == "" {
+					return errors.New("--email needs an smtp section in the profile")
+				}
+				var err error
+				if smtp, err = opts.profile.SMTP.config(opts.secretFunc(cmd.Context())); err != nil {
+					return err
+				}
+			}
+
+			client, err := opts.session(cmd)
+			if err != nil {
+				return err
+			}
+
+			generate := func() error {
+				snapshot, err := stockal.TakeSnapshot(cmd.Context(), client)
+				if err != nil {
+					return err
+				}
+				publish(cmd.Context(), cmd.ErrOrStderr(), opts.bus(cmd.Context(), cmd.ErrOrStderr()), events.NewSnapshotEvent(opts.profileKey, snapshot))
+				digest := report.New(snapshot, top)
+
+				var html, pdf bytes.Buffer
+				if err := digest.WriteHTML(&html); err != nil {
+					return err
+				}
+				if format == "pdf" {
+					if err := digest.WritePDF(&pdf); err != nil {
+						return err
+					}
+				}
+
+				if email {
+					return mailDigest(smtp, digest, html.Bytes(), pdf.Bytes())
+				}
+				if format == "pdf" {
+					return writeOutput(cmd.OutOrStdout(), out, pdf.Bytes())
+				}
+				return writeOutput(cmd.OutOrStdout(), out, html.Bytes())
+			}
+
+			if dailyAt == "" {
+				return generate()
+			}
+			at, err := time.Parse("15:04", dailyAt)
+			if err != nil {
+				return fmt.Errorf("invalid --daily-at %q (want HH:MM)", dailyAt)
+			}
+			for {
+				next := nextWeekday(time.Now(), at.Hour(), at.Minute())
+				fmt.Fprintf(cmd.ErrOrStderr(), "next report at %s\n", next.Format(time.RFC1123))
+				select {
+				case <-cmd.Context().Done():
+					return nil
+				case <-time.After(time.Until(next)):
+				}
+				if err := generate(); err != nil {
+					fmt.Fprintf(cmd.ErrOrStderr(), "report failed: %v\n", err)
+				}
+			}
+		},
+	}
+	cmd.Flags().StringVar(&format, "format", "html", "report format: html or pdf")
+	cmd.Flags().StringVar(&out, "out", "-", "output file (- for stdout)")
+	cmd.Flags().IntVar(&top, "top", 5, "number of top gainers and losers to list")
+	cmd.Flags().BoolVar(&email, "email", false, "email the report using the profile's smtp settings")
+	cmd.Flags().StringVar(&dailyAt, "daily-at", "", "keep running and report every weekday at this local time (HH:MM)")
+	return cmd
+}
+
+// mailDigest emails the HTML digest, attaching the PDF rendering if there is one.
+func mailDigest(cfg report.SMTPConfig, digest *report.Digest, html, pdf []byte) error {
+	subject := fmt.Sprintf("Portfolio digest %s: %s (%+.2f%% today)",
+		digest.AsOf.Local().Format("2006-01-02"), money(digest.TotalValue), digest.DayChangePercent)
+
+	var attachments []report.Attachment
+	if len(pdf) > 0 {
+		attachments = append(attachments, report.Attachment{
+			Filename:    "portfolio-" + digest.AsOf.Local().Format("2006-01-02") + ".pdf",
+			ContentType: "application/pdf",
+			Data:        pdf,
+		})
+	}
+	return report.Mail(cfg, subject, html, attachments...)
+}
+
+// writeOutput writes data to path, or to stdout when path is "-".
+func writeOutput(stdout io.Writer, path string, data []byte) error {
+	if path == "-" {
+		_, err := stdout.Write(data)
+		return err
+	}
+	return os.WriteFile(path, data, 0o600)
+}
+
+// nextWeekday returns the next Monday-to-Friday time at hour:minute after now.
+func nextWeekday(now time.Time, hour, minute int) time.Time {
+	next := time.Date(now.Year(), now.Month(), now.Day(), hour, minute, 0, 0, now.Location())
+	for !next.After(now) || next.Weekday() == time.Saturday || next.Weekday() == time.Sunday {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
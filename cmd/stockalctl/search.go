@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+)
+
+func newSearchCmd(opts *globalOptions) *cobra.Command {
+	var (
+		search     stockal.SearchOptions
+		typ        string
+		includeAll bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "search <query>...",
+		Short: "Look up symbols by symbol or company name",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if typ != "" {
+				search.Type = stockal.AssetType(strings.ToLower(typ))
+				if !search.Type.IsKnown() {
+					return fmt.Errorf("unknown type %q (want stock or etf)", typ)
+				}
+			}
+			search.TradableOnly = !includeAll
+
+			client, err := opts.session(cmd)
+			if err != nil {
+				return err
+			}
+			resp, err := client.SearchInstruments(cmd.Context(), strings.Join(args, " "), search)
+			if err != nil {
+				return err
+			}
+			return opts.write(cmd.OutOrStdout(), instrumentsResult(resp.Data))
+		},
+	}
+	cmd.Flags().StringVar(&typ, "type", "", "show only instruments of this type: stock or etf")
+	cmd.Flags().BoolVar(&includeAll, "all", false, "include instruments that cannot be bought on Stockal")
+	cmd.Flags().IntVar(&search.Limit, "limit", 20, "show at most this many instruments")
+	return cmd
+}
+
+// instrumentColumns are the CSV columns for instruments, named after the
+// Instrument JSON fields.
+var instrumentColumns = []string{"symbol", "company", "exchange", "type", "tradable", "fractionable"}
+
+func instrumentsResult(instruments []stockal.Instrument) result {
+	records := make([][]string, 0, len(instruments))
+	for _, in := range instruments {
+		records = append(records, []string{
+			in.Symbol, in.Company, in.Exchange, string(in.Type), strconv.FormatBool(in.Tradable), strconv.FormatBool(in.Fractionable),
+		})
+	}
+
+	return result{
+		value:   instruments,
+		columns: instrumentColumns,
+		records: records,
+		table: func(w io.Writer) error {
+			t := newTable(w, "SYMBOL", "COMPANY", "EXCHANGE", "TYPE", "FRACTIONAL")
+			for _, in := range instruments {
+				fractional := "no"
+				if in.Fractionable {
+					fractional = "yes"
+				}
+				t.row(in.Symbol, in.Company, in.Exchange, string(in.Type), fractional)
+			}
+			return t.flush()
+		},
+	}
+}
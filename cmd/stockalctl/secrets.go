@@ -0,0 +1,25 @@
+package main
+
+import (
+	"context"
+
+	"github.com/adjaecent/unofficial-stockal-api/secrets"
+)
+
+// secretFunc returns the secret name, or "" if it is not set.
+type secretFunc func(name string) (string, error)
+
+// secretFunc returns a secretFunc for the selected secrets backend. The
+// backend is opened on first use and kept while the spec stays the same.
+func (o *globalOptions) secretFunc(ctx context.Context) secretFunc {
+	return func(name string) (string, error) {
+		if o.secrets == nil || o.secretsKey != o.secretsSpec {
+			provider, err := secrets.Open(ctx, o.secretsSpec)
+			if err != nil {
+				return "", err
+			}
+			o.secrets, o.secretsKey = provider, o.secretsSpec
+		}
+		return secrets.Get(ctx, o.secrets, name)
+	}
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+)
+
+//go:embed web
+var webFiles embed.FS
+
+func newServeCmd(opts *globalOptions) *cobra.Command {
+	var (
+		listen string
+		ttl    time.Duration
+	)
+
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Serve a web dashboard of the portfolio",
+		Long: "Start a local web server with a dashboard showing holdings, allocation and the\n" +
+			"day's change. Data is fetched at most once per --ttl however many browsers are open.\n\n" +
+			"The dashboard has no login of its own, so it listens on localhost by default;\n" +
+			"only bind other addresses on networks you trust.",
+		Example: "  stockalctl serve\n  stockalctl serve --listen 127.0.0.1:9000 --ttl 1m",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := opts.session(cmd)
+			if err != nil {
+				return err
+			}
+
+			static, err := fs.Sub(webFiles, "web")
+			if err != nil {
+				return err
+			}
+			snapshots := &snapshotCache{client: client, ttl: ttl}
+			mux := http.NewServeMux()
+			mux.Handle("GET /", http.FileServerFS(static))
+			mux.HandleFunc("GET /api/snapshot", func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				snapshot, err := snapshots.get(r.Context())
+				if err != nil {
+					log.Printf("snapshot: %v", err)
+					w.WriteHeader(http.StatusBadGateway)
+					json.NewEncoder(w).Encode(map[string]string{"error": "could not load the portfolio"})
+					return
+				}
+				json.NewEncoder(w).Encode(snapshot)
+			})
+
+			ln, err := net.Listen("tcp", listen)
+			if err != nil {
+				return err
+			}
+			server := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+			go func() {
+				<-cmd.Context().Done()
+				shutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				defer cancel()
+				server.Shutdown(shutdown)
+			}()
+
+			fmt.Fprintf(cmd.ErrOrStderr(), "dashboard at http://%s (Ctrl+C to stop)\n", ln.Addr())
+			if err := server.Serve(ln); !errors.Is(err, http.ErrServerClosed) {
+				return err
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&listen, "listen", "127.0.0.1:8080", "address to listen on")
+	cmd.Flags().DurationVar(&ttl, "ttl", 30*time.Second, "how long a fetched snapshot is reused")
+	return cmd
+}
+
+// snapshotCache reuses a snapshot for ttl, so that concurrent page loads make
+// one upstream request.
+type snapshotCache struct {
+	client stockal.PortfolioReader
+	ttl    time.Duration
+
+	mu       sync.Mutex
+	snapshot *stockal.Snapshot
+}
+
+func (c *snapshotCache) get(ctx context.Context) (*stockal.Snapshot, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.snapshot != nil && time.Since(c.snapshot.TakenAt) < c.ttl {
+		return c.snapshot, nil
+	}
+	snapshot, err := stockal.TakeSnapshot(context.WithoutCancel(ctx), c.client)
+	if err != nil {
+		return nil, err
+	}
+	c.snapshot = snapshot
+	return snapshot, nil
+}
@@ -0,0 +1,276 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+)
+
+func newStackCmd(opts *globalOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "stack",
+		Short: "Browse, invest in and redeem stacks, Stockal's curated portfolios",
+	}
+	cmd.AddCommand(
+		newStackListCmd(opts),
+		newStackShowCmd(opts),
+		newStackInvestCmd(opts),
+		newStackRedeemCmd(opts),
+	)
+	return cmd
+}
+
+func newStackListCmd(opts *globalOptions) *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List the stacks on offer",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := opts.session(cmd)
+			if err != nil {
+				return err
+			}
+			resp, err := client.GetStacks(cmd.Context())
+			if err != nil {
+				return err
+			}
+			return opts.write(cmd.OutOrStdout(), stacksResult(resp.Data))
+		},
+	}
+}
+
+func newStackShowCmd(opts *globalOptions) *cobra.Command {
+	return &cobra.Command{
+		Use:   "show <stack-id>",
+		Short: "Show a stack's composition, fees and performance",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := opts.session(cmd)
+			if err != nil {
+				return err
+			}
+			resp, err := client.GetStackDetail(cmd.Context(), args[0])
+			if err != nil {
+				return err
+			}
+			return opts.write(cmd.OutOrStdout(), stackDetailResult(resp.Data))
+		},
+	}
+}
+
+func newStackInvestCmd(opts *globalOptions) *cobra.Command {
+	var (
+		amount float64
+		guard  confirmFlags
+	)
+
+	cmd := &cobra.Command{
+		Use:     "invest <stack-id>",
+		Short:   "Invest an amount in a stack",
+		Example: "  stockalctl stack invest STACK_ID --amount 500",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := opts.session(cmd)
+			if err != nil {
+				return err
+			}
+			detail, err := client.GetStackDetail(cmd.Context(), args[0])
+			if err != nil {
+				return err
+			}
+			stack := detail.Data.Stack
+			if amount < stack.MinimumInvestment {
+				return fmt.Errorf("%s needs at least %s", stack.Name, money(stack.MinimumInvestment))
+			}
+			summary, err := client.Account().Summary(cmd.Context())
+			if err != nil {
+				return err
+			}
+
+			cash := summary.Data.AccountSummary.CashAvailableForTrade
+			t := newTable(cmd.ErrOrStderr(), "INVESTMENT", "")
+			t.row("Stack", stack.Name)
+			t.row("Amount", money(amount))
+			t.row("Transaction fee", money(stack.Fees.TransactionFee))
+			t.row("Management fee", fmt.Sprintf("%.2f%%/yr", stack.Fees.ManagementFee))
+			t.row("Cash available", money(cash))
+			if err := t.flush(); err != nil {
+				return err
+			}
+			if amount+stack.Fees.TransactionFee > cash {
+				fmt.Fprintln(cmd.ErrOrStderr(), "warning: the investment and fee exceed the cash available for trade")
+			}
+			if guard.dryRun {
+				return nil
+			}
+			if err := guard.confirm(cmd, "Invest in this stack?"); err != nil {
+				return err
+			}
+
+			resp, err := client.InvestInStack(cmd.Context(), stack.ID, amount)
+			if err != nil {
+				return err
+			}
+			return opts.write(cmd.OutOrStdout(), stackOrderResult(resp.Data))
+		},
+	}
+	cmd.Flags().Float64Var(&amount, "amount", 0, "dollar amount to invest")
+	cmd.MarkFlagRequired("amount")
+	guard.register(cmd)
+	return cmd
+}
+
+func newStackRedeemCmd(opts *globalOptions) *cobra.Command {
+	var (
+		redeem stockal.RedeemOptions
+		guard  confirmFlags
+	)
+
+	cmd := &cobra.Command{
+		Use:     "redeem <stack-id>",
+		Short:   "Redeem part or all of a stack investment",
+		Example: "  stockalctl stack redeem STACK_ID --amount 200\n  stockalctl stack redeem STACK_ID --all",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := redeem.Validate(); err != nil {
+				return err
+			}
+			client, err := opts.session(cmd)
+			if err != nil {
+				return err
+			}
+			detail, err := client.GetStackDetail(cmd.Context(), args[0])
+			if err != nil {
+				return err
+			}
+
+			stack := detail.Data.Stack
+			size := "everything"
+			if !redeem.All {
+				size = money(redeem.Amount)
+			}
+			t := newTable(cmd.ErrOrStderr(), "REDEMPTION", "")
+			t.row("Stack", stack.Name)
+			t.row("Amount", size)
+			t.row("Transaction fee", money(stack.Fees.TransactionFee))
+			if err := t.flush(); err != nil {
+				return err
+			}
+			if guard.dryRun {
+				return nil
+			}
+			if err := guard.confirm(cmd, "Redeem this stack?"); err != nil {
+				return err
+			}
+
+			resp, err := client.RedeemStack(cmd.Context(), stack.ID, redeem)
+			if err != nil {
+				return err
+			}
+			return opts.write(cmd.OutOrStdout(), stackOrderResult(resp.Data))
+		},
+	}
+	cmd.Flags().Float64Var(&redeem.Amount, "amount", 0, "dollar amount to redeem")
+	cmd.Flags().BoolVar(&redeem.All, "all", false, "redeem the whole investment")
+	cmd.MarkFlagsMutuallyExclusive("amount", "all")
+	cmd.MarkFlagsOneRequired("amount", "all")
+	guard.register(cmd)
+	return cmd
+}
+
+// stackColumns are the CSV columns for stacks, named after the Stack JSON
+// fields.
+var stackColumns = []string{"stackID", "name", "minimumInvestment", "managementFee", "transactionFee", "cagr"}
+
+func stackRecord(s stockal.Stack) []string {
+	return []string{s.ID, s.Name, num(s.MinimumInvestment), num(s.Fees.ManagementFee), num(s.Fees.TransactionFee), num(s.CAGR)}
+}
+
+func stacksResult(stacks []stockal.Stack) result {
+	records := make([][]string, 0, len(stacks))
+	for _, s := range stacks {
+		records = append(records, stackRecord(s))
+	}
+
+	return result{
+		value:   stacks,
+		columns: stackColumns,
+		records: records,
+		table: func(w io.Writer) error {
+			t := newTable(w, "STACK ID", "NAME", "MINIMUM", "FEE/YR", "CAGR")
+			for _, s := range stacks {
+				t.row(s.ID, s.Name, money(s.MinimumInvestment), fmt.Sprintf("%.2f%%", s.Fees.ManagementFee), fmt.Sprintf("%.2f%%", s.CAGR))
+			}
+			return t.flush()
+		},
+	}
+}
+
+// constituentColumns are the CSV columns for a stack's composition, named
+// after the StackConstituent JSON fields.
+var constituentColumns = []string{"symbol", "company", "weight"}
+
+func stackDetailResult(d stockal.StackDetail) result {
+	records := make([][]string, 0, len(d.Composition))
+	for _, c := range d.Composition {
+		records = append(records, []string{c.Symbol, c.Company, num(c.Weight)})
+	}
+
+	return result{
+		value:   d,
+		columns: constituentColumns,
+		records: records,
+		table: func(w io.Writer) error {
+			s := d.Stack
+			t := newTable(w, "FIELD", "VALUE")
+			t.row("Stack ID", s.ID)
+			t.row("Name", s.Name)
+			t.row("Creator", s.Creator)
+			t.row("Minimum", money(s.MinimumInvestment))
+			t.row("Management fee", fmt.Sprintf("%.2f%%/yr", s.Fees.ManagementFee))
+			t.row("Transaction fee", money(s.Fees.TransactionFee))
+			t.row("CAGR", fmt.Sprintf("%.2f%%", s.CAGR))
+			if len(d.Performance) > 1 {
+				t.row("Return", fmt.Sprintf("%+.2f%% since %s", d.Return(), d.Performance[0].Date))
+			}
+			if !d.RebalancedAt.IsZero() {
+				t.row("Rebalanced", d.RebalancedAt.String())
+			}
+			if err := t.flush(); err != nil {
+				return err
+			}
+
+			fmt.Fprintln(w)
+			t = newTable(w, "SYMBOL", "COMPANY", "WEIGHT")
+			for _, c := range d.Composition {
+				t.row(c.Symbol, c.Company, fmt.Sprintf("%.2f%%", c.Weight))
+			}
+			return t.flush()
+		},
+	}
+}
+
+// stackOrderColumns are the CSV columns for stack orders, named after the
+// StackOrder JSON fields.
+var stackOrderColumns = []string{"orderID", "stackID", "side", "amount", "status", "createdAt"}
+
+func stackOrderResult(o stockal.StackOrder) result {
+	return result{
+		value:   o,
+		columns: stackOrderColumns,
+		records: [][]string{{o.ID, o.StackID, string(o.Side), num(o.Amount), string(o.Status), o.CreatedAt.String()}},
+		table: func(w io.Writer) error {
+			t := newTable(w, "FIELD", "VALUE")
+			t.row("Order ID", o.ID)
+			t.row("Stack ID", o.StackID)
+			t.row("Side", string(o.Side))
+			t.row("Amount", money(o.Amount))
+			t.row("Status", string(o.Status))
+			t.row("Created", o.CreatedAt.String())
+			return t.flush()
+		},
+	}
+}
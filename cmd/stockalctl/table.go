@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+)
+
+// table writes aligned, tab-separated rows.
+type table struct {
+	w *tabwriter.Writer
+}
+
+func newTable(out io.Writer, headers ...string) *table {
+	t := &table{w: tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)}
+	t.row(headers...)
+	return t
+}
+
+func (t *table) row(cells ...string) {
+	fmt.Fprintln(t.w, strings.Join(cells, "\t"))
+}
+
+func (t *table) flush() error {
+	return t.w.Flush()
+}
+
+func money(v float64) string {
+	return fmt.Sprintf("$%.2f", v)
+}
+
+func units(v float64) string {
+	return fmt.Sprintf("%.4f", v)
+}
+
+// percent formats part as a percentage of whole, or "-" when whole is zero.
+func percent(part, whole float64) string {
+	if whole == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.2f%%", part/whole*100)
+}
+
+// asOf formats t in the local time zone, followed by the exchange's time, or
+// "-" when t is zero.
+func asOf(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	const layout = "2006-01-02 15:04 MST"
+	return t.Local().Format(layout) + " (" + stockal.ExchangeTime(t).Format(layout) + ")"
+}
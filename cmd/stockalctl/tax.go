@@ -0,0 +1,159 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+	"github.com/adjaecent/unofficial-stockal-api/providers/rbi"
+	"github.com/adjaecent/unofficial-stockal-api/tax"
+)
+
+func newTaxCmd(opts *globalOptions) *cobra.Command {
+	var (
+		fyFlag   string
+		format   string
+		out      string
+		fxSource string
+		rbiRates string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "tax",
+		Short: "Export capital gains and foreign asset schedules for Indian ITR",
+		Long: "Build ITR Schedule CG (capital gains), OS (dividends) and FA (foreign assets)\n" +
+			"for a financial year from the account's transaction and dividend history.\n\n" +
+			"CSV output writes one file per schedule, named <out>-schedule-cg.csv and so on;\n" +
+			"XLSX output writes a single workbook with a sheet per schedule. Amounts are in\n" +
+			"US dollars; with --fx they are also converted to rupees, using RBI reference\n" +
+			"rates downloaded as CSV (--fx rbi --rbi-rates file) or Stockal's current rate\n" +
+			"(--fx stockal, only useful for trades made today). Review them before filing.",
+		Example: "  stockalctl tax --fy 2024-25\n  stockalctl tax --fy 2024-25 --format xlsx --out itr-2024-25\n" +
+			"  stockalctl tax --fy 2024-25 --fx rbi --rbi-rates reference-rates.csv",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			fy, err := tax.ParseFinancialYear(fyFlag)
+			if err != nil {
+				return err
+			}
+			if format != "csv" && format != "xlsx" {
+				return fmt.Errorf("unknown tax format %q (want csv or xlsx)", format)
+			}
+			if out == "" {
+				out = "tax-" + fy.String()
+			}
+			var fx stockal.FxProvider
+			switch fxSource {
+			case "", "none":
+			case "rbi":
+				if rbiRates == "" {
+					return errors.New("--fx rbi needs --rbi-rates")
+				}
+				f, err := os.Open(rbiRates)
+				if err != nil {
+					return err
+				}
+				rates, err := rbi.Load(f)
+				f.Close()
+				if err != nil {
+					return err
+				}
+				fx = rates
+			case "stockal":
+			default:
+				return fmt.Errorf("unknown fx source %q (want none, stockal or rbi)", fxSource)
+			}
+
+			client, err := opts.session(cmd)
+			if err != nil {
+				return err
+			}
+			// Gains are computed from every purchase, not only recent ones
+			transactions, err := stockal.AllTransactions(cmd.Context(), client, stockal.TransactionOptions{
+				Types: []stockal.TransactionType{stockal.TransactionBuy, stockal.TransactionSell},
+			})
+			if err != nil {
+				return err
+			}
+			trades, err := tax.TradesFromTransactions(transactions)
+			if err != nil {
+				return err
+			}
+			// Schedule FA covers the calendar year fy starts in, which begins before fy
+			credits, err := client.GetDividends(cmd.Context(), stockal.DividendOptions{
+				From: time.Date(fy.StartYear, time.January, 1, 0, 0, 0, 0, time.UTC),
+				To:   fy.End(),
+			})
+			if err != nil {
+				return err
+			}
+			dividends, err := tax.DividendsFromCredits(credits.Data)
+			if err != nil {
+				return err
+			}
+
+			report, err := tax.NewReport(fy, trades, dividends)
+			if errors.Is(err, tax.ErrInsufficientLots) {
+				return fmt.Errorf("%w; the transaction history does not include every purchase", err)
+			}
+			if err != nil {
+				return err
+			}
+			if fxSource == "stockal" {
+				fx = stockal.StockalFx(client)
+			}
+			if fx != nil {
+				if err := report.ConvertINR(cmd.Context(), fx); err != nil {
+					return err
+				}
+			}
+
+			if format == "xlsx" {
+				path := strings.TrimSuffix(out, ".xlsx") + ".xlsx"
+				f, err := os.Create(path)
+				if err != nil {
+					return err
+				}
+				if err := report.WriteXLSX(f); err != nil {
+					f.Close()
+					return err
+				}
+				if err := f.Close(); err != nil {
+					return err
+				}
+				fmt.Fprintf(cmd.ErrOrStderr(), "Wrote %s\n", path)
+				return nil
+			}
+
+			for _, t := range report.Tables() {
+				path := out + "-" + t.Name + ".csv"
+				f, err := os.Create(path)
+				if err != nil {
+					return err
+				}
+				if err := t.WriteCSV(f); err != nil {
+					f.Close()
+					return err
+				}
+				if err := f.Close(); err != nil {
+					return err
+				}
+				fmt.Fprintf(cmd.ErrOrStderr(), "Wrote %s (%d rows)\n", path, len(t.Rows))
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&fyFlag, "fy", "", "financial year, e.g. 2024-25")
+	cmd.Flags().StringVar(&format, "format", "csv", "output format: csv or xlsx")
+	cmd.Flags().StringVar(&out, "out", "", "output file name prefix (default tax-<fy>)")
+	cmd.Flags().StringVar(&fxSource, "fx", "none", "add rupee amounts using rates from: none, stockal or rbi")
+	cmd.Flags().StringVar(&rbiRates, "rbi-rates", "", "CSV of RBI reference rates, for --fx rbi")
+	cmd.MarkFlagRequired("fy")
+	cmd.AddCommand(newTaxDocumentsCmd(opts))
+	return cmd
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+)
+
+func newTransactionsCmd(opts *globalOptions) *cobra.Command {
+	var (
+		list         stockal.TransactionOptions
+		types        []string
+		since, until string
+		all          bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "transactions",
+		Short: "List the account's activity: trades, dividends, fees, deposits and withdrawals",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			for _, t := range types {
+				typ := stockal.TransactionType(strings.ToLower(t))
+				if !typ.IsKnown() {
+					return fmt.Errorf("unknown transaction type %q (want buy, sell, dividend, fee, tax, deposit or withdrawal)", t)
+				}
+				list.Types = append(list.Types, typ)
+			}
+			var err error
+			if list.From, err = parseDateFlag("since", since); err != nil {
+				return err
+			}
+			if list.To, err = parseDateFlag("until", until); err != nil {
+				return err
+			}
+			if !list.To.IsZero() {
+				// --until includes the day given
+				list.To = list.To.AddDate(0, 0, 1)
+			}
+			if err := list.Validate(); err != nil {
+				return err
+			}
+
+			client, err := opts.session(cmd)
+			if err != nil {
+				return err
+			}
+			if all {
+				transactions, err := stockal.AllTransactions(cmd.Context(), client, list)
+				if err == nil || len(transactions) > 0 {
+					if werr := opts.write(cmd.OutOrStdout(), transactionsResult(transactions)); werr != nil {
+						return werr
+					}
+				}
+				return err
+			}
+			resp, err := client.GetTransactions(cmd.Context(), list)
+			if err != nil {
+				return err
+			}
+			if shown := list.Offset + len(resp.Data.Transactions); shown < resp.Data.TotalRecords {
+				fmt.Fprintf(cmd.ErrOrStderr(), "%d more transactions follow; continue with --offset %d, or use --all\n",
+					resp.Data.TotalRecords-shown, shown)
+			}
+			return opts.write(cmd.OutOrStdout(), transactionsResult(resp.Data.Transactions))
+		},
+	}
+	cmd.Flags().StringSliceVar(&types, "type", nil, "show only transactions of these types, e.g. dividend,fee")
+	cmd.Flags().StringVar(&list.Symbol, "symbol", "", "show only transactions for this symbol")
+	cmd.Flags().StringVar(&since, "since", "", "show only transactions on or after this date (YYYY-MM-DD)")
+	cmd.Flags().StringVar(&until, "until", "", "show only transactions on or before this date (YYYY-MM-DD)")
+	cmd.Flags().IntVar(&list.Limit, "limit", 0, "show at most this many transactions")
+	cmd.Flags().IntVar(&list.Offset, "offset", 0, "skip this many transactions")
+	cmd.Flags().BoolVar(&all, "all", false, "read every matching transaction, a page at a time")
+	return cmd
+}
+
+// transactionColumns are the CSV columns for transactions, named after the
+// Transaction JSON fields.
+var transactionColumns = []string{
+	"transactionID", "date", "type", "symbol", "quantity", "price", "amount", "orderID", "description",
+}
+
+func transactionsResult(transactions []stockal.Transaction) result {
+	records := make([][]string, 0, len(transactions))
+	for _, t := range transactions {
+		records = append(records, []string{
+			t.ID, t.Date.String(), string(t.Type), t.Symbol, num(t.Quantity), num(t.Price), num(t.Amount), t.OrderID, t.Description,
+		})
+	}
+
+	return result{
+		value:   transactions,
+		columns: transactionColumns,
+		records: records,
+		table: func(w io.Writer) error {
+			t := newTable(w, "DATE", "TYPE", "SYMBOL", "QTY", "PRICE", "AMOUNT", "DESCRIPTION")
+			for _, tx := range transactions {
+				t.row(tx.Date.String(), string(tx.Type), tx.Symbol, units(tx.Quantity), money(tx.Price), money(tx.CashFlow()), tx.Description)
+			}
+			return t.flush()
+		},
+	}
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+)
+
+func newWatchlistCmd(opts *globalOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "watchlist",
+		Short: "List and edit watchlists",
+	}
+	cmd.AddCommand(
+		newWatchlistListCmd(opts),
+		newWatchlistCreateCmd(opts),
+		newWatchlistEditCmd(opts, "add", "Add symbols to a watchlist", stockal.WatchlistManager.AddToWatchlist),
+		newWatchlistEditCmd(opts, "remove", "Remove symbols from a watchlist", stockal.WatchlistManager.RemoveFromWatchlist),
+		newWatchlistDeleteCmd(opts),
+	)
+	return cmd
+}
+
+func newWatchlistListCmd(opts *globalOptions) *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List watchlists and their symbols",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := opts.session(cmd)
+			if err != nil {
+				return err
+			}
+			resp, err := client.GetWatchlists(cmd.Context())
+			if err != nil {
+				return err
+			}
+			return opts.write(cmd.OutOrStdout(), watchlistsResult(resp.Data))
+		},
+	}
+}
+
+func newWatchlistCreateCmd(opts *globalOptions) *cobra.Command {
+	return &cobra.Command{
+		Use:   "create <name> [symbol...]",
+		Short: "Create a watchlist",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := opts.session(cmd)
+			if err != nil {
+				return err
+			}
+			resp, err := client.CreateWatchlist(cmd.Context(), args[0], args[1:]...)
+			if err != nil {
+				return err
+			}
+			return opts.write(cmd.OutOrStdout(), watchlistsResult([]stockal.Watchlist{resp.Data}))
+		},
+	}
+}
+
+// watchlistEdit is a WatchlistManager method changing a watchlist's symbols.
+type watchlistEdit func(m stockal.WatchlistManager, ctx context.Context, watchlistID string, symbols ...string) (*stockal.WatchlistResponse, error)
+
+func newWatchlistEditCmd(opts *globalOptions, use, short string, edit watchlistEdit) *cobra.Command {
+	return &cobra.Command{
+		Use:   use + " <watchlist> <symbol>...",
+		Short: short + ", given by ID or name",
+		Args:  cobra.MinimumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := opts.session(cmd)
+			if err != nil {
+				return err
+			}
+			id, err := findWatchlist(cmd.Context(), client, args[0])
+			if err != nil {
+				return err
+			}
+			resp, err := edit(client, cmd.Context(), id, args[1:]...)
+			if err != nil {
+				return err
+			}
+			return opts.write(cmd.OutOrStdout(), watchlistsResult([]stockal.Watchlist{resp.Data}))
+		},
+	}
+}
+
+func newWatchlistDeleteCmd(opts *globalOptions) *cobra.Command {
+	var guard confirmFlags
+
+	cmd := &cobra.Command{
+		Use:   "delete <watchlist>",
+		Short: "Delete a watchlist, given by ID or name",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := opts.session(cmd)
+			if err != nil {
+				return err
+			}
+			id, err := findWatchlist(cmd.Context(), client, args[0])
+			if err != nil {
+				return err
+			}
+			if guard.dryRun {
+				fmt.Fprintf(cmd.ErrOrStderr(), "Would delete watchlist %s\n", id)
+				return nil
+			}
+			if err := guard.confirm(cmd, fmt.Sprintf("Delete watchlist %q?", args[0])); err != nil {
+				return err
+			}
+			return client.DeleteWatchlist(cmd.Context(), id)
+		},
+	}
+	guard.register(cmd)
+	return cmd
+}
+
+// findWatchlist returns the ID of the watchlist with the ID or name given.
+func findWatchlist(ctx context.Context, client stockal.WatchlistManager, idOrName string) (string, error) {
+	resp, err := client.GetWatchlists(ctx)
+	if err != nil {
+		return "", err
+	}
+	for _, w := range resp.Data {
+		if w.ID == idOrName {
+			return w.ID, nil
+		}
+	}
+	if w, ok := resp.Watchlist(idOrName); ok {
+		return w.ID, nil
+	}
+	return "", fmt.Errorf("no watchlist %q", idOrName)
+}
+
+// watchlistColumns are the CSV columns for watchlists, named after the
+// Watchlist JSON fields; symbols are separated by spaces.
+var watchlistColumns = []string{"watchlistID", "name", "symbols"}
+
+func watchlistsResult(watchlists []stockal.Watchlist) result {
+	records := make([][]string, 0, len(watchlists))
+	for _, w := range watchlists {
+		records = append(records, []string{w.ID, w.Name, strings.Join(w.Symbols(), " ")})
+	}
+
+	return result{
+		value:   watchlists,
+		columns: watchlistColumns,
+		records: records,
+		table: func(w io.Writer) error {
+			t := newTable(w, "ID", "NAME", "SYMBOLS")
+			for _, record := range records {
+				t.row(record...)
+			}
+			return t.flush()
+		},
+	}
+}
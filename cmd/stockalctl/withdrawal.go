@@ -0,0 +1,120 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+)
+
+func newWithdrawalCmd(opts *globalOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "withdrawal",
+		Short: "Send money back to a linked bank account and track withdrawals",
+	}
+	cmd.AddCommand(
+		newWithdrawalListCmd(opts),
+		newWithdrawalRequestCmd(opts),
+	)
+	return cmd
+}
+
+func newWithdrawalListCmd(opts *globalOptions) *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List withdrawals and their status",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := opts.session(cmd)
+			if err != nil {
+				return err
+			}
+			resp, err := client.GetWithdrawals(cmd.Context())
+			if err != nil {
+				return err
+			}
+			return opts.write(cmd.OutOrStdout(), withdrawalsResult(resp.Data))
+		},
+	}
+}
+
+func newWithdrawalRequestCmd(opts *globalOptions) *cobra.Command {
+	var (
+		amount      float64
+		bankAccount string
+		guard       confirmFlags
+	)
+
+	cmd := &cobra.Command{
+		Use:     "request",
+		Short:   "Withdraw an amount to a linked bank account",
+		Example: "  stockalctl withdrawal request --amount 500 --bank-account BANK_ACCOUNT_ID",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := opts.session(cmd)
+			if err != nil {
+				return err
+			}
+			summary, err := client.Account().Summary(cmd.Context())
+			if err != nil {
+				return err
+			}
+
+			available := summary.Data.AccountSummary.CashAvailableForWithdrawal
+			t := newTable(cmd.ErrOrStderr(), "WITHDRAWAL", "")
+			t.row("Amount", money(amount))
+			t.row("Bank account", bankAccount)
+			t.row("Cash available", money(available))
+			if err := t.flush(); err != nil {
+				return err
+			}
+			if amount > available {
+				return fmt.Errorf("only %s is available for withdrawal", money(available))
+			}
+			if guard.dryRun {
+				return nil
+			}
+			if err := guard.confirm(cmd, "Request this withdrawal?"); err != nil {
+				return err
+			}
+
+			resp, err := client.RequestWithdrawal(cmd.Context(), amount, bankAccount)
+			if err != nil {
+				return err
+			}
+			return opts.write(cmd.OutOrStdout(), withdrawalsResult([]stockal.Withdrawal{resp.Data}))
+		},
+	}
+	cmd.Flags().Float64Var(&amount, "amount", 0, "dollar amount to withdraw")
+	cmd.Flags().StringVar(&bankAccount, "bank-account", "", "ID of the linked bank account to send the money to")
+	cmd.MarkFlagRequired("amount")
+	cmd.MarkFlagRequired("bank-account")
+	guard.register(cmd)
+	return cmd
+}
+
+// withdrawalColumns are the CSV columns for withdrawals, named after the
+// Withdrawal JSON fields.
+var withdrawalColumns = []string{"withdrawalID", "amount", "fee", "bankAccountID", "status", "reason", "createdAt", "processedAt"}
+
+func withdrawalsResult(withdrawals []stockal.Withdrawal) result {
+	records := make([][]string, 0, len(withdrawals))
+	for _, w := range withdrawals {
+		records = append(records, []string{w.ID, num(w.Amount), num(w.Fee), w.BankAccountID, string(w.Status), w.Reason, w.CreatedAt.String(), w.ProcessedAt.String()})
+	}
+
+	return result{
+		value:   withdrawals,
+		columns: withdrawalColumns,
+		records: records,
+		table: func(w io.Writer) error {
+			t := newTable(w, "WITHDRAWAL ID", "AMOUNT", "FEE", "STATUS", "REQUESTED", "NOTE")
+			for _, wd := range withdrawals {
+				t.row(wd.ID, money(wd.Amount), money(wd.Fee), string(wd.Status), wd.CreatedAt.String(), wd.Reason)
+			}
+			return t.flush()
+		},
+	}
+}
@@ -0,0 +1,227 @@
+// Package consolidate merges holdings from Stockal and other brokers into one
+// view, so that a position held in several accounts is reported once.
+//
+// Each broker is wrapped in an Adapter returning its accounts in a normalized
+// model. The package ships the Stockal adapter; adapters for other brokers
+// only need to fill in Account and Position:
+//
+//	portfolio, err := consolidate.Fetch(ctx,
+//		consolidate.Stockal(client, "stockal-personal"),
+//		myOtherBrokerAdapter,
+//	)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	for _, h := range portfolio.Holdings {
+//		fmt.Printf("%-6s %10.2f %s (%d accounts)\n", h.Symbol, h.MarketValue, h.Currency, len(h.Positions))
+//	}
+//
+// Amounts are never converted between currencies: holdings are merged only
+// within a currency, and totals are kept per currency.
+package consolidate
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// Position is a holding of one instrument in one account.
+type Position struct {
+	// Symbol identifies the instrument, such as "AAPL"
+	Symbol string `json:"symbol"`
+	// Name is the instrument's full name
+	Name string `json:"name,omitempty"`
+	// AssetClass is the broker's classification, such as "stock" or "etf"
+	AssetClass string `json:"assetClass,omitempty"`
+	// Currency is the ISO 4217 code of the amounts below
+	Currency string `json:"currency"`
+	// Quantity is the number of units held
+	Quantity float64 `json:"quantity"`
+	// Price is the latest price per unit
+	Price float64 `json:"price"`
+	// MarketValue is the current value of the position
+	MarketValue float64 `json:"marketValue"`
+	// CostBasis is the amount invested in the position
+	CostBasis float64 `json:"costBasis"`
+}
+
+// Account is one brokerage account and its positions.
+type Account struct {
+	// Broker names the broker, such as "stockal"
+	Broker string `json:"broker"`
+	// ID identifies the account within the broker
+	ID string `json:"id"`
+	// Currency is the ISO 4217 code of Cash
+	Currency string `json:"currency"`
+	// Cash is the uninvested cash balance
+	Cash float64 `json:"cash"`
+	// Positions contains every position in the account
+	Positions []Position `json:"positions"`
+	// AsOf is when the data was retrieved
+	AsOf time.Time `json:"asOf"`
+}
+
+// Adapter retrieves accounts from one broker.
+type Adapter interface {
+	// Accounts returns the broker's accounts.
+	Accounts(ctx context.Context) ([]Account, error)
+}
+
+// AdapterFunc adapts a function to the Adapter interface.
+type AdapterFunc func(ctx context.Context) ([]Account, error)
+
+// Accounts calls f.
+func (f AdapterFunc) Accounts(ctx context.Context) ([]Account, error) {
+	return f(ctx)
+}
+
+// Source is a Position together with the account holding it.
+type Source struct {
+	Broker  string `json:"broker"`
+	Account string `json:"account"`
+	Position
+}
+
+// Holding is an instrument's positions merged across accounts.
+type Holding struct {
+	Symbol     string  `json:"symbol"`
+	Name       string  `json:"name,omitempty"`
+	AssetClass string  `json:"assetClass,omitempty"`
+	Currency   string  `json:"currency"`
+	Quantity   float64 `json:"quantity"`
+	// Price is the market value per unit across all positions
+	Price       float64 `json:"price"`
+	MarketValue float64 `json:"marketValue"`
+	CostBasis   float64 `json:"costBasis"`
+	// Gain is the unrealized gain or loss against the cost basis
+	Gain float64 `json:"gain"`
+	// Weight is the share of MarketValue in the currency's total market value, in percent
+	Weight float64 `json:"weight"`
+	// Positions lists the positions that make up the holding
+	Positions []Source `json:"positions"`
+}
+
+// Totals sums one currency's holdings and cash.
+type Totals struct {
+	MarketValue float64 `json:"marketValue"`
+	CostBasis   float64 `json:"costBasis"`
+	Gain        float64 `json:"gain"`
+	Cash        float64 `json:"cash"`
+	// NetWorth is MarketValue plus Cash
+	NetWorth float64 `json:"netWorth"`
+}
+
+// Portfolio is the consolidated view of several accounts.
+type Portfolio struct {
+	// Accounts contains the accounts that were merged
+	Accounts []Account `json:"accounts"`
+	// Holdings contains one entry per instrument and currency, largest first
+	// within each currency
+	Holdings []Holding `json:"holdings"`
+	// Totals holds the totals for each currency
+	Totals map[string]Totals `json:"totals"`
+}
+
+// Fetch retrieves the accounts of all adapters concurrently and merges them.
+// It fails if any adapter fails.
+func Fetch(ctx context.Context, adapters ...Adapter) (*Portfolio, error) {
+	results := make([][]Account, len(adapters))
+	g, ctx := errgroup.WithContext(ctx)
+	for i, a := range adapters {
+		g.Go(func() error {
+			accounts, err := a.Accounts(ctx)
+			if err != nil {
+				return err
+			}
+			results[i] = accounts
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	var accounts []Account
+	for _, r := range results {
+		accounts = append(accounts, r...)
+	}
+	return Merge(accounts...), nil
+}
+
+// Merge consolidates accounts. Positions are matched by symbol, ignoring
+// case, and currency.
+func Merge(accounts ...Account) *Portfolio {
+	p := &Portfolio{Accounts: accounts, Totals: map[string]Totals{}}
+
+	type key struct{ symbol, currency string }
+	index := map[key]int{}
+	for _, a := range accounts {
+		currency := strings.ToUpper(a.Currency)
+		t := p.Totals[currency]
+		t.Cash += a.Cash
+		p.Totals[currency] = t
+
+		for _, pos := range a.Positions {
+			pos.Currency = strings.ToUpper(pos.Currency)
+			k := key{strings.ToUpper(pos.Symbol), pos.Currency}
+			i, ok := index[k]
+			if !ok {
+				i = len(p.Holdings)
+				index[k] = i
+				p.Holdings = append(p.Holdings, Holding{
+					Symbol:     k.symbol,
+					Name:       pos.Name,
+					AssetClass: pos.AssetClass,
+					Currency:   k.currency,
+				})
+			}
+			h := &p.Holdings[i]
+			if h.Name == "" {
+				h.Name = pos.Name
+			}
+			if h.AssetClass == "" {
+				h.AssetClass = pos.AssetClass
+			}
+			h.Quantity += pos.Quantity
+			h.MarketValue += pos.MarketValue
+			h.CostBasis += pos.CostBasis
+			h.Positions = append(h.Positions, Source{Broker: a.Broker, Account: a.ID, Position: pos})
+		}
+	}
+
+	for i := range p.Holdings {
+		h := &p.Holdings[i]
+		h.Gain = h.MarketValue - h.CostBasis
+		if h.Quantity != 0 {
+			h.Price = h.MarketValue / h.Quantity
+		}
+		t := p.Totals[h.Currency]
+		t.MarketValue += h.MarketValue
+		t.CostBasis += h.CostBasis
+		p.Totals[h.Currency] = t
+	}
+	for currency, t := range p.Totals {
+		t.Gain = t.MarketValue - t.CostBasis
+		t.NetWorth = t.MarketValue + t.Cash
+		p.Totals[currency] = t
+	}
+	for i := range p.Holdings {
+		h := &p.Holdings[i]
+		if total := p.Totals[h.Currency].MarketValue; total != 0 {
+			h.Weight = h.MarketValue / total * 100
+		}
+	}
+
+	sort.SliceStable(p.Holdings, func(i, j int) bool {
+		a, b := p.Holdings[i], p.Holdings[j]
+		if a.Currency != b.Currency {
+			return a.Currency < b.Currency
+		}
+		return a.MarketValue > b.MarketValue
+	})
+	return p
+}
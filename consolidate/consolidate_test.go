@@ -0,0 +1,96 @@
+package consolidate_test
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+	"github.com/adjaecent/unofficial-stockal-api/consolidate"
+	"github.com/adjaecent/unofficial-stockal-api/stockaltest"
+)
+
+func TestMerge(t *testing.T) {
+	p := consolidate.Merge(
+		consolidate.Account{Broker: "stockal", ID: "personal", Currency: "usd", Cash: 100, Positions: []consolidate.Position{
+			{Symbol: "AAPL", Currency: "USD", Quantity: 2, MarketValue: 400, CostBasis: 300},
+			{Symbol: "VOO", Name: "Vanguard S&P 500 ETF", AssetClass: "etf", Currency: "USD", Quantity: 1, MarketValue: 500, CostBasis: 450},
+		}},
+		consolidate.Account{Broker: "other", ID: "joint", Currency: "USD", Cash: 50, Positions: []consolidate.Position{
+			{Symbol: "aapl", Name: "Apple Inc", AssetClass: "stock", Currency: "usd", Quantity: 3, MarketValue: 600, CostBasis: 650},
+			{Symbol: "AAPL", Currency: "EUR", Quantity: 1, MarketValue: 180, CostBasis: 170},
+		}},
+	)
+
+	if len(p.Holdings) != 3 {
+		t.Fatalf("got %d holdings, want AAPL and VOO in USD and AAPL in EUR: %+v", len(p.Holdings), p.Holdings)
+	}
+	eur, aapl, voo := p.Holdings[0], p.Holdings[1], p.Holdings[2]
+	if eur.Symbol != "AAPL" || eur.Currency != "EUR" || eur.Quantity != 1 || eur.Weight != 100 {
+		t.Errorf("EUR holding %+v, want 1 AAPL making up all of EUR", eur)
+	}
+	if aapl.Symbol != "AAPL" || aapl.Currency != "USD" || aapl.Quantity != 5 || aapl.MarketValue != 1000 ||
+		aapl.Price != 200 || aapl.Gain != 50 || len(aapl.Positions) != 2 {
+		t.Errorf("USD AAPL %+v, want the two accounts' 5 shares merged", aapl)
+	}
+	if aapl.Name != "Apple Inc" || aapl.AssetClass != "stock" {
+		t.Errorf("USD AAPL named %q (%q), want the name and class the second account gave", aapl.Name, aapl.AssetClass)
+	}
+	if aapl.Positions[1].Broker != "other" || aapl.Positions[1].Account != "joint" {
+		t.Errorf("second AAPL position %+v, want it attributed to other/joint", aapl.Positions[1])
+	}
+	if voo.Symbol != "VOO" || math.Abs(voo.Weight-100.0/3) > 1e-9 {
+		t.Errorf("VOO %+v, want a third of the USD value", voo)
+	}
+
+	want := consolidate.Totals{MarketValue: 1500, CostBasis: 1400, Gain: 100, Cash: 150, NetWorth: 1650}
+	if got := p.Totals["USD"]; got != want {
+		t.Errorf("USD totals %+v, want %+v", got, want)
+	}
+	if got := p.Totals["EUR"]; got.MarketValue != 180 || got.Cash != 0 {
+		t.Errorf("EUR totals %+v, want 180 in holdings and no cash", got)
+	}
+}
+
+func TestMergeEmpty(t *testing.T) {
+	p := consolidate.Merge(consolidate.Account{Broker: "stockal", ID: "personal", Currency: "USD", Cash: 25})
+	if len(p.Holdings) != 0 || p.Totals["USD"].NetWorth != 25 {
+		t.Errorf("Merge = %+v, want only the cash", p)
+	}
+}
+
+func TestFetch(t *testing.T) {
+	other := consolidate.AdapterFunc(func(ctx context.Context) ([]consolidate.Account, error) {
+		return []consolidate.Account{{Broker: "other", ID: "joint", Currency: "USD", Cash: 10}}, nil
+	})
+	p, err := consolidate.Fetch(context.Background(), consolidate.Stockal(&stockaltest.MockClient{}, "personal"), other)
+	if err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+
+	holdings := stockaltest.Portfolio().Data.Holdings
+	if len(p.Accounts) != 2 || len(p.Holdings) != len(holdings) {
+		t.Fatalf("got %d accounts and %d holdings, want 2 and %d", len(p.Accounts), len(p.Holdings), len(holdings))
+	}
+	a := p.Accounts[0]
+	cash := stockaltest.AccountSummary().Data.AccountSummary.CashBalance
+	if a.Broker != "stockal" || a.ID != "personal" || a.Currency != "USD" || a.Cash != cash || a.AsOf.IsZero() {
+		t.Errorf("Stockal account %+v, want personal in USD with %.2f cash", a, cash)
+	}
+	if got := p.Totals["USD"].Cash; got != cash+10 {
+		t.Errorf("USD cash %.2f, want both accounts' %.2f", got, cash+10)
+	}
+}
+
+func TestFetchFails(t *testing.T) {
+	failing := &stockaltest.MockClient{
+		PortfolioDetailFunc: func(ctx context.Context) (*stockal.PortfolioDetailResponse, error) {
+			return nil, stockal.ErrTokenExpired
+		},
+	}
+	_, err := consolidate.Fetch(context.Background(), consolidate.Stockal(failing, "personal"))
+	if !errors.Is(err, stockal.ErrTokenExpired) {
+		t.Errorf("Fetch = %v, want the adapter's error", err)
+	}
+}
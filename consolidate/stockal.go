@@ -0,0 +1,51 @@
+package consolidate
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+)
+
+// Stockal returns an Adapter for the Stockal account read by client. The
+// account is reported with broker "stockal" and the given ID; amounts are in
+// USD.
+func Stockal(client stockal.PortfolioReader, id string) Adapter {
+	return AdapterFunc(func(ctx context.Context) ([]Account, error) {
+		snapshot, err := stockal.TakeSnapshot(ctx, client)
+		if err != nil {
+			return nil, fmt.Errorf("stockal account %s: %w", id, err)
+		}
+		return []Account{FromSnapshot(snapshot, id)}, nil
+	})
+}
+
+// FromSnapshot converts a Stockal snapshot to an Account with the given ID,
+// for merging recorded history.
+func FromSnapshot(s *stockal.Snapshot, id string) Account {
+	a := Account{
+		Broker:    "stockal",
+		ID:        id,
+		Currency:  "USD",
+		Cash:      s.Summary.AccountSummary.CashBalance,
+		Positions: make([]Position, 0, len(s.Holdings)),
+		AsOf:      s.TakenAt,
+	}
+	if a.AsOf.IsZero() {
+		a.AsOf = time.Now()
+	}
+	for _, h := range s.Holdings {
+		a.Positions = append(a.Positions, Position{
+			Symbol:      h.Symbol,
+			Name:        h.Company,
+			AssetClass:  string(h.Category),
+			Currency:    "USD",
+			Quantity:    h.TotalUnit,
+			Price:       h.Price,
+			MarketValue: h.CurrentValue(),
+			CostBasis:   h.TotalInvestment,
+		})
+	}
+	return a
+}
@@ -0,0 +1,236 @@
+package stockal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+// DividendReader is implemented by clients that can read the dividends
+// credited to the account.
+type DividendReader interface {
+	GetDividends(ctx context.Context, opts DividendOptions) (*DividendsResponse, error)
+}
+
+// ReinvestmentStatus is whether a dividend was reinvested in the stock that
+// paid it. Stockal may add statuses, so other values should be expected.
+type ReinvestmentStatus string
+
+// Reinvestment statuses.
+const (
+	// ReinvestmentNone is a dividend kept as cash
+	ReinvestmentNone ReinvestmentStatus = "none"
+	// ReinvestmentPending is a dividend to be reinvested
+	ReinvestmentPending ReinvestmentStatus = "pending"
+	// ReinvestmentDone is a dividend reinvested in the stock
+	ReinvestmentDone ReinvestmentStatus = "reinvested"
+)
+
+// IsKnown reports whether s is one of the declared reinvestment statuses.
+func (s ReinvestmentStatus) IsKnown() bool {
+	switch s {
+	case ReinvestmentNone, ReinvestmentPending, ReinvestmentDone:
+		return true
+	}
+	return false
+}
+
+// UnmarshalJSON decodes a reinvestment status, matching the declared ones
+// regardless of case and surrounding space. Unknown statuses are kept as sent.
+func (s *ReinvestmentStatus) UnmarshalJSON(data []byte) error {
+	v, err := decodeEnum(data, ReinvestmentNone, ReinvestmentPending, ReinvestmentDone)
+	*s = v
+	return err
+}
+
+// Dividend represents a dividend credited to the account.
+type Dividend struct {
+	// ID is the dividend identifier
+	ID string `json:"dividendID"`
+	// Symbol is the stock symbol that paid the dividend
+	Symbol string `json:"symbol"`
+	// PayDate is when the dividend was credited
+	PayDate Time `json:"payDate"`
+	// ExDate is the ex-dividend date the holding was counted on
+	ExDate Time `json:"exDate,omitzero"`
+	// Quantity is the number of shares the dividend was paid on
+	Quantity float64 `json:"quantity,omitempty"`
+	// Amount is the gross dividend in US dollars, before withholding tax
+	Amount float64 `json:"amount"`
+	// Withholding is the US tax withheld in US dollars, which may be claimed as a foreign tax credit
+	Withholding float64 `json:"withholding"`
+	// Reinvestment is whether the dividend was reinvested
+	Reinvestment ReinvestmentStatus `json:"reinvestment,omitempty"`
+	// ReinvestedQuantity is the number of shares bought with the dividend
+	ReinvestedQuantity float64 `json:"reinvestedQuantity,omitempty"`
+	// Extra holds the fields the API sent that this struct does not model
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// Net returns the dividend received after withholding tax.
+func (d Dividend) Net() float64 {
+	return d.Amount - d.Withholding
+}
+
+// DividendsResponse represents the response from the dividend history API.
+type DividendsResponse struct {
+	// Code is the HTTP response code
+	Code int `json:"code"`
+	// Message is the response message (usually "Success")
+	Message string `json:"message"`
+	// Data contains the dividends, newest first
+	Data []Dividend `json:"data"`
+}
+
+func (r *DividendsResponse) normalize() {
+	if r.Data == nil {
+		r.Data = []Dividend{}
+	}
+}
+
+// DividendOptions selects dividends from the account's history. The zero
+// value selects every dividend.
+type DividendOptions struct {
+	// Symbol, if set, selects the dividends paid by one symbol
+	Symbol string
+	// From, if set, selects the dividends paid at or after it
+	From time.Time
+	// To, if set, selects the dividends paid before it
+	To time.Time
+}
+
+// Validate checks the options before they are sent. The returned error wraps
+// ErrInvalidParams.
+func (o DividendOptions) Validate() error {
+	if !o.From.IsZero() && !o.To.IsZero() && !o.From.Before(o.To) {
+		return fmt.Errorf("%w: empty date range %s to %s", ErrInvalidParams,
+			o.From.Format(time.RFC3339), o.To.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// matches reports whether d passes the symbol and date filters. Dividends
+// without a pay date pass the date filters.
+func (o DividendOptions) matches(d Dividend) bool {
+	if o.Symbol != "" && !strings.EqualFold(d.Symbol, strings.TrimSpace(o.Symbol)) {
+		return false
+	}
+	at := d.PayDate.Time
+	if at.IsZero() {
+		return true
+	}
+	return (o.From.IsZero() || !at.Before(o.From)) && (o.To.IsZero() || at.Before(o.To))
+}
+
+// query returns the options as a query string, without the leading "?".
+func (o DividendOptions) query() string {
+	query := url.Values{}
+	if symbol := strings.TrimSpace(o.Symbol); symbol != "" {
+		query.Set("symbol", strings.ToUpper(symbol))
+	}
+	if !o.From.IsZero() {
+		query.Set("from", o.From.UTC().Format(time.RFC3339))
+	}
+	if !o.To.IsZero() {
+		query.Set("to", o.To.UTC().Format(time.RFC3339))
+	}
+	return query.Encode()
+}
+
+// GetDividends retrieves the dividends credited to the account that opts
+// select, newest first, with the tax withheld from each and whether it was
+// reinvested. Use DividendsByYear to total them for tax reporting.
+//
+// The filters are applied by the API. The client applies them to the response
+// as well, so that a filtered call never returns other dividends.
+//
+// Example:
+//
+//	dividends, err := client.GetDividends(ctx, stockal.DividendOptions{
+//		From: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+//	})
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	for _, y := range stockal.DividendsByYear(dividends.Data) {
+//		fmt.Printf("%d: $%.2f gross, $%.2f withheld\n", y.Year, y.Amount, y.Withholding)
+//	}
+func (c *Client) GetDividends(ctx context.Context, opts DividendOptions) (*DividendsResponse, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	endpoint := "/v2/users/dividends"
+	if query := opts.query(); query != "" {
+		endpoint += "?" + query
+	}
+	var dividendsResp DividendsResponse
+	if err := c.do(ctx, "GET", endpoint, nil, &dividendsResp, "list dividends"); err != nil {
+		return nil, err
+	}
+
+	dividends := dividendsResp.Data[:0]
+	for _, d := range dividendsResp.Data {
+		if opts.matches(d) {
+			dividends = append(dividends, d)
+		}
+	}
+	dividendsResp.Data = dividends
+	return &dividendsResp, nil
+}
+
+// DividendTotal is the dividends one symbol paid in a year.
+type DividendTotal struct {
+	// Symbol is the stock symbol that paid the dividends
+	Symbol string
+	// Amount is the gross dividends in US dollars
+	Amount float64
+	// Withholding is the US tax withheld in US dollars
+	Withholding float64
+}
+
+// DividendYear is the dividends credited in a calendar year, which is the US
+// tax year Form 1042-S reports.
+type DividendYear struct {
+	// Year is the calendar year
+	Year int
+	// Amount is the gross dividends in US dollars
+	Amount float64
+	// Withholding is the US tax withheld in US dollars
+	Withholding float64
+	// Symbols are the totals per symbol, by symbol
+	Symbols []DividendTotal
+}
+
+// DividendsByYear totals dividends by the calendar year of their pay date,
+// oldest year first. Dividends without a pay date are left out. For Indian
+// financial years, see the tax package.
+func DividendsByYear(dividends []Dividend) []DividendYear {
+	var years []DividendYear
+	for _, d := range dividends {
+		paid := d.PayDate.Time
+		if paid.IsZero() {
+			continue
+		}
+		i, found := slices.BinarySearchFunc(years, paid.Year(), func(y DividendYear, year int) int { return y.Year - year })
+		if !found {
+			years = slices.Insert(years, i, DividendYear{Year: paid.Year()})
+		}
+		y := &years[i]
+		y.Amount += d.Amount
+		y.Withholding += d.Withholding
+
+		symbol := strings.ToUpper(d.Symbol)
+		j, found := slices.BinarySearchFunc(y.Symbols, symbol, func(t DividendTotal, symbol string) int { return strings.Compare(t.Symbol, symbol) })
+		if !found {
+			y.Symbols = slices.Insert(y.Symbols, j, DividendTotal{Symbol: symbol})
+		}
+		y.Symbols[j].Amount += d.Amount
+		y.Symbols[j].Withholding += d.Withholding
+	}
+	return years
+}
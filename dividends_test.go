@@ -0,0 +1,66 @@
+package stockal_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+)
+
+func TestGetDividends(t *testing.T) {
+	var query string
+	client := newTestClientFunc(t, func(r *http.Request) string {
+		query = r.URL.RawQuery
+		return `{"code":200,"message":"Success","data":[` +
+			`{"dividendID":"d3","symbol":"MSFT","payDate":"2025-03-13","amount":7.5,"withholding":1.875,"reinvestment":"Reinvested","reinvestedQuantity":0.0142},` +
+			`{"dividendID":"d2","symbol":"AAPL","payDate":"2024-11-14","amount":2.5,"withholding":0.625,"reinvestment":"none","recordDate":"2024-11-11"},` +
+			`{"dividendID":"d1","symbol":"aapl","payDate":"2024-05-16","amount":2.25,"withholding":0.5625}]}`
+	})
+
+	resp, err := client.GetDividends(context.Background(), stockal.DividendOptions{})
+	if err != nil {
+		t.Fatalf("GetDividends: %v", err)
+	}
+	if len(resp.Data) != 3 {
+		t.Fatalf("got %+v", resp.Data)
+	}
+	d := resp.Data[0]
+	if d.Reinvestment != stockal.ReinvestmentDone || d.Net() != 5.625 || !d.PayDate.Time.Equal(time.Date(2025, 3, 13, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("got %+v", d)
+	}
+	if string(resp.Data[1].Extra["recordDate"]) != `"2024-11-11"` {
+		t.Errorf("Extra = %v", resp.Data[1].Extra)
+	}
+
+	years := stockal.DividendsByYear(resp.Data)
+	want := []stockal.DividendYear{
+		{Year: 2024, Amount: 4.75, Withholding: 1.1875, Symbols: []stockal.DividendTotal{{Symbol: "AAPL", Amount: 4.75, Withholding: 1.1875}}},
+		{Year: 2025, Amount: 7.5, Withholding: 1.875, Symbols: []stockal.DividendTotal{{Symbol: "MSFT", Amount: 7.5, Withholding: 1.875}}},
+	}
+	if !reflect.DeepEqual(years, want) {
+		t.Errorf("DividendsByYear = %+v, want %+v", years, want)
+	}
+
+	resp, err = client.GetDividends(context.Background(), stockal.DividendOptions{
+		Symbol: "aapl",
+		From:   time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC),
+	})
+	if err != nil {
+		t.Fatalf("GetDividends: %v", err)
+	}
+	if query != "from=2024-06-01T00%3A00%3A00Z&symbol=AAPL" {
+		t.Errorf("query = %q", query)
+	}
+	if len(resp.Data) != 1 || resp.Data[0].ID != "d2" {
+		t.Errorf("filtered to %+v, want d2 only", resp.Data)
+	}
+
+	day := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	if _, err := client.GetDividends(context.Background(), stockal.DividendOptions{From: day, To: day}); !errors.Is(err, stockal.ErrInvalidParams) {
+		t.Errorf("GetDividends of an empty range: error = %v, want ErrInvalidParams", err)
+	}
+}
@@ -0,0 +1,306 @@
+package stockal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DocumentReader is implemented by clients that can list and download account
+// statements and trade confirmations.
+type DocumentReader interface {
+	GetStatements(ctx context.Context, year int, month time.Month) (*DocumentsResponse, error)
+	DownloadStatement(ctx context.Context, id string, w io.Writer) error
+	GetTradeConfirmations(ctx context.Context, year int, month time.Month) (*DocumentsResponse, error)
+	DownloadTradeConfirmation(ctx context.Context, id string, w io.Writer) error
+}
+
+// TaxDocumentReader is implemented by clients that can list and download the
+// account's tax documents.
+type TaxDocumentReader interface {
+	GetTaxDocuments(ctx context.Context, year int) (*DocumentsResponse, error)
+	DownloadTaxDocument(ctx context.Context, id string, w io.Writer) error
+}
+
+// DocumentFormat is the file format of a document. Stockal may add formats,
+// so other values should be expected.
+type DocumentFormat string
+
+// Document formats.
+const (
+	DocumentPDF DocumentFormat = "pdf"
+	DocumentCSV DocumentFormat = "csv"
+)
+
+// IsKnown reports whether f is one of the declared document formats.
+func (f DocumentFormat) IsKnown() bool {
+	switch f {
+	case DocumentPDF, DocumentCSV:
+		return true
+	}
+	return false
+}
+
+// UnmarshalJSON decodes a document format, matching the declared ones
+// regardless of case and surrounding space. Unknown formats are kept as sent.
+func (f *DocumentFormat) UnmarshalJSON(data []byte) error {
+	v, err := decodeEnum(data, DocumentPDF, DocumentCSV)
+	*f = v
+	return err
+}
+
+// TaxForm is the kind of a tax document. Stockal may add kinds, so other
+// values should be expected.
+type TaxForm string
+
+// Tax forms.
+const (
+	// TaxForm1042S is the US form reporting income paid to a non-resident,
+	// chiefly dividends, and the tax withheld on it
+	TaxForm1042S TaxForm = "1042-S"
+	// TaxFormGainLoss is the report of gains and losses realised on sales
+	TaxFormGainLoss TaxForm = "gain_loss"
+)
+
+// IsKnown reports whether f is one of the declared tax forms.
+func (f TaxForm) IsKnown() bool {
+	switch f {
+	case TaxForm1042S, TaxFormGainLoss:
+		return true
+	}
+	return false
+}
+
+// UnmarshalJSON decodes a tax form, matching the declared ones regardless of
+// case and surrounding space. Unknown forms are kept as sent.
+func (f *TaxForm) UnmarshalJSON(data []byte) error {
+	v, err := decodeEnum(data, TaxForm1042S, TaxFormGainLoss)
+	*f = v
+	return err
+}
+
+// Document represents a file the account can download, such as a monthly
+// statement, a trade confirmation or a tax form.
+type Document struct {
+	// ID is the document identifier
+	ID string `json:"documentID"`
+	// Name is the document's title (e.g., "Statement March 2024")
+	Name string `json:"name"`
+	// Format is the file format
+	Format DocumentFormat `json:"format"`
+	// Date is the day the document covers: the end of a statement's period, or a confirmation's trade date
+	Date Time `json:"date"`
+	// OrderID is the order a trade confirmation is for
+	OrderID string `json:"orderID,omitempty"`
+	// Form is the kind of a tax document
+	Form TaxForm `json:"form,omitempty"`
+	// Size is the file size in bytes, when known
+	Size int64 `json:"size,omitempty"`
+	// Extra holds the fields the API sent that this struct does not model
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// FileName returns a file name for the document: its ID with the format as
+// extension, safe to use as a path element.
+func (d Document) FileName() string {
+	name := strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r == ':' || r < ' ' {
+			return '_'
+		}
+		return r
+	}, d.ID)
+	if name == "" || name == "." || name == ".." {
+		name = "document"
+	}
+	if d.Format == "" {
+		return name
+	}
+	return name + "." + strings.ToLower(string(d.Format))
+}
+
+// DocumentsResponse represents the response from the document list APIs.
+type DocumentsResponse struct {
+	// Code is the HTTP response code
+	Code int `json:"code"`
+	// Message is the response message (usually "Success")
+	Message string `json:"message"`
+	// Data contains the documents, oldest first
+	Data []Document `json:"data"`
+}
+
+func (r *DocumentsResponse) normalize() {
+	if r.Data == nil {
+		r.Data = []Document{}
+	}
+}
+
+// documentPeriod builds the query for a document list: a year, and a month
+// of it unless month is zero.
+func documentPeriod(year int, month time.Month) (string, error) {
+	var problems []string
+	if year < 1 {
+		problems = append(problems, fmt.Sprintf("invalid year %d", year))
+	}
+	if month < 0 || month > time.December {
+		problems = append(problems, fmt.Sprintf("invalid month %d", month))
+	}
+	if len(problems) > 0 {
+		return "", fmt.Errorf("%w: %s", ErrInvalidParams, strings.Join(problems, "; "))
+	}
+
+	q := url.Values{"year": {strconv.Itoa(year)}}
+	if month != 0 {
+		q.Set("month", strconv.Itoa(int(month)))
+	}
+	return q.Encode(), nil
+}
+
+// GetStatements retrieves the account statements for a month, or for the
+// whole year if month is zero. Download one with DownloadStatement.
+//
+// Example:
+//
+//	statements, err := client.GetStatements(ctx, 2024, time.March)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	for _, s := range statements.Data {
+//		f, _ := os.Create(s.FileName())
+//		err := client.DownloadStatement(ctx, s.ID, f)
+//		f.Close()
+//		if err != nil {
+//			log.Fatal(err)
+//		}
+//	}
+func (c *Client) GetStatements(ctx context.Context, year int, month time.Month) (*DocumentsResponse, error) {
+	query, err := documentPeriod(year, month)
+	if err != nil {
+		return nil, err
+	}
+
+	var documentsResp DocumentsResponse
+	if err := c.do(ctx, "GET", "/v2/documents/statements?"+query, nil, &documentsResp, "list statements"); err != nil {
+		return nil, err
+	}
+	return &documentsResp, nil
+}
+
+// DownloadStatement writes the file of the statement with the given ID to w,
+// streaming it rather than holding it in memory. If the download fails part
+// way, w holds what arrived before the error.
+func (c *Client) DownloadStatement(ctx context.Context, id string, w io.Writer) error {
+	if id == "" {
+		return fmt.Errorf("%w: statement ID is required", ErrInvalidParams)
+	}
+	return c.download(ctx, "/v2/documents/statements/"+url.PathEscape(id)+"/download", w, "download statement")
+}
+
+// GetTradeConfirmations retrieves the confirmations of the trades executed in
+// a month, or in the whole year if month is zero. Download one with
+// DownloadTradeConfirmation.
+func (c *Client) GetTradeConfirmations(ctx context.Context, year int, month time.Month) (*DocumentsResponse, error) {
+	query, err := documentPeriod(year, month)
+	if err != nil {
+		return nil, err
+	}
+
+	var documentsResp DocumentsResponse
+	if err := c.do(ctx, "GET", "/v2/documents/confirmations?"+query, nil, &documentsResp, "list trade confirmations"); err != nil {
+		return nil, err
+	}
+	return &documentsResp, nil
+}
+
+// DownloadTradeConfirmation writes the file of the trade confirmation with the
+// given ID to w, as DownloadStatement does.
+func (c *Client) DownloadTradeConfirmation(ctx context.Context, id string, w io.Writer) error {
+	if id == "" {
+		return fmt.Errorf("%w: trade confirmation ID is required", ErrInvalidParams)
+	}
+	return c.download(ctx, "/v2/documents/confirmations/"+url.PathEscape(id)+"/download", w, "download trade confirmation")
+}
+
+// GetTaxDocuments retrieves the tax documents for a US tax year, which is a
+// calendar year: the Form 1042-S of dividends and withholding, and the
+// realised gain and loss report. An Indian financial year spans two of them,
+// so filing for 2024-25 needs the documents of 2024 and 2025. Download one
+// with DownloadTaxDocument.
+//
+// Example:
+//
+//	docs, err := client.GetTaxDocuments(ctx, 2024)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	for _, d := range docs.Data {
+//		if d.Form == stockal.TaxForm1042S {
+//			f, _ := os.Create(d.FileName())
+//			err := client.DownloadTaxDocument(ctx, d.ID, f)
+//			f.Close()
+//			if err != nil {
+//				log.Fatal(err)
+//			}
+//		}
+//	}
+func (c *Client) GetTaxDocuments(ctx context.Context, year int) (*DocumentsResponse, error) {
+	query, err := documentPeriod(year, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	var documentsResp DocumentsResponse
+	if err := c.do(ctx, "GET", "/v2/documents/tax?"+query, nil, &documentsResp, "list tax documents"); err != nil {
+		return nil, err
+	}
+	return &documentsResp, nil
+}
+
+// DownloadTaxDocument writes the file of the tax document with the given ID
+// to w, as DownloadStatement does.
+func (c *Client) DownloadTaxDocument(ctx context.Context, id string, w io.Writer) error {
+	if id == "" {
+		return fmt.Errorf("%w: tax document ID is required", ErrInvalidParams)
+	}
+	return c.download(ctx, "/v2/documents/tax/"+url.PathEscape(id)+"/download", w, "download tax document")
+}
+
+// download performs an authenticated GET of a file and copies the response
+// body to w. A JSON response is the API reporting an error rather than a file,
+// and is handled as any other call's response.
+func (c *Client) download(ctx context.Context, endpoint string, w io.Writer, operation string) error {
+	if err := c.authenticate(ctx); err != nil {
+		return err
+	}
+
+	ctx, cancel := c.withDeadline(ctx)
+	defer cancel()
+
+	resp, err := c.makeRequest(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return fmt.Errorf("%s request failed: %w", operation, err)
+	}
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if resp.StatusCode != http.StatusOK || mediaType == "application/json" {
+		var errResp struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		}
+		if err := c.handleResponse(resp, &errResp, operation); err != nil {
+			return err
+		}
+		return &MalformedResponseError{Operation: operation, Problems: []string{"no file in response"}}
+	}
+
+	defer resp.Body.Close()
+	if _, err := io.Copy(w, resp.Body); err != nil {
+		return fmt.Errorf("%s failed: %w", operation, err)
+	}
+	return nil
+}
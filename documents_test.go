@@ -0,0 +1,152 @@
+package stockal_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+)
+
+func TestDocuments(t *testing.T) {
+	var requests []string
+	client := newHandlerClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.RequestURI())
+		switch r.URL.Path {
+		case "/v2/documents/statements":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"code":200,"message":"Success","data":[{"documentID":"st-2024-03","name":"Statement March 2024",` +
+				`"format":"PDF","date":"2024-03-31","size":5,"pages":2}]}`))
+		case "/v2/documents/confirmations":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"code":200,"message":"Success","data":[{"documentID":"tc1","format":"csv","date":"2024-03-04","orderID":"o1"}]}`))
+		case "/v2/documents/statements/st-2024-03/download":
+			w.Header().Set("Content-Type", "application/pdf")
+			w.Write([]byte("%PDF-"))
+		case "/v2/documents/confirmations/tc1/download":
+			w.Header().Set("Content-Type", "text/csv")
+			w.Write([]byte("[symbol],qty\nAAPL,1\n"))
+		default:
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"code":404,"message":"Document not found"}`))
+		}
+	})
+	ctx := context.Background()
+
+	statements, err := client.GetStatements(ctx, 2024, time.March)
+	if err != nil {
+		t.Fatalf("GetStatements: %v", err)
+	}
+	if len(statements.Data) != 1 {
+		t.Fatalf("got %+v", statements.Data)
+	}
+	s := statements.Data[0]
+	if s.Format != stockal.DocumentPDF || s.FileName() != "st-2024-03.pdf" || string(s.Extra["pages"]) != "2" ||
+		!s.Date.Equal(time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("got %+v", s)
+	}
+	var file bytes.Buffer
+	if err := client.DownloadStatement(ctx, s.ID, &file); err != nil || file.String() != "%PDF-" {
+		t.Errorf("DownloadStatement = %q, %v", file.String(), err)
+	}
+
+	confirmations, err := client.GetTradeConfirmations(ctx, 2024, 0)
+	if err != nil {
+		t.Fatalf("GetTradeConfirmations: %v", err)
+	}
+	c := confirmations.Data[0]
+	if c.OrderID != "o1" || c.FileName() != "tc1.csv" {
+		t.Errorf("got %+v", c)
+	}
+	file.Reset()
+	if err := client.DownloadTradeConfirmation(ctx, c.ID, &file); err != nil || file.String() != "[symbol],qty\nAAPL,1\n" {
+		t.Errorf("DownloadTradeConfirmation = %q, %v", file.String(), err)
+	}
+
+	if name := (stockal.Document{ID: "../tc:1"}).FileName(); name != ".._tc_1" {
+		t.Errorf("FileName() = %q, want a single path element", name)
+	}
+
+	file.Reset()
+	var apiErr *stockal.APIError
+	if err := client.DownloadStatement(ctx, "missing", &file); !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound || file.Len() > 0 {
+		t.Errorf("DownloadStatement of a missing statement: error = %v, wrote %q", err, file.String())
+	}
+
+	want := []string{
+		"/v2/documents/statements?month=3&year=2024",
+		"/v2/documents/statements/st-2024-03/download",
+		"/v2/documents/confirmations?year=2024",
+		"/v2/documents/confirmations/tc1/download",
+		"/v2/documents/statements/missing/download",
+	}
+	if !slices.Equal(requests, want) {
+		t.Errorf("sent %q, want %q", requests, want)
+	}
+
+	requests = nil
+	for name, err := range map[string]error{
+		"GetStatements without a year":         second(client.GetStatements(ctx, 0, time.March)),
+		"GetStatements of month 13":            second(client.GetStatements(ctx, 2024, 13)),
+		"GetTradeConfirmations of month -1":    second(client.GetTradeConfirmations(ctx, 2024, -1)),
+		"DownloadStatement without an ID":      client.DownloadStatement(ctx, "", &file),
+		"DownloadTradeConfirmation without ID": client.DownloadTradeConfirmation(ctx, "", &file),
+	} {
+		if !errors.Is(err, stockal.ErrInvalidParams) {
+			t.Errorf("%s: error = %v, want ErrInvalidParams", name, err)
+		}
+	}
+	if len(requests) > 0 {
+		t.Errorf("invalid requests sent %q", requests)
+	}
+}
+
+func TestTaxDocuments(t *testing.T) {
+	var requests []string
+	client := newHandlerClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.RequestURI())
+		switch r.URL.Path {
+		case "/v2/documents/tax":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"code":200,"message":"Success","data":[` +
+				`{"documentID":"1042s-2024","form":"1042-s","format":"pdf","date":"2024-12-31"},` +
+				`{"documentID":"gl-2024","form":"GAIN_LOSS","format":"csv","date":"2024-12-31"}]}`))
+		case "/v2/documents/tax/1042s-2024/download":
+			w.Header().Set("Content-Type", "application/pdf")
+			w.Write([]byte("%PDF-1042"))
+		}
+	})
+	ctx := context.Background()
+
+	docs, err := client.GetTaxDocuments(ctx, 2024)
+	if err != nil {
+		t.Fatalf("GetTaxDocuments: %v", err)
+	}
+	if len(docs.Data) != 2 || docs.Data[0].Form != stockal.TaxForm1042S || docs.Data[1].Form != stockal.TaxFormGainLoss {
+		t.Fatalf("got %+v", docs.Data)
+	}
+	var file bytes.Buffer
+	if err := client.DownloadTaxDocument(ctx, docs.Data[0].ID, &file); err != nil || file.String() != "%PDF-1042" {
+		t.Errorf("DownloadTaxDocument = %q, %v", file.String(), err)
+	}
+	want := []string{"/v2/documents/tax?year=2024", "/v2/documents/tax/1042s-2024/download"}
+	if !slices.Equal(requests, want) {
+		t.Errorf("sent %q, want %q", requests, want)
+	}
+
+	requests = nil
+	if _, err := client.GetTaxDocuments(ctx, 0); !errors.Is(err, stockal.ErrInvalidParams) {
+		t.Errorf("GetTaxDocuments without a year: error = %v, want ErrInvalidParams", err)
+	}
+	if err := client.DownloadTaxDocument(ctx, "", &file); !errors.Is(err, stockal.ErrInvalidParams) {
+		t.Errorf("DownloadTaxDocument without an ID: error = %v, want ErrInvalidParams", err)
+	}
+	if len(requests) > 0 {
+		t.Errorf("invalid requests sent %q", requests)
+	}
+}
@@ -0,0 +1,382 @@
+// Package events publishes account activity (portfolio snapshots, price
+// ticks, order lifecycle changes, deposits, dividends and triggered alerts)
+// to streaming platforms.
+//
+// Every event is an Event envelope whose Data has a versioned schema, named
+// in Schema and published as JSON Schema by SchemaJSON, so consumers can
+// validate payloads and evolve with them. Sinks deliver events to a platform;
+// see the kafka and nats subpackages.
+//
+// # Basic Usage
+//
+//	sink := kafka.New("http://localhost:8082")
+//	defer sink.Close()
+//
+//	snapshot, err := stockal.TakeSnapshot(ctx, client)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	err = sink.Publish(ctx, events.NewSnapshotEvent("personal", snapshot))
+//
+// OrderTracker, PriceTracker and TransactionTracker turn successive order
+// lists, quotes and transaction lists into lifecycle events, price ticks and
+// transaction events.
+package events
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+)
+
+// Type identifies the kind of an Event.
+type Type string
+
+// Event types.
+const (
+	TypeSnapshotTaken       Type = "snapshot.taken"
+	TypePriceTick           Type = "price.tick"
+	TypeOrderPlaced         Type = "order.placed"
+	TypeOrderUpdated        Type = "order.updated"
+	TypeOrderFilled         Type = "order.filled"
+	TypeOrderCancelled      Type = "order.cancelled"
+	TypeOrderRejected       Type = "order.rejected"
+	TypeAlertTriggered      Type = "alert.triggered"
+	TypeDepositCredited     Type = "deposit.credited"
+	TypeDividendPaid        Type = "dividend.paid"
+	TypeTransactionRecorded Type = "transaction.recorded"
+)
+
+// Payload schemas, as "<name>/v<version>". A version changes only when a
+// field is removed or changes meaning; new fields are added in place.
+const (
+	SchemaSnapshot    = "stockal.snapshot/v1"
+	SchemaPriceTick   = "stockal.price_tick/v1"
+	SchemaOrder       = "stockal.order/v1"
+	SchemaAlert       = "stockal.alert/v1"
+	SchemaTransaction = "stockal.transaction/v1"
+)
+
+// Event is the envelope published for every event.
+type Event struct {
+	// ID uniquely identifies the event, for consumers discarding redeliveries
+	ID string `json:"id"`
+	// Type identifies the kind of event
+	Type Type `json:"type"`
+	// Schema names the schema of Data
+	Schema string `json:"schema"`
+	// Time is when the event occurred
+	Time time.Time `json:"time"`
+	// Account names the account the event concerns; empty for market data
+	Account string `json:"account,omitempty"`
+	// Key orders related events: sinks with partitions send events with the
+	// same key to the same partition. It is the account, symbol or order ID.
+	Key string `json:"key"`
+	// Data is the payload: *Snapshot, *PriceTick, *OrderUpdate, *Transaction
+	// or *Alert
+	Data any `json:"data"`
+	// Source is the value the event was made from: a *stockal.Snapshot,
+	// stockal.Quote, stockal.Order, stockal.Transaction or Alert. It is for
+	// subscribers in the same process, such as notifiers, and is not
+	// published.
+	Source any `json:"-"`
+}
+
+// Snapshot is the payload of a TypeSnapshotTaken event.
+type Snapshot struct {
+	// TakenAt is when the snapshot was taken
+	TakenAt time.Time `json:"takenAt"`
+	// TotalValue is the current value of all holdings
+	TotalValue float64 `json:"totalValue"`
+	// TotalInvested is the amount invested in all holdings
+	TotalInvested float64 `json:"totalInvested"`
+	// CashBalance is the account's cash balance
+	CashBalance float64 `json:"cashBalance"`
+	// CashAvailable is the cash available for trading
+	CashAvailable float64 `json:"cashAvailable"`
+	// Holdings contains every holding in the portfolio
+	Holdings []Holding `json:"holdings"`
+}
+
+// Holding is one position in a Snapshot.
+type Holding struct {
+	Symbol     string  `json:"symbol"`
+	Company    string  `json:"company"`
+	Units      float64 `json:"units"`
+	Price      float64 `json:"price"`
+	PriorClose float64 `json:"priorClose"`
+	Value      float64 `json:"value"`
+	Invested   float64 `json:"invested"`
+}
+
+// PriceTick is the payload of a TypePriceTick event.
+type PriceTick struct {
+	Symbol     string  `json:"symbol"`
+	Price      float64 `json:"price"`
+	PriorClose float64 `json:"priorClose"`
+	Volume     int64   `json:"volume"`
+	// TradedAt is the time of the last trade, if known
+	TradedAt *time.Time `json:"tradedAt,omitempty"`
+	// Source names the quote provider; empty for Stockal
+	Source string `json:"source,omitempty"`
+}
+
+// OrderUpdate is the payload of the order events.
+type OrderUpdate struct {
+	OrderID string            `json:"orderId"`
+	Symbol  string            `json:"symbol"`
+	Side    stockal.OrderSide `json:"side"`
+	Type    stockal.OrderType `json:"type"`
+	Status  string            `json:"status"`
+	// PreviousStatus is the status before this change; empty for new orders
+	PreviousStatus string  `json:"previousStatus,omitempty"`
+	Quantity       float64 `json:"quantity"`
+	Amount         float64 `json:"amount"`
+	LimitPrice     float64 `json:"limitPrice,omitempty"`
+	FilledQuantity float64 `json:"filledQuantity"`
+	AveragePrice   float64 `json:"averagePrice"`
+	CreatedAt      string  `json:"createdAt"`
+}
+
+// Transaction is the payload of the TypeDepositCredited, TypeDividendPaid
+// and TypeTransactionRecorded events.
+type Transaction struct {
+	TransactionID string                  `json:"transactionId"`
+	Type          stockal.TransactionType `json:"type"`
+	// Symbol is the stock traded or paying a dividend; empty for fund movements
+	Symbol string `json:"symbol,omitempty"`
+	// Amount is the cash amount in USD, positive for money in
+	Amount      float64 `json:"amount"`
+	Description string  `json:"description,omitempty"`
+	Date        string  `json:"date"`
+}
+
+// Alert is the payload of a TypeAlertTriggered event: an alert rule whose
+// condition became true.
+type Alert struct {
+	// Symbol is the stock symbol the rule watches; empty for portfolio rules
+	Symbol string `json:"symbol,omitempty"`
+	// Condition describes the rule (e.g., "above")
+	Condition string `json:"condition"`
+	// Threshold is the value the rule compares against
+	Threshold float64 `json:"threshold"`
+	// Price is the price or portfolio value that triggered the alert
+	Price float64 `json:"price"`
+	// Message is a human-readable description of the alert
+	Message string `json:"message"`
+	// Trades are suggested orders that would resolve the alert, if any
+	Trades []stockal.OrderRequest `json:"trades,omitempty"`
+}
+
+// NewSnapshotEvent returns a TypeSnapshotTaken event for account's snapshot.
+func NewSnapshotEvent(account string, s *stockal.Snapshot) Event {
+	payload := &Snapshot{
+		TakenAt:       s.TakenAt,
+		TotalValue:    s.Summary.PortfolioSummary.TotalCurrentValue,
+		TotalInvested: s.Summary.PortfolioSummary.TotalInvestmentAmount,
+		CashBalance:   s.Summary.AccountSummary.CashBalance,
+		CashAvailable: s.Summary.AccountSummary.CashAvailableForTrade,
+		Holdings:      make([]Holding, 0, len(s.Holdings)),
+	}
+	for _, h := range s.Holdings {
+		payload.Holdings = append(payload.Holdings, Holding{
+			Symbol:     h.Symbol,
+			Company:    h.Company,
+			Units:      h.TotalUnit,
+			Price:      h.Price,
+			PriorClose: h.PriorClose,
+			Value:      h.CurrentValue(),
+			Invested:   h.TotalInvestment,
+		})
+	}
+	return newEvent(TypeSnapshotTaken, SchemaSnapshot, s.TakenAt, account, account, payload, s)
+}
+
+// NewPriceTickEvent returns a TypePriceTick event for a quote.
+func NewPriceTickEvent(q stockal.Quote) Event {
+	tick := &PriceTick{
+		Symbol:     q.Symbol,
+		Price:      q.Price,
+		PriorClose: q.PriorClose,
+		Volume:     q.Volume,
+		Source:     q.Source,
+	}
+	at := time.Now().UTC()
+	if traded := q.TradedAt(); !traded.IsZero() {
+		tick.TradedAt, at = &traded, traded
+	}
+	return newEvent(TypePriceTick, SchemaPriceTick, at, "", q.Symbol, tick, q)
+}
+
+// NewOrderEvent returns an order event for account's order, whose status
+// was previously previousStatus (empty for a new order). The event type
+// follows from the change.
+func NewOrderEvent(account string, o stockal.Order, previousStatus string) Event {
+	update := &OrderUpdate{
+		OrderID:        o.ID,
+		Symbol:         o.Symbol,
+		Side:           o.Side,
+		Type:           o.Type,
+		Status:         string(o.Status),
+		PreviousStatus: previousStatus,
+		Quantity:       o.Quantity,
+		Amount:         o.Amount,
+		LimitPrice:     o.LimitPrice,
+		FilledQuantity: o.FilledQuantity,
+		AveragePrice:   o.AveragePrice,
+		CreatedAt:      o.CreatedAt.String(),
+	}
+
+	typ := TypeOrderUpdated
+	switch {
+	case previousStatus == "":
+		typ = TypeOrderPlaced
+	case o.Status == stockal.OrderStatusFilled:
+		typ = TypeOrderFilled
+	case o.Status == stockal.OrderStatusCancelled:
+		typ = TypeOrderCancelled
+	case o.Status == stockal.OrderStatusRejected:
+		typ = TypeOrderRejected
+	}
+	return newEvent(typ, SchemaOrder, time.Now().UTC(), account, o.ID, update, o)
+}
+
+// NewOrderFilledEvent returns a TypeOrderFilled event for account's order,
+// for producers that see fills without the order's previous status.
+func NewOrderFilledEvent(account string, o stockal.Order) Event {
+	e := NewOrderEvent(account, o, "")
+	e.Type = TypeOrderFilled
+	return e
+}
+
+// NewTransactionEvent returns an event for a transaction on account:
+// TypeDepositCredited for deposits, TypeDividendPaid for dividends and
+// TypeTransactionRecorded for the rest. The event time is the transaction's.
+func NewTransactionEvent(account string, tx stockal.Transaction) Event {
+	payload := &Transaction{
+		TransactionID: tx.ID,
+		Type:          tx.Type,
+		Symbol:        tx.Symbol,
+		Amount:        tx.CashFlow(),
+		Description:   tx.Description,
+		Date:          tx.Date.String(),
+	}
+	typ := TypeTransactionRecorded
+	switch tx.Type {
+	case stockal.TransactionDeposit:
+		typ = TypeDepositCredited
+	case stockal.TransactionDividend:
+		typ = TypeDividendPaid
+	}
+	at := tx.Date.Time
+	if at.IsZero() {
+		at = time.Now().UTC()
+	}
+	key := tx.Symbol
+	if key == "" {
+		key = account
+	}
+	return newEvent(typ, SchemaTransaction, at, account, key, payload, tx)
+}
+
+// NewAlertEvent returns a TypeAlertTriggered event for an alert on account.
+func NewAlertEvent(account string, a Alert) Event {
+	payload := &a
+	key := a.Symbol
+	if key == "" {
+		key = account
+	}
+	return newEvent(TypeAlertTriggered, SchemaAlert, time.Now().UTC(), account, key, payload, a)
+}
+
+func newEvent(typ Type, schema string, at time.Time, account, key string, data, source any) Event {
+	return Event{ID: newID(), Type: typ, Schema: schema, Time: at, Account: account, Key: key, Data: data, Source: source}
+}
+
+// newID returns a random 128-bit identifier.
+func newID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// Sink delivers events to a streaming platform.
+//
+// Publish delivers the events in order and returns once the platform has
+// accepted them. Close releases the sink's resources.
+type Sink interface {
+	Publish(ctx context.Context, events ...Event) error
+	Close() error
+}
+
+// Multi returns a Sink that publishes each batch to every sink in turn. All
+// sinks are attempted; their errors are joined.
+func Multi(sinks ...Sink) Sink {
+	return multi(sinks)
+}
+
+type multi []Sink
+
+func (m multi) Publish(ctx context.Context, events ...Event) error {
+	var errs []error
+	for _, s := range m {
+		if err := s.Publish(ctx, events...); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (m multi) Close() error {
+	var errs []error
+	for _, s := range m {
+		if err := s.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Topics names the destination of each kind of event: a Kafka topic, a NATS
+// subject and so on.
+type Topics struct {
+	Snapshots  string
+	PriceTicks string
+	Orders     string
+	Alerts     string
+	// Transactions receives deposits, dividends and other transactions;
+	// if empty, they go to Orders
+	Transactions string
+}
+
+// DefaultTopics are the destinations used unless configured otherwise.
+var DefaultTopics = Topics{
+	Snapshots:    "stockal.snapshots",
+	PriceTicks:   "stockal.price-ticks",
+	Orders:       "stockal.orders",
+	Alerts:       "stockal.alerts",
+	Transactions: "stockal.transactions",
+}
+
+// For returns the destination for events of type t.
+func (t Topics) For(typ Type) string {
+	switch typ {
+	case TypeSnapshotTaken:
+		return t.Snapshots
+	case TypePriceTick:
+		return t.PriceTicks
+	case TypeAlertTriggered:
+		if t.Alerts != "" {
+			return t.Alerts
+		}
+	case TypeDepositCredited, TypeDividendPaid, TypeTransactionRecorded:
+		if t.Transactions != "" {
+			return t.Transactions
+		}
+	}
+	return t.Orders
+}
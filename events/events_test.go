@@ -0,0 +1,265 @@
+package events_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+	"github.com/adjaecent/unofficial-stockal-api/events"
+)
+
+func TestNewOrderEvent(t *testing.T) {
+	tests := []struct {
+		status   stockal.OrderStatus
+		previous string
+		want     events.Type
+	}{
+		{status: stockal.OrderStatusNew, want: events.TypeOrderPlaced},
+		{status: stockal.OrderStatusFilled, want: events.TypeOrderPlaced},
+		{status: stockal.OrderStatusPartiallyFilled, previous: "new", want: events.TypeOrderUpdated},
+		{status: stockal.OrderStatusFilled, previous: "partially_filled", want: events.TypeOrderFilled},
+		{status: stockal.OrderStatusCancelled, previous: "new", want: events.TypeOrderCancelled},
+		{status: stockal.OrderStatusRejected, previous: "new", want: events.TypeOrderRejected},
+	}
+	for _, tt := range tests {
+		o := stockal.Order{ID: "o1", Symbol: "AAPL", Side: stockal.OrderSideBuy, Status: tt.status, Quantity: 2}
+		e := events.NewOrderEvent("personal", o, tt.previous)
+		if e.Type != tt.want {
+			t.Errorf("%s after %q: type %s, want %s", tt.status, tt.previous, e.Type, tt.want)
+		}
+		update, ok := e.Data.(*events.OrderUpdate)
+		if !ok || update.Status != string(tt.status) || update.PreviousStatus != tt.previous {
+			t.Errorf("%s after %q: data %+v", tt.status, tt.previous, e.Data)
+		}
+		if e.Key != "o1" || e.Account != "personal" || e.Schema != events.SchemaOrder {
+			t.Errorf("event %+v, want personal's order o1", e)
+		}
+	}
+
+	filled := events.NewOrderFilledEvent("personal", stockal.Order{ID: "o2", Status: stockal.OrderStatusFilled})
+	if filled.Type != events.TypeOrderFilled {
+		t.Errorf("NewOrderFilledEvent type %s, want %s", filled.Type, events.TypeOrderFilled)
+	}
+}
+
+func TestNewTransactionEvent(t *testing.T) {
+	tests := []struct {
+		tx     stockal.Transaction
+		typ    events.Type
+		key    string
+		amount float64
+	}{
+		{
+			tx:  stockal.Transaction{ID: "t1", Type: stockal.TransactionDeposit, Amount: 500, Date: stockal.NewTime("2024-05-01T10:00:00Z")},
+			typ: events.TypeDepositCredited, key: "personal", amount: 500,
+		},
+		{
+			tx:  stockal.Transaction{ID: "t2", Type: stockal.TransactionDividend, Symbol: "VOO", Amount: 12.5, Date: stockal.NewTime("2024-05-01T10:00:00Z")},
+			typ: events.TypeDividendPaid, key: "VOO", amount: 12.5,
+		},
+		{
+			tx:  stockal.Transaction{ID: "t3", Type: stockal.TransactionWithdrawal, Amount: 200, Date: stockal.NewTime("2024-05-01T10:00:00Z")},
+			typ: events.TypeTransactionRecorded, key: "personal", amount: -200,
+		},
+	}
+	for _, tt := range tests {
+		e := events.NewTransactionEvent("personal", tt.tx)
+		payload := e.Data.(*events.Transaction)
+		if e.Type != tt.typ || e.Key != tt.key || payload.Amount != tt.amount {
+			t.Errorf("%s: type %s, key %q, amount %.2f; want %s, %q, %.2f", tt.tx.Type, e.Type, e.Key, payload.Amount, tt.typ, tt.key, tt.amount)
+		}
+		if !e.Time.Equal(time.Date(2024, time.May, 1, 10, 0, 0, 0, time.UTC)) {
+			t.Errorf("%s: time %v, want the transaction's date", tt.tx.Type, e.Time)
+		}
+	}
+}
+
+func TestNewPriceTickEvent(t *testing.T) {
+	e := events.NewPriceTickEvent(stockal.Quote{Symbol: "AAPL", Price: 201.5, Timestamp: 1746111600000})
+	tick := e.Data.(*events.PriceTick)
+	traded := time.UnixMilli(1746111600000).UTC()
+	if e.Key != "AAPL" || e.Account != "" || tick.TradedAt == nil || !tick.TradedAt.Equal(traded) || !e.Time.Equal(traded) {
+		t.Errorf("event %+v with tick %+v, want AAPL traded at %v", e, tick, traded)
+	}
+
+	e = events.NewPriceTickEvent(stockal.Quote{Symbol: "AAPL", Price: 201.5})
+	if tick := e.Data.(*events.PriceTick); tick.TradedAt != nil || e.Time.IsZero() {
+		t.Errorf("tick without a trade time: %+v at %v, want no trade time and the current time", tick, e.Time)
+	}
+}
+
+func TestNewAlertEvent(t *testing.T) {
+	a := events.Alert{Symbol: "AAPL", Condition: "above", Threshold: 200, Price: 201}
+	e := events.NewAlertEvent("personal", a)
+	if src, ok := e.Source.(events.Alert); e.Type != events.TypeAlertTriggered || e.Key != "AAPL" || !ok || src.Threshold != 200 {
+		t.Errorf("event %+v, want an AAPL alert made from the rule's alert", e)
+	}
+	if e := events.NewAlertEvent("personal", events.Alert{Condition: "drift"}); e.Key != "personal" {
+		t.Errorf("portfolio alert keyed %q, want the account", e.Key)
+	}
+	if e.ID == events.NewAlertEvent("personal", a).ID {
+		t.Error("two events have the same ID")
+	}
+}
+
+func TestEventJSON(t *testing.T) {
+	s := &stockal.Snapshot{TakenAt: time.Date(2025, time.May, 1, 15, 0, 0, 0, time.UTC)}
+	s.Holdings = []stockal.Holding{{Symbol: "AAPL", TotalUnit: 2, Price: 200}}
+	data, err := json.Marshal(events.NewSnapshotEvent("personal", s))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got map[string]any
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := got["Source"]; ok {
+		t.Errorf("published event %s includes its source", data)
+	}
+	if got["type"] != "snapshot.taken" || got["schema"] != events.SchemaSnapshot || got["key"] != "personal" {
+		t.Errorf("published event %s", data)
+	}
+	holdings := got["data"].(map[string]any)["holdings"].([]any)
+	if len(holdings) != 1 || holdings[0].(map[string]any)["value"] != 400.0 {
+		t.Errorf("published holdings %v, want AAPL worth 400", holdings)
+	}
+}
+
+func TestSchemaJSON(t *testing.T) {
+	for _, name := range []string{events.EnvelopeSchema, events.SchemaSnapshot, events.SchemaPriceTick,
+		events.SchemaOrder, events.SchemaAlert, events.SchemaTransaction} {
+		data, ok := events.SchemaJSON(name)
+		if !ok {
+			t.Errorf("no schema for %s", name)
+			continue
+		}
+		var schema struct {
+			ID string `json:"$id"`
+		}
+		if err := json.Unmarshal(data, &schema); err != nil || schema.ID != name {
+			t.Errorf("schema for %s has $id %q (%v)", name, schema.ID, err)
+		}
+	}
+	for _, name := range []string{"stockal.snapshot/v2", "stockal.snapshot", "snapshot.v1", ""} {
+		if _, ok := events.SchemaJSON(name); ok {
+			t.Errorf("SchemaJSON(%q) found a schema", name)
+		}
+	}
+}
+
+func TestTopicsFor(t *testing.T) {
+	tests := []struct {
+		typ          events.Type
+		topic        string
+		withoutSplit string
+	}{
+		{events.TypeSnapshotTaken, "stockal.snapshots", "s"},
+		{events.TypePriceTick, "stockal.price-ticks", "p"},
+		{events.TypeOrderFilled, "stockal.orders", "o"},
+		{events.TypeAlertTriggered, "stockal.alerts", "o"},
+		{events.TypeDividendPaid, "stockal.transactions", "o"},
+		{events.TypeTransactionRecorded, "stockal.transactions", "o"},
+	}
+	merged := events.Topics{Snapshots: "s", PriceTicks: "p", Orders: "o"}
+	for _, tt := range tests {
+		if got := events.DefaultTopics.For(tt.typ); got != tt.topic {
+			t.Errorf("DefaultTopics.For(%s) = %q, want %q", tt.typ, got, tt.topic)
+		}
+		if got := merged.For(tt.typ); got != tt.withoutSplit {
+			t.Errorf("For(%s) without alert and transaction topics = %q, want %q", tt.typ, got, tt.withoutSplit)
+		}
+	}
+}
+
+// sink records the events published to it.
+type sink struct {
+	events []events.Event
+	err    error
+	closed bool
+}
+
+func (s *sink) Publish(ctx context.Context, evs ...events.Event) error {
+	s.events = append(s.events, evs...)
+	return s.err
+}
+
+func (s *sink) Close() error {
+	s.closed = true
+	return s.err
+}
+
+func TestMulti(t *testing.T) {
+	errDown := errors.New("down")
+	failing, ok := &sink{err: errDown}, &sink{}
+	m := events.Multi(failing, ok)
+
+	e := events.NewAlertEvent("personal", events.Alert{Condition: "drift"})
+	if err := m.Publish(context.Background(), e); !errors.Is(err, errDown) {
+		t.Errorf("Publish = %v, want the failing sink's error", err)
+	}
+	if len(ok.events) != 1 || ok.events[0].ID != e.ID {
+		t.Errorf("second sink got %+v, want the event despite the first failing", ok.events)
+	}
+	if err := m.Close(); !errors.Is(err, errDown) || !failing.closed || !ok.closed {
+		t.Errorf("Close = %v, want every sink closed", err)
+	}
+}
+
+func TestOrderTracker(t *testing.T) {
+	tracker := events.NewOrderTracker("personal")
+	if evs := tracker.Update([]stockal.Order{{ID: "o1", Status: stockal.OrderStatusNew}}); len(evs) != 0 {
+		t.Errorf("first Update = %+v, want existing orders recorded only", evs)
+	}
+
+	evs := tracker.Update([]stockal.Order{
+		{ID: "o1", Status: stockal.OrderStatusPartiallyFilled, FilledQuantity: 1},
+		{ID: "o2", Status: stockal.OrderStatusNew},
+	})
+	if len(evs) != 2 || evs[0].Type != events.TypeOrderUpdated || evs[1].Type != events.TypeOrderPlaced {
+		t.Fatalf("Update = %+v, want o1 updated and o2 placed", evs)
+	}
+	if prev := evs[0].Data.(*events.OrderUpdate).PreviousStatus; prev != string(stockal.OrderStatusNew) {
+		t.Errorf("o1's previous status %q, want %q", prev, stockal.OrderStatusNew)
+	}
+
+	evs = tracker.Update([]stockal.Order{
+		{ID: "o1", Status: stockal.OrderStatusPartiallyFilled, FilledQuantity: 2},
+		{ID: "o2", Status: stockal.OrderStatusNew},
+	})
+	if len(evs) != 1 || evs[0].Type != events.TypeOrderUpdated || evs[0].Key != "o1" {
+		t.Errorf("Update = %+v, want o1's further fill only", evs)
+	}
+}
+
+func TestPriceTracker(t *testing.T) {
+	tracker := events.NewPriceTracker()
+	quotes := []stockal.Quote{{Symbol: "AAPL", Price: 200, Timestamp: 1}, {Symbol: "VOO", Price: 500, Timestamp: 1}}
+	if evs := tracker.Update(quotes); len(evs) != 2 {
+		t.Errorf("first Update = %d ticks, want 2", len(evs))
+	}
+	quotes[1].Volume = 100
+	evs := tracker.Update(quotes)
+	if len(evs) != 1 || evs[0].Key != "VOO" {
+		t.Errorf("Update = %+v, want VOO's changed volume only", evs)
+	}
+}
+
+func TestTransactionTracker(t *testing.T) {
+	tracker := events.NewTransactionTracker("personal")
+	if evs := tracker.Update([]stockal.Transaction{{ID: "t1"}}); len(evs) != 0 {
+		t.Errorf("first Update = %+v, want past transactions recorded only", evs)
+	}
+	// Newest first, as listed
+	evs := tracker.Update([]stockal.Transaction{{ID: "t3"}, {ID: "t2"}, {ID: "t1"}})
+	var ids []string
+	for _, e := range evs {
+		ids = append(ids, e.Data.(*events.Transaction).TransactionID)
+	}
+	if !slices.Equal(ids, []string{"t2", "t3"}) {
+		t.Errorf("Update reported %q, want the new transactions oldest first", ids)
+	}
+}
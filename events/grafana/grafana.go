@@ -0,0 +1,190 @@
+// Package grafana turns notable account events into Grafana annotations, so
+// that they appear on the portfolio charts drawn from the influx exporter:
+//
+//	sink := grafana.New("http://grafana.lan:3000", grafana.WithToken(os.Getenv("GRAFANA_TOKEN")),
+//		grafana.WithDashboard("stockal"), grafana.WithMinTradeValue(1000))
+//
+// Every filled order worth at least the minimum trade value, every deposit
+// and every dividend becomes one annotation, created through the HTTP API's
+// POST /api/annotations. Trades are tagged "stockal", "trade", the order side
+// and the symbol; deposits "stockal" and "deposit"; dividends "stockal",
+// "dividend" and the symbol. A dashboard selects them with an annotation
+// query on those tags. Other events are ignored.
+package grafana
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/adjaecent/unofficial-stockal-api/events"
+)
+
+// Sink posts annotations to Grafana. It implements events.Sink.
+type Sink struct {
+	grafanaURL    string
+	token         string
+	dashboardUID  string
+	tags          []string
+	minTradeValue float64
+	httpClient    *http.Client
+}
+
+var _ events.Sink = (*Sink)(nil)
+
+// Option configures a Sink.
+type Option func(*Sink)
+
+// WithToken sets the service account token used to authenticate.
+func WithToken(token string) Option {
+	return func(s *Sink) {
+		s.token = token
+	}
+}
+
+// WithDashboard attaches annotations to the dashboard with the given UID.
+// Without it they are organization-wide and shown wherever a query asks for
+// their tags.
+func WithDashboard(uid string) Option {
+	return func(s *Sink) {
+		s.dashboardUID = uid
+	}
+}
+
+// WithTags adds tags to every annotation.
+func WithTags(tags ...string) Option {
+	return func(s *Sink) {
+		s.tags = append(s.tags, tags...)
+	}
+}
+
+// WithMinTradeValue skips fills worth less than value, in USD.
+func WithMinTradeValue(value float64) Option {
+	return func(s *Sink) {
+		s.minTradeValue = value
+	}
+}
+
+// WithHTTPClient sets the HTTP client used to reach Grafana.
+func WithHTTPClient(client *http.Client) Option {
+	return func(s *Sink) {
+		s.httpClient = client
+	}
+}
+
+// New creates a Sink posting to the Grafana server at grafanaURL.
+func New(grafanaURL string, options ...Option) *Sink {
+	s := &Sink{
+		grafanaURL: strings.TrimSuffix(grafanaURL, "/"),
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+	for _, option := range options {
+		option(s)
+	}
+	return s
+}
+
+// Annotation is the body of an annotation request.
+type Annotation struct {
+	DashboardUID string   `json:"dashboardUID,omitempty"`
+	Time         int64    `json:"time"`
+	Tags         []string `json:"tags"`
+	Text         string   `json:"text"`
+}
+
+// Annotate returns the annotation for e, or false if e is not notable.
+func (s *Sink) Annotate(e events.Event) (Annotation, bool) {
+	var (
+		tags []string
+		text string
+	)
+	switch data := e.Data.(type) {
+	case *events.OrderUpdate:
+		value := data.FilledQuantity * data.AveragePrice
+		if e.Type != events.TypeOrderFilled || value < s.minTradeValue {
+			return Annotation{}, false
+		}
+		side := strings.ToLower(string(data.Side))
+		tags = []string{"stockal", "trade", side, data.Symbol}
+		text = fmt.Sprintf("%s %g %s @ $%.2f ($%.2f)", strings.ToUpper(side), data.FilledQuantity, data.Symbol, data.AveragePrice, value)
+	case *events.Transaction:
+		switch e.Type {
+		case events.TypeDepositCredited:
+			tags = []string{"stockal", "deposit"}
+			text = fmt.Sprintf("DEPOSIT $%.2f", data.Amount)
+		case events.TypeDividendPaid:
+			tags = []string{"stockal", "dividend", data.Symbol}
+			text = fmt.Sprintf("DIVIDEND %s $%.2f", data.Symbol, data.Amount)
+		default:
+			return Annotation{}, false
+		}
+	default:
+		return Annotation{}, false
+	}
+	tags = append(tags, s.tags...)
+	if e.Account != "" {
+		tags = append(tags, "account:"+e.Account)
+	}
+	return Annotation{
+		DashboardUID: s.dashboardUID,
+		Time:         e.Time.UnixMilli(),
+		Tags:         tags,
+		Text:         text,
+	}, true
+}
+
+// Publish creates an annotation for each notable event.
+func (s *Sink) Publish(ctx context.Context, evs ...events.Event) error {
+	for _, e := range evs {
+		if a, ok := s.Annotate(e); ok {
+			if err := s.post(ctx, a); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *Sink) post(ctx context.Context, a Annotation) error {
+	body, err := json.Marshal(a)
+	if err != nil {
+		return fmt.Errorf("grafana: failed to marshal annotation: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.grafanaURL+"/api/annotations", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("grafana: failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	if s.token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.token)
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("grafana: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		var e struct {
+			Message string `json:"message"`
+		}
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		if json.Unmarshal(data, &e) == nil && e.Message != "" {
+			return fmt.Errorf("grafana: creating annotation: %s (status %d)", e.Message, resp.StatusCode)
+		}
+		return fmt.Errorf("grafana: creating annotation: status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Close releases idle connections to Grafana.
+func (s *Sink) Close() error {
+	s.httpClient.CloseIdleConnections()
+	return nil
+}
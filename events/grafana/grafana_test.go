@@ -0,0 +1,66 @@
+package grafana_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+	"github.com/adjaecent/unofficial-stockal-api/events"
+	"github.com/adjaecent/unofficial-stockal-api/events/grafana"
+)
+
+func TestAnnotate(t *testing.T) {
+	sink := grafana.New("http://grafana.test", grafana.WithMinTradeValue(100), grafana.WithTags("home"))
+	tests := []struct {
+		name  string
+		event events.Event
+		tags  []string
+		text  string
+	}{
+		{
+			name: "trade",
+			event: events.NewOrderFilledEvent("personal", stockal.Order{ID: "o1", Symbol: "AAPL", Side: stockal.OrderSideBuy,
+				Status: stockal.OrderStatusFilled, FilledQuantity: 2, AveragePrice: 150}),
+			tags: []string{"stockal", "trade", "buy", "AAPL", "home", "account:personal"},
+			text: "BUY 2 AAPL @ $150.00 ($300.00)",
+		},
+		{
+			name: "small trade",
+			event: events.NewOrderFilledEvent("personal", stockal.Order{ID: "o2", Symbol: "AAPL", Side: stockal.OrderSideBuy,
+				Status: stockal.OrderStatusFilled, FilledQuantity: 0.5, AveragePrice: 150}),
+		},
+		{
+			name: "deposit",
+			event: events.NewTransactionEvent("personal", stockal.Transaction{ID: "t1", Type: stockal.TransactionDeposit,
+				Amount: 500, Date: stockal.NewTime("2024-05-01T10:00:00Z")}),
+			tags: []string{"stockal", "deposit", "home", "account:personal"},
+			text: "DEPOSIT $500.00",
+		},
+		{
+			name: "dividend",
+			event: events.NewTransactionEvent("personal", stockal.Transaction{ID: "t2", Type: stockal.TransactionDividend,
+				Symbol: "VOO", Amount: 12.5, Date: stockal.NewTime("2024-05-02T10:00:00Z")}),
+			tags: []string{"stockal", "dividend", "VOO", "home", "account:personal"},
+			text: "DIVIDEND VOO $12.50",
+		},
+		{
+			name: "fee",
+			event: events.NewTransactionEvent("personal", stockal.Transaction{ID: "t3", Type: stockal.TransactionFee,
+				Amount: 2, Date: stockal.NewTime("2024-05-03T10:00:00Z")}),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, ok := sink.Annotate(tt.event)
+			if ok != (tt.text != "") {
+				t.Fatalf("Annotate = %+v, %v", a, ok)
+			}
+			if !ok {
+				return
+			}
+			if !slices.Equal(a.Tags, tt.tags) || a.Text != tt.text || a.Time != tt.event.Time.UnixMilli() {
+				t.Errorf("Annotate = %+v, want tags %v and text %q", a, tt.tags, tt.text)
+			}
+		})
+	}
+}
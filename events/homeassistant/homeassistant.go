@@ -0,0 +1,273 @@
+// Package homeassistant publishes portfolio sensors to Home Assistant over
+// MQTT, using MQTT discovery so that they appear without any configuration:
+//
+//	sink, err := homeassistant.Connect("tcp://mqtt.lan:1883",
+//		homeassistant.WithCredentials("stockal", os.Getenv("MQTT_PASSWORD")))
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	defer sink.Close()
+//	err = sink.Publish(ctx, events.NewSnapshotEvent("personal", snapshot))
+//
+// Each account gets a device with sensors for portfolio value, day change
+// (in USD and percent), total gain, cash balance and cash available. Their
+// values are published, retained, as one JSON state message per snapshot to
+// <base>/<account>/state, and <base>/status reports whether the publisher is
+// online. Events other than snapshots are ignored.
+package homeassistant
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+
+	"github.com/adjaecent/unofficial-stockal-api/events"
+)
+
+// sensor describes one Home Assistant sensor.
+type sensor struct {
+	key, name, unit, deviceClass, stateClass, icon string
+}
+
+var sensors = []sensor{
+	{"value", "Portfolio value", "USD", "monetary", "total", "mdi:chart-line"},
+	{"day_change", "Day change", "USD", "monetary", "total", "mdi:swap-vertical"},
+	{"day_change_percent", "Day change %", "%", "", "measurement", "mdi:percent"},
+	{"gain", "Total gain", "USD", "monetary", "total", "mdi:cash-plus"},
+	{"cash_balance", "Cash balance", "USD", "monetary", "total", "mdi:cash"},
+	{"cash_available", "Cash available", "USD", "monetary", "total", "mdi:cash-check"},
+}
+
+// State is the state message published for each snapshot.
+type State struct {
+	Value            float64   `json:"value"`
+	DayChange        float64   `json:"day_change"`
+	DayChangePercent float64   `json:"day_change_percent"`
+	Gain             float64   `json:"gain"`
+	CashBalance      float64   `json:"cash_balance"`
+	CashAvailable    float64   `json:"cash_available"`
+	Holdings         int       `json:"holdings"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+// NewState computes the sensor values for a snapshot.
+func NewState(s *events.Snapshot) State {
+	state := State{
+		Value:         s.TotalValue,
+		Gain:          s.TotalValue - s.TotalInvested,
+		CashBalance:   s.CashBalance,
+		CashAvailable: s.CashAvailable,
+		Holdings:      len(s.Holdings),
+		UpdatedAt:     s.TakenAt,
+	}
+	var previous float64
+	for _, h := range s.Holdings {
+		if h.PriorClose > 0 {
+			state.DayChange += (h.Price - h.PriorClose) * h.Units
+			previous += h.PriorClose * h.Units
+		}
+	}
+	if previous > 0 {
+		state.DayChangePercent = math.Round(state.DayChange/previous*10000) / 100
+	}
+	return state
+}
+
+// Sink publishes snapshot events as Home Assistant sensors. It implements
+// events.Sink.
+type Sink struct {
+	client          mqtt.Client
+	baseTopic       string
+	discoveryPrefix string
+	options         *mqtt.ClientOptions
+
+	mu         sync.Mutex
+	discovered map[string]bool
+}
+
+var _ events.Sink = (*Sink)(nil)
+
+// Option configures a Sink.
+type Option func(*Sink)
+
+// WithCredentials sets the MQTT username and password.
+func WithCredentials(username, password string) Option {
+	return func(s *Sink) {
+		s.options.SetUsername(username)
+		s.options.SetPassword(password)
+	}
+}
+
+// WithBaseTopic sets the prefix of the state and status topics ("stockal" by
+// default).
+func WithBaseTopic(topic string) Option {
+	return func(s *Sink) {
+		s.baseTopic = topic
+	}
+}
+
+// WithDiscoveryPrefix sets Home Assistant's discovery prefix
+// ("homeassistant" by default).
+func WithDiscoveryPrefix(prefix string) Option {
+	return func(s *Sink) {
+		s.discoveryPrefix = prefix
+	}
+}
+
+// WithMQTTOptions adjusts the underlying client options, e.g. for TLS.
+func WithMQTTOptions(configure func(*mqtt.ClientOptions)) Option {
+	return func(s *Sink) {
+		configure(s.options)
+	}
+}
+
+// Connect connects to the MQTT broker at brokerURL, such as
+// "tcp://localhost:1883" or "ssl://mqtt.example.com:8883".
+func Connect(brokerURL string, options ...Option) (*Sink, error) {
+	s := &Sink{
+		baseTopic:       "stockal",
+		discoveryPrefix: "homeassistant",
+		options:         mqtt.NewClientOptions().AddBroker(brokerURL).SetClientID("stockal-events"),
+		discovered:      map[string]bool{},
+	}
+	for _, option := range options {
+		option(s)
+	}
+	status := s.baseTopic + "/status"
+	s.options.SetWill(status, "offline", 1, true)
+	s.options.SetAutoReconnect(true)
+	s.options.SetOnConnectHandler(func(c mqtt.Client) {
+		c.Publish(status, 1, true, "online")
+	})
+
+	s.client = mqtt.NewClient(s.options)
+	token := s.client.Connect()
+	if !token.WaitTimeout(30 * time.Second) {
+		return nil, fmt.Errorf("homeassistant: connecting to %s: timed out", brokerURL)
+	}
+	if err := token.Error(); err != nil {
+		return nil, fmt.Errorf("homeassistant: connecting to %s: %w", brokerURL, err)
+	}
+	return s, nil
+}
+
+// Publish updates the sensors of each snapshot's account, announcing them to
+// Home Assistant the first time the account is seen.
+func (s *Sink) Publish(ctx context.Context, evs ...events.Event) error {
+	for _, e := range evs {
+		snapshot, ok := e.Data.(*events.Snapshot)
+		if !ok {
+			continue
+		}
+		account := objectID(e.Account)
+		if err := s.discover(ctx, account, e.Account); err != nil {
+			return err
+		}
+		state, err := json.Marshal(NewState(snapshot))
+		if err != nil {
+			return fmt.Errorf("homeassistant: failed to marshal state: %w", err)
+		}
+		if err := s.publish(ctx, s.stateTopic(account), state); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// discover publishes the retained discovery configuration of the account's
+// sensors once.
+func (s *Sink) discover(ctx context.Context, account, name string) error {
+	s.mu.Lock()
+	done := s.discovered[account]
+	s.mu.Unlock()
+	if done {
+		return nil
+	}
+
+	if name == "" {
+		name = "default"
+	}
+	device := map[string]any{
+		"identifiers":  []string{"stockal_" + account},
+		"name":         "Stockal " + name,
+		"manufacturer": "Stockal",
+		"model":        "Portfolio",
+	}
+	for _, sn := range sensors {
+		config := map[string]any{
+			"name":                sn.name,
+			"unique_id":           "stockal_" + account + "_" + sn.key,
+			"state_topic":         s.stateTopic(account),
+			"value_template":      "{{ value_json." + sn.key + " }}",
+			"availability_topic":  s.baseTopic + "/status",
+			"unit_of_measurement": sn.unit,
+			"state_class":         sn.stateClass,
+			"icon":                sn.icon,
+			"device":              device,
+		}
+		if sn.deviceClass != "" {
+			config["device_class"] = sn.deviceClass
+		}
+		payload, err := json.Marshal(config)
+		if err != nil {
+			return fmt.Errorf("homeassistant: failed to marshal discovery config: %w", err)
+		}
+		topic := s.discoveryPrefix + "/sensor/stockal_" + account + "/" + sn.key + "/config"
+		if err := s.publish(ctx, topic, payload); err != nil {
+			return err
+		}
+	}
+
+	s.mu.Lock()
+	s.discovered[account] = true
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *Sink) stateTopic(account string) string {
+	return s.baseTopic + "/" + account + "/state"
+}
+
+// publish sends a retained message and waits for the broker to acknowledge it.
+func (s *Sink) publish(ctx context.Context, topic string, payload []byte) error {
+	token := s.client.Publish(topic, 1, true, payload)
+	select {
+	case <-token.Done():
+		if err := token.Error(); err != nil {
+			return fmt.Errorf("homeassistant: publishing to %s: %w", topic, err)
+		}
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close marks the publisher offline and disconnects.
+func (s *Sink) Close() error {
+	s.client.Publish(s.baseTopic+"/status", 1, true, "offline").WaitTimeout(5 * time.Second)
+	s.client.Disconnect(250)
+	return nil
+}
+
+// objectID turns an account name into a Home Assistant object ID.
+func objectID(account string) string {
+	if account == "" {
+		return "default"
+	}
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r + 'a' - 'A'
+		default:
+			return '_'
+		}
+	}, account)
+}
@@ -0,0 +1,323 @@
+package homeassistant_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+	"github.com/adjaecent/unofficial-stockal-api/events"
+	"github.com/adjaecent/unofficial-stockal-api/events/homeassistant"
+)
+
+// MQTT control packet types.
+const (
+	packetConnect    = 1
+	packetConnack    = 2
+	packetPublish    = 3
+	packetPuback     = 4
+	packetPingreq    = 12
+	packetPingresp   = 13
+	packetDisconnect = 14
+)
+
+// message is a message published to the fake broker.
+type message struct {
+	topic   string
+	payload string
+	qos     byte
+	retain  bool
+}
+
+// broker is a fake MQTT 3.1.1 broker speaking enough of the protocol for
+// publishing. It answers connections with returnCode, and acknowledges
+// publishes except to topics starting with unacknowledged.
+type broker struct {
+	ln             net.Listener
+	returnCode     byte
+	unacknowledged string
+
+	mu       sync.Mutex
+	username string
+	password string
+	will     message
+	messages []message
+}
+
+func newBroker(t *testing.T, returnCode byte, unacknowledged string) *broker {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := &broker{ln: ln, returnCode: returnCode, unacknowledged: unacknowledged}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go b.serve(conn)
+		}
+	}()
+	t.Cleanup(func() { ln.Close() })
+	return b
+}
+
+// url returns the broker's address as Connect takes it.
+func (b *broker) url() string {
+	return "tcp://" + b.ln.Addr().String()
+}
+
+// published returns the messages published so far.
+func (b *broker) published() []message {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]message(nil), b.messages...)
+}
+
+func (b *broker) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	for {
+		header, body, err := readPacket(r)
+		if err != nil {
+			return
+		}
+		switch header >> 4 {
+		case packetConnect:
+			b.connect(body)
+			conn.Write([]byte{packetConnack << 4, 2, 0, b.returnCode})
+			if b.returnCode != 0 {
+				return
+			}
+		case packetPublish:
+			topic, rest := readString(body)
+			m := message{topic: topic, qos: header >> 1 & 3, retain: header&1 == 1}
+			if m.qos > 0 {
+				if b.unacknowledged == "" || !strings.HasPrefix(topic, b.unacknowledged) {
+					conn.Write([]byte{packetPuback << 4, 2, rest[0], rest[1]})
+				}
+				rest = rest[2:]
+			}
+			m.payload = string(rest)
+			b.mu.Lock()
+			b.messages = append(b.messages, m)
+			b.mu.Unlock()
+		case packetPingreq:
+			conn.Write([]byte{packetPingresp << 4, 0})
+		case packetDisconnect:
+			return
+		}
+	}
+}
+
+// connect records the will and credentials of a CONNECT packet.
+func (b *broker) connect(body []byte) {
+	_, rest := readString(body) // protocol name
+	flags := rest[1]
+	_, rest = readString(rest[4:]) // client ID, after the level, flags and keep alive
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if flags&0x04 != 0 {
+		b.will.topic, rest = readString(rest)
+		b.will.payload, rest = readString(rest)
+		b.will.qos, b.will.retain = flags>>3&3, flags&0x20 != 0
+	}
+	if flags&0x80 != 0 {
+		b.username, rest = readString(rest)
+	}
+	if flags&0x40 != 0 {
+		b.password, _ = readString(rest)
+	}
+}
+
+// readPacket reads a control packet, returning its first byte and body.
+func readPacket(r *bufio.Reader) (byte, []byte, error) {
+	header, err := r.ReadByte()
+	if err != nil {
+		return 0, nil, err
+	}
+	length, err := binary.ReadUvarint(r)
+	if err != nil {
+		return 0, nil, err
+	}
+	body := make([]byte, length)
+	_, err = io.ReadFull(r, body)
+	return header, body, err
+}
+
+// readString reads a length-prefixed string.
+func readString(b []byte) (string, []byte) {
+	n := int(binary.BigEndian.Uint16(b))
+	return string(b[2 : 2+n]), b[2+n:]
+}
+
+// snapshot returns a snapshot holding AAPL, which rose, and VOO, which has no
+// prior close.
+func snapshot() *stockal.Snapshot {
+	s := &stockal.Snapshot{TakenAt: time.Date(2024, 5, 1, 20, 0, 0, 0, time.UTC)}
+	s.Summary.PortfolioSummary.TotalCurrentValue = 2400
+	s.Summary.PortfolioSummary.TotalInvestmentAmount = 2000
+	s.Summary.AccountSummary.CashBalance = 300
+	s.Summary.AccountSummary.CashAvailableForTrade = 250
+	s.Holdings = []stockal.Holding{
+		{Symbol: "AAPL", TotalUnit: 10, Price: 190, PriorClose: 180},
+		{Symbol: "VOO", TotalUnit: 1, Price: 500},
+	}
+	return s
+}
+
+func TestNewState(t *testing.T) {
+	got := homeassistant.NewState(events.NewSnapshotEvent("me", snapshot()).Data.(*events.Snapshot))
+	want := homeassistant.State{Value: 2400, DayChange: 100, DayChangePercent: 5.56, Gain: 400, CashBalance: 300,
+		CashAvailable: 250, Holdings: 2, UpdatedAt: time.Date(2024, 5, 1, 20, 0, 0, 0, time.UTC)}
+	if got != want {
+		t.Errorf("NewState = %+v, want %+v", got, want)
+	}
+
+	if got := homeassistant.NewState(&events.Snapshot{TotalValue: 100}); got.DayChange != 0 || got.DayChangePercent != 0 {
+		t.Errorf("NewState without holdings = %+v, want no day change", got)
+	}
+}
+
+func TestPublish(t *testing.T) {
+	b := newBroker(t, 0, "")
+	sink, err := homeassistant.Connect(b.url(), homeassistant.WithCredentials("stockal", "s3cret"),
+		homeassistant.WithBaseTopic("home/stockal"), homeassistant.WithDiscoveryPrefix("ha"))
+	if err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+
+	snapshotEvent := events.NewSnapshotEvent("Me@Example.com", snapshot())
+	order := events.NewOrderFilledEvent("Me@Example.com", stockal.Order{ID: "o1", Symbol: "AAPL", Status: stockal.OrderStatusFilled})
+	for range 2 {
+		if err := sink.Publish(context.Background(), snapshotEvent, order); err != nil {
+			t.Fatalf("Publish: %v", err)
+		}
+	}
+	sink.Close()
+
+	b.mu.Lock()
+	username, password, will := b.username, b.password, b.will
+	b.mu.Unlock()
+	if username != "stockal" || password != "s3cret" {
+		t.Errorf("connected as %q:%q, want the credentials", username, password)
+	}
+	if will != (message{topic: "home/stockal/status", payload: "offline", qos: 1, retain: true}) {
+		t.Errorf("will %+v, want a retained offline status", will)
+	}
+
+	var topics, status []string
+	published := map[string]string{}
+	for _, m := range b.published() {
+		if m.qos != 1 || !m.retain {
+			t.Errorf("%s published with QoS %d, retained %v; want QoS 1, retained", m.topic, m.qos, m.retain)
+		}
+		// The online status is published as the client connects, concurrently
+		// with the sensors
+		if m.topic == "home/stockal/status" {
+			status = append(status, m.payload)
+			continue
+		}
+		topics = append(topics, m.topic)
+		published[m.topic] = m.payload
+	}
+	if strings.Join(status, ",") != "online,offline" {
+		t.Errorf("status %q, want online then offline", status)
+	}
+	want := []string{
+		"ha/sensor/stockal_me_example_com/value/config",
+		"ha/sensor/stockal_me_example_com/day_change/config",
+		"ha/sensor/stockal_me_example_com/day_change_percent/config",
+		"ha/sensor/stockal_me_example_com/gain/config",
+		"ha/sensor/stockal_me_example_com/cash_balance/config",
+		"ha/sensor/stockal_me_example_com/cash_available/config",
+		"home/stockal/me_example_com/state",
+		"home/stockal/me_example_com/state",
+	}
+	if strings.Join(topics, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("published to\n%s\nwant the sensors announced once\n%s", strings.Join(topics, "\n"), strings.Join(want, "\n"))
+	}
+
+	var config struct {
+		Name          string `json:"name"`
+		UniqueID      string `json:"unique_id"`
+		StateTopic    string `json:"state_topic"`
+		ValueTemplate string `json:"value_template"`
+		Availability  string `json:"availability_topic"`
+		Unit          string `json:"unit_of_measurement"`
+		DeviceClass   string `json:"device_class"`
+		Device        struct {
+			Identifiers []string `json:"identifiers"`
+			Name        string   `json:"name"`
+		} `json:"device"`
+	}
+	payload := published["ha/sensor/stockal_me_example_com/value/config"]
+	if err := json.Unmarshal([]byte(payload), &config); err != nil {
+		t.Fatalf("invalid config %s: %v", payload, err)
+	}
+	if config.Name != "Portfolio value" || config.UniqueID != "stockal_me_example_com_value" ||
+		config.StateTopic != "home/stockal/me_example_com/state" || config.ValueTemplate != "{{ value_json.value }}" ||
+		config.Availability != "home/stockal/status" || config.Unit != "USD" || config.DeviceClass != "monetary" ||
+		config.Device.Name != "Stockal Me@Example.com" || len(config.Device.Identifiers) != 1 || config.Device.Identifiers[0] != "stockal_me_example_com" {
+		t.Errorf("value sensor config %+v", config)
+	}
+	if payload := published["ha/sensor/stockal_me_example_com/day_change_percent/config"]; strings.Contains(payload, `"device_class"`) {
+		t.Errorf("day change %% config %s has a device class", payload)
+	}
+
+	var state homeassistant.State
+	payload = published["home/stockal/me_example_com/state"]
+	if err := json.Unmarshal([]byte(payload), &state); err != nil {
+		t.Fatalf("invalid state %s: %v", payload, err)
+	}
+	if state != homeassistant.NewState(snapshotEvent.Data.(*events.Snapshot)) {
+		t.Errorf("state %+v, want the snapshot's", state)
+	}
+}
+
+func TestConnectRefused(t *testing.T) {
+	b := newBroker(t, 5, "") // not authorized
+	_, err := homeassistant.Connect(b.url(), homeassistant.WithCredentials("stockal", "wrong"))
+	if err == nil || !strings.HasPrefix(err.Error(), "homeassistant: connecting to "+b.url()+": ") {
+		t.Errorf("Connect = %v, want the broker's refusal", err)
+	}
+
+	b.ln.Close()
+	if _, err := homeassistant.Connect(b.url()); err == nil {
+		t.Error("Connect succeeded without a broker")
+	}
+}
+
+func TestPublishUnacknowledged(t *testing.T) {
+	b := newBroker(t, 0, "homeassistant/sensor/")
+	sink, err := homeassistant.Connect(b.url())
+	if err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	defer sink.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if err := sink.Publish(ctx, events.NewSnapshotEvent("", snapshot())); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Publish = %v, want the context's error", err)
+	}
+	var topics []string
+	for _, m := range b.published() {
+		if m.topic != "stockal/status" {
+			topics = append(topics, m.topic)
+		}
+	}
+	if len(topics) != 1 || topics[0] != "homeassistant/sensor/stockal_default/value/config" {
+		t.Errorf("published to %q, want only the default account's first sensor", topics)
+	}
+}
@@ -0,0 +1,236 @@
+// Package influx writes portfolio values and prices to InfluxDB as
+// time-series points in line protocol, for dashboards on a TIG stack:
+//
+//	sink := influx.New("http://localhost:8086", "stockal",
+//		influx.WithOrg("home"), influx.WithToken(os.Getenv("INFLUX_TOKEN")))
+//	err := sink.Publish(ctx, events.NewSnapshotEvent("personal", snapshot))
+//
+// Points are written with the /api/v2/write endpoint, which InfluxDB 2 and 3
+// serve and InfluxDB 1.8+ accepts with "database/retention-policy" as the
+// bucket. Snapshot events become these measurements:
+//
+//	stockal_portfolio,account=<account> value,invested,gain,cash_balance,cash_available,holdings
+//	stockal_holding,account=<account>,symbol=<symbol> units,price,prior_close,value,invested,gain
+//
+// and price ticks become
+//
+//	stockal_price,symbol=<symbol>[,source=<provider>] price,prior_close,volume
+//
+// Order events carry no time series and are ignored.
+package influx
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/adjaecent/unofficial-stockal-api/events"
+)
+
+// Measurement names.
+const (
+	MeasurementPortfolio = "stockal_portfolio"
+	MeasurementHolding   = "stockal_holding"
+	MeasurementPrice     = "stockal_price"
+)
+
+// Sink writes events to an InfluxDB bucket. It implements events.Sink.
+type Sink struct {
+	serverURL  string
+	bucket     string
+	org        string
+	token      string
+	httpClient *http.Client
+}
+
+var _ events.Sink = (*Sink)(nil)
+
+// Option configures a Sink.
+type Option func(*Sink)
+
+// WithOrg sets the organization owning the bucket (InfluxDB 2).
+func WithOrg(org string) Option {
+	return func(s *Sink) {
+		s.org = org
+	}
+}
+
+// WithToken sets the API token, or "username:password" for InfluxDB 1.8.
+func WithToken(token string) Option {
+	return func(s *Sink) {
+		s.token = token
+	}
+}
+
+// WithHTTPClient sets the HTTP client used to reach the server.
+func WithHTTPClient(client *http.Client) Option {
+	return func(s *Sink) {
+		s.httpClient = client
+	}
+}
+
+// New creates a Sink writing to bucket on the server at serverURL.
+func New(serverURL, bucket string, options ...Option) *Sink {
+	s := &Sink{
+		serverURL:  strings.TrimSuffix(serverURL, "/"),
+		bucket:     bucket,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+	for _, option := range options {
+		option(s)
+	}
+	return s
+}
+
+// Publish writes the points for the events in one request.
+func (s *Sink) Publish(ctx context.Context, evs ...events.Event) error {
+	var body []byte
+	for _, e := range evs {
+		body = AppendLines(body, e)
+	}
+	if len(body) == 0 {
+		return nil
+	}
+
+	query := url.Values{"bucket": {s.bucket}, "precision": {"ns"}}
+	if s.org != "" {
+		query.Set("org", s.org)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.serverURL+"/api/v2/write?"+query.Encode(), bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("influx: failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	if s.token != "" {
+		req.Header.Set("Authorization", "Token "+s.token)
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("influx: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("influx: writing to %s: status %d: %s", s.bucket, resp.StatusCode, bytes.TrimSpace(msg))
+	}
+	return nil
+}
+
+// Close releases idle connections to the server.
+func (s *Sink) Close() error {
+	s.httpClient.CloseIdleConnections()
+	return nil
+}
+
+// AppendLines appends the line protocol points for e to b, one per line, and
+// returns the extended buffer. Events without points leave b unchanged. It
+// can feed Telegraf or other line protocol consumers directly.
+func AppendLines(b []byte, e events.Event) []byte {
+	switch data := e.Data.(type) {
+	case *events.Snapshot:
+		at := data.TakenAt.UnixNano()
+		b = appendPoint(b, MeasurementPortfolio, []tag{{"account", e.Account}}, []field{
+			{"value", data.TotalValue},
+			{"invested", data.TotalInvested},
+			{"gain", data.TotalValue - data.TotalInvested},
+			{"cash_balance", data.CashBalance},
+			{"cash_available", data.CashAvailable},
+			{"holdings", len(data.Holdings)},
+		}, at)
+		for _, h := range data.Holdings {
+			b = appendPoint(b, MeasurementHolding, []tag{{"account", e.Account}, {"symbol", h.Symbol}}, []field{
+				{"units", h.Units},
+				{"price", h.Price},
+				{"prior_close", h.PriorClose},
+				{"value", h.Value},
+				{"invested", h.Invested},
+				{"gain", h.Value - h.Invested},
+			}, at)
+		}
+	case *events.PriceTick:
+		b = appendPoint(b, MeasurementPrice, []tag{{"source", data.Source}, {"symbol", data.Symbol}}, []field{
+			{"price", data.Price},
+			{"prior_close", data.PriorClose},
+			{"volume", data.Volume},
+		}, e.Time.UnixNano())
+	}
+	return b
+}
+
+type tag struct {
+	key, value string
+}
+
+type field struct {
+	key   string
+	value any
+}
+
+// appendPoint appends one line. Tags must be sorted by key; empty tags and
+// non-finite fields are left out, as line protocol cannot express them.
+func appendPoint(b []byte, measurement string, tags []tag, fields []field, at int64) []byte {
+	b = append(b, escape(measurement, ", ")...)
+	for _, t := range tags {
+		if t.value == "" {
+			continue
+		}
+		b = append(b, ',')
+		b = append(b, escape(t.key, ",= ")...)
+		b = append(b, '=')
+		b = append(b, escape(t.value, ",= ")...)
+	}
+	sep := byte(' ')
+	for _, f := range fields {
+		switch v := f.value.(type) {
+		case float64:
+			if math.IsNaN(v) || math.IsInf(v, 0) {
+				continue
+			}
+			b = append(b, sep)
+			b = append(b, f.key...)
+			b = append(b, '=')
+			b = strconv.AppendFloat(b, v, 'f', -1, 64)
+		case int:
+			b = append(b, sep)
+			b = append(b, f.key...)
+			b = append(b, '=')
+			b = strconv.AppendInt(b, int64(v), 10)
+			b = append(b, 'i')
+		case int64:
+			b = append(b, sep)
+			b = append(b, f.key...)
+			b = append(b, '=')
+			b = strconv.AppendInt(b, v, 10)
+			b = append(b, 'i')
+		default:
+			continue
+		}
+		sep = ','
+	}
+	b = append(b, ' ')
+	b = strconv.AppendInt(b, at, 10)
+	return append(b, '\n')
+}
+
+// escape backslash-escapes the characters in special.
+func escape(s, special string) string {
+	if !strings.ContainsAny(s, special) {
+		return s
+	}
+	var sb strings.Builder
+	for _, r := range s {
+		if strings.ContainsRune(special, r) {
+			sb.WriteByte('\\')
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
@@ -0,0 +1,141 @@
+package influx_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+	"github.com/adjaecent/unofficial-stockal-api/events"
+	"github.com/adjaecent/unofficial-stockal-api/events/influx"
+)
+
+// takenAt is when snapshot was taken, 1714593600000000000 in Unix nanoseconds.
+var takenAt = time.Date(2024, 5, 1, 20, 0, 0, 0, time.UTC)
+
+// snapshot returns a snapshot holding AAPL and a symbol that needs escaping.
+func snapshot() *stockal.Snapshot {
+	s := &stockal.Snapshot{TakenAt: takenAt}
+	s.Summary.PortfolioSummary.TotalCurrentValue = 2700
+	s.Summary.PortfolioSummary.TotalInvestmentAmount = 2500
+	s.Summary.AccountSummary.CashBalance = 300
+	s.Summary.AccountSummary.CashAvailableForTrade = 250.5
+	s.Holdings = []stockal.Holding{
+		{Symbol: "AAPL", TotalUnit: 10, Price: 190, PriorClose: 180, TotalInvestment: 1500},
+		{Symbol: "BRK B", TotalUnit: 2, Price: 400, PriorClose: 401, TotalInvestment: 800},
+	}
+	return s
+}
+
+func TestAppendLines(t *testing.T) {
+	tick := events.NewPriceTickEvent(stockal.Quote{Symbol: "AAPL", Price: 190.5, PriorClose: 187, Volume: 51234567,
+		Timestamp: 1700000000, Source: "yahoo"})
+	stockalTick := events.NewPriceTickEvent(stockal.Quote{Symbol: "VOO", Price: 430, PriorClose: math.NaN(), Timestamp: 1700000000})
+
+	tests := []struct {
+		name  string
+		event events.Event
+		want  string
+	}{
+		{
+			name:  "snapshot",
+			event: events.NewSnapshotEvent("me, myself", snapshot()),
+			want: `stockal_portfolio,account=me\,\ myself value=2700,invested=2500,gain=200,cash_balance=300,cash_available=250.5,holdings=2i 1714593600000000000
+stockal_holding,account=me\,\ myself,symbol=AAPL units=10,price=190,prior_close=180,value=1900,invested=1500,gain=400 1714593600000000000
+stockal_holding,account=me\,\ myself,symbol=BRK\ B units=2,price=400,prior_close=401,value=800,invested=800,gain=0 1714593600000000000
+`,
+		},
+		{
+			name:  "price tick",
+			event: tick,
+			want:  "stockal_price,source=yahoo,symbol=AAPL price=190.5,prior_close=187,volume=51234567i 1700000000000000000\n",
+		},
+		{
+			name:  "without a source or prior close",
+			event: stockalTick,
+			want:  "stockal_price,symbol=VOO price=430,volume=0i 1700000000000000000\n",
+		},
+		{
+			name:  "order",
+			event: events.NewOrderFilledEvent("me", stockal.Order{ID: "o1", Symbol: "AAPL", Status: stockal.OrderStatusFilled}),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(influx.AppendLines([]byte("# points\n"), tt.event)); got != "# points\n"+tt.want {
+				t.Errorf("AppendLines =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPublish(t *testing.T) {
+	var (
+		req  *http.Request
+		body string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		req, body = r, string(b)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	sink := influx.New(server.URL+"/", "stockal/autogen", influx.WithOrg("home"), influx.WithToken("s3cret"),
+		influx.WithHTTPClient(server.Client()))
+	defer sink.Close()
+	order := events.NewOrderFilledEvent("me", stockal.Order{ID: "o1", Symbol: "AAPL", Status: stockal.OrderStatusFilled})
+	err := sink.Publish(context.Background(), events.NewSnapshotEvent("me", snapshot()), order,
+		events.NewPriceTickEvent(stockal.Quote{Symbol: "AAPL", Price: 190.5, Timestamp: 1700000000}))
+	if err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+
+	if req.Method != http.MethodPost || req.URL.Path != "/api/v2/write" {
+		t.Errorf("request %s %s, want a write", req.Method, req.URL.Path)
+	}
+	if q := req.URL.Query(); q.Get("bucket") != "stockal/autogen" || q.Get("org") != "home" || q.Get("precision") != "ns" {
+		t.Errorf("query %v, want the bucket, org and nanosecond precision", q)
+	}
+	if auth := req.Header.Get("Authorization"); auth != "Token s3cret" {
+		t.Errorf("Authorization %q, want the token", auth)
+	}
+	if lines := strings.Split(strings.TrimSuffix(body, "\n"), "\n"); len(lines) != 4 || !strings.HasPrefix(lines[3], "stockal_price,symbol=AAPL ") {
+		t.Errorf("body\n%s\nwant the snapshot's three points and the tick's in one request", body)
+	}
+
+	// Events without points make no request
+	req = nil
+	if err := sink.Publish(context.Background(), order); err != nil || req != nil {
+		t.Errorf("Publish of an order = %v, request %v; want no request", err, req)
+	}
+}
+
+func TestPublishFail(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Has("org") || r.Header.Get("Authorization") != "" {
+			t.Errorf("request %s with %v, want no org or token", r.URL, r.Header)
+		}
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"code":"not found","message":"bucket \"stockal\" not found"}`+"\n")
+	}))
+	defer server.Close()
+
+	sink := influx.New(server.URL, "stockal", influx.WithHTTPClient(server.Client()))
+	err := sink.Publish(context.Background(), events.NewSnapshotEvent("me", snapshot()))
+	want := `influx: writing to stockal: status 404: {"code":"not found","message":"bucket \"stockal\" not found"}`
+	if err == nil || err.Error() != want {
+		t.Errorf("Publish = %v, want %s", err, want)
+	}
+
+	server.Close()
+	if err := sink.Publish(context.Background(), events.NewSnapshotEvent("me", snapshot())); err == nil || !strings.HasPrefix(err.Error(), "influx: ") {
+		t.Errorf("Publish without a server = %v, want an influx error", err)
+	}
+}
@@ -0,0 +1,180 @@
+// Package kafka publishes events to Kafka through a Kafka REST proxy, such as
+// the Confluent REST Proxy or Redpanda's HTTP Proxy, using the v2 produce
+// API. Going through the proxy keeps the library free of a native Kafka
+// client:
+//
+//	sink := kafka.New("http://localhost:8082", kafka.WithTopics(events.Topics{
+//		Snapshots:  "portfolio.snapshots",
+//		PriceTicks: "market.ticks",
+//		Orders:     "portfolio.orders",
+//	}))
+//	err := sink.Publish(ctx, events.NewSnapshotEvent("personal", snapshot))
+//
+// Records are JSON: the key is the event's Key, so events for one symbol or
+// order stay on one partition and in order, and the value is the Event
+// envelope.
+package kafka
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/adjaecent/unofficial-stockal-api/events"
+)
+
+// Content types of the REST proxy v2 API.
+const (
+	contentType = "application/vnd.kafka.json.v2+json"
+	accept      = "application/vnd.kafka.v2+json"
+)
+
+// ErrRejected is returned when the proxy rejects one or more records.
+var ErrRejected = errors.New("kafka: records rejected")
+
+// Sink publishes events to Kafka topics. It implements events.Sink.
+type Sink struct {
+	proxyURL           string
+	topics             events.Topics
+	httpClient         *http.Client
+	username, password string
+}
+
+var _ events.Sink = (*Sink)(nil)
+
+// Option configures a Sink.
+type Option func(*Sink)
+
+// WithTopics sets the topic for each kind of event (events.DefaultTopics by default).
+func WithTopics(topics events.Topics) Option {
+	return func(s *Sink) {
+		s.topics = topics
+	}
+}
+
+// WithHTTPClient sets the HTTP client used to reach the proxy.
+func WithHTTPClient(client *http.Client) Option {
+	return func(s *Sink) {
+		s.httpClient = client
+	}
+}
+
+// WithBasicAuth sets credentials for a proxy requiring HTTP basic auth.
+func WithBasicAuth(username, password string) Option {
+	return func(s *Sink) {
+		s.username, s.password = username, password
+	}
+}
+
+// New creates a Sink publishing through the REST proxy at proxyURL.
+func New(proxyURL string, options ...Option) *Sink {
+	s := &Sink{
+		proxyURL:   strings.TrimSuffix(proxyURL, "/"),
+		topics:     events.DefaultTopics,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+	for _, option := range options {
+		option(s)
+	}
+	return s
+}
+
+type record struct {
+	Key   string       `json:"key"`
+	Value events.Event `json:"value"`
+}
+
+type produceResponse struct {
+	Offsets []struct {
+		Partition int     `json:"partition"`
+		Offset    int64   `json:"offset"`
+		ErrorCode *int    `json:"error_code"`
+		Error     *string `json:"error"`
+	} `json:"offsets"`
+}
+
+type errorResponse struct {
+	ErrorCode int    `json:"error_code"`
+	Message   string `json:"message"`
+}
+
+// Publish sends the events, one request per topic, preserving their order
+// within each topic.
+func (s *Sink) Publish(ctx context.Context, evs ...events.Event) error {
+	var topics []string
+	batches := map[string][]record{}
+	for _, e := range evs {
+		topic := s.topics.For(e.Type)
+		if _, ok := batches[topic]; !ok {
+			topics = append(topics, topic)
+		}
+		batches[topic] = append(batches[topic], record{Key: e.Key, Value: e})
+	}
+	for _, topic := range topics {
+		if err := s.produce(ctx, topic, batches[topic]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Sink) produce(ctx context.Context, topic string, records []record) error {
+	body, err := json.Marshal(map[string]any{"records": records})
+	if err != nil {
+		return fmt.Errorf("kafka: failed to marshal records: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.proxyURL+"/topics/"+url.PathEscape(topic), bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("kafka: failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Accept", accept)
+	if s.username != "" {
+		req.SetBasicAuth(s.username, s.password)
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("kafka: %w", err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return fmt.Errorf("kafka: failed to read response: %w", err)
+	}
+	if resp.StatusCode/100 != 2 {
+		var e errorResponse
+		if json.Unmarshal(data, &e) == nil && e.Message != "" {
+			return fmt.Errorf("kafka: producing to %s: %s (error %d)", topic, e.Message, e.ErrorCode)
+		}
+		return fmt.Errorf("kafka: producing to %s: status %d", topic, resp.StatusCode)
+	}
+
+	var produced produceResponse
+	if err := json.Unmarshal(data, &produced); err != nil {
+		return fmt.Errorf("kafka: invalid response: %w", err)
+	}
+	var problems []string
+	for i, o := range produced.Offsets {
+		if o.Error != nil && *o.Error != "" {
+			problems = append(problems, fmt.Sprintf("record %d: %s", i, *o.Error))
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("%w by %s: %s", ErrRejected, topic, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// Close releases idle connections to the proxy.
+func (s *Sink) Close() error {
+	s.httpClient.CloseIdleConnections()
+	return nil
+}
@@ -0,0 +1,149 @@
+package kafka_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+	"github.com/adjaecent/unofficial-stockal-api/events"
+	"github.com/adjaecent/unofficial-stockal-api/events/kafka"
+)
+
+// produced is a request the fake proxy received.
+type produced struct {
+	topic   string
+	user    string
+	records []struct {
+		Key   string       `json:"key"`
+		Value events.Event `json:"value"`
+	}
+}
+
+// proxy returns a fake REST proxy that records produce requests and answers
+// with respond.
+func proxy(t *testing.T, respond func(w http.ResponseWriter)) (*httptest.Server, *[]produced) {
+	t.Helper()
+	var requests []produced
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		topic, ok := strings.CutPrefix(r.URL.Path, "/topics/")
+		if r.Method != http.MethodPost || !ok || r.Header.Get("Content-Type") != "application/vnd.kafka.json.v2+json" {
+			t.Errorf("unexpected request %s %s (%s)", r.Method, r.URL.Path, r.Header.Get("Content-Type"))
+		}
+		p := produced{topic: topic}
+		p.user, _, _ = r.BasicAuth()
+		body, _ := io.ReadAll(r.Body)
+		var batch struct {
+			Records json.RawMessage `json:"records"`
+		}
+		if err := json.Unmarshal(body, &batch); err != nil {
+			t.Errorf("invalid body %s: %v", body, err)
+		}
+		json.Unmarshal(batch.Records, &p.records)
+		requests = append(requests, p)
+		respond(w)
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func TestPublish(t *testing.T) {
+	server, requests := proxy(t, func(w http.ResponseWriter) {
+		io.WriteString(w, `{"offsets":[{"partition":0,"offset":1}]}`)
+	})
+	sink := kafka.New(server.URL+"/", kafka.WithBasicAuth("stockal", "secret"))
+	defer sink.Close()
+
+	o1 := events.NewOrderEvent("personal", stockal.Order{ID: "o1", Status: stockal.OrderStatusNew}, "")
+	tick := events.NewPriceTickEvent(stockal.Quote{Symbol: "AAPL", Price: 200})
+	o2 := events.NewOrderEvent("personal", stockal.Order{ID: "o1", Status: stockal.OrderStatusFilled}, "new")
+	if err := sink.Publish(context.Background(), o1, tick, o2); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+
+	var topics []string
+	for _, p := range *requests {
+		topics = append(topics, p.topic)
+		if p.user != "stockal" {
+			t.Errorf("producing to %s as %q, want stockal", p.topic, p.user)
+		}
+	}
+	if !slices.Equal(topics, []string{"stockal.orders", "stockal.price-ticks"}) {
+		t.Fatalf("produced to %q, want one request per topic in order of first event", topics)
+	}
+	orders := (*requests)[0].records
+	if len(orders) != 2 || orders[0].Value.ID != o1.ID || orders[1].Value.ID != o2.ID || orders[1].Key != "o1" {
+		t.Errorf("order records %+v, want o1's two events in order keyed by the order", orders)
+	}
+	if ticks := (*requests)[1].records; len(ticks) != 1 || ticks[0].Key != "AAPL" || ticks[0].Value.Type != events.TypePriceTick {
+		t.Errorf("tick records %+v, want AAPL's tick", ticks)
+	}
+}
+
+func TestPublishErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		err    string
+	}{
+		{
+			name:   "rejected record",
+			status: http.StatusOK,
+			body:   `{"offsets":[{"partition":null,"offset":null,"error_code":40403,"error":"schema not found"}]}`,
+			err:    "kafka: records rejected by stockal.alerts: record 0: schema not found",
+		},
+		{
+			name:   "proxy error",
+			status: http.StatusNotFound,
+			body:   `{"error_code":40401,"message":"Topic not found."}`,
+			err:    "kafka: producing to stockal.alerts: Topic not found. (error 40401)",
+		},
+		{
+			name:   "status",
+			status: http.StatusBadGateway,
+			body:   "bad gateway",
+			err:    "kafka: producing to stockal.alerts: status 502",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, _ := proxy(t, func(w http.ResponseWriter) {
+				w.WriteHeader(tt.status)
+				io.WriteString(w, tt.body)
+			})
+			sink := kafka.New(server.URL)
+			err := sink.Publish(context.Background(), events.NewAlertEvent("personal", events.Alert{Condition: "drift"}))
+			if err == nil || err.Error() != tt.err {
+				t.Errorf("Publish = %v, want %q", err, tt.err)
+			}
+		})
+	}
+
+	server, _ := proxy(t, func(w http.ResponseWriter) {
+		io.WriteString(w, `{"offsets":[{"error_code":1,"error":"too large"}]}`)
+	})
+	err := kafka.New(server.URL).Publish(context.Background(), events.NewAlertEvent("personal", events.Alert{}))
+	if !errors.Is(err, kafka.ErrRejected) {
+		t.Errorf("Publish = %v, want kafka.ErrRejected", err)
+	}
+}
+
+func TestTopics(t *testing.T) {
+	server, requests := proxy(t, func(w http.ResponseWriter) {
+		io.WriteString(w, `{"offsets":[]}`)
+	})
+	sink := kafka.New(server.URL, kafka.WithTopics(events.Topics{Orders: "portfolio/orders"}))
+	if err := sink.Publish(context.Background(), events.NewAlertEvent("personal", events.Alert{})); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	if len(*requests) != 1 || (*requests)[0].topic != "portfolio/orders" {
+		t.Errorf("produced to %+v, want the configured orders topic", *requests)
+	}
+}
@@ -0,0 +1,196 @@
+// Package nats publishes events to NATS subjects, optionally through
+// JetStream for persistence:
+//
+//	sink, err := nats.Connect("nats://localhost:4222", nats.WithJetStream())
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	defer sink.Close()
+//	err = sink.Publish(ctx, events.NewSnapshotEvent("personal", snapshot))
+//
+// Messages are the JSON Event envelope, with the event ID in the Nats-Msg-Id
+// header so JetStream discards duplicates, and the type and schema in the
+// Stockal-Event-Type and Stockal-Schema headers.
+package nats
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+
+	"github.com/adjaecent/unofficial-stockal-api/events"
+)
+
+// Headers set on every message, besides nats.MsgIdHdr.
+const (
+	HeaderEventType = "Stockal-Event-Type"
+	HeaderSchema    = "Stockal-Schema"
+)
+
+// Sink publishes events to NATS. It implements events.Sink.
+type Sink struct {
+	conn *nats.Conn
+	// owned is set when the sink opened conn and must close it
+	owned       bool
+	topics      events.Topics
+	keySubjects bool
+	useJS       bool
+	js          jetstream.JetStream
+	natsOptions []nats.Option
+}
+
+var _ events.Sink = (*Sink)(nil)
+
+// Option configures a Sink.
+type Option func(*Sink)
+
+// WithTopics sets the subject for each kind of event (events.DefaultTopics by default).
+func WithTopics(topics events.Topics) Option {
+	return func(s *Sink) {
+		s.topics = topics
+	}
+}
+
+// WithKeySubjects appends each event's key to its subject, so subscribers
+// can filter: "stockal.price-ticks.AAPL" or "stockal.orders.>".
+func WithKeySubjects() Option {
+	return func(s *Sink) {
+		s.keySubjects = true
+	}
+}
+
+// WithJetStream publishes through JetStream, waiting for each message to be
+// stored. A stream must capture the subjects.
+func WithJetStream() Option {
+	return func(s *Sink) {
+		s.useJS = true
+	}
+}
+
+// WithNATSOptions sets options for the connection opened by Connect, such as
+// nats.UserCredentials or nats.Token.
+func WithNATSOptions(options ...nats.Option) Option {
+	return func(s *Sink) {
+		s.natsOptions = append(s.natsOptions, options...)
+	}
+}
+
+// Connect connects to the NATS servers at url (comma-separated for a
+// cluster) and returns a Sink that closes the connection when closed.
+func Connect(url string, options ...Option) (*Sink, error) {
+	s := newSink(options)
+	conn, err := nats.Connect(url, append([]nats.Option{nats.Name("stockal-events")}, s.natsOptions...)...)
+	if err != nil {
+		return nil, err
+	}
+	s.conn, s.owned = conn, true
+	if err := s.init(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// New returns a Sink publishing on an existing connection, which the caller
+// keeps ownership of.
+func New(conn *nats.Conn, options ...Option) (*Sink, error) {
+	s := newSink(options)
+	s.conn = conn
+	if err := s.init(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func newSink(options []Option) *Sink {
+	s := &Sink{topics: events.DefaultTopics}
+	for _, option := range options {
+		option(s)
+	}
+	return s
+}
+
+func (s *Sink) init() error {
+	if !s.useJS {
+		return nil
+	}
+	js, err := jetstream.New(s.conn)
+	if err != nil {
+		return err
+	}
+	s.js = js
+	return nil
+}
+
+// Publish sends the events in order. Without JetStream it returns once the
+// server has received them; with JetStream, once each has been stored.
+func (s *Sink) Publish(ctx context.Context, evs ...events.Event) error {
+	for _, e := range evs {
+		data, err := json.Marshal(e)
+		if err != nil {
+			return fmt.Errorf("nats: failed to marshal event: %w", err)
+		}
+		msg := nats.NewMsg(s.subject(e))
+		msg.Data = data
+		msg.Header.Set(nats.MsgIdHdr, e.ID)
+		msg.Header.Set(HeaderEventType, string(e.Type))
+		msg.Header.Set(HeaderSchema, e.Schema)
+
+		if s.js != nil {
+			if _, err := s.js.PublishMsg(ctx, msg); err != nil {
+				return fmt.Errorf("nats: publishing to %s: %w", msg.Subject, err)
+			}
+			continue
+		}
+		if err := s.conn.PublishMsg(msg); err != nil {
+			return fmt.Errorf("nats: publishing to %s: %w", msg.Subject, err)
+		}
+	}
+	if s.js == nil {
+		return s.flush(ctx)
+	}
+	return nil
+}
+
+// flush waits for the server to receive the published messages. The
+// connection's FlushWithContext requires a deadline, so without one the
+// default flush timeout applies.
+func (s *Sink) flush(ctx context.Context) error {
+	if _, ok := ctx.Deadline(); !ok {
+		return s.conn.Flush()
+	}
+	return s.conn.FlushWithContext(ctx)
+}
+
+// subject returns the subject for an event.
+func (s *Sink) subject(e events.Event) string {
+	subject := s.topics.For(e.Type)
+	if s.keySubjects && e.Key != "" {
+		subject += "." + subjectToken(e.Key)
+	}
+	return subject
+}
+
+// subjectToken makes key usable as one subject token, replacing the
+// separator, wildcards and whitespace.
+func subjectToken(key string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '.', '*', '>', ' ', '\t', '\r', '\n':
+			return '_'
+		}
+		return r
+	}, key)
+}
+
+// Close drains the connection if the sink opened it.
+func (s *Sink) Close() error {
+	if !s.owned {
+		return nil
+	}
+	return s.conn.Drain()
+}
@@ -0,0 +1,219 @@
+package nats_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/textproto"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	natsgo "github.com/nats-io/nats.go"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+	"github.com/adjaecent/unofficial-stockal-api/events"
+	"github.com/adjaecent/unofficial-stockal-api/events/nats"
+)
+
+// message is a message published to the fake server.
+type message struct {
+	subject string
+	header  textproto.MIMEHeader
+	data    []byte
+}
+
+// server is a fake NATS server speaking enough of the client protocol for
+// publishing. It answers requests, such as JetStream publishes, with ack.
+type server struct {
+	ln  net.Listener
+	ack string
+
+	mu       sync.Mutex
+	messages []message
+}
+
+func newServer(t *testing.T, ack string) *server {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &server{ln: ln, ack: ack}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	t.Cleanup(func() { ln.Close() })
+	return s
+}
+
+func (s *server) url() string {
+	return "nats://" + s.ln.Addr().String()
+}
+
+func (s *server) published() []message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.messages)
+}
+
+func (s *server) serve(conn net.Conn) {
+	defer conn.Close()
+	fmt.Fprintf(conn, "INFO {\"server_id\":\"test\",\"version\":\"2.10.0\",\"proto\":1,\"headers\":true,\"max_payload\":1048576}\r\n")
+	r := bufio.NewReader(conn)
+	// subs holds each subscription's subject, by ID
+	subs := map[string]string{}
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		args := strings.Fields(line)
+		if len(args) == 0 {
+			continue
+		}
+		switch strings.ToUpper(args[0]) {
+		case "PING":
+			io.WriteString(conn, "PONG\r\n")
+		case "SUB":
+			subs[args[len(args)-1]] = args[1]
+		case "PUB", "HPUB":
+			m, reply, err := s.read(r, args)
+			if err != nil {
+				return
+			}
+			s.mu.Lock()
+			s.messages = append(s.messages, m)
+			s.mu.Unlock()
+			if reply == "" {
+				continue
+			}
+			for sid, subject := range subs {
+				if prefix, ok := strings.CutSuffix(subject, "*"); ok && strings.HasPrefix(reply, prefix) || subject == reply {
+					fmt.Fprintf(conn, "MSG %s %s %d\r\n%s\r\n", reply, sid, len(s.ack), s.ack)
+				}
+			}
+		}
+	}
+}
+
+// read reads the message of a PUB or HPUB command.
+func (s *server) read(r *bufio.Reader, args []string) (m message, reply string, err error) {
+	m.subject = args[1]
+	if len(args) == 4 && args[0] == "PUB" || len(args) == 5 {
+		reply = args[2]
+	}
+	size, _ := strconv.Atoi(args[len(args)-1])
+	payload := make([]byte, size+2)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return m, "", err
+	}
+	m.data = payload[:size]
+	if args[0] == "HPUB" {
+		headerSize, _ := strconv.Atoi(args[len(args)-2])
+		header, data := m.data[:headerSize], m.data[headerSize:]
+		_, fields, _ := strings.Cut(string(header), "\r\n")
+		m.header, err = textproto.NewReader(bufio.NewReader(strings.NewReader(fields))).ReadMIMEHeader()
+		if err != nil {
+			return m, "", err
+		}
+		m.data = data
+	}
+	return m, reply, nil
+}
+
+func TestPublish(t *testing.T) {
+	s := newServer(t, "")
+	sink, err := nats.Connect(s.url(), nats.WithKeySubjects())
+	if err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	defer sink.Close()
+
+	tick := events.NewPriceTickEvent(stockal.Quote{Symbol: "BRK.B", Price: 450})
+	order := events.NewOrderEvent("personal", stockal.Order{ID: "o1", Status: stockal.OrderStatusNew}, "")
+	if err := sink.Publish(context.Background(), tick, order); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+
+	// Publish flushed, so the server has both messages
+	messages := s.published()
+	if len(messages) != 2 {
+		t.Fatalf("server received %d messages, want 2", len(messages))
+	}
+	if got := []string{messages[0].subject, messages[1].subject}; !slices.Equal(got, []string{"stockal.price-ticks.BRK_B", "stockal.orders.o1"}) {
+		t.Errorf("published to %q, want the key appended as one token", got)
+	}
+	m := messages[0]
+	if m.header.Get(natsgo.MsgIdHdr) != tick.ID || m.header.Get(nats.HeaderEventType) != "price.tick" ||
+		m.header.Get(nats.HeaderSchema) != events.SchemaPriceTick {
+		t.Errorf("headers %v, want the event's ID, type and schema", m.header)
+	}
+	var e events.Event
+	if err := json.Unmarshal(m.data, &e); err != nil || e.ID != tick.ID || e.Key != "BRK.B" {
+		t.Errorf("message %s (%v), want the tick's envelope", m.data, err)
+	}
+}
+
+func TestNew(t *testing.T) {
+	s := newServer(t, "")
+	conn, err := natsgo.Connect(s.url())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	sink, err := nats.New(conn, nats.WithTopics(events.Topics{Orders: "portfolio"}))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := sink.Publish(context.Background(), events.NewAlertEvent("personal", events.Alert{Symbol: "AAPL"})); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	if messages := s.published(); len(messages) != 1 || messages[0].subject != "portfolio" {
+		t.Errorf("published %+v, want one message on the configured subject", messages)
+	}
+	if err := sink.Close(); err != nil || conn.IsClosed() {
+		t.Errorf("Close = %v, closed the caller's connection: %v", err, conn.IsClosed())
+	}
+}
+
+func TestJetStream(t *testing.T) {
+	tests := []struct {
+		name string
+		ack  string
+		err  bool
+	}{
+		{name: "stored", ack: `{"stream":"STOCKAL","seq":1}`},
+		{name: "failed", ack: `{"error":{"code":503,"err_code":10039,"description":"jetstream not enabled"}}`, err: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newServer(t, tt.ack)
+			sink, err := nats.Connect(s.url(), nats.WithJetStream())
+			if err != nil {
+				t.Fatalf("Connect: %v", err)
+			}
+			defer sink.Close()
+
+			err = sink.Publish(context.Background(), events.NewAlertEvent("personal", events.Alert{}))
+			if (err != nil) != tt.err {
+				t.Fatalf("Publish = %v, want error %v", err, tt.err)
+			}
+			if messages := s.published(); len(messages) == 0 || messages[0].subject != "stockal.alerts" {
+				t.Errorf("published %+v, want the alert on stockal.alerts", messages)
+			}
+		})
+	}
+}
@@ -0,0 +1,432 @@
+// Package notion keeps Notion databases up to date with the portfolio, for
+// finance tracking done in Notion:
+//
+//	sink := notion.New(os.Getenv("NOTION_TOKEN"),
+//		notion.WithHoldings("5c8f…", nil),
+//		notion.WithSummary("a31e…", notion.Mapping{"cash_available": ""}))
+//	err := sink.Publish(ctx, events.NewSnapshotEvent("personal", snapshot))
+//
+// Each snapshot event upserts one row per holding in the holdings database,
+// identified by its symbol, and one row per day in the summary database,
+// identified by its date. Rows of holdings that have been sold are left as
+// they were. Other events are ignored.
+//
+// A Mapping names the database property each field is written to. Holdings
+// rows have the fields symbol, company, units, price, prior_close, value,
+// invested, gain, updated and account; summary rows have date, value,
+// invested, gain, cash_balance, cash_available, holdings and account. The
+// property types are read from the database: numbers go to number
+// properties, and text to title, text or select properties. The date and
+// updated fields need date properties, or text ones.
+//
+// The integration must be connected to both databases. Notion allows about
+// three requests per second; rate-limited requests are retried after the
+// delay Notion asks for.
+package notion
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/adjaecent/unofficial-stockal-api/events"
+)
+
+// BaseURL is the Notion API used by default.
+const BaseURL = "https://api.notion.com"
+
+// apiVersion is the Notion-Version the requests are written against.
+const apiVersion = "2022-06-28"
+
+// maxAttempts bounds the tries of a rate-limited request.
+const maxAttempts = 4
+
+// Mapping maps row fields to the names of the database properties they are
+// written to. Fields that are not mapped, or mapped to "", are not written.
+type Mapping map[string]string
+
+// DefaultHoldingsMapping is the holdings database layout used unless
+// overridden: a "Symbol" title and a property for each number.
+var DefaultHoldingsMapping = Mapping{
+	"symbol":   "Symbol",
+	"company":  "Company",
+	"units":    "Units",
+	"price":    "Price",
+	"value":    "Value",
+	"invested": "Invested",
+	"gain":     "Gain",
+	"updated":  "Updated",
+}
+
+// DefaultSummaryMapping is the summary database layout used unless
+// overridden: a "Date" title and a property for each total.
+var DefaultSummaryMapping = Mapping{
+	"date":           "Date",
+	"value":          "Value",
+	"invested":       "Invested",
+	"gain":           "Gain",
+	"cash_balance":   "Cash Balance",
+	"cash_available": "Cash Available",
+}
+
+// Sink upserts snapshot rows into Notion databases. It implements events.Sink.
+type Sink struct {
+	token      string
+	baseURL    string
+	httpClient *http.Client
+
+	mu       sync.Mutex
+	holdings *database
+	summary  *database
+}
+
+var _ events.Sink = (*Sink)(nil)
+
+// database is a Notion database rows are upserted into.
+type database struct {
+	id      string
+	mapping Mapping
+	// key is the field identifying a row
+	key string
+	// fields are the fields of its rows
+	fields []string
+
+	// types holds the type of each mapped property, read on first use
+	types map[string]string
+	// pages caches the page ID of each row written, by key and account
+	pages map[string]string
+}
+
+// Option configures a Sink.
+type Option func(*Sink)
+
+// WithHoldings upserts a row per holding into the database with the given ID.
+// mapping overrides entries of DefaultHoldingsMapping and may be nil.
+func WithHoldings(databaseID string, mapping Mapping) Option {
+	return func(s *Sink) {
+		s.holdings = newDatabase(databaseID, "symbol", holdingFields, DefaultHoldingsMapping, mapping)
+	}
+}
+
+// WithSummary upserts a row per day into the database with the given ID.
+// mapping overrides entries of DefaultSummaryMapping and may be nil. Days
+// are dates in the local time zone.
+func WithSummary(databaseID string, mapping Mapping) Option {
+	return func(s *Sink) {
+		s.summary = newDatabase(databaseID, "date", summaryFields, DefaultSummaryMapping, mapping)
+	}
+}
+
+// WithHTTPClient sets the HTTP client used to reach Notion.
+func WithHTTPClient(client *http.Client) Option {
+	return func(s *Sink) {
+		s.httpClient = client
+	}
+}
+
+// WithBaseURL sets the API base URL, for tests.
+func WithBaseURL(baseURL string) Option {
+	return func(s *Sink) {
+		s.baseURL = strings.TrimSuffix(baseURL, "/")
+	}
+}
+
+// New creates a Sink authenticating with an internal integration token.
+// Without WithHoldings or WithSummary it writes nothing.
+func New(token string, options ...Option) *Sink {
+	s := &Sink{
+		token:      token,
+		baseURL:    BaseURL,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+	for _, option := range options {
+		option(s)
+	}
+	return s
+}
+
+// Row fields of each database.
+var (
+	holdingFields = []string{"symbol", "company", "units", "price", "prior_close", "value", "invested", "gain", "updated", "account"}
+	summaryFields = []string{"date", "value", "invested", "gain", "cash_balance", "cash_available", "holdings", "account"}
+)
+
+func newDatabase(id, key string, fields []string, defaults, overrides Mapping) *database {
+	mapping := maps.Clone(defaults)
+	for field, property := range overrides {
+		if property == "" {
+			delete(mapping, field)
+		} else {
+			mapping[field] = property
+		}
+	}
+	return &database{id: id, mapping: mapping, key: key, fields: fields, pages: make(map[string]string)}
+}
+
+// Publish upserts the rows of each snapshot event.
+func (s *Sink) Publish(ctx context.Context, evs ...events.Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, e := range evs {
+		snapshot, ok := e.Data.(*events.Snapshot)
+		if !ok {
+			continue
+		}
+		if s.summary != nil {
+			row := map[string]any{
+				"date":           snapshot.TakenAt.Local().Format(time.DateOnly),
+				"value":          snapshot.TotalValue,
+				"invested":       snapshot.TotalInvested,
+				"gain":           snapshot.TotalValue - snapshot.TotalInvested,
+				"cash_balance":   snapshot.CashBalance,
+				"cash_available": snapshot.CashAvailable,
+				"holdings":       len(snapshot.Holdings),
+				"account":        e.Account,
+			}
+			if err := s.upsert(ctx, s.summary, row); err != nil {
+				return err
+			}
+		}
+		if s.holdings != nil {
+			for _, h := range snapshot.Holdings {
+				row := map[string]any{
+					"symbol":      h.Symbol,
+					"company":     h.Company,
+					"units":       h.Units,
+					"price":       h.Price,
+					"prior_close": h.PriorClose,
+					"value":       h.Value,
+					"invested":    h.Invested,
+					"gain":        h.Value - h.Invested,
+					"updated":     snapshot.TakenAt.UTC().Format(time.RFC3339),
+					"account":     e.Account,
+				}
+				if err := s.upsert(ctx, s.holdings, row); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// Close releases idle connections to Notion.
+func (s *Sink) Close() error {
+	s.httpClient.CloseIdleConnections()
+	return nil
+}
+
+// upsert updates the row with the same key, and account if mapped, or
+// creates it.
+func (s *Sink) upsert(ctx context.Context, db *database, row map[string]any) error {
+	if err := s.loadSchema(ctx, db); err != nil {
+		return err
+	}
+	properties := make(map[string]any, len(db.mapping))
+	for field, property := range db.mapping {
+		v, err := propertyValue(db.types[property], row[field])
+		if err != nil {
+			return fmt.Errorf("notion: property %q: %w", property, err)
+		}
+		properties[property] = v
+	}
+
+	rowKey := fmt.Sprint(row[db.key], "\x00", row["account"])
+	pageID, ok := db.pages[rowKey]
+	if !ok {
+		var err error
+		if pageID, err = s.find(ctx, db, row); err != nil {
+			return err
+		}
+	}
+	var page struct {
+		ID string `json:"id"`
+	}
+	if pageID != "" {
+		if err := s.do(ctx, http.MethodPatch, "/v1/pages/"+pageID, map[string]any{"properties": properties}, &page); err != nil {
+			return err
+		}
+	} else {
+		body := map[string]any{"parent": map[string]string{"database_id": db.id}, "properties": properties}
+		if err := s.do(ctx, http.MethodPost, "/v1/pages", body, &page); err != nil {
+			return err
+		}
+	}
+	db.pages[rowKey] = page.ID
+	return nil
+}
+
+// loadSchema reads the types of the mapped properties.
+func (s *Sink) loadSchema(ctx context.Context, db *database) error {
+	if db.types != nil {
+		return nil
+	}
+	if _, ok := db.mapping[db.key]; !ok {
+		return fmt.Errorf("notion: the %s field must be mapped", db.key)
+	}
+	for field := range db.mapping {
+		if !slices.Contains(db.fields, field) {
+			return fmt.Errorf("notion: unknown field %q (want one of %s)", field, strings.Join(db.fields, ", "))
+		}
+	}
+	var schema struct {
+		Properties map[string]struct {
+			Type string `json:"type"`
+		} `json:"properties"`
+	}
+	if err := s.do(ctx, http.MethodGet, "/v1/databases/"+db.id, nil, &schema); err != nil {
+		return err
+	}
+	types := make(map[string]string, len(db.mapping))
+	for _, property := range db.mapping {
+		p, ok := schema.Properties[property]
+		if !ok {
+			return fmt.Errorf("notion: database %s has no property %q", db.id, property)
+		}
+		types[property] = p.Type
+	}
+	db.types = types
+	return nil
+}
+
+// find returns the ID of the page holding row, or "" if there is none.
+func (s *Sink) find(ctx context.Context, db *database, row map[string]any) (string, error) {
+	filters := []any{}
+	for _, field := range []string{db.key, "account"} {
+		property, ok := db.mapping[field]
+		if !ok {
+			continue
+		}
+		typ := db.types[property]
+		switch typ {
+		case "title", "rich_text", "select", "date":
+		default:
+			return "", fmt.Errorf("notion: property %q identifies rows and cannot have type %s", property, typ)
+		}
+		filters = append(filters, map[string]any{"property": property, typ: map[string]any{"equals": fmt.Sprint(row[field])}})
+	}
+	var result struct {
+		Results []struct {
+			ID string `json:"id"`
+		} `json:"results"`
+	}
+	query := map[string]any{"filter": map[string]any{"and": filters}, "page_size": 1}
+	if err := s.do(ctx, http.MethodPost, "/v1/databases/"+db.id+"/query", query, &result); err != nil {
+		return "", err
+	}
+	if len(result.Results) == 0 {
+		return "", nil
+	}
+	return result.Results[0].ID, nil
+}
+
+// propertyValue returns the property value object setting a property of
+// type typ to v, a string or a number.
+func propertyValue(typ string, v any) (any, error) {
+	var text string
+	switch v := v.(type) {
+	case string:
+		text = v
+	case float64:
+		text = strconv.FormatFloat(v, 'f', -1, 64)
+	case int:
+		text = strconv.Itoa(v)
+	}
+
+	switch typ {
+	case "number":
+		switch v := v.(type) {
+		case float64, int:
+			return map[string]any{"number": v}, nil
+		}
+		return nil, fmt.Errorf("cannot write text %q to a number", text)
+	case "title", "rich_text":
+		return map[string]any{typ: []any{map[string]any{"text": map[string]string{"content": text}}}}, nil
+	case "select":
+		if text == "" {
+			return map[string]any{"select": nil}, nil
+		}
+		return map[string]any{"select": map[string]string{"name": text}}, nil
+	case "date":
+		if _, ok := v.(string); !ok {
+			return nil, errors.New("cannot write a number to a date")
+		}
+		return map[string]any{"date": map[string]string{"start": text}}, nil
+	}
+	return nil, fmt.Errorf("unsupported property type %s", typ)
+}
+
+// apiError is the body of an error response.
+type apiError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// do sends a request with a JSON body, unless body is nil, and decodes the
+// response into dst. Rate-limited requests are retried.
+func (s *Sink) do(ctx context.Context, method, path string, body, dst any) error {
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return fmt.Errorf("notion: failed to marshal request: %w", err)
+		}
+	}
+
+	for attempt := 1; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, method, s.baseURL+path, bytes.NewReader(payload))
+		if err != nil {
+			return fmt.Errorf("notion: failed to create request: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+s.token)
+		req.Header.Set("Notion-Version", apiVersion)
+		if body != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+
+		resp, err := s.httpClient.Do(req)
+		if err != nil {
+			return fmt.Errorf("notion: %w", err)
+		}
+		data, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return fmt.Errorf("notion: %w", err)
+		}
+
+		if resp.StatusCode == http.StatusTooManyRequests && attempt < maxAttempts {
+			delay := time.Second
+			if secs, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+				delay = time.Duration(secs) * time.Second
+			}
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(delay):
+			}
+			continue
+		}
+		if resp.StatusCode/100 != 2 {
+			var apiErr apiError
+			if json.Unmarshal(data, &apiErr) == nil && apiErr.Message != "" {
+				return fmt.Errorf("notion: %s %s: %s: %s", method, path, apiErr.Code, apiErr.Message)
+			}
+			return fmt.Errorf("notion: %s %s: status %d", method, path, resp.StatusCode)
+		}
+		if err := json.Unmarshal(data, dst); err != nil {
+			return fmt.Errorf("notion: failed to decode response: %w", err)
+		}
+		return nil
+	}
+}
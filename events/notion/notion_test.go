@@ -0,0 +1,363 @@
+package notion_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+	"github.com/adjaecent/unofficial-stockal-api/events"
+	"github.com/adjaecent/unofficial-stockal-api/events/notion"
+)
+
+// request is a request received by the fake Notion API.
+type request struct {
+	method, path string
+	header       http.Header
+	body         map[string]any
+}
+
+// api is a fake Notion API serving the schemas of its databases. Queries
+// find the pages in existing, by the value their first filter looks for;
+// created pages are numbered.
+type api struct {
+	schemas  map[string]map[string]string
+	existing map[string]string
+
+	mu       sync.Mutex
+	requests []request
+}
+
+func newAPI(t *testing.T, schemas map[string]map[string]string, existing map[string]string) (*api, *httptest.Server) {
+	t.Helper()
+	a := &api{schemas: schemas, existing: existing}
+	server := httptest.NewServer(a)
+	t.Cleanup(server.Close)
+	return a, server
+}
+
+func (a *api) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var body map[string]any
+	json.NewDecoder(r.Body).Decode(&body)
+	a.mu.Lock()
+	a.requests = append(a.requests, request{method: r.Method, path: r.URL.Path, header: r.Header, body: body})
+	created := len(a.requests)
+	a.mu.Unlock()
+
+	id, query := strings.CutPrefix(r.URL.Path, "/v1/databases/")
+	id, query = strings.CutSuffix(id, "/query")
+	switch {
+	case r.Method == http.MethodGet && !query:
+		properties := map[string]any{}
+		for name, typ := range a.schemas[id] {
+			properties[name] = map[string]string{"type": typ}
+		}
+		json.NewEncoder(w).Encode(map[string]any{"object": "database", "id": id, "properties": properties})
+	case r.Method == http.MethodPost && query:
+		var filter struct {
+			Filter struct {
+				And []map[string]any `json:"and"`
+			} `json:"filter"`
+		}
+		b, _ := json.Marshal(body)
+		json.Unmarshal(b, &filter)
+		var value string
+		for key, condition := range filter.Filter.And[0] {
+			if key != "property" {
+				value = condition.(map[string]any)["equals"].(string)
+			}
+		}
+		results := []any{}
+		if page, ok := a.existing[value]; ok {
+			results = append(results, map[string]string{"object": "page", "id": page})
+		}
+		json.NewEncoder(w).Encode(map[string]any{"object": "list", "results": results})
+	case r.Method == http.MethodPost && r.URL.Path == "/v1/pages":
+		fmt.Fprintf(w, `{"object":"page","id":"page-%d"}`, created)
+	case r.Method == http.MethodPatch && strings.HasPrefix(r.URL.Path, "/v1/pages/"):
+		fmt.Fprintf(w, `{"object":"page","id":%q}`, strings.TrimPrefix(r.URL.Path, "/v1/pages/"))
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintf(w, `{"object":"error","code":"invalid_request_url","message":"%s %s"}`, r.Method, r.URL.Path)
+	}
+}
+
+// received returns the requests received so far.
+func (a *api) received() []request {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return append([]request(nil), a.requests...)
+}
+
+// reset forgets the requests received so far.
+func (a *api) reset() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.requests = nil
+}
+
+// lines returns the requests received so far as "METHOD path" lines.
+func (a *api) lines() string {
+	var lines []string
+	for _, r := range a.received() {
+		lines = append(lines, r.method+" "+r.path)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Database schemas as the default mappings expect them, with an account.
+var (
+	holdingsSchema = map[string]string{"Symbol": "title", "Company": "rich_text", "Units": "number", "Price": "number",
+		"Value": "number", "Invested": "number", "Gain": "number", "Updated": "date", "Account": "select"}
+	summarySchema = map[string]string{"Date": "title", "Value": "number", "Invested": "number", "Gain": "number",
+		"Cash Balance": "number", "Account": "select"}
+)
+
+var (
+	takenAt = time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	// day is the summary row of takenAt, dated in the local time zone
+	day = takenAt.Local().Format(time.DateOnly)
+)
+
+// snapshot returns a snapshot holding AAPL and MSFT.
+func snapshot() *stockal.Snapshot {
+	s := &stockal.Snapshot{TakenAt: takenAt}
+	s.Summary.PortfolioSummary.TotalCurrentValue = 3500
+	s.Summary.PortfolioSummary.TotalInvestmentAmount = 3250
+	s.Summary.AccountSummary.CashBalance = 300
+	s.Summary.AccountSummary.CashAvailableForTrade = 250
+	s.Holdings = []stockal.Holding{
+		{Symbol: "AAPL", Company: "Apple Inc.", TotalUnit: 10, Price: 190, PriorClose: 180, TotalInvestment: 1500},
+		{Symbol: "MSFT", Company: "Microsoft Corporation", TotalUnit: 4, Price: 400, TotalInvestment: 1750},
+	}
+	return s
+}
+
+func TestPublish(t *testing.T) {
+	a, server := newAPI(t, map[string]map[string]string{"hold": holdingsSchema, "sum": summarySchema},
+		map[string]string{"AAPL": "page-aapl"})
+	sink := notion.New("s3cret", notion.WithBaseURL(server.URL+"/"), notion.WithHTTPClient(server.Client()),
+		notion.WithHoldings("hold", notion.Mapping{"account": "Account"}),
+		notion.WithSummary("sum", notion.Mapping{"cash_available": "", "account": "Account"}))
+	defer sink.Close()
+
+	e := events.NewSnapshotEvent("me", snapshot())
+	order := events.NewOrderFilledEvent("me", stockal.Order{ID: "o1", Symbol: "AAPL", Status: stockal.OrderStatusFilled})
+	if err := sink.Publish(context.Background(), e, order); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	want := `GET /v1/databases/sum
+POST /v1/databases/sum/query
+POST /v1/pages
+GET /v1/databases/hold
+POST /v1/databases/hold/query
+PATCH /v1/pages/page-aapl
+POST /v1/databases/hold/query
+POST /v1/pages`
+	if got := a.lines(); got != want {
+		t.Fatalf("requests\n%s\nwant\n%s", got, want)
+	}
+
+	requests := a.received()
+	for _, r := range requests {
+		if r.header.Get("Authorization") != "Bearer s3cret" || r.header.Get("Notion-Version") != "2022-06-28" {
+			t.Errorf("%s %s sent %v, want the token and API version", r.method, r.path, r.header)
+		}
+		if ct := r.header.Get("Content-Type"); (r.method == http.MethodGet) != (ct == "") {
+			t.Errorf("%s %s has Content-Type %q", r.method, r.path, ct)
+		}
+	}
+
+	bodies := []struct {
+		i    int
+		want string
+	}{
+		{1, `{"filter":{"and":[{"property":"Date","title":{"equals":"` + day + `"}},{"property":"Account","select":{"equals":"me"}}]},"page_size":1}`},
+		{2, `{"parent":{"database_id":"sum"},"properties":{"Account":{"select":{"name":"me"}},"Cash Balance":{"number":300},` +
+			`"Date":{"title":[{"text":{"content":"` + day + `"}}]},"Gain":{"number":250},"Invested":{"number":3250},"Value":{"number":3500}}}`},
+		{5, `{"properties":{"Account":{"select":{"name":"me"}},"Company":{"rich_text":[{"text":{"content":"Apple Inc."}}]},` +
+			`"Gain":{"number":400},"Invested":{"number":1500},"Price":{"number":190},"Symbol":{"title":[{"text":{"content":"AAPL"}}]},` +
+			`"Units":{"number":10},"Updated":{"date":{"start":"2024-05-01T12:00:00Z"}},"Value":{"number":1900}}}`},
+	}
+	for _, b := range bodies {
+		if got, _ := json.Marshal(requests[b.i].body); string(got) != b.want {
+			t.Errorf("%s body\n%s\nwant\n%s", requests[b.i].path, got, b.want)
+		}
+	}
+
+	// Rows written are updated without looking them up again
+	a.reset()
+	if err := sink.Publish(context.Background(), e); err != nil {
+		t.Fatalf("Publish again: %v", err)
+	}
+	want = `PATCH /v1/pages/page-3
+PATCH /v1/pages/page-aapl
+PATCH /v1/pages/page-8`
+	if got := a.lines(); got != want {
+		t.Errorf("requests publishing again\n%s\nwant\n%s", got, want)
+	}
+
+	// Another account's rows are looked up separately
+	a.reset()
+	if err := sink.Publish(context.Background(), events.NewSnapshotEvent("them", snapshot())); err != nil {
+		t.Fatalf("Publish for another account: %v", err)
+	}
+	if got := a.lines(); !strings.HasPrefix(got, "POST /v1/databases/sum/query\nPOST /v1/pages\n") {
+		t.Errorf("requests for another account\n%s\nwant new rows", got)
+	}
+}
+
+func TestPublishFail(t *testing.T) {
+	missingGain := map[string]string{}
+	for name, typ := range holdingsSchema {
+		if name != "Gain" {
+			missingGain[name] = typ
+		}
+	}
+	textUnits := map[string]string{}
+	for name, typ := range holdingsSchema {
+		textUnits[name] = typ
+	}
+	textUnits["Symbol"] = "number"
+
+	tests := []struct {
+		name     string
+		schema   map[string]string
+		mapping  notion.Mapping
+		want     string
+		requests int
+	}{
+		{name: "key not mapped", schema: holdingsSchema, mapping: notion.Mapping{"symbol": ""},
+			want: "notion: the symbol field must be mapped"},
+		{name: "unknown field", schema: holdingsSchema, mapping: notion.Mapping{"colour": "Colour"},
+			want: `notion: unknown field "colour" (want one of symbol, company, units, price, prior_close, value, invested, gain, updated, account)`},
+		{name: "missing property", schema: missingGain,
+			want: `notion: database hold has no property "Gain"`, requests: 1},
+		{name: "wrong type", schema: textUnits,
+			want: `notion: property "Symbol": cannot write text "AAPL" to a number`, requests: 1},
+		{name: "date to a number", schema: holdingsSchema, mapping: notion.Mapping{"units": "Updated"},
+			want: `notion: property "Updated": cannot write a number to a date`, requests: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, server := newAPI(t, map[string]map[string]string{"hold": tt.schema}, nil)
+			sink := notion.New("s3cret", notion.WithBaseURL(server.URL), notion.WithHTTPClient(server.Client()),
+				notion.WithHoldings("hold", tt.mapping))
+			err := sink.Publish(context.Background(), events.NewSnapshotEvent("me", snapshot()))
+			if err == nil || err.Error() != tt.want {
+				t.Errorf("Publish = %v, want %s", err, tt.want)
+			}
+			if len(a.received()) != tt.requests {
+				t.Errorf("requests\n%s\nwant %d", a.lines(), tt.requests)
+			}
+		})
+	}
+}
+
+func TestPublishAPIError(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		want   string
+	}{
+		{name: "notion error", status: http.StatusNotFound,
+			body: `{"object":"error","status":404,"code":"object_not_found","message":"Could not find database with ID: hold."}`,
+			want: "notion: GET /v1/databases/hold: object_not_found: Could not find database with ID: hold."},
+		{name: "other error", status: http.StatusBadGateway, body: "<html>Bad Gateway</html>",
+			want: "notion: GET /v1/databases/hold: status 502"},
+		{name: "malformed", status: http.StatusOK, body: "{",
+			want: "notion: failed to decode response: unexpected end of JSON input"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				fmt.Fprint(w, tt.body)
+			}))
+			defer server.Close()
+
+			sink := notion.New("s3cret", notion.WithBaseURL(server.URL), notion.WithHTTPClient(server.Client()),
+				notion.WithHoldings("hold", nil))
+			err := sink.Publish(context.Background(), events.NewSnapshotEvent("me", snapshot()))
+			if err == nil || err.Error() != tt.want {
+				t.Errorf("Publish = %v, want %s", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestPublishRateLimited(t *testing.T) {
+	var (
+		mu                sync.Mutex
+		attempts, limited = 0, 2
+	)
+	// count returns the attempts made since it was last called, and limits
+	// the next n.
+	count := func(n int) int {
+		mu.Lock()
+		defer mu.Unlock()
+		made := attempts
+		attempts, limited = 0, n
+		return made
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		attempts++
+		limit := attempts <= limited
+		mu.Unlock()
+		if limit {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			fmt.Fprint(w, `{"object":"error","status":429,"code":"rate_limited","message":"Rate limited"}`)
+			return
+		}
+		fmt.Fprint(w, `{"object":"database","properties":{}}`)
+	}))
+	defer server.Close()
+
+	sink := notion.New("s3cret", notion.WithBaseURL(server.URL), notion.WithHTTPClient(server.Client()),
+		notion.WithHoldings("hold", nil))
+	// The schema is read after two retries, and lacks the properties
+	err := sink.Publish(context.Background(), events.NewSnapshotEvent("me", snapshot()))
+	if made := count(10); err == nil || !strings.HasPrefix(err.Error(), "notion: database hold has no property") || made != 3 {
+		t.Errorf("Publish = %v after %d attempts, want the schema read on the third", err, made)
+	}
+
+	// Requests still limited after four attempts fail
+	err = sink.Publish(context.Background(), events.NewSnapshotEvent("me", snapshot()))
+	if made := count(0); err == nil || err.Error() != "notion: GET /v1/databases/hold: rate_limited: Rate limited" || made != 4 {
+		t.Errorf("Publish = %v after %d attempts, want the rate limit after 4", err, made)
+	}
+}
+
+func TestPublishRateLimitedCancel(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "60")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	sink := notion.New("s3cret", notion.WithBaseURL(server.URL), notion.WithHTTPClient(server.Client()),
+		notion.WithSummary("sum", nil))
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := sink.Publish(ctx, events.NewSnapshotEvent("me", snapshot())); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Publish = %v, want the context's error while waiting", err)
+	}
+}
+
+func TestPublishNothing(t *testing.T) {
+	a, server := newAPI(t, nil, nil)
+	sink := notion.New("s3cret", notion.WithBaseURL(server.URL), notion.WithHTTPClient(server.Client()))
+	if err := sink.Publish(context.Background(), events.NewSnapshotEvent("me", snapshot())); err != nil || len(a.received()) != 0 {
+		t.Errorf("Publish without databases = %v, requests\n%s\nwant none", err, a.lines())
+	}
+}
@@ -0,0 +1,31 @@
+package events
+
+import (
+	"embed"
+	"strings"
+)
+
+//go:embed schemas/*.json
+var schemas embed.FS
+
+// EnvelopeSchema names the schema of the Event envelope itself.
+const EnvelopeSchema = "stockal.event"
+
+// SchemaJSON returns the JSON Schema for a schema name such as
+// SchemaPriceTick or EnvelopeSchema, for registering with a schema registry
+// or validating consumers. ok is false for unknown names.
+func SchemaJSON(name string) (schema []byte, ok bool) {
+	file := "event.json"
+	if name != EnvelopeSchema {
+		base, version, found := strings.Cut(strings.TrimPrefix(name, "stockal."), "/")
+		if !found {
+			return nil, false
+		}
+		file = base + "." + version + ".json"
+	}
+	data, err := schemas.ReadFile("schemas/" + file)
+	if err != nil {
+		return nil, false
+	}
+	return data, true
+}
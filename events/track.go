@@ -0,0 +1,122 @@
+package events
+
+import (
+	"slices"
+	"strconv"
+	"sync"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+)
+
+// OrderTracker turns successive lists of an account's orders into order
+// lifecycle events. It is safe for concurrent use.
+type OrderTracker struct {
+	account string
+
+	mu sync.Mutex
+	// states holds each order's status and filled quantity, by order ID
+	states map[string]orderState
+	primed bool
+}
+
+type orderState struct {
+	status string
+	filled float64
+}
+
+// NewOrderTracker creates an OrderTracker for account's orders.
+func NewOrderTracker(account string) *OrderTracker {
+	return &OrderTracker{account: account, states: map[string]orderState{}}
+}
+
+// Update compares orders with those from the previous call and returns an
+// event for each order that is new or has changed status or fill. The first
+// call only records the orders, so existing orders are not reported as new.
+func (t *OrderTracker) Update(orders []stockal.Order) []Event {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var events []Event
+	for _, o := range orders {
+		state := orderState{status: string(o.Status), filled: o.FilledQuantity}
+		prev, seen := t.states[o.ID]
+		t.states[o.ID] = state
+		switch {
+		case !t.primed:
+		case !seen:
+			events = append(events, NewOrderEvent(t.account, o, ""))
+		case prev != state:
+			events = append(events, NewOrderEvent(t.account, o, prev.status))
+		}
+	}
+	t.primed = true
+	return events
+}
+
+// PriceTracker turns successive quotes into price ticks, skipping quotes
+// that have not changed. It is safe for concurrent use.
+type PriceTracker struct {
+	mu   sync.Mutex
+	last map[string]string
+}
+
+// NewPriceTracker creates a PriceTracker.
+func NewPriceTracker() *PriceTracker {
+	return &PriceTracker{last: map[string]string{}}
+}
+
+// Update returns a price tick for each quote whose price, volume or trade
+// time differs from the previous quote for its symbol.
+func (t *PriceTracker) Update(quotes []stockal.Quote) []Event {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var events []Event
+	for _, q := range quotes {
+		state := strconv.FormatFloat(q.Price, 'g', -1, 64) + "/" + strconv.FormatInt(q.Volume, 10) + "/" + strconv.FormatInt(q.Timestamp, 10)
+		if t.last[q.Symbol] == state {
+			continue
+		}
+		t.last[q.Symbol] = state
+		events = append(events, NewPriceTickEvent(q))
+	}
+	return events
+}
+
+// TransactionTracker turns successive lists of an account's transactions
+// into transaction events. It is safe for concurrent use.
+type TransactionTracker struct {
+	account string
+
+	mu     sync.Mutex
+	seen   map[string]bool
+	primed bool
+}
+
+// NewTransactionTracker creates a TransactionTracker for account's
+// transactions.
+func NewTransactionTracker(account string) *TransactionTracker {
+	return &TransactionTracker{account: account, seen: map[string]bool{}}
+}
+
+// Update returns an event for each transaction not in an earlier call, oldest
+// first. The first call only records the transactions, so past ones are not
+// reported.
+func (t *TransactionTracker) Update(transactions []stockal.Transaction) []Event {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var events []Event
+	// Transactions are listed newest first
+	for _, tx := range slices.Backward(transactions) {
+		if t.seen[tx.ID] {
+			continue
+		}
+		t.seen[tx.ID] = true
+		if t.primed {
+			events = append(events, NewTransactionEvent(t.account, tx))
+		}
+	}
+	t.primed = true
+	return events
+}
@@ -25,7 +25,7 @@ func main() {
 	password := "P93WmwB7P7658uy"
 
 	// Attempt to login
-	resp, err := client.Login(ctx, username, password)
+	resp, err := client.Auth().Login(ctx, username, password)
 	if err != nil {
 		log.Fatalf("Login failed: %v", err)
 	}
@@ -38,14 +38,15 @@ func main() {
 
 	// Get account summary
 	fmt.Printf("\nFetching account summary...\n")
-	summary, err := client.GetAccountSummary(ctx)
+	summary, err := client.Account().Summary(ctx)
 	if err != nil {
 		log.Fatalf("Failed to get account summary: %v", err)
 	}
 
 	fmt.Printf("Account Summary Response Code: %d\n", summary.Code)
 	fmt.Printf("Message: %s\n", summary.Message)
-	fmt.Printf("UTC Time: %s\n", summary.Data.UTCTime)
+	asOf := summary.Data.AsOf()
+	fmt.Printf("As of: %s (%s in New York)\n", asOf.Local().Format(time.RFC1123), stockal.ExchangeTime(asOf).Format(time.Kitchen))
 
 	// Account details
 	fmt.Printf("\n--- Account Details ---\n")
@@ -59,7 +60,7 @@ func main() {
 	fmt.Printf("\n--- Portfolio Summary ---\n")
 	fmt.Printf("Total Current Value: $%.2f\n", summary.Data.PortfolioSummary.TotalCurrentValue)
 	fmt.Printf("Total Investment Amount: $%.2f\n", summary.Data.PortfolioSummary.TotalInvestmentAmount)
-	fmt.Printf("Total Gain/Loss: $%.2f\n", summary.Data.PortfolioSummary.TotalCurrentValue-summary.Data.PortfolioSummary.TotalInvestmentAmount)
+	fmt.Printf("Total Gain/Loss: $%.2f\n", summary.Data.PortfolioSummary.TotalGain())
 
 	// Stock portfolio
 	fmt.Printf("Stock Portfolio - Current: $%.2f, Investment: $%.2f\n",
@@ -73,7 +74,7 @@ func main() {
 
 	// Get portfolio details
 	fmt.Printf("\nFetching portfolio details...\n")
-	portfolio, err := client.GetPortfolioDetail(ctx)
+	portfolio, err := client.Portfolio().Detail(ctx)
 	if err != nil {
 		log.Fatalf("Failed to get portfolio details: %v", err)
 	}
@@ -86,14 +87,10 @@ func main() {
 	// Display holdings
 	fmt.Printf("\n--- Holdings ---\n")
 	for i, holding := range portfolio.Data.Holdings {
-		currentValue := holding.TotalUnit * holding.Price
-		gainLoss := currentValue - holding.TotalInvestment
-		gainLossPercent := (gainLoss / holding.TotalInvestment) * 100
-
 		fmt.Printf("%d. %s (%s)\n", i+1, holding.Company, holding.Symbol)
-		fmt.Printf("   Units: %.4f @ $%.2f = $%.2f\n", holding.TotalUnit, holding.Price, currentValue)
+		fmt.Printf("   Units: %.4f @ $%.2f = $%.2f\n", holding.TotalUnit, holding.Price, holding.CurrentValue())
 		fmt.Printf("   Investment: $%.2f\n", holding.TotalInvestment)
-		fmt.Printf("   Gain/Loss: $%.2f (%.2f%%)\n", gainLoss, gainLossPercent)
+		fmt.Printf("   Gain/Loss: $%.2f (%.2f%%)\n", holding.UnrealizedGain(), holding.GainPercent())
 		fmt.Printf("   Category: %s | Status: %s\n", holding.Category, holding.Status)
 		if holding.SellOnly {
 			fmt.Printf("   ** SELL ONLY **\n")
@@ -0,0 +1,87 @@
+//go:build js && wasm
+
+// Command wasm exposes the client to JavaScript when compiled to WebAssembly:
+//
+//	GOOS=js GOARCH=wasm go build -o main.wasm ./example/wasm
+//	cp "$(go env GOROOT)/lib/wasm/wasm_exec.js" .
+//
+// Load it from a page served behind a reverse proxy that forwards /v2 and /v3
+// to the Stockal API:
+//
+//	const go = new Go();
+//	const { instance } = await WebAssembly.instantiateStreaming(fetch("main.wasm"), go.importObject);
+//	go.run(instance);
+//	await stockal.login(username, password);
+//	const portfolio = JSON.parse(await stockal.portfolio());
+//
+// Each function returns a Promise resolving to the response as JSON.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"syscall/js"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+)
+
+func main() {
+	// Same-origin base URL: the page's server proxies to the API.
+	baseURL := js.Global().Get("location").Get("origin").String()
+	client := stockal.NewClient(
+		stockal.WithBaseURL(baseURL),
+		stockal.WithTokenStore(stockal.NewLocalStorageTokenStore("stockal-session")),
+	)
+
+	api := map[string]any{
+		"login": promise(func(ctx context.Context, args []js.Value) (any, error) {
+			return client.Auth().Login(ctx, args[0].String(), args[1].String())
+		}),
+		"summary": promise(func(ctx context.Context, args []js.Value) (any, error) {
+			return client.Account().Summary(ctx)
+		}),
+		"portfolio": promise(func(ctx context.Context, args []js.Value) (any, error) {
+			return client.Portfolio().Detail(ctx)
+		}),
+		"quotes": promise(func(ctx context.Context, args []js.Value) (any, error) {
+			symbols := make([]string, len(args))
+			for i, arg := range args {
+				symbols[i] = arg.String()
+			}
+			return client.GetQuotes(ctx, symbols...)
+		}),
+		"logout": promise(func(ctx context.Context, args []js.Value) (any, error) {
+			return nil, client.Auth().Logout(ctx)
+		}),
+	}
+	js.Global().Set("stockal", js.ValueOf(api))
+
+	// Keep the functions callable.
+	select {}
+}
+
+// promise wraps f as a JavaScript function returning a Promise. Go code must
+// not block the event loop, so f runs in its own goroutine.
+func promise(f func(ctx context.Context, args []js.Value) (any, error)) js.Func {
+	return js.FuncOf(func(this js.Value, args []js.Value) any {
+		executor := js.FuncOf(func(this js.Value, handlers []js.Value) any {
+			resolve, reject := handlers[0], handlers[1]
+			go func() {
+				result, err := f(context.Background(), args)
+				if err != nil {
+					reject.Invoke(js.Global().Get("Error").New(err.Error()))
+					return
+				}
+				data, err := json.Marshal(result)
+				if err != nil {
+					reject.Invoke(js.Global().Get("Error").New(err.Error()))
+					return
+				}
+				resolve.Invoke(string(data))
+			}()
+			return nil
+		})
+		defer executor.Release()
+		return js.Global().Get("Promise").New(executor)
+	})
+}
@@ -26,13 +26,13 @@ func ExampleNewClient() {
 	// Custom client created successfully
 }
 
-// ExampleClient_Login demonstrates user authentication with the Stockal API.
-func ExampleClient_Login() {
+// ExampleAuthService_Login demonstrates user authentication with the Stockal API.
+func ExampleAuthService_Login() {
 	client := stockal.NewClient()
 	ctx := context.Background()
 
 	// Note: Use actual credentials in real usage
-	resp, err := client.Login(ctx, "your_username", "your_password")
+	resp, err := client.Auth().Login(ctx, "your_username", "your_password")
 	if err != nil {
 		log.Printf("Login failed: %v", err)
 		return
@@ -42,20 +42,20 @@ func ExampleClient_Login() {
 	fmt.Printf("Token expires: %s\n", resp.Data.ExpiryAccessToken)
 }
 
-// ExampleClient_GetAccountSummary demonstrates fetching account summary information.
-func ExampleClient_GetAccountSummary() {
+// ExampleAccountService_Summary demonstrates fetching account summary information.
+func ExampleAccountService_Summary() {
 	client := stockal.NewClient()
 	ctx := context.Background()
 
 	// Login first (credentials would be real in actual usage)
-	_, err := client.Login(ctx, "your_username", "your_password")
+	_, err := client.Auth().Login(ctx, "your_username", "your_password")
 	if err != nil {
 		log.Printf("Login failed: %v", err)
 		return
 	}
 
 	// Get account summary
-	summary, err := client.GetAccountSummary(ctx)
+	summary, err := client.Account().Summary(ctx)
 	if err != nil {
 		log.Printf("Failed to get account summary: %v", err)
 		return
@@ -65,25 +65,23 @@ func ExampleClient_GetAccountSummary() {
 	fmt.Printf("Total portfolio value: $%.2f\n", summary.Data.PortfolioSummary.TotalCurrentValue)
 	fmt.Printf("Total investment: $%.2f\n", summary.Data.PortfolioSummary.TotalInvestmentAmount)
 
-	// Calculate total gain/loss
-	gainLoss := summary.Data.PortfolioSummary.TotalCurrentValue - summary.Data.PortfolioSummary.TotalInvestmentAmount
-	fmt.Printf("Total gain/loss: $%.2f\n", gainLoss)
+	fmt.Printf("Total gain/loss: $%.2f\n", summary.Data.PortfolioSummary.TotalGain())
 }
 
-// ExampleClient_GetPortfolioDetail demonstrates fetching detailed portfolio information.
-func ExampleClient_GetPortfolioDetail() {
+// ExamplePortfolioService_Detail demonstrates fetching detailed portfolio information.
+func ExamplePortfolioService_Detail() {
 	client := stockal.NewClient()
 	ctx := context.Background()
 
 	// Login first (credentials would be real in actual usage)
-	_, err := client.Login(ctx, "your_username", "your_password")
+	_, err := client.Auth().Login(ctx, "your_username", "your_password")
 	if err != nil {
 		log.Printf("Login failed: %v", err)
 		return
 	}
 
 	// Get portfolio details
-	portfolio, err := client.GetPortfolioDetail(ctx)
+	portfolio, err := client.Portfolio().Detail(ctx)
 	if err != nil {
 		log.Printf("Failed to get portfolio details: %v", err)
 		return
@@ -98,14 +96,10 @@ func ExampleClient_GetPortfolioDetail() {
 			break
 		}
 
-		currentValue := holding.TotalUnit * holding.Price
-		gainLoss := currentValue - holding.TotalInvestment
-		gainLossPercent := (gainLoss / holding.TotalInvestment) * 100
-
 		fmt.Printf("\n%s (%s):\n", holding.Company, holding.Symbol)
-		fmt.Printf("  Units: %.4f @ $%.2f = $%.2f\n", holding.TotalUnit, holding.Price, currentValue)
+		fmt.Printf("  Units: %.4f @ $%.2f = $%.2f\n", holding.TotalUnit, holding.Price, holding.CurrentValue())
 		fmt.Printf("  Investment: $%.2f\n", holding.TotalInvestment)
-		fmt.Printf("  Gain/Loss: $%.2f (%.2f%%)\n", gainLoss, gainLossPercent)
+		fmt.Printf("  Gain/Loss: $%.2f (%.2f%%)\n", holding.UnrealizedGain(), holding.GainPercent())
 
 		if holding.SellOnly {
 			fmt.Printf("  Status: SELL ONLY\n")
@@ -123,14 +117,14 @@ func Example() {
 	ctx := context.Background()
 
 	// Step 1: Login
-	resp, err := client.Login(ctx, "your_username", "your_password")
+	resp, err := client.Auth().Login(ctx, "your_username", "your_password")
 	if err != nil {
 		log.Fatal("Login failed:", err)
 	}
 	fmt.Printf("✓ Login successful (token expires: %s)\n", resp.Data.ExpiryAccessToken)
 
 	// Step 2: Get account summary
-	summary, err := client.GetAccountSummary(ctx)
+	summary, err := client.Account().Summary(ctx)
 	if err != nil {
 		log.Fatal("Failed to get account summary:", err)
 	}
@@ -140,7 +134,7 @@ func Example() {
 	fmt.Printf("  Portfolio value: $%.2f\n", summary.Data.PortfolioSummary.TotalCurrentValue)
 
 	// Step 3: Get portfolio details
-	portfolio, err := client.GetPortfolioDetail(ctx)
+	portfolio, err := client.Portfolio().Detail(ctx)
 	if err != nil {
 		log.Fatal("Failed to get portfolio details:", err)
 	}
@@ -154,8 +148,7 @@ func Example() {
 
 	for _, holding := range portfolio.Data.Holdings {
 		if holding.TotalInvestment > 0 {
-			currentValue := holding.TotalUnit * holding.Price
-			performance := ((currentValue - holding.TotalInvestment) / holding.TotalInvestment) * 100
+			performance := holding.GainPercent()
 
 			if performance > bestPerformance {
 				bestPerformance = performance
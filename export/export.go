@@ -0,0 +1,231 @@
+// Package export writes Stockal activity in the file formats personal finance
+// software imports: OFX for GnuCash, Moneydance and most others, and QIF for
+// Quicken and older tools. WriteICS writes upcoming dividend and earnings
+// dates as an iCalendar feed.
+//
+// # Basic Usage
+//
+//	snapshot, err := stockal.TakeSnapshot(ctx, client)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	transactions, err := stockal.AllTransactions(ctx, client, stockal.TransactionOptions{})
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	statement, err := export.NewStatement("stockal", snapshot, transactions)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	if err := export.WriteOFX(os.Stdout, statement); err != nil {
+//		log.Fatal(err)
+//	}
+//
+// Purchases and sales are exported as trades; dividends, deposits,
+// withdrawals, fees and taxes as cash transactions. Both keep their
+// transaction IDs, so that importing a statement again skips them.
+//
+// # Calendars
+//
+// Stockal has no corporate event calendar, so events come from a
+// stockal.CorporateEventProvider such as the Alpha Vantage provider:
+//
+//	provider := alphavantage.New(os.Getenv("ALPHAVANTAGE_API_KEY"))
+//	events, err := provider.CorporateEvents(ctx, from, from.AddDate(0, 3, 0), "AAPL", "MSFT")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	if err := export.WriteICS(file, "Stockal holdings", events); err != nil {
+//		log.Fatal(err)
+//	}
+package export
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+)
+
+// Trade is a purchase or sale of shares.
+type Trade struct {
+	// ID uniquely identifies the trade, so that importers skip duplicates
+	ID string
+	// Time is when the trade was executed
+	Time time.Time
+	// Symbol is the stock symbol
+	Symbol string
+	// Side is buy or sell
+	Side stockal.OrderSide
+	// Quantity is the number of shares traded
+	Quantity float64
+	// Price is the price per share
+	Price float64
+}
+
+// Total returns the value of the trade.
+func (t Trade) Total() float64 {
+	return t.Quantity * t.Price
+}
+
+// CashTransaction is a transaction that moves cash without trading shares:
+// a dividend, deposit, withdrawal, fee or tax.
+type CashTransaction struct {
+	// ID uniquely identifies the transaction, so that importers skip duplicates
+	ID string
+	// Time is when the transaction happened
+	Time time.Time
+	// Type is the kind of transaction
+	Type stockal.TransactionType
+	// Symbol is the stock paying a dividend; empty for other transactions
+	Symbol string
+	// Amount is the effect on the cash balance, negative for money out
+	Amount float64
+	// Memo describes the transaction
+	Memo string
+}
+
+// Holding is a position at the statement date.
+type Holding struct {
+	Symbol   string
+	Quantity float64
+	Price    float64
+}
+
+// Statement is an investment account's positions and trades.
+type Statement struct {
+	// AccountID identifies the account in the finance software
+	AccountID string
+	// Currency is the ISO 4217 code of all amounts
+	Currency string
+	// AsOf is the statement date, when the positions were valued
+	AsOf time.Time
+	// Cash is the cash balance
+	Cash float64
+	// Holdings contains the positions at AsOf
+	Holdings []Holding
+	// Trades contains the trades, oldest first
+	Trades []Trade
+	// CashTransactions contains the other transactions, oldest first
+	CashTransactions []CashTransaction
+	// Names maps symbols to security names
+	Names map[string]string
+}
+
+// NewStatement builds a statement for accountID from a snapshot and the
+// account's transactions. Transactions of types Stockal adds later are left
+// out.
+func NewStatement(accountID string, snapshot *stockal.Snapshot, transactions []stockal.Transaction) (*Statement, error) {
+	s := &Statement{
+		AccountID: accountID,
+		Currency:  "USD",
+		AsOf:      snapshot.TakenAt,
+		Cash:      snapshot.Summary.AccountSummary.CashBalance,
+		Names:     map[string]string{},
+	}
+	for _, h := range snapshot.Holdings {
+		s.Holdings = append(s.Holdings, Holding{Symbol: h.Symbol, Quantity: h.TotalUnit, Price: h.Price})
+		if h.Company != "" {
+			s.Names[h.Symbol] = h.Company
+		}
+	}
+	for _, tx := range transactions {
+		if !tx.Type.IsKnown() {
+			continue
+		}
+		t := tx.Date.Time
+		if t.IsZero() {
+			return nil, fmt.Errorf("transaction %s: invalid date %q", tx.ID, tx.Date.Raw)
+		}
+		switch tx.Type {
+		case stockal.TransactionBuy, stockal.TransactionSell:
+			side := stockal.OrderSideBuy
+			if tx.Type == stockal.TransactionSell {
+				side = stockal.OrderSideSell
+			}
+			price := tx.Price
+			if price == 0 && tx.Quantity > 0 {
+				price = math.Abs(tx.Amount) / tx.Quantity
+			}
+			s.Trades = append(s.Trades, Trade{
+				ID:       tx.ID,
+				Time:     t,
+				Symbol:   tx.Symbol,
+				Side:     side,
+				Quantity: tx.Quantity,
+				Price:    price,
+			})
+		default:
+			s.CashTransactions = append(s.CashTransactions, CashTransaction{
+				ID:     tx.ID,
+				Time:   t,
+				Type:   tx.Type,
+				Symbol: tx.Symbol,
+				Amount: tx.CashFlow(),
+				Memo:   tx.Description,
+			})
+		}
+	}
+	sort.SliceStable(s.Trades, func(i, j int) bool { return s.Trades[i].Time.Before(s.Trades[j].Time) })
+	sort.SliceStable(s.CashTransactions, func(i, j int) bool {
+		return s.CashTransactions[i].Time.Before(s.CashTransactions[j].Time)
+	})
+	return s, nil
+}
+
+// start returns the time of the earliest transaction, or AsOf if there are
+// none.
+func (s *Statement) start() time.Time {
+	start := s.AsOf
+	if len(s.Trades) > 0 && s.Trades[0].Time.Before(start) {
+		start = s.Trades[0].Time
+	}
+	if len(s.CashTransactions) > 0 && s.CashTransactions[0].Time.Before(start) {
+		start = s.CashTransactions[0].Time
+	}
+	return start
+}
+
+// name returns the security name of symbol, or the symbol itself.
+func (s *Statement) name(symbol string) string {
+	if name := s.Names[symbol]; name != "" {
+		return name
+	}
+	return symbol
+}
+
+// symbols returns every symbol held or traded, sorted.
+func (s *Statement) symbols() []string {
+	seen := map[string]bool{}
+	var symbols []string
+	add := func(symbol string) {
+		if !seen[symbol] {
+			seen[symbol] = true
+			symbols = append(symbols, symbol)
+		}
+	}
+	for _, h := range s.Holdings {
+		add(h.Symbol)
+	}
+	for _, t := range s.Trades {
+		add(t.Symbol)
+	}
+	for _, c := range s.CashTransactions {
+		if c.Symbol != "" {
+			add(c.Symbol)
+		}
+	}
+	sort.Strings(symbols)
+	return symbols
+}
+
+// now returns the current time; tests replace it.
+var now = time.Now
+
+// isSell reports whether a trade side is a sale.
+func isSell(side stockal.OrderSide) bool {
+	return strings.EqualFold(string(side), string(stockal.OrderSideSell))
+}
@@ -0,0 +1,102 @@
+package export
+
+import (
+	"bytes"
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+func testStatement(t *testing.T) *Statement {
+	t.Helper()
+	snapshot := &stockal.Snapshot{
+		TakenAt: time.Date(2024, time.June, 28, 20, 0, 0, 0, time.UTC),
+		Holdings: []stockal.Holding{
+			{Symbol: "AAPL", Company: "Apple Inc.", TotalUnit: 1.5, Price: 210.62},
+			{Symbol: "VOO", Company: "Vanguard S&P 500 ETF", TotalUnit: 2, Price: 500.13},
+		},
+	}
+	snapshot.Summary.AccountSummary.CashBalance = 118.25
+	statement, err := NewStatement("personal", snapshot, []stockal.Transaction{
+		{ID: "t6", Type: stockal.TransactionSell, Symbol: "AAPL", Quantity: 0.5, Amount: 105, Date: stockal.NewTime("2024-06-03T15:00:00Z")},
+		{ID: "t1", Type: stockal.TransactionDeposit, Amount: 1500, Description: "Wire transfer", Date: stockal.NewTime("2024-05-01T09:00:00Z")},
+		{ID: "t2", Type: stockal.TransactionBuy, Symbol: "AAPL", Quantity: 2, Price: 170, Amount: -340, Date: stockal.NewTime("2024-05-02T14:30:00Z")},
+		{ID: "t3", Type: stockal.TransactionBuy, Symbol: "VOO", Quantity: 2, Price: 470, Amount: -940, Date: stockal.NewTime("2024-05-02 14:45:00")},
+		{ID: "t4", Type: stockal.TransactionFee, Amount: 2.5, Description: "Wire fee", Date: stockal.NewTime("2024-05-03T09:00:00Z")},
+		{ID: "t5", Type: stockal.TransactionDividend, Symbol: "VOO", Amount: 3.55, Date: stockal.NewTime("2024-05-15T12:00:00Z")},
+		{ID: "t7", Type: stockal.TransactionWithdrawal, Amount: -100, Date: stockal.NewTime("2024-06-10T09:00:00Z")},
+		{ID: "t8", Type: "interest", Amount: 0.2, Date: stockal.NewTime("2024-06-11T09:00:00Z")},
+	})
+	if err != nil {
+		t.Fatalf("NewStatement: %v", err)
+	}
+	return statement
+}
+
+func TestNewStatement(t *testing.T) {
+	s := testStatement(t)
+	if len(s.Trades) != 3 || s.Trades[0].ID != "t2" || s.Trades[2].ID != "t6" {
+		t.Fatalf("trades = %+v, want t2, t3 and t6 oldest first", s.Trades)
+	}
+	if s.Trades[2].Price != 210 {
+		t.Errorf("sale price = %g, want 210 from the amount", s.Trades[2].Price)
+	}
+	want := []struct {
+		id     string
+		amount float64
+	}{{"t1", 1500}, {"t4", -2.5}, {"t5", 3.55}, {"t7", -100}}
+	if len(s.CashTransactions) != len(want) {
+		t.Fatalf("cash transactions = %+v, want %v", s.CashTransactions, want)
+	}
+	for i, w := range want {
+		if c := s.CashTransactions[i]; c.ID != w.id || c.Amount != w.amount {
+			t.Errorf("cash transaction %d = %+v, want %s of %g", i, c, w.id, w.amount)
+		}
+	}
+
+	_, err := NewStatement("personal", &stockal.Snapshot{}, []stockal.Transaction{{ID: "t1", Type: stockal.TransactionDeposit, Date: stockal.NewTime("soon")}})
+	if err == nil {
+		t.Error("NewStatement accepted an invalid date")
+	}
+}
+
+func TestWrite(t *testing.T) {
+	defer func(f func() time.Time) { now = f }(now)
+	now = func() time.Time { return time.Date(2024, time.June, 29, 8, 0, 0, 0, time.UTC) }
+
+	tests := []struct {
+		golden string
+		write  func(io.Writer, *Statement) error
+	}{
+		{"statement.ofx", WriteOFX},
+		{"statement.qif", WriteQIF},
+	}
+	for _, tt := range tests {
+		t.Run(tt.golden, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := tt.write(&buf, testStatement(t)); err != nil {
+				t.Fatal(err)
+			}
+			path := filepath.Join("testdata", tt.golden)
+			if *update {
+				if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(buf.Bytes(), want) {
+				t.Errorf("output differs from %s (run with -update to rewrite it):\n%s", path, buf.Bytes())
+			}
+		})
+	}
+}
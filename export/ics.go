@@ -0,0 +1,98 @@
+package export
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+)
+
+// icsLineLimit is the maximum length of an iCalendar content line, in octets,
+// before it must be folded.
+const icsLineLimit = 75
+
+// WriteICS writes events as an iCalendar feed named name, one all-day event
+// per corporate event. Event UIDs depend only on the symbol, kind and date, so
+// calendar applications update rather than duplicate events when the feed is
+// regenerated.
+func WriteICS(w io.Writer, name string, events []stockal.CorporateEvent) error {
+	bw := bufio.NewWriter(w)
+	line := func(s string) {
+		writeFolded(bw, s)
+	}
+
+	line("BEGIN:VCALENDAR")
+	line("VERSION:2.0")
+	line("PRODID:-//unofficial-stockal-api//stockal//EN")
+	line("CALSCALE:GREGORIAN")
+	line("METHOD:PUBLISH")
+	line("X-WR-CALNAME:" + icsText(name))
+
+	stamp := now().UTC().Format("20060102T150405Z")
+	for _, e := range events {
+		day := e.Date.Format("20060102")
+		line("BEGIN:VEVENT")
+		line("UID:" + icsText(fmt.Sprintf("%s-%s-%s@unofficial-stockal-api", e.Symbol, e.Kind, day)))
+		line("DTSTAMP:" + stamp)
+		line("DTSTART;VALUE=DATE:" + day)
+		line("DTEND;VALUE=DATE:" + e.Date.AddDate(0, 0, 1).Format("20060102"))
+		line("SUMMARY:" + icsText(icsSummary(e)))
+		line("CATEGORIES:" + icsText(string(e.Kind)))
+		line("TRANSP:TRANSPARENT")
+		line("END:VEVENT")
+	}
+	line("END:VCALENDAR")
+	return bw.Flush()
+}
+
+// icsSummary describes an event in a few words, e.g. "AAPL ex-dividend (0.26 USD)".
+func icsSummary(e stockal.CorporateEvent) string {
+	var what string
+	switch e.Kind {
+	case stockal.EventExDividend:
+		what = "ex-dividend"
+	case stockal.EventDividendPayment:
+		what = "dividend payment"
+	case stockal.EventEarnings:
+		what = "earnings"
+	default:
+		what = string(e.Kind)
+	}
+	summary := e.Symbol + " " + what
+	if e.Amount == 0 {
+		return summary
+	}
+	amount := strconv.FormatFloat(e.Amount, 'f', -1, 64)
+	if e.Kind == stockal.EventEarnings {
+		amount = "EPS est. " + amount
+	}
+	if e.Currency != "" {
+		amount += " " + e.Currency
+	}
+	return summary + " (" + amount + ")"
+}
+
+// icsText escapes a TEXT property value.
+var icsText = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`).Replace
+
+// writeFolded writes a content line terminated by CRLF, folding it into
+// continuation lines at icsLineLimit octets without splitting UTF-8 sequences.
+func writeFolded(w *bufio.Writer, s string) {
+	limit := icsLineLimit
+	for len(s) > limit {
+		cut := limit
+		for cut > 0 && s[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		w.WriteString(s[:cut])
+		w.WriteString("\r\n ")
+		s = s[cut:]
+		// Continuation lines start with a space, which counts toward the limit.
+		limit = icsLineLimit - 1
+	}
+	w.WriteString(s)
+	w.WriteString("\r\n")
+}
@@ -0,0 +1,263 @@
+package export
+
+import (
+	"encoding/xml"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+)
+
+// ofxHeader starts an OFX 2.2 document.
+const ofxHeader = `<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<?OFX OFXHEADER="200" VERSION="220" SECURITY="NONE" OLDFILEUID="NONE" NEWFILEUID="NONE"?>
+`
+
+// brokerID identifies Stockal in INVACCTFROM.
+const brokerID = "stockal.com"
+
+type ofxDocument struct {
+	XMLName xml.Name `xml:"OFX"`
+	SignOn  struct {
+		Response struct {
+			Status   ofxStatus `xml:"STATUS"`
+			Server   string    `xml:"DTSERVER"`
+			Language string    `xml:"LANGUAGE"`
+		} `xml:"SONRS"`
+	} `xml:"SIGNONMSGSRSV1"`
+	Statements struct {
+		Response struct {
+			TransactionID string                 `xml:"TRNUID"`
+			Status        ofxStatus              `xml:"STATUS"`
+			Statement     ofxInvestmentStatement `xml:"INVSTMTRS"`
+		} `xml:"INVSTMTTRNRS"`
+	} `xml:"INVSTMTMSGSRSV1"`
+	Securities struct {
+		List []ofxStockInfo `xml:"SECLIST>STOCKINFO"`
+	} `xml:"SECLISTMSGSRSV1"`
+}
+
+type ofxStatus struct {
+	Code     int    `xml:"CODE"`
+	Severity string `xml:"SEVERITY"`
+}
+
+type ofxInvestmentStatement struct {
+	AsOf     string `xml:"DTASOF"`
+	Currency string `xml:"CURDEF"`
+	Account  struct {
+		BrokerID  string `xml:"BROKERID"`
+		AccountID string `xml:"ACCTID"`
+	} `xml:"INVACCTFROM"`
+	Transactions struct {
+		Start  string               `xml:"DTSTART"`
+		End    string               `xml:"DTEND"`
+		Buys   []ofxTrade           `xml:"BUYSTOCK"`
+		Sells  []ofxTrade           `xml:"SELLSTOCK"`
+		Income []ofxIncome          `xml:"INCOME"`
+		Bank   []ofxBankTransaction `xml:"INVBANKTRAN"`
+	} `xml:"INVTRANLIST"`
+	Positions []ofxPosition `xml:"INVPOSLIST>POSSTOCK"`
+	Balance   struct {
+		AvailableCash string `xml:"AVAILCASH"`
+		Margin        string `xml:"MARGINBALANCE"`
+		Short         string `xml:"SHORTBALANCE"`
+	} `xml:"INVBAL"`
+}
+
+type ofxSecurityID struct {
+	UniqueID     string `xml:"UNIQUEID"`
+	UniqueIDType string `xml:"UNIQUEIDTYPE"`
+}
+
+// ofxTrade is a BUYSTOCK or SELLSTOCK aggregate. OFX nests buys in INVBUY
+// and sells in INVSELL, which otherwise have the same content.
+type ofxTrade struct {
+	Buy      *ofxTradeDetail `xml:"INVBUY,omitempty"`
+	Sell     *ofxTradeDetail `xml:"INVSELL,omitempty"`
+	BuyType  string          `xml:"BUYTYPE,omitempty"`
+	SellType string          `xml:"SELLTYPE,omitempty"`
+}
+
+type ofxTradeDetail struct {
+	Transaction struct {
+		ID   string `xml:"FITID"`
+		Date string `xml:"DTTRADE"`
+	} `xml:"INVTRAN"`
+	Security    ofxSecurityID `xml:"SECID"`
+	Units       string        `xml:"UNITS"`
+	UnitPrice   string        `xml:"UNITPRICE"`
+	Total       string        `xml:"TOTAL"`
+	SubAccount  string        `xml:"SUBACCTSEC"`
+	FundAccount string        `xml:"SUBACCTFUND"`
+}
+
+// ofxIncome is an INCOME aggregate, for a dividend.
+type ofxIncome struct {
+	Transaction struct {
+		ID   string `xml:"FITID"`
+		Date string `xml:"DTTRADE"`
+		Memo string `xml:"MEMO,omitempty"`
+	} `xml:"INVTRAN"`
+	Security    ofxSecurityID `xml:"SECID"`
+	Type        string        `xml:"INCOMETYPE"`
+	Total       string        `xml:"TOTAL"`
+	SubAccount  string        `xml:"SUBACCTSEC"`
+	FundAccount string        `xml:"SUBACCTFUND"`
+}
+
+// ofxBankTransaction is an INVBANKTRAN aggregate, for cash moving in or out
+// of the account.
+type ofxBankTransaction struct {
+	Transaction struct {
+		Type   string `xml:"TRNTYPE"`
+		Posted string `xml:"DTPOSTED"`
+		Amount string `xml:"TRNAMT"`
+		ID     string `xml:"FITID"`
+		Memo   string `xml:"MEMO,omitempty"`
+	} `xml:"STMTTRN"`
+	FundAccount string `xml:"SUBACCTFUND"`
+}
+
+type ofxPosition struct {
+	Security    ofxSecurityID `xml:"INVPOS>SECID"`
+	HeldIn      string        `xml:"INVPOS>HELDINACCT"`
+	Type        string        `xml:"INVPOS>POSTYPE"`
+	Units       string        `xml:"INVPOS>UNITS"`
+	UnitPrice   string        `xml:"INVPOS>UNITPRICE"`
+	MarketValue string        `xml:"INVPOS>MKTVAL"`
+	PriceAsOf   string        `xml:"INVPOS>DTPRICEASOF"`
+}
+
+type ofxStockInfo struct {
+	Security ofxSecurityID `xml:"SECINFO>SECID"`
+	Name     string        `xml:"SECINFO>SECNAME"`
+	Ticker   string        `xml:"SECINFO>TICKER"`
+}
+
+// WriteOFX writes the statement as an OFX 2.2 investment statement.
+// Securities are identified by ticker, as Stockal does not report CUSIPs.
+// Dividends are INCOME transactions; deposits, withdrawals, fees and taxes
+// are bank transactions of the investment account.
+func WriteOFX(w io.Writer, s *Statement) error {
+	var doc ofxDocument
+	doc.SignOn.Response.Status = ofxStatus{Code: 0, Severity: "INFO"}
+	doc.SignOn.Response.Server = ofxTime(now())
+	doc.SignOn.Response.Language = "ENG"
+
+	resp := &doc.Statements.Response
+	resp.TransactionID = "0"
+	resp.Status = ofxStatus{Code: 0, Severity: "INFO"}
+	st := &resp.Statement
+	st.AsOf = ofxTime(s.AsOf)
+	st.Currency = s.Currency
+	st.Account.BrokerID = brokerID
+	st.Account.AccountID = s.AccountID
+
+	st.Transactions.Start, st.Transactions.End = ofxTime(s.start()), ofxTime(s.AsOf)
+	for _, t := range s.Trades {
+		detail := &ofxTradeDetail{
+			Security:    tickerID(t.Symbol),
+			UnitPrice:   ofxAmount(t.Price),
+			SubAccount:  "CASH",
+			FundAccount: "CASH",
+		}
+		detail.Transaction.ID = t.ID
+		detail.Transaction.Date = ofxTime(t.Time)
+		if isSell(t.Side) {
+			detail.Units = ofxAmount(-t.Quantity)
+			detail.Total = ofxMoney(t.Total())
+			st.Transactions.Sells = append(st.Transactions.Sells, ofxTrade{Sell: detail, SellType: "SELL"})
+		} else {
+			detail.Units = ofxAmount(t.Quantity)
+			detail.Total = ofxMoney(-t.Total())
+			st.Transactions.Buys = append(st.Transactions.Buys, ofxTrade{Buy: detail, BuyType: "BUY"})
+		}
+	}
+	for _, c := range s.CashTransactions {
+		if c.Type == stockal.TransactionDividend {
+			income := ofxIncome{
+				Security:    tickerID(c.Symbol),
+				Type:        "DIV",
+				Total:       ofxMoney(c.Amount),
+				SubAccount:  "CASH",
+				FundAccount: "CASH",
+			}
+			income.Transaction.ID = c.ID
+			income.Transaction.Date = ofxTime(c.Time)
+			income.Transaction.Memo = c.Memo
+			st.Transactions.Income = append(st.Transactions.Income, income)
+			continue
+		}
+		bank := ofxBankTransaction{FundAccount: "CASH"}
+		bank.Transaction.Type = ofxTransactionType(c)
+		bank.Transaction.Posted = ofxTime(c.Time)
+		bank.Transaction.Amount = ofxMoney(c.Amount)
+		bank.Transaction.ID = c.ID
+		bank.Transaction.Memo = c.Memo
+		st.Transactions.Bank = append(st.Transactions.Bank, bank)
+	}
+	for _, h := range s.Holdings {
+		st.Positions = append(st.Positions, ofxPosition{
+			Security:    tickerID(h.Symbol),
+			HeldIn:      "CASH",
+			Type:        "LONG",
+			Units:       ofxAmount(h.Quantity),
+			UnitPrice:   ofxAmount(h.Price),
+			MarketValue: ofxMoney(h.Quantity * h.Price),
+			PriceAsOf:   ofxTime(s.AsOf),
+		})
+	}
+	st.Balance.AvailableCash = ofxMoney(s.Cash)
+	st.Balance.Margin = "0"
+	st.Balance.Short = "0"
+
+	for _, symbol := range s.symbols() {
+		doc.Securities.List = append(doc.Securities.List, ofxStockInfo{
+			Security: tickerID(symbol),
+			Name:     s.name(symbol),
+			Ticker:   symbol,
+		})
+	}
+
+	if _, err := io.WriteString(w, ofxHeader); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// ofxTransactionType returns the TRNTYPE of a bank transaction.
+func ofxTransactionType(c CashTransaction) string {
+	switch {
+	case c.Type == stockal.TransactionFee:
+		return "FEE"
+	case c.Amount < 0:
+		return "DEBIT"
+	default:
+		return "DEP"
+	}
+}
+
+func tickerID(symbol string) ofxSecurityID {
+	return ofxSecurityID{UniqueID: symbol, UniqueIDType: "TICKER"}
+}
+
+// ofxTime formats t as an OFX datetime in UTC.
+func ofxTime(t time.Time) string {
+	return t.UTC().Format("20060102150405.000") + "[0:GMT]"
+}
+
+func ofxAmount(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+func ofxMoney(v float64) string {
+	return strconv.FormatFloat(v, 'f', 2, 64)
+}
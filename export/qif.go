@@ -0,0 +1,72 @@
+package export
+
+import (
+	"bufio"
+	"cmp"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+)
+
+// WriteQIF writes the statement as a QIF investment account. QIF has no
+// notion of positions, so the holdings are exported as the security list and
+// their prices at the statement date; the trades carry the quantities.
+// Dividends are exported as Div, deposits as XIn, and withdrawals, fees and
+// taxes as XOut, with the transaction's description as the memo.
+func WriteQIF(w io.Writer, s *Statement) error {
+	bw := bufio.NewWriter(w)
+
+	fmt.Fprintf(bw, "!Account\nN%s\nTInvst\n^\n", s.AccountID)
+
+	fmt.Fprint(bw, "!Type:Security\n")
+	for _, symbol := range s.symbols() {
+		fmt.Fprintf(bw, "N%s\nS%s\nTStock\n^\n", s.name(symbol), symbol)
+	}
+
+	fmt.Fprint(bw, "!Type:Invst\n")
+	for _, t := range s.Trades {
+		action := "Buy"
+		if isSell(t.Side) {
+			action = "Sell"
+		}
+		fmt.Fprintf(bw, "D%s\nN%s\nY%s\nI%s\nQ%s\nU%s\nT%s\nM%s\n^\n",
+			qifDate(t.Time), action, s.name(t.Symbol),
+			qifAmount(t.Price), qifAmount(t.Quantity), qifMoney(t.Total()), qifMoney(t.Total()),
+			"Stockal transaction "+t.ID)
+	}
+	for _, c := range s.CashTransactions {
+		memo := cmp.Or(c.Memo, "Stockal transaction "+c.ID)
+		switch {
+		case c.Type == stockal.TransactionDividend:
+			fmt.Fprintf(bw, "D%s\nNDiv\nY%s\nT%s\nM%s\n^\n", qifDate(c.Time), s.name(c.Symbol), qifMoney(c.Amount), memo)
+		case c.Amount < 0:
+			fmt.Fprintf(bw, "D%s\nNXOut\nT%s\nM%s\n^\n", qifDate(c.Time), qifMoney(-c.Amount), memo)
+		default:
+			fmt.Fprintf(bw, "D%s\nNXIn\nT%s\nM%s\n^\n", qifDate(c.Time), qifMoney(c.Amount), memo)
+		}
+	}
+
+	if len(s.Holdings) > 0 {
+		fmt.Fprint(bw, "!Type:Prices\n")
+		for _, h := range s.Holdings {
+			fmt.Fprintf(bw, "%q,%s,%q\n^\n", h.Symbol, qifAmount(h.Price), qifDate(s.AsOf))
+		}
+	}
+	return bw.Flush()
+}
+
+// qifDate formats t as MM/DD/YYYY in its own time zone.
+func qifDate(t time.Time) string {
+	return t.Format("01/02/2006")
+}
+
+func qifAmount(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+func qifMoney(v float64) string {
+	return strconv.FormatFloat(v, 'f', 2, 64)
+}
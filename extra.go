@@ -0,0 +1,300 @@
+package stockal
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// The response structs below keep the fields the API sends that they do not
+// model in Extra, so that fields Stockal adds can be used before the client
+// models them, and reported with their payloads. Extra is marshalled back
+// alongside the modelled fields, so that stored snapshots keep them too.
+
+// knownFields caches the JSON names of each struct type's fields.
+var knownFields sync.Map // reflect.Type -> []string
+
+// fieldNames returns the JSON names of the fields of struct type t.
+func fieldNames(t reflect.Type) []string {
+	if names, ok := knownFields.Load(t); ok {
+		return names.([]string)
+	}
+	var names []string
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		switch name {
+		case "-":
+			continue
+		case "":
+			name = field.Name
+		}
+		names = append(names, name)
+	}
+	knownFields.Store(t, names)
+	return names
+}
+
+// unmarshalExtra decodes data into v, a pointer to a struct without an
+// UnmarshalJSON method, and returns the object's fields v has no field for,
+// or nil. Like encoding/json, it matches names case-insensitively.
+func unmarshalExtra(data []byte, v any) (map[string]json.RawMessage, error) {
+	if err := json.Unmarshal(data, v); err != nil {
+		return nil, err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil || len(fields) == 0 {
+		return nil, nil
+	}
+	for _, name := range fieldNames(reflect.TypeOf(v).Elem()) {
+		for key := range fields {
+			if strings.EqualFold(key, name) {
+				delete(fields, key)
+			}
+		}
+	}
+	if len(fields) == 0 {
+		return nil, nil
+	}
+	return fields, nil
+}
+
+// marshalExtra encodes v, a struct without a MarshalJSON method, with the
+// extra fields it has no field for added.
+func marshalExtra(v any, extra map[string]json.RawMessage) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil || len(extra) == 0 {
+		return data, err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	for key, value := range extra {
+		// Never let an extra field shadow a modelled one
+		if _, ok := fields[key]; !ok {
+			fields[key] = value
+		}
+	}
+	return json.Marshal(fields)
+}
+
+func (d *AccountSummaryData) UnmarshalJSON(data []byte) error {
+	type plain AccountSummaryData
+	extra, err := unmarshalExtra(data, (*plain)(d))
+	d.Extra = extra
+	return err
+}
+
+func (d AccountSummaryData) MarshalJSON() ([]byte, error) {
+	type plain AccountSummaryData
+	return marshalExtra(plain(d), d.Extra)
+}
+
+func (s *AccountSummary) UnmarshalJSON(data []byte) error {
+	type plain AccountSummary
+	extra, err := unmarshalExtra(data, (*plain)(s))
+	s.Extra = extra
+	return err
+}
+
+func (s AccountSummary) MarshalJSON() ([]byte, error) {
+	type plain AccountSummary
+	return marshalExtra(plain(s), s.Extra)
+}
+
+func (d *PortfolioDetailData) UnmarshalJSON(data []byte) error {
+	type plain PortfolioDetailData
+	extra, err := unmarshalExtra(data, (*plain)(d))
+	d.Extra = extra
+	return err
+}
+
+func (d PortfolioDetailData) MarshalJSON() ([]byte, error) {
+	type plain PortfolioDetailData
+	return marshalExtra(plain(d), d.Extra)
+}
+
+func (h *Holding) UnmarshalJSON(data []byte) error {
+	type plain Holding
+	extra, err := unmarshalExtra(data, (*plain)(h))
+	h.Extra = extra
+	return err
+}
+
+func (h Holding) MarshalJSON() ([]byte, error) {
+	type plain Holding
+	return marshalExtra(plain(h), h.Extra)
+}
+
+func (q *Quote) UnmarshalJSON(data []byte) error {
+	type plain Quote
+	extra, err := unmarshalExtra(data, (*plain)(q))
+	q.Extra = extra
+	return err
+}
+
+func (q Quote) MarshalJSON() ([]byte, error) {
+	type plain Quote
+	return marshalExtra(plain(q), q.Extra)
+}
+
+func (o *Order) UnmarshalJSON(data []byte) error {
+	type plain Order
+	extra, err := unmarshalExtra(data, (*plain)(o))
+	o.Extra = extra
+	return err
+}
+
+func (o Order) MarshalJSON() ([]byte, error) {
+	type plain Order
+	return marshalExtra(plain(o), o.Extra)
+}
+
+func (t *Transaction) UnmarshalJSON(data []byte) error {
+	type plain Transaction
+	extra, err := unmarshalExtra(data, (*plain)(t))
+	t.Extra = extra
+	return err
+}
+
+func (t Transaction) MarshalJSON() ([]byte, error) {
+	type plain Transaction
+	return marshalExtra(plain(t), t.Extra)
+}
+
+func (w *Watchlist) UnmarshalJSON(data []byte) error {
+	type plain Watchlist
+	extra, err := unmarshalExtra(data, (*plain)(w))
+	w.Extra = extra
+	return err
+}
+
+func (w Watchlist) MarshalJSON() ([]byte, error) {
+	type plain Watchlist
+	return marshalExtra(plain(w), w.Extra)
+}
+
+func (i *WatchlistItem) UnmarshalJSON(data []byte) error {
+	type plain WatchlistItem
+	extra, err := unmarshalExtra(data, (*plain)(i))
+	i.Extra = extra
+	return err
+}
+
+func (i WatchlistItem) MarshalJSON() ([]byte, error) {
+	type plain WatchlistItem
+	return marshalExtra(plain(i), i.Extra)
+}
+
+func (in *Instrument) UnmarshalJSON(data []byte) error {
+	type plain Instrument
+	extra, err := unmarshalExtra(data, (*plain)(in))
+	in.Extra = extra
+	return err
+}
+
+func (in Instrument) MarshalJSON() ([]byte, error) {
+	type plain Instrument
+	return marshalExtra(plain(in), in.Extra)
+}
+
+func (s *Stack) UnmarshalJSON(data []byte) error {
+	type plain Stack
+	extra, err := unmarshalExtra(data, (*plain)(s))
+	s.Extra = extra
+	return err
+}
+
+func (s Stack) MarshalJSON() ([]byte, error) {
+	type plain Stack
+	return marshalExtra(plain(s), s.Extra)
+}
+
+func (d *StackDetail) UnmarshalJSON(data []byte) error {
+	type plain StackDetail
+	extra, err := unmarshalExtra(data, (*plain)(d))
+	d.Extra = extra
+	return err
+}
+
+func (d StackDetail) MarshalJSON() ([]byte, error) {
+	type plain StackDetail
+	return marshalExtra(plain(d), d.Extra)
+}
+
+func (o *StackOrder) UnmarshalJSON(data []byte) error {
+	type plain StackOrder
+	extra, err := unmarshalExtra(data, (*plain)(o))
+	o.Extra = extra
+	return err
+}
+
+func (o StackOrder) MarshalJSON() ([]byte, error) {
+	type plain StackOrder
+	return marshalExtra(plain(o), o.Extra)
+}
+
+func (f *FundingInstructions) UnmarshalJSON(data []byte) error {
+	type plain FundingInstructions
+	extra, err := unmarshalExtra(data, (*plain)(f))
+	f.Extra = extra
+	return err
+}
+
+func (f FundingInstructions) MarshalJSON() ([]byte, error) {
+	type plain FundingInstructions
+	return marshalExtra(plain(f), f.Extra)
+}
+
+func (d *Deposit) UnmarshalJSON(data []byte) error {
+	type plain Deposit
+	extra, err := unmarshalExtra(data, (*plain)(d))
+	d.Extra = extra
+	return err
+}
+
+func (d Deposit) MarshalJSON() ([]byte, error) {
+	type plain Deposit
+	return marshalExtra(plain(d), d.Extra)
+}
+
+func (w *Withdrawal) UnmarshalJSON(data []byte) error {
+	type plain Withdrawal
+	extra, err := unmarshalExtra(data, (*plain)(w))
+	w.Extra = extra
+	return err
+}
+
+func (w Withdrawal) MarshalJSON() ([]byte, error) {
+	type plain Withdrawal
+	return marshalExtra(plain(w), w.Extra)
+}
+
+func (d *Document) UnmarshalJSON(data []byte) error {
+	type plain Document
+	extra, err := unmarshalExtra(data, (*plain)(d))
+	d.Extra = extra
+	return err
+}
+
+func (d Document) MarshalJSON() ([]byte, error) {
+	type plain Document
+	return marshalExtra(plain(d), d.Extra)
+}
+
+func (d *Dividend) UnmarshalJSON(data []byte) error {
+	type plain Dividend
+	extra, err := unmarshalExtra(data, (*plain)(d))
+	d.Extra = extra
+	return err
+}
+
+func (d Dividend) MarshalJSON() ([]byte, error) {
+	type plain Dividend
+	return marshalExtra(plain(d), d.Extra)
+}
@@ -0,0 +1,64 @@
+package stockal_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+)
+
+func TestExtraFields(t *testing.T) {
+	var h stockal.Holding
+	err := json.Unmarshal([]byte(`{"symbol":"AAPL","TOTALUNIT":2,"sector":"Technology","lots":[{"units":2}]}`), &h)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Field names match case-insensitively, as encoding/json matches them
+	if h.Symbol != "AAPL" || h.TotalUnit != 2 || len(h.Extra) != 2 || string(h.Extra["sector"]) != `"Technology"` {
+		t.Errorf("got %+v", h)
+	}
+
+	// Extra fields survive a round trip, without shadowing modelled ones
+	h.Extra["symbol"] = json.RawMessage(`"MSFT"`)
+	data, err := json.Marshal(h)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var again stockal.Holding
+	if err := json.Unmarshal(data, &again); err != nil {
+		t.Fatal(err)
+	}
+	if again.Symbol != "AAPL" || string(again.Extra["lots"]) != `[{"units":2}]` || len(again.Extra) != 2 {
+		t.Errorf("after a round trip got %+v from %s", again, data)
+	}
+
+	var q stockal.Quote
+	if err := json.Unmarshal([]byte(`{"symbol":"AAPL","price":1}`), &q); err != nil || q.Extra != nil {
+		t.Errorf("got %+v, %v; want no extra fields", q, err)
+	}
+}
+
+func TestExtraFieldsInResponses(t *testing.T) {
+	client := newTestClient(t, map[string]string{
+		"/v2/users/accountSummary/summary": `{"code":200,"message":"Success","data":{"accountSummary":{"cashBalance":25,"marginEnabled":false},"riskProfile":"moderate"}}`,
+		"/v2/users/portfolio/detail":       `{"code":200,"message":"Success","data":{"holdings":[{"symbol":"VOO","sector":"Index"}],"totalRecords":1,"currency":"USD"}}`,
+	})
+
+	summary, err := client.Account().Summary(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(summary.Data.Extra["riskProfile"]) != `"moderate"` || string(summary.Data.AccountSummary.Extra["marginEnabled"]) != "false" {
+		t.Errorf("got %+v", summary.Data)
+	}
+
+	// Streamed responses are decoded piecewise, and keep them too
+	portfolio, err := client.StreamPortfolioDetail(context.Background(), stockal.PortfolioParams{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(portfolio.Data.Extra["currency"]) != `"USD"` || string(portfolio.Data.Holdings[0].Extra["sector"]) != `"Index"` {
+		t.Errorf("got %+v", portfolio.Data)
+	}
+}
@@ -0,0 +1,70 @@
+package stockal_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+	"github.com/adjaecent/unofficial-stockal-api/stockaltest"
+)
+
+func TestFixtureServer(t *testing.T) {
+	srv := stockaltest.NewServer()
+	defer srv.Close()
+	srv.HandleFunc("GET /v2/market/fx/USDINR", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"code":200,"message":"Success","data":{"rate":90.5}}`))
+	})
+
+	ctx := context.Background()
+	client := srv.NewClient()
+	if _, err := client.Auth().Login(ctx, "alice", "secret"); err != nil {
+		t.Fatalf("Auth.Login: %v", err)
+	}
+
+	summary, err := client.Account().Summary(ctx)
+	if err != nil {
+		t.Fatalf("Account.Summary: %v", err)
+	}
+	if summary.Data.AccountSummary.CashBalance != stockaltest.AccountSummary().Data.AccountSummary.CashBalance {
+		t.Errorf("CashBalance = %v, want the fixture's", summary.Data.AccountSummary.CashBalance)
+	}
+	portfolio, err := client.Portfolio().Detail(ctx)
+	if err != nil {
+		t.Fatalf("Portfolio.Detail: %v", err)
+	}
+	if got, want := len(portfolio.Data.Holdings), len(stockaltest.Portfolio().Data.Holdings); got != want {
+		t.Errorf("got %d holdings, want %d", got, want)
+	}
+	order, err := client.Orders().Get(ctx, "ord-1001")
+	if err != nil {
+		t.Fatalf("Orders.Get: %v", err)
+	}
+	if order.Data.Symbol != "MSFT" {
+		t.Errorf("order ord-1001 is for %s, want MSFT", order.Data.Symbol)
+	}
+	if _, err := client.Orders().Get(ctx, "ord-404"); err == nil {
+		t.Error("Orders.Get of an unknown order succeeded")
+	}
+	quotes, err := client.GetQuotes(ctx, "AAPL", "TSLA")
+	if err != nil {
+		t.Fatalf("GetQuotes: %v", err)
+	}
+	if len(quotes.Data) != 1 || quotes.Data[0].Symbol != "AAPL" {
+		t.Errorf("quotes = %+v, want AAPL only", quotes.Data)
+	}
+	fx, err := client.GetFxRate(ctx)
+	if err != nil {
+		t.Fatalf("GetFxRate: %v", err)
+	}
+	if fx.Data.Rate != 90.5 {
+		t.Errorf("rate = %v, want 90.5 from the handler", fx.Data.Rate)
+	}
+
+	stranger := srv.NewClient(stockal.WithAccessToken("someone-else", ""))
+	if _, err := stranger.Portfolio().Detail(ctx); !errors.Is(err, stockal.ErrTokenExpired) {
+		t.Errorf("GetPortfolioDetail with another token: error = %v, want ErrTokenExpired", err)
+	}
+}
@@ -0,0 +1,442 @@
+package stockal
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// ErrInvalidTransfer is returned when a deposit or withdrawal fails validation
+// before it is sent.
+var ErrInvalidTransfer = errors.New("invalid transfer")
+
+// DepositManager is implemented by clients that can fund the account.
+type DepositManager interface {
+	GetFundingInstructions(ctx context.Context) (*FundingInstructionsResponse, error)
+	CreateDeposit(ctx context.Context, deposit DepositRequest) (*DepositResponse, error)
+	GetDepositStatus(ctx context.Context, depositID string) (*DepositResponse, error)
+}
+
+// PurposeCodeEquity is the RBI purpose code for investment abroad in equity
+// shares, under which remittances to the account are made. CreateDeposit
+// uses it when a DepositRequest has none.
+const PurposeCodeEquity = "S0001"
+
+// FundingInstructions represents where and how to send money to the account.
+type FundingInstructions struct {
+	// BeneficiaryName is the account holder to name on the remittance
+	BeneficiaryName string `json:"beneficiaryName"`
+	// BankName is the US bank receiving the remittance
+	BankName string `json:"bankName"`
+	// BankAddress is the receiving bank's address
+	BankAddress string `json:"bankAddress,omitempty"`
+	// AccountNumber is the account to credit at the receiving bank
+	AccountNumber string `json:"accountNumber"`
+	// RoutingNumber is the receiving bank's ABA routing number
+	RoutingNumber string `json:"routingNumber,omitempty"`
+	// SwiftCode is the receiving bank's SWIFT code, for international wires
+	SwiftCode string `json:"swiftCode"`
+	// Reference is the text to quote on the remittance, so that it is credited to this account
+	Reference string `json:"reference"`
+	// LRS is the account holder's use of the Liberalised Remittance Scheme
+	LRS LRSUsage `json:"lrs"`
+	// Extra holds the fields the API sent that this struct does not model
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// LRSUsage represents how much of the yearly Liberalised Remittance Scheme
+// limit an Indian resident has used. Remittances by all banks count towards
+// it, so the figures cover only what Stockal knows about.
+type LRSUsage struct {
+	// FinancialYear is the Indian financial year the figures are for (e.g., "2024-25")
+	FinancialYear string `json:"financialYear"`
+	// Limit is the yearly limit, in US dollars
+	Limit float64 `json:"limit"`
+	// Remitted is the amount remitted this financial year, in US dollars
+	Remitted float64 `json:"remitted"`
+}
+
+// Remaining returns how much more can be remitted this financial year, in US
+// dollars, or zero once the limit is reached.
+func (u LRSUsage) Remaining() float64 {
+	return max(u.Limit-u.Remitted, 0)
+}
+
+// FundingInstructionsResponse represents the response from the funding
+// instructions API.
+type FundingInstructionsResponse struct {
+	// Code is the HTTP response code
+	Code int `json:"code"`
+	// Message is the response message (usually "Success")
+	Message string `json:"message"`
+	// Data contains the instructions
+	Data FundingInstructions `json:"data"`
+}
+
+// DepositMethod is how a deposit is sent. Stockal may add methods, so other
+// values should be expected.
+type DepositMethod string
+
+// Deposit methods.
+const (
+	// DepositWire is an international wire sent by the user's bank
+	DepositWire DepositMethod = "wire"
+	// DepositPartnerBank is a remittance through one of Stockal's partner banks
+	DepositPartnerBank DepositMethod = "partner_bank"
+)
+
+// IsKnown reports whether m is one of the declared deposit methods.
+func (m DepositMethod) IsKnown() bool {
+	switch m {
+	case DepositWire, DepositPartnerBank:
+		return true
+	}
+	return false
+}
+
+// UnmarshalJSON decodes a deposit method, matching the declared ones
+// regardless of case and surrounding space. Unknown methods are kept as sent.
+func (m *DepositMethod) UnmarshalJSON(data []byte) error {
+	v, err := decodeEnum(data, DepositWire, DepositPartnerBank)
+	*m = v
+	return err
+}
+
+// DepositStatus is the progress of a deposit. Stockal may add statuses, so
+// other values should be expected.
+type DepositStatus string
+
+// Deposit statuses.
+const (
+	// DepositPending is a deposit announced but not yet received
+	DepositPending DepositStatus = "pending"
+	// DepositReceived is a deposit received but not yet credited to the account
+	DepositReceived DepositStatus = "received"
+	// DepositCompleted is a deposit credited to the account
+	DepositCompleted DepositStatus = "completed"
+	// DepositFailed is a deposit returned to the sender or never received
+	DepositFailed DepositStatus = "failed"
+)
+
+// IsKnown reports whether s is one of the declared deposit statuses.
+func (s DepositStatus) IsKnown() bool {
+	switch s {
+	case DepositPending, DepositReceived, DepositCompleted, DepositFailed:
+		return true
+	}
+	return false
+}
+
+// IsPending reports whether a deposit with status s may still be credited.
+// Unknown statuses are not.
+func (s DepositStatus) IsPending() bool {
+	return s == DepositPending || s == DepositReceived
+}
+
+// UnmarshalJSON decodes a deposit status, matching the declared ones
+// regardless of case and surrounding space. Unknown statuses are kept as sent.
+func (s *DepositStatus) UnmarshalJSON(data []byte) error {
+	v, err := decodeEnum(data, DepositPending, DepositReceived, DepositCompleted, DepositFailed)
+	*s = v
+	return err
+}
+
+// panPattern matches an Indian Permanent Account Number.
+var panPattern = regexp.MustCompile(`^[A-Z]{5}[0-9]{4}[A-Z]$`)
+
+// DepositRequest represents the request payload for announcing a deposit, so
+// that Stockal can match the remittance to the account and report it under
+// the Liberalised Remittance Scheme.
+type DepositRequest struct {
+	// Amount is the amount to be remitted, in US dollars
+	Amount float64 `json:"amount"`
+	// Method is how the money is sent
+	Method DepositMethod `json:"method"`
+	// PAN is the remitter's Permanent Account Number, required for LRS reporting
+	PAN string `json:"pan"`
+	// PurposeCode is the RBI purpose code of the remittance; S0001, investment abroad in equity, if empty
+	PurposeCode string `json:"purposeCode"`
+	// RemittingBank is the Indian bank sending the money
+	RemittingBank string `json:"remittingBank,omitempty"`
+	// SourceOfFunds is where the money comes from, as declared on the A2 form (e.g., "savings")
+	SourceOfFunds string `json:"sourceOfFunds,omitempty"`
+}
+
+// Validate checks the request before it is sent. The returned error wraps
+// ErrInvalidTransfer.
+func (r DepositRequest) Validate() error {
+	var problems []string
+	if !(r.Amount > 0) {
+		problems = append(problems, "amount must be positive")
+	}
+	if !r.Method.IsKnown() {
+		problems = append(problems, fmt.Sprintf("unknown method %q", r.Method))
+	}
+	if !panPattern.MatchString(strings.ToUpper(strings.TrimSpace(r.PAN))) {
+		problems = append(problems, "a valid PAN is required")
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("%w: %s", ErrInvalidTransfer, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// Deposit represents a deposit and its progress.
+type Deposit struct {
+	// ID is the deposit identifier
+	ID string `json:"depositID"`
+	// Amount is the amount announced, in US dollars
+	Amount float64 `json:"amount"`
+	// AmountReceived is the amount credited, in US dollars, after bank charges
+	AmountReceived float64 `json:"amountReceived,omitempty"`
+	// Method is how the money is sent
+	Method DepositMethod `json:"method"`
+	// Status is the progress of the deposit
+	Status DepositStatus `json:"status"`
+	// Reference is the text to quote on the remittance
+	Reference string `json:"reference,omitempty"`
+	// AmountINR is the amount debited by the remitting bank, in rupees
+	AmountINR float64 `json:"amountINR,omitempty"`
+	// TCS is the tax collected at source by the remitting bank, in rupees
+	TCS float64 `json:"tcs,omitempty"`
+	// CreatedAt is when the deposit was announced
+	CreatedAt Time `json:"createdAt,omitzero"`
+	// CreditedAt is when the deposit was credited to the account
+	CreditedAt Time `json:"creditedAt,omitzero"`
+	// Extra holds the fields the API sent that this struct does not model
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// DepositResponse represents the response from the deposit APIs.
+type DepositResponse struct {
+	// Code is the HTTP response code
+	Code int `json:"code"`
+	// Message is the response message (usually "Success")
+	Message string `json:"message"`
+	// Data contains the deposit
+	Data Deposit `json:"data"`
+}
+
+func (r *DepositResponse) validate() []string {
+	if r.Data.ID == "" {
+		return []string{"missing deposit ID"}
+	}
+	return nil
+}
+
+// GetFundingInstructions retrieves the bank details to send money to the
+// account, and how much of the yearly LRS limit has been used.
+//
+// Example:
+//
+//	instructions, err := client.GetFundingInstructions(ctx)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	fmt.Printf("Wire to %s, SWIFT %s, quoting %q\n", instructions.Data.BankName, instructions.Data.SwiftCode, instructions.Data.Reference)
+//	fmt.Printf("LRS remaining this year: $%.0f\n", instructions.Data.LRS.Remaining())
+func (c *Client) GetFundingInstructions(ctx context.Context) (*FundingInstructionsResponse, error) {
+	var instructionsResp FundingInstructionsResponse
+	if err := c.do(ctx, "GET", "/v2/funding/instructions", nil, &instructionsResp, "funding instructions"); err != nil {
+		return nil, err
+	}
+	return &instructionsResp, nil
+}
+
+// CreateDeposit announces a deposit before the money is sent, so that Stockal
+// can match the remittance to the account. The request is validated before it
+// is sent. Send the money as GetFundingInstructions says, quoting the
+// Reference of the deposit returned, and follow it with GetDepositStatus.
+//
+// Example:
+//
+//	resp, err := client.CreateDeposit(ctx, stockal.DepositRequest{
+//		Amount: 1000,
+//		Method: stockal.DepositWire,
+//		PAN:    "ABCDE1234F",
+//	})
+func (c *Client) CreateDeposit(ctx context.Context, deposit DepositRequest) (*DepositResponse, error) {
+	if err := deposit.Validate(); err != nil {
+		return nil, err
+	}
+	deposit.PAN = strings.ToUpper(strings.TrimSpace(deposit.PAN))
+	if deposit.PurposeCode == "" {
+		deposit.PurposeCode = PurposeCodeEquity
+	}
+
+	var depositResp DepositResponse
+	if err := c.do(ctx, "POST", "/v2/funding/deposits", deposit, &depositResp, "create deposit"); err != nil {
+		return nil, err
+	}
+	return &depositResp, nil
+}
+
+// GetDepositStatus retrieves a deposit and its progress.
+func (c *Client) GetDepositStatus(ctx context.Context, depositID string) (*DepositResponse, error) {
+	if depositID == "" {
+		return nil, fmt.Errorf("%w: deposit ID is required", ErrInvalidTransfer)
+	}
+
+	var depositResp DepositResponse
+	if err := c.do(ctx, "GET", "/v2/funding/deposits/"+url.PathEscape(depositID), nil, &depositResp, "deposit status"); err != nil {
+		return nil, err
+	}
+	return &depositResp, nil
+}
+
+// WithdrawalManager is implemented by clients that can send money from the
+// account back to the user's bank.
+type WithdrawalManager interface {
+	RequestWithdrawal(ctx context.Context, amount float64, bankAccountID string) (*WithdrawalResponse, error)
+	GetWithdrawals(ctx context.Context) (*WithdrawalsResponse, error)
+}
+
+// WithdrawalStatus is the progress of a withdrawal. Stockal may add statuses,
+// so other values should be expected.
+type WithdrawalStatus string
+
+// Withdrawal statuses.
+const (
+	// WithdrawalPending is a withdrawal requested but not yet sent
+	WithdrawalPending WithdrawalStatus = "pending"
+	// WithdrawalProcessed is a withdrawal sent to the user's bank
+	WithdrawalProcessed WithdrawalStatus = "processed"
+	// WithdrawalRejected is a withdrawal refused, with the money left in the account
+	WithdrawalRejected WithdrawalStatus = "rejected"
+)
+
+// IsKnown reports whether s is one of the declared withdrawal statuses.
+func (s WithdrawalStatus) IsKnown() bool {
+	switch s {
+	case WithdrawalPending, WithdrawalProcessed, WithdrawalRejected:
+		return true
+	}
+	return false
+}
+
+// UnmarshalJSON decodes a withdrawal status, matching the declared ones
+// regardless of case and surrounding space. Unknown statuses are kept as sent.
+func (s *WithdrawalStatus) UnmarshalJSON(data []byte) error {
+	v, err := decodeEnum(data, WithdrawalPending, WithdrawalProcessed, WithdrawalRejected)
+	*s = v
+	return err
+}
+
+// withdrawalRequest represents the request payload for a withdrawal.
+type withdrawalRequest struct {
+	// Amount is the amount to withdraw, in US dollars
+	Amount float64 `json:"amount"`
+	// BankAccountID is the user's linked bank account to send the money to
+	BankAccountID string `json:"bankAccountID"`
+}
+
+// Withdrawal represents a withdrawal and its progress.
+type Withdrawal struct {
+	// ID is the withdrawal identifier
+	ID string `json:"withdrawalID"`
+	// Amount is the amount withdrawn, in US dollars
+	Amount float64 `json:"amount"`
+	// Fee is the charge deducted for the withdrawal, in US dollars
+	Fee float64 `json:"fee,omitempty"`
+	// BankAccountID is the linked bank account the money is sent to
+	BankAccountID string `json:"bankAccountID"`
+	// Status is the progress of the withdrawal
+	Status WithdrawalStatus `json:"status"`
+	// Reason explains a rejection
+	Reason string `json:"reason,omitempty"`
+	// CreatedAt is when the withdrawal was requested
+	CreatedAt Time `json:"createdAt,omitzero"`
+	// ProcessedAt is when the money was sent
+	ProcessedAt Time `json:"processedAt,omitzero"`
+	// Extra holds the fields the API sent that this struct does not model
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// WithdrawalResponse represents the response from the withdrawal request API.
+type WithdrawalResponse struct {
+	// Code is the HTTP response code
+	Code int `json:"code"`
+	// Message is the response message (usually "Success")
+	Message string `json:"message"`
+	// Data contains the withdrawal
+	Data Withdrawal `json:"data"`
+}
+
+func (r *WithdrawalResponse) validate() []string {
+	if r.Data.ID == "" {
+		return []string{"missing withdrawal ID"}
+	}
+	return nil
+}
+
+// WithdrawalsResponse represents the response from the withdrawal list API.
+type WithdrawalsResponse struct {
+	// Code is the HTTP response code
+	Code int `json:"code"`
+	// Message is the response message (usually "Success")
+	Message string `json:"message"`
+	// Data contains the withdrawals, newest first
+	Data []Withdrawal `json:"data"`
+}
+
+func (r *WithdrawalsResponse) normalize() {
+	if r.Data == nil {
+		r.Data = []Withdrawal{}
+	}
+}
+
+// RequestWithdrawal asks for amount US dollars to be sent from the account to
+// a linked bank account. The amount is checked against the account summary's
+// CashAvailableForWithdrawal first, which costs one request, and a larger
+// amount returns an error wrapping ErrInvalidTransfer without requesting
+// anything. Track the withdrawal with GetWithdrawals.
+//
+// Example:
+//
+//	resp, err := client.RequestWithdrawal(ctx, 500, bankAccountID)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	fmt.Println("withdrawal", resp.Data.ID, resp.Data.Status)
+func (c *Client) RequestWithdrawal(ctx context.Context, amount float64, bankAccountID string) (*WithdrawalResponse, error) {
+	var problems []string
+	if !(amount > 0) {
+		problems = append(problems, "amount must be positive")
+	}
+	if bankAccountID == "" {
+		problems = append(problems, "bank account ID is required")
+	}
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidTransfer, strings.Join(problems, "; "))
+	}
+
+	summary, err := c.Account().Summary(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if available := summary.Data.AccountSummary.CashAvailableForWithdrawal; amount > available {
+		return nil, fmt.Errorf("%w: $%.2f exceeds the $%.2f available for withdrawal", ErrInvalidTransfer, amount, available)
+	}
+
+	var withdrawalResp WithdrawalResponse
+	payload := withdrawalRequest{Amount: amount, BankAccountID: bankAccountID}
+	if err := c.do(ctx, "POST", "/v2/funding/withdrawals", payload, &withdrawalResp, "request withdrawal"); err != nil {
+		return nil, err
+	}
+	return &withdrawalResp, nil
+}
+
+// GetWithdrawals retrieves the account's withdrawals and their progress,
+// newest first.
+func (c *Client) GetWithdrawals(ctx context.Context) (*WithdrawalsResponse, error) {
+	var withdrawalsResp WithdrawalsResponse
+	if err := c.do(ctx, "GET", "/v2/funding/withdrawals", nil, &withdrawalsResp, "list withdrawals"); err != nil {
+		return nil, err
+	}
+	return &withdrawalsResp, nil
+}
@@ -0,0 +1,165 @@
+package stockal_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+)
+
+func TestGetFundingInstructions(t *testing.T) {
+	client := newTestClient(t, map[string]string{
+		"/v2/funding/instructions": `{"code":200,"message":"Success","data":{"beneficiaryName":"Jane Doe","bankName":"Evolve Bank",` +
+			`"accountNumber":"123456789","swiftCode":"FRNAUS44","reference":"ST-42",` +
+			`"lrs":{"financialYear":"2024-25","limit":250000,"remitted":260000},"intermediaryBank":"Citibank"}}`,
+	})
+
+	resp, err := client.GetFundingInstructions(context.Background())
+	if err != nil {
+		t.Fatalf("GetFundingInstructions: %v", err)
+	}
+	in := resp.Data
+	if in.SwiftCode != "FRNAUS44" || in.Reference != "ST-42" || string(in.Extra["intermediaryBank"]) != `"Citibank"` {
+		t.Errorf("got %+v", in)
+	}
+	if in.LRS.Remaining() != 0 {
+		t.Errorf("Remaining() over the limit = %v, want 0", in.LRS.Remaining())
+	}
+	if got := (stockal.LRSUsage{Limit: 250000, Remitted: 10000}).Remaining(); got != 240000 {
+		t.Errorf("Remaining() = %v, want 240000", got)
+	}
+}
+
+func TestDeposits(t *testing.T) {
+	var requests []string
+	client := newHandlerClient(t, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests = append(requests, r.Method+" "+r.URL.Path+" "+string(body))
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"code":200,"message":"Success","data":{"depositID":"d1","amount":1000,"amountReceived":985,"method":"WIRE",` +
+			`"status":"Received","reference":"ST-42-d1","amountINR":84000,"createdAt":"2024-03-01T09:00:00Z","utr":"SBIN0001"}}`))
+	})
+	ctx := context.Background()
+
+	resp, err := client.CreateDeposit(ctx, stockal.DepositRequest{Amount: 1000, Method: stockal.DepositWire, PAN: " abcde1234f "})
+	if err != nil {
+		t.Fatalf("CreateDeposit: %v", err)
+	}
+	d := resp.Data
+	if d.ID != "d1" || d.Method != stockal.DepositWire || d.Status != stockal.DepositReceived || !d.Status.IsPending() ||
+		string(d.Extra["utr"]) != `"SBIN0001"` {
+		t.Errorf("CreateDeposit = %+v", d)
+	}
+	if !d.CreatedAt.Time.Equal(time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)) || !d.CreditedAt.Time.IsZero() {
+		t.Errorf("CreatedAt = %v, CreditedAt = %v", d.CreatedAt.Time, d.CreditedAt.Time)
+	}
+	if _, err := client.GetDepositStatus(ctx, "d1"); err != nil {
+		t.Errorf("GetDepositStatus: %v", err)
+	}
+	want := []string{
+		`POST /v2/funding/deposits {"amount":1000,"method":"wire","pan":"ABCDE1234F","purposeCode":"S0001"}`,
+		`GET /v2/funding/deposits/d1 `,
+	}
+	if !slices.Equal(requests, want) {
+		t.Errorf("sent %q, want %q", requests, want)
+	}
+
+	requests = nil
+	valid := stockal.DepositRequest{Amount: 1000, Method: stockal.DepositWire, PAN: "ABCDE1234F"}
+	for name, change := range map[string]func(*stockal.DepositRequest){
+		"no amount":      func(r *stockal.DepositRequest) { r.Amount = 0 },
+		"NaN amount":     func(r *stockal.DepositRequest) { r.Amount = math.NaN() },
+		"unknown method": func(r *stockal.DepositRequest) { r.Method = "cheque" },
+		"no PAN":         func(r *stockal.DepositRequest) { r.PAN = "" },
+		"malformed PAN":  func(r *stockal.DepositRequest) { r.PAN = "ABCDE12345" },
+	} {
+		req := valid
+		change(&req)
+		if _, err := client.CreateDeposit(ctx, req); !errors.Is(err, stockal.ErrInvalidTransfer) {
+			t.Errorf("CreateDeposit with %s: error = %v, want ErrInvalidTransfer", name, err)
+		}
+	}
+	if _, err := client.GetDepositStatus(ctx, ""); !errors.Is(err, stockal.ErrInvalidTransfer) {
+		t.Errorf("GetDepositStatus without an ID: error = %v, want ErrInvalidTransfer", err)
+	}
+	if len(requests) > 0 {
+		t.Errorf("invalid deposits sent %q", requests)
+	}
+}
+
+func TestWithdrawals(t *testing.T) {
+	var requests []string
+	client := newHandlerClient(t, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests = append(requests, r.Method+" "+r.URL.Path+" "+string(body))
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/v2/users/accountSummary/summary":
+			w.Write([]byte(`{"code":200,"message":"Success","data":{"accountSummary":{"cashBalance":900,"cashAvailableForWithdrawal":600}}}`))
+		case "/v2/funding/withdrawals":
+			if r.Method == http.MethodPost {
+				w.Write([]byte(`{"code":200,"message":"Success","data":{"withdrawalID":"w2","amount":500,"bankAccountID":"b1","status":"PENDING"}}`))
+				return
+			}
+			w.Write([]byte(`{"code":200,"message":"Success","data":[` +
+				`{"withdrawalID":"w1","amount":200,"fee":5,"bankAccountID":"b1","status":"rejected","reason":"bank details mismatch",` +
+				`"createdAt":"2024-02-01T10:00:00Z","swift":"MT103"}]}`))
+		}
+	})
+	ctx := context.Background()
+
+	resp, err := client.RequestWithdrawal(ctx, 500, "b1")
+	if err != nil {
+		t.Fatalf("RequestWithdrawal: %v", err)
+	}
+	if resp.Data.ID != "w2" || resp.Data.Status != stockal.WithdrawalPending {
+		t.Errorf("RequestWithdrawal = %+v", resp.Data)
+	}
+	list, err := client.GetWithdrawals(ctx)
+	if err != nil {
+		t.Fatalf("GetWithdrawals: %v", err)
+	}
+	if len(list.Data) != 1 {
+		t.Fatalf("got %+v", list.Data)
+	}
+	w := list.Data[0]
+	if w.Status != stockal.WithdrawalRejected || w.Reason != "bank details mismatch" || string(w.Extra["swift"]) != `"MT103"` ||
+		!w.CreatedAt.Time.Equal(time.Date(2024, 2, 1, 10, 0, 0, 0, time.UTC)) || !w.ProcessedAt.Time.IsZero() {
+		t.Errorf("got %+v", w)
+	}
+	want := []string{
+		`GET /v2/users/accountSummary/summary `,
+		`POST /v2/funding/withdrawals {"amount":500,"bankAccountID":"b1"}`,
+		`GET /v2/funding/withdrawals `,
+	}
+	if !slices.Equal(requests, want) {
+		t.Errorf("sent %q, want %q", requests, want)
+	}
+
+	requests = nil
+	for name, err := range map[string]error{
+		"no amount":       second(client.RequestWithdrawal(ctx, 0, "b1")),
+		"NaN amount":      second(client.RequestWithdrawal(ctx, math.NaN(), "b1")),
+		"no bank account": second(client.RequestWithdrawal(ctx, 100, "")),
+	} {
+		if !errors.Is(err, stockal.ErrInvalidTransfer) {
+			t.Errorf("RequestWithdrawal with %s: error = %v, want ErrInvalidTransfer", name, err)
+		}
+	}
+	if len(requests) > 0 {
+		t.Errorf("invalid withdrawals sent %q", requests)
+	}
+
+	if _, err := client.RequestWithdrawal(ctx, 600.01, "b1"); !errors.Is(err, stockal.ErrInvalidTransfer) {
+		t.Errorf("RequestWithdrawal over the cash available: error = %v, want ErrInvalidTransfer", err)
+	}
+	if want := []string{`GET /v2/users/accountSummary/summary `}; !slices.Equal(requests, want) {
+		t.Errorf("withdrawal over the cash available sent %q, want %q", requests, want)
+	}
+}
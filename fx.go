@@ -0,0 +1,88 @@
+package stockal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrRateUnavailable is returned when an FxProvider has no rate for the
+// requested date.
+var ErrRateUnavailable = errors.New("exchange rate unavailable")
+
+// FxRate is the US dollar to Indian rupee exchange rate on a date.
+type FxRate struct {
+	// Date is the date the rate was published for
+	Date time.Time `json:"date"`
+	// Rate is the number of rupees per dollar
+	Rate float64 `json:"rate"`
+	// Source names the provider of the rate (e.g. "stockal", "rbi")
+	Source string `json:"source"`
+}
+
+// FxProvider supplies USD/INR exchange rates, for valuing holdings in rupees
+// and for tax computations.
+//
+// USDINR returns the rate in effect on date: the rate published for that day
+// or, on holidays, the most recent earlier one. It returns an error wrapping
+// ErrRateUnavailable when it has none.
+type FxProvider interface {
+	USDINR(ctx context.Context, date time.Time) (FxRate, error)
+}
+
+// FxRateResponse represents the response from the exchange rate API.
+type FxRateResponse struct {
+	// Code is the HTTP response code
+	Code int `json:"code"`
+	// Message is the response message (usually "Success")
+	Message string `json:"message"`
+	// Data contains the rate
+	Data FxRateData `json:"data"`
+}
+
+// FxRateData represents the data payload of an exchange rate response.
+type FxRateData struct {
+	// Rate is the number of rupees Stockal charges per dollar
+	Rate float64 `json:"rate"`
+	// UpdatedAt is when Stockal last updated the rate
+	UpdatedAt Time `json:"updatedAt"`
+}
+
+func (r *FxRateResponse) validate() []string {
+	if r.Data.Rate <= 0 {
+		return []string{"data.rate is missing"}
+	}
+	return nil
+}
+
+// GetFxRate retrieves the USD/INR rate Stockal currently applies to deposits.
+func (c *Client) GetFxRate(ctx context.Context) (*FxRateResponse, error) {
+	var fxResp FxRateResponse
+	if err := c.do(ctx, "GET", "/v2/market/fx/USDINR", nil, &fxResp, "fx rate"); err != nil {
+		return nil, err
+	}
+	return &fxResp, nil
+}
+
+// StockalFx returns an FxProvider using the rate Stockal applies. Stockal only
+// publishes its current rate, so the provider answers for today alone.
+func StockalFx(client MarketData) FxProvider {
+	return stockalFx{client: client}
+}
+
+type stockalFx struct {
+	client MarketData
+}
+
+func (p stockalFx) USDINR(ctx context.Context, date time.Time) (FxRate, error) {
+	now := time.Now().In(date.Location())
+	if y, m, d := date.Date(); !(y == now.Year() && m == now.Month() && d == now.Day()) {
+		return FxRate{}, fmt.Errorf("stockal: %w for %s: only today's rate is available", ErrRateUnavailable, date.Format(time.DateOnly))
+	}
+	resp, err := p.client.GetFxRate(ctx)
+	if err != nil {
+		return FxRate{}, err
+	}
+	return FxRate{Date: now, Rate: resp.Data.Rate, Source: "stockal"}, nil
+}
@@ -0,0 +1,64 @@
+package stockal_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+	"github.com/adjaecent/unofficial-stockal-api/stockaltest"
+)
+
+func TestGetFxRate(t *testing.T) {
+	client := newResponseClient(t, http.StatusOK, "application/json", `{"code":200,"message":"Success","data":{"rate":87.15,"updatedAt":"2025-03-14T09:00:00Z"}}`)
+	resp, err := client.GetFxRate(context.Background())
+	if err != nil {
+		t.Fatalf("GetFxRate: %v", err)
+	}
+	if resp.Data.Rate != 87.15 || !resp.Data.UpdatedAt.Equal(time.Date(2025, 3, 14, 9, 0, 0, 0, time.UTC)) {
+		t.Errorf("rate %+v, want 87.15 updated at 09:00 UTC", resp.Data)
+	}
+
+	for _, body := range []string{
+		`{"code":200,"message":"Success","data":{"updatedAt":"2025-03-14T09:00:00Z"}}`,
+		`{"code":200,"message":"Success","data":{"rate":-1}}`,
+	} {
+		client := newResponseClient(t, http.StatusOK, "application/json", body)
+		if _, err := client.GetFxRate(context.Background()); !errors.Is(err, stockal.ErrMalformedResponse) {
+			t.Errorf("GetFxRate of %s = %v, want ErrMalformedResponse", body, err)
+		}
+	}
+}
+
+func TestStockalFx(t *testing.T) {
+	client := &stockaltest.MockClient{}
+	fx := stockal.StockalFx(client)
+
+	ist := time.FixedZone("IST", 5*60*60+30*60)
+	today := time.Now().In(ist)
+	rate, err := fx.USDINR(context.Background(), today)
+	if err != nil {
+		t.Fatalf("USDINR today: %v", err)
+	}
+	if rate.Rate != 87.15 || rate.Source != "stockal" || rate.Date.Location() != ist {
+		t.Errorf("rate %+v, want Stockal's 87.15 dated today in the zone asked", rate)
+	}
+
+	calls := len(client.Calls())
+	_, err = fx.USDINR(context.Background(), today.AddDate(0, 0, -1))
+	if !errors.Is(err, stockal.ErrRateUnavailable) {
+		t.Errorf("USDINR yesterday = %v, want ErrRateUnavailable", err)
+	}
+	if len(client.Calls()) != calls {
+		t.Error("USDINR asked Stockal for a rate it does not publish")
+	}
+
+	client.GetFxRateFunc = func(ctx context.Context) (*stockal.FxRateResponse, error) {
+		return nil, stockal.ErrServerError
+	}
+	if _, err := fx.USDINR(context.Background(), today); !errors.Is(err, stockal.ErrServerError) {
+		t.Errorf("USDINR when Stockal fails = %v, want its error", err)
+	}
+}
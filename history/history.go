@@ -0,0 +1,116 @@
+// Package history records portfolio snapshots over time.
+//
+// Store is implemented by backends in subpackages, such as history/sqlite:
+//
+//	store, err := sqlite.Open("stockal.db")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	defer store.Close()
+//
+//	snapshot, err := stockal.TakeSnapshot(ctx, client)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	id, err := store.Save(ctx, snapshot)
+//
+// Stores that implement TransactionStore, such as history/sqlite and
+// history/postgres, also keep the account's transactions, so that deposits,
+// dividends and fees can be set against the values recorded.
+//
+// historytest.TestStore checks a backend against the Store contract.
+package history
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+)
+
+// ErrNotFound is returned when a requested snapshot does not exist.
+var ErrNotFound = errors.New("snapshot not found")
+
+// Entry summarizes a stored snapshot.
+type Entry struct {
+	// ID identifies the snapshot within the store
+	ID int64 `json:"id"`
+	// TakenAt is when the snapshot was taken
+	TakenAt time.Time `json:"takenAt"`
+	// TotalValue is the current value of all holdings
+	TotalValue float64 `json:"totalValue"`
+	// TotalInvested is the amount invested across all holdings
+	TotalInvested float64 `json:"totalInvested"`
+	// Cash is the cash available for trading
+	Cash float64 `json:"cash"`
+	// Holdings is the number of holdings in the snapshot
+	Holdings int `json:"holdings"`
+}
+
+// Point is a holding's position at the time of one snapshot.
+type Point struct {
+	// TakenAt is when the snapshot was taken
+	TakenAt time.Time `json:"takenAt"`
+	// Units is the number of units held
+	Units float64 `json:"units"`
+	// Price is the price per unit
+	Price float64 `json:"price"`
+	// Value is the market value of the holding
+	Value float64 `json:"value"`
+	// Invested is the amount invested in the holding
+	Invested float64 `json:"invested"`
+}
+
+// Store persists snapshots.
+type Store interface {
+	// Save stores a snapshot and returns its ID.
+	Save(ctx context.Context, s *stockal.Snapshot) (int64, error)
+	// Get returns the snapshot with the given ID, or ErrNotFound.
+	Get(ctx context.Context, id int64) (*stockal.Snapshot, error)
+	// List summarizes the snapshots taken in [from, to), oldest first. A zero
+	// from or to leaves that end of the range open.
+	List(ctx context.Context, from, to time.Time) ([]Entry, error)
+	// Holding returns the position in symbol at each snapshot in [from, to)
+	// that held it, oldest first.
+	Holding(ctx context.Context, symbol string, from, to time.Time) ([]Point, error)
+	// Prune deletes snapshots taken before the given time and returns how many
+	// were deleted.
+	Prune(ctx context.Context, before time.Time) (int64, error)
+	// Close releases the store's resources.
+	Close() error
+}
+
+// TransactionStore is implemented by stores that also keep the account's
+// transactions.
+type TransactionStore interface {
+	// SaveTransactions stores transactions, replacing any stored with the same
+	// ID, and returns how many were not stored before. It fails if a
+	// transaction's date cannot be parsed.
+	SaveTransactions(ctx context.Context, transactions []stockal.Transaction) (int, error)
+	// Transactions returns the transactions dated in [from, to), oldest first.
+	// A zero from or to leaves that end of the range open.
+	Transactions(ctx context.Context, from, to time.Time) ([]stockal.Transaction, error)
+}
+
+// TransactionTime returns when tx happened, for stores indexing transactions
+// by date.
+func TransactionTime(tx stockal.Transaction) (time.Time, error) {
+	if tx.Date.IsZero() {
+		return time.Time{}, fmt.Errorf("transaction %s: invalid date %q", tx.ID, tx.Date.Raw)
+	}
+	return tx.Date.Time, nil
+}
+
+// NewEntry summarizes a snapshot.
+func NewEntry(id int64, s *stockal.Snapshot) Entry {
+	return Entry{
+		ID:            id,
+		TakenAt:       s.TakenAt,
+		TotalValue:    s.Summary.PortfolioSummary.TotalCurrentValue,
+		TotalInvested: s.Summary.PortfolioSummary.TotalInvestmentAmount,
+		Cash:          s.Summary.AccountSummary.CashAvailableForTrade,
+		Holdings:      len(s.Holdings),
+	}
+}
@@ -0,0 +1,119 @@
+// Package historytest checks history.Store implementations against the
+// behavior the interface documents.
+//
+//	func TestStore(t *testing.T) {
+//		store, err := mystore.Open(t.TempDir())
+//		if err != nil {
+//			t.Fatal(err)
+//		}
+//		defer store.Close()
+//		historytest.TestStore(t, store)
+//	}
+package historytest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+	"github.com/adjaecent/unofficial-stockal-api/history"
+)
+
+// day returns 15:00 UTC on the given day of May 2025, during the session.
+func day(d int) time.Time {
+	return time.Date(2025, time.May, d, 15, 0, 0, 0, time.UTC)
+}
+
+// snapshot returns a snapshot taken on the given day, holding AAPL and, on
+// the 2nd, VOO.
+func snapshot(d int) *stockal.Snapshot {
+	s := &stockal.Snapshot{TakenAt: day(d)}
+	s.Summary.AccountSummary.CashAvailableForTrade = 100 * float64(d)
+	s.Summary.PortfolioSummary.TotalCurrentValue = 1000 * float64(d)
+	s.Summary.PortfolioSummary.TotalInvestmentAmount = 900
+	s.Holdings = []stockal.Holding{{Symbol: "AAPL", TotalUnit: float64(d), Price: 200, TotalInvestment: 150 * float64(d)}}
+	if d == 2 {
+		s.Holdings = append(s.Holdings, stockal.Holding{Symbol: "VOO", TotalUnit: 1, Price: 500, TotalInvestment: 450})
+	}
+	return s
+}
+
+// TestStore saves snapshots taken on three days to store, which must be
+// empty, and checks that they are read back, listed, tracked by holding and
+// pruned as history.Store documents.
+func TestStore(t *testing.T, store history.Store) {
+	t.Helper()
+	ctx := context.Background()
+
+	// Save out of order, so that listing has to sort
+	ids := map[int]int64{}
+	for _, d := range []int{3, 1, 2} {
+		id, err := store.Save(ctx, snapshot(d))
+		if err != nil {
+			t.Fatalf("Save: %v", err)
+		}
+		ids[d] = id
+	}
+
+	got, err := store.Get(ctx, ids[2])
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if !got.TakenAt.Equal(day(2)) || len(got.Holdings) != 2 || got.Summary.PortfolioSummary.TotalCurrentValue != 2000 {
+		t.Errorf("Get = %+v, want the snapshot of the 2nd", got)
+	}
+	if _, err := store.Get(ctx, max(ids[1], ids[2], ids[3])+1); !errors.Is(err, history.ErrNotFound) {
+		t.Errorf("Get of an unknown ID = %v, want history.ErrNotFound", err)
+	}
+
+	entries, err := store.List(ctx, time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("List = %+v, want 3 entries", entries)
+	}
+	for i, e := range entries {
+		d := i + 1
+		want := history.NewEntry(ids[d], snapshot(d))
+		if e.ID != want.ID || !e.TakenAt.Equal(want.TakenAt) || e.TotalValue != want.TotalValue ||
+			e.TotalInvested != want.TotalInvested || e.Cash != want.Cash || e.Holdings != want.Holdings {
+			t.Errorf("entry %d = %+v, want %+v", i, e, want)
+		}
+	}
+	if entries, err := store.List(ctx, day(2), day(3)); err != nil || len(entries) != 1 || entries[0].ID != ids[2] {
+		t.Errorf("List from the 2nd to the 3rd = %+v, %v; want the 2nd only", entries, err)
+	}
+
+	points, err := store.Holding(ctx, "aapl", time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatalf("Holding: %v", err)
+	}
+	if len(points) != 3 {
+		t.Fatalf("Holding = %+v, want AAPL in all 3 snapshots", points)
+	}
+	want := history.Point{TakenAt: day(3), Units: 3, Price: 200, Value: 600, Invested: 450}
+	if p := points[2]; !p.TakenAt.Equal(want.TakenAt) || p.Units != want.Units || p.Price != want.Price ||
+		p.Value != want.Value || p.Invested != want.Invested {
+		t.Errorf("last AAPL point = %+v, want %+v", p, want)
+	}
+	if points, err := store.Holding(ctx, "VOO", day(1), time.Time{}); err != nil || len(points) != 1 || !points[0].TakenAt.Equal(day(2)) {
+		t.Errorf("Holding(VOO) = %+v, %v; want the 2nd only", points, err)
+	}
+
+	deleted, err := store.Prune(ctx, day(2))
+	if err != nil || deleted != 1 {
+		t.Fatalf("Prune = %d, %v; want 1 deleted", deleted, err)
+	}
+	if entries, err := store.List(ctx, time.Time{}, time.Time{}); err != nil || len(entries) != 2 || entries[0].ID != ids[2] {
+		t.Errorf("List after pruning = %+v, %v; want the 2nd and 3rd", entries, err)
+	}
+	if points, err := store.Holding(ctx, "AAPL", time.Time{}, time.Time{}); err != nil || len(points) != 2 {
+		t.Errorf("Holding after pruning = %+v, %v; want 2 points", points, err)
+	}
+	if _, err := store.Get(ctx, ids[1]); !errors.Is(err, history.ErrNotFound) {
+		t.Errorf("Get of a pruned snapshot = %v, want history.ErrNotFound", err)
+	}
+}
@@ -0,0 +1,22 @@
+CREATE TABLE stockal_snapshots (
+	id             BIGSERIAL        PRIMARY KEY,
+	account        TEXT             NOT NULL,
+	taken_at       TIMESTAMPTZ      NOT NULL,
+	total_value    DOUBLE PRECISION NOT NULL,
+	total_invested DOUBLE PRECISION NOT NULL,
+	cash           DOUBLE PRECISION NOT NULL,
+	holdings       INTEGER          NOT NULL,
+	data           JSONB            NOT NULL
+);
+CREATE INDEX stockal_snapshots_account_taken_at ON stockal_snapshots (account, taken_at);
+
+CREATE TABLE stockal_holdings (
+	snapshot_id BIGINT           NOT NULL REFERENCES stockal_snapshots (id) ON DELETE CASCADE,
+	symbol      TEXT             NOT NULL,
+	units       DOUBLE PRECISION NOT NULL,
+	price       DOUBLE PRECISION NOT NULL,
+	invested    DOUBLE PRECISION NOT NULL,
+	value       DOUBLE PRECISION GENERATED ALWAYS AS (units * price) STORED,
+	PRIMARY KEY (snapshot_id, symbol)
+);
+CREATE INDEX stockal_holdings_symbol ON stockal_holdings (symbol);
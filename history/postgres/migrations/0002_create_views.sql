@@ -0,0 +1,14 @@
+-- One row per account and day with the last snapshot taken that day, for
+-- charting and joins against other daily data.
+CREATE VIEW stockal_daily_values AS
+SELECT DISTINCT ON (account, (taken_at AT TIME ZONE 'America/New_York')::date)
+	account,
+	(taken_at AT TIME ZONE 'America/New_York')::date AS day,
+	id AS snapshot_id,
+	taken_at,
+	total_value,
+	total_invested,
+	total_value - total_invested AS gain,
+	cash
+FROM stockal_snapshots
+ORDER BY account, (taken_at AT TIME ZONE 'America/New_York')::date, taken_at DESC;
@@ -0,0 +1,14 @@
+CREATE TABLE stockal_transactions (
+	account     TEXT             NOT NULL,
+	id          TEXT             NOT NULL,
+	type        TEXT             NOT NULL,
+	symbol      TEXT             NOT NULL,
+	quantity    DOUBLE PRECISION NOT NULL,
+	price       DOUBLE PRECISION NOT NULL,
+	-- cash_flow is the effect on the cash balance, negative for money out
+	cash_flow   DOUBLE PRECISION NOT NULL,
+	occurred_at TIMESTAMPTZ      NOT NULL,
+	data        JSONB            NOT NULL,
+	PRIMARY KEY (account, id)
+);
+CREATE INDEX stockal_transactions_account_occurred_at ON stockal_transactions (account, occurred_at);
@@ -0,0 +1,321 @@
+// Package s3 implements history.Store on S3-compatible object storage, so that
+// serverless jobs can keep history without running a database.
+//
+// Each snapshot is a JSON object named by the day and time it was taken:
+//
+//	<prefix>snapshots/2025/01/31/1738357500000.json
+//
+// The snapshot ID is the time it was taken in Unix milliseconds. Every object
+// lives under one prefix, so a bucket lifecycle rule on <prefix>snapshots/ can
+// expire or archive old snapshots instead of Prune.
+//
+// Open takes credentials, region and endpoint from the usual AWS environment
+// variables and shared configuration. Google Cloud Storage works through its
+// S3-compatible XML API with HMAC keys:
+//
+//	AWS_REGION=auto AWS_ENDPOINT_URL_S3=https://storage.googleapis.com \
+//	AWS_ACCESS_KEY_ID=GOOG... AWS_SECRET_ACCESS_KEY=... stockal-snapshotd run --s3 s3://my-bucket/stockal/
+package s3
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"path"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	awss3 "github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+	"github.com/adjaecent/unofficial-stockal-api/history"
+)
+
+// concurrency limits the objects read or deleted at once by List, Holding
+// and Prune.
+const concurrency = 8
+
+// Store is a history.Store backed by an S3 bucket.
+type Store struct {
+	client    *awss3.Client
+	bucket    string
+	prefix    string
+	endpoint  string
+	pathStyle bool
+}
+
+var _ history.Store = (*Store)(nil)
+
+// Option configures a Store.
+type Option func(*Store)
+
+// WithPrefix stores snapshots under prefix, such as "stockal/", so that one
+// bucket can hold several histories.
+func WithPrefix(prefix string) Option {
+	return func(s *Store) {
+		s.prefix = prefix
+	}
+}
+
+// WithEndpoint makes Open use an S3-compatible service at url, such as MinIO
+// or Google Cloud Storage, instead of AWS.
+func WithEndpoint(url string) Option {
+	return func(s *Store) {
+		s.endpoint = url
+	}
+}
+
+// WithPathStyle makes Open address the bucket in the URL path rather than the
+// host name, as MinIO and most self-hosted services require.
+func WithPathStyle() Option {
+	return func(s *Store) {
+		s.pathStyle = true
+	}
+}
+
+// New returns a store for bucket using client.
+func New(client *awss3.Client, bucket string, opts ...Option) *Store {
+	s := &Store{client: client, bucket: bucket}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Open returns a store for bucket with a client configured from the
+// environment.
+func Open(bucket string, opts ...Option) (*Store, error) {
+	s := New(nil, bucket, opts...)
+	cfg, err := config.LoadDefaultConfig(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	s.client = awss3.NewFromConfig(cfg, func(o *awss3.Options) {
+		if s.endpoint != "" {
+			o.BaseEndpoint = aws.String(s.endpoint)
+		}
+		o.UsePathStyle = s.pathStyle
+		// Other S3 implementations, GCS among them, reject the checksums
+		// the SDK adds by default.
+		o.RequestChecksumCalculation = aws.RequestChecksumCalculationWhenRequired
+		o.ResponseChecksumValidation = aws.ResponseChecksumValidationWhenRequired
+	})
+	return s, nil
+}
+
+// Close implements history.Store. The client holds no resources to release.
+func (s *Store) Close() error {
+	return nil
+}
+
+// Save implements history.Store.
+func (s *Store) Save(ctx context.Context, snapshot *stockal.Snapshot) (int64, error) {
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return 0, err
+	}
+	id := snapshot.TakenAt.UnixMilli()
+	_, err = s.client.PutObject(ctx, &awss3.PutObjectInput{
+		Bucket:      aws.String(s.bucket),
+		Key:         aws.String(s.key(id)),
+		Body:        bytes.NewReader(data),
+		ContentType: aws.String("application/json"),
+	})
+	if err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+// Get implements history.Store.
+func (s *Store) Get(ctx context.Context, id int64) (*stockal.Snapshot, error) {
+	out, err := s.client.GetObject(ctx, &awss3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(s.key(id)),
+	})
+	var noSuchKey *types.NoSuchKey
+	if errors.As(err, &noSuchKey) {
+		return nil, history.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer out.Body.Close()
+
+	var snapshot stockal.Snapshot
+	if err := json.NewDecoder(out.Body).Decode(&snapshot); err != nil {
+		return nil, fmt.Errorf("decode snapshot %d: %w", id, err)
+	}
+	return &snapshot, nil
+}
+
+// List implements history.Store. It reads every snapshot in the range.
+func (s *Store) List(ctx context.Context, from, to time.Time) ([]history.Entry, error) {
+	ids, err := s.ids(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+	found := make([]*history.Entry, len(ids))
+	err = s.each(ctx, ids, func(i int, snapshot *stockal.Snapshot) {
+		e := history.NewEntry(ids[i], snapshot)
+		found[i] = &e
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []history.Entry
+	for _, e := range found {
+		if e != nil {
+			entries = append(entries, *e)
+		}
+	}
+	return entries, nil
+}
+
+// Holding implements history.Store. It reads every snapshot in the range.
+func (s *Store) Holding(ctx context.Context, symbol string, from, to time.Time) ([]history.Point, error) {
+	ids, err := s.ids(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+	points := make([]*history.Point, len(ids))
+	err = s.each(ctx, ids, func(i int, snapshot *stockal.Snapshot) {
+		for _, h := range snapshot.Holdings {
+			if strings.EqualFold(h.Symbol, symbol) {
+				points[i] = &history.Point{
+					TakenAt:  snapshot.TakenAt.UTC(),
+					Units:    h.TotalUnit,
+					Price:    h.Price,
+					Value:    h.CurrentValue(),
+					Invested: h.TotalInvestment,
+				}
+				return
+			}
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var held []history.Point
+	for _, p := range points {
+		if p != nil {
+			held = append(held, *p)
+		}
+	}
+	return held, nil
+}
+
+// Prune implements history.Store. Objects are deleted one by one, since not
+// every S3-compatible service supports batch deletes.
+func (s *Store) Prune(ctx context.Context, before time.Time) (int64, error) {
+	ids, err := s.ids(ctx, time.Time{}, before)
+	if err != nil {
+		return 0, err
+	}
+	var deleted atomic.Int64
+	g, ctx := errgroup.WithContext(ctx)
+	g.SetLimit(concurrency)
+	for _, id := range ids {
+		g.Go(func() error {
+			_, err := s.client.DeleteObject(ctx, &awss3.DeleteObjectInput{
+				Bucket: aws.String(s.bucket),
+				Key:    aws.String(s.key(id)),
+			})
+			if err != nil {
+				return fmt.Errorf("delete snapshot %d: %w", id, err)
+			}
+			deleted.Add(1)
+			return nil
+		})
+	}
+	err = g.Wait()
+	return deleted.Load(), err
+}
+
+// ids returns the IDs of the snapshots taken in [from, to), oldest first. A
+// zero from or to leaves that end of the range open.
+func (s *Store) ids(ctx context.Context, from, to time.Time) ([]int64, error) {
+	lo, hi := int64(math.MinInt64), int64(math.MaxInt64)
+	input := &awss3.ListObjectsV2Input{
+		Bucket: aws.String(s.bucket),
+		Prefix: aws.String(s.prefix + "snapshots/"),
+	}
+	if !from.IsZero() {
+		lo = from.UnixMilli()
+		// Keys sort by day, so listing can skip the days before from.
+		day := from.UTC().AddDate(0, 0, -1).Format("2006/01/02")
+		input.StartAfter = aws.String(s.prefix + "snapshots/" + day + "/~")
+	}
+	if !to.IsZero() {
+		hi = to.UnixMilli()
+	}
+
+	var ids []int64
+	pages := awss3.NewListObjectsV2Paginator(s.client, input)
+	for pages.HasMorePages() {
+		page, err := pages.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, obj := range page.Contents {
+			id, ok := parseKey(aws.ToString(obj.Key))
+			if !ok || id < lo {
+				continue
+			}
+			if id >= hi {
+				// IDs have a fixed width, so later keys are later snapshots.
+				return ids, nil
+			}
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+// each reads the snapshots with the given IDs concurrently and calls fn with
+// each one's index in ids. Snapshots deleted in the meantime are skipped.
+func (s *Store) each(ctx context.Context, ids []int64, fn func(int, *stockal.Snapshot)) error {
+	g, ctx := errgroup.WithContext(ctx)
+	g.SetLimit(concurrency)
+	for i, id := range ids {
+		g.Go(func() error {
+			snapshot, err := s.Get(ctx, id)
+			if errors.Is(err, history.ErrNotFound) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			fn(i, snapshot)
+			return nil
+		})
+	}
+	return g.Wait()
+}
+
+// key returns the object key of the snapshot with the given ID.
+func (s *Store) key(id int64) string {
+	day := time.UnixMilli(id).UTC().Format("2006/01/02")
+	return s.prefix + "snapshots/" + day + "/" + strconv.FormatInt(id, 10) + ".json"
+}
+
+// parseKey returns the snapshot ID in an object key.
+func parseKey(key string) (int64, bool) {
+	name, ok := strings.CutSuffix(path.Base(key), ".json")
+	if !ok {
+		return 0, false
+	}
+	id, err := strconv.ParseInt(name, 10, 64)
+	return id, err == nil
+}
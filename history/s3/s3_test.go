@@ -0,0 +1,132 @@
+package s3_test
+
+import (
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awss3 "github.com/aws/aws-sdk-go-v2/service/s3"
+
+	"github.com/adjaecent/unofficial-stockal-api/history/historytest"
+	"github.com/adjaecent/unofficial-stockal-api/history/s3"
+)
+
+// listPage is how many keys the fake bucket lists at a time, so that listing
+// takes several pages.
+const listPage = 2
+
+// bucket is an in-memory S3 bucket serving the path-style requests the store
+// makes.
+type bucket struct {
+	name string
+
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+type listResult struct {
+	XMLName               xml.Name `xml:"ListBucketResult"`
+	Name                  string
+	Prefix                string
+	KeyCount              int
+	MaxKeys               int
+	IsTruncated           bool
+	NextContinuationToken string `xml:",omitempty"`
+	Contents              []struct{ Key string }
+}
+
+func (b *bucket) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	key, ok := strings.CutPrefix(r.URL.Path, "/"+b.name+"/")
+	switch {
+	case r.Method == http.MethodGet && !ok && r.URL.Query().Get("list-type") == "2":
+		b.list(w, r)
+	case !ok:
+		http.Error(w, "unexpected request", http.StatusBadRequest)
+	case r.Method == http.MethodPut:
+		b.objects[key], _ = io.ReadAll(r.Body)
+	case r.Method == http.MethodGet:
+		data, found := b.objects[key]
+		if !found {
+			w.WriteHeader(http.StatusNotFound)
+			io.WriteString(w, `<?xml version="1.0" encoding="UTF-8"?><Error><Code>NoSuchKey</Code><Message>The specified key does not exist.</Message></Error>`)
+			return
+		}
+		w.Write(data)
+	case r.Method == http.MethodDelete:
+		delete(b.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "unexpected method", http.StatusMethodNotAllowed)
+	}
+}
+
+// list lists the keys under prefix after start-after or the continuation
+// token, in order, a page at a time.
+func (b *bucket) list(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	after := q.Get("start-after")
+	if token := q.Get("continuation-token"); token != "" {
+		after = token
+	}
+	var keys []string
+	for key := range b.objects {
+		if strings.HasPrefix(key, q.Get("prefix")) && key > after {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+
+	result := listResult{Name: b.name, Prefix: q.Get("prefix"), MaxKeys: listPage}
+	if len(keys) > listPage {
+		keys = keys[:listPage]
+		result.IsTruncated, result.NextContinuationToken = true, keys[listPage-1]
+	}
+	result.KeyCount = len(keys)
+	for _, key := range keys {
+		result.Contents = append(result.Contents, struct{ Key string }{key})
+	}
+	w.Header().Set("Content-Type", "application/xml")
+	xml.NewEncoder(w).Encode(result)
+}
+
+func TestStore(t *testing.T) {
+	b := &bucket{name: "history", objects: map[string][]byte{}}
+	server := httptest.NewServer(b)
+	defer server.Close()
+
+	client := awss3.New(awss3.Options{
+		Region:                     "us-east-1",
+		BaseEndpoint:               aws.String(server.URL),
+		UsePathStyle:               true,
+		Credentials:                aws.AnonymousCredentials{},
+		RequestChecksumCalculation: aws.RequestChecksumCalculationWhenRequired,
+		ResponseChecksumValidation: aws.ResponseChecksumValidationWhenRequired,
+	})
+	// Another history under a different prefix must not be seen
+	b.objects["other/snapshots/2025/05/01/1746111600000.json"] = []byte(`{}`)
+
+	historytest.TestStore(t, s3.New(client, "history", s3.WithPrefix("stockal/")))
+
+	var keys []string
+	for key := range b.objects {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	want := []string{
+		"other/snapshots/2025/05/01/1746111600000.json",
+		"stockal/snapshots/2025/05/02/1746198000000.json",
+		"stockal/snapshots/2025/05/03/1746284400000.json",
+	}
+	if !slices.Equal(keys, want) {
+		t.Errorf("bucket holds %q, want %q", keys, want)
+	}
+}
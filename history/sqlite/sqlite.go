@@ -0,0 +1,277 @@
+// Package sqlite implements history.Store on a SQLite database file.
+//
+// It uses github.com/mattn/go-sqlite3 and therefore needs cgo.
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+	"github.com/adjaecent/unofficial-stockal-api/history"
+)
+
+const schema = `
+CREATE TABLE IF NOT EXISTS snapshots (
+	id             INTEGER PRIMARY KEY,
+	taken_at       INTEGER NOT NULL,
+	total_value    REAL    NOT NULL,
+	total_invested REAL    NOT NULL,
+	cash           REAL    NOT NULL,
+	holdings       INTEGER NOT NULL,
+	data           BLOB    NOT NULL
+);
+CREATE INDEX IF NOT EXISTS snapshots_taken_at ON snapshots (taken_at);
+
+CREATE TABLE IF NOT EXISTS holdings (
+	snapshot_id INTEGER NOT NULL REFERENCES snapshots (id) ON DELETE CASCADE,
+	symbol      TEXT    NOT NULL,
+	units       REAL    NOT NULL,
+	price       REAL    NOT NULL,
+	invested    REAL    NOT NULL,
+	PRIMARY KEY (snapshot_id, symbol)
+);
+CREATE INDEX IF NOT EXISTS holdings_symbol ON holdings (symbol);
+
+CREATE TABLE IF NOT EXISTS transactions (
+	id          TEXT    PRIMARY KEY,
+	type        TEXT    NOT NULL,
+	symbol      TEXT    NOT NULL,
+	cash_flow   REAL    NOT NULL,
+	occurred_at INTEGER NOT NULL,
+	data        BLOB    NOT NULL
+);
+CREATE INDEX IF NOT EXISTS transactions_occurred_at ON transactions (occurred_at);
+`
+
+// Store is a history.Store backed by SQLite.
+type Store struct {
+	db *sql.DB
+}
+
+var (
+	_ history.Store            = (*Store)(nil)
+	_ history.TransactionStore = (*Store)(nil)
+)
+
+// Open opens the database at path, creating it and its tables if needed.
+func Open(path string) (*Store, error) {
+	db, err := sql.Open("sqlite3", "file:"+path+"?_foreign_keys=on&_busy_timeout=5000&_journal_mode=WAL")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("create schema: %w", err)
+	}
+	return &Store{db: db}, nil
+}
+
+// Close closes the database.
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Save implements history.Store.
+func (s *Store) Save(ctx context.Context, snapshot *stockal.Snapshot) (int64, error) {
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return 0, err
+	}
+	e := history.NewEntry(0, snapshot)
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx,
+		`INSERT INTO snapshots (taken_at, total_value, total_invested, cash, holdings, data) VALUES (?, ?, ?, ?, ?, ?)`,
+		e.TakenAt.UnixMilli(), e.TotalValue, e.TotalInvested, e.Cash, e.Holdings, data)
+	if err != nil {
+		return 0, fmt.Errorf("save snapshot: %w", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	stmt, err := tx.PrepareContext(ctx,
+		`INSERT OR REPLACE INTO holdings (snapshot_id, symbol, units, price, invested) VALUES (?, ?, ?, ?, ?)`)
+	if err != nil {
+		return 0, err
+	}
+	defer stmt.Close()
+	for _, h := range snapshot.Holdings {
+		if _, err := stmt.ExecContext(ctx, id, h.Symbol, h.TotalUnit, h.Price, h.TotalInvestment); err != nil {
+			return 0, fmt.Errorf("save holding %s: %w", h.Symbol, err)
+		}
+	}
+	return id, tx.Commit()
+}
+
+// Get implements history.Store.
+func (s *Store) Get(ctx context.Context, id int64) (*stockal.Snapshot, error) {
+	var data []byte
+	err := s.db.QueryRowContext(ctx, `SELECT data FROM snapshots WHERE id = ?`, id).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, history.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var snapshot stockal.Snapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, fmt.Errorf("decode snapshot %d: %w", id, err)
+	}
+	return &snapshot, nil
+}
+
+// List implements history.Store.
+func (s *Store) List(ctx context.Context, from, to time.Time) ([]history.Entry, error) {
+	lo, hi := bounds(from, to)
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT id, taken_at, total_value, total_invested, cash, holdings FROM snapshots
+		WHERE taken_at >= ? AND taken_at < ? ORDER BY taken_at`, lo, hi)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []history.Entry
+	for rows.Next() {
+		var e history.Entry
+		var takenAt int64
+		if err := rows.Scan(&e.ID, &takenAt, &e.TotalValue, &e.TotalInvested, &e.Cash, &e.Holdings); err != nil {
+			return nil, err
+		}
+		e.TakenAt = time.UnixMilli(takenAt).UTC()
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+// Holding implements history.Store.
+func (s *Store) Holding(ctx context.Context, symbol string, from, to time.Time) ([]history.Point, error) {
+	lo, hi := bounds(from, to)
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT s.taken_at, h.units, h.price, h.invested FROM holdings h
+		JOIN snapshots s ON s.id = h.snapshot_id
+		WHERE h.symbol = ? AND s.taken_at >= ? AND s.taken_at < ? ORDER BY s.taken_at`,
+		strings.ToUpper(symbol), lo, hi)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var points []history.Point
+	for rows.Next() {
+		var p history.Point
+		var takenAt int64
+		if err := rows.Scan(&takenAt, &p.Units, &p.Price, &p.Invested); err != nil {
+			return nil, err
+		}
+		p.TakenAt = time.UnixMilli(takenAt).UTC()
+		p.Value = p.Units * p.Price
+		points = append(points, p)
+	}
+	return points, rows.Err()
+}
+
+// Prune implements history.Store.
+func (s *Store) Prune(ctx context.Context, before time.Time) (int64, error) {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM snapshots WHERE taken_at < ?`, before.UnixMilli())
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// SaveTransactions implements history.TransactionStore.
+func (s *Store) SaveTransactions(ctx context.Context, transactions []stockal.Transaction) (int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx,
+		`INSERT INTO transactions (id, type, symbol, cash_flow, occurred_at, data) VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET type = excluded.type, symbol = excluded.symbol, cash_flow = excluded.cash_flow,
+			occurred_at = excluded.occurred_at, data = excluded.data`)
+	if err != nil {
+		return 0, err
+	}
+	defer stmt.Close()
+
+	added := 0
+	for _, t := range transactions {
+		at, err := history.TransactionTime(t)
+		if err != nil {
+			return 0, err
+		}
+		data, err := json.Marshal(t)
+		if err != nil {
+			return 0, err
+		}
+		var exists bool
+		if err := tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM transactions WHERE id = ?)`, t.ID).Scan(&exists); err != nil {
+			return 0, err
+		}
+		if _, err := stmt.ExecContext(ctx, t.ID, string(t.Type), strings.ToUpper(t.Symbol), t.CashFlow(), at.UnixMilli(), data); err != nil {
+			return 0, fmt.Errorf("save transaction %s: %w", t.ID, err)
+		}
+		if !exists {
+			added++
+		}
+	}
+	return added, tx.Commit()
+}
+
+// Transactions implements history.TransactionStore.
+func (s *Store) Transactions(ctx context.Context, from, to time.Time) ([]stockal.Transaction, error) {
+	lo, hi := bounds(from, to)
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT id, data FROM transactions WHERE occurred_at >= ? AND occurred_at < ? ORDER BY occurred_at, id`, lo, hi)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var transactions []stockal.Transaction
+	for rows.Next() {
+		var id string
+		var data []byte
+		if err := rows.Scan(&id, &data); err != nil {
+			return nil, err
+		}
+		var t stockal.Transaction
+		if err := json.Unmarshal(data, &t); err != nil {
+			return nil, fmt.Errorf("decode transaction %s: %w", id, err)
+		}
+		transactions = append(transactions, t)
+	}
+	return transactions, rows.Err()
+}
+
+// bounds converts an optional time range to Unix milliseconds.
+func bounds(from, to time.Time) (lo, hi int64) {
+	lo, hi = math.MinInt64, math.MaxInt64
+	if !from.IsZero() {
+		lo = from.UnixMilli()
+	}
+	if !to.IsZero() {
+		hi = to.UnixMilli()
+	}
+	return lo, hi
+}
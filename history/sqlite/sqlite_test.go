@@ -0,0 +1,65 @@
+package sqlite_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+	"github.com/adjaecent/unofficial-stockal-api/history/historytest"
+	"github.com/adjaecent/unofficial-stockal-api/history/sqlite"
+)
+
+func openStore(t *testing.T) *sqlite.Store {
+	t.Helper()
+	store, err := sqlite.Open(filepath.Join(t.TempDir(), "history.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+func TestStore(t *testing.T) {
+	historytest.TestStore(t, openStore(t))
+}
+
+func TestTransactions(t *testing.T) {
+	ctx := context.Background()
+	store := openStore(t)
+
+	deposit := stockal.Transaction{ID: "t1", Type: stockal.TransactionDeposit, Amount: 1000, Date: stockal.NewTime("2025-01-02T10:00:00Z")}
+	buy := stockal.Transaction{ID: "t2", Type: stockal.TransactionBuy, Symbol: "AAPL", Quantity: 2, Price: 150, Amount: 300, Date: stockal.NewTime("2025-01-03T15:00:00Z")}
+	dividend := stockal.Transaction{ID: "t3", Type: stockal.TransactionDividend, Symbol: "AAPL", Amount: 0.5, Date: stockal.NewTime("2025-02-14T00:00:00Z")}
+
+	added, err := store.SaveTransactions(ctx, []stockal.Transaction{dividend, buy, deposit})
+	if err != nil || added != 3 {
+		t.Fatalf("SaveTransactions = %d, %v; want 3 added", added, err)
+	}
+	buy.Description = "Bought 2 AAPL"
+	added, err = store.SaveTransactions(ctx, []stockal.Transaction{buy})
+	if err != nil || added != 0 {
+		t.Fatalf("saving again = %d, %v; want nothing added", added, err)
+	}
+
+	all, err := store.Transactions(ctx, time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatalf("Transactions: %v", err)
+	}
+	if len(all) != 3 || all[0].ID != "t1" || all[1].ID != "t2" || all[2].ID != "t3" {
+		t.Fatalf("got %+v, want the transactions oldest first", all)
+	}
+	if all[1].Description != "Bought 2 AAPL" || all[1].Quantity != 2 {
+		t.Errorf("got %+v, want the replaced purchase", all[1])
+	}
+
+	january, err := store.Transactions(ctx, time.Date(2025, time.January, 3, 0, 0, 0, 0, time.UTC), time.Date(2025, time.February, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil || len(january) != 1 || january[0].ID != "t2" {
+		t.Errorf("Transactions in range = %+v, %v; want the purchase", january, err)
+	}
+
+	if _, err := store.SaveTransactions(ctx, []stockal.Transaction{{ID: "t4", Type: stockal.TransactionFee, Date: stockal.NewTime("someday")}}); err == nil {
+		t.Error("SaveTransactions accepted an invalid date")
+	}
+}
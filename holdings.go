@@ -0,0 +1,211 @@
+package stockal
+
+import (
+	"cmp"
+	"encoding/json"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Category is the asset category of a holding. Stockal may add categories,
+// so other values should be expected.
+type Category string
+
+// Holding categories.
+const (
+	CategoryStock Category = "stock"
+	CategoryETF   Category = "etf"
+	CategoryStack Category = "stack"
+)
+
+// IsKnown reports whether c is one of the declared categories.
+func (c Category) IsKnown() bool {
+	switch c {
+	case CategoryStock, CategoryETF, CategoryStack:
+		return true
+	}
+	return false
+}
+
+// UnmarshalJSON decodes a category, matching the declared ones regardless of
+// case and surrounding space. Unknown categories are kept as sent.
+func (c *Category) UnmarshalJSON(data []byte) error {
+	s, err := decodeEnum(data, CategoryStock, CategoryETF, CategoryStack)
+	*c = s
+	return err
+}
+
+// HoldingStatus is the settlement status of a holding. Stockal may add
+// statuses, so other values should be expected.
+type HoldingStatus string
+
+// Holding statuses.
+const (
+	StatusSuccessful HoldingStatus = "successful"
+	StatusPending    HoldingStatus = "pending"
+	StatusFailed     HoldingStatus = "failed"
+)
+
+// IsKnown reports whether s is one of the declared statuses.
+func (s HoldingStatus) IsKnown() bool {
+	switch s {
+	case StatusSuccessful, StatusPending, StatusFailed:
+		return true
+	}
+	return false
+}
+
+// UnmarshalJSON decodes a status, matching the declared ones regardless of
+// case and surrounding space. Unknown statuses are kept as sent.
+func (s *HoldingStatus) UnmarshalJSON(data []byte) error {
+	v, err := decodeEnum(data, StatusSuccessful, StatusPending, StatusFailed)
+	*s = v
+	return err
+}
+
+// AssetType is the instrument type of a holding. Stockal may add types, so
+// other values should be expected.
+type AssetType string
+
+// Asset types.
+const (
+	TypeStock AssetType = "stock"
+	TypeETF   AssetType = "etf"
+	TypeStack AssetType = "stack"
+)
+
+// IsKnown reports whether t is one of the declared asset types.
+func (t AssetType) IsKnown() bool {
+	switch t {
+	case TypeStock, TypeETF, TypeStack:
+		return true
+	}
+	return false
+}
+
+// UnmarshalJSON decodes an asset type, matching the declared ones regardless
+// of case and surrounding space. Unknown types are kept as sent.
+func (t *AssetType) UnmarshalJSON(data []byte) error {
+	v, err := decodeEnum(data, TypeStock, TypeETF, TypeStack)
+	*t = v
+	return err
+}
+
+// decodeEnum decodes a JSON string into one of known, compared without regard
+// to case or surrounding space. Other strings are returned unchanged, and
+// null, numbers and booleans as their JSON text, so that a new or malformed
+// value never fails the whole response.
+func decodeEnum[T ~string](data []byte, known ...T) (T, error) {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		var v any
+		if err := json.Unmarshal(data, &v); err != nil {
+			return "", err
+		}
+		if v == nil {
+			return "", nil
+		}
+		return T(data), nil
+	}
+	for _, k := range known {
+		if strings.EqualFold(strings.TrimSpace(s), string(k)) {
+			return k, nil
+		}
+	}
+	return T(s), nil
+}
+
+// CurrentValue returns the market value of the holding at its current price.
+func (h Holding) CurrentValue() float64 {
+	return h.TotalUnit * h.Price
+}
+
+// UnrealizedGain returns the gain, or loss if negative, on the holding since
+// it was bought: its current value less the amount invested.
+func (h Holding) UnrealizedGain() float64 {
+	return h.CurrentValue() - h.TotalInvestment
+}
+
+// GainPercent returns the unrealized gain as a percentage of the amount
+// invested, or zero if nothing was invested.
+func (h Holding) GainPercent() float64 {
+	if h.TotalInvestment == 0 {
+		return 0
+	}
+	return h.UnrealizedGain() / h.TotalInvestment * 100
+}
+
+// DayChange returns the change in the holding's value since the previous
+// session's close, from Close, or Price if the API sent no Close, and
+// PriorClose. It is zero if the API sent no prior close.
+func (h Holding) DayChange() float64 {
+	if h.PriorClose == 0 {
+		return 0
+	}
+	return (h.closeOrPrice() - h.PriorClose) * h.TotalUnit
+}
+
+// DayChangePercent returns the day change as a percentage of the prior
+// close, or zero if the API sent no prior close.
+func (h Holding) DayChangePercent() float64 {
+	if h.PriorClose == 0 {
+		return 0
+	}
+	return (h.closeOrPrice() - h.PriorClose) / h.PriorClose * 100
+}
+
+// closeOrPrice returns Close, or Price if the API sent no Close.
+func (h Holding) closeOrPrice() float64 {
+	if h.Close == 0 {
+		return h.Price
+	}
+	return h.Close
+}
+
+// UpdatedAt returns when the holding was last updated, from Timestamp or
+// else Date, or the zero time if the API did not say.
+func (h Holding) UpdatedAt() time.Time {
+	if t := unixTime(h.Timestamp); !t.IsZero() {
+		return t
+	}
+	return h.Date.Time
+}
+
+// MergeHoldings returns holdings with the rows of each symbol and category
+// merged into one, as the API sometimes returns several after corporate
+// actions. Merged rows sum the units and investment and otherwise take the
+// most recently updated row's values. Holdings keep the position of their
+// first row.
+func MergeHoldings(holdings []Holding) []Holding {
+	type key struct {
+		symbol   string
+		category Category
+	}
+	merged := make([]Holding, 0, len(holdings))
+	index := map[key]int{}
+	for _, h := range holdings {
+		k := key{strings.ToUpper(strings.TrimSpace(h.Symbol)), h.Category}
+		i, dup := index[k]
+		if !dup {
+			index[k] = len(merged)
+			merged = append(merged, h)
+			continue
+		}
+		units := merged[i].TotalUnit + h.TotalUnit
+		investment := merged[i].TotalInvestment + h.TotalInvestment
+		if h.Timestamp > merged[i].Timestamp {
+			merged[i] = h
+		}
+		merged[i].TotalUnit, merged[i].TotalInvestment = units, investment
+	}
+	return merged
+}
+
+// SortHoldings sorts holdings by symbol, then category, so that they are
+// listed in the same order whatever order the API returned them in.
+func SortHoldings(holdings []Holding) {
+	slices.SortStableFunc(holdings, func(a, b Holding) int {
+		return cmp.Or(strings.Compare(a.Symbol, b.Symbol), strings.Compare(string(a.Category), string(b.Category)))
+	})
+}
@@ -0,0 +1,106 @@
+package stockal_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+)
+
+// duplicatedPortfolio has AAPL twice after a corporate action, out of order.
+const duplicatedPortfolio = `{"code":200,"message":"Success","data":{"holdings":[` +
+	`{"symbol":"VOO","category":"etf","totalUnit":2,"totalInvestment":800,"price":450,"timestamp":100},` +
+	`{"symbol":"AAPL","category":"stock","totalUnit":1,"totalInvestment":150,"price":170,"timestamp":100},` +
+	`{"symbol":"AAPL","category":"stock","totalUnit":3,"totalInvestment":450,"price":175,"timestamp":200}],"totalRecords":3}}`
+
+func TestMergeHoldings(t *testing.T) {
+	holdings := stockal.MergeHoldings([]stockal.Holding{
+		{Symbol: "VOO", Category: stockal.CategoryETF, TotalUnit: 2},
+		{Symbol: "AAPL", Category: stockal.CategoryStock, TotalUnit: 1, TotalInvestment: 150, Price: 175, Timestamp: 200},
+		{Symbol: "aapl ", Category: stockal.CategoryStock, TotalUnit: 3, TotalInvestment: 450, Price: 170, Timestamp: 100},
+		{Symbol: "AAPL", Category: stockal.CategoryStack, TotalUnit: 5},
+	})
+	if len(holdings) != 3 {
+		t.Fatalf("got %d holdings, want 3: %+v", len(holdings), holdings)
+	}
+	aapl := holdings[1]
+	if aapl.TotalUnit != 4 || aapl.TotalInvestment != 600 || aapl.Price != 175 || aapl.Symbol != "AAPL" {
+		t.Errorf("merged AAPL = %+v", aapl)
+	}
+	if holdings[0].Symbol != "VOO" || holdings[2].Category != stockal.CategoryStack {
+		t.Errorf("got %+v, want first positions kept", holdings)
+	}
+
+	stockal.SortHoldings(holdings)
+	if holdings[0].Category != stockal.CategoryStack || holdings[1].Category != stockal.CategoryStock || holdings[2].Symbol != "VOO" {
+		t.Errorf("sorted holdings = %+v", holdings)
+	}
+}
+
+func TestPortfolioHoldingsMerged(t *testing.T) {
+	client := newTestClient(t, map[string]string{"/v2/users/portfolio/detail": duplicatedPortfolio})
+	for name, get := range map[string]func() (*stockal.PortfolioDetailResponse, error){
+		"GetPortfolioDetail": func() (*stockal.PortfolioDetailResponse, error) {
+			return client.Portfolio().Detail(context.Background())
+		},
+		"StreamPortfolioDetail": func() (*stockal.PortfolioDetailResponse, error) {
+			return client.StreamPortfolioDetail(context.Background(), stockal.PortfolioParams{}, nil)
+		},
+	} {
+		portfolio, err := get()
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		holdings := portfolio.Data.Holdings
+		if len(holdings) != 2 || portfolio.Data.TotalRecords != 3 {
+			t.Fatalf("%s returned %d holdings of %d, want 2 of 3 rows", name, len(holdings), portfolio.Data.TotalRecords)
+		}
+		if holdings[0].Symbol != "AAPL" || holdings[0].TotalUnit != 4 || holdings[0].Price != 175 || holdings[1].Symbol != "VOO" {
+			t.Errorf("%s returned %+v", name, holdings)
+		}
+	}
+}
+
+func TestWithRawHoldings(t *testing.T) {
+	client := newTestClient(t, map[string]string{"/v2/users/portfolio/detail": duplicatedPortfolio}, stockal.WithRawHoldings())
+
+	portfolio, err := client.Portfolio().Detail(context.Background())
+	if err != nil {
+		t.Fatalf("Portfolio.Detail: %v", err)
+	}
+	if len(portfolio.Data.Holdings) != 3 || portfolio.Data.Holdings[0].Symbol != "VOO" {
+		t.Errorf("got %+v, want the holdings as sent", portfolio.Data.Holdings)
+	}
+}
+
+func TestHoldingGains(t *testing.T) {
+	h := stockal.Holding{TotalUnit: 4, TotalInvestment: 400, Price: 125, Close: 125, PriorClose: 120}
+	if h.CurrentValue() != 500 || h.UnrealizedGain() != 100 || h.GainPercent() != 25 || h.DayChange() != 20 {
+		t.Errorf("value %v, gain %v (%v%%), day change %v; want 500, 100 (25%%), 20",
+			h.CurrentValue(), h.UnrealizedGain(), h.GainPercent(), h.DayChange())
+	}
+
+	// Without a close the price is used; without a prior close there is no change
+	h.Close = 0
+	if h.DayChange() != 20 {
+		t.Errorf("DayChange without Close = %v, want 20", h.DayChange())
+	}
+	h.PriorClose = 0
+	if h.DayChange() != 0 {
+		t.Errorf("DayChange without PriorClose = %v, want 0", h.DayChange())
+	}
+	if h.DayChangePercent() != 0 {
+		t.Errorf("DayChangePercent without PriorClose = %v, want 0", h.DayChangePercent())
+	}
+	if pct := (stockal.Holding{TotalUnit: 2, Price: 130, Close: 125, PriorClose: 100}).DayChangePercent(); pct != 25 {
+		t.Errorf("DayChangePercent = %v, want 25 from the close", pct)
+	}
+	if (stockal.Holding{TotalUnit: 1, Price: 10}).GainPercent() != 0 {
+		t.Error("GainPercent with nothing invested is not zero")
+	}
+
+	s := stockal.PortfolioSummary{TotalCurrentValue: 1100, TotalInvestmentAmount: 1000}
+	if s.TotalGain() != 100 || s.TotalGainPercent() != 10 {
+		t.Errorf("TotalGain = %v (%v%%), want 100 (10%%)", s.TotalGain(), s.TotalGainPercent())
+	}
+}
@@ -0,0 +1,136 @@
+package stockal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// InstrumentSearcher is implemented by clients that can look up the
+// instruments Stockal offers.
+type InstrumentSearcher interface {
+	SearchInstruments(ctx context.Context, query string, opts SearchOptions) (*InstrumentsResponse, error)
+}
+
+// Instrument represents a security that can be looked up on Stockal.
+type Instrument struct {
+	// Symbol is the stock symbol (e.g., "AAPL")
+	Symbol string `json:"symbol"`
+	// Company is the full company or fund name
+	Company string `json:"company"`
+	// Exchange is the exchange the instrument is listed on (e.g., "NASDAQ")
+	Exchange string `json:"exchange,omitempty"`
+	// Type is the asset type (e.g., "stock" or "etf")
+	Type AssetType `json:"type"`
+	// Tradable indicates if the instrument can be bought on Stockal
+	Tradable bool `json:"tradable"`
+	// Fractionable indicates if fractional quantities and amount orders are allowed
+	Fractionable bool `json:"fractionable"`
+	// SellOnly indicates if only sell operations are allowed (optional)
+	SellOnly bool `json:"sellOnly,omitempty"`
+	// Logo is the URL to the company's logo image (optional)
+	Logo string `json:"logo,omitempty"`
+	// Extra holds the fields the API sent that this struct does not model
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// InstrumentsResponse represents the response from the instrument search API.
+type InstrumentsResponse struct {
+	// Code is the HTTP response code
+	Code int `json:"code"`
+	// Message is the response message (usually "Success")
+	Message string `json:"message"`
+	// Data contains the matching instruments, best match first
+	Data []Instrument `json:"data"`
+}
+
+func (r *InstrumentsResponse) normalize() {
+	if r.Data == nil {
+		r.Data = []Instrument{}
+	}
+}
+
+// Instrument returns the instrument with symbol, if present in the response.
+func (r *InstrumentsResponse) Instrument(symbol string) (Instrument, bool) {
+	for _, in := range r.Data {
+		if strings.EqualFold(in.Symbol, strings.TrimSpace(symbol)) {
+			return in, true
+		}
+	}
+	return Instrument{}, false
+}
+
+// SearchOptions narrows an instrument search. The zero value returns the
+// API's default number of matches of every type.
+type SearchOptions struct {
+	// Type, if set, selects the instruments of one asset type
+	Type AssetType
+	// TradableOnly selects the instruments that can be bought on Stockal
+	TradableOnly bool
+	// Limit is the maximum number of instruments to return; zero means the
+	// API's default
+	Limit int
+}
+
+// SearchInstruments looks up the instruments whose symbol or name matches
+// query, best match first, so that symbols can be found rather than
+// hard-coded. An instrument whose symbol is query comes first.
+//
+// The options are applied by the API. The client applies them to the
+// response as well, so that a narrowed search never returns other
+// instruments.
+//
+// Example:
+//
+//	found, err := client.SearchInstruments(ctx, "vanguard s&p", stockal.SearchOptions{Type: stockal.TypeETF, Limit: 5})
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	for _, in := range found.Data {
+//		fmt.Printf("%-6s %s (%s)\n", in.Symbol, in.Company, in.Exchange)
+//	}
+func (c *Client) SearchInstruments(ctx context.Context, query string, opts SearchOptions) (*InstrumentsResponse, error) {
+	query = strings.TrimSpace(query)
+	switch {
+	case query == "":
+		return nil, fmt.Errorf("%w: a search query is required", ErrInvalidParams)
+	case opts.Limit < 0:
+		return nil, fmt.Errorf("%w: negative limit %d", ErrInvalidParams, opts.Limit)
+	}
+
+	params := url.Values{"q": {query}}
+	if opts.Type != "" {
+		params.Set("type", string(opts.Type))
+	}
+	if opts.TradableOnly {
+		params.Set("tradable", "true")
+	}
+	if opts.Limit > 0 {
+		params.Set("limit", strconv.Itoa(opts.Limit))
+	}
+	var searchResp InstrumentsResponse
+	if err := c.do(ctx, "GET", "/v2/market/search?"+params.Encode(), nil, &searchResp, "search instruments"); err != nil {
+		return nil, err
+	}
+
+	instruments := searchResp.Data[:0]
+	for _, in := range searchResp.Data {
+		if (opts.Type == "" || in.Type == opts.Type) && (!opts.TradableOnly || in.Tradable) {
+			instruments = append(instruments, in)
+		}
+	}
+	if i := slices.IndexFunc(instruments, func(in Instrument) bool { return strings.EqualFold(in.Symbol, query) }); i > 0 {
+		exact := instruments[i]
+		copy(instruments[1:i+1], instruments[:i])
+		instruments[0] = exact
+	}
+	if opts.Limit > 0 && len(instruments) > opts.Limit {
+		instruments = instruments[:opts.Limit]
+	}
+	searchResp.Data = instruments
+	return &searchResp, nil
+}
@@ -0,0 +1,71 @@
+package stockal_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+)
+
+func TestSearchInstruments(t *testing.T) {
+	var query string
+	client := newHandlerClient(t, func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		// Every match, as from an API that ignores the options
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"code":200,"message":"Success","data":[` +
+			`{"symbol":"VOOG","company":"Vanguard S&P 500 Growth ETF","exchange":"NYSE","type":"ETF","tradable":true,"fractionable":true},` +
+			`{"symbol":"VOO","company":"Vanguard S&P 500 ETF","exchange":"NYSE","type":"etf","tradable":true,"fractionable":true,"expenseRatio":0.03},` +
+			`{"symbol":"VOOV","company":"Vanguard S&P 500 Value ETF","exchange":"NYSE","type":"etf","tradable":false},` +
+			`{"symbol":"VOOX","company":"Voox Holdings","exchange":"NASDAQ","type":"stock","tradable":true}]}`))
+	})
+
+	tests := []struct {
+		query   string
+		opts    stockal.SearchOptions
+		sent    string
+		symbols []string
+	}{
+		// The exact match comes first
+		{" voo ", stockal.SearchOptions{}, "q=voo", []string{"VOO", "VOOG", "VOOV", "VOOX"}},
+		{"voo", stockal.SearchOptions{Type: stockal.TypeETF, TradableOnly: true}, "q=voo&tradable=true&type=etf", []string{"VOO", "VOOG"}},
+		{"vanguard", stockal.SearchOptions{Limit: 2}, "limit=2&q=vanguard", []string{"VOOG", "VOO"}},
+	}
+	for _, tt := range tests {
+		resp, err := client.SearchInstruments(context.Background(), tt.query, tt.opts)
+		if err != nil {
+			t.Fatalf("SearchInstruments(%q, %+v): %v", tt.query, tt.opts, err)
+		}
+		if query != tt.sent {
+			t.Errorf("SearchInstruments(%q, %+v) sent query %q, want %q", tt.query, tt.opts, query, tt.sent)
+		}
+		var symbols []string
+		for _, in := range resp.Data {
+			symbols = append(symbols, in.Symbol)
+		}
+		if !slices.Equal(symbols, tt.symbols) {
+			t.Errorf("SearchInstruments(%q, %+v) returned %v, want %v", tt.query, tt.opts, symbols, tt.symbols)
+		}
+	}
+
+	resp, err := client.SearchInstruments(context.Background(), "voo", stockal.SearchOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	voo, ok := resp.Instrument("voo")
+	if !ok || voo.Type != stockal.TypeETF || !voo.Fractionable || voo.Exchange != "NYSE" || string(voo.Extra["expenseRatio"]) != "0.03" {
+		t.Errorf("Instrument(voo) = %+v, %v", voo, ok)
+	}
+
+	for _, opts := range []struct {
+		query string
+		opts  stockal.SearchOptions
+	}{{"  ", stockal.SearchOptions{}}, {"voo", stockal.SearchOptions{Limit: -1}}} {
+		if _, err := client.SearchInstruments(context.Background(), opts.query, opts.opts); !errors.Is(err, stockal.ErrInvalidParams) {
+			t.Errorf("SearchInstruments(%q, %+v) error = %v, want ErrInvalidParams", opts.query, opts.opts, err)
+		}
+	}
+}
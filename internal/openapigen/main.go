@@ -0,0 +1,645 @@
+// Command openapigen keeps the component schemas of the OpenAPI spec in sync
+// with the library's request and response types.
+//
+// It reads the type definitions from the package source, so property names,
+// types and descriptions follow the Go fields, their JSON tags and their doc
+// comments, and rewrites the components.schemas section of each spec file.
+// Paths, and schemas without a Go counterpart, are left as written; keys the
+// Go code cannot express, such as examples and formats, are carried over from
+// the existing spec.
+//
+// Usage:
+//
+//	go run ./internal/openapigen openapi.yaml docs/openapi.yaml
+//	go run ./internal/openapigen -check openapi.yaml docs/openapi.yaml
+//
+// With -check nothing is written, and the exit status is 1 if a spec is out
+// of date.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"log"
+	"os"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// roots lists the Go types published in the spec, by schema name. The types
+// they reference are published too, under their Go names.
+var roots = []struct{ schema, goType string }{
+	{"LoginRequest", "LoginRequest"},
+	{"RefreshRequest", "refreshRequest"},
+	{"LoginResponse", "LoginResponse"},
+	{"AccountSummaryResponse", "AccountSummaryResponse"},
+	{"PortfolioDetailResponse", "PortfolioDetailResponse"},
+	{"OrderRequest", "OrderRequest"},
+	{"OrderChanges", "OrderChanges"},
+	{"OrderResponse", "OrderResponse"},
+	{"OrderListResponse", "OrderListResponse"},
+	{"TransactionListResponse", "TransactionListResponse"},
+	{"DividendsResponse", "DividendsResponse"},
+	{"QuotesResponse", "QuotesResponse"},
+	{"CandlesResponse", "CandlesResponse"},
+	{"InstrumentsResponse", "InstrumentsResponse"},
+	{"FxRateResponse", "FxRateResponse"},
+	{"DocumentsResponse", "DocumentsResponse"},
+	{"FundingInstructionsResponse", "FundingInstructionsResponse"},
+	{"DepositRequest", "DepositRequest"},
+	{"DepositResponse", "DepositResponse"},
+	{"WithdrawalRequest", "withdrawalRequest"},
+	{"WithdrawalResponse", "WithdrawalResponse"},
+	{"WithdrawalsResponse", "WithdrawalsResponse"},
+	{"StacksResponse", "StacksResponse"},
+	{"StackDetailResponse", "StackDetailResponse"},
+	{"StackInvestRequest", "stackInvestRequest"},
+	{"RedeemOptions", "RedeemOptions"},
+	{"StackOrderResponse", "StackOrderResponse"},
+	{"WatchlistRequest", "watchlistRequest"},
+	{"WatchlistSymbolsRequest", "watchlistSymbolsRequest"},
+	{"WatchlistsResponse", "WatchlistsResponse"},
+	{"WatchlistResponse", "WatchlistResponse"},
+	{"ErrorResponse", "APIError"},
+}
+
+func main() {
+	pkgDir := flag.String("pkg", ".", "directory of the Go package holding the types")
+	check := flag.Bool("check", false, "report out-of-date specs instead of rewriting them")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "usage: openapigen [-pkg dir] [-check] spec.yaml...")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	log.SetFlags(0)
+	log.SetPrefix("openapigen: ")
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	g, err := newGenerator(*pkgDir)
+	if err != nil {
+		log.Fatal(err)
+	}
+	schemas, err := g.generate()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	stale := false
+	for _, path := range flag.Args() {
+		old, err := os.ReadFile(path)
+		if err != nil {
+			log.Fatal(err)
+		}
+		updated, err := rewrite(old, schemas)
+		if err != nil {
+			log.Fatalf("%s: %v", path, err)
+		}
+		if bytes.Equal(old, updated) {
+			continue
+		}
+		if *check {
+			fmt.Fprintf(os.Stderr, "%s is out of date; run go generate\n", path)
+			stale = true
+			continue
+		}
+		if err := os.WriteFile(path, updated, 0o644); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if stale {
+		os.Exit(1)
+	}
+}
+
+// schema is a generated component schema.
+type schema struct {
+	name string
+	node *yaml.Node
+}
+
+// generator converts Go type declarations to OpenAPI schemas.
+type generator struct {
+	// types holds the package's type declarations by name
+	types map[string]*ast.TypeSpec
+	// docs holds each type's doc comment
+	docs map[string]string
+	// enums holds the constant values declared for each named string type
+	enums map[string][]string
+	// names maps Go type names to schema names
+	names map[string]string
+	// done and out collect the schemas generated so far, dependencies first
+	done map[string]bool
+	out  []schema
+}
+
+func newGenerator(dir string) (*generator, error) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, func(fi os.FileInfo) bool {
+		return !strings.HasSuffix(fi.Name(), "_test.go")
+	}, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	g := &generator{
+		types: map[string]*ast.TypeSpec{},
+		docs:  map[string]string{},
+		enums: map[string][]string{},
+		names: map[string]string{},
+		done:  map[string]bool{},
+	}
+	for _, pkg := range pkgs {
+		for _, file := range pkg.Files {
+			for _, decl := range file.Decls {
+				gen, ok := decl.(*ast.GenDecl)
+				if !ok {
+					continue
+				}
+				for _, spec := range gen.Specs {
+					switch spec := spec.(type) {
+					case *ast.TypeSpec:
+						g.types[spec.Name.Name] = spec
+						doc := spec.Doc
+						if doc == nil {
+							doc = gen.Doc
+						}
+						g.docs[spec.Name.Name] = strings.TrimSpace(doc.Text())
+					case *ast.ValueSpec:
+						g.addEnumValues(spec)
+					}
+				}
+			}
+		}
+	}
+	for _, root := range roots {
+		if g.types[root.goType] == nil {
+			return nil, fmt.Errorf("type %s not found in %s", root.goType, dir)
+		}
+		g.names[root.goType] = root.schema
+	}
+	return g, nil
+}
+
+// addEnumValues records constants declared with an explicit named type.
+func (g *generator) addEnumValues(spec *ast.ValueSpec) {
+	typ, ok := spec.Type.(*ast.Ident)
+	if !ok {
+		return
+	}
+	for _, value := range spec.Values {
+		lit, ok := value.(*ast.BasicLit)
+		if !ok || lit.Kind != token.STRING {
+			continue
+		}
+		if s, err := strconv.Unquote(lit.Value); err == nil {
+			g.enums[typ.Name] = append(g.enums[typ.Name], s)
+		}
+	}
+}
+
+func (g *generator) generate() ([]schema, error) {
+	for _, root := range roots {
+		if err := g.define(root.goType); err != nil {
+			return nil, err
+		}
+	}
+	return g.out, nil
+}
+
+// schemaName returns the name under which a Go type is published.
+func (g *generator) schemaName(goType string) string {
+	if name, ok := g.names[goType]; ok {
+		return name
+	}
+	r, size := utf8.DecodeRuneInString(goType)
+	return string(unicode.ToUpper(r)) + goType[size:]
+}
+
+// define generates the schema for a named type after those it references.
+func (g *generator) define(goType string) error {
+	if g.done[goType] {
+		return nil
+	}
+	g.done[goType] = true
+
+	spec := g.types[goType]
+	var node *yaml.Node
+	var err error
+	switch t := spec.Type.(type) {
+	case *ast.StructType:
+		node, err = g.object(t, strings.HasSuffix(goType, "Request"))
+	default:
+		node, err = g.schemaFor(t)
+		if values := g.enums[goType]; err == nil && len(values) > 0 {
+			enum := &yaml.Node{Kind: yaml.SequenceNode}
+			for _, v := range values {
+				enum.Content = append(enum.Content, str(v))
+			}
+			setKey(node, "enum", enum)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", goType, err)
+	}
+	if doc := g.docs[goType]; doc != "" {
+		insertKey(node, "description", text(summarize(goType, doc)))
+	}
+	g.out = append(g.out, schema{name: g.schemaName(goType), node: node})
+	return nil
+}
+
+// object generates an object schema for a struct. Fields without omitempty
+// are listed as required when required is set.
+func (g *generator) object(t *ast.StructType, required bool) (*yaml.Node, error) {
+	node := mapping("type", str("object"))
+	props := &yaml.Node{Kind: yaml.MappingNode}
+	var names []*yaml.Node
+	for _, field := range t.Fields.List {
+		tag := ""
+		if field.Tag != nil {
+			tag, _ = strconv.Unquote(field.Tag.Value)
+		}
+		name, opts, _ := strings.Cut(reflect.StructTag(tag).Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+
+		if len(field.Names) == 0 {
+			// Embedded struct: its fields are promoted.
+			ident, ok := field.Type.(*ast.Ident)
+			if !ok || g.types[ident.Name] == nil {
+				return nil, fmt.Errorf("unsupported embedded field %s", exprString(field.Type))
+			}
+			st, ok := g.types[ident.Name].Type.(*ast.StructType)
+			if !ok {
+				return nil, fmt.Errorf("unsupported embedded field %s", ident.Name)
+			}
+			embedded, err := g.object(st, required)
+			if err != nil {
+				return nil, err
+			}
+			props.Content = append(props.Content, key(embedded, "properties").Content...)
+			if req := key(embedded, "required"); req != nil {
+				names = append(names, req.Content...)
+			}
+			continue
+		}
+
+		for _, ident := range field.Names {
+			if !ident.IsExported() {
+				continue
+			}
+			prop, err := g.schemaFor(field.Type)
+			if err != nil {
+				return nil, fmt.Errorf("field %s: %w", ident.Name, err)
+			}
+			if desc := describe(ident.Name, field); desc != "" {
+				setKey(prop, "description", text(desc))
+			}
+			jsonName := name
+			if jsonName == "" {
+				jsonName = ident.Name
+			}
+			props.Content = append(props.Content, str(jsonName), prop)
+			if required && !strings.Contains(opts, "omitempty") {
+				names = append(names, str(jsonName))
+			}
+		}
+	}
+	if len(names) > 0 {
+		setKey(node, "required", &yaml.Node{Kind: yaml.SequenceNode, Content: names})
+	}
+	setKey(node, "properties", props)
+	return node, nil
+}
+
+// schemaFor generates the schema for a field type.
+func (g *generator) schemaFor(expr ast.Expr) (*yaml.Node, error) {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		switch t.Name {
+		case "string":
+			return mapping("type", str("string")), nil
+		case "bool":
+			return mapping("type", str("boolean")), nil
+		case "int", "int32", "uint", "uint32":
+			return mapping("type", str("integer")), nil
+		case "int64", "uint64":
+			return mapping("type", str("integer"), "format", str("int64")), nil
+		case "float64":
+			return mapping("type", str("number"), "format", str("double")), nil
+		case "float32":
+			return mapping("type", str("number"), "format", str("float")), nil
+		case "any":
+			return &yaml.Node{Kind: yaml.MappingNode, Style: yaml.FlowStyle}, nil
+		case "Time":
+			// Time marshals to the timestamp as the API sent it, in any of
+			// the forms ParseTime reads
+			return mapping("type", str("string")), nil
+		}
+		if g.types[t.Name] == nil {
+			return nil, fmt.Errorf("unsupported type %s", t.Name)
+		}
+		if err := g.define(t.Name); err != nil {
+			return nil, err
+		}
+		return mapping("$ref", ref(g.schemaName(t.Name))), nil
+	case *ast.StarExpr:
+		node, err := g.schemaFor(t.X)
+		if err != nil {
+			return nil, err
+		}
+		if key(node, "$ref") != nil {
+			// Siblings of $ref are ignored, so nullable needs allOf.
+			node = mapping("allOf", &yaml.Node{Kind: yaml.SequenceNode, Content: []*yaml.Node{node}})
+		}
+		setKey(node, "nullable", &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: "true"})
+		return node, nil
+	case *ast.ArrayType:
+		if ident, ok := t.Elt.(*ast.Ident); ok && ident.Name == "byte" {
+			return mapping("type", str("string"), "format", str("byte")), nil
+		}
+		items, err := g.schemaFor(t.Elt)
+		if err != nil {
+			return nil, err
+		}
+		return mapping("type", str("array"), "items", items), nil
+	case *ast.MapType:
+		values, err := g.schemaFor(t.Value)
+		if err != nil {
+			return nil, err
+		}
+		return mapping("type", str("object"), "additionalProperties", values), nil
+	case *ast.InterfaceType:
+		return &yaml.Node{Kind: yaml.MappingNode, Style: yaml.FlowStyle}, nil
+	case *ast.StructType:
+		return g.object(t, false)
+	case *ast.SelectorExpr:
+		switch exprString(t) {
+		case "time.Time":
+			return mapping("type", str("string"), "format", str("date-time")), nil
+		case "time.Duration":
+			return mapping("type", str("integer"), "format", str("int64")), nil
+		case "json.RawMessage":
+			return &yaml.Node{Kind: yaml.MappingNode, Style: yaml.FlowStyle}, nil
+		}
+	}
+	return nil, fmt.Errorf("unsupported type %s", exprString(expr))
+}
+
+// describe turns a field's doc comment into a property description, dropping
+// the leading field name: "Price is the last traded price" becomes "Last
+// traded price".
+func describe(name string, field *ast.Field) string {
+	doc := field.Doc
+	if doc == nil {
+		doc = field.Comment
+	}
+	s := strings.Join(strings.Fields(doc.Text()), " ")
+	rest, ok := strings.CutPrefix(s, name+" ")
+	if !ok {
+		return s
+	}
+	for _, verb := range []string{"is the ", "are the ", "is ", "are ", "contains the ", "contains ", "shows "} {
+		if r, ok := strings.CutPrefix(rest, verb); ok {
+			rest = r
+			break
+		}
+	}
+	return capitalize(rest)
+}
+
+// summarize turns a type's doc comment into a schema description, dropping
+// the leading type name: "Quote represents the latest price" becomes "The
+// latest price".
+func summarize(name, doc string) string {
+	rest, ok := strings.CutPrefix(doc, name+" ")
+	if !ok {
+		return doc
+	}
+	for _, verb := range []string{"represents ", "is "} {
+		if r, ok := strings.CutPrefix(rest, verb); ok {
+			return capitalize(r)
+		}
+	}
+	return doc
+}
+
+func capitalize(s string) string {
+	r, size := utf8.DecodeRuneInString(s)
+	return string(unicode.ToUpper(r)) + s[size:]
+}
+
+// rewrite replaces the schemas generated from Go types in the components
+// section of spec, keeping any other schemas.
+func rewrite(spec []byte, schemas []schema) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(spec, &doc); err != nil {
+		return nil, err
+	}
+	existing := key(key(doc.Content[0], "components"), "schemas")
+	if existing == nil {
+		return nil, fmt.Errorf("no components.schemas section")
+	}
+
+	generated := map[string]bool{}
+	var out bytes.Buffer
+	for _, s := range schemas {
+		generated[s.name] = true
+		node := s.node
+		if old := key(existing, s.name); old != nil {
+			merge(node, old)
+		}
+		if err := writeSchema(&out, s.name, node); err != nil {
+			return nil, err
+		}
+	}
+	for i := 0; i+1 < len(existing.Content); i += 2 {
+		if name := existing.Content[i].Value; !generated[name] {
+			if err := writeSchema(&out, name, existing.Content[i+1]); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// Splice the new section in place of the old, leaving the rest of the
+	// file, including its formatting, untouched.
+	lines := strings.SplitAfter(string(spec), "\n")
+	start, end := -1, len(lines)
+	inComponents := false
+	for i, line := range lines {
+		trimmed := strings.TrimRight(line, "\r\n")
+		switch {
+		case start >= 0:
+			if trimmed != "" && indent(trimmed) <= 2 {
+				end = i
+			}
+		case trimmed == "components:":
+			inComponents = true
+		case inComponents && trimmed == "  schemas:":
+			start = i + 1
+		case inComponents && trimmed != "" && indent(trimmed) == 0:
+			inComponents = false
+		}
+		if start >= 0 && end < len(lines) {
+			break
+		}
+	}
+	if start < 0 {
+		return nil, fmt.Errorf("components.schemas not found")
+	}
+	section := strings.TrimSuffix(out.String(), "\n")
+	if end < len(lines) {
+		section += "\n"
+	}
+	return []byte(strings.Join(lines[:start], "") + section + strings.Join(lines[end:], "")), nil
+}
+
+// merge copies keys the generator cannot derive, such as examples, from the
+// schema in the existing spec. Keys are only carried over while the type is
+// unchanged.
+func merge(node, old *yaml.Node) {
+	if node.Kind != yaml.MappingNode || old.Kind != yaml.MappingNode {
+		return
+	}
+	if t, oldType := key(node, "type"), key(old, "type"); t != nil && oldType != nil && t.Value != oldType.Value {
+		return
+	}
+	for i := 0; i+1 < len(old.Content); i += 2 {
+		k, v := old.Content[i].Value, old.Content[i+1]
+		switch current := key(node, k); {
+		case current == nil:
+			switch {
+			case k == "format":
+				insertKey(node, k, v)
+			case k == "example" || k == "deprecated" || (k == "description" && key(node, "$ref") == nil):
+				setKey(node, k, v)
+			}
+		case k == "properties":
+			for j := 0; j+1 < len(current.Content); j += 2 {
+				if oldProp := key(v, current.Content[j].Value); oldProp != nil {
+					merge(current.Content[j+1], oldProp)
+				}
+			}
+		case k == "items":
+			merge(current, v)
+		}
+	}
+}
+
+// writeSchema writes one schema indented under components.schemas, followed
+// by a blank line.
+func writeSchema(w *bytes.Buffer, name string, node *yaml.Node) error {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&yaml.Node{Kind: yaml.MappingNode, Content: []*yaml.Node{str(name), node}}); err != nil {
+		return err
+	}
+	if err := enc.Close(); err != nil {
+		return err
+	}
+	for _, line := range strings.SplitAfter(buf.String(), "\n") {
+		switch line {
+		case "":
+		case "\n":
+			w.WriteString(line)
+		default:
+			w.WriteString("    " + line)
+		}
+	}
+	w.WriteString("\n")
+	return nil
+}
+
+func indent(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " "))
+}
+
+// key returns the value of k in a mapping node, or nil.
+func key(node *yaml.Node, k string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == k {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// insertKey sets k in a mapping node, inserting it after the first key (the
+// type) if absent.
+func insertKey(node *yaml.Node, k string, v *yaml.Node) {
+	if key(node, k) != nil || len(node.Content) < 2 {
+		setKey(node, k, v)
+		return
+	}
+	node.Content = slices.Insert(node.Content, 2, str(k), v)
+}
+
+// setKey sets k in a mapping node, appending it if absent.
+func setKey(node *yaml.Node, k string, v *yaml.Node) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == k {
+			node.Content[i+1] = v
+			return
+		}
+	}
+	node.Content = append(node.Content, str(k), v)
+}
+
+func mapping(kv ...any) *yaml.Node {
+	node := &yaml.Node{Kind: yaml.MappingNode}
+	for i := 0; i+1 < len(kv); i += 2 {
+		node.Content = append(node.Content, str(kv[i].(string)), kv[i+1].(*yaml.Node))
+	}
+	return node
+}
+
+func str(s string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: s}
+}
+
+// text is a string scalar, written as a block when it spans lines.
+func text(s string) *yaml.Node {
+	node := str(s)
+	if strings.Contains(s, "\n") {
+		node.Style = yaml.LiteralStyle
+	}
+	return node
+}
+
+func ref(name string) *yaml.Node {
+	node := str("#/components/schemas/" + name)
+	node.Style = yaml.DoubleQuotedStyle
+	return node
+}
+
+func exprString(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		return t.Name
+	case *ast.SelectorExpr:
+		return exprString(t.X) + "." + t.Sel.Name
+	case *ast.StarExpr:
+		return "*" + exprString(t.X)
+	case *ast.ArrayType:
+		return "[]" + exprString(t.Elt)
+	}
+	return fmt.Sprintf("%T", expr)
+}
@@ -0,0 +1,88 @@
+package stockal
+
+import "context"
+
+// defaultPageSize is how many items the iterators read per request unless
+// their options set a limit.
+const defaultPageSize = 100
+
+// Iterator reads a paged listing one item at a time, fetching the next page
+// when the last is used up: HoldingIterator, OrderIterator and
+// TransactionIterator return one. An Iterator is not safe for concurrent use.
+//
+// Example:
+//
+//	it := stockal.OrderIterator(client.Orders(), stockal.OrderListOptions{Status: stockal.OrderStatusFilled})
+//	for it.Next(ctx) {
+//		fmt.Println(it.Value().ID)
+//	}
+//	if err := it.Err(); err != nil {
+//		log.Fatal(err)
+//	}
+type Iterator[T any] struct {
+	operation string
+	// fetch returns the next page, and whether there may be more after it
+	fetch func(ctx context.Context) (page []T, more bool, err error)
+	// key names an item in a PartialError
+	key func(T) string
+
+	page  []T
+	value T
+	read  []string
+	done  bool
+	err   error
+}
+
+// newIterator returns an Iterator over the pages fetch returns, naming items
+// with key.
+func newIterator[T any](operation string, key func(T) string, fetch func(ctx context.Context) ([]T, bool, error)) *Iterator[T] {
+	return &Iterator[T]{operation: operation, fetch: fetch, key: key}
+}
+
+// Next advances to the next item, fetching a page if need be, and reports
+// whether there is one. It returns false at the end of the listing or on
+// failure, which Err reports. If ctx is cancelled before a page is fetched,
+// the failure is a *PartialError naming the items read so far.
+func (it *Iterator[T]) Next(ctx context.Context) bool {
+	for len(it.page) == 0 {
+		if it.done || it.err != nil {
+			return false
+		}
+		if err := stopped(ctx, it.operation, it.read, nil); err != nil {
+			it.err = err
+			return false
+		}
+		page, more, err := it.fetch(ctx)
+		if err != nil {
+			if stop := stopped(ctx, it.operation, it.read, nil); stop != nil {
+				err = stop
+			}
+			it.err = err
+			return false
+		}
+		it.page, it.done = page, !more
+	}
+	it.value, it.page = it.page[0], it.page[1:]
+	it.read = append(it.read, it.key(it.value))
+	return true
+}
+
+// Value returns the item Next advanced to.
+func (it *Iterator[T]) Value() T {
+	return it.value
+}
+
+// Err returns the failure that ended the iteration, or nil.
+func (it *Iterator[T]) Err() error {
+	return it.err
+}
+
+// All reads the items Next has not yet returned and returns them, never nil.
+// On failure it returns the items read with the error.
+func (it *Iterator[T]) All(ctx context.Context) ([]T, error) {
+	items := []T{}
+	for it.Next(ctx) {
+		items = append(items, it.value)
+	}
+	return items, it.err
+}
@@ -0,0 +1,50 @@
+package stockal_test
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+)
+
+func TestIterator(t *testing.T) {
+	var requests int
+	client := newTestClientFunc(t, func(r *http.Request) string {
+		requests++
+		switch r.URL.Query().Get("cursor") {
+		case "":
+			return `{"code":200,"data":{"orders":[{"orderID":"o1"},{"orderID":"o2"}],"totalRecords":5,"nextCursor":"c2"}}`
+		case "c2":
+			// An empty page in the middle of a listing is skipped
+			return `{"code":200,"data":{"orders":[],"totalRecords":5,"nextCursor":"c3"}}`
+		case "c3":
+			return `{"code":200,"data":{"orders":[{"orderID":"o3"},{"orderID":"o4"}],"totalRecords":5,"nextCursor":"c4"}}`
+		}
+		return `{"code":400,"message":"bad cursor"}`
+	})
+
+	ctx := context.Background()
+	it := stockal.OrderIterator(client.Orders(), stockal.OrderListOptions{Limit: 2})
+	if !it.Next(ctx) || it.Value().ID != "o1" {
+		t.Fatalf("first Next: value %+v, err %v", it.Value(), it.Err())
+	}
+	if requests != 1 {
+		t.Errorf("made %d requests for the first order, want 1", requests)
+	}
+	rest, err := it.All(ctx)
+	var ids []string
+	for _, o := range rest {
+		ids = append(ids, o.ID)
+	}
+	if !slices.Equal(ids, []string{"o2", "o3", "o4"}) {
+		t.Errorf("All after Next returned %v, want o2 to o4", ids)
+	}
+	if err == nil || err != it.Err() {
+		t.Errorf("All error = %v, Err = %v; want the failure of the last page from both", err, it.Err())
+	}
+	if it.Next(ctx) {
+		t.Error("Next succeeded after a failure")
+	}
+}
@@ -0,0 +1,71 @@
+package stockal
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"slices"
+	"time"
+)
+
+// WithLogger logs every request to logger at debug level, once it is
+// answered or fails: the method, endpoint, request headers and body, HTTP
+// status, duration, and how many times it was retried. Authorization headers,
+// passwords and tokens are redacted, as Redact does. Nothing is logged unless
+// logger is enabled for debug messages.
+//
+// Example:
+//
+//	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug}))
+//	client := stockal.NewClient(stockal.WithLogger(logger))
+func WithLogger(logger *slog.Logger) ClientOption {
+	return func(c *clientConfig) {
+		c.logger = logger
+	}
+}
+
+// logRequest logs a request with body that was sent attempts times since
+// start, ending in resp or err.
+func (c *Client) logRequest(ctx context.Context, req *http.Request, body []byte, start time.Time, attempts int, resp *http.Response, err error) {
+	if c.logger == nil || !c.logger.Enabled(ctx, slog.LevelDebug) {
+		return
+	}
+	accessToken, refreshToken, _ := c.tokens()
+	attrs := []slog.Attr{
+		slog.String("method", req.Method),
+		slog.String("endpoint", Redact(req.URL.RequestURI(), accessToken, refreshToken)),
+		slog.Duration("duration", time.Since(start)),
+		slog.Int("retries", attempts-1),
+		slog.Any("headers", redactedHeaders(req.Header)),
+	}
+	if len(body) > 0 {
+		attrs = append(attrs, slog.String("body", Redact(string(body), accessToken, refreshToken)))
+	}
+	if resp != nil {
+		attrs = append(attrs, slog.Int("status", resp.StatusCode))
+	}
+	if err != nil {
+		attrs = append(attrs, slog.String("error", err.Error()))
+	}
+	c.logger.LogAttrs(ctx, slog.LevelDebug, "stockal request", attrs...)
+}
+
+// redactedHeaders returns header as log attributes, sorted by name, with the
+// values of credential headers such as Authorization redacted.
+func redactedHeaders(header http.Header) slog.Value {
+	names := make([]string, 0, len(header))
+	for name := range header {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	attrs := make([]slog.Attr, 0, len(names))
+	for _, name := range names {
+		value := header.Get(name)
+		if queryKey.MatchString(name) {
+			value = Redacted
+		}
+		attrs = append(attrs, slog.String(name, value))
+	}
+	return slog.GroupValue(attrs...)
+}
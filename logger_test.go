@@ -0,0 +1,49 @@
+package stockal_test
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+)
+
+func TestLogger(t *testing.T) {
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	client := newHandlerClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/v3/auth/login" {
+			w.Write([]byte(`{"code":200,"message":"Success","data":{"accessToken":"session-token-1","refreshToken":"refresh-token-1"}}`))
+			return
+		}
+		w.Write([]byte(`{"code":200,"message":"Success","data":null}`))
+	}, stockal.WithTokenStore(stockal.NewMemoryTokenStore(nil)), stockal.WithLogger(logger))
+	if _, err := client.Auth().Login(context.Background(), "alice", "hunter22"); err != nil {
+		t.Fatalf("Auth.Login: %v", err)
+	}
+	if _, err := client.Portfolio().Detail(context.Background()); err != nil {
+		t.Fatalf("Portfolio.Detail: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(logs.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("logged %d lines, want 2:\n%s", len(lines), logs.String())
+	}
+	for _, want := range []string{"level=DEBUG", "method=POST", "endpoint=/v3/auth/login", "status=200", "retries=0", "duration="} {
+		if !strings.Contains(lines[0], want) {
+			t.Errorf("login log %q lacks %q", lines[0], want)
+		}
+	}
+	if !strings.Contains(lines[1], "headers.Authorization="+stockal.Redacted) {
+		t.Errorf("portfolio log %q lacks a redacted Authorization header", lines[1])
+	}
+	for _, secret := range []string{"hunter22", "session-token-1", "refresh-token-1"} {
+		if strings.Contains(logs.String(), secret) {
+			t.Errorf("logs reveal %q:\n%s", secret, logs.String())
+		}
+	}
+}
@@ -0,0 +1,208 @@
+package stockal
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// US equity regular trading session, in exchange-local time.
+const (
+	marketOpenMinute  = 9*60 + 30
+	marketCloseMinute = 16 * 60
+)
+
+// exchangeLocation returns the America/New_York time zone, falling back to a
+// fixed EST offset when the system has no time zone database.
+var exchangeLocation = sync.OnceValue(func() *time.Location {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		return time.FixedZone("EST", -5*60*60)
+	}
+	return loc
+})
+
+// IsMarketOpen reports whether t falls within the US regular trading session
+// (9:30–16:00 America/New_York, Monday to Friday).
+//
+// Exchange holidays and early closes are not taken into account.
+func IsMarketOpen(t time.Time) bool {
+	local := t.In(exchangeLocation())
+	if local.Weekday() == time.Saturday || local.Weekday() == time.Sunday {
+		return false
+	}
+	minute := local.Hour()*60 + local.Minute()
+	return minute >= marketOpenMinute && minute < marketCloseMinute
+}
+
+// NextMarketOpen returns the start of the next regular trading session after t,
+// or t itself if the market is open at t.
+//
+// Like IsMarketOpen, it does not know about exchange holidays.
+func NextMarketOpen(t time.Time) time.Time {
+	if IsMarketOpen(t) {
+		return t
+	}
+	return nextSessionBoundary(t, marketOpenMinute)
+}
+
+// NextMarketClose returns the end of the current regular trading session if the
+// market is open at t, or of the next session otherwise.
+func NextMarketClose(t time.Time) time.Time {
+	return nextSessionBoundary(t, marketCloseMinute)
+}
+
+// nextSessionBoundary returns the first weekday time strictly after t at the
+// given minute of the exchange-local day.
+func nextSessionBoundary(t time.Time, minute int) time.Time {
+	local := t.In(exchangeLocation())
+	day := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, local.Location())
+	for {
+		at := time.Date(day.Year(), day.Month(), day.Day(), minute/60, minute%60, 0, 0, day.Location())
+		if weekday := at.Weekday(); at.After(local) && weekday != time.Saturday && weekday != time.Sunday {
+			return at
+		}
+		day = day.AddDate(0, 0, 1)
+	}
+}
+
+// MarketData is implemented by clients that can read market prices.
+type MarketData interface {
+	GetQuotes(ctx context.Context, symbols ...string) (*QuotesResponse, error)
+	GetFxRate(ctx context.Context) (*FxRateResponse, error)
+}
+
+// Quote represents the latest price information for a symbol.
+type Quote struct {
+	// Symbol is the stock symbol (e.g., "AAPL")
+	Symbol string `json:"symbol"`
+	// Price is the last traded price
+	Price float64 `json:"price"`
+	// Open is the opening price of the current session
+	Open float64 `json:"open"`
+	// High is the highest price of the current session
+	High float64 `json:"high"`
+	// Low is the lowest price of the current session
+	Low float64 `json:"low"`
+	// PriorClose is the previous session's closing price
+	PriorClose float64 `json:"priorClose"`
+	// Volume is the number of shares traded in the current session
+	Volume int64 `json:"volume"`
+	// Timestamp is the Unix timestamp of the last trade
+	Timestamp int64 `json:"timestamp"`
+	// Source names the QuoteProvider the quote came from; empty for Stockal's own quotes
+	Source string `json:"source,omitempty"`
+	// Extra holds the fields the API sent that this struct does not model
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// TradedAt returns the time of the last trade, from Timestamp, or the zero
+// time if the API did not say.
+func (q Quote) TradedAt() time.Time {
+	return unixTime(q.Timestamp)
+}
+
+// QuoteProvider is a source of quotes other than Stockal. See WithQuoteFallback.
+//
+// Quotes returns quotes for the symbols it knows, omitting the others, and
+// sets Source on each quote.
+type QuoteProvider interface {
+	Quotes(ctx context.Context, symbols ...string) ([]Quote, error)
+}
+
+// QuotesResponse represents the response from the quotes API.
+type QuotesResponse struct {
+	// Code is the HTTP response code
+	Code int `json:"code"`
+	// Message is the response message (usually "Success")
+	Message string `json:"message"`
+	// Data contains one quote per requested symbol that Stockal knows about
+	Data []Quote `json:"data"`
+}
+
+func (r *QuotesResponse) normalize() {
+	if r.Data == nil {
+		r.Data = []Quote{}
+	}
+}
+
+// Quote returns the quote for symbol, if present in the response.
+func (r *QuotesResponse) Quote(symbol string) (Quote, bool) {
+	for _, q := range r.Data {
+		if strings.EqualFold(q.Symbol, symbol) {
+			return q, true
+		}
+	}
+	return Quote{}, false
+}
+
+// GetQuotes retrieves the latest quotes for one or more symbols in a single request.
+//
+// With WithQuoteFallback, symbols Stockal has no quote for are looked up with
+// the fallback provider, as are all symbols if Stockal fails or cannot be
+// reached (ErrServerError, ErrUpstreamUnavailable or a transport error).
+// Other errors, such as ErrTokenExpired, are returned as they are. Quotes from
+// the fallback have Source set.
+//
+// Example:
+//
+//	quotes, err := client.GetQuotes(ctx, "AAPL", "TSLA")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	if q, ok := quotes.Quote("AAPL"); ok {
+//		fmt.Printf("AAPL: $%.2f\n", q.Price)
+//	}
+func (c *Client) GetQuotes(ctx context.Context, symbols ...string) (*QuotesResponse, error) {
+	if len(symbols) == 0 {
+		return nil, errors.New("at least one symbol is required")
+	}
+
+	query := url.Values{"symbols": {strings.Join(symbols, ",")}}
+	var quotesResp QuotesResponse
+	err := c.do(ctx, "GET", "/v2/market/quotes?"+query.Encode(), nil, &quotesResp, "quotes")
+	if c.quoteFallback == nil {
+		if err != nil {
+			return nil, err
+		}
+		return &quotesResp, nil
+	}
+
+	if err != nil {
+		if ctx.Err() != nil || !stockalUnavailable(err) {
+			return nil, err
+		}
+		quotes, fallbackErr := c.quoteFallback.Quotes(ctx, symbols...)
+		if fallbackErr != nil {
+			return nil, errors.Join(err, fmt.Errorf("quote fallback: %w", fallbackErr))
+		}
+		return &QuotesResponse{Code: http.StatusOK, Message: "Success", Data: quotes}, nil
+	}
+
+	var missing []string
+	for _, symbol := range symbols {
+		if _, ok := quotesResp.Quote(symbol); !ok {
+			missing = append(missing, symbol)
+		}
+	}
+	if len(missing) > 0 {
+		// Stockal's answer stands on its own; a failing fallback only leaves gaps.
+		if quotes, err := c.quoteFallback.Quotes(ctx, missing...); err == nil {
+			quotesResp.Data = append(quotesResp.Data, quotes...)
+		}
+	}
+	return &quotesResp, nil
+}
+
+// stockalUnavailable reports whether err means Stockal failed or could not be
+// reached, rather than rejecting the request or the session.
+func stockalUnavailable(err error) bool {
+	var transportErr *url.Error
+	return errors.Is(err, ErrServerError) || errors.Is(err, ErrUpstreamUnavailable) || errors.As(err, &transportErr)
+}
@@ -0,0 +1,121 @@
+package stockal_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+)
+
+// fakeQuoteProvider is a QuoteProvider knowing a price for every symbol, and
+// recording the symbols it was asked for.
+type fakeQuoteProvider struct {
+	requested []string
+}
+
+func (p *fakeQuoteProvider) Quotes(ctx context.Context, symbols ...string) ([]stockal.Quote, error) {
+	p.requested = append(p.requested, symbols...)
+	var quotes []stockal.Quote
+	for _, symbol := range symbols {
+		quotes = append(quotes, stockal.Quote{Symbol: symbol, Price: 100, Source: "fake"})
+	}
+	return quotes, nil
+}
+
+func TestGetQuotesFallback(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      int
+		contentType string
+		body        string
+		wantErr     bool
+		// err is what the error should match, if anything in particular
+		err error
+		// fallback lists the symbols the fallback should be asked for
+		fallback []string
+	}{
+		{
+			name:        "missing symbol",
+			status:      http.StatusOK,
+			contentType: "application/json",
+			body:        `{"code":200,"message":"Success","data":[{"symbol":"AAPL","price":190}]}`,
+			fallback:    []string{"VOO"},
+		},
+		{
+			name:        "server error",
+			status:      http.StatusInternalServerError,
+			contentType: "application/json",
+			body:        `{"code":500,"message":"Internal server error"}`,
+			fallback:    []string{"AAPL", "VOO"},
+		},
+		{
+			name:        "upstream unavailable",
+			status:      http.StatusBadGateway,
+			contentType: "text/html",
+			body:        "<html>Bad gateway</html>",
+			fallback:    []string{"AAPL", "VOO"},
+		},
+		{
+			name:        "token expired",
+			status:      http.StatusUnauthorized,
+			contentType: "application/json",
+			body:        `{"code":401,"message":"Unauthorized"}`,
+			wantErr:     true,
+			err:         stockal.ErrTokenExpired,
+		},
+		{
+			name:        "invalid request",
+			status:      http.StatusBadRequest,
+			contentType: "application/json",
+			body:        `{"code":400,"message":"Invalid symbols"}`,
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := &fakeQuoteProvider{}
+			client := newHandlerClient(t, func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", tt.contentType)
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}, stockal.WithQuoteFallback(provider))
+
+			quotes, err := client.GetQuotes(context.Background(), "AAPL", "VOO")
+			if !slices.Equal(provider.requested, tt.fallback) {
+				t.Errorf("fallback asked for %q, want %q", provider.requested, tt.fallback)
+			}
+			if tt.wantErr {
+				if err == nil || tt.err != nil && !errors.Is(err, tt.err) {
+					t.Errorf("GetQuotes error = %v, want Stockal's error", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetQuotes: %v", err)
+			}
+			q, ok := quotes.Quote("VOO")
+			if !ok || q.Source != "fake" {
+				t.Errorf("VOO quote = %+v, %v; want one from the fallback", q, ok)
+			}
+		})
+	}
+}
+
+func TestGetQuotesFallbackUnreachable(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	srv.Close()
+
+	provider := &fakeQuoteProvider{}
+	client := newLoggedInClient(srv.URL, stockal.WithQuoteFallback(provider))
+	quotes, err := client.GetQuotes(context.Background(), "AAPL")
+	if err != nil {
+		t.Fatalf("GetQuotes: %v", err)
+	}
+	if q, ok := quotes.Quote("AAPL"); !ok || q.Source != "fake" {
+		t.Errorf("AAPL quote = %+v, %v; want one from the fallback", q, ok)
+	}
+}
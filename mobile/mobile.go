@@ -0,0 +1,367 @@
+// Package mobile is a facade over the client for Android and iOS apps, built
+// with gomobile:
+//
+//	gomobile bind -target=android -o stockal.aar ./mobile
+//	gomobile bind -target=ios -o Stockal.xcframework ./mobile
+//
+// gomobile can only bind a subset of Go, so the facade differs from the main
+// package: calls block instead of taking a context (run them off the main
+// thread, and stop them with Client.Cancel or a timeout); lists are returned
+// as JSON arrays in the API's own format; and symbols are passed as one
+// comma-separated string.
+//
+// Sessions are persisted through a TokenStore implemented by the app, which
+// should keep the token in the Android Keystore or the iOS Keychain.
+package mobile
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+)
+
+// TokenStore persists the session token, an opaque JSON string, between app
+// launches. Load returns an empty string when no token has been saved.
+type TokenStore interface {
+	Load() (string, error)
+	Save(token string) error
+	Clear() error
+}
+
+// Client is a Stockal API client. Its methods are safe for concurrent use.
+type Client struct {
+	client stockal.StockalClient
+
+	mu      sync.Mutex
+	timeout time.Duration
+	// ctx is cancelled by Cancel, stopping the calls in flight
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// NewClient creates a client for the API at baseURL (empty for the default)
+// that persists its session in store, which may be nil.
+func NewClient(baseURL string, store TokenStore) *Client {
+	options := []stockal.ClientOption{stockal.WithUserAgent("unofficial-stockal-api-mobile/1.0")}
+	if baseURL != "" {
+		options = append(options, stockal.WithBaseURL(baseURL))
+	}
+	if store != nil {
+		options = append(options, stockal.WithTokenStore(tokenStore{store}))
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Client{
+		client:  stockal.NewClient(options...),
+		timeout: stockal.DefaultTimeout,
+		ctx:     ctx,
+		cancel:  cancel,
+	}
+}
+
+// SetTimeout sets how long each call may take, in seconds.
+func (c *Client) SetTimeout(seconds int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.timeout = time.Duration(seconds) * time.Second
+}
+
+// Cancel stops every call in progress; they return an error. Later calls are
+// not affected.
+func (c *Client) Cancel() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.cancel()
+	c.ctx, c.cancel = context.WithCancel(context.Background())
+}
+
+func (c *Client) context() (context.Context, context.CancelFunc) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return context.WithTimeout(c.ctx, c.timeout)
+}
+
+// Login authenticates with the user's credentials.
+func (c *Client) Login(username, password string) error {
+	ctx, cancel := c.context()
+	defer cancel()
+	_, err := c.client.Auth().Login(ctx, username, password)
+	return err
+}
+
+// Logout forgets the session and clears it from the token store.
+func (c *Client) Logout() error {
+	ctx, cancel := c.context()
+	defer cancel()
+	return c.client.Auth().Logout(ctx)
+}
+
+// AccountSummary holds the account's cash balances and portfolio totals.
+type AccountSummary struct {
+	CashAvailableForTrade      float64
+	CashAvailableForWithdrawal float64
+	CashBalance                float64
+	UnsettledAmount            float64
+	TotalCurrentValue          float64
+	TotalInvestmentAmount      float64
+	// Restricted is set when the account has trading restrictions
+	Restricted bool
+}
+
+// AccountSummary retrieves cash balances and portfolio totals.
+func (c *Client) AccountSummary() (*AccountSummary, error) {
+	ctx, cancel := c.context()
+	defer cancel()
+	resp, err := c.client.Account().Summary(ctx)
+	if err != nil {
+		return nil, err
+	}
+	d := resp.Data
+	return &AccountSummary{
+		CashAvailableForTrade:      d.AccountSummary.CashAvailableForTrade,
+		CashAvailableForWithdrawal: d.AccountSummary.CashAvailableForWithdrawal,
+		CashBalance:                d.AccountSummary.CashBalance,
+		UnsettledAmount:            d.UnsettledAmount,
+		TotalCurrentValue:          d.PortfolioSummary.TotalCurrentValue,
+		TotalInvestmentAmount:      d.PortfolioSummary.TotalInvestmentAmount,
+		Restricted:                 d.AccountSummary.Restricted,
+	}, nil
+}
+
+// HoldingsJSON retrieves the portfolio's holdings as a JSON array of holding
+// objects (see the Holding schema in the OpenAPI spec).
+func (c *Client) HoldingsJSON() (string, error) {
+	ctx, cancel := c.context()
+	defer cancel()
+	resp, err := c.client.Portfolio().Detail(ctx)
+	if err != nil {
+		return "", err
+	}
+	return toJSON(resp.Data.Holdings)
+}
+
+// Quote is the latest price information for a symbol.
+type Quote struct {
+	Symbol     string
+	Price      float64
+	Open       float64
+	High       float64
+	Low        float64
+	PriorClose float64
+	Volume     int64
+	// Timestamp is the Unix time of the last trade
+	Timestamp int64
+}
+
+// Quote retrieves the latest quote for a symbol.
+func (c *Client) Quote(symbol string) (*Quote, error) {
+	ctx, cancel := c.context()
+	defer cancel()
+	resp, err := c.client.GetQuotes(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+	q, ok := resp.Quote(symbol)
+	if !ok {
+		return nil, fmt.Errorf("no quote for %s", symbol)
+	}
+	return &Quote{
+		Symbol:     q.Symbol,
+		Price:      q.Price,
+		Open:       q.Open,
+		High:       q.High,
+		Low:        q.Low,
+		PriorClose: q.PriorClose,
+		Volume:     q.Volume,
+		Timestamp:  q.Timestamp,
+	}, nil
+}
+
+// QuotesJSON retrieves quotes for comma-separated symbols as a JSON array of
+// quote objects. Unknown symbols are omitted.
+func (c *Client) QuotesJSON(symbols string) (string, error) {
+	var list []string
+	for _, s := range strings.Split(symbols, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			list = append(list, s)
+		}
+	}
+	ctx, cancel := c.context()
+	defer cancel()
+	resp, err := c.client.GetQuotes(ctx, list...)
+	if err != nil {
+		return "", err
+	}
+	return toJSON(resp.Data)
+}
+
+// Order is an order and its execution state.
+type Order struct {
+	ID             string
+	Symbol         string
+	Side           string
+	Type           string
+	Status         string
+	Quantity       float64
+	Amount         float64
+	LimitPrice     float64
+	FilledQuantity float64
+	AveragePrice   float64
+	CreatedAt      string
+	UpdatedAt      string
+	FilledAt       string
+}
+
+// PlaceOrder submits an order. side is "buy" or "sell" and orderType is
+// "market" or "limit". Set exactly one of quantity (shares) or amount
+// (dollars); limitPrice is only for limit orders. The order is real.
+func (c *Client) PlaceOrder(symbol, side, orderType string, quantity, amount, limitPrice float64) (*Order, error) {
+	ctx, cancel := c.context()
+	defer cancel()
+	resp, err := c.client.Orders().Place(ctx, stockal.OrderRequest{
+		Symbol:     symbol,
+		Side:       stockal.OrderSide(side),
+		Type:       stockal.OrderType(orderType),
+		Quantity:   quantity,
+		Amount:     amount,
+		LimitPrice: limitPrice,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return newOrder(resp.Data), nil
+}
+
+// CancelOrder cancels an open order.
+func (c *Client) CancelOrder(orderID string) (*Order, error) {
+	ctx, cancel := c.context()
+	defer cancel()
+	resp, err := c.client.Orders().Cancel(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	return newOrder(resp.Data), nil
+}
+
+// ModifyOrder changes an open order. Zero leaves quantity, amount or
+// limitPrice unchanged.
+func (c *Client) ModifyOrder(orderID string, quantity, amount, limitPrice float64) (*Order, error) {
+	ctx, cancel := c.context()
+	defer cancel()
+	resp, err := c.client.Orders().Modify(ctx, orderID, stockal.OrderChanges{
+		Quantity:   quantity,
+		Amount:     amount,
+		LimitPrice: limitPrice,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return newOrder(resp.Data), nil
+}
+
+// OrdersJSON retrieves open and recent orders as a JSON array of order objects.
+func (c *Client) OrdersJSON() (string, error) {
+	ctx, cancel := c.context()
+	defer cancel()
+	resp, err := c.client.Orders().List(ctx)
+	if err != nil {
+		return "", err
+	}
+	return toJSON(resp.Data.Orders)
+}
+
+func newOrder(o stockal.Order) *Order {
+	return &Order{
+		ID:             o.ID,
+		Symbol:         o.Symbol,
+		Side:           string(o.Side),
+		Type:           string(o.Type),
+		Status:         string(o.Status),
+		Quantity:       o.Quantity,
+		Amount:         o.Amount,
+		LimitPrice:     o.LimitPrice,
+		FilledQuantity: o.FilledQuantity,
+		AveragePrice:   o.AveragePrice,
+		CreatedAt:      o.CreatedAt.String(),
+		UpdatedAt:      o.UpdatedAt.String(),
+		FilledAt:       o.FilledAt.String(),
+	}
+}
+
+// Error kinds returned by ErrorKind.
+const (
+	ErrorKindNone             = ""
+	ErrorKindNotAuthenticated = "not_authenticated"
+	ErrorKindInvalidOrder     = "invalid_order"
+	ErrorKindRateLimited      = "rate_limited"
+	ErrorKindUnavailable      = "unavailable"
+	ErrorKindAPI              = "api"
+	ErrorKindCancelled        = "cancelled"
+	ErrorKindOther            = "other"
+)
+
+// ErrorKind classifies an error returned by a Client method, since error
+// types do not survive the language boundary.
+func ErrorKind(err error) string {
+	var apiErr *stockal.APIError
+	switch {
+	case err == nil:
+		return ErrorKindNone
+	case errors.Is(err, stockal.ErrNotAuthenticated), errors.Is(err, stockal.ErrInvalidCredentials),
+		errors.Is(err, stockal.ErrLoginIncomplete):
+		return ErrorKindNotAuthenticated
+	case errors.Is(err, stockal.ErrInvalidOrder):
+		return ErrorKindInvalidOrder
+	case errors.Is(err, stockal.ErrRateLimited):
+		return ErrorKindRateLimited
+	case errors.Is(err, stockal.ErrUpstreamUnavailable):
+		return ErrorKindUnavailable
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return ErrorKindCancelled
+	case errors.As(err, &apiErr):
+		return ErrorKindAPI
+	}
+	return ErrorKindOther
+}
+
+func toJSON(v any) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// tokenStore adapts an app's TokenStore to stockal.TokenStore.
+type tokenStore struct {
+	store TokenStore
+}
+
+func (s tokenStore) Load(ctx context.Context) (*stockal.LoginData, error) {
+	secret, err := s.store.Load()
+	if err != nil || secret == "" {
+		return nil, err
+	}
+	var token stockal.LoginData
+	if err := json.Unmarshal([]byte(secret), &token); err != nil {
+		return nil, fmt.Errorf("invalid saved token: %w", err)
+	}
+	return &token, nil
+}
+
+func (s tokenStore) Save(ctx context.Context, token *stockal.LoginData) error {
+	secret, err := json.Marshal(token)
+	if err != nil {
+		return err
+	}
+	return s.store.Save(string(secret))
+}
+
+func (s tokenStore) Clear(ctx context.Context) error {
+	return s.store.Clear()
+}
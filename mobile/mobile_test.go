@@ -0,0 +1,287 @@
+package mobile_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+	"github.com/adjaecent/unofficial-stockal-api/mobile"
+	"github.com/adjaecent/unofficial-stockal-api/stockaltest"
+)
+
+// memStore is a TokenStore kept in memory, as an app's keychain would be.
+type memStore struct {
+	mu    sync.Mutex
+	token string
+}
+
+func (s *memStore) Load() (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.token, nil
+}
+
+func (s *memStore) Save(token string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.token = token
+	return nil
+}
+
+func (s *memStore) Clear() error {
+	return s.Save("")
+}
+
+func TestClient(t *testing.T) {
+	srv := stockaltest.NewServer()
+	defer srv.Close()
+	store := &memStore{}
+	c := mobile.NewClient(srv.URL, store)
+
+	if _, err := c.AccountSummary(); mobile.ErrorKind(err) != mobile.ErrorKindNotAuthenticated {
+		t.Errorf("AccountSummary before logging in = %v, want kind %s", err, mobile.ErrorKindNotAuthenticated)
+	}
+	if err := c.Login("alice", "secret"); err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	var saved stockal.LoginData
+	if err := json.Unmarshal([]byte(store.token), &saved); err != nil || saved.AccessToken != stockaltest.Session().Data.AccessToken {
+		t.Errorf("saved token %s, %v; want the session", store.token, err)
+	}
+
+	summary, err := c.AccountSummary()
+	if err != nil {
+		t.Fatalf("AccountSummary: %v", err)
+	}
+	fixture := stockaltest.AccountSummary().Data
+	want := mobile.AccountSummary{
+		CashAvailableForTrade:      fixture.AccountSummary.CashAvailableForTrade,
+		CashAvailableForWithdrawal: fixture.AccountSummary.CashAvailableForWithdrawal,
+		CashBalance:                fixture.AccountSummary.CashBalance,
+		UnsettledAmount:            fixture.UnsettledAmount,
+		TotalCurrentValue:          fixture.PortfolioSummary.TotalCurrentValue,
+		TotalInvestmentAmount:      fixture.PortfolioSummary.TotalInvestmentAmount,
+		Restricted:                 fixture.AccountSummary.Restricted,
+	}
+	if *summary != want || summary.CashBalance != 1350.75 {
+		t.Errorf("AccountSummary = %+v, want the fixture's %+v", *summary, want)
+	}
+
+	// A new client, as when the app is launched again, restores the session
+	relaunched := mobile.NewClient(srv.URL, store)
+	if _, err := relaunched.AccountSummary(); err != nil {
+		t.Errorf("AccountSummary with the saved session: %v", err)
+	}
+
+	if err := c.Logout(); err != nil {
+		t.Fatalf("Logout: %v", err)
+	}
+	if store.token != "" {
+		t.Errorf("saved token %s after logging out, want none", store.token)
+	}
+	if _, err := mobile.NewClient(srv.URL, store).AccountSummary(); mobile.ErrorKind(err) != mobile.ErrorKindNotAuthenticated {
+		t.Errorf("AccountSummary after logging out = %v, want kind %s", err, mobile.ErrorKindNotAuthenticated)
+	}
+}
+
+// login returns a client logged in to srv.
+func login(t *testing.T, srv *stockaltest.Server) *mobile.Client {
+	t.Helper()
+	c := mobile.NewClient(srv.URL, nil)
+	if err := c.Login("alice", "secret"); err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	return c
+}
+
+func TestJSON(t *testing.T) {
+	srv := stockaltest.NewServer()
+	defer srv.Close()
+	c := login(t, srv)
+
+	// Lists are JSON arrays in the API's own format
+	tests := []struct {
+		name string
+		call func() (string, error)
+		key  string
+		want []string
+	}{
+		{name: "holdings", call: c.HoldingsJSON, key: "symbol", want: []string{"AAPL", "MSFT", "VOO"}},
+		{name: "quotes", call: func() (string, error) { return c.QuotesJSON(" aapl, TSLA,,VOO ") }, key: "symbol", want: []string{"AAPL", "VOO"}},
+		{name: "orders", call: c.OrdersJSON, key: "orderID", want: []string{"ord-1003", "ord-1002", "ord-1001"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := tt.call()
+			if err != nil {
+				t.Fatalf("call: %v", err)
+			}
+			var list []map[string]any
+			if err := json.Unmarshal([]byte(data), &list); err != nil {
+				t.Fatalf("invalid JSON %s: %v", data, err)
+			}
+			var got []string
+			for _, item := range list {
+				got = append(got, fmt.Sprint(item[tt.key]))
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("%s of %s = %v, want %v", tt.key, data, got, tt.want)
+			}
+		})
+	}
+
+	// Holdings decode back into the client's own type
+	data, err := c.HoldingsJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var holdings []stockal.Holding
+	if err := json.Unmarshal([]byte(data), &holdings); err != nil || holdings[0].TotalUnit != 10 || holdings[0].Price != 213.49 {
+		t.Errorf("holdings %+v, %v; want AAPL's 10 units at 213.49", holdings, err)
+	}
+
+	if data, err := c.QuotesJSON("TSLA"); err != nil || data != "[]" {
+		t.Errorf("QuotesJSON of an unknown symbol = %s, %v; want an empty array", data, err)
+	}
+}
+
+func TestQuote(t *testing.T) {
+	srv := stockaltest.NewServer()
+	defer srv.Close()
+	c := login(t, srv)
+
+	q, err := c.Quote("AAPL")
+	if err != nil {
+		t.Fatalf("Quote: %v", err)
+	}
+	want := mobile.Quote{Symbol: "AAPL", Price: 213.49, Open: 211.25, High: 213.95, Low: 209.58, PriorClose: 209.68,
+		Volume: 60107582, Timestamp: 1741982400}
+	if *q != want {
+		t.Errorf("Quote = %+v, want %+v", *q, want)
+	}
+
+	_, err = c.Quote("TSLA")
+	if err == nil || err.Error() != "no quote for TSLA" || mobile.ErrorKind(err) != mobile.ErrorKindOther {
+		t.Errorf("Quote of an unknown symbol = %v (%s), want no quote", err, mobile.ErrorKind(err))
+	}
+}
+
+func TestOrders(t *testing.T) {
+	srv := stockaltest.NewServer()
+	defer srv.Close()
+	var placed stockal.OrderRequest
+	srv.HandleFunc("POST /v2/orders", func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&placed)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"code":200,"message":"Success","data":{"orderID":"ord-2001","symbol":%q,"side":%q,"type":%q,`+
+			`"status":"new","quantity":%v,"limitPrice":%v,"createdAt":"2025-03-14T15:02:11Z"}}`,
+			placed.Symbol, placed.Side, placed.Type, placed.Quantity, placed.LimitPrice)
+	})
+	srv.HandleFunc("DELETE /v2/orders/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{"code":400,"message":"Order already filled"}`)
+	})
+	c := login(t, srv)
+
+	order, err := c.PlaceOrder("AAPL", "buy", "limit", 2, 0, 200)
+	if err != nil {
+		t.Fatalf("PlaceOrder: %v", err)
+	}
+	want := mobile.Order{ID: "ord-2001", Symbol: "AAPL", Side: "buy", Type: "limit", Status: "new", Quantity: 2, LimitPrice: 200,
+		CreatedAt: "2025-03-14T15:02:11Z"}
+	if *order != want {
+		t.Errorf("PlaceOrder = %+v, want %+v", *order, want)
+	}
+
+	tests := []struct {
+		name string
+		call func() error
+		kind string
+		want string
+	}{
+		{
+			name: "invalid order",
+			call: func() error { _, err := c.PlaceOrder("AAPL", "buy", "market", 2, 100, 0); return err },
+			kind: mobile.ErrorKindInvalidOrder,
+			want: "invalid order: ",
+		},
+		{
+			name: "no order ID",
+			call: func() error { _, err := c.ModifyOrder("", 3, 0, 0); return err },
+			kind: mobile.ErrorKindInvalidOrder,
+			want: "invalid order: order ID is required",
+		},
+		{
+			name: "API error",
+			call: func() error { _, err := c.CancelOrder("ord-1002"); return err },
+			kind: mobile.ErrorKindAPI,
+			want: "API error 400: Order already filled",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.call()
+			if err == nil || !strings.Contains(err.Error(), tt.want) || mobile.ErrorKind(err) != tt.kind {
+				t.Errorf("error %v (%s), want %q (%s)", err, mobile.ErrorKind(err), tt.want, tt.kind)
+			}
+		})
+	}
+}
+
+func TestCancel(t *testing.T) {
+	srv := stockaltest.NewServer()
+	defer srv.Close()
+	started := make(chan struct{}, 1)
+	srv.HandleFunc("GET /v2/users/portfolio/detail", func(w http.ResponseWriter, r *http.Request) {
+		started <- struct{}{}
+		<-r.Context().Done()
+	})
+	c := login(t, srv)
+
+	done := make(chan error)
+	go func() {
+		_, err := c.HoldingsJSON()
+		done <- err
+	}()
+	<-started
+	c.Cancel()
+	if err := <-done; mobile.ErrorKind(err) != mobile.ErrorKindCancelled {
+		t.Errorf("HoldingsJSON when cancelled = %v (%s), want kind %s", err, mobile.ErrorKind(err), mobile.ErrorKindCancelled)
+	}
+
+	// Calls after Cancel are not affected
+	if _, err := c.AccountSummary(); err != nil {
+		t.Errorf("AccountSummary after Cancel: %v", err)
+	}
+}
+
+func TestErrorKind(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{nil, mobile.ErrorKindNone},
+		{stockal.ErrNotAuthenticated, mobile.ErrorKindNotAuthenticated},
+		{fmt.Errorf("login: %w", stockal.ErrInvalidCredentials), mobile.ErrorKindNotAuthenticated},
+		{stockal.ErrLoginIncomplete, mobile.ErrorKindNotAuthenticated},
+		{fmt.Errorf("%w: quantity must be positive", stockal.ErrInvalidOrder), mobile.ErrorKindInvalidOrder},
+		{stockal.ErrRateLimited, mobile.ErrorKindRateLimited},
+		{stockal.ErrUpstreamUnavailable, mobile.ErrorKindUnavailable},
+		{context.Canceled, mobile.ErrorKindCancelled},
+		{fmt.Errorf("get portfolio: %w", context.DeadlineExceeded), mobile.ErrorKindCancelled},
+		{&stockal.APIError{Code: 500, Message: "Internal error"}, mobile.ErrorKindAPI},
+		{errors.New("no quote for TSLA"), mobile.ErrorKindOther},
+	}
+	for _, tt := range tests {
+		if got := mobile.ErrorKind(tt.err); got != tt.want {
+			t.Errorf("ErrorKind(%v) = %q, want %q", tt.err, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+	"github.com/adjaecent/unofficial-stockal-api/events"
+)
+
+// Discord embed colors.
+const (
+	discordGreen = 0x2ecc71
+	discordRed   = 0xe74c3c
+	discordBlue  = 0x3498db
+)
+
+// Discord posts events to a Discord channel webhook as embeds.
+type Discord struct {
+	webhookURL string
+	options
+}
+
+// NewDiscord creates a Discord sink posting to a channel webhook URL.
+func NewDiscord(webhookURL string, opts ...Option) *Discord {
+	return &Discord{
+		webhookURL: webhookURL,
+		options:    newOptions("", 10*time.Second, opts),
+	}
+}
+
+// Notify posts the event to the channel.
+func (d *Discord) Notify(ctx context.Context, event events.Event) error {
+	body, err := json.Marshal(map[string]any{
+		"username": "Stockal",
+		"embeds":   []discordEmbed{newDiscordEmbed(event)},
+	})
+	if err != nil {
+		return fmt.Errorf("discord: failed to marshal message: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("discord: failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := d.httpClient.Do(req)
+	if err != nil {
+		// The error quotes the URL, which is itself a credential for webhooks
+		return fmt.Errorf("discord: %w", stockal.RedactError(err))
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("discord: responded with status code %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+	return nil
+}
+
+type discordEmbed struct {
+	Title       string         `json:"title"`
+	Description string         `json:"description,omitempty"`
+	Color       int            `json:"color"`
+	Fields      []discordField `json:"fields,omitempty"`
+	Timestamp   string         `json:"timestamp"`
+}
+
+type discordField struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Inline bool   `json:"inline"`
+}
+
+// newDiscordEmbed renders an event as an embed, colored by direction.
+func newDiscordEmbed(event events.Event) discordEmbed {
+	e := discordEmbed{
+		Title:     Title(event),
+		Color:     discordBlue,
+		Timestamp: event.Time.UTC().Format(time.RFC3339),
+	}
+
+	switch data := event.Source.(type) {
+	case *stockal.Snapshot:
+		d := digest(event)
+		if d.DayChange < 0 {
+			e.Color = discordRed
+		} else if d.DayChange > 0 {
+			e.Color = discordGreen
+		}
+		e.Fields = discordFields(
+			"Value", money(d.TotalValue),
+			"Today", fmt.Sprintf("%s (%+.2f%%)", signedMoney(d.DayChange), d.DayChangePercent),
+			"Gain", signedMoney(d.TotalGain),
+			"Cash", money(d.Cash),
+		)
+		if len(d.Gainers) > 0 {
+			e.Fields = append(e.Fields, discordField{Name: "Gainers", Value: movers(d.Gainers)})
+		}
+		if len(d.Losers) > 0 {
+			e.Fields = append(e.Fields, discordField{Name: "Losers", Value: movers(d.Losers)})
+		}
+	case events.Alert:
+		e.Description = data.Message
+		if data.Condition == "below" {
+			e.Color = discordRed
+		} else {
+			e.Color = discordGreen
+		}
+		e.Fields = discordFields(
+			"Price", money(data.Price),
+			"Rule", fmt.Sprintf("%s %s", data.Condition, money(data.Threshold)),
+		)
+	case stockal.Order:
+		e.Fields = discordFields(
+			"Quantity", units(data.FilledQuantity),
+			"Average price", money(data.AveragePrice),
+			"Order", data.ID,
+		)
+	default:
+		e.Description = Text(event)
+	}
+	return e
+}
+
+// discordFields returns inline fields from name/value pairs.
+func discordFields(pairs ...string) []discordField {
+	var fields []discordField
+	for i := 0; i+1 < len(pairs); i += 2 {
+		fields = append(fields, discordField{Name: pairs[i], Value: pairs[i+1], Inline: true})
+	}
+	return fields
+}
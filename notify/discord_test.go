@@ -0,0 +1,146 @@
+package notify_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+	"github.com/adjaecent/unofficial-stockal-api/events"
+	"github.com/adjaecent/unofficial-stockal-api/notify"
+)
+
+// discordMessage is a Discord webhook payload.
+type discordMessage struct {
+	Username string `json:"username"`
+	Embeds   []struct {
+		Title       string `json:"title"`
+		Description string `json:"description"`
+		Color       int    `json:"color"`
+		Fields      []struct {
+			Name   string `json:"name"`
+			Value  string `json:"value"`
+			Inline bool   `json:"inline"`
+		} `json:"fields"`
+		Timestamp string `json:"timestamp"`
+	} `json:"embeds"`
+}
+
+func TestDiscord(t *testing.T) {
+	const (
+		green = 0x2ecc71
+		red   = 0xe74c3c
+		blue  = 0x3498db
+	)
+	loss := snapshotEvent()
+	loss.Source.(*stockal.Snapshot).Holdings[0].Price = 170
+
+	tests := []struct {
+		name        string
+		event       events.Event
+		title       string
+		description string
+		color       int
+		// fields are the embed's fields as "name: value"
+		fields []string
+	}{
+		{
+			name:        "alert",
+			event:       alertEvent(),
+			title:       "Price alert: AAPL",
+			description: "AAPL is $201.00",
+			color:       green,
+			fields:      []string{"Price: $201.00", "Rule: above $200.00"},
+		},
+		{
+			name:        "alert below",
+			event:       events.NewAlertEvent("personal", events.Alert{Symbol: "TSLA", Condition: "below", Threshold: 150, Price: 149, Message: "TSLA is $149.00"}),
+			title:       "Price alert: TSLA",
+			description: "TSLA is $149.00",
+			color:       red,
+			fields:      []string{"Price: $149.00", "Rule: below $150.00"},
+		},
+		{
+			name:  "snapshot",
+			event: snapshotEvent(),
+			title: "Portfolio digest Wed 1 May 2024",
+			color: green,
+			fields: []string{"Value: $2700.00", "Today: +$50.00 (+1.89%)", "Gain: +$200.00", "Cash: $300.00",
+				"Gainers: AAPL +5.56%", "Losers: TSLA -5.88%"},
+		},
+		{
+			name:   "snapshot down",
+			event:  loss,
+			title:  "Portfolio digest Wed 1 May 2024",
+			color:  red,
+			fields: []string{"Value: $2700.00", "Today: -$150.00 (-5.66%)", "Gain: +$200.00", "Cash: $300.00", "Losers: TSLA -5.88%, AAPL -5.56%"},
+		},
+		{
+			name:   "order",
+			event:  orderEvent(),
+			title:  "Order filled: BUY AAPL",
+			color:  blue,
+			fields: []string{"Quantity: 2.5", "Average price: $190.25", "Order: ord-1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			url, last := receive(t, http.StatusNoContent)
+			if err := notify.NewDiscord(url).Notify(context.Background(), tt.event); err != nil {
+				t.Fatalf("Notify: %v", err)
+			}
+
+			_, body := last()
+			var msg discordMessage
+			if err := json.Unmarshal(body, &msg); err != nil || len(msg.Embeds) != 1 {
+				t.Fatalf("payload %s, want one embed", body)
+			}
+			e := msg.Embeds[0]
+			if msg.Username != "Stockal" || e.Title != tt.title || e.Description != tt.description || e.Color != tt.color {
+				t.Errorf("embed %q: %q in %#x from %q, want %q: %q in %#x", e.Title, e.Description, e.Color, msg.Username, tt.title, tt.description, tt.color)
+			}
+			if e.Timestamp != tt.event.Time.UTC().Format(time.RFC3339) {
+				t.Errorf("timestamp %q, want the event time", e.Timestamp)
+			}
+			var fields []string
+			for _, f := range e.Fields {
+				fields = append(fields, f.Name+": "+f.Value)
+			}
+			if strings.Join(fields, "\n") != strings.Join(tt.fields, "\n") {
+				t.Errorf("fields %q, want %q", fields, tt.fields)
+			}
+		})
+	}
+}
+
+func TestDiscordStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"message": "Unknown Webhook", "code": 10015}`)
+	}))
+	defer server.Close()
+
+	err := notify.NewDiscord(server.URL+"/api/webhooks/1234567890/token").Notify(context.Background(), alertEvent())
+	if err == nil || !strings.HasPrefix(err.Error(), "discord: responded with status code 404: ") || !strings.Contains(err.Error(), "Unknown Webhook") {
+		t.Errorf("Notify = %v, want the status and Discord's message", err)
+	}
+}
+
+func TestDiscordUnreachable(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+
+	const token = "vJ4Rk9qL2xN7wT1pZ8sY3mC6bF0hD5gA-_uEoW2iXcVnB7"
+	err := notify.NewDiscord(server.URL+"/api/webhooks/1234567890/"+token).Notify(context.Background(), alertEvent())
+	if err == nil {
+		t.Fatal("Notify succeeded without a server")
+	}
+	if strings.Contains(err.Error(), token) {
+		t.Errorf("error %q quotes the webhook token", err)
+	}
+}
@@ -0,0 +1,82 @@
+package notify
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+	"github.com/adjaecent/unofficial-stockal-api/events"
+	"github.com/adjaecent/unofficial-stockal-api/report"
+)
+
+// digestMovers is the number of gainers and losers chat sinks list for a snapshot.
+const digestMovers = 3
+
+// Title returns a one-line summary of the event.
+func Title(e events.Event) string {
+	switch data := e.Source.(type) {
+	case *stockal.Snapshot:
+		return "Portfolio digest " + data.TakenAt.Format("Mon 2 Jan 2006")
+	case events.Alert:
+		return "Price alert: " + data.Symbol
+	case stockal.Order:
+		return fmt.Sprintf("Order %s: %s %s", strings.TrimPrefix(string(e.Type), "order."), strings.ToUpper(string(data.Side)), data.Symbol)
+	}
+	return string(e.Type)
+}
+
+// Text returns a plain-text description of the event, suitable for chat messages.
+func Text(e events.Event) string {
+	switch data := e.Source.(type) {
+	case *stockal.Snapshot:
+		d := report.New(data, digestMovers)
+		var b strings.Builder
+		fmt.Fprintf(&b, "Value: %s (%s today, %+.2f%%)\n", money(d.TotalValue), signedMoney(d.DayChange), d.DayChangePercent)
+		fmt.Fprintf(&b, "Gain: %s on %s invested\n", signedMoney(d.TotalGain), money(d.TotalInvested))
+		fmt.Fprintf(&b, "Cash: %s", money(d.Cash))
+		if len(d.Gainers) > 0 {
+			fmt.Fprintf(&b, "\nGainers: %s", movers(d.Gainers))
+		}
+		if len(d.Losers) > 0 {
+			fmt.Fprintf(&b, "\nLosers: %s", movers(d.Losers))
+		}
+		return b.String()
+	case events.Alert:
+		return data.Message
+	case stockal.Order:
+		return fmt.Sprintf("%s %s %s at %s (order %s)",
+			strings.ToUpper(string(data.Side)), units(data.FilledQuantity), data.Symbol, money(data.AveragePrice), data.ID)
+	}
+	return fmt.Sprintf("%v", e.Data)
+}
+
+// digest returns the report digest of a snapshot event, or nil for other events.
+func digest(e events.Event) *report.Digest {
+	if s, ok := e.Source.(*stockal.Snapshot); ok {
+		return report.New(s, digestMovers)
+	}
+	return nil
+}
+
+func movers(ms []report.Mover) string {
+	parts := make([]string, len(ms))
+	for i, m := range ms {
+		parts[i] = fmt.Sprintf("%s %+.2f%%", m.Symbol, m.DayChangePercent)
+	}
+	return strings.Join(parts, ", ")
+}
+
+func money(v float64) string {
+	return fmt.Sprintf("$%.2f", v)
+}
+
+func signedMoney(v float64) string {
+	if v < 0 {
+		return fmt.Sprintf("-$%.2f", -v)
+	}
+	return fmt.Sprintf("+$%.2f", v)
+}
+
+func units(v float64) string {
+	return strings.TrimRight(strings.TrimRight(fmt.Sprintf("%.4f", v), "0"), ".")
+}
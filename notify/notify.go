@@ -0,0 +1,97 @@
+// Package notify delivers account events, such as snapshots, triggered alerts
+// and filled orders, to external systems.
+//
+// Every sink implements Notifier, so producers can publish an events.Event
+// without knowing where it ends up:
+//
+//	sink := notify.NewWebhook("https://example.com/hooks/stockal", []byte(secret))
+//	if err := sink.Notify(ctx, events.NewSnapshotEvent("personal", snapshot)); err != nil {
+//		log.Print(err)
+//	}
+//
+// Webhook signs JSON events for arbitrary receivers; Telegram posts them as
+// chat messages and can also run as an interactive bot; Slack and Discord
+// format them as Block Kit messages and embeds. The chat sinks format
+// snapshots, orders and alerts from the event's Source.
+package notify
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/adjaecent/unofficial-stockal-api/events"
+)
+
+// Notifier delivers events to a destination.
+type Notifier interface {
+	Notify(ctx context.Context, event events.Event) error
+}
+
+// Option configures a sink. Options a sink has no use for are ignored.
+type Option func(*options)
+
+// options holds the settings shared by the sinks.
+type options struct {
+	apiURL     string
+	httpClient *http.Client
+	onError    func(err error)
+}
+
+// WithHTTPClient sets the HTTP client used to deliver events.
+func WithHTTPClient(client *http.Client) Option {
+	return func(o *options) {
+		o.httpClient = client
+	}
+}
+
+// WithAPIURL overrides the base URL of the Telegram Bot API, e.g. for a
+// self-hosted Bot API server, or of the Slack Web API used by bot sinks.
+func WithAPIURL(apiURL string) Option {
+	return func(o *options) {
+		o.apiURL = strings.TrimSuffix(apiURL, "/")
+	}
+}
+
+// WithErrorHandler sets a function called with the errors Telegram.Serve
+// recovers from, instead of logging them with the log package.
+func WithErrorHandler(h func(err error)) Option {
+	return func(o *options) {
+		o.onError = h
+	}
+}
+
+// newOptions applies opts over a sink's defaults: its API base URL, if it
+// has one, and a timeout for its HTTP client.
+func newOptions(apiURL string, timeout time.Duration, opts []Option) options {
+	o := options{
+		apiURL:     apiURL,
+		httpClient: &http.Client{Timeout: timeout},
+		onError:    func(err error) { log.Print(err) },
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// Multi returns a Notifier that delivers each event to every notifier in turn.
+// All notifiers are attempted; their errors are joined.
+func Multi(notifiers ...Notifier) Notifier {
+	return multi(notifiers)
+}
+
+type multi []Notifier
+
+func (m multi) Notify(ctx context.Context, event events.Event) error {
+	var errs []error
+	for _, n := range m {
+		if err := n.Notify(ctx, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
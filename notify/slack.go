@@ -0,0 +1,163 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+	"github.com/adjaecent/unofficial-stockal-api/events"
+)
+
+// SlackAPI is the base URL of the Slack Web API.
+const SlackAPI = "https://slack.com/api"
+
+// Slack posts events to a Slack channel as Block Kit messages, either through
+// an incoming webhook or as a bot with the chat:write scope.
+type Slack struct {
+	webhookURL string
+	token      string
+	channel    string
+	options
+}
+
+// NewSlackWebhook creates a Slack sink posting to an incoming webhook URL.
+func NewSlackWebhook(webhookURL string, opts ...Option) *Slack {
+	return &Slack{
+		webhookURL: webhookURL,
+		options:    newOptions("", 10*time.Second, opts),
+	}
+}
+
+// NewSlackBot creates a Slack sink posting to channel with a bot token.
+func NewSlackBot(token, channel string, opts ...Option) *Slack {
+	return &Slack{
+		token:   token,
+		channel: channel,
+		options: newOptions(SlackAPI, 10*time.Second, opts),
+	}
+}
+
+// Notify posts the event to the channel.
+func (s *Slack) Notify(ctx context.Context, event events.Event) error {
+	msg := map[string]any{
+		"text":   Title(event),
+		"blocks": slackBlocks(event),
+	}
+
+	url := s.webhookURL
+	if url == "" {
+		url = s.apiURL + "/chat.postMessage"
+		msg["channel"] = s.channel
+	}
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("slack: failed to marshal message: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("slack: failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	if s.token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.token)
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		// The error quotes the URL, which is a credential for incoming webhooks
+		return fmt.Errorf("slack: %w", stockal.RedactError(err, s.token))
+	}
+	defer resp.Body.Close()
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("slack: responded with status code %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+	if s.webhookURL == "" {
+		// The Web API reports failures in the body with a 200 status.
+		var result struct {
+			OK    bool   `json:"ok"`
+			Error string `json:"error"`
+		}
+		if err := json.Unmarshal(respBody, &result); err != nil {
+			return fmt.Errorf("slack: failed to parse response: %w", err)
+		}
+		if !result.OK {
+			return fmt.Errorf("slack: chat.postMessage: %s", result.Error)
+		}
+	}
+	return nil
+}
+
+// slackBlocks renders an event as Block Kit blocks.
+func slackBlocks(event events.Event) []map[string]any {
+	blocks := []map[string]any{{
+		"type": "header",
+		"text": slackText("plain_text", Title(event)),
+	}}
+
+	switch data := event.Source.(type) {
+	case *stockal.Snapshot:
+		d := digest(event)
+		blocks = append(blocks, slackFields(
+			"*Value*", money(d.TotalValue),
+			"*Today*", fmt.Sprintf("%s (%+.2f%%)", signedMoney(d.DayChange), d.DayChangePercent),
+			"*Gain*", signedMoney(d.TotalGain),
+			"*Cash*", money(d.Cash),
+		))
+		if len(d.Gainers) > 0 || len(d.Losers) > 0 {
+			var b strings.Builder
+			if len(d.Gainers) > 0 {
+				fmt.Fprintf(&b, ":chart_with_upwards_trend: *Gainers:* %s\n", movers(d.Gainers))
+			}
+			if len(d.Losers) > 0 {
+				fmt.Fprintf(&b, ":chart_with_downwards_trend: *Losers:* %s", movers(d.Losers))
+			}
+			blocks = append(blocks, map[string]any{
+				"type": "section",
+				"text": slackText("mrkdwn", strings.TrimSpace(b.String())),
+			})
+		}
+	case events.Alert:
+		blocks = append(blocks,
+			map[string]any{"type": "section", "text": slackText("mrkdwn", data.Message)},
+			slackFields(
+				"*Price*", money(data.Price),
+				"*Rule*", fmt.Sprintf("%s %s", data.Condition, money(data.Threshold)),
+			))
+	case stockal.Order:
+		blocks = append(blocks, slackFields(
+			"*Quantity*", units(data.FilledQuantity),
+			"*Average price*", money(data.AveragePrice),
+			"*Order*", data.ID,
+			"*Status*", string(data.Status),
+		))
+	default:
+		blocks = append(blocks, map[string]any{"type": "section", "text": slackText("plain_text", Text(event))})
+	}
+
+	return append(blocks, map[string]any{
+		"type":     "context",
+		"elements": []any{slackText("mrkdwn", fmt.Sprintf("<!date^%d^{date_short_pretty} {time}|%s>", event.Time.Unix(), event.Time.Format(time.RFC1123)))},
+	})
+}
+
+func slackText(kind, text string) map[string]any {
+	return map[string]any{"type": kind, "text": text}
+}
+
+// slackFields returns a section block of label/value pairs shown in two columns.
+func slackFields(pairs ...string) map[string]any {
+	var fields []any
+	for i := 0; i+1 < len(pairs); i += 2 {
+		fields = append(fields, slackText("mrkdwn", pairs[i]+"\n"+pairs[i+1]))
+	}
+	return map[string]any{"type": "section", "fields": fields}
+}
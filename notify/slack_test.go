@@ -0,0 +1,171 @@
+package notify_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/adjaecent/unofficial-stockal-api/events"
+	"github.com/adjaecent/unofficial-stockal-api/notify"
+)
+
+// slackMessage is the part of a chat.postMessage payload the tests check.
+type slackMessage struct {
+	Channel string `json:"channel"`
+	Text    string `json:"text"`
+	Blocks  []struct {
+		Type string `json:"type"`
+		Text *struct {
+			Type string `json:"type"`
+			Text string `json:"text"`
+		} `json:"text"`
+		Fields []struct {
+			Text string `json:"text"`
+		} `json:"fields"`
+		Elements []struct {
+			Text string `json:"text"`
+		} `json:"elements"`
+	} `json:"blocks"`
+}
+
+// texts returns the text of every block, field and context element in order.
+func (m slackMessage) texts() []string {
+	var texts []string
+	for _, b := range m.Blocks {
+		if b.Text != nil {
+			texts = append(texts, b.Text.Text)
+		}
+		for _, f := range b.Fields {
+			texts = append(texts, f.Text)
+		}
+		for _, e := range b.Elements {
+			texts = append(texts, e.Text)
+		}
+	}
+	return texts
+}
+
+func TestSlackWebhook(t *testing.T) {
+	tests := []struct {
+		name  string
+		event events.Event
+		text  string
+		want  []string
+	}{
+		{
+			name:  "alert",
+			event: alertEvent(),
+			text:  "Price alert: AAPL",
+			want:  []string{"Price alert: AAPL", "AAPL is $201.00", "*Price*\n$201.00", "*Rule*\nabove $200.00"},
+		},
+		{
+			name:  "snapshot",
+			event: snapshotEvent(),
+			text:  "Portfolio digest Wed 1 May 2024",
+			want: []string{"Portfolio digest Wed 1 May 2024", "*Value*\n$2700.00", "*Today*\n+$50.00 (+1.89%)",
+				"*Gain*\n+$200.00", "*Cash*\n$300.00",
+				":chart_with_upwards_trend: *Gainers:* AAPL +5.56%\n:chart_with_downwards_trend: *Losers:* TSLA -5.88%"},
+		},
+		{
+			name:  "order",
+			event: orderEvent(),
+			text:  "Order filled: BUY AAPL",
+			want:  []string{"Order filled: BUY AAPL", "*Quantity*\n2.5", "*Average price*\n$190.25", "*Order*\nord-1", "*Status*\nfilled"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			url, last := receive(t, http.StatusOK)
+			if err := notify.NewSlackWebhook(url).Notify(context.Background(), tt.event); err != nil {
+				t.Fatalf("Notify: %v", err)
+			}
+
+			req, body := last()
+			if req.Header.Get("Authorization") != "" {
+				t.Error("webhook request has an Authorization header")
+			}
+			var msg slackMessage
+			if err := json.Unmarshal(body, &msg); err != nil {
+				t.Fatalf("invalid payload %s: %v", body, err)
+			}
+			if msg.Text != tt.text || msg.Channel != "" {
+				t.Errorf("text %q, channel %q; want %q without a channel", msg.Text, msg.Channel, tt.text)
+			}
+			if msg.Blocks[0].Type != "header" || msg.Blocks[len(msg.Blocks)-1].Type != "context" {
+				t.Errorf("payload %s, want a header first and the time last", body)
+			}
+			texts := msg.texts()
+			if len(texts) != len(tt.want)+1 {
+				t.Fatalf("texts %q, want %q and the time", texts, tt.want)
+			}
+			for i, want := range tt.want {
+				if texts[i] != want {
+					t.Errorf("text %d = %q, want %q", i, texts[i], want)
+				}
+			}
+			if timestamp := texts[len(texts)-1]; !strings.HasPrefix(timestamp, fmt.Sprintf("<!date^%d^", tt.event.Time.Unix())) {
+				t.Errorf("time %q, want a Slack date of the event time", timestamp)
+			}
+		})
+	}
+}
+
+func TestSlackBot(t *testing.T) {
+	var msg slackMessage
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/chat.postMessage" {
+			t.Errorf("posted to %s, want chat.postMessage", r.URL.Path)
+		}
+		if auth := r.Header.Get("Authorization"); auth != "Bearer xoxb-token" {
+			t.Errorf("Authorization %q, want the bot token", auth)
+		}
+		json.NewDecoder(r.Body).Decode(&msg)
+		fmt.Fprint(w, `{"ok":true,"channel":"C123","ts":"1714593900.000100"}`)
+	}))
+	defer server.Close()
+
+	bot := notify.NewSlackBot("xoxb-token", "#stocks", notify.WithAPIURL(server.URL+"/api/"))
+	if err := bot.Notify(context.Background(), alertEvent()); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+	if msg.Channel != "#stocks" || msg.Text != "Price alert: AAPL" {
+		t.Errorf("posted %+v, want the alert in #stocks", msg)
+	}
+}
+
+func TestSlackErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		bot    bool
+		status int
+		body   string
+		want   string
+	}{
+		{name: "webhook status", status: http.StatusNotFound, body: "no_service", want: "slack: responded with status code 404: no_service"},
+		{name: "bot status", bot: true, status: http.StatusInternalServerError, body: "oops", want: "slack: responded with status code 500: oops"},
+		{name: "bot error", bot: true, status: http.StatusOK, body: `{"ok":false,"error":"channel_not_found"}`, want: "slack: chat.postMessage: channel_not_found"},
+		{name: "bot invalid response", bot: true, status: http.StatusOK, body: "ok", want: "slack: failed to parse response"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				fmt.Fprint(w, tt.body)
+			}))
+			defer server.Close()
+
+			sink := notify.NewSlackWebhook(server.URL)
+			if tt.bot {
+				sink = notify.NewSlackBot("xoxb-token", "#stocks", notify.WithAPIURL(server.URL))
+			}
+			err := sink.Notify(context.Background(), alertEvent())
+			if err == nil || !strings.HasPrefix(err.Error(), tt.want) {
+				t.Errorf("Notify = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
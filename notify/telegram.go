@@ -0,0 +1,192 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+	"github.com/adjaecent/unofficial-stockal-api/events"
+)
+
+// TelegramAPI is the base URL of the Telegram Bot API.
+const TelegramAPI = "https://api.telegram.org"
+
+// telegramPollTimeout is how long a getUpdates call waits for new messages.
+const telegramPollTimeout = 50 * time.Second
+
+// telegramMaxBackoff caps the wait between retries of a failing getUpdates call.
+const telegramMaxBackoff = time.Minute
+
+// telegramBackoff is the wait after the first failed getUpdates call; it
+// doubles with each further failure. Tests shorten it.
+var telegramBackoff = time.Second
+
+// Telegram sends events as messages from a Telegram bot to a chat.
+//
+// Create a bot with @BotFather to get a token, then message it once and read
+// the chat ID from https://api.telegram.org/bot<token>/getUpdates.
+type Telegram struct {
+	token  string
+	chatID string
+	options
+}
+
+// NewTelegram creates a Telegram sink for the bot token and chat ID. chatID may
+// also be a public channel username such as "@mychannel".
+func NewTelegram(token, chatID string, opts ...Option) *Telegram {
+	return &Telegram{
+		token:   token,
+		chatID:  chatID,
+		options: newOptions(TelegramAPI, telegramPollTimeout+10*time.Second, opts),
+	}
+}
+
+// Notify sends the event to the configured chat.
+func (t *Telegram) Notify(ctx context.Context, event events.Event) error {
+	return t.Send(ctx, t.chatID, Title(event)+"\n\n"+Text(event))
+}
+
+// Send sends a plain-text message to a chat.
+func (t *Telegram) Send(ctx context.Context, chatID, text string) error {
+	return t.call(ctx, "sendMessage", map[string]any{"chat_id": chatID, "text": text}, nil)
+}
+
+// TelegramHandler answers a bot command. command is the command name without
+// the leading slash (e.g., "quote") and args are the words that followed it.
+type TelegramHandler func(ctx context.Context, command string, args []string) (string, error)
+
+// Serve runs the bot interactively until ctx is cancelled, answering commands
+// with handler. Only messages from the configured chat are answered, so the bot
+// does not reveal account data to strangers who find it.
+//
+// Failures to fetch messages or send replies do not stop the bot: they are
+// logged, or passed to the WithErrorHandler function, and fetching is retried
+// with exponential backoff. Serve returns nil once ctx is cancelled.
+func (t *Telegram) Serve(ctx context.Context, handler TelegramHandler) error {
+	var offset int64
+	backoff := telegramBackoff
+	for {
+		var updates []telegramUpdate
+		err := t.call(ctx, "getUpdates", map[string]any{
+			"offset":          offset,
+			"timeout":         int(telegramPollTimeout.Seconds()),
+			"allowed_updates": []string{"message"},
+		}, &updates)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			t.onError(err)
+			timer := time.NewTimer(backoff)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return nil
+			case <-timer.C:
+			}
+			backoff = min(2*backoff, telegramMaxBackoff)
+			continue
+		}
+		backoff = telegramBackoff
+
+		for _, u := range updates {
+			offset = u.UpdateID + 1
+			if u.Message == nil || !t.fromChat(u.Message.Chat) {
+				continue
+			}
+			command, args, ok := parseCommand(u.Message.Text)
+			if !ok {
+				continue
+			}
+
+			reply, err := handler(ctx, command, args)
+			if err != nil {
+				reply = "Error: " + err.Error()
+			}
+			if reply == "" {
+				continue
+			}
+			if err := t.Send(ctx, strconv.FormatInt(u.Message.Chat.ID, 10), reply); err != nil {
+				if ctx.Err() != nil {
+					return nil
+				}
+				t.onError(err)
+			}
+		}
+	}
+}
+
+func (t *Telegram) fromChat(chat telegramChat) bool {
+	return t.chatID == strconv.FormatInt(chat.ID, 10) ||
+		chat.Username != "" && strings.EqualFold(t.chatID, "@"+chat.Username)
+}
+
+// parseCommand splits "/quote@MyBot TSLA" into "quote" and ["TSLA"].
+func parseCommand(text string) (command string, args []string, ok bool) {
+	fields := strings.Fields(text)
+	if len(fields) == 0 || !strings.HasPrefix(fields[0], "/") {
+		return "", nil, false
+	}
+	command, _, _ = strings.Cut(fields[0][1:], "@")
+	return strings.ToLower(command), fields[1:], true
+}
+
+type telegramUpdate struct {
+	UpdateID int64            `json:"update_id"`
+	Message  *telegramMessage `json:"message"`
+}
+
+type telegramMessage struct {
+	Chat telegramChat `json:"chat"`
+	Text string       `json:"text"`
+}
+
+type telegramChat struct {
+	ID       int64  `json:"id"`
+	Username string `json:"username"`
+}
+
+// call invokes a Bot API method, decoding its result into result if non-nil.
+func (t *Telegram) call(ctx context.Context, method string, params any, result any) error {
+	body, err := json.Marshal(params)
+	if err != nil {
+		return fmt.Errorf("telegram: failed to marshal %s: %w", method, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.apiURL+"/bot"+t.token+"/"+method, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("telegram: failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := t.httpClient.Do(req)
+	if err != nil {
+		// The request URL contains the bot token; keep it out of the error.
+		return fmt.Errorf("telegram: %s: %w", method, stockal.RedactError(err, t.token))
+	}
+	defer resp.Body.Close()
+
+	var envelope struct {
+		OK          bool            `json:"ok"`
+		Description string          `json:"description"`
+		Result      json.RawMessage `json:"result"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil {
+		return fmt.Errorf("telegram: %s responded with status code %d", method, resp.StatusCode)
+	}
+	if !envelope.OK {
+		return fmt.Errorf("telegram: %s: %s", method, envelope.Description)
+	}
+	if result != nil {
+		if err := json.Unmarshal(envelope.Result, result); err != nil {
+			return fmt.Errorf("telegram: failed to decode %s result: %w", method, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseCommand(t *testing.T) {
+	tests := []struct {
+		text    string
+		command string
+		args    []string
+		ok      bool
+	}{
+		{text: "/summary", command: "summary", ok: true},
+		{text: "/quote TSLA AAPL", command: "quote", args: []string{"TSLA", "AAPL"}, ok: true},
+		{text: "/Quote@MyStockalBot  tsla ", command: "quote", args: []string{"tsla"}, ok: true},
+		{text: "  /portfolio\n", command: "portfolio", ok: true},
+		{text: "/", command: "", ok: true},
+		{text: "hello /summary"},
+		{text: ""},
+		{text: "   "},
+	}
+	for _, tt := range tests {
+		command, args, ok := parseCommand(tt.text)
+		if command != tt.command || !slices.Equal(args, tt.args) || ok != tt.ok {
+			t.Errorf("parseCommand(%q) = %q, %q, %v; want %q, %q, %v", tt.text, command, args, ok, tt.command, tt.args, tt.ok)
+		}
+	}
+}
+
+func TestFromChat(t *testing.T) {
+	tests := []struct {
+		chatID string
+		chat   telegramChat
+		want   bool
+	}{
+		{chatID: "42", chat: telegramChat{ID: 42}, want: true},
+		{chatID: "-1001234", chat: telegramChat{ID: -1001234}, want: true},
+		{chatID: "42", chat: telegramChat{ID: 43}},
+		{chatID: "@MyChannel", chat: telegramChat{ID: 7, Username: "mychannel"}, want: true},
+		{chatID: "@mychannel", chat: telegramChat{ID: 7, Username: "otherchannel"}},
+		{chatID: "mychannel", chat: telegramChat{ID: 7, Username: "mychannel"}},
+		{chatID: "@", chat: telegramChat{ID: 7}},
+		{chatID: "", chat: telegramChat{}},
+	}
+	for _, tt := range tests {
+		if got := NewTelegram("token", tt.chatID).fromChat(tt.chat); got != tt.want {
+			t.Errorf("fromChat(%+v) for chat %q = %v, want %v", tt.chat, tt.chatID, got, tt.want)
+		}
+	}
+}
+
+func TestServeRecovers(t *testing.T) {
+	backoff := telegramBackoff
+	telegramBackoff = time.Millisecond
+	t.Cleanup(func() { telegramBackoff = backoff })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var (
+		polls int
+		sent  []string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var params map[string]any
+		json.NewDecoder(r.Body).Decode(&params)
+		switch r.URL.Path {
+		case "/botTOKEN/getUpdates":
+			polls++
+			switch polls {
+			case 1:
+				w.WriteHeader(http.StatusBadGateway)
+				fmt.Fprint(w, "<html>Bad gateway</html>")
+			case 2:
+				fmt.Fprint(w, `{"ok":true,"result":[
+					{"update_id":10,"message":{"chat":{"id":99},"text":"/ping stranger"}},
+					{"update_id":11,"message":{"chat":{"id":42},"text":"/ping first"}},
+					{"update_id":12,"message":{"chat":{"id":42},"text":"just chatting"}},
+					{"update_id":13,"message":{"chat":{"id":42},"text":"/ping second"}}]}`)
+			default:
+				if offset := params["offset"]; offset != float64(14) {
+					t.Errorf("getUpdates offset %v, want 14", offset)
+				}
+				cancel()
+				fmt.Fprint(w, `{"ok":true,"result":[]}`)
+			}
+		case "/botTOKEN/sendMessage":
+			text := fmt.Sprint(params["text"])
+			if params["chat_id"] != "42" {
+				t.Errorf("replied to chat %v", params["chat_id"])
+			}
+			if len(sent) == 0 {
+				sent = append(sent, "failed: "+text)
+				fmt.Fprint(w, `{"ok":false,"description":"Too Many Requests: retry after 1"}`)
+				return
+			}
+			sent = append(sent, text)
+			fmt.Fprint(w, `{"ok":true,"result":{}}`)
+		default:
+			t.Errorf("unexpected request %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	var errs []string
+	bot := NewTelegram("TOKEN", "42", WithAPIURL(server.URL), WithHTTPClient(server.Client()),
+		WithErrorHandler(func(err error) { errs = append(errs, err.Error()) }))
+	err := bot.Serve(ctx, func(ctx context.Context, command string, args []string) (string, error) {
+		return "pong " + strings.Join(args, " "), nil
+	})
+	if err != nil {
+		t.Fatalf("Serve = %v, want nil once cancelled", err)
+	}
+
+	if want := []string{"failed: pong first", "pong second"}; !slices.Equal(sent, want) {
+		t.Errorf("sent %q, want %q", sent, want)
+	}
+	if len(errs) != 2 || !strings.Contains(errs[0], "getUpdates") || !strings.Contains(errs[1], "Too Many Requests") {
+		t.Errorf("errors %q, want the failed poll and reply", errs)
+	}
+}
+
+func TestServeCancelledWhileBackingOff(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"ok":false,"description":"Unauthorized"}`)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	bot := NewTelegram("TOKEN", "42", WithAPIURL(server.URL), WithHTTPClient(server.Client()),
+		WithErrorHandler(func(err error) { cancel() }))
+	done := make(chan error, 1)
+	go func() { done <- bot.Serve(ctx, nil) }()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Serve = %v, want nil once cancelled", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Serve did not return after ctx was cancelled")
+	}
+}
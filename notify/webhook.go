@@ -0,0 +1,99 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+	"github.com/adjaecent/unofficial-stockal-api/events"
+)
+
+// Headers set on every webhook request.
+const (
+	HeaderEvent     = "X-Stockal-Event"
+	HeaderTimestamp = "X-Stockal-Timestamp"
+	HeaderSignature = "X-Stockal-Signature"
+)
+
+// Webhook posts events as JSON to a URL, in the envelope events.SchemaJSON
+// describes.
+//
+// When a secret is configured, each request carries an HMAC-SHA256 signature
+// of "<timestamp>.<body>" in the X-Stockal-Signature header (as "sha256=<hex>"),
+// with the Unix timestamp in X-Stockal-Timestamp. Receivers should check it
+// with VerifySignature and reject stale timestamps.
+type Webhook struct {
+	url    string
+	secret []byte
+	options
+}
+
+// NewWebhook creates a Webhook posting to url. secret may be empty to disable signing.
+func NewWebhook(url string, secret []byte, opts ...Option) *Webhook {
+	return &Webhook{
+		url:     url,
+		secret:  secret,
+		options: newOptions("", 10*time.Second, opts),
+	}
+}
+
+// Notify posts the event. Any non-2xx response is an error.
+func (w *Webhook) Notify(ctx context.Context, event events.Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("webhook: failed to marshal event: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("webhook: failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, string(event.Type))
+	if len(w.secret) > 0 {
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		req.Header.Set(HeaderTimestamp, timestamp)
+		req.Header.Set(HeaderSignature, "sha256="+sign(w.secret, timestamp, body))
+	}
+
+	resp, err := w.httpClient.Do(req)
+	if err != nil {
+		// The error quotes the URL, which may carry a token
+		return fmt.Errorf("webhook: %w", stockal.RedactError(err))
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook: %s responded with status code: %d", stockal.RedactURL(w.url), resp.StatusCode)
+	}
+	return nil
+}
+
+// VerifySignature reports whether signature (the X-Stockal-Signature header)
+// matches body and timestamp for the shared secret. It is false for an empty
+// secret, since webhooks without one do not sign.
+func VerifySignature(secret []byte, timestamp string, body []byte, signature string) bool {
+	if len(secret) == 0 {
+		return false
+	}
+	expected := "sha256=" + sign(secret, timestamp, body)
+	return hmac.Equal([]byte(expected), []byte(signature))
+}
+
+func sign(secret []byte, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
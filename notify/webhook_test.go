@@ -0,0 +1,166 @@
+package notify_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+	"github.com/adjaecent/unofficial-stockal-api/events"
+	"github.com/adjaecent/unofficial-stockal-api/notify"
+)
+
+// receive starts a webhook receiver and returns its URL and the last request
+// it received, with its body.
+func receive(t *testing.T, status int) (string, func() (*http.Request, []byte)) {
+	t.Helper()
+	var req *http.Request
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req = r
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+	return server.URL, func() (*http.Request, []byte) { return req, body }
+}
+
+func alertEvent() events.Event {
+	return events.NewAlertEvent("personal", events.Alert{Symbol: "AAPL", Condition: "above", Threshold: 200, Price: 201, Message: "AAPL is $201.00"})
+}
+
+// snapshotEvent is a snapshot of a portfolio up $50 on the day, with one
+// gainer and one loser.
+func snapshotEvent() events.Event {
+	s := &stockal.Snapshot{
+		TakenAt: time.Date(2024, 5, 1, 20, 5, 0, 0, time.UTC),
+		Holdings: []stockal.Holding{
+			{Symbol: "AAPL", TotalUnit: 10, Price: 190, PriorClose: 180, TotalInvestment: 1500},
+			{Symbol: "TSLA", TotalUnit: 5, Price: 160, PriorClose: 170, TotalInvestment: 1000},
+		},
+	}
+	s.Summary.PortfolioSummary.TotalCurrentValue = 2700
+	s.Summary.PortfolioSummary.TotalInvestmentAmount = 2500
+	s.Summary.AccountSummary.CashAvailableForTrade = 300
+	return events.NewSnapshotEvent("personal", s)
+}
+
+func orderEvent() events.Event {
+	return events.NewOrderEvent("personal", stockal.Order{
+		ID: "ord-1", Symbol: "AAPL", Side: stockal.OrderSideBuy, Status: stockal.OrderStatusFilled, FilledQuantity: 2.5, AveragePrice: 190.25,
+	}, string(stockal.OrderStatusNew))
+}
+
+func TestWebhookSigned(t *testing.T) {
+	url, last := receive(t, http.StatusNoContent)
+	secret := []byte("s3cret")
+	if err := notify.NewWebhook(url, secret).Notify(context.Background(), alertEvent()); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+
+	req, body := last()
+	if got := req.Header.Get(notify.HeaderEvent); got != string(events.TypeAlertTriggered) {
+		t.Errorf("%s = %q, want %q", notify.HeaderEvent, got, events.TypeAlertTriggered)
+	}
+	timestamp, signature := req.Header.Get(notify.HeaderTimestamp), req.Header.Get(notify.HeaderSignature)
+	if !notify.VerifySignature(secret, timestamp, body, signature) {
+		t.Errorf("signature %q does not verify", signature)
+	}
+	var e events.Event
+	if err := json.Unmarshal(body, &e); err != nil || e.Type != events.TypeAlertTriggered || e.Account != "personal" {
+		t.Errorf("body %s, want the alert event", body)
+	}
+}
+
+func TestWebhookUnsigned(t *testing.T) {
+	for name, secret := range map[string][]byte{"nil": nil, "empty": {}} {
+		t.Run(name, func(t *testing.T) {
+			url, last := receive(t, http.StatusOK)
+			if err := notify.NewWebhook(url, secret).Notify(context.Background(), alertEvent()); err != nil {
+				t.Fatalf("Notify: %v", err)
+			}
+			req, _ := last()
+			if req.Header.Get(notify.HeaderSignature) != "" || req.Header.Get(notify.HeaderTimestamp) != "" {
+				t.Errorf("request signed without a secret: %v", req.Header)
+			}
+		})
+	}
+}
+
+func TestWebhookStatus(t *testing.T) {
+	url, _ := receive(t, http.StatusInternalServerError)
+	if err := notify.NewWebhook(url+"/hook?token=abc", nil).Notify(context.Background(), alertEvent()); err == nil {
+		t.Error("Notify accepted a 500 response")
+	} else if stockal.Redact(err.Error()) != err.Error() {
+		t.Errorf("error %q quotes the token", err)
+	}
+}
+
+func TestVerifySignature(t *testing.T) {
+	secret := []byte("s3cret")
+	body := []byte(`{"type":"alert.triggered"}`)
+	// echo -n '1714573800.{"type":"alert.triggered"}' | openssl dgst -sha256 -hmac s3cret
+	signature := "sha256=c586fba803902f83f0b4fc72941567e39fde8e2cd2a11492d2c324afcd960968"
+
+	tests := []struct {
+		name      string
+		secret    []byte
+		timestamp string
+		body      []byte
+		signature string
+		want      bool
+	}{
+		{name: "valid", secret: secret, timestamp: "1714573800", body: body, signature: signature, want: true},
+		{name: "wrong secret", secret: []byte("other"), timestamp: "1714573800", body: body, signature: signature},
+		{name: "other timestamp", secret: secret, timestamp: "1714573801", body: body, signature: signature},
+		{name: "tampered body", secret: secret, timestamp: "1714573800", body: []byte(`{"type":"order.filled"}`), signature: signature},
+		{name: "missing prefix", secret: secret, timestamp: "1714573800", body: body, signature: signature[len("sha256="):]},
+		{
+			// The signature is the HMAC with an empty key, which must not count
+			name: "empty secret", secret: []byte{}, timestamp: "1714573800", body: body,
+			signature: "sha256=526cf93318ef22bec5be70fbe88eef24e8a62eb1cb6f4f78556de38eb1056208",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := notify.VerifySignature(tt.secret, tt.timestamp, tt.body, tt.signature); got != tt.want {
+				t.Errorf("VerifySignature = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// roundTripFunc is an http.RoundTripper calling a function.
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
+
+func TestWithHTTPClient(t *testing.T) {
+	var hosts []string
+	client := &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		hosts = append(hosts, r.URL.Host)
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(`{"ok":true}`)), Request: r}, nil
+	})}
+
+	sinks := []notify.Notifier{
+		notify.NewWebhook("https://hooks.example.com/stockal", nil, notify.WithHTTPClient(client)),
+		notify.NewSlackWebhook("https://hooks.slack.com/services/T0/B0/x", notify.WithHTTPClient(client)),
+		notify.NewSlackBot("xoxb-token", "#stocks", notify.WithHTTPClient(client), notify.WithAPIURL("https://slack.example.com/api")),
+		notify.NewDiscord("https://discord.com/api/webhooks/1/x", notify.WithHTTPClient(client)),
+		notify.NewTelegram("TOKEN", "42", notify.WithHTTPClient(client), notify.WithAPIURL("https://telegram.example.com/")),
+	}
+	for _, sink := range sinks {
+		if err := sink.Notify(context.Background(), alertEvent()); err != nil {
+			t.Errorf("%T: Notify: %v", sink, err)
+		}
+	}
+	want := []string{"hooks.example.com", "hooks.slack.com", "slack.example.com", "discord.com", "telegram.example.com"}
+	if strings.Join(hosts, " ") != strings.Join(want, " ") {
+		t.Errorf("requested %q through the client, want %q", hosts, want)
+	}
+}
@@ -0,0 +1,173 @@
+package stockal
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// OrderQuerier searches the order history: a page at a time, or only the
+// orders matching a filter.
+type OrderQuerier interface {
+	ListWithOptions(ctx context.Context, opts OrderListOptions) (*OrderListResponse, error)
+}
+
+// OrderListOptions selects orders from the order history. The zero value
+// selects the open and recent orders, as List does.
+type OrderListOptions struct {
+	// Symbol, if set, selects the orders for one symbol
+	Symbol string
+	// Status, if set, selects the orders with one status
+	Status OrderStatus
+	// From, if set, selects the orders placed at or after it
+	From time.Time
+	// To, if set, selects the orders placed before it
+	To time.Time
+
+	// Limit is the maximum number of orders to return; zero means the API's
+	// default
+	Limit int
+	// Offset is the number of orders to skip
+	Offset int
+	// Cursor continues a listing where a previous page ended, from its
+	// NextCursor; it replaces Offset
+	Cursor string
+}
+
+// Validate checks the options before they are sent. The returned error wraps
+// ErrInvalidParams.
+func (o OrderListOptions) Validate() error {
+	switch {
+	case o.Limit < 0:
+		return fmt.Errorf("%w: negative limit %d", ErrInvalidParams, o.Limit)
+	case o.Offset < 0:
+		return fmt.Errorf("%w: negative offset %d", ErrInvalidParams, o.Offset)
+	case o.Offset > 0 && o.Cursor != "":
+		return fmt.Errorf("%w: both an offset and a cursor given", ErrInvalidParams)
+	case !o.From.IsZero() && !o.To.IsZero() && !o.From.Before(o.To):
+		return fmt.Errorf("%w: empty date range %s to %s", ErrInvalidParams,
+			o.From.Format(time.RFC3339), o.To.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// matches reports whether o passes the symbol, status and date filters.
+// Orders without a placement time pass the date filters.
+func (o OrderListOptions) matches(order Order) bool {
+	if o.Symbol != "" && !strings.EqualFold(order.Symbol, strings.TrimSpace(o.Symbol)) {
+		return false
+	}
+	if o.Status != "" && order.Status != o.Status {
+		return false
+	}
+	placed := order.CreatedAt.Time
+	if placed.IsZero() {
+		return true
+	}
+	return (o.From.IsZero() || !placed.Before(o.From)) && (o.To.IsZero() || placed.Before(o.To))
+}
+
+// query returns the options as a query string, without the leading "?".
+func (o OrderListOptions) query() string {
+	query := url.Values{}
+	if symbol := strings.TrimSpace(o.Symbol); symbol != "" {
+		query.Set("symbol", strings.ToUpper(symbol))
+	}
+	if o.Status != "" {
+		query.Set("status", string(o.Status))
+	}
+	if !o.From.IsZero() {
+		query.Set("from", o.From.UTC().Format(time.RFC3339))
+	}
+	if !o.To.IsZero() {
+		query.Set("to", o.To.UTC().Format(time.RFC3339))
+	}
+	if o.Limit > 0 {
+		query.Set("limit", strconv.Itoa(o.Limit))
+	}
+	if o.Offset > 0 {
+		query.Set("offset", strconv.Itoa(o.Offset))
+	}
+	if o.Cursor != "" {
+		query.Set("cursor", o.Cursor)
+	}
+	return query.Encode()
+}
+
+// ListWithOptions retrieves the orders opts select from the order
+// history, open and historic, so that accounts with thousands of orders can
+// be read a page at a time. Data.TotalRecords is the number of orders
+// matching the filters, and Data.NextCursor, when the API pages by cursor,
+// continues the listing.
+//
+// The filters are applied by the API. The client applies them to the response
+// as well, so that a filtered call never returns other orders.
+//
+// Example:
+//
+//	opts := stockal.OrderListOptions{Symbol: "AAPL", Status: stockal.OrderStatusFilled, Limit: 100}
+//	orders, err := client.Orders().ListWithOptions(ctx, opts)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+func (s *OrdersService) ListWithOptions(ctx context.Context, opts OrderListOptions) (*OrderListResponse, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	endpoint := "/v2/orders"
+	if query := opts.query(); query != "" {
+		endpoint += "?" + query
+	}
+	var listResp OrderListResponse
+	if err := s.client.do(ctx, "GET", endpoint, nil, &listResp, "list orders"); err != nil {
+		return nil, err
+	}
+
+	orders := listResp.Data.Orders[:0]
+	for _, o := range listResp.Data.Orders {
+		if opts.matches(o) {
+			orders = append(orders, o)
+		}
+	}
+	listResp.Data.Orders = orders
+	return &listResp, nil
+}
+
+// OrderIterator returns an Iterator over every order opts select, reading
+// Limit orders per request (100 if unset) and following NextCursor when the
+// API pages by cursor and Offset otherwise.
+func OrderIterator(q OrderQuerier, opts OrderListOptions) *Iterator[Order] {
+	if opts.Limit == 0 {
+		opts.Limit = defaultPageSize
+	}
+	return newIterator("read orders", func(o Order) string { return o.ID }, func(ctx context.Context) ([]Order, bool, error) {
+		page, err := q.ListWithOptions(ctx, opts)
+		if err != nil {
+			return nil, false, err
+		}
+		orders := page.Data.Orders
+		switch {
+		case page.Data.NextCursor != "":
+			opts.Cursor, opts.Offset = page.Data.NextCursor, 0
+			return orders, true, nil
+		case opts.Cursor != "":
+			// The last page of a cursor listing
+			return orders, false, nil
+		default:
+			opts.Offset += len(orders)
+			return orders, len(orders) > 0 && opts.Offset < page.Data.TotalRecords, nil
+		}
+	})
+}
+
+// AllOrders reads every order opts select, as OrderIterator does. If ctx is
+// cancelled between pages, it returns the orders read so far with a
+// *PartialError naming their IDs; to resume, call it again with the Cursor or
+// Offset advanced past them.
+func AllOrders(ctx context.Context, q OrderQuerier, opts OrderListOptions) ([]Order, error) {
+	return OrderIterator(q, opts).All(ctx)
+}
@@ -0,0 +1,333 @@
+package stockal
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// ErrInvalidOrder is returned when an order request fails client-side validation.
+var ErrInvalidOrder = errors.New("invalid order")
+
+// Trader places and manages orders.
+type Trader interface {
+	Place(ctx context.Context, order OrderRequest) (*OrderResponse, error)
+	Cancel(ctx context.Context, orderID string) (*OrderResponse, error)
+	Get(ctx context.Context, orderID string) (*OrderResponse, error)
+	List(ctx context.Context) (*OrderListResponse, error)
+}
+
+// OrderSide is the direction of an order.
+type OrderSide string
+
+// Order sides.
+const (
+	OrderSideBuy  OrderSide = "buy"
+	OrderSideSell OrderSide = "sell"
+)
+
+// OrderType is the execution type of an order.
+type OrderType string
+
+// Order types.
+const (
+	OrderTypeMarket OrderType = "market"
+	OrderTypeLimit  OrderType = "limit"
+)
+
+// OrderModifier changes open orders.
+type OrderModifier interface {
+	Modify(ctx context.Context, orderID string, changes OrderChanges) (*OrderResponse, error)
+}
+
+// OrderManager places, changes and lists orders. OrdersService implements
+// it.
+type OrderManager interface {
+	Trader
+	OrderModifier
+	OrderQuerier
+}
+
+// OrderStatus is the execution status of an order. Stockal may add statuses,
+// so other values should be expected.
+type OrderStatus string
+
+// Order statuses.
+const (
+	OrderStatusNew             OrderStatus = "new"
+	OrderStatusPartiallyFilled OrderStatus = "partially_filled"
+	OrderStatusFilled          OrderStatus = "filled"
+	OrderStatusCancelled       OrderStatus = "cancelled"
+	OrderStatusRejected        OrderStatus = "rejected"
+	OrderStatusExpired         OrderStatus = "expired"
+)
+
+// IsKnown reports whether s is one of the declared statuses.
+func (s OrderStatus) IsKnown() bool {
+	switch s {
+	case OrderStatusNew, OrderStatusPartiallyFilled, OrderStatusFilled,
+		OrderStatusCancelled, OrderStatusRejected, OrderStatusExpired:
+		return true
+	}
+	return false
+}
+
+// IsOpen reports whether an order with status s may still be filled,
+// modified or cancelled.
+func (s OrderStatus) IsOpen() bool {
+	return s == OrderStatusNew || s == OrderStatusPartiallyFilled
+}
+
+// UnmarshalJSON decodes a status, matching the declared ones regardless of
+// case and surrounding space, and the American spelling "canceled". Unknown
+// statuses are kept as sent.
+func (s *OrderStatus) UnmarshalJSON(data []byte) error {
+	v, err := decodeEnum(data, OrderStatusNew, OrderStatusPartiallyFilled, OrderStatusFilled,
+		OrderStatusCancelled, OrderStatusRejected, OrderStatusExpired, "canceled")
+	if v == "canceled" {
+		v = OrderStatusCancelled
+	}
+	*s = v
+	return err
+}
+
+// OrderRequest represents the request payload for placing an order.
+//
+// Exactly one of Quantity or Amount must be set. Amount places a notional
+// (dollar-amount) order, which Stockal supports for fractional investing.
+type OrderRequest struct {
+	// Symbol is the stock symbol to trade (e.g., "AAPL")
+	Symbol string `json:"symbol"`
+	// Side is buy or sell
+	Side OrderSide `json:"side"`
+	// Type is market or limit
+	Type OrderType `json:"type"`
+	// Quantity is the number of shares to trade
+	Quantity float64 `json:"quantity,omitempty"`
+	// Amount is the dollar amount to trade
+	Amount float64 `json:"amount,omitempty"`
+	// LimitPrice is the limit price; required for limit orders
+	LimitPrice float64 `json:"limitPrice,omitempty"`
+}
+
+// Validate checks the order request for obvious mistakes before it is sent.
+// The returned error wraps ErrInvalidOrder.
+func (r OrderRequest) Validate() error {
+	var problems []string
+	if strings.TrimSpace(r.Symbol) == "" {
+		problems = append(problems, "symbol is required")
+	}
+	if r.Side != OrderSideBuy && r.Side != OrderSideSell {
+		problems = append(problems, fmt.Sprintf("unknown side %q", r.Side))
+	}
+	switch r.Type {
+	case OrderTypeMarket:
+		if r.LimitPrice != 0 {
+			problems = append(problems, "market orders cannot have a limit price")
+		}
+	case OrderTypeLimit:
+		if r.LimitPrice <= 0 {
+			problems = append(problems, "limit orders need a positive limit price")
+		}
+	default:
+		problems = append(problems, fmt.Sprintf("unknown type %q", r.Type))
+	}
+	if (r.Quantity > 0) == (r.Amount > 0) {
+		problems = append(problems, "exactly one of quantity or amount must be positive")
+	}
+	if r.Quantity < 0 || r.Amount < 0 {
+		problems = append(problems, "quantity and amount cannot be negative")
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("%w: %s", ErrInvalidOrder, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// Order represents an order and its execution state.
+type Order struct {
+	// ID is the order identifier
+	ID string `json:"orderID"`
+	// Symbol is the traded stock symbol
+	Symbol string `json:"symbol"`
+	// Side is buy or sell
+	Side OrderSide `json:"side"`
+	// Type is market or limit
+	Type OrderType `json:"type"`
+	// Status is the order status (e.g., "new", "filled", "cancelled")
+	Status OrderStatus `json:"status"`
+	// Quantity is the number of shares ordered (zero for amount orders)
+	Quantity float64 `json:"quantity"`
+	// Amount is the dollar amount ordered (zero for quantity orders)
+	Amount float64 `json:"amount"`
+	// LimitPrice is the limit price for limit orders
+	LimitPrice float64 `json:"limitPrice,omitempty"`
+	// FilledQuantity is the number of shares filled so far
+	FilledQuantity float64 `json:"filledQuantity"`
+	// AveragePrice is the average fill price
+	AveragePrice float64 `json:"averagePrice"`
+	// CreatedAt is when the order was placed
+	CreatedAt Time `json:"createdAt"`
+	// UpdatedAt is when the order last changed, as by a fill or modification
+	UpdatedAt Time `json:"updatedAt,omitzero"`
+	// FilledAt is when the order was completely filled
+	FilledAt Time `json:"filledAt,omitzero"`
+	// Extra holds the fields the API sent that this struct does not model
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// OrderChanges represents the request payload for modifying an open order.
+// Only the fields set are changed; the symbol, side and type cannot be.
+type OrderChanges struct {
+	// Quantity is the new number of shares to trade, for quantity orders
+	Quantity float64 `json:"quantity,omitempty"`
+	// Amount is the new dollar amount to trade, for amount orders
+	Amount float64 `json:"amount,omitempty"`
+	// LimitPrice is the new limit price, for limit orders
+	LimitPrice float64 `json:"limitPrice,omitempty"`
+}
+
+// Validate checks the changes for obvious mistakes before they are sent.
+// The returned error wraps ErrInvalidOrder.
+func (c OrderChanges) Validate() error {
+	var problems []string
+	if c.Quantity < 0 || c.Amount < 0 || c.LimitPrice < 0 {
+		problems = append(problems, "quantity, amount and limit price cannot be negative")
+	}
+	if c.Quantity > 0 && c.Amount > 0 {
+		problems = append(problems, "at most one of quantity or amount can be changed")
+	}
+	if c == (OrderChanges{}) {
+		problems = append(problems, "no changes given")
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("%w: %s", ErrInvalidOrder, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// OrderResponse represents the response from the single-order API endpoints.
+type OrderResponse struct {
+	// Code is the HTTP response code
+	Code int `json:"code"`
+	// Message is the response message (usually "Success")
+	Message string `json:"message"`
+	// Data contains the order
+	Data Order `json:"data"`
+}
+
+func (r *OrderResponse) validate() []string {
+	if r.Data.ID == "" {
+		return []string{"missing order ID"}
+	}
+	return nil
+}
+
+// OrderListData represents the data payload of an order list response.
+type OrderListData struct {
+	// Orders contains the open and historic orders
+	Orders []Order `json:"orders"`
+	// TotalRecords is the total number of orders
+	TotalRecords int `json:"totalRecords"`
+	// NextCursor is the cursor continuing the listing after these orders, or empty on the last page
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
+// OrderListResponse represents the response from the order list API.
+type OrderListResponse struct {
+	// Code is the HTTP response code
+	Code int `json:"code"`
+	// Message is the response message (usually "Success")
+	Message string `json:"message"`
+	// Data contains the orders
+	Data OrderListData `json:"data"`
+}
+
+func (r *OrderListResponse) normalize() {
+	if r.Data.Orders == nil {
+		r.Data.Orders = []Order{}
+	}
+}
+
+// Place submits an order. The request is validated before it is sent.
+//
+// Example:
+//
+//	resp, err := client.Orders().Place(ctx, stockal.OrderRequest{
+//		Symbol:     "AAPL",
+//		Side:       stockal.OrderSideBuy,
+//		Type:       stockal.OrderTypeLimit,
+//		Quantity:   1,
+//		LimitPrice: 180,
+//	})
+func (s *OrdersService) Place(ctx context.Context, order OrderRequest) (*OrderResponse, error) {
+	if err := order.Validate(); err != nil {
+		return nil, err
+	}
+
+	var orderResp OrderResponse
+	if err := s.client.do(ctx, "POST", "/v2/orders", order, &orderResp, "place order"); err != nil {
+		return nil, err
+	}
+	return &orderResp, nil
+}
+
+// Cancel cancels an open order.
+func (s *OrdersService) Cancel(ctx context.Context, orderID string) (*OrderResponse, error) {
+	if orderID == "" {
+		return nil, fmt.Errorf("%w: order ID is required", ErrInvalidOrder)
+	}
+
+	var orderResp OrderResponse
+	if err := s.client.do(ctx, "DELETE", "/v2/orders/"+url.PathEscape(orderID), nil, &orderResp, "cancel order"); err != nil {
+		return nil, err
+	}
+	return &orderResp, nil
+}
+
+// Modify changes the quantity, amount or limit price of an open order.
+// The changes are validated before they are sent. Stockal rejects changes to
+// orders that are no longer open, and an order may fill before the change
+// reaches the exchange; the response holds the order as changed.
+//
+// Example:
+//
+//	resp, err := client.Orders().Modify(ctx, orderID, stockal.OrderChanges{LimitPrice: 175})
+func (s *OrdersService) Modify(ctx context.Context, orderID string, changes OrderChanges) (*OrderResponse, error) {
+	if orderID == "" {
+		return nil, fmt.Errorf("%w: order ID is required", ErrInvalidOrder)
+	}
+	if err := changes.Validate(); err != nil {
+		return nil, err
+	}
+
+	var orderResp OrderResponse
+	if err := s.client.do(ctx, "PATCH", "/v2/orders/"+url.PathEscape(orderID), changes, &orderResp, "modify order"); err != nil {
+		return nil, err
+	}
+	return &orderResp, nil
+}
+
+// Get retrieves a single order and its current status.
+func (s *OrdersService) Get(ctx context.Context, orderID string) (*OrderResponse, error) {
+	if orderID == "" {
+		return nil, fmt.Errorf("%w: order ID is required", ErrInvalidOrder)
+	}
+
+	var orderResp OrderResponse
+	if err := s.client.do(ctx, "GET", "/v2/orders/"+url.PathEscape(orderID), nil, &orderResp, "get order"); err != nil {
+		return nil, err
+	}
+	return &orderResp, nil
+}
+
+// List retrieves the account's open and recent orders. To search the
+// whole order history, use ListWithOptions.
+func (s *OrdersService) List(ctx context.Context) (*OrderListResponse, error) {
+	return s.ListWithOptions(ctx, OrderListOptions{})
+}
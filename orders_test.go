@@ -0,0 +1,154 @@
+package stockal_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+)
+
+func TestOrdersModify(t *testing.T) {
+	var method, path, body string
+	client := newHandlerClient(t, func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		method, path, body = r.Method, r.URL.Path, string(b)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"code":200,"message":"Success","data":{"orderID":"o1","symbol":"AAPL","status":"New",` +
+			`"limitPrice":175,"createdAt":"2024-03-01T14:30:00Z","updatedAt":"2024-03-01T15:00:00Z"}}`))
+	})
+
+	resp, err := client.Orders().Modify(context.Background(), "o1", stockal.OrderChanges{LimitPrice: 175})
+	if err != nil {
+		t.Fatalf("Orders.Modify: %v", err)
+	}
+	if method != http.MethodPatch || path != "/v2/orders/o1" || body != `{"limitPrice":175}` {
+		t.Errorf("sent %s %s %s", method, path, body)
+	}
+	order := resp.Data
+	if order.Status != stockal.OrderStatusNew || !order.Status.IsOpen() || order.LimitPrice != 175 {
+		t.Errorf("got %+v", order)
+	}
+	if !order.UpdatedAt.Time.Equal(time.Date(2024, 3, 1, 15, 0, 0, 0, time.UTC)) || !order.FilledAt.Time.IsZero() {
+		t.Errorf("UpdatedAt = %v, FilledAt = %v", order.UpdatedAt.Time, order.FilledAt.Time)
+	}
+
+	for _, changes := range []stockal.OrderChanges{
+		{},
+		{LimitPrice: -1},
+		{Quantity: 1, Amount: 100},
+	} {
+		method = "unsent"
+		_, err := client.Orders().Modify(context.Background(), "o1", changes)
+		if !errors.Is(err, stockal.ErrInvalidOrder) || method != "unsent" {
+			t.Errorf("Orders.Modify(%+v) = %v, want ErrInvalidOrder without a request", changes, err)
+		}
+	}
+}
+
+func TestOrderStatus(t *testing.T) {
+	tests := []struct {
+		json   string
+		status stockal.OrderStatus
+		open   bool
+	}{
+		{`"partially_filled"`, stockal.OrderStatusPartiallyFilled, true},
+		{`" FILLED "`, stockal.OrderStatusFilled, false},
+		{`"canceled"`, stockal.OrderStatusCancelled, false},
+		{`"pending_review"`, "pending_review", false},
+	}
+	for _, tt := range tests {
+		var status stockal.OrderStatus
+		if err := json.Unmarshal([]byte(tt.json), &status); err != nil {
+			t.Fatalf("Unmarshal(%s): %v", tt.json, err)
+		}
+		if status != tt.status || status.IsOpen() != tt.open {
+			t.Errorf("Unmarshal(%s) = %q, open %v", tt.json, status, status.IsOpen())
+		}
+	}
+}
+
+func TestListOrdersWithOptions(t *testing.T) {
+	var query string
+	client := newHandlerClient(t, func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		// Every order, as from an API that ignores the filters
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"code":200,"message":"Success","data":{"orders":[` +
+			`{"orderID":"o1","symbol":"AAPL","status":"filled","createdAt":"2024-01-15T10:00:00Z"},` +
+			`{"orderID":"o2","symbol":"AAPL","status":"cancelled","createdAt":"2024-02-15T10:00:00Z"},` +
+			`{"orderID":"o3","symbol":"VOO","status":"filled","createdAt":"2024-03-15T10:00:00Z"}],"totalRecords":3}}`))
+	})
+
+	feb := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		opts  stockal.OrderListOptions
+		query string
+		ids   []string
+	}{
+		{stockal.OrderListOptions{}, "", []string{"o1", "o2", "o3"}},
+		{stockal.OrderListOptions{Symbol: "aapl", Status: stockal.OrderStatusFilled}, "status=filled&symbol=AAPL", []string{"o1"}},
+		{stockal.OrderListOptions{From: feb, Limit: 10}, "from=2024-02-01T00%3A00%3A00Z&limit=10", []string{"o2", "o3"}},
+		{stockal.OrderListOptions{To: feb, Cursor: "c1"}, "cursor=c1&to=2024-02-01T00%3A00%3A00Z", []string{"o1"}},
+	}
+	for _, tt := range tests {
+		resp, err := client.Orders().ListWithOptions(context.Background(), tt.opts)
+		if err != nil {
+			t.Fatalf("Orders.ListWithOptions(%+v): %v", tt.opts, err)
+		}
+		if query != tt.query {
+			t.Errorf("Orders.ListWithOptions(%+v) sent query %q, want %q", tt.opts, query, tt.query)
+		}
+		var ids []string
+		for _, o := range resp.Data.Orders {
+			ids = append(ids, o.ID)
+		}
+		if !slices.Equal(ids, tt.ids) {
+			t.Errorf("Orders.ListWithOptions(%+v) returned %v, want %v", tt.opts, ids, tt.ids)
+		}
+	}
+
+	for _, opts := range []stockal.OrderListOptions{
+		{Limit: -1},
+		{Offset: 10, Cursor: "c1"},
+		{From: feb, To: feb},
+	} {
+		if _, err := client.Orders().ListWithOptions(context.Background(), opts); !errors.Is(err, stockal.ErrInvalidParams) {
+			t.Errorf("Orders.ListWithOptions(%+v) error = %v, want ErrInvalidParams", opts, err)
+		}
+	}
+}
+
+func TestAllOrders(t *testing.T) {
+	// Pages linked by cursor
+	client := newTestClientFunc(t, func(r *http.Request) string {
+		switch r.URL.Query().Get("cursor") {
+		case "":
+			return `{"code":200,"data":{"orders":[{"orderID":"o1"},{"orderID":"o2"}],"totalRecords":3,"nextCursor":"c2"}}`
+		case "c2":
+			return `{"code":200,"data":{"orders":[{"orderID":"o3"}],"totalRecords":3}}`
+		}
+		return `{"code":400,"message":"bad cursor"}`
+	})
+	orders, err := stockal.AllOrders(context.Background(), client.Orders(), stockal.OrderListOptions{Limit: 2})
+	if err != nil || len(orders) != 3 || orders[2].ID != "o3" {
+		t.Errorf("AllOrders by cursor = %+v, %v", orders, err)
+	}
+
+	// Pages by offset
+	client = newTestClientFunc(t, func(r *http.Request) string {
+		if r.URL.Query().Get("offset") == "" {
+			return `{"code":200,"data":{"orders":[{"orderID":"o1"},{"orderID":"o2"}],"totalRecords":3}}`
+		}
+		return `{"code":200,"data":{"orders":[{"orderID":"o3"}],"totalRecords":3}}`
+	})
+	orders, err = stockal.AllOrders(context.Background(), client.Orders(), stockal.OrderListOptions{Limit: 2})
+	if err != nil || len(orders) != 3 || orders[2].ID != "o3" {
+		t.Errorf("AllOrders by offset = %+v, %v", orders, err)
+	}
+}
@@ -0,0 +1,189 @@
+// Package poller runs recurring tasks against the Stockal API, such as
+// refreshing quotes or taking snapshots, with market-hours awareness, jitter
+// and backoff on failure.
+//
+// # Basic Usage
+//
+//	p := poller.New(poller.WithErrorHandler(func(task string, err error) {
+//		log.Printf("%s: %v", task, err)
+//	}))
+//	p.Add(poller.Task{
+//		Name:     "summary",
+//		Schedule: poller.DuringMarketHours(poller.Every(5 * time.Minute)),
+//		Run:      func(ctx context.Context) error { _, err := client.Account().Summary(ctx); return err },
+//	})
+//	p.Add(poller.Task{
+//		Name:     "quotes",
+//		Schedule: poller.Every(30 * time.Second),
+//		Jitter:   5 * time.Second,
+//		Run:      refreshQuotes,
+//	})
+//	p.Add(poller.Task{
+//		Name:     "snapshot",
+//		Schedule: poller.AtMarketClose(5 * time.Minute),
+//		Run:      takeSnapshot,
+//	})
+//	err := p.Run(ctx) // blocks until ctx is cancelled
+package poller
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+// Backoff defaults.
+const (
+	// DefaultMaxBackoff caps the delay between retries of a failing task.
+	DefaultMaxBackoff = 15 * time.Minute
+
+	// maxInitialBackoff caps the first retry delay of tasks with long schedules.
+	maxInitialBackoff = time.Minute
+)
+
+// ErrDuplicateTask is returned by Add when a task with the same name exists.
+var ErrDuplicateTask = errors.New("duplicate task name")
+
+// Task is a unit of recurring work.
+type Task struct {
+	// Name identifies the task in errors
+	Name string
+	// Run does the work. It should return promptly when ctx is cancelled.
+	Run func(ctx context.Context) error
+	// Schedule determines when the task runs
+	Schedule Schedule
+	// Immediate runs the task once as soon as the poller starts
+	Immediate bool
+	// Jitter delays each run by a random duration in [0, Jitter), spreading
+	// load when many pollers share a schedule
+	Jitter time.Duration
+	// MaxBackoff caps the retry delay after failures (DefaultMaxBackoff if zero)
+	MaxBackoff time.Duration
+}
+
+// Option configures a Poller.
+type Option func(*Poller)
+
+// WithErrorHandler sets a function called with every task failure. It may be
+// called concurrently from different tasks.
+func WithErrorHandler(h func(task string, err error)) Option {
+	return func(p *Poller) {
+		p.onError = h
+	}
+}
+
+// Poller runs tasks on their schedules.
+type Poller struct {
+	mu      sync.Mutex
+	tasks   []Task
+	onError func(task string, err error)
+}
+
+// New creates a Poller.
+func New(opts ...Option) *Poller {
+	p := &Poller{onError: func(string, error) {}}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// Add registers a task. Tasks must be added before Run is called.
+func (p *Poller) Add(t Task) error {
+	if t.Name == "" || t.Run == nil || t.Schedule == nil {
+		return errors.New("poller: task needs a name, Run and Schedule")
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, existing := range p.tasks {
+		if existing.Name == t.Name {
+			return ErrDuplicateTask
+		}
+	}
+	p.tasks = append(p.tasks, t)
+	return nil
+}
+
+// Run runs every task until ctx is cancelled, then waits for in-flight runs
+// to return. It returns ctx's error.
+func (p *Poller) Run(ctx context.Context) error {
+	p.mu.Lock()
+	tasks := append([]Task(nil), p.tasks...)
+	p.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, t := range tasks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			p.loop(ctx, t)
+		}()
+	}
+	wg.Wait()
+	return ctx.Err()
+}
+
+// loop runs a single task on its schedule.
+func (p *Poller) loop(ctx context.Context, t Task) {
+	failures := 0
+	next := time.Now()
+	if !t.Immediate {
+		next = p.next(t, time.Now(), 0)
+	}
+
+	timer := time.NewTimer(time.Until(next))
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+
+		if err := t.Run(ctx); err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			failures++
+			p.onError(t.Name, err)
+		} else {
+			failures = 0
+		}
+		timer.Reset(time.Until(p.next(t, time.Now(), failures)))
+	}
+}
+
+// next returns when t should run again after now, given its consecutive failures.
+//
+// After a failure the task is retried after an exponentially growing delay
+// instead of at its next scheduled time. The first delay is the task's regular
+// interval, capped at a minute so that daily tasks retry promptly, and it never
+// exceeds MaxBackoff. A retry that falls outside the times the schedule allows,
+// such as outside market hours for DuringMarketHours, waits until they start.
+func (p *Poller) next(t Task, now time.Time, failures int) time.Time {
+	at := t.Schedule.Next(now)
+	if failures > 0 {
+		maxBackoff := t.MaxBackoff
+		if maxBackoff <= 0 {
+			maxBackoff = DefaultMaxBackoff
+		}
+		delay := min(at.Sub(now), maxInitialBackoff)
+		if delay <= 0 {
+			delay = time.Second
+		}
+		for i := 1; i < failures && delay < maxBackoff; i++ {
+			delay *= 2
+		}
+		at = now.Add(min(delay, maxBackoff))
+		if w, ok := t.Schedule.(window); ok {
+			at = w.earliest(at)
+		}
+	}
+	if t.Jitter > 0 {
+		at = at.Add(rand.N(t.Jitter))
+	}
+	return at
+}
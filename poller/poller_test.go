@@ -0,0 +1,154 @@
+package poller
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestNextBackoff(t *testing.T) {
+	now := et("2024-05-01 12:00")
+	tests := []struct {
+		name   string
+		task   Task
+		delays []time.Duration // after 0, 1, 2... consecutive failures
+	}{
+		{
+			name:   "doubles from the interval",
+			task:   Task{Schedule: Every(30 * time.Second), MaxBackoff: 3 * time.Minute},
+			delays: []time.Duration{30 * time.Second, 30 * time.Second, time.Minute, 2 * time.Minute, 3 * time.Minute, 3 * time.Minute},
+		},
+		{
+			name:   "daily task retries within a minute",
+			task:   Task{Schedule: DailyAt(18, 0, time.UTC)},
+			delays: []time.Duration{2 * time.Hour, time.Minute, 2 * time.Minute, 4 * time.Minute},
+		},
+		{
+			name:   "default cap",
+			task:   Task{Schedule: Every(time.Minute)},
+			delays: []time.Duration{time.Minute, time.Minute, 2 * time.Minute, 4 * time.Minute, 8 * time.Minute, DefaultMaxBackoff, DefaultMaxBackoff},
+		},
+		{
+			name:   "schedule already due",
+			task:   Task{Schedule: ScheduleFunc(func(now time.Time) time.Time { return now })},
+			delays: []time.Duration{0, time.Second, 2 * time.Second},
+		},
+	}
+	p := New()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for failures, want := range tt.delays {
+				if got := p.next(tt.task, now, failures).Sub(now); got != want {
+					t.Errorf("delay after %d failures = %v, want %v", failures, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestNextBackoffMarketHours(t *testing.T) {
+	task := Task{Schedule: DuringMarketHours(Every(5 * time.Minute))}
+	tests := []struct {
+		name     string
+		now      time.Time
+		failures int
+		want     time.Time
+	}{
+		{"scheduled after the close", et("2024-05-01 15:58"), 0, et("2024-05-02 09:30")},
+		{"retry before the close", et("2024-05-01 15:58"), 1, et("2024-05-01 15:59")},
+		{"retry at the close", et("2024-05-01 15:58"), 2, et("2024-05-02 09:30")},
+		{"retry over the weekend", et("2024-05-03 15:50"), 5, et("2024-05-06 09:30")},
+		{"retry during the session", et("2024-05-01 12:00"), 3, et("2024-05-01 12:04")},
+		{"retry before the open", et("2024-05-01 09:00"), 6, et("2024-05-01 09:30")},
+	}
+	p := New()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := p.next(task, tt.now, tt.failures); !got.Equal(tt.want) {
+				t.Errorf("next after %d failures at %v = %v, want %v", tt.failures, tt.now, got.UTC(), tt.want)
+			}
+		})
+	}
+}
+
+func TestNextJitter(t *testing.T) {
+	now := et("2024-05-01 12:00")
+	task := Task{Schedule: Every(time.Minute), Jitter: 10 * time.Second}
+	for range 100 {
+		if d := New().next(task, now, 0).Sub(now); d < time.Minute || d >= time.Minute+task.Jitter {
+			t.Fatalf("delay %v, want within [1m, 1m10s)", d)
+		}
+	}
+}
+
+func TestAdd(t *testing.T) {
+	p := New()
+	run := func(context.Context) error { return nil }
+	if err := p.Add(Task{Name: "quotes", Run: run, Schedule: Every(time.Minute)}); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	if err := p.Add(Task{Name: "quotes", Run: run, Schedule: Every(time.Hour)}); !errors.Is(err, ErrDuplicateTask) {
+		t.Errorf("Add with a duplicate name = %v, want ErrDuplicateTask", err)
+	}
+	for _, task := range []Task{{Run: run, Schedule: Every(time.Minute)}, {Name: "a", Schedule: Every(time.Minute)}, {Name: "b", Run: run}} {
+		if err := p.Add(task); err == nil {
+			t.Errorf("Add(%+v) accepted an incomplete task", task)
+		}
+	}
+}
+
+func TestRun(t *testing.T) {
+	var mu sync.Mutex
+	var failed []string
+	reported := make(chan struct{}, 1)
+	p := New(WithErrorHandler(func(task string, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		failed = append(failed, task)
+		reported <- struct{}{}
+	}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var runs atomic.Int32
+	p.Add(Task{Name: "ok", Immediate: true, Schedule: Every(time.Millisecond), Run: func(ctx context.Context) error {
+		// Stop once this task has run a few times and the failure is in
+		if runs.Add(1) >= 3 {
+			select {
+			case <-reported:
+				cancel()
+			default:
+			}
+		}
+		return nil
+	}})
+	p.Add(Task{Name: "failing", Immediate: true, Schedule: Every(time.Hour), Run: func(ctx context.Context) error {
+		return errors.New("unavailable")
+	}})
+	p.Add(Task{Name: "later", Schedule: Every(time.Hour), Run: func(ctx context.Context) error {
+		t.Error("task ran before its schedule")
+		return nil
+	}})
+
+	done := make(chan error)
+	go func() { done <- p.Run(ctx) }()
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Run = %v, want context.Canceled", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run did not return after cancellation")
+	}
+
+	if n := runs.Load(); n < 3 {
+		t.Errorf("task ran %d times, want at least 3", n)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(failed) != 1 || failed[0] != "failing" {
+		t.Errorf("failures reported for %q, want the failing task once", failed)
+	}
+}
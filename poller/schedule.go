@@ -0,0 +1,76 @@
+package poller
+
+import (
+	"time"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+)
+
+// Schedule determines when a task runs.
+type Schedule interface {
+	// Next returns the next run time strictly after now.
+	Next(now time.Time) time.Time
+}
+
+// ScheduleFunc adapts a function to a Schedule.
+type ScheduleFunc func(now time.Time) time.Time
+
+// Next implements Schedule.
+func (f ScheduleFunc) Next(now time.Time) time.Time {
+	return f(now)
+}
+
+// Every runs a task at a fixed interval.
+func Every(d time.Duration) Schedule {
+	return ScheduleFunc(func(now time.Time) time.Time {
+		return now.Add(d)
+	})
+}
+
+// DailyAt runs a task every day at hour:minute in loc.
+func DailyAt(hour, minute int, loc *time.Location) Schedule {
+	return ScheduleFunc(func(now time.Time) time.Time {
+		local := now.In(loc)
+		at := time.Date(local.Year(), local.Month(), local.Day(), hour, minute, 0, 0, loc)
+		if !at.After(local) {
+			at = time.Date(local.Year(), local.Month(), local.Day()+1, hour, minute, 0, 0, loc)
+		}
+		return at
+	})
+}
+
+// AtMarketClose runs a task offset after the close of each US trading session.
+// A small positive offset gives the API time to publish closing prices.
+func AtMarketClose(offset time.Duration) Schedule {
+	return ScheduleFunc(func(now time.Time) time.Time {
+		return stockal.NextMarketClose(now.Add(-offset)).Add(offset)
+	})
+}
+
+// DuringMarketHours restricts s to US regular trading hours: runs that would
+// fall outside a session are moved to the next market open. Retries after a
+// failure are moved the same way.
+func DuringMarketHours(s Schedule) Schedule {
+	return marketHours{s}
+}
+
+// window is implemented by schedules that only allow runs at certain times,
+// so that retries after a failure keep to them.
+type window interface {
+	// earliest returns the first time at or after t that a run is allowed.
+	earliest(t time.Time) time.Time
+}
+
+// marketHours is the Schedule returned by DuringMarketHours.
+type marketHours struct {
+	s Schedule
+}
+
+// Next implements Schedule.
+func (m marketHours) Next(now time.Time) time.Time {
+	return stockal.NextMarketOpen(m.s.Next(now))
+}
+
+func (m marketHours) earliest(t time.Time) time.Time {
+	return stockal.NextMarketOpen(t)
+}
@@ -0,0 +1,43 @@
+package poller
+
+import (
+	"testing"
+	"time"
+)
+
+// et returns a time given in New York summer time (UTC-4), in UTC.
+func et(s string) time.Time {
+	t, err := time.Parse("2006-01-02 15:04 -0700", s+" -0400")
+	if err != nil {
+		panic(err)
+	}
+	return t.UTC()
+}
+
+func TestSchedules(t *testing.T) {
+	// 1 May 2024 is a Wednesday
+	tests := []struct {
+		name     string
+		schedule Schedule
+		now      time.Time
+		want     time.Time
+	}{
+		{"every", Every(time.Minute), et("2024-05-01 12:00"), et("2024-05-01 12:01")},
+		{"daily later today", DailyAt(18, 0, time.UTC), et("2024-05-01 12:00"), et("2024-05-01 14:00")},
+		{"daily at the time", DailyAt(18, 0, time.UTC), et("2024-05-01 14:00"), et("2024-05-02 14:00")},
+		{"close during the session", AtMarketClose(5 * time.Minute), et("2024-05-01 12:00"), et("2024-05-01 16:05")},
+		{"close within the offset", AtMarketClose(5 * time.Minute), et("2024-05-01 16:03"), et("2024-05-01 16:05")},
+		{"close after the offset", AtMarketClose(5 * time.Minute), et("2024-05-01 16:10"), et("2024-05-02 16:05")},
+		{"close on a Friday evening", AtMarketClose(5 * time.Minute), et("2024-05-03 17:00"), et("2024-05-06 16:05")},
+		{"market hours open", DuringMarketHours(Every(time.Hour)), et("2024-05-01 12:00"), et("2024-05-01 13:00")},
+		{"market hours after the close", DuringMarketHours(Every(time.Hour)), et("2024-05-01 15:30"), et("2024-05-02 09:30")},
+		{"market hours over the weekend", DuringMarketHours(Every(time.Hour)), et("2024-05-03 15:30"), et("2024-05-06 09:30")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.schedule.Next(tt.now); !got.Equal(tt.want) {
+				t.Errorf("Next(%v) = %v, want %v", tt.now, got.UTC(), tt.want)
+			}
+		})
+	}
+}
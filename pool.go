@@ -0,0 +1,121 @@
+package stockal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrDuplicateAccount is returned when a name is added to a ClientPool twice.
+var ErrDuplicateAccount = errors.New("account already in pool")
+
+// AccountError is an error from one account of a ClientPool.
+type AccountError struct {
+	// Name is the account's name in the pool
+	Name string
+	Err  error
+}
+
+func (e *AccountError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Name, e.Err)
+}
+
+func (e *AccountError) Unwrap() error {
+	return e.Err
+}
+
+// ClientPool holds clients for several accounts under names of the caller's
+// choosing, and runs the same work against all of them concurrently. It is
+// safe for concurrent use.
+//
+// Example:
+//
+//	pool := stockal.NewClientPool()
+//	pool.Add("personal", personal)
+//	pool.Add("family", family)
+//	snapshots, err := pool.Snapshots(ctx)
+type ClientPool struct {
+	mu      sync.Mutex
+	names   []string
+	clients map[string]StockalClient
+}
+
+// NewClientPool creates an empty pool.
+func NewClientPool() *ClientPool {
+	return &ClientPool{clients: map[string]StockalClient{}}
+}
+
+// Add adds a client under name.
+func (p *ClientPool) Add(name string, client StockalClient) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, ok := p.clients[name]; ok {
+		return fmt.Errorf("%w: %s", ErrDuplicateAccount, name)
+	}
+	p.names = append(p.names, name)
+	p.clients[name] = client
+	return nil
+}
+
+// Client returns the client added under name.
+func (p *ClientPool) Client(name string) (StockalClient, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	client, ok := p.clients[name]
+	return client, ok
+}
+
+// Names returns the account names in the order they were added.
+func (p *ClientPool) Names() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return append([]string(nil), p.names...)
+}
+
+// Each calls fn for every account concurrently and waits for all calls to
+// return. Failures do not stop the other calls; they are returned joined, each
+// as an *AccountError.
+func (p *ClientPool) Each(ctx context.Context, fn func(ctx context.Context, name string, client StockalClient) error) error {
+	p.mu.Lock()
+	names := append([]string(nil), p.names...)
+	clients := make([]StockalClient, len(names))
+	for i, name := range names {
+		clients[i] = p.clients[name]
+	}
+	p.mu.Unlock()
+
+	errs := make([]error, len(names))
+	var wg sync.WaitGroup
+	for i := range names {
+		wg.Go(func() {
+			if err := fn(ctx, names[i], clients[i]); err != nil {
+				errs[i] = &AccountError{Name: names[i], Err: err}
+			}
+		})
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// Snapshots takes a snapshot of every account concurrently. Accounts that
+// fail are missing from the map and reported in the returned error, so the
+// map may be non-empty even when the error is not nil.
+func (p *ClientPool) Snapshots(ctx context.Context) (map[string]*Snapshot, error) {
+	var mu sync.Mutex
+	snapshots := map[string]*Snapshot{}
+	err := p.Each(ctx, func(ctx context.Context, name string, client StockalClient) error {
+		snapshot, err := TakeSnapshot(ctx, client)
+		if err != nil {
+			return err
+		}
+		mu.Lock()
+		snapshots[name] = snapshot
+		mu.Unlock()
+		return nil
+	})
+	return snapshots, err
+}
@@ -0,0 +1,124 @@
+package stockal_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+	"github.com/adjaecent/unofficial-stockal-api/stockaltest"
+)
+
+func TestClientPool(t *testing.T) {
+	pool := stockal.NewClientPool()
+	personal, family := &stockaltest.MockClient{}, &stockaltest.MockClient{}
+	if err := pool.Add("personal", personal); err != nil {
+		t.Fatal(err)
+	}
+	if err := pool.Add("family", family); err != nil {
+		t.Fatal(err)
+	}
+	if err := pool.Add("personal", family); !errors.Is(err, stockal.ErrDuplicateAccount) {
+		t.Errorf("Add of a duplicate name = %v, want ErrDuplicateAccount", err)
+	}
+
+	if names := pool.Names(); !slices.Equal(names, []string{"personal", "family"}) {
+		t.Errorf("Names() = %q, want the order added", names)
+	}
+	if client, ok := pool.Client("family"); !ok || client != family {
+		t.Errorf("Client(family) = %v, %v", client, ok)
+	}
+	if _, ok := pool.Client("work"); ok {
+		t.Error("Client(work) found an account never added")
+	}
+}
+
+func TestClientPoolEach(t *testing.T) {
+	pool := stockal.NewClientPool()
+	for _, name := range []string{"personal", "family", "work"} {
+		pool.Add(name, &stockaltest.MockClient{})
+	}
+
+	errFamily, errWork := errors.New("family failed"), stockal.ErrTokenExpired
+	var (
+		mu     sync.Mutex
+		called []string
+	)
+	err := pool.Each(context.Background(), func(ctx context.Context, name string, client stockal.StockalClient) error {
+		mu.Lock()
+		called = append(called, name)
+		mu.Unlock()
+		switch name {
+		case "family":
+			return errFamily
+		case "work":
+			return errWork
+		}
+		return nil
+	})
+
+	slices.Sort(called)
+	if !slices.Equal(called, []string{"family", "personal", "work"}) {
+		t.Errorf("called for %q, want every account despite the failures", called)
+	}
+	if !errors.Is(err, errFamily) || !errors.Is(err, stockal.ErrTokenExpired) {
+		t.Fatalf("Each = %v, want both failures", err)
+	}
+	joined, ok := err.(interface{ Unwrap() []error })
+	if !ok {
+		t.Fatalf("Each = %T, want the errors joined", err)
+	}
+	var names []string
+	for _, e := range joined.Unwrap() {
+		var accountErr *stockal.AccountError
+		if !errors.As(e, &accountErr) {
+			t.Fatalf("error %v is not an *AccountError", e)
+		}
+		names = append(names, accountErr.Name)
+	}
+	// The errors are in the order the accounts were added
+	if !slices.Equal(names, []string{"family", "work"}) {
+		t.Errorf("failed accounts %q, want family and work", names)
+	}
+	if want := "family: family failed\nwork: " + stockal.ErrTokenExpired.Error(); err.Error() != want {
+		t.Errorf("Each = %q, want %q", err, want)
+	}
+
+	if err := stockal.NewClientPool().Each(context.Background(), nil); err != nil {
+		t.Errorf("Each of an empty pool = %v, want nil", err)
+	}
+}
+
+func TestClientPoolSnapshots(t *testing.T) {
+	pool := stockal.NewClientPool()
+	pool.Add("personal", &stockaltest.MockClient{})
+	pool.Add("family", &stockaltest.MockClient{
+		PortfolioDetailFunc: func(ctx context.Context) (*stockal.PortfolioDetailResponse, error) {
+			return nil, stockal.ErrServerError
+		},
+	})
+	pool.Add("work", &stockaltest.MockClient{})
+
+	snapshots, err := pool.Snapshots(context.Background())
+	var accountErr *stockal.AccountError
+	if !errors.As(err, &accountErr) || accountErr.Name != "family" || !errors.Is(err, stockal.ErrServerError) {
+		t.Errorf("Snapshots error = %v, want the family account's", err)
+	}
+	if len(snapshots) != 2 || snapshots["personal"] == nil || snapshots["work"] == nil {
+		t.Fatalf("snapshots %v, want personal and work", snapshots)
+	}
+	if _, ok := snapshots["family"]; ok {
+		t.Error("the failed account has a snapshot")
+	}
+	if got, want := len(snapshots["personal"].Holdings), len(stockaltest.Portfolio().Data.Holdings); got != want {
+		t.Errorf("personal snapshot has %d holdings, want %d", got, want)
+	}
+
+	pool = stockal.NewClientPool()
+	pool.Add("personal", &stockaltest.MockClient{})
+	if snapshots, err := pool.Snapshots(context.Background()); err != nil || len(snapshots) != 1 {
+		t.Errorf("Snapshots = %v, %v; want one without an error", snapshots, err)
+	}
+}
@@ -0,0 +1,426 @@
+package stockal
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidParams is returned when request parameters fail client-side validation.
+var ErrInvalidParams = errors.New("invalid parameters")
+
+// PortfolioQuerier reads the portfolio, whole or in part: a page at a time,
+// or only the holdings matching a filter. PortfolioService implements it.
+type PortfolioQuerier interface {
+	Detail(ctx context.Context) (*PortfolioDetailResponse, error)
+	DetailWithParams(ctx context.Context, params PortfolioParams) (*PortfolioDetailResponse, error)
+}
+
+// WithRawHoldings makes the client return holdings as the API sends them.
+// By default, rows the API repeats for a symbol and category, as it sometimes
+// does after corporate actions, are merged (see MergeHoldings), and holdings
+// are sorted by symbol (see SortHoldings) unless the request asks the API to
+// sort them. Pages of holdings, read with a Limit or Offset, are always
+// returned as sent, since a repeated row may be on another page; AllHoldings
+// merges and sorts the holdings once every page is read.
+func WithRawHoldings() ClientOption {
+	return func(c *clientConfig) {
+		c.rawHoldings = true
+	}
+}
+
+// tidyHoldings merges and sorts the holdings in data as WithRawHoldings
+// describes. TotalRecords still counts the rows as the API sent them, so
+// that offsets stay in step with the API's.
+func (c *Client) tidyHoldings(data *PortfolioDetailData, params PortfolioParams) {
+	if c.rawHoldings || params.paged() {
+		return
+	}
+	data.Holdings = MergeHoldings(data.Holdings)
+	if params.SortBy == "" {
+		SortHoldings(data.Holdings)
+	}
+}
+
+// SortOrder is the direction of a sort.
+type SortOrder string
+
+// Sort orders.
+const (
+	SortAscending  SortOrder = "asc"
+	SortDescending SortOrder = "desc"
+)
+
+// PortfolioParams selects holdings by category or symbol and a page of them.
+// The zero value selects all of them in the API's default order.
+type PortfolioParams struct {
+	// Category, if set, selects the holdings of one category
+	Category Category
+	// Symbol, if set, selects the holding of one symbol
+	Symbol string
+
+	// Limit is the maximum number of holdings to return; zero means no limit
+	Limit int
+	// Offset is the number of holdings to skip
+	Offset int
+	// SortBy is the holding field to sort by, e.g. "symbol" or "totalInvestment"
+	SortBy string
+	// Order is the sort direction; the API sorts ascending by default
+	Order SortOrder
+}
+
+// Validate checks the parameters before they are sent. The returned error
+// wraps ErrInvalidParams.
+func (p PortfolioParams) Validate() error {
+	switch {
+	case p.Limit < 0:
+		return fmt.Errorf("%w: negative limit %d", ErrInvalidParams, p.Limit)
+	case p.Offset < 0:
+		return fmt.Errorf("%w: negative offset %d", ErrInvalidParams, p.Offset)
+	case p.Order != "" && p.Order != SortAscending && p.Order != SortDescending:
+		return fmt.Errorf("%w: unknown sort order %q", ErrInvalidParams, p.Order)
+	case p.Order != "" && p.SortBy == "":
+		return fmt.Errorf("%w: sort order given without a field to sort by", ErrInvalidParams)
+	}
+	return nil
+}
+
+// partial reports whether the parameters may select fewer holdings than the
+// portfolio has.
+func (p PortfolioParams) partial() bool {
+	return p.Limit > 0 || p.Offset > 0 || p.Category != "" || p.Symbol != ""
+}
+
+// paged reports whether the parameters select a page of the holdings.
+func (p PortfolioParams) paged() bool {
+	return p.Limit > 0 || p.Offset > 0
+}
+
+// matches reports whether h passes the category and symbol filters.
+func (p PortfolioParams) matches(h Holding) bool {
+	return (p.Category == "" || h.Category == p.Category) &&
+		(p.Symbol == "" || strings.EqualFold(h.Symbol, strings.TrimSpace(p.Symbol)))
+}
+
+// query returns the parameters as a query string, without the leading "?".
+func (p PortfolioParams) query() string {
+	query := url.Values{}
+	if p.Category != "" {
+		query.Set("category", string(p.Category))
+	}
+	if symbol := strings.TrimSpace(p.Symbol); symbol != "" {
+		query.Set("symbol", strings.ToUpper(symbol))
+	}
+	if p.Limit > 0 {
+		query.Set("limit", strconv.Itoa(p.Limit))
+	}
+	if p.Offset > 0 {
+		query.Set("offset", strconv.Itoa(p.Offset))
+	}
+	if p.SortBy != "" {
+		query.Set("sortBy", p.SortBy)
+	}
+	if p.Order != "" {
+		query.Set("order", string(p.Order))
+	}
+	return query.Encode()
+}
+
+// DetailWithParams retrieves the holdings params select, so that
+// accounts with many holdings can be read a page at a time, and integrations
+// interested in one category or position need not transfer the rest.
+// Data.TotalRecords is the number of holdings in the whole portfolio, or of
+// those matching the filters, counting rows as the API sends them. A page,
+// selected with Limit or Offset, holds the rows as sent (see WithRawHoldings),
+// so the next page starts at Offset plus its length.
+//
+// The filters are applied by the API. The client applies them to the response
+// as well, so that a filtered call never returns other holdings.
+//
+// Example:
+//
+//	params := stockal.PortfolioParams{Limit: 50, SortBy: "symbol"}
+//	for {
+//		page, err := client.Portfolio().DetailWithParams(ctx, params)
+//		if err != nil {
+//			log.Fatal(err)
+//		}
+//		for _, h := range page.Data.Holdings {
+//			fmt.Println(h.Symbol)
+//		}
+//		params.Offset += len(page.Data.Holdings)
+//		if len(page.Data.Holdings) == 0 || params.Offset >= page.Data.TotalRecords {
+//			break
+//		}
+//	}
+func (s *PortfolioService) DetailWithParams(ctx context.Context, params PortfolioParams) (*PortfolioDetailResponse, error) {
+	if err := params.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.client.authenticate(ctx); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := s.client.withDeadline(ctx)
+	defer cancel()
+
+	endpoint := "/v2/users/portfolio/detail"
+	if query := params.query(); query != "" {
+		endpoint += "?" + query
+	}
+	resp, err := s.client.makeRequest(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("portfolio detail request failed: %w", err)
+	}
+
+	portfolioResp := PortfolioDetailResponse{partial: params.partial()}
+	if err := s.client.handleResponse(resp, &portfolioResp, "portfolio detail"); err != nil {
+		return &portfolioResp, err
+	}
+
+	holdings := portfolioResp.Data.Holdings[:0]
+	for _, h := range portfolioResp.Data.Holdings {
+		if params.matches(h) {
+			holdings = append(holdings, h)
+		}
+	}
+	portfolioResp.Data.Holdings = holdings
+	s.client.tidyHoldings(&portfolioResp.Data, params)
+
+	return &portfolioResp, nil
+}
+
+// HoldingIterator returns an Iterator over every holding params select,
+// reading Limit holdings per request (100 if unset) from Offset on. The
+// holdings are returned as the API sends them; see WithRawHoldings.
+func HoldingIterator(q PortfolioQuerier, params PortfolioParams) *Iterator[Holding] {
+	if params.Limit == 0 {
+		params.Limit = defaultPageSize
+	}
+	return newIterator("read holdings", func(h Holding) string { return h.Symbol }, func(ctx context.Context) ([]Holding, bool, error) {
+		page, err := q.DetailWithParams(ctx, params)
+		if err != nil {
+			return nil, false, err
+		}
+		holdings := page.Data.Holdings
+		params.Offset += len(holdings)
+		return holdings, len(holdings) > 0 && params.Offset < page.Data.TotalRecords, nil
+	})
+}
+
+// AllHoldings reads every holding params select, as HoldingIterator does,
+// then merges and sorts them as WithRawHoldings describes, unless q is the
+// Portfolio service of a Client made with WithRawHoldings. If
+// ctx is cancelled between pages, it returns the holdings read so far, as
+// sent, with a *PartialError naming their symbols; to resume, advance Offset
+// by the number of holdings returned and call it again.
+func AllHoldings(ctx context.Context, q PortfolioQuerier, params PortfolioParams) ([]Holding, error) {
+	holdings, err := HoldingIterator(q, params).All(ctx)
+	if s, ok := q.(*PortfolioService); err != nil || ok && s.client.rawHoldings {
+		return holdings, err
+	}
+	holdings = MergeHoldings(holdings)
+	if params.SortBy == "" {
+		SortHoldings(holdings)
+	}
+	return holdings, nil
+}
+
+// PortfolioStreamer is implemented by clients that can decode the portfolio
+// one holding at a time.
+type PortfolioStreamer interface {
+	StreamPortfolioDetail(ctx context.Context, params PortfolioParams, fn func(Holding) error) (*PortfolioDetailResponse, error)
+}
+
+// StreamPortfolioDetail retrieves the holdings params select like
+// Portfolio().DetailWithParams, but decodes them from the response one at a
+// time instead of reading the whole response first, which lowers peak memory
+// for very large portfolios and lets callers show the first holdings sooner.
+//
+// If fn is nil, the holdings are collected in Data.Holdings. Otherwise fn is
+// called with each holding as it is decoded, before any duplicates are merged
+// (see WithRawHoldings), and Data.Holdings is left empty; an error from fn
+// stops the decoding and is returned as is. Problems that
+// only show at the end of the response, such as a holding count differing
+// from Data.TotalRecords, are reported after fn has seen the holdings.
+//
+// Example:
+//
+//	_, err := client.StreamPortfolioDetail(ctx, stockal.PortfolioParams{}, func(h stockal.Holding) error {
+//		fmt.Println(h.Symbol, h.TotalUnit)
+//		return nil
+//	})
+func (c *Client) StreamPortfolioDetail(ctx context.Context, params PortfolioParams, fn func(Holding) error) (*PortfolioDetailResponse, error) {
+	if err := params.Validate(); err != nil {
+		return nil, err
+	}
+	if err := c.authenticate(ctx); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := c.withDeadline(ctx)
+	defer cancel()
+
+	endpoint := "/v2/users/portfolio/detail"
+	if query := params.query(); query != "" {
+		endpoint += "?" + query
+	}
+	resp, err := c.makeRequest(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("portfolio detail request failed: %w", err)
+	}
+
+	portfolioResp := PortfolioDetailResponse{partial: params.partial()}
+
+	// Error responses are small, and unlabelled JSON needs sniffing; both
+	// take the usual path
+	if resp.StatusCode != http.StatusOK || !isJSONResponse(resp.Header.Get("Content-Type"), nil) {
+		if err := c.handleResponse(resp, &portfolioResp, "portfolio detail"); err != nil {
+			return &portfolioResp, err
+		}
+		holdings := portfolioResp.Data.Holdings
+		portfolioResp.Data.Holdings = []Holding{}
+		for _, h := range holdings {
+			if !params.matches(h) {
+				continue
+			}
+			if fn == nil {
+				portfolioResp.Data.Holdings = append(portfolioResp.Data.Holdings, h)
+			} else if err := fn(h); err != nil {
+				return &portfolioResp, err
+			}
+		}
+		c.tidyHoldings(&portfolioResp.Data, params)
+		return &portfolioResp, nil
+	}
+	defer resp.Body.Close()
+
+	var (
+		count    int
+		problems []string
+		fnErr    error
+	)
+	envelope, err := decodePortfolioStream(json.NewDecoder(resp.Body), &portfolioResp.Data, func(h Holding) error {
+		if h.Symbol == "" {
+			problems = append(problems, fmt.Sprintf("holding %d has no symbol", count))
+		}
+		count++
+		switch {
+		case !params.matches(h):
+		case fn == nil:
+			portfolioResp.Data.Holdings = append(portfolioResp.Data.Holdings, h)
+		default:
+			fnErr = fn(h)
+		}
+		return fnErr
+	})
+	if fnErr != nil {
+		return &portfolioResp, fnErr
+	}
+	json.Unmarshal(envelope, &portfolioResp)
+	if err := checkEnvelope(resp.StatusCode, envelope, "portfolio detail"); err != nil {
+		return &portfolioResp, err
+	}
+	if err != nil {
+		return &portfolioResp, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	portfolioResp.normalize()
+	if count > portfolioResp.Data.TotalRecords || !portfolioResp.partial && count != portfolioResp.Data.TotalRecords {
+		problems = append([]string{fmt.Sprintf("totalRecords is %d but %d holdings were returned", portfolioResp.Data.TotalRecords, count)}, problems...)
+	}
+	if len(problems) > 0 {
+		return &portfolioResp, &MalformedResponseError{Operation: "portfolio detail", Problems: problems}
+	}
+	c.tidyHoldings(&portfolioResp.Data, params)
+	return &portfolioResp, nil
+}
+
+// decodePortfolioStream decodes a portfolio detail response from dec, passing
+// each holding to each and stopping at the first error. The other fields of
+// the data object are decoded into data; the envelope's fields besides data
+// are returned as a JSON object, so that its code can be checked.
+func decodePortfolioStream(dec *json.Decoder, data *PortfolioDetailData, each func(Holding) error) ([]byte, error) {
+	envelope := map[string]json.RawMessage{}
+	rest := map[string]json.RawMessage{}
+	err := decodeObject(dec, func(key string) error {
+		if key != "data" {
+			return decodeRaw(dec, envelope, key)
+		}
+		return decodeObject(dec, func(key string) error {
+			if key != "holdings" {
+				return decodeRaw(dec, rest, key)
+			}
+			return decodeArray(dec, func() error {
+				var h Holding
+				if err := dec.Decode(&h); err != nil {
+					return err
+				}
+				return each(h)
+			})
+		})
+	})
+	if err == nil {
+		b, _ := json.Marshal(rest)
+		err = json.Unmarshal(b, data)
+	}
+	b, _ := json.Marshal(envelope)
+	return b, err
+}
+
+// decodeRaw decodes the next value from dec into m under key.
+func decodeRaw(dec *json.Decoder, m map[string]json.RawMessage, key string) error {
+	var v json.RawMessage
+	if err := dec.Decode(&v); err != nil {
+		return err
+	}
+	m[key] = v
+	return nil
+}
+
+// decodeObject reads a JSON object or null from dec, calling field with each
+// key; field must consume the value.
+func decodeObject(dec *json.Decoder, field func(key string) error) error {
+	tok, err := dec.Token()
+	if err != nil || tok == nil {
+		return err
+	}
+	if tok != json.Delim('{') {
+		return fmt.Errorf("expected an object, got %v", tok)
+	}
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		if err := field(key.(string)); err != nil {
+			return err
+		}
+	}
+	_, err = dec.Token()
+	return err
+}
+
+// decodeArray reads a JSON array or null from dec, calling elem while there
+// are elements; elem must consume one.
+func decodeArray(dec *json.Decoder, elem func() error) error {
+	tok, err := dec.Token()
+	if err != nil || tok == nil {
+		return err
+	}
+	if tok != json.Delim('[') {
+		return fmt.Errorf("expected an array, got %v", tok)
+	}
+	for dec.More() {
+		if err := elem(); err != nil {
+			return err
+		}
+	}
+	_, err = dec.Token()
+	return err
+}
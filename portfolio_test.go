@@ -0,0 +1,288 @@
+package stockal_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+)
+
+func TestPortfolioDetailWithParams(t *testing.T) {
+	var query string
+	client := newHandlerClient(t, func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		// One holding of the portfolio's 120, or all of them when not paging
+		total := "120"
+		if !r.URL.Query().Has("limit") && !r.URL.Query().Has("offset") {
+			total = "1"
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"code":200,"message":"Success","data":{"holdings":[{"symbol":"VOO"}],"totalRecords":` + total + `}}`))
+	})
+
+	tests := []struct {
+		params stockal.PortfolioParams
+		query  string
+	}{
+		{stockal.PortfolioParams{}, ""},
+		{stockal.PortfolioParams{Limit: 50, Offset: 100}, "limit=50&offset=100"},
+		{stockal.PortfolioParams{SortBy: "symbol", Order: stockal.SortDescending}, "order=desc&sortBy=symbol"},
+	}
+	for _, tt := range tests {
+		portfolio, err := client.Portfolio().DetailWithParams(context.Background(), tt.params)
+		if err != nil {
+			t.Fatalf("Portfolio.DetailWithParams(%+v): %v", tt.params, err)
+		}
+		if query != tt.query {
+			t.Errorf("Portfolio.DetailWithParams(%+v) sent query %q, want %q", tt.params, query, tt.query)
+		}
+		if len(portfolio.Data.Holdings) != 1 {
+			t.Errorf("got %+v", portfolio.Data)
+		}
+	}
+
+	for _, params := range []stockal.PortfolioParams{
+		{Limit: -1},
+		{Offset: -5},
+		{SortBy: "symbol", Order: "sideways"},
+		{Order: stockal.SortAscending},
+	} {
+		query = "unsent"
+		_, err := client.Portfolio().DetailWithParams(context.Background(), params)
+		if !errors.Is(err, stockal.ErrInvalidParams) {
+			t.Errorf("Portfolio.DetailWithParams(%+v) error = %v, want ErrInvalidParams", params, err)
+		}
+		if query != "unsent" {
+			t.Errorf("Portfolio.DetailWithParams(%+v) sent a request", params)
+		}
+	}
+}
+
+func TestPortfolioDetailFilters(t *testing.T) {
+	var query string
+	client := newHandlerClient(t, func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		// The whole portfolio, as from an API that ignores the filters
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"code":200,"message":"Success","data":{"holdings":[` +
+			`{"symbol":"AAPL","category":"stock"},{"symbol":"VOO","category":"etf"},{"symbol":"QQQ","category":"ETF"}],"totalRecords":3}}`))
+	})
+
+	tests := []struct {
+		params  stockal.PortfolioParams
+		query   string
+		symbols []string
+	}{
+		{stockal.PortfolioParams{Category: stockal.CategoryETF}, "category=etf", []string{"QQQ", "VOO"}},
+		{stockal.PortfolioParams{Symbol: " voo "}, "symbol=VOO", []string{"VOO"}},
+		{stockal.PortfolioParams{Category: stockal.CategoryStock, Symbol: "VOO"}, "category=stock&symbol=VOO", nil},
+	}
+	for _, tt := range tests {
+		portfolio, err := client.Portfolio().DetailWithParams(context.Background(), tt.params)
+		if err != nil {
+			t.Fatalf("Portfolio.DetailWithParams(%+v): %v", tt.params, err)
+		}
+		if query != tt.query {
+			t.Errorf("Portfolio.DetailWithParams(%+v) sent query %q, want %q", tt.params, query, tt.query)
+		}
+		var symbols []string
+		for _, h := range portfolio.Data.Holdings {
+			symbols = append(symbols, h.Symbol)
+		}
+		if !slices.Equal(symbols, tt.symbols) {
+			t.Errorf("Portfolio.DetailWithParams(%+v) returned %v, want %v", tt.params, symbols, tt.symbols)
+		}
+	}
+}
+
+func TestStreamPortfolioDetail(t *testing.T) {
+	// The server sends the rest of the response only once the client has
+	// seen the first holding, so the test only passes if holdings are
+	// decoded as they arrive
+	firstSeen := make(chan struct{})
+	client := newHandlerClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"code":200,"message":"Success","data":{"pendingData":[],"holdings":[{"symbol":"AAPL","category":"stock"},`))
+		w.(http.Flusher).Flush()
+		select {
+		case <-firstSeen:
+		case <-time.After(5 * time.Second):
+		}
+		w.Write([]byte(`{"symbol":"VOO","category":"etf"}],"totalRecords":2}}`))
+	})
+
+	var symbols []string
+	portfolio, err := client.StreamPortfolioDetail(context.Background(), stockal.PortfolioParams{}, func(h stockal.Holding) error {
+		if len(symbols) == 0 {
+			close(firstSeen)
+		}
+		symbols = append(symbols, h.Symbol)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("StreamPortfolioDetail: %v", err)
+	}
+	if !slices.Equal(symbols, []string{"AAPL", "VOO"}) {
+		t.Errorf("callback saw %v", symbols)
+	}
+	if portfolio.Code != 200 || portfolio.Data.TotalRecords != 2 || len(portfolio.Data.Holdings) != 0 {
+		t.Errorf("got %+v", portfolio)
+	}
+}
+
+func TestStreamPortfolioDetailResponses(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		params  stockal.PortfolioParams
+		symbols []string
+		err     error
+	}{
+		{"all", 200, `{"code":200,"data":{"holdings":[{"symbol":"AAPL"},{"symbol":"VOO","category":"etf"}],"totalRecords":2}}`,
+			stockal.PortfolioParams{}, []string{"AAPL", "VOO"}, nil},
+		{"filtered", 200, `{"data":{"holdings":[{"symbol":"AAPL"},{"symbol":"VOO","category":"etf"}],"totalRecords":2},"code":200}`,
+			stockal.PortfolioParams{Category: stockal.CategoryETF}, []string{"VOO"}, nil},
+		{"new account", 200, `{"code":200,"message":"Success","data":null}`, stockal.PortfolioParams{}, []string{}, nil},
+		{"count mismatch", 200, `{"code":200,"data":{"holdings":[{"symbol":"AAPL"}],"totalRecords":3}}`,
+			stockal.PortfolioParams{}, []string{"AAPL"}, stockal.ErrMalformedResponse},
+		{"error in envelope", 200, `{"code":401,"message":"Unauthorized","data":null}`, stockal.PortfolioParams{}, []string{}, &stockal.APIError{}},
+		{"error status", 503, `{"code":503,"message":"Down for maintenance"}`, stockal.PortfolioParams{}, []string{}, &stockal.APIError{}},
+		{"truncated", 200, `{"code":200,"data":{"holdings":[{"symbol":"AAPL"},{"sym`, stockal.PortfolioParams{}, []string{"AAPL"}, errors.New("")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newHandlerClient(t, func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			})
+
+			// Without a callback the holdings are collected
+			portfolio, err := client.StreamPortfolioDetail(context.Background(), tt.params, nil)
+			var apiErr *stockal.APIError
+			switch {
+			case tt.err == nil && err != nil:
+				t.Fatalf("StreamPortfolioDetail: %v", err)
+			case tt.err == nil:
+			case errors.As(tt.err, &apiErr):
+				if !errors.As(err, &apiErr) {
+					t.Errorf("error = %v, want an APIError", err)
+				}
+			case errors.Is(tt.err, stockal.ErrMalformedResponse):
+				if !errors.Is(err, stockal.ErrMalformedResponse) {
+					t.Errorf("error = %v, want ErrMalformedResponse", err)
+				}
+			case err == nil:
+				t.Error("StreamPortfolioDetail succeeded")
+			}
+			if err == nil {
+				symbols := []string{}
+				for _, h := range portfolio.Data.Holdings {
+					symbols = append(symbols, h.Symbol)
+				}
+				if !slices.Equal(symbols, tt.symbols) {
+					t.Errorf("got holdings %v, want %v", symbols, tt.symbols)
+				}
+			}
+		})
+	}
+}
+
+func TestStreamPortfolioDetailStops(t *testing.T) {
+	client := newTestClient(t, map[string]string{
+		"/v2/users/portfolio/detail": `{"code":200,"data":{"holdings":[{"symbol":"AAPL"},{"symbol":"VOO"}],"totalRecords":2}}`,
+	})
+	stop := errors.New("stop")
+	calls := 0
+	_, err := client.StreamPortfolioDetail(context.Background(), stockal.PortfolioParams{}, func(stockal.Holding) error {
+		calls++
+		return stop
+	})
+	if err != stop || calls != 1 {
+		t.Errorf("StreamPortfolioDetail = %v after %d calls, want the callback's error after 1", err, calls)
+	}
+}
+
+func TestAllHoldings(t *testing.T) {
+	holdings := []string{`{"symbol":"AAPL"}`, `{"symbol":"QQQ"}`, `{"symbol":"VOO"}`}
+	page := func(r *http.Request) string {
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		end := min(offset+limit, len(holdings))
+		return `{"code":200,"message":"Success","data":{"holdings":[` + strings.Join(holdings[offset:end], ",") + `],"totalRecords":3}}`
+	}
+	client := newHandlerClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(page(r)))
+	})
+
+	all, err := stockal.AllHoldings(context.Background(), client.Portfolio(), stockal.PortfolioParams{Limit: 2})
+	if err != nil {
+		t.Fatalf("AllHoldings: %v", err)
+	}
+	if len(all) != 3 {
+		t.Errorf("got %+v", all)
+	}
+
+	cancelling, ctx := newCancellingClient(t, page)
+	params := stockal.PortfolioParams{Limit: 2}
+	all, err = stockal.AllHoldings(ctx, cancelling.Portfolio(), params)
+	var partial *stockal.PartialError
+	if !errors.As(err, &partial) || len(all) != 2 || !slices.Equal(partial.Completed, []string{"AAPL", "QQQ"}) {
+		t.Fatalf("AllHoldings = %+v, %v; want the first page and a PartialError", all, err)
+	}
+	params.Offset += len(all)
+	rest, err := stockal.AllHoldings(context.Background(), client.Portfolio(), params)
+	if err != nil || len(rest) != 1 || rest[0].Symbol != "VOO" {
+		t.Errorf("resumed AllHoldings = %+v, %v", rest, err)
+	}
+}
+
+func TestAllHoldingsMergesAcrossPages(t *testing.T) {
+	rows := []string{
+		`{"symbol":"AAA","category":"stock","totalUnit":1}`, `{"symbol":"AAA","category":"stock","totalUnit":2}`,
+		`{"symbol":"BBB","category":"stock","totalUnit":1}`, `{"symbol":"CCC","category":"stock","totalUnit":1}`,
+		`{"symbol":"DDD","category":"stock","totalUnit":1}`, `{"symbol":"CCC","category":"stock","totalUnit":4}`,
+		`{"symbol":"EEE","category":"stock","totalUnit":1}`,
+	}
+	var offsets []string
+	client := newTestClientFunc(t, func(r *http.Request) string {
+		offsets = append(offsets, r.URL.Query().Get("offset"))
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		end := min(offset+limit, len(rows))
+		return `{"code":200,"message":"Success","data":{"holdings":[` + strings.Join(rows[offset:end], ",") + `],"totalRecords":7}}`
+	})
+
+	page, err := client.Portfolio().DetailWithParams(context.Background(), stockal.PortfolioParams{Limit: 3})
+	if err != nil {
+		t.Fatalf("Portfolio.DetailWithParams: %v", err)
+	}
+	if len(page.Data.Holdings) != 3 || page.Data.TotalRecords != 7 {
+		t.Errorf("page has %d holdings of %d, want 3 of 7 as sent", len(page.Data.Holdings), page.Data.TotalRecords)
+	}
+
+	offsets = nil
+	all, err := stockal.AllHoldings(context.Background(), client.Portfolio(), stockal.PortfolioParams{Limit: 3})
+	if err != nil {
+		t.Fatalf("AllHoldings: %v", err)
+	}
+	if !slices.Equal(offsets, []string{"", "3", "6"}) {
+		t.Errorf("requested offsets %q, want every row once", offsets)
+	}
+	var got []string
+	for _, h := range all {
+		got = append(got, fmt.Sprintf("%s:%g", h.Symbol, h.TotalUnit))
+	}
+	if want := []string{"AAA:3", "BBB:1", "CCC:5", "DDD:1", "EEE:1"}; !slices.Equal(got, want) {
+		t.Errorf("AllHoldings = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,243 @@
+// Package alphavantage provides historical candles from Alpha Vantage
+// (https://www.alphavantage.co) as a stockal.CandleProvider, and dividend and
+// earnings dates as a stockal.CorporateEventProvider:
+//
+//	provider := alphavantage.New(os.Getenv("ALPHAVANTAGE_API_KEY"))
+//	candles, err := provider.Candles(ctx, "AAPL", stockal.IntervalDay, from, to)
+//
+// Daily and weekly series cover the symbol's full history. Intraday series
+// cover the most recent 30 days only. Free API keys are limited to a few
+// requests per minute; the limit is reported as ErrRateLimited.
+package alphavantage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+)
+
+// BaseURL is the Alpha Vantage API used by default.
+const BaseURL = "https://www.alphavantage.co"
+
+// ErrRateLimited is returned when Alpha Vantage reports that the API key's
+// request limit has been reached.
+var ErrRateLimited = errors.New("alphavantage: rate limit reached")
+
+// Provider fetches candles and corporate events from Alpha Vantage. It
+// implements stockal.CandleProvider and stockal.CorporateEventProvider.
+type Provider struct {
+	apiKey     string
+	baseURL    string
+	httpClient *http.Client
+}
+
+var (
+	_ stockal.CandleProvider         = (*Provider)(nil)
+	_ stockal.CorporateEventProvider = (*Provider)(nil)
+)
+
+// Option configures a Provider.
+type Option func(*Provider)
+
+// WithHTTPClient sets the HTTP client used for requests.
+func WithHTTPClient(client *http.Client) Option {
+	return func(p *Provider) {
+		p.httpClient = client
+	}
+}
+
+// WithBaseURL sets the API base URL, for tests.
+func WithBaseURL(baseURL string) Option {
+	return func(p *Provider) {
+		p.baseURL = strings.TrimSuffix(baseURL, "/")
+	}
+}
+
+// New creates a Provider using apiKey.
+func New(apiKey string, options ...Option) *Provider {
+	p := &Provider{apiKey: apiKey, baseURL: BaseURL, httpClient: &http.Client{Timeout: 30 * time.Second}}
+	for _, option := range options {
+		option(p)
+	}
+	return p
+}
+
+// series describes the Alpha Vantage function serving an interval.
+type series struct {
+	function string
+	// key is the JSON key of the time series in the response
+	key string
+	// intraday is the interval parameter of intraday functions
+	intraday string
+}
+
+var seriesByInterval = map[stockal.Interval]series{
+	stockal.Interval1Min: {function: "TIME_SERIES_INTRADAY", key: "Time Series (1min)", intraday: "1min"},
+	stockal.Interval5Min: {function: "TIME_SERIES_INTRADAY", key: "Time Series (5min)", intraday: "5min"},
+	stockal.IntervalDay:  {function: "TIME_SERIES_DAILY", key: "Time Series (Daily)"},
+	stockal.IntervalWeek: {function: "TIME_SERIES_WEEKLY", key: "Weekly Time Series"},
+}
+
+// Candles returns the bars of symbol starting within [from, to], oldest first.
+func (p *Provider) Candles(ctx context.Context, symbol string, interval stockal.Interval, from, to time.Time) ([]stockal.Candle, error) {
+	s, ok := seriesByInterval[interval]
+	if !ok {
+		return nil, fmt.Errorf("alphavantage: %w: %q", stockal.ErrUnsupportedInterval, interval)
+	}
+
+	query := url.Values{
+		"function":   {s.function},
+		"symbol":     {strings.ToUpper(symbol)},
+		"outputsize": {"full"},
+	}
+	if s.intraday != "" {
+		query.Set("interval", s.intraday)
+	}
+	body, err := p.query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	raw, ok := body[s.key]
+	if !ok {
+		return nil, fmt.Errorf("alphavantage: response has no %q", s.key)
+	}
+	var bars map[string]map[string]string
+	if err := json.Unmarshal(raw, &bars); err != nil {
+		return nil, fmt.Errorf("alphavantage: decode %s: %w", s.key, err)
+	}
+
+	candles := make([]stockal.Candle, 0, len(bars))
+	for stamp, bar := range bars {
+		t, err := parseTime(stamp)
+		if err != nil {
+			return nil, fmt.Errorf("alphavantage: %w", err)
+		}
+		if t.Before(from) || t.After(to) {
+			continue
+		}
+		c, err := candle(t, bar)
+		if err != nil {
+			return nil, fmt.Errorf("alphavantage: %s: %w", stamp, err)
+		}
+		candles = append(candles, c)
+	}
+	slices.SortFunc(candles, func(a, b stockal.Candle) int { return a.Time.Compare(b.Time) })
+	return candles, nil
+}
+
+// get performs an API request and returns the response body, which the
+// caller must close.
+func (p *Provider) get(ctx context.Context, query url.Values) (io.ReadCloser, error) {
+	query.Set("apikey", p.apiKey)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.baseURL+"/query?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		// The request URL carries the API key.
+		return nil, fmt.Errorf("alphavantage: %w", stockal.RedactError(err, p.apiKey))
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("alphavantage: HTTP %d", resp.StatusCode)
+	}
+	return resp.Body, nil
+}
+
+// query performs a JSON API request, reporting the errors and notices that
+// Alpha Vantage returns with status 200.
+func (p *Provider) query(ctx context.Context, query url.Values) (map[string]json.RawMessage, error) {
+	r, err := p.get(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	var body map[string]json.RawMessage
+	if err := json.NewDecoder(r).Decode(&body); err != nil {
+		return nil, fmt.Errorf("alphavantage: decode: %w", err)
+	}
+	if err := notice(body); err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
+// notice returns the error reported in a response body, if any.
+func notice(body map[string]json.RawMessage) error {
+	if msg, ok := message(body, "Error Message"); ok {
+		return fmt.Errorf("alphavantage: %s", msg)
+	}
+	for _, key := range []string{"Note", "Information"} {
+		if msg, ok := message(body, key); ok {
+			return fmt.Errorf("%w: %s", ErrRateLimited, msg)
+		}
+	}
+	return nil
+}
+
+// message returns a string field of the response, used for errors and notices.
+func message(body map[string]json.RawMessage, key string) (string, bool) {
+	raw, ok := body[key]
+	if !ok {
+		return "", false
+	}
+	var msg string
+	if err := json.Unmarshal(raw, &msg); err != nil {
+		return string(raw), true
+	}
+	return msg, true
+}
+
+// easternLocation is the US/Eastern zone Alpha Vantage timestamps are in.
+var easternLocation = sync.OnceValue(func() *time.Location {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		return time.FixedZone("EST", -5*60*60)
+	}
+	return loc
+})
+
+// parseTime parses a series timestamp, a date for daily and weekly bars or a
+// date and time for intraday bars.
+func parseTime(stamp string) (time.Time, error) {
+	layout := time.DateOnly
+	if len(stamp) > len(time.DateOnly) {
+		layout = time.DateTime
+	}
+	return time.ParseInLocation(layout, stamp, easternLocation())
+}
+
+func candle(t time.Time, bar map[string]string) (stockal.Candle, error) {
+	c := stockal.Candle{Time: t}
+	for _, f := range []struct {
+		key string
+		dst *float64
+	}{
+		{"1. open", &c.Open}, {"2. high", &c.High}, {"3. low", &c.Low}, {"4. close", &c.Close},
+	} {
+		v, err := strconv.ParseFloat(bar[f.key], 64)
+		if err != nil {
+			return stockal.Candle{}, fmt.Errorf("invalid %s %q", f.key, bar[f.key])
+		}
+		*f.dst = v
+	}
+	volume, err := strconv.ParseInt(bar["5. volume"], 10, 64)
+	if err != nil {
+		return stockal.Candle{}, fmt.Errorf("invalid volume %q", bar["5. volume"])
+	}
+	c.Volume = volume
+	return c, nil
+}
@@ -0,0 +1,214 @@
+package alphavantage_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+	"github.com/adjaecent/unofficial-stockal-api/providers/alphavantage"
+)
+
+// daily is a TIME_SERIES_DAILY response for AAPL.
+const daily = `{"Meta Data":{"2. Symbol":"AAPL"},"Time Series (Daily)":{
+	"2024-05-03":{"1. open":"186.65","2. high":"187.00","3. low":"182.66","4. close":"183.38","5. volume":"163224109"},
+	"2024-05-01":{"1. open":"169.58","2. high":"172.71","3. low":"169.11","4. close":"169.30","5. volume":"50383147"},
+	"2024-05-02":{"1. open":"172.51","2. high":"173.42","3. low":"170.89","4. close":"173.03","5. volume":"94214915"},
+	"2024-04-30":{"1. open":"173.33","2. high":"174.99","3. low":"170.00","4. close":"170.33","5. volume":"65934776"}}}`
+
+// response is a fake API response: an HTTP status, or 200 with a body.
+type response struct {
+	status int
+	body   string
+}
+
+// newServer returns a fake Alpha Vantage answering each function with its
+// response, checking that requests carry the API key "K3Y".
+func newServer(t *testing.T, responses map[string]response) *httptest.Server {
+	server, _ := newRecordingServer(t, responses)
+	return server
+}
+
+// newRecordingServer is newServer, also returning a function that returns the
+// query of the last request.
+func newRecordingServer(t *testing.T, responses map[string]response) (*httptest.Server, func() url.Values) {
+	t.Helper()
+	var (
+		mu   sync.Mutex
+		last url.Values
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/query" || r.URL.Query().Get("apikey") != "K3Y" {
+			t.Errorf("request %s, want /query with the API key", r.URL)
+		}
+		mu.Lock()
+		last = r.URL.Query()
+		mu.Unlock()
+		resp, ok := responses[r.URL.Query().Get("function")]
+		if !ok {
+			fmt.Fprint(w, `{"Error Message":"This API function does not exist."}`)
+			return
+		}
+		if resp.status != 0 {
+			w.WriteHeader(resp.status)
+			return
+		}
+		fmt.Fprint(w, resp.body)
+	}))
+	t.Cleanup(server.Close)
+	return server, func() url.Values {
+		mu.Lock()
+		defer mu.Unlock()
+		return last
+	}
+}
+
+func TestCandles(t *testing.T) {
+	server, last := newRecordingServer(t, map[string]response{"TIME_SERIES_DAILY": {body: daily}})
+	p := alphavantage.New("K3Y", alphavantage.WithBaseURL(server.URL+"/"), alphavantage.WithHTTPClient(server.Client()))
+
+	ny, _ := time.LoadLocation("America/New_York")
+	from, to := time.Date(2024, 5, 1, 0, 0, 0, 0, ny), time.Date(2024, 5, 2, 0, 0, 0, 0, ny)
+	candles, err := p.Candles(context.Background(), "aapl", stockal.IntervalDay, from, to)
+	if err != nil {
+		t.Fatalf("Candles: %v", err)
+	}
+	if query := last(); query.Get("symbol") != "AAPL" || query.Get("outputsize") != "full" || query.Has("interval") {
+		t.Errorf("query %v, want the full daily series of AAPL", query)
+	}
+	if len(candles) != 2 || !candles[0].Time.Equal(from) || !candles[1].Time.Equal(to) {
+		t.Fatalf("candles %+v, want 1 and 2 May, oldest first", candles)
+	}
+	if c := candles[1]; c.Open != 172.51 || c.High != 173.42 || c.Low != 170.89 || c.Close != 173.03 || c.Volume != 94214915 {
+		t.Errorf("2 May candle %+v, want the series' figures", c)
+	}
+
+	if _, err := p.Candles(context.Background(), "AAPL", stockal.Interval("1h"), from, to); !errors.Is(err, stockal.ErrUnsupportedInterval) {
+		t.Errorf("Candles hourly = %v, want ErrUnsupportedInterval", err)
+	}
+}
+
+func TestCandlesIntraday(t *testing.T) {
+	server, last := newRecordingServer(t, map[string]response{"TIME_SERIES_INTRADAY": {body: `{"Time Series (5min)":{
+		"2024-05-01 09:35:00":{"1. open":"169.58","2. high":"170.10","3. low":"169.40","4. close":"170.00","5. volume":"1200345"}}}`}})
+	p := alphavantage.New("K3Y", alphavantage.WithBaseURL(server.URL), alphavantage.WithHTTPClient(server.Client()))
+
+	candles, err := p.Candles(context.Background(), "AAPL", stockal.Interval5Min, time.Time{}, time.Now())
+	if err != nil {
+		t.Fatalf("Candles: %v", err)
+	}
+	if interval := last().Get("interval"); interval != "5min" {
+		t.Errorf("interval %q, want 5min", interval)
+	}
+	// 9:35 in New York summer time
+	if len(candles) != 1 || !candles[0].Time.Equal(time.Date(2024, 5, 1, 13, 35, 0, 0, time.UTC)) {
+		t.Errorf("candles %+v, want one at 13:35 UTC", candles)
+	}
+}
+
+func TestCandlesFail(t *testing.T) {
+	tests := []struct {
+		name      string
+		response  response
+		want      string
+		rateLimit bool
+	}{
+		{name: "status", response: response{status: http.StatusInternalServerError}, want: "alphavantage: HTTP 500"},
+		{name: "error message", response: response{body: `{"Error Message":"Invalid API call."}`}, want: "alphavantage: Invalid API call."},
+		{name: "note", response: response{body: `{"Note":"Thank you for using Alpha Vantage! Our standard API call frequency is 5 calls per minute."}`}, want: "5 calls per minute", rateLimit: true},
+		{name: "information", response: response{body: `{"Information":"We have detected your API key as K3Y and our standard API rate limit is 25 requests per day."}`}, want: "25 requests per day", rateLimit: true},
+		{name: "no series", response: response{body: `{"Meta Data":{}}`}, want: `alphavantage: response has no "Time Series (Daily)"`},
+		{name: "invalid price", response: response{body: `{"Time Series (Daily)":{"2024-05-01":{"1. open":"n/a"}}}`}, want: `alphavantage: 2024-05-01: invalid 1. open "n/a"`},
+		{name: "invalid volume", response: response{body: `{"Time Series (Daily)":{"2024-05-01":{"1. open":"1","2. high":"1","3. low":"1","4. close":"1","5. volume":"1.5"}}}`}, want: `invalid volume "1.5"`},
+		{name: "not json", response: response{body: "<html>"}, want: "alphavantage: decode:"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newServer(t, map[string]response{"TIME_SERIES_DAILY": tt.response})
+			p := alphavantage.New("K3Y", alphavantage.WithBaseURL(server.URL), alphavantage.WithHTTPClient(server.Client()))
+
+			_, err := p.Candles(context.Background(), "AAPL", stockal.IntervalDay, time.Time{}, time.Now())
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Candles = %v, want %q", err, tt.want)
+			}
+			if errors.Is(err, alphavantage.ErrRateLimited) != tt.rateLimit {
+				t.Errorf("Candles = %v, rate limited %v", err, !tt.rateLimit)
+			}
+		})
+	}
+}
+
+func TestCandlesUnreachable(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+
+	p := alphavantage.New("S3CR3TK3Y", alphavantage.WithBaseURL(server.URL))
+	_, err := p.Candles(context.Background(), "AAPL", stockal.IntervalDay, time.Time{}, time.Now())
+	if err == nil || strings.Contains(err.Error(), "S3CR3TK3Y") {
+		t.Errorf("Candles = %v, want an error without the API key", err)
+	}
+}
+
+func TestCorporateEvents(t *testing.T) {
+	server := newServer(t, map[string]response{
+		"DIVIDENDS": {body: `{"symbol":"AAPL","data":[
+			{"ex_dividend_date":"2024-05-10","declaration_date":"2024-05-02","record_date":"2024-05-13","payment_date":"2024-05-16","amount":"0.25"},
+			{"ex_dividend_date":"2024-02-09","payment_date":"2024-02-15","amount":"0.24"},
+			{"ex_dividend_date":"2024-05-20","payment_date":"None","amount":"0.10"}]}`},
+		"EARNINGS_CALENDAR": {body: "symbol,name,reportDate,fiscalDateEnding,estimate,currency\n" +
+			"AAPL,Apple Inc,2024-05-02,2024-03-31,1.5,USD\n" +
+			"MSFT,Microsoft Corp,2024-05-03,2024-03-31,2.82,USD\n" +
+			"AAPL,Apple Inc,2024-08-01,2024-06-30,,USD\n"},
+	})
+	p := alphavantage.New("K3Y", alphavantage.WithBaseURL(server.URL), alphavantage.WithHTTPClient(server.Client()))
+
+	from, to := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 5, 31, 0, 0, 0, 0, time.UTC)
+	events, err := p.CorporateEvents(context.Background(), from, to, "aapl")
+	if err != nil {
+		t.Fatalf("CorporateEvents: %v", err)
+	}
+	var got []string
+	for _, e := range events {
+		got = append(got, fmt.Sprintf("%s %s %s %g %s", e.Date.Format(time.DateOnly), e.Symbol, e.Kind, e.Amount, e.Currency))
+	}
+	want := []string{
+		"2024-05-02 AAPL " + string(stockal.EventEarnings) + " 1.5 USD",
+		"2024-05-10 AAPL " + string(stockal.EventExDividend) + " 0.25 USD",
+		"2024-05-16 AAPL " + string(stockal.EventDividendPayment) + " 0.25 USD",
+		"2024-05-20 AAPL " + string(stockal.EventExDividend) + " 0.1 USD",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("events\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestCorporateEventsFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		earnings response
+		want     string
+	}{
+		{name: "rate limited", earnings: response{body: `{"Information":"Please subscribe to any of the premium plans."}`}, want: "premium plans"},
+		{name: "unexpected json", earnings: response{body: `{"data":[]}`}, want: "alphavantage: unexpected earnings calendar response"},
+		{name: "missing column", earnings: response{body: "symbol,name,reportDate\nAAPL,Apple Inc,2024-05-02\n"}, want: `alphavantage: earnings calendar has no "estimate" column`},
+		{name: "status", earnings: response{status: http.StatusBadGateway}, want: "alphavantage: HTTP 502"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newServer(t, map[string]response{"DIVIDENDS": {body: `{"data":[]}`}, "EARNINGS_CALENDAR": tt.earnings})
+			p := alphavantage.New("K3Y", alphavantage.WithBaseURL(server.URL), alphavantage.WithHTTPClient(server.Client()))
+
+			_, err := p.CorporateEvents(context.Background(), time.Time{}, time.Now(), "AAPL")
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("CorporateEvents = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
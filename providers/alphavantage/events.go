@@ -0,0 +1,169 @@
+package alphavantage
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+)
+
+// CorporateEvents returns the ex-dividend, dividend payment and earnings dates
+// of symbols within [from, to], ordered by date.
+//
+// Dividends take one request per symbol. Earnings dates come from a single
+// request covering the next three months, so earnings outside that horizon
+// are not reported. Amounts are in USD.
+func (p *Provider) CorporateEvents(ctx context.Context, from, to time.Time, symbols ...string) ([]stockal.CorporateEvent, error) {
+	var events []stockal.CorporateEvent
+	for _, symbol := range symbols {
+		dividends, err := p.dividends(ctx, strings.ToUpper(symbol), from, to)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, dividends...)
+	}
+	earnings, err := p.earnings(ctx, from, to, symbols)
+	if err != nil {
+		return nil, err
+	}
+	events = append(events, earnings...)
+
+	slices.SortStableFunc(events, func(a, b stockal.CorporateEvent) int {
+		if c := a.Date.Compare(b.Date); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Symbol, b.Symbol)
+	})
+	return events, nil
+}
+
+// dividend is an entry of the DIVIDENDS function. Unknown dates are "None".
+type dividend struct {
+	ExDividendDate string `json:"ex_dividend_date"`
+	PaymentDate    string `json:"payment_date"`
+	Amount         string `json:"amount"`
+}
+
+func (p *Provider) dividends(ctx context.Context, symbol string, from, to time.Time) ([]stockal.CorporateEvent, error) {
+	body, err := p.query(ctx, url.Values{"function": {"DIVIDENDS"}, "symbol": {symbol}})
+	if err != nil {
+		return nil, err
+	}
+	var data []dividend
+	if raw, ok := body["data"]; ok {
+		if err := json.Unmarshal(raw, &data); err != nil {
+			return nil, fmt.Errorf("alphavantage: decode dividends: %w", err)
+		}
+	}
+
+	var events []stockal.CorporateEvent
+	for _, d := range data {
+		amount, _ := strconv.ParseFloat(d.Amount, 64)
+		for _, date := range []struct {
+			kind  stockal.CorporateEventKind
+			value string
+		}{
+			{stockal.EventExDividend, d.ExDividendDate},
+			{stockal.EventDividendPayment, d.PaymentDate},
+		} {
+			t, err := time.Parse(time.DateOnly, date.value)
+			if err != nil || t.Before(from) || t.After(to) {
+				continue
+			}
+			events = append(events, stockal.CorporateEvent{
+				Symbol:   symbol,
+				Kind:     date.kind,
+				Date:     t,
+				Amount:   amount,
+				Currency: "USD",
+			})
+		}
+	}
+	return events, nil
+}
+
+// earnings reads the EARNINGS_CALENDAR function, a CSV of upcoming reports:
+//
+//	symbol,name,reportDate,fiscalDateEnding,estimate,currency
+func (p *Provider) earnings(ctx context.Context, from, to time.Time, symbols []string) ([]stockal.CorporateEvent, error) {
+	if len(symbols) == 0 {
+		return nil, nil
+	}
+	r, err := p.get(ctx, url.Values{"function": {"EARNINGS_CALENDAR"}, "horizon": {"3month"}})
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	// Errors and notices come back as JSON rather than CSV.
+	br := bufio.NewReader(r)
+	if first, _ := br.Peek(1); bytes.Equal(first, []byte("{")) {
+		var body map[string]json.RawMessage
+		if err := json.NewDecoder(br).Decode(&body); err != nil {
+			return nil, fmt.Errorf("alphavantage: decode: %w", err)
+		}
+		if err := notice(body); err != nil {
+			return nil, err
+		}
+		return nil, errors.New("alphavantage: unexpected earnings calendar response")
+	}
+
+	wanted := make(map[string]bool, len(symbols))
+	for _, symbol := range symbols {
+		wanted[strings.ToUpper(symbol)] = true
+	}
+
+	cr := csv.NewReader(br)
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("alphavantage: earnings calendar: %w", err)
+	}
+	column := make(map[string]int, len(header))
+	for i, name := range header {
+		column[name] = i
+	}
+	for _, name := range []string{"symbol", "reportDate", "estimate", "currency"} {
+		if _, ok := column[name]; !ok {
+			return nil, fmt.Errorf("alphavantage: earnings calendar has no %q column", name)
+		}
+	}
+
+	var events []stockal.CorporateEvent
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("alphavantage: earnings calendar: %w", err)
+		}
+		symbol := record[column["symbol"]]
+		if !wanted[symbol] {
+			continue
+		}
+		t, err := time.Parse(time.DateOnly, record[column["reportDate"]])
+		if err != nil || t.Before(from) || t.After(to) {
+			continue
+		}
+		estimate, _ := strconv.ParseFloat(record[column["estimate"]], 64)
+		events = append(events, stockal.CorporateEvent{
+			Symbol:   symbol,
+			Kind:     stockal.EventEarnings,
+			Date:     t,
+			Amount:   estimate,
+			Currency: record[column["currency"]],
+		})
+	}
+	return events, nil
+}
@@ -0,0 +1,172 @@
+// Package polygon provides historical candles from Polygon.io
+// (https://polygon.io) as a stockal.CandleProvider:
+//
+//	provider := polygon.New(os.Getenv("POLYGON_API_KEY"))
+//	candles, err := provider.Candles(ctx, "AAPL", stockal.IntervalDay, from, to)
+//
+// Prices are split-adjusted. How far back data goes depends on the plan of
+// the API key.
+package polygon
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+)
+
+// BaseURL is the Polygon API used by default.
+const BaseURL = "https://api.polygon.io"
+
+// maxPages bounds the pages followed by one Candles call.
+const maxPages = 100
+
+// Provider fetches candles from Polygon. It implements stockal.CandleProvider.
+type Provider struct {
+	apiKey     string
+	baseURL    string
+	httpClient *http.Client
+}
+
+var _ stockal.CandleProvider = (*Provider)(nil)
+
+// Option configures a Provider.
+type Option func(*Provider)
+
+// WithHTTPClient sets the HTTP client used for requests.
+func WithHTTPClient(client *http.Client) Option {
+	return func(p *Provider) {
+		p.httpClient = client
+	}
+}
+
+// WithBaseURL sets the API base URL, for tests.
+func WithBaseURL(baseURL string) Option {
+	return func(p *Provider) {
+		p.baseURL = strings.TrimSuffix(baseURL, "/")
+	}
+}
+
+// New creates a Provider using apiKey.
+func New(apiKey string, options ...Option) *Provider {
+	p := &Provider{apiKey: apiKey, baseURL: BaseURL, httpClient: &http.Client{Timeout: 30 * time.Second}}
+	for _, option := range options {
+		option(p)
+	}
+	return p
+}
+
+// timespans maps intervals to Polygon's multiplier and timespan.
+var timespans = map[stockal.Interval]struct {
+	multiplier int
+	timespan   string
+}{
+	stockal.Interval1Min: {1, "minute"},
+	stockal.Interval5Min: {5, "minute"},
+	stockal.IntervalDay:  {1, "day"},
+	stockal.IntervalWeek: {1, "week"},
+}
+
+// aggregatesResponse is the aggregates (bars) API response.
+type aggregatesResponse struct {
+	Status  string `json:"status"`
+	Error   string `json:"error"`
+	Message string `json:"message"`
+	Results []struct {
+		Open   float64 `json:"o"`
+		High   float64 `json:"h"`
+		Low    float64 `json:"l"`
+		Close  float64 `json:"c"`
+		Volume float64 `json:"v"`
+		// Time is the start of the bar in Unix milliseconds
+		Time int64 `json:"t"`
+	} `json:"results"`
+	NextURL string `json:"next_url"`
+}
+
+// Candles returns the bars of symbol starting within [from, to], oldest
+// first, following Polygon's pagination.
+func (p *Provider) Candles(ctx context.Context, symbol string, interval stockal.Interval, from, to time.Time) ([]stockal.Candle, error) {
+	span, ok := timespans[interval]
+	if !ok {
+		return nil, fmt.Errorf("polygon: %w: %q", stockal.ErrUnsupportedInterval, interval)
+	}
+
+	next := fmt.Sprintf("%s/v2/aggs/ticker/%s/range/%d/%s/%d/%d?adjusted=true&sort=asc&limit=50000",
+		p.baseURL, url.PathEscape(strings.ToUpper(symbol)), span.multiplier, span.timespan, from.UnixMilli(), to.UnixMilli())
+	var candles []stockal.Candle
+	for page := 0; next != ""; page++ {
+		if page == maxPages {
+			return nil, fmt.Errorf("polygon: more than %d pages; narrow the range", maxPages)
+		}
+		resp, err := p.get(ctx, next)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range resp.Results {
+			candles = append(candles, stockal.Candle{
+				Time:   time.UnixMilli(r.Time).UTC(),
+				Open:   r.Open,
+				High:   r.High,
+				Low:    r.Low,
+				Close:  r.Close,
+				Volume: int64(r.Volume),
+			})
+		}
+		next = resp.NextURL
+	}
+	return candles, nil
+}
+
+// get fetches one page, authenticating with the API key.
+func (p *Provider) get(ctx context.Context, endpoint string) (*aggregatesResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+p.apiKey)
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("polygon: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var body aggregatesResponse
+	decodeErr := json.NewDecoder(resp.Body).Decode(&body)
+	if resp.StatusCode != http.StatusOK {
+		msg := body.Error
+		if msg == "" {
+			msg = body.Message
+		}
+		if resp.StatusCode == http.StatusTooManyRequests {
+			return nil, &stockal.RateLimitError{RetryAfter: retryAfter(resp), Limit: -1, Remaining: -1}
+		}
+		if msg != "" {
+			return nil, fmt.Errorf("polygon: HTTP %d: %s", resp.StatusCode, msg)
+		}
+		return nil, fmt.Errorf("polygon: HTTP %d", resp.StatusCode)
+	}
+	if decodeErr != nil {
+		return nil, fmt.Errorf("polygon: decode: %w", decodeErr)
+	}
+	if body.Status == "ERROR" {
+		return nil, errors.New("polygon: " + body.Error)
+	}
+	return &body, nil
+}
+
+func retryAfter(resp *http.Response) time.Duration {
+	seconds, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+	if err != nil {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
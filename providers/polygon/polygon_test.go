@@ -0,0 +1,155 @@
+package polygon_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+	"github.com/adjaecent/unofficial-stockal-api/providers/polygon"
+)
+
+// newServer returns a fake Polygon calling handler for requests that carry
+// the API key "K3Y".
+func newServer(t *testing.T, handler http.HandlerFunc) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if auth := r.Header.Get("Authorization"); auth != "Bearer K3Y" {
+			t.Errorf("Authorization %q, want the API key", auth)
+		}
+		handler(w, r)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestCandles(t *testing.T) {
+	from, to := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 5, 3, 0, 0, 0, 0, time.UTC)
+	var server *httptest.Server
+	server = newServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case fmt.Sprintf("/v2/aggs/ticker/AAPL/range/1/day/%d/%d", from.UnixMilli(), to.UnixMilli()):
+			if q := r.URL.Query(); q.Get("adjusted") != "true" || q.Get("sort") != "asc" {
+				t.Errorf("query %v, want adjusted bars, oldest first", q)
+			}
+			fmt.Fprintf(w, `{"ticker":"AAPL","status":"OK","resultsCount":2,"results":[
+				{"v":5.0383147e+07,"vw":170.9,"o":169.58,"c":169.3,"h":172.71,"l":169.11,"t":1714536000000,"n":720000},
+				{"v":9.4214915e+07,"o":172.51,"c":173.03,"h":173.42,"l":170.89,"t":1714622400000}],
+				"next_url":"%s/v2/aggs/ticker/AAPL/range/1/day/1714708800000/%d?cursor=abc"}`, server.URL, to.UnixMilli())
+		case fmt.Sprintf("/v2/aggs/ticker/AAPL/range/1/day/1714708800000/%d", to.UnixMilli()):
+			if cursor := r.URL.Query().Get("cursor"); cursor != "abc" {
+				t.Errorf("cursor %q, want the next page's", cursor)
+			}
+			fmt.Fprint(w, `{"ticker":"AAPL","status":"DELAYED","results":[{"v":163224109,"o":186.65,"c":183.38,"h":187,"l":182.66,"t":1714708800000}]}`)
+		default:
+			t.Errorf("unexpected request %s", r.URL)
+			http.NotFound(w, r)
+		}
+	})
+	p := polygon.New("K3Y", polygon.WithBaseURL(server.URL+"/"), polygon.WithHTTPClient(server.Client()))
+
+	candles, err := p.Candles(context.Background(), "aapl", stockal.IntervalDay, from, to)
+	if err != nil {
+		t.Fatalf("Candles: %v", err)
+	}
+	if len(candles) != 3 {
+		t.Fatalf("candles %+v, want both pages", candles)
+	}
+	if c := candles[1]; !c.Time.Equal(time.Date(2024, 5, 2, 4, 0, 0, 0, time.UTC)) || c.Time.Location() != time.UTC ||
+		c.Open != 172.51 || c.High != 173.42 || c.Low != 170.89 || c.Close != 173.03 || c.Volume != 94214915 {
+		t.Errorf("2 May candle %+v, want the bar's figures", c)
+	}
+	if c := candles[2]; c.Close != 183.38 || c.Volume != 163224109 {
+		t.Errorf("3 May candle %+v, want the second page's bar", c)
+	}
+}
+
+func TestCandlesIntervals(t *testing.T) {
+	var path string
+	server := newServer(t, func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		fmt.Fprint(w, `{"status":"OK","resultsCount":0}`)
+	})
+	p := polygon.New("K3Y", polygon.WithBaseURL(server.URL), polygon.WithHTTPClient(server.Client()))
+
+	for interval, want := range map[stockal.Interval]string{
+		stockal.Interval1Min: "/range/1/minute/",
+		stockal.Interval5Min: "/range/5/minute/",
+		stockal.IntervalWeek: "/range/1/week/",
+	} {
+		candles, err := p.Candles(context.Background(), "BRK.B", interval, time.Time{}, time.Now())
+		if err != nil || len(candles) != 0 {
+			t.Errorf("Candles %s = %+v, %v; want none", interval, candles, err)
+		}
+		if !strings.HasPrefix(path, "/v2/aggs/ticker/BRK.B"+want) {
+			t.Errorf("Candles %s requested %s, want %s", interval, path, want)
+		}
+	}
+	if _, err := p.Candles(context.Background(), "AAPL", stockal.Interval("1h"), time.Time{}, time.Now()); !errors.Is(err, stockal.ErrUnsupportedInterval) {
+		t.Errorf("Candles hourly = %v, want ErrUnsupportedInterval", err)
+	}
+}
+
+func TestCandlesFail(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		want   string
+	}{
+		{name: "error", status: http.StatusForbidden, body: `{"status":"NOT_AUTHORIZED","request_id":"1","message":"You are not entitled to this data."}`, want: "polygon: HTTP 403: You are not entitled to this data."},
+		{name: "bad request", status: http.StatusBadRequest, body: `{"status":"ERROR","request_id":"2","error":"Could not parse the time parameter: 'from'."}`, want: "polygon: HTTP 400: Could not parse the time parameter"},
+		{name: "status only", status: http.StatusBadGateway, body: "<html>Bad gateway</html>", want: "polygon: HTTP 502"},
+		{name: "error status", status: http.StatusOK, body: `{"status":"ERROR","error":"Unknown API Key"}`, want: "polygon: Unknown API Key"},
+		{name: "not json", status: http.StatusOK, body: "<html>", want: "polygon: decode:"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newServer(t, func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				fmt.Fprint(w, tt.body)
+			})
+			p := polygon.New("K3Y", polygon.WithBaseURL(server.URL), polygon.WithHTTPClient(server.Client()))
+
+			_, err := p.Candles(context.Background(), "AAPL", stockal.IntervalDay, time.Time{}, time.Now())
+			if err == nil || !strings.HasPrefix(err.Error(), tt.want) {
+				t.Errorf("Candles = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestCandlesRateLimited(t *testing.T) {
+	server := newServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "12")
+		w.WriteHeader(http.StatusTooManyRequests)
+		fmt.Fprint(w, `{"status":"ERROR","request_id":"3","error":"You've exceeded the maximum requests per minute."}`)
+	})
+	p := polygon.New("K3Y", polygon.WithBaseURL(server.URL), polygon.WithHTTPClient(server.Client()))
+
+	_, err := p.Candles(context.Background(), "AAPL", stockal.IntervalDay, time.Time{}, time.Now())
+	var rlErr *stockal.RateLimitError
+	if !errors.As(err, &rlErr) || rlErr.RetryAfter != 12*time.Second || !errors.Is(err, stockal.ErrRateLimited) {
+		t.Errorf("Candles = %v, want a rate limit asking for 12s", err)
+	}
+}
+
+func TestCandlesTooManyPages(t *testing.T) {
+	pages := 0
+	var server *httptest.Server
+	server = newServer(t, func(w http.ResponseWriter, r *http.Request) {
+		pages++
+		fmt.Fprintf(w, `{"status":"OK","results":[{"o":1,"c":1,"h":1,"l":1,"v":1,"t":%d}],"next_url":"%s/v2/next?cursor=%d"}`, pages, server.URL, pages)
+	})
+	p := polygon.New("K3Y", polygon.WithBaseURL(server.URL), polygon.WithHTTPClient(server.Client()))
+
+	_, err := p.Candles(context.Background(), "AAPL", stockal.Interval1Min, time.Time{}, time.Now())
+	if err == nil || !strings.Contains(err.Error(), "narrow the range") || pages != 100 {
+		t.Errorf("Candles after %d pages = %v, want it to stop at 100", pages, err)
+	}
+}
@@ -0,0 +1,164 @@
+// Package rbi provides USD/INR reference rates published by the Reserve Bank
+// of India, for converting dollar amounts to rupees in tax computations.
+//
+// The RBI publishes one reference rate per working day. Download the archive
+// for the period you need from the RBI website as CSV and load it:
+//
+//	f, err := os.Open("rbi-reference-rates.csv")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	rates, err := rbi.Load(f)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	rate, err := rates.USDINR(ctx, date)
+//
+// Indian tax rules generally prescribe the SBI TT buying rate; the RBI
+// reference rate is a close, publicly archived substitute. Check which your
+// return requires.
+package rbi
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+)
+
+// Source is the Source set on rates from this provider.
+const Source = "rbi"
+
+// maxGap is how far back USDINR looks for a rate when none was published on
+// the requested date, covering weekends and runs of bank holidays.
+const maxGap = 10 * 24 * time.Hour
+
+// dateLayouts are the date formats accepted in reference rate files.
+var dateLayouts = []string{"02/01/2006", "02-01-2006", "2006-01-02", "02-Jan-2006", "02 Jan 2006", "Jan 2, 2006"}
+
+// indiaLocation returns the Asia/Kolkata time zone, in which rates are
+// published, falling back to a fixed IST offset.
+var indiaLocation = sync.OnceValue(func() *time.Location {
+	loc, err := time.LoadLocation("Asia/Kolkata")
+	if err != nil {
+		return time.FixedZone("IST", 5*60*60+30*60)
+	}
+	return loc
+})
+
+// Rates is a table of daily reference rates. It implements stockal.FxProvider.
+type Rates struct {
+	// days holds the published rates sorted by date
+	days []stockal.FxRate
+}
+
+var _ stockal.FxProvider = (*Rates)(nil)
+
+// Load reads reference rates from CSV. The file needs a header row with a
+// date column and a US dollar column (titled e.g. "Date" and "USD" or "US
+// Dollar"); other columns are ignored, as are rows without a USD rate.
+func Load(r io.Reader) (*Rates, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("rbi: reading header: %w", err)
+	}
+	dateCol, usdCol := -1, -1
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		switch {
+		case dateCol < 0 && strings.Contains(name, "date"):
+			dateCol = i
+		case usdCol < 0 && (strings.Contains(name, "usd") || strings.Contains(name, "dollar")):
+			usdCol = i
+		}
+	}
+	if dateCol < 0 || usdCol < 0 {
+		return nil, errors.New("rbi: header needs a date column and a USD column")
+	}
+
+	rates := &Rates{}
+	for line := 2; ; line++ {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("rbi: %w", err)
+		}
+		if max(dateCol, usdCol) >= len(record) {
+			continue
+		}
+		value := strings.TrimSpace(record[usdCol])
+		if value == "" || value == "-" || strings.EqualFold(value, "NA") {
+			continue
+		}
+		day, err := parseDate(record[dateCol])
+		if err != nil {
+			return nil, fmt.Errorf("rbi: line %d: %w", line, err)
+		}
+		rate, err := strconv.ParseFloat(value, 64)
+		if err != nil || rate <= 0 {
+			return nil, fmt.Errorf("rbi: line %d: invalid rate %q", line, value)
+		}
+		rates.Add(day, rate)
+	}
+	if len(rates.days) == 0 {
+		return nil, errors.New("rbi: no rates found")
+	}
+	return rates, nil
+}
+
+// Add records the reference rate for a day, replacing any rate already
+// recorded for it.
+func (r *Rates) Add(day time.Time, rate float64) {
+	y, m, d := day.In(indiaLocation()).Date()
+	day = time.Date(y, m, d, 0, 0, 0, 0, indiaLocation())
+	i, found := slices.BinarySearchFunc(r.days, day, func(fx stockal.FxRate, t time.Time) int {
+		return fx.Date.Compare(t)
+	})
+	fx := stockal.FxRate{Date: day, Rate: rate, Source: Source}
+	if found {
+		r.days[i] = fx
+		return
+	}
+	r.days = slices.Insert(r.days, i, fx)
+}
+
+// USDINR returns the reference rate for date, or the latest one published in
+// the preceding ten days when there was none that day.
+func (r *Rates) USDINR(ctx context.Context, date time.Time) (stockal.FxRate, error) {
+	y, m, d := date.In(indiaLocation()).Date()
+	day := time.Date(y, m, d, 0, 0, 0, 0, indiaLocation())
+	i, found := slices.BinarySearchFunc(r.days, day, func(fx stockal.FxRate, t time.Time) int {
+		return fx.Date.Compare(t)
+	})
+	if found {
+		return r.days[i], nil
+	}
+	if i > 0 && day.Sub(r.days[i-1].Date) <= maxGap {
+		return r.days[i-1], nil
+	}
+	return stockal.FxRate{}, fmt.Errorf("rbi: %w for %s", stockal.ErrRateUnavailable, day.Format(time.DateOnly))
+}
+
+func parseDate(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	for _, layout := range dateLayouts {
+		if t, err := time.ParseInLocation(layout, s, indiaLocation()); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q", s)
+}
@@ -0,0 +1,120 @@
+package rbi_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+	"github.com/adjaecent/unofficial-stockal-api/providers/rbi"
+)
+
+// reference is an RBI reference rate archive, newest first as downloaded,
+// with a byte order mark and a missing rate.
+const reference = "\ufeffDate,US Dollar,Pound Sterling,Euro,Japanese Yen\n" +
+	"06/05/2024,83.4750,104.6900,89.8000,54.3100\n" +
+	"03/05/2024,83.3895,104.7318,89.3864,54.2300\n" +
+	"02/05/2024,-,104.2500,89.2300,53.8800\n" +
+	"30/04/2024,83.4425,104.4054,89.2622,53.3700\n"
+
+// ist is India Standard Time.
+var ist = time.FixedZone("IST", 5*60*60+30*60)
+
+func TestLoad(t *testing.T) {
+	rates, err := rbi.Load(strings.NewReader(reference))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		date time.Time
+		rate float64
+		day  string
+	}{
+		{name: "published", date: time.Date(2024, 5, 3, 12, 0, 0, 0, ist), rate: 83.3895, day: "2024-05-03"},
+		{name: "weekend", date: time.Date(2024, 5, 5, 12, 0, 0, 0, ist), rate: 83.3895, day: "2024-05-03"},
+		{name: "missing rate", date: time.Date(2024, 5, 2, 12, 0, 0, 0, ist), rate: 83.4425, day: "2024-04-30"},
+		{name: "holiday", date: time.Date(2024, 5, 1, 12, 0, 0, 0, ist), rate: 83.4425, day: "2024-04-30"},
+		// 8pm on 5 May in New York is already 6 May in India
+		{name: "indian date", date: time.Date(2024, 5, 6, 0, 0, 0, 0, time.UTC).Add(-4 * time.Hour), rate: 83.4750, day: "2024-05-06"},
+		{name: "within ten days", date: time.Date(2024, 5, 16, 12, 0, 0, 0, ist), rate: 83.4750, day: "2024-05-06"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rate, err := rates.USDINR(context.Background(), tt.date)
+			if err != nil {
+				t.Fatalf("USDINR(%v): %v", tt.date, err)
+			}
+			if rate.Rate != tt.rate || rate.Date.Format(time.DateOnly) != tt.day || rate.Source != rbi.Source {
+				t.Errorf("USDINR(%v) = %+v, want %v from %s", tt.date, rate, tt.rate, tt.day)
+			}
+		})
+	}
+
+	for _, date := range []time.Time{time.Date(2024, 4, 29, 12, 0, 0, 0, ist), time.Date(2024, 5, 17, 12, 0, 0, 0, ist)} {
+		if rate, err := rates.USDINR(context.Background(), date); !errors.Is(err, stockal.ErrRateUnavailable) {
+			t.Errorf("USDINR(%v) = %+v, %v; want ErrRateUnavailable", date, rate, err)
+		}
+	}
+}
+
+func TestLoadFormats(t *testing.T) {
+	for _, csv := range []string{
+		"Date,USD\n2024-05-03,83.3895\n",
+		"Reference Date , USD/INR\n03-05-2024 , 83.3895\n",
+		"Date,Currency in USD\n03-May-2024,83.3895\n",
+		"Sr,Date,Dollar\n1,03 May 2024,83.3895\n",
+		`Date,USD` + "\n" + `"May 3, 2024",83.3895` + "\n",
+	} {
+		rates, err := rbi.Load(strings.NewReader(csv))
+		if err != nil {
+			t.Errorf("Load(%q): %v", csv, err)
+			continue
+		}
+		if rate, err := rates.USDINR(context.Background(), time.Date(2024, 5, 3, 0, 0, 0, 0, ist)); err != nil || rate.Rate != 83.3895 {
+			t.Errorf("Load(%q): USDINR = %+v, %v; want 83.3895", csv, rate, err)
+		}
+	}
+}
+
+func TestLoadFail(t *testing.T) {
+	tests := []struct {
+		name, csv, want string
+	}{
+		{name: "empty", csv: "", want: "rbi: reading header"},
+		{name: "no usd column", csv: "Date,Euro\n03/05/2024,89.38\n", want: "rbi: header needs a date column and a USD column"},
+		{name: "no rates", csv: "Date,USD\n03/05/2024,NA\n04/05/2024,\n", want: "rbi: no rates found"},
+		{name: "invalid date", csv: "Date,USD\n3rd May,83.3895\n", want: `rbi: line 2: invalid date "3rd May"`},
+		{name: "invalid rate", csv: "Date,USD\n03/05/2024,83.3895\n06/05/2024,eighty\n", want: `rbi: line 3: invalid rate "eighty"`},
+		{name: "negative rate", csv: "Date,USD\n03/05/2024,-83\n", want: `rbi: line 2: invalid rate "-83"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := rbi.Load(strings.NewReader(tt.csv))
+			if err == nil || !strings.HasPrefix(err.Error(), tt.want) {
+				t.Errorf("Load = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestAdd(t *testing.T) {
+	rates := &rbi.Rates{}
+	rates.Add(time.Date(2024, 5, 6, 0, 0, 0, 0, ist), 83.4750)
+	rates.Add(time.Date(2024, 5, 3, 0, 0, 0, 0, ist), 83.3895)
+	// A time late on 2 May in UTC is 3 May in India, and replaces its rate
+	rates.Add(time.Date(2024, 5, 2, 20, 0, 0, 0, time.UTC), 83.40)
+
+	for day, want := range map[int]float64{3: 83.40, 4: 83.40, 6: 83.4750, 7: 83.4750} {
+		rate, err := rates.USDINR(context.Background(), time.Date(2024, 5, day, 12, 0, 0, 0, ist))
+		if err != nil || rate.Rate != want {
+			t.Errorf("USDINR on %d May = %+v, %v; want %v", day, rate, err, want)
+		}
+	}
+	if _, err := (&rbi.Rates{}).USDINR(context.Background(), time.Now()); !errors.Is(err, stockal.ErrRateUnavailable) {
+		t.Errorf("USDINR without rates = %v, want ErrRateUnavailable", err)
+	}
+}
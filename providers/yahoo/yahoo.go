@@ -0,0 +1,190 @@
+// Package yahoo provides quotes from Yahoo Finance, for use as a fallback
+// when Stockal's quote endpoint is down or does not know a symbol:
+//
+//	client := stockal.NewClient(stockal.WithQuoteFallback(yahoo.New()))
+//
+// Yahoo Finance has no official API and its terms restrict commercial use.
+// Quotes may be delayed.
+package yahoo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+)
+
+// Source is the Source set on quotes from this provider.
+const Source = "yahoo"
+
+// BaseURL is the Yahoo Finance API used by default.
+const BaseURL = "https://query1.finance.yahoo.com"
+
+// maxConcurrent caps the simultaneous requests made by one Quotes call.
+const maxConcurrent = 8
+
+// Provider fetches quotes from Yahoo Finance. It implements stockal.QuoteProvider.
+type Provider struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+var _ stockal.QuoteProvider = (*Provider)(nil)
+
+// Option configures a Provider.
+type Option func(*Provider)
+
+// WithHTTPClient sets the HTTP client used for requests.
+func WithHTTPClient(client *http.Client) Option {
+	return func(p *Provider) {
+		p.httpClient = client
+	}
+}
+
+// WithBaseURL sets the API base URL, for tests.
+func WithBaseURL(baseURL string) Option {
+	return func(p *Provider) {
+		p.baseURL = strings.TrimSuffix(baseURL, "/")
+	}
+}
+
+// New creates a Provider.
+func New(options ...Option) *Provider {
+	p := &Provider{baseURL: BaseURL, httpClient: &http.Client{Timeout: 10 * time.Second}}
+	for _, option := range options {
+		option(p)
+	}
+	return p
+}
+
+// Quotes returns the latest quote for each symbol Yahoo knows. Symbols it does
+// not know are omitted; an error is returned only if no quote could be fetched.
+func (p *Provider) Quotes(ctx context.Context, symbols ...string) ([]stockal.Quote, error) {
+	var (
+		mu     sync.Mutex
+		quotes []stockal.Quote
+		errs   []error
+		wg     sync.WaitGroup
+	)
+	sem := make(chan struct{}, maxConcurrent)
+	for _, symbol := range symbols {
+		wg.Go(func() {
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			q, err := p.quote(ctx, symbol)
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err != nil:
+				errs = append(errs, fmt.Errorf("%s: %w", symbol, err))
+			case q != nil:
+				quotes = append(quotes, *q)
+			}
+		})
+	}
+	wg.Wait()
+
+	if len(quotes) == 0 && len(errs) > 0 {
+		return nil, fmt.Errorf("yahoo: %w", errors.Join(errs...))
+	}
+	// Keep the order of the request.
+	ordered := make([]stockal.Quote, 0, len(quotes))
+	for _, symbol := range symbols {
+		for _, q := range quotes {
+			if strings.EqualFold(q.Symbol, symbol) {
+				ordered = append(ordered, q)
+				break
+			}
+		}
+	}
+	return ordered, nil
+}
+
+// chartResponse is the part of the chart API response used for quotes.
+type chartResponse struct {
+	Chart struct {
+		Result []struct {
+			Meta struct {
+				Symbol             string  `json:"symbol"`
+				RegularMarketPrice float64 `json:"regularMarketPrice"`
+				ChartPreviousClose float64 `json:"chartPreviousClose"`
+				PreviousClose      float64 `json:"previousClose"`
+				DayHigh            float64 `json:"regularMarketDayHigh"`
+				DayLow             float64 `json:"regularMarketDayLow"`
+				Volume             int64   `json:"regularMarketVolume"`
+				Time               int64   `json:"regularMarketTime"`
+			} `json:"meta"`
+			Indicators struct {
+				Quote []struct {
+					Open []*float64 `json:"open"`
+				} `json:"quote"`
+			} `json:"indicators"`
+		} `json:"result"`
+		Error *struct {
+			Code        string `json:"code"`
+			Description string `json:"description"`
+		} `json:"error"`
+	} `json:"chart"`
+}
+
+// quote fetches one symbol's quote, returning nil if Yahoo does not know it.
+func (p *Provider) quote(ctx context.Context, symbol string) (*stockal.Quote, error) {
+	endpoint := p.baseURL + "/v8/finance/chart/" + url.PathEscape(strings.ToUpper(symbol)) + "?interval=1d&range=1d"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	// Yahoo rejects requests without a browser-like User-Agent.
+	req.Header.Set("User-Agent", "Mozilla/5.0 (compatible; unofficial-stockal-api)")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+
+	var chart chartResponse
+	if err := json.NewDecoder(resp.Body).Decode(&chart); err != nil {
+		return nil, fmt.Errorf("decode: %w", err)
+	}
+	if chart.Chart.Error != nil {
+		return nil, fmt.Errorf("%s: %s", chart.Chart.Error.Code, chart.Chart.Error.Description)
+	}
+	if len(chart.Chart.Result) == 0 || chart.Chart.Result[0].Meta.RegularMarketPrice == 0 {
+		return nil, nil
+	}
+
+	r := chart.Chart.Result[0]
+	q := &stockal.Quote{
+		Symbol:     r.Meta.Symbol,
+		Price:      r.Meta.RegularMarketPrice,
+		High:       r.Meta.DayHigh,
+		Low:        r.Meta.DayLow,
+		PriorClose: r.Meta.PreviousClose,
+		Volume:     r.Meta.Volume,
+		Timestamp:  r.Meta.Time,
+		Source:     Source,
+	}
+	if q.PriorClose == 0 {
+		q.PriorClose = r.Meta.ChartPreviousClose
+	}
+	if quotes := r.Indicators.Quote; len(quotes) > 0 && len(quotes[0].Open) > 0 && quotes[0].Open[0] != nil {
+		q.Open = *quotes[0].Open[0]
+	}
+	return q, nil
+}
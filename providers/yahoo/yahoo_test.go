@@ -0,0 +1,101 @@
+package yahoo_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/adjaecent/unofficial-stockal-api/providers/yahoo"
+)
+
+// chart is a chart API response for AAPL.
+const chart = `{"chart":{"result":[{"meta":{"symbol":"AAPL","regularMarketPrice":190.5,
+	"chartPreviousClose":187,"regularMarketDayHigh":191,"regularMarketDayLow":188.25,
+	"regularMarketVolume":51234567,"regularMarketTime":1700000000},
+	"indicators":{"quote":[{"open":[188.5]}]}}],"error":null}}`
+
+// response is a fake chart API response: an HTTP status, or 200 with a body.
+type response struct {
+	status int
+	body   string
+}
+
+// newServer returns a fake Yahoo Finance answering chart requests with the
+// response for each symbol, or 404 for symbols it does not have.
+func newServer(t *testing.T, responses map[string]response) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ua := r.Header.Get("User-Agent"); !strings.HasPrefix(ua, "Mozilla/5.0") {
+			t.Errorf("User-Agent %q, want a browser-like one", ua)
+		}
+		symbol, ok := strings.CutPrefix(r.URL.Path, "/v8/finance/chart/")
+		resp, known := responses[symbol]
+		if !ok || !known {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"chart":{"result":null,"error":{"code":"Not Found","description":"No data found, symbol may be delisted"}}}`)
+			return
+		}
+		if resp.status != 0 {
+			w.WriteHeader(resp.status)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, resp.body)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestQuotes(t *testing.T) {
+	server := newServer(t, map[string]response{
+		"AAPL": {body: chart},
+		"VOO":  {body: `{"chart":{"result":[{"meta":{"symbol":"VOO","regularMarketPrice":430,"previousClose":428},"indicators":{"quote":[{"open":[null]}]}}],"error":null}}`},
+		"BRK":  {body: `{"chart":{"result":null,"error":{"code":"Bad Request","description":"Invalid symbol"}}}`},
+		"GONE": {body: `{"chart":{"result":[{"meta":{"symbol":"GONE","regularMarketPrice":0}}],"error":null}}`},
+		"SLOW": {status: http.StatusTooManyRequests},
+	})
+	p := yahoo.New(yahoo.WithBaseURL(server.URL+"/"), yahoo.WithHTTPClient(server.Client()))
+
+	quotes, err := p.Quotes(context.Background(), "voo", "AAPL", "BRK", "GONE", "SLOW", "MISSING")
+	if err != nil {
+		t.Fatalf("Quotes: %v", err)
+	}
+	if len(quotes) != 2 || quotes[0].Symbol != "VOO" || quotes[1].Symbol != "AAPL" {
+		t.Fatalf("quotes %+v, want VOO and AAPL in the order asked", quotes)
+	}
+
+	aapl := quotes[1]
+	if aapl.Price != 190.5 || aapl.Open != 188.5 || aapl.High != 191 || aapl.Low != 188.25 ||
+		aapl.PriorClose != 187 || aapl.Volume != 51234567 || aapl.Timestamp != 1700000000 || aapl.Source != yahoo.Source {
+		t.Errorf("AAPL quote %+v, want the chart's figures", aapl)
+	}
+	if voo := quotes[0]; voo.PriorClose != 428 || voo.Open != 0 {
+		t.Errorf("VOO quote %+v, want the previous close and no open", voo)
+	}
+}
+
+func TestQuotesFail(t *testing.T) {
+	server := newServer(t, map[string]response{
+		"AAPL": {status: http.StatusTooManyRequests},
+		"BRK":  {body: `{"chart":{"result":null,"error":{"code":"Bad Request","description":"Invalid symbol"}}}`},
+		"VOO":  {body: "not json"},
+	})
+	p := yahoo.New(yahoo.WithBaseURL(server.URL), yahoo.WithHTTPClient(server.Client()))
+
+	_, err := p.Quotes(context.Background(), "AAPL", "BRK", "VOO")
+	if err == nil {
+		t.Fatal("Quotes succeeded without any quote")
+	}
+	for _, want := range []string{"AAPL: HTTP 429", "BRK: Bad Request: Invalid symbol", "VOO: decode:"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Quotes error %q, want it to mention %q", err, want)
+		}
+	}
+
+	if quotes, err := p.Quotes(context.Background(), "MISSING"); err != nil || len(quotes) != 0 {
+		t.Errorf("Quotes of an unknown symbol = %+v, %v; want none without an error", quotes, err)
+	}
+}
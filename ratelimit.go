@@ -0,0 +1,89 @@
+package stockal
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// DefaultRateLimitWait is the longest the client waits to retry a
+// rate-limited request unless WithRateLimitWait says otherwise.
+const DefaultRateLimitWait = 30 * time.Second
+
+// rateLimitFallbackWait is how long the client waits to retry a
+// rate-limited request when the server does not say.
+const rateLimitFallbackWait = time.Second
+
+// RateLimitEvent describes a request the server rejected with HTTP 429.
+type RateLimitEvent struct {
+	// Method is the request's HTTP method, e.g. "GET"
+	Method string
+	// Endpoint is the request's path and query, e.g. "/v2/orders"
+	Endpoint string
+	// Err is the rate limit as the server reported it
+	Err *RateLimitError
+	// Wait is how long the client waits before retrying the request, or zero
+	// if it gives up: the request was retried as often as allowed, or the
+	// server asked for a longer wait than allowed
+	Wait time.Duration
+}
+
+// WithRateLimitWait bounds how long the client waits to retry a request the
+// server rejected with HTTP 429 Too Many Requests. Each request is retried at
+// most once, or as many times as the retry policy allows (see WithRetry),
+// after the server's Retry-After or rate limit reset; if the server asks for
+// longer than max, or the call's deadline would pass first, the call fails
+// with a *RateLimitError right away. Zero disables retrying. The default is
+// DefaultRateLimitWait.
+func WithRateLimitWait(max time.Duration) ClientOption {
+	return func(c *clientConfig) {
+		c.rateLimitWait = max
+	}
+}
+
+// WithRateLimitHook sets a function called for every response with HTTP
+// status 429, such as to log it or count it in metrics. It is called before
+// the client waits to retry.
+func WithRateLimitHook(hook func(RateLimitEvent)) ClientOption {
+	return func(c *clientConfig) {
+		c.rateLimitHook = hook
+	}
+}
+
+// rateLimited reports whether to retry a request that got resp, waiting as
+// the server asks first, unless last is set. It closes the response body if it
+// retries.
+func (c *Client) rateLimited(ctx context.Context, req *http.Request, resp *http.Response, last bool) bool {
+	if resp.StatusCode != http.StatusTooManyRequests {
+		return false
+	}
+	event := RateLimitEvent{
+		Method:   req.Method,
+		Endpoint: req.URL.RequestURI(),
+		Err:      parseRateLimit(resp.Header, time.Now()),
+	}
+	wait := event.Err.RetryAfter
+	if wait <= 0 {
+		wait = rateLimitFallbackWait
+	}
+	deadline, hasDeadline := ctx.Deadline()
+	if !last && wait <= c.rateLimitWait && (!hasDeadline || time.Now().Add(wait).Before(deadline)) {
+		event.Wait = wait
+	}
+	if c.rateLimitHook != nil {
+		c.rateLimitHook(event)
+	}
+	if event.Wait == 0 {
+		return false
+	}
+
+	resp.Body.Close()
+	timer := time.NewTimer(event.Wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return true
+	}
+}
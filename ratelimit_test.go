@@ -0,0 +1,156 @@
+package stockal_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+)
+
+func TestRateLimitRetry(t *testing.T) {
+	tests := []struct {
+		name       string
+		retryAfter string
+		limited    int
+		opts       []stockal.ClientOption
+		requests   int
+		waits      []time.Duration
+		fails      bool
+	}{
+		{"retried once", "1", 1, nil, 2, []time.Duration{time.Second}, false},
+		{"still limited", "1", 2, nil, 2, []time.Duration{time.Second, 0}, true},
+		{"wait too long", "120", 1, nil, 1, []time.Duration{0}, true},
+		{"disabled", "1", 1, []stockal.ClientOption{stockal.WithRateLimitWait(0)}, 1, []time.Duration{0}, true},
+		{"past deadline", "1", 1, []stockal.ClientOption{stockal.WithDefaultDeadline(500 * time.Millisecond)}, 1, []time.Duration{0}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests := 0
+			var waits []time.Duration
+			hook := stockal.WithRateLimitHook(func(e stockal.RateLimitEvent) {
+				if e.Method != "GET" || e.Endpoint != "/v2/orders" || e.Err.RetryAfter == 0 {
+					t.Errorf("got event %+v", e)
+				}
+				waits = append(waits, e.Wait)
+			})
+			client := newHandlerClient(t, func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				w.Header().Set("Content-Type", "application/json")
+				if requests <= tt.limited {
+					w.Header().Set("Retry-After", tt.retryAfter)
+					w.WriteHeader(http.StatusTooManyRequests)
+					w.Write([]byte(`{"code":429,"message":"Too many requests"}`))
+					return
+				}
+				w.Write([]byte(`{"code":200,"message":"Success","data":{"orders":[],"totalRecords":0}}`))
+			}, append([]stockal.ClientOption{hook}, tt.opts...)...)
+
+			_, err := client.Orders().List(context.Background())
+			var rateLimit *stockal.RateLimitError
+			if tt.fails != errors.As(err, &rateLimit) {
+				t.Errorf("Orders.List error = %v, want a RateLimitError: %v", err, tt.fails)
+			}
+			if requests != tt.requests {
+				t.Errorf("server got %d requests, want %d", requests, tt.requests)
+			}
+			if len(waits) != len(tt.waits) {
+				t.Fatalf("hook saw waits %v, want %v", waits, tt.waits)
+			}
+			for i := range waits {
+				if waits[i] != tt.waits[i] {
+					t.Errorf("hook saw waits %v, want %v", waits, tt.waits)
+				}
+			}
+		})
+	}
+}
+
+func TestRateLimitRetryResendsBody(t *testing.T) {
+	var bodies []string
+	client := newHandlerClient(t, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		w.Header().Set("Content-Type", "application/json")
+		if len(bodies) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte(`{"code":200,"message":"Success","data":{"orderID":"o1"}}`))
+	})
+
+	order := stockal.OrderRequest{Symbol: "VOO", Side: stockal.OrderSideBuy, Type: stockal.OrderTypeMarket, Amount: 100}
+	if _, err := client.Orders().Place(context.Background(), order); err != nil {
+		t.Fatalf("Orders.Place: %v", err)
+	}
+	if len(bodies) != 2 || bodies[0] == "" || bodies[0] != bodies[1] {
+		t.Errorf("server got bodies %q, want the same body twice", bodies)
+	}
+}
+
+func TestRateLimitError(t *testing.T) {
+	now := time.Now()
+	future := now.Add(time.Minute).UTC().Format(http.TimeFormat)
+	past := now.Add(-time.Minute).UTC().Format(http.TimeFormat)
+	reset := strconv.FormatInt(now.Add(45*time.Second).Unix(), 10)
+
+	tests := []struct {
+		name       string
+		header     map[string]string
+		retryAfter time.Duration
+		limit      int
+		remaining  int
+		reset      bool
+	}{
+		{name: "none", limit: -1, remaining: -1},
+		{
+			name:       "seconds",
+			header:     map[string]string{"Retry-After": "30", "X-RateLimit-Limit": "100", "X-RateLimit-Remaining": "0"},
+			retryAfter: 30 * time.Second, limit: 100, remaining: 0,
+		},
+		{name: "date", header: map[string]string{"Retry-After": future}, retryAfter: time.Minute, limit: -1, remaining: -1},
+		{name: "past date", header: map[string]string{"Retry-After": past}, limit: -1, remaining: -1},
+		{name: "invalid", header: map[string]string{"Retry-After": "soon", "X-RateLimit-Limit": "many"}, limit: -1, remaining: -1},
+		{name: "negative", header: map[string]string{"Retry-After": "-5"}, limit: -1, remaining: -1},
+		{name: "reset", header: map[string]string{"X-RateLimit-Reset": reset}, retryAfter: 45 * time.Second, limit: -1, remaining: -1, reset: true},
+		{
+			name:       "retry after and reset",
+			header:     map[string]string{"Retry-After": "10", "X-RateLimit-Reset": reset},
+			retryAfter: 10 * time.Second, limit: -1, remaining: -1, reset: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newHandlerClient(t, func(w http.ResponseWriter, r *http.Request) {
+				for k, v := range tt.header {
+					w.Header().Set(k, v)
+				}
+				w.WriteHeader(http.StatusTooManyRequests)
+			}, stockal.WithRateLimitWait(0))
+
+			_, err := client.Orders().List(context.Background())
+			var rateLimit *stockal.RateLimitError
+			if !errors.As(err, &rateLimit) || !errors.Is(err, stockal.ErrRateLimited) {
+				t.Fatalf("Orders.List error = %v, want a RateLimitError", err)
+			}
+			// Times in headers have a resolution of a second
+			if d := rateLimit.RetryAfter; d > tt.retryAfter || d < tt.retryAfter-2*time.Second || (tt.retryAfter == 0) != (d == 0) {
+				t.Errorf("RetryAfter = %s, want %s", d, tt.retryAfter)
+			}
+			if rateLimit.Limit != tt.limit || rateLimit.Remaining != tt.remaining {
+				t.Errorf("Limit, Remaining = %d, %d; want %d, %d", rateLimit.Limit, rateLimit.Remaining, tt.limit, tt.remaining)
+			}
+			if tt.reset != !rateLimit.Reset.IsZero() || tt.reset && strconv.FormatInt(rateLimit.Reset.Unix(), 10) != reset {
+				t.Errorf("Reset = %v, want %v at %s", rateLimit.Reset, tt.reset, reset)
+			}
+			if tt.retryAfter == 0 && rateLimit.Error() != "rate limited" {
+				t.Errorf("Error() = %q without a wait", rateLimit.Error())
+			}
+		})
+	}
+}
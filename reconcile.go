@@ -0,0 +1,149 @@
+package stockal
+
+import (
+	"fmt"
+	"math"
+)
+
+// DefaultTolerance is the largest difference, in dollars, Reconcile accepts
+// between a reported and a computed amount unless WithTolerance says
+// otherwise: one cent, for rounding.
+const DefaultTolerance = 0.01
+
+// Discrepancy is a reported amount that differs from the amount computed from
+// the data it summarises by more than the tolerance.
+type Discrepancy struct {
+	// Check names the amount, e.g. "etf investment amount"
+	Check string `json:"check"`
+	// Reported is the amount as the API reported it
+	Reported float64 `json:"reported"`
+	// Computed is the amount computed from the details
+	Computed float64 `json:"computed"`
+}
+
+// Difference returns how far the reported amount is above the computed one.
+func (d Discrepancy) Difference() float64 {
+	return d.Reported - d.Computed
+}
+
+func (d Discrepancy) String() string {
+	return fmt.Sprintf("%s: reported %.2f, computed %.2f (%+.2f)", d.Check, d.Reported, d.Computed, d.Difference())
+}
+
+// ReconcileOption configures Reconcile.
+type ReconcileOption func(*reconcileConfig)
+
+type reconcileConfig struct {
+	absolute float64
+	relative float64
+}
+
+// WithTolerance sets the largest difference, in dollars, Reconcile accepts
+// between a reported and a computed amount. The default is DefaultTolerance.
+func WithTolerance(epsilon float64) ReconcileOption {
+	return func(c *reconcileConfig) {
+		c.absolute = epsilon
+	}
+}
+
+// WithRelativeTolerance makes Reconcile accept differences up to fraction of
+// the reported amount as well, e.g. 0.005 for half a percent. Current values
+// need it when prices move between the summary and portfolio requests.
+func WithRelativeTolerance(fraction float64) ReconcileOption {
+	return func(c *reconcileConfig) {
+		c.relative = fraction
+	}
+}
+
+// within reports whether reported and computed agree within the tolerance.
+func (c reconcileConfig) within(reported, computed float64) bool {
+	return math.Abs(reported-computed) <= max(c.absolute, c.relative*math.Abs(reported))
+}
+
+// Reconcile cross-checks the portfolio summary against the holdings it
+// summarises, as the API's totals sometimes drift from its details: the
+// investment amount and current value of each category against the sums of
+// its holdings, and the totals against the sums of the categories. It returns
+// the amounts that disagree by more than the tolerance, or nil.
+//
+// Holdings must be the whole portfolio, not a page or a filtered part. The
+// cash balance is checked against the transactions by ReconcileCash.
+//
+// Example:
+//
+//	for _, d := range stockal.Reconcile(summary.Data, portfolio.Data.Holdings, stockal.WithRelativeTolerance(0.005)) {
+//		log.Printf("portfolio data disagrees: %s", d)
+//	}
+func Reconcile(summary AccountSummaryData, holdings []Holding, opts ...ReconcileOption) []Discrepancy {
+	cfg := reconcileConfig{absolute: DefaultTolerance}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	var computed [3]Portfolio
+	categories := []Category{CategoryStock, CategoryStack, CategoryETF}
+	for _, h := range holdings {
+		for i, category := range categories {
+			if h.Category == category {
+				computed[i].InvestmentAmount += h.TotalInvestment
+				computed[i].CurrentValue += h.CurrentValue()
+			}
+		}
+	}
+
+	var discrepancies []Discrepancy
+	check := func(name string, reported, computed float64) {
+		if !cfg.within(reported, computed) {
+			discrepancies = append(discrepancies, Discrepancy{Check: name, Reported: reported, Computed: computed})
+		}
+	}
+	ps := summary.PortfolioSummary
+	reported := [3]Portfolio{ps.StockPortfolio, ps.StackPortfolio, ps.ETFPortfolio}
+	for i, category := range categories {
+		check(string(category)+" investment amount", reported[i].InvestmentAmount, computed[i].InvestmentAmount)
+		check(string(category)+" current value", reported[i].CurrentValue, computed[i].CurrentValue)
+	}
+	check("total investment amount", ps.TotalInvestmentAmount,
+		reported[0].InvestmentAmount+reported[1].InvestmentAmount+reported[2].InvestmentAmount)
+	check("total current value", ps.TotalCurrentValue,
+		reported[0].CurrentValue+reported[1].CurrentValue+reported[2].CurrentValue)
+	return discrepancies
+}
+
+// ReconcileCash checks the cash balance against the account's transactions:
+// the balance should be the sum of their cash flows. It returns the
+// discrepancy if they disagree by more than the tolerance, or nil.
+//
+// Transactions must be the account's whole history, as AllTransactions reads
+// it, not a page or a filtered part.
+//
+// Example:
+//
+//	transactions, err := stockal.AllTransactions(ctx, client, stockal.TransactionOptions{})
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	for _, d := range stockal.ReconcileCash(summary.Data.AccountSummary.CashBalance, transactions) {
+//		log.Printf("cash balance disagrees with the ledger: %s", d)
+//	}
+func ReconcileCash(balance float64, transactions []Transaction, opts ...ReconcileOption) []Discrepancy {
+	cfg := reconcileConfig{absolute: DefaultTolerance}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	var computed float64
+	for _, t := range transactions {
+		computed += t.CashFlow()
+	}
+	if cfg.within(balance, computed) {
+		return nil
+	}
+	return []Discrepancy{{Check: "cash balance", Reported: balance, Computed: computed}}
+}
+
+// Reconcile cross-checks the snapshot's summary against its holdings, as the
+// Reconcile function does.
+func (s *Snapshot) Reconcile(opts ...ReconcileOption) []Discrepancy {
+	return Reconcile(s.Summary, s.Holdings, opts...)
+}
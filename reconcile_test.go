@@ -0,0 +1,73 @@
+package stockal_test
+
+import (
+	"testing"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+)
+
+func TestReconcile(t *testing.T) {
+	holdings := []stockal.Holding{
+		{Symbol: "AAPL", Category: stockal.CategoryStock, TotalUnit: 2, Price: 150, TotalInvestment: 280},
+		{Symbol: "MSFT", Category: stockal.CategoryStock, TotalUnit: 1, Price: 400, TotalInvestment: 350},
+		{Symbol: "VOO", Category: stockal.CategoryETF, TotalUnit: 1, Price: 500, TotalInvestment: 450},
+	}
+	summary := func(stockValue float64) stockal.AccountSummaryData {
+		return stockal.AccountSummaryData{PortfolioSummary: stockal.PortfolioSummary{
+			StockPortfolio:        stockal.Portfolio{CurrentValue: stockValue, InvestmentAmount: 630},
+			ETFPortfolio:          stockal.Portfolio{CurrentValue: 500, InvestmentAmount: 450},
+			TotalCurrentValue:     stockValue + 500,
+			TotalInvestmentAmount: 1080,
+		}}
+	}
+
+	tests := []struct {
+		name   string
+		value  float64
+		opts   []stockal.ReconcileOption
+		checks []string
+	}{
+		{"consistent", 700, nil, nil},
+		{"rounding", 700.004, nil, nil},
+		{"drift", 703, nil, []string{"stock current value"}},
+		{"drift within tolerance", 703, []stockal.ReconcileOption{stockal.WithTolerance(5)}, nil},
+		{"drift within relative tolerance", 703, []stockal.ReconcileOption{stockal.WithRelativeTolerance(0.005)}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var checks []string
+			for _, d := range stockal.Reconcile(summary(tt.value), holdings, tt.opts...) {
+				checks = append(checks, d.Check)
+			}
+			if len(checks) != len(tt.checks) || len(checks) > 0 && checks[0] != tt.checks[0] {
+				t.Errorf("Reconcile found %q, want %q", checks, tt.checks)
+			}
+		})
+	}
+
+	// A total that disagrees with its categories
+	bad := summary(700)
+	bad.PortfolioSummary.TotalInvestmentAmount = 1000
+	snapshot := &stockal.Snapshot{Summary: bad, Holdings: holdings}
+	discrepancies := snapshot.Reconcile()
+	if len(discrepancies) != 1 || discrepancies[0].Check != "total investment amount" || discrepancies[0].Difference() != -80 {
+		t.Errorf("Reconcile() = %v", discrepancies)
+	}
+}
+
+func TestReconcileCash(t *testing.T) {
+	transactions := []stockal.Transaction{
+		{Type: stockal.TransactionDeposit, Amount: 1000},
+		{Type: stockal.TransactionBuy, Amount: -300},
+		// Unsigned amounts are signed by type
+		{Type: stockal.TransactionFee, Amount: 1.5},
+		{Type: stockal.TransactionDividend, Amount: 2.25},
+	}
+	if d := stockal.ReconcileCash(700.75, transactions); d != nil {
+		t.Errorf("ReconcileCash = %v, want none", d)
+	}
+	d := stockal.ReconcileCash(710, transactions)
+	if len(d) != 1 || d[0].Check != "cash balance" || d[0].Computed != 700.75 {
+		t.Errorf("ReconcileCash = %v, want the cash balance 9.25 above the ledger", d)
+	}
+}
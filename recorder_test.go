@@ -0,0 +1,69 @@
+package stockal_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+	"github.com/adjaecent/unofficial-stockal-api/stockaltest"
+)
+
+func TestRecorder(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "cassettes", "portfolio.json")
+
+	srv := stockaltest.NewServer()
+	rec, err := stockaltest.NewRecorder(path, stockaltest.Record)
+	if err != nil {
+		t.Fatalf("NewRecorder: %v", err)
+	}
+	rec.Secrets = []string{"alice@example.com"}
+	client := srv.NewClient(stockal.WithHTTPClient(rec.HTTPClient()))
+	if _, err := client.Auth().Login(ctx, "alice@example.com", "hunter22"); err != nil {
+		t.Fatalf("Login while recording: %v", err)
+	}
+	recorded, err := client.Portfolio().Detail(ctx)
+	if err != nil {
+		t.Fatalf("GetPortfolioDetail while recording: %v", err)
+	}
+	if err := rec.Save(); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	srv.Close()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading cassette: %v", err)
+	}
+	session := stockaltest.Session().Data
+	for _, secret := range []string{"alice@example.com", "hunter22", session.AccessToken, session.RefreshToken} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("cassette reveals %q:\n%s", secret, data)
+		}
+	}
+
+	rec, err = stockaltest.NewRecorder(path, stockaltest.Replay)
+	if err != nil {
+		t.Fatalf("NewRecorder to replay: %v", err)
+	}
+	rec.Secrets = []string{"alice@example.com"}
+	client = stockal.NewClient(stockal.WithBaseURL("https://api.invalid"), stockal.WithHTTPClient(rec.HTTPClient()))
+	if _, err := client.Auth().Login(ctx, "alice@example.com", "hunter22"); err != nil {
+		t.Fatalf("Login while replaying: %v", err)
+	}
+	replayed, err := client.Portfolio().Detail(ctx)
+	if err != nil {
+		t.Fatalf("GetPortfolioDetail while replaying: %v", err)
+	}
+	if !reflect.DeepEqual(replayed.Data.Holdings, recorded.Data.Holdings) {
+		t.Errorf("replayed holdings %+v, recorded %+v", replayed.Data.Holdings, recorded.Data.Holdings)
+	}
+	if _, err := client.Portfolio().Detail(ctx); !errors.Is(err, stockaltest.ErrNotRecorded) {
+		t.Errorf("GetPortfolioDetail replayed twice: error = %v, want ErrNotRecorded", err)
+	}
+}
@@ -0,0 +1,137 @@
+package stockal
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Redacted replaces the credentials and tokens Redact removes.
+const Redacted = "[REDACTED]"
+
+// sensitiveKey matches the names of fields, headers and query parameters
+// that hold credentials, e.g. password, accessToken, refresh_token and
+// X-Api-Key.
+const sensitiveKey = `(?i:[a-z_\-]*(?:password|passwd|secret|token|apikey|api_key|api-key|authorization|signature|credentials?)[a-z_\-]*|otp)`
+
+var (
+	// jsonField matches "key": "value" pairs with a sensitive key.
+	jsonField = regexp.MustCompile(`("` + sensitiveKey + `"\s*:\s*)"(?:[^"\\]|\\.)*"`)
+	// keyValue matches key=value and key: value pairs with a sensitive key,
+	// as in query strings, form bodies and headers.
+	keyValue = regexp.MustCompile(`\b(` + sensitiveKey + `\s*[=:]\s*)([^\s&",;}]+)`)
+	// bearer matches authorization schemes followed by a credential.
+	bearer = regexp.MustCompile(`(?i)\b(bearer|basic)\s+[A-Za-z0-9\-._~+/]+=*`)
+	// jwt matches JSON Web Tokens, which Stockal uses as access tokens.
+	jwt = regexp.MustCompile(`\beyJ[A-Za-z0-9_\-]+\.[A-Za-z0-9_\-]+\.[A-Za-z0-9_\-]*`)
+	// urlPattern matches URLs in free text.
+	urlPattern = regexp.MustCompile(`https?://[^\s"'<>]+`)
+	// queryKey matches sensitive query parameter names.
+	queryKey = regexp.MustCompile(`^` + sensitiveKey + `$`)
+)
+
+// Redact removes credentials and tokens from s, such as an error message or a
+// response body: the given secrets wherever they appear, values of fields and
+// parameters named like passwords and tokens, authorization credentials,
+// JSON Web Tokens, and the passwords and token-like path segments of URLs.
+func Redact(s string, secrets ...string) string {
+	for _, secret := range secrets {
+		// Short values would match too much innocent text
+		if len(secret) >= 4 {
+			s = strings.ReplaceAll(s, secret, Redacted)
+		}
+	}
+	s = urlPattern.ReplaceAllStringFunc(s, RedactURL)
+	s = jsonField.ReplaceAllString(s, `${1}"`+Redacted+`"`)
+	s = bearer.ReplaceAllString(s, "${1} "+Redacted)
+	s = jwt.ReplaceAllString(s, Redacted)
+	return keyValue.ReplaceAllStringFunc(s, func(m string) string {
+		sub := keyValue.FindStringSubmatch(m)
+		if value := sub[2]; value == Redacted || bearer.MatchString(value+" x") {
+			return m
+		}
+		return sub[1] + Redacted
+	})
+}
+
+// RedactURL returns rawURL without its password, the values of sensitive
+// query parameters, and path segments that look like tokens, such as those
+// of Telegram bot, Slack webhook and Discord webhook URLs.
+func RedactURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return rawURL
+	}
+	if _, ok := u.User.Password(); ok {
+		u.User = url.UserPassword(u.User.Username(), Redacted)
+	}
+	if u.RawQuery != "" {
+		query := u.Query()
+		for key := range query {
+			if queryKey.MatchString(key) {
+				query.Set(key, Redacted)
+			}
+		}
+		u.RawQuery = query.Encode()
+	}
+	segments := strings.Split(u.Path, "/")
+	for i, segment := range segments {
+		if tokenLike(segment) {
+			segments[i] = Redacted
+			u.RawPath = ""
+		}
+	}
+	u.Path = strings.Join(segments, "/")
+
+	// Keep the placeholder readable rather than escaped
+	s := u.String()
+	s = strings.ReplaceAll(s, url.QueryEscape(Redacted), Redacted)
+	return strings.ReplaceAll(s, url.PathEscape(Redacted), Redacted)
+}
+
+// tokenLike reports whether a path segment looks like a credential: a JSON
+// Web Token, or a long run of token characters mixing upper case, lower case
+// and digits. Hex identifiers such as database IDs have a single case, and are
+// left alone.
+func tokenLike(segment string) bool {
+	if segment != "" && jwt.FindString(segment) == segment {
+		return true
+	}
+	if len(segment) < 20 || strings.ContainsFunc(segment, func(r rune) bool { return !tokenChar(r) }) {
+		return false
+	}
+	upper := strings.ContainsFunc(segment, func(r rune) bool { return r >= 'A' && r <= 'Z' })
+	lower := strings.ContainsFunc(segment, func(r rune) bool { return r >= 'a' && r <= 'z' })
+	digit := strings.ContainsFunc(segment, func(r rune) bool { return r >= '0' && r <= '9' })
+	return upper && lower && digit
+}
+
+// tokenChar reports whether r may appear in a token: the base64 and base64url
+// alphabets, padding, and the separators of Telegram bot tokens.
+func tokenChar(r rune) bool {
+	return r >= 'A' && r <= 'Z' || r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || strings.ContainsRune("+/=-_.:~", r)
+}
+
+// RedactError returns err with Redact applied to its message. The result
+// still matches what err matches with errors.Is and errors.As, and is err
+// itself if there was nothing to redact.
+func RedactError(err error, secrets ...string) error {
+	if err == nil {
+		return nil
+	}
+	msg := Redact(err.Error(), secrets...)
+	if msg == err.Error() {
+		return err
+	}
+	return &redactedError{msg: msg, err: err}
+}
+
+// redactedError is an error whose message has been redacted.
+type redactedError struct {
+	msg string
+	err error
+}
+
+func (e *redactedError) Error() string { return e.msg }
+
+func (e *redactedError) Unwrap() error { return e.err }
@@ -0,0 +1,259 @@
+// Package registry lets extension packages add Stockal endpoints, notifiers,
+// history stores and stockalctl commands without changes to this module.
+//
+// An extension registers what it provides from an init function, in the
+// manner of database/sql drivers:
+//
+//	package ntfy
+//
+//	func init() {
+//		registry.RegisterNotifier("ntfy", func(ctx context.Context, cfg registry.NotifierConfig) (notify.Notifier, error) {
+//			token, err := cfg.Secret(cfg.Settings["token_env"])
+//			if err != nil {
+//				return nil, err
+//			}
+//			return New(cfg.Settings["topic"], token), nil
+//		})
+//	}
+//
+// and is linked in with a blank import, such as those in the plugins.go files
+// of cmd/stockalctl and cmd/stockal-snapshotd:
+//
+//	import _ "example.com/stockal-ntfy"
+//
+// Registering the same name twice panics, so conflicts between extensions
+// show up when the program starts.
+package registry
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/spf13/cobra"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+	"github.com/adjaecent/unofficial-stockal-api/history"
+	"github.com/adjaecent/unofficial-stockal-api/notify"
+)
+
+var (
+	mu        sync.RWMutex
+	endpoints = map[string]EndpointGroup{}
+	notifiers = map[string]NotifierFactory{}
+	stores    = map[string]StoreOpener{}
+	commands  = map[string]CommandFactory{}
+)
+
+// register adds v to m under name, panicking on a missing or duplicate name.
+func register[V any](m map[string]V, kind, name string, v V) {
+	mu.Lock()
+	defer mu.Unlock()
+	if name == "" {
+		panic("registry: " + kind + " registered without a name")
+	}
+	if _, dup := m[name]; dup {
+		panic(fmt.Sprintf("registry: %s %q registered twice", kind, name))
+	}
+	m[name] = v
+}
+
+// names returns the keys of m in order.
+func names[V any](m map[string]V) []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	return slices.Sorted(maps.Keys(m))
+}
+
+// Endpoint is a Stockal API endpoint the client has no method for.
+type Endpoint struct {
+	// Name identifies the endpoint within its group, e.g. "list"
+	Name string
+	// Description says what the endpoint returns
+	Description string
+	// Method is the HTTP method, e.g. "GET"
+	Method string
+	// Path is relative to the API base URL and may contain {param}
+	// placeholders, e.g. "/v2/news/{symbol}"
+	Path string
+}
+
+// Call calls the endpoint with c, such as the client stockal.NewClient
+// returns, and decodes the response into result. params fill the path's
+// placeholders; the others are sent as the query string of GET and DELETE
+// requests and as a JSON object otherwise.
+func (e Endpoint) Call(ctx context.Context, c stockal.Caller, params map[string]string, result any) error {
+	rest := maps.Clone(params)
+	path := e.Path
+	for {
+		start := strings.Index(path, "{")
+		end := strings.Index(path, "}")
+		if start < 0 || end < start {
+			break
+		}
+		name := path[start+1 : end]
+		value, ok := rest[name]
+		if !ok {
+			return fmt.Errorf("%s: missing parameter %q", e.Name, name)
+		}
+		delete(rest, name)
+		path = path[:start] + url.PathEscape(value) + path[end+1:]
+	}
+
+	var payload any
+	if len(rest) > 0 {
+		if e.Method == "GET" || e.Method == "DELETE" {
+			query := url.Values{}
+			for k, v := range rest {
+				query.Set(k, v)
+			}
+			path += "?" + query.Encode()
+		} else {
+			payload = rest
+		}
+	}
+	return c.Call(ctx, e.Method, path, payload, result)
+}
+
+// EndpointGroup is a set of related endpoints, such as those of one Stockal
+// feature.
+type EndpointGroup struct {
+	// Name identifies the group, e.g. "news"
+	Name string
+	// Description says what the endpoints are for
+	Description string
+	// Endpoints are the group's endpoints
+	Endpoints []Endpoint
+}
+
+// Endpoint returns the group's endpoint called name.
+func (g EndpointGroup) Endpoint(name string) (Endpoint, bool) {
+	for _, e := range g.Endpoints {
+		if e.Name == name {
+			return e, true
+		}
+	}
+	return Endpoint{}, false
+}
+
+// RegisterEndpoints makes a group of endpoints available, e.g. to
+// "stockalctl call".
+func RegisterEndpoints(g EndpointGroup) {
+	register(endpoints, "endpoint group", g.Name, g)
+}
+
+// Endpoints returns the registered endpoint group called name.
+func Endpoints(name string) (EndpointGroup, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	g, ok := endpoints[name]
+	return g, ok
+}
+
+// EndpointGroups returns the registered endpoint groups, ordered by name.
+func EndpointGroups() []EndpointGroup {
+	groups := []EndpointGroup{}
+	for _, name := range names(endpoints) {
+		g, _ := Endpoints(name)
+		groups = append(groups, g)
+	}
+	return groups
+}
+
+// NotifierConfig configures one notifier.
+type NotifierConfig struct {
+	// Settings are the notifier's settings, such as the keys of a stockalctl
+	// profile's notifiers entry besides type
+	Settings map[string]string
+	// Secret returns the secret name, or "" if it is not set. Settings
+	// should name secrets rather than hold them.
+	Secret func(name string) (string, error)
+}
+
+// NotifierFactory creates a notifier from its configuration.
+type NotifierFactory func(ctx context.Context, cfg NotifierConfig) (notify.Notifier, error)
+
+// RegisterNotifier makes a kind of notifier available, e.g. to stockalctl
+// profiles' notifiers section.
+func RegisterNotifier(kind string, f NotifierFactory) {
+	register(notifiers, "notifier", kind, f)
+}
+
+// Notifier returns the factory for the registered kind of notifier.
+func Notifier(kind string) (NotifierFactory, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	f, ok := notifiers[kind]
+	return f, ok
+}
+
+// NotifierKinds returns the registered kinds of notifier in order.
+func NotifierKinds() []string {
+	return names(notifiers)
+}
+
+// StoreOpener opens the history store a URL names.
+type StoreOpener func(ctx context.Context, u *url.URL) (history.Store, error)
+
+// RegisterStore makes a history store available for URLs with the given
+// scheme, e.g. to "stockal-snapshotd --store".
+func RegisterStore(scheme string, open StoreOpener) {
+	register(stores, "store", scheme, open)
+}
+
+// OpenStore opens the history store rawURL names, using the opener
+// registered for its scheme.
+func OpenStore(ctx context.Context, rawURL string) (history.Store, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("registry: invalid store URL: %w", err)
+	}
+	mu.RLock()
+	open, ok := stores[u.Scheme]
+	mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("registry: no store registered for %q (have %s)", u.Scheme, list(StoreSchemes()))
+	}
+	return open(ctx, u)
+}
+
+// StoreSchemes returns the URL schemes of the registered stores in order.
+func StoreSchemes() []string {
+	return names(stores)
+}
+
+// Session returns the client of the account a command runs for, logged in.
+type Session func(cmd *cobra.Command) (stockal.StockalClient, error)
+
+// CommandFactory creates a command. session gives its RunE access to the
+// account selected by the global flags and profile.
+type CommandFactory func(session Session) *cobra.Command
+
+// RegisterCommand adds a stockalctl subcommand. name must be the command's
+// name, which may not clash with a built-in command.
+func RegisterCommand(name string, f CommandFactory) {
+	register(commands, "command", name, f)
+}
+
+// Commands creates the registered commands, ordered by name.
+func Commands(session Session) []*cobra.Command {
+	var cmds []*cobra.Command
+	for _, name := range names(commands) {
+		mu.RLock()
+		f := commands[name]
+		mu.RUnlock()
+		cmds = append(cmds, f(session))
+	}
+	return cmds
+}
+
+func list(names []string) string {
+	if len(names) == 0 {
+		return "none"
+	}
+	return strings.Join(names, ", ")
+}
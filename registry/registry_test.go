@@ -0,0 +1,193 @@
+package registry_test
+
+import (
+	"context"
+	"net/url"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+	"github.com/adjaecent/unofficial-stockal-api/events"
+	"github.com/adjaecent/unofficial-stockal-api/history"
+	"github.com/adjaecent/unofficial-stockal-api/notify"
+	"github.com/adjaecent/unofficial-stockal-api/registry"
+)
+
+// The registry is global, so each test registers names of its own.
+
+// caller records the request an endpoint makes.
+type caller struct {
+	method, path string
+	payload      any
+}
+
+func (c *caller) Call(ctx context.Context, method, endpoint string, payload, result any) error {
+	c.method, c.path, c.payload = method, endpoint, payload
+	return nil
+}
+
+func TestEndpointCall(t *testing.T) {
+	tests := []struct {
+		name     string
+		endpoint registry.Endpoint
+		params   map[string]string
+		path     string
+		payload  any
+		err      string
+	}{
+		{
+			name:     "path and query",
+			endpoint: registry.Endpoint{Name: "list", Method: "GET", Path: "/v2/news/{symbol}"},
+			params:   map[string]string{"symbol": "BRK/B", "limit": "5", "page": "2"},
+			path:     "/v2/news/BRK%2FB?limit=5&page=2",
+		},
+		{
+			name:     "payload",
+			endpoint: registry.Endpoint{Name: "alert", Method: "POST", Path: "/v2/alerts/{symbol}"},
+			params:   map[string]string{"symbol": "AAPL", "price": "200"},
+			path:     "/v2/alerts/AAPL",
+			payload:  map[string]string{"price": "200"},
+		},
+		{
+			name:     "no parameters",
+			endpoint: registry.Endpoint{Name: "status", Method: "DELETE", Path: "/v2/alerts"},
+			path:     "/v2/alerts",
+		},
+		{
+			name:     "missing parameter",
+			endpoint: registry.Endpoint{Name: "list", Method: "GET", Path: "/v2/news/{symbol}"},
+			params:   map[string]string{"limit": "5"},
+			err:      `list: missing parameter "symbol"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var c caller
+			err := tt.endpoint.Call(context.Background(), &c, tt.params, nil)
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Fatalf("Call = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Call: %v", err)
+			}
+			if c.method != tt.endpoint.Method || c.path != tt.path || !reflect.DeepEqual(c.payload, tt.payload) {
+				t.Errorf("called %s %s with %v, want %s %s with %v", c.method, c.path, c.payload, tt.endpoint.Method, tt.path, tt.payload)
+			}
+		})
+	}
+}
+
+func TestEndpoints(t *testing.T) {
+	registry.RegisterEndpoints(registry.EndpointGroup{Name: "test-news", Endpoints: []registry.Endpoint{{Name: "list"}, {Name: "get"}}})
+	registry.RegisterEndpoints(registry.EndpointGroup{Name: "test-alerts"})
+
+	g, ok := registry.Endpoints("test-news")
+	if !ok {
+		t.Fatal("test-news not registered")
+	}
+	if e, ok := g.Endpoint("get"); !ok || e.Name != "get" {
+		t.Errorf("Endpoint(get) = %+v, %v", e, ok)
+	}
+	if _, ok := g.Endpoint("delete"); ok {
+		t.Error("Endpoint found an endpoint the group lacks")
+	}
+
+	var names []string
+	for _, g := range registry.EndpointGroups() {
+		names = append(names, g.Name)
+	}
+	if i, j := slices.Index(names, "test-alerts"), slices.Index(names, "test-news"); i < 0 || j < i {
+		t.Errorf("EndpointGroups = %q, want test-alerts before test-news", names)
+	}
+}
+
+func TestRegisterPanics(t *testing.T) {
+	registry.RegisterEndpoints(registry.EndpointGroup{Name: "test-dup"})
+	tests := map[string]func(){
+		"duplicate": func() { registry.RegisterEndpoints(registry.EndpointGroup{Name: "test-dup"}) },
+		"no name":   func() { registry.RegisterStore("", nil) },
+	}
+	for name, register := range tests {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("registering did not panic")
+				}
+			}()
+			register()
+		})
+	}
+}
+
+type discard struct{}
+
+func (discard) Notify(ctx context.Context, e events.Event) error { return nil }
+
+func TestNotifier(t *testing.T) {
+	var got registry.NotifierConfig
+	registry.RegisterNotifier("test-ntfy", func(ctx context.Context, cfg registry.NotifierConfig) (notify.Notifier, error) {
+		got = cfg
+		return discard{}, nil
+	})
+	if !slices.Contains(registry.NotifierKinds(), "test-ntfy") {
+		t.Errorf("NotifierKinds = %q, want test-ntfy", registry.NotifierKinds())
+	}
+	factory, ok := registry.Notifier("test-ntfy")
+	if !ok {
+		t.Fatal("test-ntfy not registered")
+	}
+	cfg := registry.NotifierConfig{Settings: map[string]string{"topic": "stockal"}}
+	if _, err := factory(context.Background(), cfg); err != nil || got.Settings["topic"] != "stockal" {
+		t.Errorf("factory = %v, called with %+v", err, got)
+	}
+	if _, ok := registry.Notifier("test-missing"); ok {
+		t.Error("Notifier found an unregistered kind")
+	}
+}
+
+func TestOpenStore(t *testing.T) {
+	var opened *url.URL
+	registry.RegisterStore("test-mem", func(ctx context.Context, u *url.URL) (history.Store, error) {
+		opened = u
+		return nil, nil
+	})
+
+	if _, err := registry.OpenStore(context.Background(), "test-mem://bucket/prefix?region=eu"); err != nil {
+		t.Fatalf("OpenStore: %v", err)
+	}
+	if opened == nil || opened.Host != "bucket" || opened.Path != "/prefix" || opened.Query().Get("region") != "eu" {
+		t.Errorf("opener called with %v, want the parsed URL", opened)
+	}
+
+	_, err := registry.OpenStore(context.Background(), "test-unknown://x")
+	if err == nil || !strings.Contains(err.Error(), "test-mem") {
+		t.Errorf("OpenStore of an unknown scheme = %v, want an error listing the registered schemes", err)
+	}
+	if _, err := registry.OpenStore(context.Background(), "test-mem://%zz"); err == nil {
+		t.Error("OpenStore accepted an invalid URL")
+	}
+}
+
+func TestCommands(t *testing.T) {
+	session := registry.Session(func(cmd *cobra.Command) (stockal.StockalClient, error) { return nil, nil })
+	var passed registry.Session
+	registry.RegisterCommand("test-news", func(s registry.Session) *cobra.Command {
+		passed = s
+		return &cobra.Command{Use: "test-news"}
+	})
+
+	var names []string
+	for _, cmd := range registry.Commands(session) {
+		names = append(names, cmd.Name())
+	}
+	if !slices.Contains(names, "test-news") || passed == nil {
+		t.Errorf("Commands = %q, want test-news created with the session", names)
+	}
+}
@@ -0,0 +1,62 @@
+package report
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+)
+
+var htmlTemplate = template.Must(template.New("digest").Funcs(template.FuncMap{
+	"money":   func(v float64) string { return fmt.Sprintf("$%.2f", v) },
+	"percent": func(v float64) string { return fmt.Sprintf("%.2f%%", v) },
+	"sign": func(v float64) string {
+		switch {
+		case v > 0:
+			return "up"
+		case v < 0:
+			return "down"
+		}
+		return ""
+	},
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Portfolio digest {{.AsOf.Format "2006-01-02"}}</title>
+<style>
+body { font-family: -apple-system, Helvetica, Arial, sans-serif; color: #222; }
+table { border-collapse: collapse; margin-bottom: 1.5em; }
+th, td { padding: 4px 10px; text-align: right; border-bottom: 1px solid #eee; }
+th:first-child, td:first-child { text-align: left; }
+.up { color: #1a7f37; }
+.down { color: #cf222e; }
+</style>
+</head>
+<body>
+<h1>Portfolio digest</h1>
+<p>As of {{.AsOf.Format "2006-01-02 15:04 MST"}}</p>
+<table>
+<tr><td>Portfolio value</td><td>{{money .TotalValue}}</td></tr>
+<tr><td>Day change</td><td class="{{sign .DayChange}}">{{money .DayChange}} ({{percent .DayChangePercent}})</td></tr>
+<tr><td>Total gain/loss</td><td class="{{sign .TotalGain}}">{{money .TotalGain}}</td></tr>
+<tr><td>Invested</td><td>{{money .TotalInvested}}</td></tr>
+<tr><td>Cash available</td><td>{{money .Cash}}</td></tr>
+</table>
+{{define "movers"}}<table>
+<tr><th>Symbol</th><th>Price</th><th>Day %</th><th>Day change</th><th>Value</th></tr>
+{{range .}}<tr><td>{{.Symbol}}</td><td>{{money .Price}}</td><td class="{{sign .DayChangePercent}}">{{percent .DayChangePercent}}</td><td class="{{sign .DayChange}}">{{money .DayChange}}</td><td>{{money .Value}}</td></tr>
+{{end}}</table>{{end}}
+{{if .Gainers}}<h2>Top gainers</h2>
+{{template "movers" .Gainers}}{{end}}
+{{if .Losers}}<h2>Top losers</h2>
+{{template "movers" .Losers}}{{end}}
+<h2>Holdings</h2>
+{{template "movers" .Holdings}}
+</body>
+</html>
+`))
+
+// WriteHTML renders the digest as a standalone HTML document.
+func (d *Digest) WriteHTML(w io.Writer) error {
+	return htmlTemplate.Execute(w, d)
+}
@@ -0,0 +1,116 @@
+package report
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SMTPConfig holds the settings needed to send a digest by email.
+type SMTPConfig struct {
+	// Host is the SMTP server host name
+	Host string `yaml:"host"`
+	// Port is the SMTP server port (587 if zero)
+	Port int `yaml:"port"`
+	// Username is the SMTP login; no authentication is used if empty
+	Username string `yaml:"username"`
+	// Password is the SMTP password
+	Password string `yaml:"-"`
+	// From is the sender address
+	From string `yaml:"from"`
+	// To lists the recipient addresses
+	To []string `yaml:"to"`
+}
+
+// Attachment is a file attached to an email.
+type Attachment struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+}
+
+// Mail sends an HTML email with optional attachments. STARTTLS is used when the
+// server supports it.
+func Mail(cfg SMTPConfig, subject string, html []byte, attachments ...Attachment) error {
+	if cfg.Host == "" || cfg.From == "" || len(cfg.To) == 0 {
+		return errors.New("smtp: host, from and at least one recipient are required")
+	}
+	port := cfg.Port
+	if port == 0 {
+		port = 587
+	}
+
+	msg, err := buildMessage(cfg, subject, html, attachments)
+	if err != nil {
+		return err
+	}
+
+	var auth smtp.Auth
+	if cfg.Username != "" {
+		auth = smtp.PlainAuth("", cfg.Username, cfg.Password, cfg.Host)
+	}
+	if err := smtp.SendMail(net.JoinHostPort(cfg.Host, strconv.Itoa(port)), auth, cfg.From, cfg.To, msg); err != nil {
+		return fmt.Errorf("smtp: %w", err)
+	}
+	return nil
+}
+
+// buildMessage assembles a multipart MIME message.
+func buildMessage(cfg SMTPConfig, subject string, html []byte, attachments []Attachment) ([]byte, error) {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Type", "text/html; charset=utf-8")
+	header.Set("Content-Transfer-Encoding", "base64")
+	part, err := mw.CreatePart(header)
+	if err != nil {
+		return nil, err
+	}
+	writeBase64(part, html)
+
+	for _, a := range attachments {
+		header := textproto.MIMEHeader{}
+		header.Set("Content-Type", a.ContentType)
+		header.Set("Content-Transfer-Encoding", "base64")
+		header.Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": a.Filename}))
+		part, err := mw.CreatePart(header)
+		if err != nil {
+			return nil, err
+		}
+		writeBase64(part, a.Data)
+	}
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", cfg.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(cfg.To, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&msg, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&msg, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&msg, "Content-Type: multipart/mixed; boundary=%s\r\n\r\n", mw.Boundary())
+	msg.Write(body.Bytes())
+	return msg.Bytes(), nil
+}
+
+// writeBase64 writes data base64-encoded in 76-character lines, as required by RFC 2045.
+func writeBase64(w io.Writer, data []byte) {
+	encoded := base64.StdEncoding.EncodeToString(data)
+	for len(encoded) > 76 {
+		w.Write([]byte(encoded[:76] + "\r\n"))
+		encoded = encoded[76:]
+	}
+	w.Write([]byte(encoded + "\r\n"))
+}
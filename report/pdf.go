@@ -0,0 +1,79 @@
+package report
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+// WritePDF renders the digest as a PDF document.
+func (d *Digest) WritePDF(w io.Writer) error {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(15, 15, 15)
+	pdf.AddPage()
+
+	pdf.SetFont("Helvetica", "B", 18)
+	pdf.CellFormat(0, 10, "Portfolio digest", "", 1, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 10)
+	pdf.CellFormat(0, 6, "As of "+d.AsOf.Format("2006-01-02 15:04 MST"), "", 1, "L", false, 0, "")
+	pdf.Ln(4)
+
+	summary := [][2]string{
+		{"Portfolio value", money(d.TotalValue)},
+		{"Day change", fmt.Sprintf("%s (%.2f%%)", money(d.DayChange), d.DayChangePercent)},
+		{"Total gain/loss", money(d.TotalGain)},
+		{"Invested", money(d.TotalInvested)},
+		{"Cash available", money(d.Cash)},
+	}
+	for _, row := range summary {
+		pdf.CellFormat(50, 6, row[0], "", 0, "L", false, 0, "")
+		pdf.CellFormat(40, 6, row[1], "", 1, "R", false, 0, "")
+	}
+
+	if len(d.Gainers) > 0 {
+		pdfMovers(pdf, "Top gainers", d.Gainers)
+	}
+	if len(d.Losers) > 0 {
+		pdfMovers(pdf, "Top losers", d.Losers)
+	}
+	pdfMovers(pdf, "Holdings", d.Holdings)
+
+	return pdf.Output(w)
+}
+
+func pdfMovers(pdf *gofpdf.Fpdf, title string, movers []Mover) {
+	widths := []float64{30, 30, 30, 35, 35}
+	headers := []string{"Symbol", "Price", "Day %", "Day change", "Value"}
+
+	pdf.Ln(6)
+	pdf.SetFont("Helvetica", "B", 13)
+	pdf.CellFormat(0, 8, title, "", 1, "L", false, 0, "")
+
+	pdf.SetFont("Helvetica", "B", 10)
+	for i, h := range headers {
+		align := "R"
+		if i == 0 {
+			align = "L"
+		}
+		pdf.CellFormat(widths[i], 6, h, "B", 0, align, false, 0, "")
+	}
+	pdf.Ln(-1)
+
+	pdf.SetFont("Helvetica", "", 10)
+	for _, m := range movers {
+		cells := []string{m.Symbol, money(m.Price), fmt.Sprintf("%.2f%%", m.DayChangePercent), money(m.DayChange), money(m.Value)}
+		for i, c := range cells {
+			align := "R"
+			if i == 0 {
+				align = "L"
+			}
+			pdf.CellFormat(widths[i], 6, c, "", 0, align, false, 0, "")
+		}
+		pdf.Ln(-1)
+	}
+}
+
+func money(v float64) string {
+	return fmt.Sprintf("$%.2f", v)
+}
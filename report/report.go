@@ -0,0 +1,109 @@
+// Package report builds daily portfolio digests from a stockal.Snapshot and
+// renders them as HTML or PDF, optionally delivering them by email.
+//
+// # Basic Usage
+//
+//	snapshot, err := stockal.TakeSnapshot(ctx, client)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	digest := report.New(snapshot, 5)
+//	if err := digest.WriteHTML(os.Stdout); err != nil {
+//		log.Fatal(err)
+//	}
+package report
+
+import (
+	"sort"
+	"time"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+)
+
+// Mover is a holding together with its performance for the day.
+type Mover struct {
+	// Symbol is the stock symbol
+	Symbol string
+	// Company is the full company name
+	Company string
+	// Price is the current price per share
+	Price float64
+	// Value is the current market value of the holding
+	Value float64
+	// DayChange is the change in the holding's value since the prior close
+	DayChange float64
+	// DayChangePercent is the price change since the prior close, in percent
+	DayChangePercent float64
+	// Gain is the unrealized gain or loss against the amount invested
+	Gain float64
+}
+
+// Digest is a daily portfolio report.
+type Digest struct {
+	// AsOf is when the underlying snapshot was taken
+	AsOf time.Time
+	// TotalValue is the current value of all holdings
+	TotalValue float64
+	// TotalInvested is the amount invested across all holdings
+	TotalInvested float64
+	// TotalGain is the unrealized gain or loss across all holdings
+	TotalGain float64
+	// DayChange is the change in portfolio value since the prior close
+	DayChange float64
+	// DayChangePercent is DayChange relative to the value at the prior close, in percent
+	DayChangePercent float64
+	// Cash is the cash available for trading
+	Cash float64
+	// Holdings contains every holding, largest first
+	Holdings []Mover
+	// Gainers contains the best performers of the day, best first
+	Gainers []Mover
+	// Losers contains the worst performers of the day, worst first
+	Losers []Mover
+}
+
+// New builds a Digest from a snapshot, listing up to top gainers and losers.
+func New(snapshot *stockal.Snapshot, top int) *Digest {
+	d := &Digest{
+		AsOf:          snapshot.TakenAt,
+		TotalValue:    snapshot.Summary.PortfolioSummary.TotalCurrentValue,
+		TotalInvested: snapshot.Summary.PortfolioSummary.TotalInvestmentAmount,
+		Cash:          snapshot.Summary.AccountSummary.CashAvailableForTrade,
+	}
+	d.TotalGain = d.TotalValue - d.TotalInvested
+
+	var priorValue float64
+	for _, h := range snapshot.Holdings {
+		m := Mover{
+			Symbol:           h.Symbol,
+			Company:          h.Company,
+			Price:            h.Price,
+			Value:            h.CurrentValue(),
+			Gain:             h.UnrealizedGain(),
+			DayChange:        h.DayChange(),
+			DayChangePercent: h.DayChangePercent(),
+		}
+		d.DayChange += m.DayChange
+		priorValue += m.Value - m.DayChange
+		d.Holdings = append(d.Holdings, m)
+	}
+	if priorValue != 0 {
+		d.DayChangePercent = d.DayChange / priorValue * 100
+	}
+
+	sort.SliceStable(d.Holdings, func(i, j int) bool { return d.Holdings[i].Value > d.Holdings[j].Value })
+
+	byChange := append([]Mover(nil), d.Holdings...)
+	sort.SliceStable(byChange, func(i, j int) bool { return byChange[i].DayChangePercent > byChange[j].DayChangePercent })
+	for _, m := range byChange {
+		if len(d.Gainers) < top && m.DayChangePercent > 0 {
+			d.Gainers = append(d.Gainers, m)
+		}
+	}
+	for i := len(byChange) - 1; i >= 0; i-- {
+		if len(d.Losers) < top && byChange[i].DayChangePercent < 0 {
+			d.Losers = append(d.Losers, byChange[i])
+		}
+	}
+	return d
+}
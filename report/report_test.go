@@ -0,0 +1,255 @@
+package report_test
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"io"
+	"math"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+	"github.com/adjaecent/unofficial-stockal-api/report"
+)
+
+// snapshot returns a snapshot with two gainers, a loser and a holding
+// without a prior close. TSLA's day change is to its session close.
+func snapshot() *stockal.Snapshot {
+	s := &stockal.Snapshot{TakenAt: time.Date(2024, 5, 1, 20, 0, 0, 0, time.UTC)}
+	s.Summary.PortfolioSummary.TotalCurrentValue = 5500
+	s.Summary.PortfolioSummary.TotalInvestmentAmount = 5000
+	s.Summary.AccountSummary.CashAvailableForTrade = 250
+	s.Holdings = []stockal.Holding{
+		{Symbol: "VOO", Company: "Vanguard S&P 500 ETF", TotalUnit: 2, Price: 500, TotalInvestment: 1000},
+		{Symbol: "MSFT", Company: "Microsoft Corporation", TotalUnit: 4, Price: 400, PriorClose: 410, TotalInvestment: 1750},
+		{Symbol: "TSLA", Company: "Tesla, Inc.", TotalUnit: 5, Price: 200, Close: 198, PriorClose: 190, TotalInvestment: 900},
+		{Symbol: "AAPL", Company: "Apple Inc.", TotalUnit: 10, Price: 190, PriorClose: 180, TotalInvestment: 1500},
+	}
+	return s
+}
+
+// symbols returns the symbols of movers.
+func symbols(movers []report.Mover) string {
+	var s []string
+	for _, m := range movers {
+		s = append(s, m.Symbol)
+	}
+	return strings.Join(s, ",")
+}
+
+func near(got, want float64) bool {
+	return math.Abs(got-want) < 1e-9
+}
+
+func TestNew(t *testing.T) {
+	d := report.New(snapshot(), 5)
+	if d.TotalValue != 5500 || d.TotalInvested != 5000 || d.TotalGain != 500 || d.Cash != 250 || !d.AsOf.Equal(snapshot().TakenAt) {
+		t.Errorf("digest %+v, want the snapshot's totals", d)
+	}
+	// AAPL gained 100, TSLA 40 and MSFT lost 40 from a prior value of 5400
+	if d.DayChange != 100 || !near(d.DayChangePercent, 100.0/5400*100) {
+		t.Errorf("day change %v (%v%%), want 100 (1.85%%)", d.DayChange, d.DayChangePercent)
+	}
+
+	if got := symbols(d.Holdings); got != "AAPL,MSFT,VOO,TSLA" {
+		t.Errorf("holdings %s, want largest first", got)
+	}
+	msft := d.Holdings[1]
+	want := report.Mover{Symbol: "MSFT", Company: "Microsoft Corporation", Price: 400, Value: 1600, DayChange: -40, Gain: -150}
+	if msft.DayChangePercent, want.DayChangePercent = 0, 0; msft != want {
+		t.Errorf("MSFT %+v, want %+v", msft, want)
+	}
+
+	tests := []struct {
+		top             int
+		gainers, losers string
+	}{
+		{top: 5, gainers: "AAPL,TSLA", losers: "MSFT"},
+		{top: 1, gainers: "AAPL", losers: "MSFT"},
+		{top: 0},
+	}
+	for _, tt := range tests {
+		d := report.New(snapshot(), tt.top)
+		if gainers, losers := symbols(d.Gainers), symbols(d.Losers); gainers != tt.gainers || losers != tt.losers {
+			t.Errorf("top %d: gainers %q, losers %q; want %q, %q", tt.top, gainers, losers, tt.gainers, tt.losers)
+		}
+	}
+
+	if d := report.New(&stockal.Snapshot{}, 5); d.DayChangePercent != 0 || d.Holdings != nil {
+		t.Errorf("digest of an empty snapshot %+v, want no change", d)
+	}
+}
+
+func TestWriteHTML(t *testing.T) {
+	var b strings.Builder
+	if err := report.New(snapshot(), 5).WriteHTML(&b); err != nil {
+		t.Fatalf("WriteHTML: %v", err)
+	}
+	html := b.String()
+	for _, want := range []string{
+		"<title>Portfolio digest 2024-05-01</title>",
+		"<p>As of 2024-05-01 20:00 UTC</p>",
+		"<tr><td>Portfolio value</td><td>$5500.00</td></tr>",
+		`<tr><td>Day change</td><td class="up">$100.00 (1.85%)</td></tr>`,
+		`<tr><td>Total gain/loss</td><td class="up">$500.00</td></tr>`,
+		"<tr><td>Cash available</td><td>$250.00</td></tr>",
+		"<h2>Top gainers</h2>",
+		"<h2>Top losers</h2>",
+		`<tr><td>MSFT</td><td>$400.00</td><td class="down">-2.44%</td><td class="down">$-40.00</td><td>$1600.00</td></tr>`,
+		`<tr><td>VOO</td><td>$500.00</td><td class="">0.00%</td><td class="">$0.00</td><td>$1000.00</td></tr>`,
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("HTML lacks %s:\n%s", want, html)
+		}
+	}
+	if n := strings.Count(html, "<td>MSFT</td>"); n != 2 {
+		t.Errorf("MSFT listed %d times, want among the losers and holdings", n)
+	}
+
+	s := snapshot()
+	s.Holdings = []stockal.Holding{{Symbol: "<b>", TotalUnit: 1, Price: 10}}
+	b.Reset()
+	if err := report.New(s, 5).WriteHTML(&b); err != nil {
+		t.Fatalf("WriteHTML: %v", err)
+	}
+	if html := b.String(); !strings.Contains(html, "<td>&lt;b&gt;</td>") || strings.Contains(html, "Top gainers") || strings.Contains(html, "Top losers") {
+		t.Errorf("HTML without movers:\n%s\nwant the symbol escaped and no movers", html)
+	}
+}
+
+func TestWritePDF(t *testing.T) {
+	for _, d := range []*report.Digest{report.New(snapshot(), 5), report.New(&stockal.Snapshot{}, 5)} {
+		var b bytes.Buffer
+		if err := d.WritePDF(&b); err != nil {
+			t.Fatalf("WritePDF: %v", err)
+		}
+		if pdf := b.Bytes(); !bytes.HasPrefix(pdf, []byte("%PDF-")) || !bytes.HasSuffix(bytes.TrimSpace(pdf), []byte("%%EOF")) {
+			t.Errorf("WritePDF wrote %d bytes starting %q, want a PDF document", len(pdf), pdf[:min(len(pdf), 8)])
+		}
+	}
+}
+
+// smtpServer is a fake SMTP server accepting one message from me, with the
+// password s3cret, which it sends on the returned channel.
+func smtpServer(t *testing.T) (port int, messages <-chan string) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	received := make(chan string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		reply := func(lines ...string) {
+			conn.Write([]byte(strings.Join(lines, "\r\n") + "\r\n"))
+		}
+		reply("220 localhost ESMTP")
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			switch command, _, _ := strings.Cut(strings.TrimSpace(line), " "); strings.ToUpper(command) {
+			case "EHLO":
+				reply("250-localhost", "250 AUTH PLAIN")
+			case "AUTH":
+				if want := "AUTH PLAIN " + base64.StdEncoding.EncodeToString([]byte("\x00me\x00s3cret")); strings.TrimSpace(line) != want {
+					t.Errorf("sent %q, want %q", strings.TrimSpace(line), want)
+				}
+				reply("235 2.7.0 Authentication successful")
+			case "DATA":
+				reply("354 End data with <CR><LF>.<CR><LF>")
+				var data strings.Builder
+				for {
+					line, err := r.ReadString('\n')
+					if err != nil || line == ".\r\n" {
+						break
+					}
+					data.WriteString(line)
+				}
+				received <- data.String()
+				reply("250 OK")
+			case "QUIT":
+				reply("221 Bye")
+				return
+			default:
+				reply("250 OK")
+			}
+		}
+	}()
+	return ln.Addr().(*net.TCPAddr).Port, received
+}
+
+func TestMail(t *testing.T) {
+	port, messages := smtpServer(t)
+	cfg := report.SMTPConfig{Host: "127.0.0.1", Port: port, Username: "me", Password: "s3cret",
+		From: "stockal@example.com", To: []string{"me@example.com", "you@example.com"}}
+	err := report.Mail(cfg, "Portfolio digest — 1 May", []byte("<p>Up 1.85%</p>"),
+		report.Attachment{Filename: "digest.pdf", ContentType: "application/pdf", Data: []byte("%PDF-1.3")})
+	if err != nil {
+		t.Fatalf("Mail: %v", err)
+	}
+
+	msg, err := mail.ReadMessage(strings.NewReader(<-messages))
+	if err != nil {
+		t.Fatalf("invalid message: %v", err)
+	}
+	subject, _ := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	if msg.Header.Get("From") != "stockal@example.com" || msg.Header.Get("To") != "me@example.com, you@example.com" ||
+		subject != "Portfolio digest — 1 May" {
+		t.Errorf("headers %v, want the sender, recipients and subject", msg.Header)
+	}
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/mixed" {
+		t.Fatalf("Content-Type %q, want multipart/mixed", msg.Header.Get("Content-Type"))
+	}
+
+	var parts []string
+	mr := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("invalid part: %v", err)
+		}
+		data, err := io.ReadAll(base64.NewDecoder(base64.StdEncoding, part))
+		if err != nil {
+			t.Fatalf("invalid part: %v", err)
+		}
+		parts = append(parts, part.Header.Get("Content-Type")+" "+part.FileName()+": "+string(data))
+	}
+	want := "text/html; charset=utf-8 : <p>Up 1.85%</p>\napplication/pdf digest.pdf: %PDF-1.3"
+	if got := strings.Join(parts, "\n"); got != want {
+		t.Errorf("parts\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestMailFail(t *testing.T) {
+	if err := report.Mail(report.SMTPConfig{Host: "127.0.0.1", From: "stockal@example.com"}, "digest", nil); err == nil {
+		t.Error("Mail without recipients succeeded")
+	}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ln.Close()
+	cfg := report.SMTPConfig{Host: "127.0.0.1", Port: ln.Addr().(*net.TCPAddr).Port, From: "stockal@example.com", To: []string{"me@example.com"}}
+	if err := report.Mail(cfg, "digest", nil); err == nil || !strings.HasPrefix(err.Error(), "smtp: ") {
+		t.Errorf("Mail without a server = %v, want an smtp error", err)
+	}
+}
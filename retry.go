@@ -0,0 +1,154 @@
+package stockal
+
+import (
+	"context"
+	"math/rand/v2"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// RetryPolicy controls how the client retries requests that fail for
+// transient reasons: 5xx responses and network errors, including timeouts.
+// Each retry waits longer than the last, with jitter so that clients retrying
+// together spread out. Rate-limited requests are retried as WithRateLimitWait
+// says, as many times as MaxAttempts allows.
+//
+// Requests that change state, such as placing an order, are sent with POST and
+// may have taken effect even though they failed, so they are not retried
+// unless RetryNonIdempotent is set.
+type RetryPolicy struct {
+	// MaxAttempts is the most times a request is sent, counting the first;
+	// values below 2 disable retrying
+	MaxAttempts int
+	// InitialBackoff is the wait before the first retry; each later retry
+	// waits twice as long as the one before
+	InitialBackoff time.Duration
+	// MaxBackoff caps the wait before a retry; zero means no cap
+	MaxBackoff time.Duration
+	// Jitter is the fraction of each wait, from 0 to 1, that is random
+	Jitter float64
+	// Budget, if positive, is the most retries the client makes in any
+	// minute, across all requests, so that an outage is not met with a storm
+	// of retries
+	Budget int
+	// RetryNonIdempotent retries POST requests too
+	RetryNonIdempotent bool
+}
+
+// DefaultRetryPolicy suits unattended jobs: three attempts, waiting about half
+// a second and then a second, with at most 30 retries a minute.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:    3,
+	InitialBackoff: 500 * time.Millisecond,
+	MaxBackoff:     10 * time.Second,
+	Jitter:         0.2,
+	Budget:         30,
+}
+
+// WithRetry retries requests that fail for transient reasons as policy says.
+// Without it, only rate-limited requests are retried, once.
+//
+// Example:
+//
+//	client := stockal.NewClient(stockal.WithRetry(stockal.DefaultRetryPolicy))
+func WithRetry(policy RetryPolicy) ClientOption {
+	return func(c *clientConfig) {
+		c.retry = &policy
+	}
+}
+
+// backoff returns the wait before retry number n, counting from 1.
+func (p RetryPolicy) backoff(n int) time.Duration {
+	wait := p.InitialBackoff
+	for i := 1; i < n && (p.MaxBackoff <= 0 || wait < p.MaxBackoff); i++ {
+		wait *= 2
+	}
+	if p.MaxBackoff > 0 && wait > p.MaxBackoff {
+		wait = p.MaxBackoff
+	}
+	if jitter := min(max(p.Jitter, 0), 1); jitter > 0 {
+		wait -= time.Duration(float64(wait) * jitter * rand.Float64())
+	}
+	return wait
+}
+
+// retryBudget counts the retries a client made in the last minute.
+type retryBudget struct {
+	mu    sync.Mutex
+	limit int
+	spent []time.Time
+}
+
+// take reports whether another retry fits in the budget at now, and if so
+// counts it.
+func (b *retryBudget) take(now time.Time) bool {
+	if b == nil || b.limit <= 0 {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	cutoff := now.Add(-time.Minute)
+	i := 0
+	for i < len(b.spent) && !b.spent[i].After(cutoff) {
+		i++
+	}
+	b.spent = b.spent[i:]
+	if len(b.spent) >= b.limit {
+		return false
+	}
+	b.spent = append(b.spent, now)
+	return true
+}
+
+// idempotent reports whether a request with method can be sent twice with
+// the effect of sending it once.
+func idempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// rateLimitAttempts returns the most times a rate-limited request is sent.
+func (c *Client) rateLimitAttempts() int {
+	if c.retry != nil && c.retry.MaxAttempts > 2 {
+		return c.retry.MaxAttempts
+	}
+	return 2
+}
+
+// retryTransient reports whether to retry a request that got resp or err on
+// the given attempt, counting from 1, waiting the policy's backoff first. It
+// closes the response body if it retries.
+func (c *Client) retryTransient(ctx context.Context, req *http.Request, resp *http.Response, err error, attempt int) bool {
+	if c.retry == nil || attempt >= c.retry.MaxAttempts || ctx.Err() != nil {
+		return false
+	}
+	if err == nil && (resp.StatusCode < 500 || resp.StatusCode > 599) {
+		return false
+	}
+	if !idempotent(req.Method) && !c.retry.RetryNonIdempotent {
+		return false
+	}
+	wait := c.retry.backoff(attempt)
+	if deadline, ok := ctx.Deadline(); ok && !time.Now().Add(wait).Before(deadline) {
+		return false
+	}
+	if !c.retryBudget.take(time.Now()) {
+		return false
+	}
+
+	if resp != nil {
+		resp.Body.Close()
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+	return true
+}
@@ -0,0 +1,97 @@
+package stockal_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+)
+
+// testRetryPolicy retries without waiting long.
+var testRetryPolicy = stockal.RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond}
+
+func TestRetry(t *testing.T) {
+	budget := testRetryPolicy
+	budget.Budget = 1
+	nonIdempotent := testRetryPolicy
+	nonIdempotent.RetryNonIdempotent = true
+
+	tests := []struct {
+		name     string
+		failures int
+		drop     bool
+		post     bool
+		opts     []stockal.ClientOption
+		requests int
+		fails    bool
+	}{
+		{"no policy", 1, false, false, nil, 1, true},
+		{"recovers", 2, false, false, []stockal.ClientOption{stockal.WithRetry(testRetryPolicy)}, 3, false},
+		{"gives up", 3, false, false, []stockal.ClientOption{stockal.WithRetry(testRetryPolicy)}, 3, true},
+		{"network error", 1, true, false, []stockal.ClientOption{stockal.WithRetry(testRetryPolicy)}, 2, false},
+		{"over budget", 2, false, false, []stockal.ClientOption{stockal.WithRetry(budget)}, 2, true},
+		{"post", 1, false, true, []stockal.ClientOption{stockal.WithRetry(testRetryPolicy)}, 1, true},
+		{"post allowed", 1, false, true, []stockal.ClientOption{stockal.WithRetry(nonIdempotent)}, 2, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests := 0
+			client := newHandlerClient(t, func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				if requests <= tt.failures {
+					if tt.drop {
+						panic(http.ErrAbortHandler)
+					}
+					w.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+				w.Header().Set("Content-Type", "application/json")
+				if r.Method == "POST" {
+					w.Write([]byte(`{"code":200,"message":"Success","data":{"watchlistID":"w1","name":"Tech","symbols":[]}}`))
+					return
+				}
+				w.Write([]byte(`{"code":200,"message":"Success","data":{"orders":[],"totalRecords":0}}`))
+			}, tt.opts...)
+
+			var err error
+			if tt.post {
+				_, err = client.CreateWatchlist(context.Background(), "Tech")
+			} else {
+				_, err = client.Orders().List(context.Background())
+			}
+			if tt.fails != (err != nil) {
+				t.Errorf("error = %v, want an error: %v", err, tt.fails)
+			}
+			if tt.fails && !tt.drop && !errors.Is(err, stockal.ErrServerError) {
+				t.Errorf("error = %v, want ErrServerError", err)
+			}
+			if requests != tt.requests {
+				t.Errorf("server got %d requests, want %d", requests, tt.requests)
+			}
+		})
+	}
+}
+
+func TestRetryRateLimited(t *testing.T) {
+	requests := 0
+	client := newHandlerClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", "application/json")
+		if requests <= 2 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"code":429,"message":"Too many requests"}`))
+			return
+		}
+		w.Write([]byte(`{"code":200,"message":"Success","data":{"orders":[],"totalRecords":0}}`))
+	}, stockal.WithRetry(testRetryPolicy))
+	if _, err := client.Orders().List(context.Background()); err != nil {
+		t.Fatalf("Orders.List: %v", err)
+	}
+	if requests != 3 {
+		t.Errorf("server got %d requests, want 3", requests)
+	}
+}
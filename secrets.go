@@ -0,0 +1,81 @@
+package stockal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrSecretNotFound is returned by a SecretsProvider that has no secret of
+// the requested name.
+var ErrSecretNotFound = errors.New("secret not found")
+
+// SecretsProvider resolves named secrets such as passwords, API tokens and
+// webhook signing secrets, so that deployments keep them out of
+// configuration files.
+//
+// Names are environment variable style, e.g. "STOCKAL_PASSWORD". Secret
+// returns ErrSecretNotFound, possibly wrapped, when there is no such secret.
+type SecretsProvider interface {
+	Secret(ctx context.Context, name string) (string, error)
+}
+
+// EnvSecrets reads secrets from environment variables of the same name.
+// Empty variables count as unset.
+type EnvSecrets struct{}
+
+var _ SecretsProvider = EnvSecrets{}
+
+// Secret returns the value of the environment variable name.
+func (EnvSecrets) Secret(ctx context.Context, name string) (string, error) {
+	if v := os.Getenv(name); v != "" {
+		return v, nil
+	}
+	return "", fmt.Errorf("%w: %s", ErrSecretNotFound, name)
+}
+
+// FileSecrets reads secrets from files named after them in a directory, as
+// Docker and Kubernetes mount them (e.g. /run/secrets/STOCKAL_PASSWORD).
+// Trailing newlines are removed.
+type FileSecrets struct {
+	Dir string
+}
+
+var _ SecretsProvider = FileSecrets{}
+
+// Secret returns the contents of the file name in the directory.
+func (s FileSecrets) Secret(ctx context.Context, name string) (string, error) {
+	if name == "" || name != filepath.Base(name) || name == "." || name == ".." {
+		return "", fmt.Errorf("invalid secret name %q", name)
+	}
+	data, err := os.ReadFile(filepath.Join(s.Dir, name))
+	if errors.Is(err, os.ErrNotExist) {
+		return "", fmt.Errorf("%w: %s", ErrSecretNotFound, name)
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// ChainSecrets returns a SecretsProvider that asks each provider in turn and
+// returns the first secret found. Errors other than ErrSecretNotFound stop
+// the search.
+func ChainSecrets(providers ...SecretsProvider) SecretsProvider {
+	return chainSecrets(providers)
+}
+
+type chainSecrets []SecretsProvider
+
+func (c chainSecrets) Secret(ctx context.Context, name string) (string, error) {
+	for _, p := range c {
+		v, err := p.Secret(ctx, name)
+		if !errors.Is(err, ErrSecretNotFound) {
+			return v, err
+		}
+	}
+	return "", fmt.Errorf("%w: %s", ErrSecretNotFound, name)
+}
@@ -0,0 +1,122 @@
+// Package awssm resolves secrets from AWS Secrets Manager as a
+// stockal.SecretsProvider.
+//
+// By default each secret is its own Secrets Manager secret, named by the
+// secret name after an optional prefix: with the prefix "stockal/",
+// STOCKAL_PASSWORD is read from the secret "stockal/STOCKAL_PASSWORD". With
+// WithJSON, all secrets are keys of one secret holding a JSON object, as the
+// console creates for key/value secrets:
+//
+//	{"STOCKAL_USERNAME": "alice", "STOCKAL_PASSWORD": "..."}
+//
+// Open takes credentials and region from the usual AWS environment variables
+// and shared configuration, or from the instance or task role.
+package awssm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager/types"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+)
+
+// Provider reads secrets from AWS Secrets Manager. It implements
+// stockal.SecretsProvider.
+type Provider struct {
+	client *secretsmanager.Client
+	prefix string
+	jsonID string
+
+	mu sync.Mutex
+	// values caches the keys of the JSON secret, read once
+	values map[string]string
+}
+
+var _ stockal.SecretsProvider = (*Provider)(nil)
+
+// Option configures a Provider.
+type Option func(*Provider)
+
+// WithPrefix reads each secret from the secret named prefix followed by its
+// name.
+func WithPrefix(prefix string) Option {
+	return func(p *Provider) {
+		p.prefix = prefix
+	}
+}
+
+// WithJSON reads all secrets from the keys of the JSON object stored in the
+// secret secretID, a name or ARN.
+func WithJSON(secretID string) Option {
+	return func(p *Provider) {
+		p.jsonID = secretID
+	}
+}
+
+// New returns a provider using client.
+func New(client *secretsmanager.Client, opts ...Option) *Provider {
+	p := &Provider{client: client}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// Open returns a provider with a client configured from the environment.
+func Open(ctx context.Context, opts ...Option) (*Provider, error) {
+	cfg, err := config.LoadDefaultConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("awssm: %w", err)
+	}
+	return New(secretsmanager.NewFromConfig(cfg), opts...), nil
+}
+
+// Secret returns the current value of the secret name.
+func (p *Provider) Secret(ctx context.Context, name string) (string, error) {
+	if p.jsonID == "" {
+		return p.get(ctx, p.prefix+name)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.values == nil {
+		raw, err := p.get(ctx, p.jsonID)
+		if err != nil {
+			return "", err
+		}
+		var values map[string]string
+		if err := json.Unmarshal([]byte(raw), &values); err != nil {
+			return "", fmt.Errorf("awssm: secret %s is not a JSON object of strings: %w", p.jsonID, err)
+		}
+		p.values = values
+	}
+	v, ok := p.values[name]
+	if !ok {
+		return "", fmt.Errorf("awssm: %w: %s has no key %s", stockal.ErrSecretNotFound, p.jsonID, name)
+	}
+	return v, nil
+}
+
+// get returns the string value of the secret id.
+func (p *Provider) get(ctx context.Context, id string) (string, error) {
+	out, err := p.client.GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{SecretId: aws.String(id)})
+	if err != nil {
+		var notFound *types.ResourceNotFoundException
+		if errors.As(err, &notFound) {
+			return "", fmt.Errorf("awssm: %w: %s", stockal.ErrSecretNotFound, id)
+		}
+		return "", fmt.Errorf("awssm: %w", err)
+	}
+	if out.SecretString == nil {
+		return string(out.SecretBinary), nil
+	}
+	return *out.SecretString, nil
+}
@@ -0,0 +1,93 @@
+package awssm_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+	"github.com/adjaecent/unofficial-stockal-api/secrets/awssm"
+)
+
+// newClient returns a client of a fake Secrets Manager holding secrets, and
+// the IDs of the secrets it was asked for.
+func newClient(t *testing.T, secrets map[string]string) (*secretsmanager.Client, *[]string) {
+	t.Helper()
+	var requested []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if target := r.Header.Get("X-Amz-Target"); target != "secretsmanager.GetSecretValue" {
+			t.Errorf("unexpected operation %q", target)
+		}
+		var in struct{ SecretId string }
+		if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+			t.Errorf("invalid request: %v", err)
+		}
+		requested = append(requested, in.SecretId)
+
+		w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+		value, ok := secrets[in.SecretId]
+		if !ok {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"__type":"ResourceNotFoundException","message":"Secrets Manager can't find the specified secret."}`)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"Name": in.SecretId, "SecretString": value})
+	}))
+	t.Cleanup(server.Close)
+
+	client := secretsmanager.New(secretsmanager.Options{
+		Region:       "us-east-1",
+		BaseEndpoint: aws.String(server.URL),
+		Credentials:  aws.AnonymousCredentials{},
+	})
+	return client, &requested
+}
+
+func TestSecret(t *testing.T) {
+	client, requested := newClient(t, map[string]string{"stockal/STOCKAL_PASSWORD": "hunter2"})
+	p := awssm.New(client, awssm.WithPrefix("stockal/"))
+
+	if v, err := p.Secret(context.Background(), "STOCKAL_PASSWORD"); err != nil || v != "hunter2" {
+		t.Errorf("Secret = %q, %v; want the prefixed secret", v, err)
+	}
+	if _, err := p.Secret(context.Background(), "STOCKAL_TOKEN"); !errors.Is(err, stockal.ErrSecretNotFound) {
+		t.Errorf("Secret of a missing secret = %v, want ErrSecretNotFound", err)
+	}
+	if len(*requested) != 2 || (*requested)[1] != "stockal/STOCKAL_TOKEN" {
+		t.Errorf("requested %q, want each secret by its prefixed name", *requested)
+	}
+}
+
+func TestSecretJSON(t *testing.T) {
+	client, requested := newClient(t, map[string]string{
+		"stockal": `{"STOCKAL_USERNAME":"alice","STOCKAL_PASSWORD":"hunter2"}`,
+		"broken":  `{"STOCKAL_PASSWORD":42}`,
+	})
+	p := awssm.New(client, awssm.WithJSON("stockal"))
+
+	for name, want := range map[string]string{"STOCKAL_USERNAME": "alice", "STOCKAL_PASSWORD": "hunter2"} {
+		if v, err := p.Secret(context.Background(), name); err != nil || v != want {
+			t.Errorf("Secret(%s) = %q, %v; want %q", name, v, err, want)
+		}
+	}
+	if _, err := p.Secret(context.Background(), "STOCKAL_TOKEN"); !errors.Is(err, stockal.ErrSecretNotFound) {
+		t.Errorf("Secret of a missing key = %v, want ErrSecretNotFound", err)
+	}
+	if len(*requested) != 1 {
+		t.Errorf("requested %q, want the JSON secret read once", *requested)
+	}
+
+	if _, err := awssm.New(client, awssm.WithJSON("broken")).Secret(context.Background(), "STOCKAL_PASSWORD"); err == nil {
+		t.Error("Secret accepted a JSON secret with a number")
+	}
+	if _, err := awssm.New(client, awssm.WithJSON("missing")).Secret(context.Background(), "STOCKAL_PASSWORD"); !errors.Is(err, stockal.ErrSecretNotFound) {
+		t.Errorf("Secret of a missing JSON secret = %v, want ErrSecretNotFound", err)
+	}
+}
@@ -0,0 +1,126 @@
+// Package gcpsm resolves secrets from Google Cloud Secret Manager as a
+// stockal.SecretsProvider.
+//
+// Each secret is read from the latest version of the Secret Manager secret
+// of the same name in a project, e.g. STOCKAL_PASSWORD from
+// projects/my-project/secrets/STOCKAL_PASSWORD/versions/latest.
+//
+// Open authenticates with Application Default Credentials: the
+// GOOGLE_APPLICATION_CREDENTIALS key file, gcloud's user credentials or the
+// service account of the VM, Cloud Run service or GKE workload. The account
+// needs the Secret Manager Secret Accessor role.
+package gcpsm
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"golang.org/x/oauth2/google"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+)
+
+// BaseURL is the Secret Manager API used by default.
+const BaseURL = "https://secretmanager.googleapis.com"
+
+// scope is the OAuth scope Secret Manager requires.
+const scope = "https://www.googleapis.com/auth/cloud-platform"
+
+// Provider reads secrets from Google Cloud Secret Manager. It implements
+// stockal.SecretsProvider.
+type Provider struct {
+	project    string
+	version    string
+	baseURL    string
+	httpClient *http.Client
+}
+
+var _ stockal.SecretsProvider = (*Provider)(nil)
+
+// Option configures a Provider.
+type Option func(*Provider)
+
+// WithVersion reads the given version of each secret instead of "latest".
+func WithVersion(version string) Option {
+	return func(p *Provider) {
+		p.version = version
+	}
+}
+
+// WithBaseURL sets the API base URL, for tests and private endpoints.
+func WithBaseURL(baseURL string) Option {
+	return func(p *Provider) {
+		p.baseURL = strings.TrimSuffix(baseURL, "/")
+	}
+}
+
+// New returns a provider for the secrets of project, sending requests with
+// httpClient, which must add the credentials.
+func New(project string, httpClient *http.Client, opts ...Option) *Provider {
+	p := &Provider{project: project, version: "latest", baseURL: BaseURL, httpClient: httpClient}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// Open returns a provider for the secrets of project using Application
+// Default Credentials.
+func Open(ctx context.Context, project string, opts ...Option) (*Provider, error) {
+	client, err := google.DefaultClient(ctx, scope)
+	if err != nil {
+		return nil, fmt.Errorf("gcpsm: %w", err)
+	}
+	return New(project, client, opts...), nil
+}
+
+// Secret returns the value of the secret name.
+func (p *Provider) Secret(ctx context.Context, name string) (string, error) {
+	path := fmt.Sprintf("/v1/projects/%s/secrets/%s/versions/%s:access",
+		url.PathEscape(p.project), url.PathEscape(name), url.PathEscape(p.version))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.baseURL+path, nil)
+	if err != nil {
+		return "", fmt.Errorf("gcpsm: failed to create request: %w", err)
+	}
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("gcpsm: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return "", fmt.Errorf("gcpsm: %w: %s", stockal.ErrSecretNotFound, name)
+	}
+	if resp.StatusCode != http.StatusOK {
+		var body struct {
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		if json.Unmarshal(data, &body) == nil && body.Error.Message != "" {
+			return "", fmt.Errorf("gcpsm: %s: %s", name, body.Error.Message)
+		}
+		return "", fmt.Errorf("gcpsm: %s: status %d", name, resp.StatusCode)
+	}
+
+	var version struct {
+		Payload struct {
+			Data string `json:"data"`
+		} `json:"payload"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&version); err != nil {
+		return "", fmt.Errorf("gcpsm: failed to decode response: %w", err)
+	}
+	value, err := base64.StdEncoding.DecodeString(version.Payload.Data)
+	if err != nil {
+		return "", fmt.Errorf("gcpsm: %s: invalid payload: %w", name, err)
+	}
+	return string(value), nil
+}
@@ -0,0 +1,57 @@
+package gcpsm_test
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+	"github.com/adjaecent/unofficial-stockal-api/secrets/gcpsm"
+)
+
+func TestSecret(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.EscapedPath())
+		switch r.URL.Path {
+		case "/v1/projects/my-project/secrets/STOCKAL_PASSWORD/versions/latest:access",
+			"/v1/projects/my-project/secrets/STOCKAL_PASSWORD/versions/3:access":
+			fmt.Fprintf(w, `{"name":"...","payload":{"data":%q}}`, base64.StdEncoding.EncodeToString([]byte("hunter2")))
+		case "/v1/projects/my-project/secrets/STOCKAL_TOKEN/versions/latest:access":
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `{"error":{"code":403,"message":"Permission denied on secret STOCKAL_TOKEN"}}`)
+		case "/v1/projects/my-project/secrets/STOCKAL_BROKEN/versions/latest:access":
+			fmt.Fprint(w, `{"payload":{"data":"not base64!"}}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"error":{"code":404,"message":"Secret not found"}}`)
+		}
+	}))
+	defer server.Close()
+
+	p := gcpsm.New("my-project", server.Client(), gcpsm.WithBaseURL(server.URL+"/"))
+	if v, err := p.Secret(context.Background(), "STOCKAL_PASSWORD"); err != nil || v != "hunter2" {
+		t.Errorf("Secret = %q, %v; want the decoded payload", v, err)
+	}
+	if _, err := p.Secret(context.Background(), "STOCKAL_USERNAME"); !errors.Is(err, stockal.ErrSecretNotFound) {
+		t.Errorf("Secret of a missing secret = %v, want ErrSecretNotFound", err)
+	}
+	if _, err := p.Secret(context.Background(), "STOCKAL_TOKEN"); err == nil || err.Error() != "gcpsm: STOCKAL_TOKEN: Permission denied on secret STOCKAL_TOKEN" {
+		t.Errorf("Secret = %v, want the API's message", err)
+	}
+	if _, err := p.Secret(context.Background(), "STOCKAL_BROKEN"); err == nil {
+		t.Error("Secret accepted a payload that is not base64")
+	}
+
+	pinned := gcpsm.New("my-project", server.Client(), gcpsm.WithBaseURL(server.URL), gcpsm.WithVersion("3"))
+	if v, err := pinned.Secret(context.Background(), "STOCKAL_PASSWORD"); err != nil || v != "hunter2" {
+		t.Errorf("Secret of version 3 = %q, %v", v, err)
+	}
+	if last := paths[len(paths)-1]; last != "/v1/projects/my-project/secrets/STOCKAL_PASSWORD/versions/3:access" {
+		t.Errorf("requested %s, want version 3", last)
+	}
+}
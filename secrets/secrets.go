@@ -0,0 +1,103 @@
+// Package secrets selects the stockal.SecretsProvider the commands resolve
+// credentials, tokens and webhook secrets with, so that deployments never
+// keep passwords in configuration files.
+//
+// A backend is named by a spec, as given to the commands' secrets flag or
+// the STOCKAL_SECRETS environment variable:
+//
+//	env                          environment variables only (the default)
+//	file:/run/secrets            files named after the secrets in a directory
+//	aws-sm:stockal/              AWS Secrets Manager, secret "stockal/<name>"
+//	aws-sm-json:stockal          AWS Secrets Manager, keys of the JSON secret "stockal"
+//	gcp-sm:my-project            Google Cloud Secret Manager, latest version of "<name>"
+//
+// Environment variables take precedence over the backend, so a secret can be
+// overridden locally, and settings that are not secret, such as a username,
+// can stay in the environment.
+package secrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+	"github.com/adjaecent/unofficial-stockal-api/secrets/awssm"
+	"github.com/adjaecent/unofficial-stockal-api/secrets/gcpsm"
+)
+
+// EnvSpec is the environment variable holding the default spec.
+const EnvSpec = "STOCKAL_SECRETS"
+
+// Open returns the provider for spec: the environment, followed by the
+// backend spec names, if any.
+func Open(ctx context.Context, spec string) (stockal.SecretsProvider, error) {
+	kind, arg, _ := strings.Cut(spec, ":")
+	var backend stockal.SecretsProvider
+	switch kind {
+	case "", "env":
+		return stockal.EnvSecrets{}, nil
+	case "file":
+		if arg == "" {
+			return nil, errors.New("secrets: file: needs a directory")
+		}
+		backend = stockal.FileSecrets{Dir: arg}
+	case "aws-sm":
+		p, err := awssm.Open(ctx, awssm.WithPrefix(arg))
+		if err != nil {
+			return nil, err
+		}
+		backend = p
+	case "aws-sm-json":
+		if arg == "" {
+			return nil, errors.New("secrets: aws-sm-json: needs a secret name")
+		}
+		p, err := awssm.Open(ctx, awssm.WithJSON(arg))
+		if err != nil {
+			return nil, err
+		}
+		backend = p
+	case "gcp-sm":
+		if arg == "" {
+			return nil, errors.New("secrets: gcp-sm: needs a project")
+		}
+		p, err := gcpsm.Open(ctx, arg)
+		if err != nil {
+			return nil, err
+		}
+		backend = p
+	default:
+		return nil, fmt.Errorf("secrets: unknown backend %q (want env, file, aws-sm, aws-sm-json or gcp-sm)", kind)
+	}
+	return stockal.ChainSecrets(stockal.EnvSecrets{}, backend), nil
+}
+
+// Get returns the secret name from p, or "" if p does not have it.
+func Get(ctx context.Context, p stockal.SecretsProvider, name string) (string, error) {
+	v, err := p.Secret(ctx, name)
+	if errors.Is(err, stockal.ErrSecretNotFound) {
+		return "", nil
+	}
+	return v, err
+}
+
+// Lookup returns a function reading secrets from the backend spec names, or
+// from the environment, for commands that read their secrets once at
+// startup. It exits the program, as log.Fatal does, if the backend cannot be
+// opened or fails to read a secret.
+func Lookup(spec string) func(name string) string {
+	ctx := context.Background()
+	provider, err := Open(ctx, spec)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return func(name string) string {
+		v, err := Get(ctx, provider, name)
+		if err != nil {
+			log.Fatal(err)
+		}
+		return v
+	}
+}
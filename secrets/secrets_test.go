@@ -0,0 +1,100 @@
+package secrets_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+	"github.com/adjaecent/unofficial-stockal-api/secrets"
+)
+
+func TestOpen(t *testing.T) {
+	dir := t.TempDir()
+	for name, value := range map[string]string{"STOCKAL_USERNAME": "file-user", "STOCKAL_PASSWORD": "hunter2\n"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(value), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("STOCKAL_USERNAME", "alice")
+	t.Setenv("STOCKAL_PASSWORD", "")
+
+	tests := []struct {
+		spec     string
+		username string
+		password string
+	}{
+		{spec: "", username: "alice"},
+		{spec: "env", username: "alice"},
+		{spec: "file:" + dir, username: "alice", password: "hunter2"},
+	}
+	for _, tt := range tests {
+		p, err := secrets.Open(context.Background(), tt.spec)
+		if err != nil {
+			t.Fatalf("Open(%q): %v", tt.spec, err)
+		}
+		username, err := secrets.Get(context.Background(), p, "STOCKAL_USERNAME")
+		if err != nil || username != tt.username {
+			t.Errorf("%q: username %q, %v; want %q from the environment", tt.spec, username, err, tt.username)
+		}
+		password, err := secrets.Get(context.Background(), p, "STOCKAL_PASSWORD")
+		if err != nil || password != tt.password {
+			t.Errorf("%q: password %q, %v; want %q", tt.spec, password, err, tt.password)
+		}
+	}
+}
+
+func TestOpenFails(t *testing.T) {
+	for _, spec := range []string{"file", "file:", "aws-sm-json", "gcp-sm:", "vault:secret/stockal"} {
+		if _, err := secrets.Open(context.Background(), spec); err == nil {
+			t.Errorf("Open(%q) succeeded", spec)
+		}
+	}
+}
+
+// secretsFunc is a SecretsProvider calling a function.
+type secretsFunc func(name string) (string, error)
+
+func (f secretsFunc) Secret(ctx context.Context, name string) (string, error) { return f(name) }
+
+func TestGet(t *testing.T) {
+	errDenied := errors.New("access denied")
+	p := secretsFunc(func(name string) (string, error) {
+		switch name {
+		case "STOCKAL_PASSWORD":
+			return "hunter2", nil
+		case "STOCKAL_TOKEN":
+			return "", errDenied
+		}
+		return "", stockal.ErrSecretNotFound
+	})
+
+	if v, err := secrets.Get(context.Background(), p, "STOCKAL_PASSWORD"); err != nil || v != "hunter2" {
+		t.Errorf("Get = %q, %v; want the secret", v, err)
+	}
+	if v, err := secrets.Get(context.Background(), p, "STOCKAL_OTHER"); err != nil || v != "" {
+		t.Errorf("Get of a missing secret = %q, %v; want empty without an error", v, err)
+	}
+	if _, err := secrets.Get(context.Background(), p, "STOCKAL_TOKEN"); !errors.Is(err, errDenied) {
+		t.Errorf("Get = %v, want the provider's error", err)
+	}
+}
+
+func TestLookup(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "STOCKAL_PASSWORD"), []byte("hunter2"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("STOCKAL_PASSWORD", "")
+	t.Setenv("STOCKAL_TOKEN", "")
+
+	lookup := secrets.Lookup("file:" + dir)
+	if v := lookup("STOCKAL_PASSWORD"); v != "hunter2" {
+		t.Errorf("lookup = %q, want the file's secret", v)
+	}
+	if v := lookup("STOCKAL_TOKEN"); v != "" {
+		t.Errorf("lookup of a missing secret = %q, want empty", v)
+	}
+}
@@ -0,0 +1,85 @@
+package stockal_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+)
+
+func TestEnvSecrets(t *testing.T) {
+	t.Setenv("STOCKAL_PASSWORD", "hunter2")
+	t.Setenv("STOCKAL_TOKEN", "")
+
+	if v, err := (stockal.EnvSecrets{}).Secret(context.Background(), "STOCKAL_PASSWORD"); err != nil || v != "hunter2" {
+		t.Errorf("Secret = %q, %v; want the variable", v, err)
+	}
+	if _, err := (stockal.EnvSecrets{}).Secret(context.Background(), "STOCKAL_TOKEN"); !errors.Is(err, stockal.ErrSecretNotFound) {
+		t.Errorf("Secret of an empty variable = %v, want ErrSecretNotFound", err)
+	}
+}
+
+func TestFileSecrets(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "STOCKAL_PASSWORD"), []byte("hunter2\r\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	secrets := stockal.FileSecrets{Dir: dir}
+
+	if v, err := secrets.Secret(context.Background(), "STOCKAL_PASSWORD"); err != nil || v != "hunter2" {
+		t.Errorf("Secret = %q, %v; want the file without its newline", v, err)
+	}
+	if _, err := secrets.Secret(context.Background(), "STOCKAL_TOKEN"); !errors.Is(err, stockal.ErrSecretNotFound) {
+		t.Errorf("Secret of a missing file = %v, want ErrSecretNotFound", err)
+	}
+	for _, name := range []string{"", ".", "..", "../STOCKAL_PASSWORD", "sub/STOCKAL_PASSWORD"} {
+		if v, err := secrets.Secret(context.Background(), name); err == nil || errors.Is(err, stockal.ErrSecretNotFound) {
+			t.Errorf("Secret(%q) = %q, %v; want an invalid name error", name, v, err)
+		}
+	}
+}
+
+// secretsFunc is a SecretsProvider calling a function.
+type secretsFunc func(name string) (string, error)
+
+func (f secretsFunc) Secret(ctx context.Context, name string) (string, error) { return f(name) }
+
+func TestChainSecrets(t *testing.T) {
+	errDenied := errors.New("access denied")
+	local := secretsFunc(func(name string) (string, error) {
+		if name == "STOCKAL_USERNAME" {
+			return "alice", nil
+		}
+		return "", stockal.ErrSecretNotFound
+	})
+	remote := secretsFunc(func(name string) (string, error) {
+		switch name {
+		case "STOCKAL_USERNAME", "STOCKAL_PASSWORD":
+			return "remote-" + name, nil
+		case "STOCKAL_TOKEN":
+			return "", errDenied
+		}
+		return "", stockal.ErrSecretNotFound
+	})
+	chain := stockal.ChainSecrets(local, remote)
+
+	tests := []struct {
+		name  string
+		value string
+		err   error
+	}{
+		{name: "STOCKAL_USERNAME", value: "alice"},
+		{name: "STOCKAL_PASSWORD", value: "remote-STOCKAL_PASSWORD"},
+		{name: "STOCKAL_TOKEN", err: errDenied},
+		{name: "STOCKAL_OTHER", err: stockal.ErrSecretNotFound},
+	}
+	for _, tt := range tests {
+		v, err := chain.Secret(context.Background(), tt.name)
+		if v != tt.value || !errors.Is(err, tt.err) {
+			t.Errorf("Secret(%s) = %q, %v; want %q, %v", tt.name, v, err, tt.value, tt.err)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package stockal
+
+import "context"
+
+// service is the state the services share: the client whose transport and
+// session they use.
+type service struct {
+	client *Client
+}
+
+// AuthService logs in and out of Stockal and refreshes the session. Use it as
+// client.Auth().
+type AuthService service
+
+// AccountService reads account-level data. Use it as client.Account().
+type AccountService service
+
+// PortfolioService reads the holdings in the portfolio. Use it as
+// client.Portfolio().
+type PortfolioService service
+
+// OrdersService places, changes and lists orders. Use it as client.Orders().
+type OrdersService service
+
+var (
+	_ Authenticator    = (*AuthService)(nil)
+	_ AccountReader    = (*AccountService)(nil)
+	_ PortfolioQuerier = (*PortfolioService)(nil)
+	_ OrderManager     = (*OrdersService)(nil)
+)
+
+// Auth returns the service that logs in and out and refreshes the session.
+func (c *Client) Auth() Authenticator {
+	return (*AuthService)(&c.common)
+}
+
+// Account returns the service that reads the account summary.
+func (c *Client) Account() AccountReader {
+	return (*AccountService)(&c.common)
+}
+
+// Portfolio returns the service that reads the holdings.
+func (c *Client) Portfolio() PortfolioQuerier {
+	return (*PortfolioService)(&c.common)
+}
+
+// Orders returns the service that places, changes and lists orders.
+func (c *Client) Orders() OrderManager {
+	return (*OrdersService)(&c.common)
+}
+
+// Login, GetAccountSummary and GetPortfolioDetail were the first methods of
+// the Client, before the services. They remain for code written against them.
+
+// Login authenticates with Stockal.
+//
+// Deprecated: Use client.Auth().Login.
+func (c *Client) Login(ctx context.Context, username, password string) (*LoginResponse, error) {
+	return c.Auth().Login(ctx, username, password)
+}
+
+// GetAccountSummary retrieves a summary of the user's account.
+//
+// Deprecated: Use client.Account().Summary.
+func (c *Client) GetAccountSummary(ctx context.Context) (*AccountSummaryResponse, error) {
+	return c.Account().Summary(ctx)
+}
+
+// GetPortfolioDetail retrieves the holdings in the user's portfolio.
+//
+// Deprecated: Use client.Portfolio().Detail.
+func (c *Client) GetPortfolioDetail(ctx context.Context) (*PortfolioDetailResponse, error) {
+	return c.Portfolio().Detail(ctx)
+}
@@ -0,0 +1,55 @@
+package stockal_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+)
+
+func TestServicesShareSession(t *testing.T) {
+	authorizations := map[string]string{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		authorizations[r.URL.Path] = r.Header.Get("Authorization")
+		switch r.URL.Path {
+		case "/v3/auth/login":
+			w.Write([]byte(`{"code":200,"message":"Success","data":{"accessToken":"token","refreshToken":"refresh"}}`))
+		case "/v2/orders":
+			w.Write([]byte(`{"code":200,"message":"Success","data":{"orders":[],"totalRecords":0}}`))
+		default:
+			w.Write([]byte(`{"code":200,"message":"Success","data":null}`))
+		}
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	client := stockal.NewClient(stockal.WithBaseURL(srv.URL))
+	if _, err := client.Auth().Login(ctx, "alice", "secret"); err != nil {
+		t.Fatalf("Auth.Login: %v", err)
+	}
+	if _, err := client.Account().Summary(ctx); err != nil {
+		t.Fatalf("Account.Summary: %v", err)
+	}
+	if _, err := client.Portfolio().Detail(ctx); err != nil {
+		t.Fatalf("Portfolio.Detail: %v", err)
+	}
+	if _, err := client.Orders().List(ctx); err != nil {
+		t.Fatalf("Orders.List: %v", err)
+	}
+	for _, path := range []string{"/v2/users/accountSummary/summary", "/v2/users/portfolio/detail", "/v2/orders"} {
+		if authorizations[path] != "token" {
+			t.Errorf("%s sent Authorization %q, want the session from Auth.Login", path, authorizations[path])
+		}
+	}
+
+	if err := client.Auth().Logout(ctx); err != nil {
+		t.Fatalf("Auth.Logout: %v", err)
+	}
+	if _, err := client.Orders().List(ctx); !errors.Is(err, stockal.ErrNotAuthenticated) {
+		t.Errorf("Orders.List after Auth.Logout: error = %v, want ErrNotAuthenticated", err)
+	}
+}
@@ -0,0 +1,41 @@
+package stockal
+
+import (
+	"context"
+	"time"
+)
+
+// Snapshot is a point-in-time view of an account: its summary and holdings.
+type Snapshot struct {
+	// TakenAt is when the snapshot was taken
+	TakenAt time.Time `json:"takenAt"`
+	// Summary contains the account and portfolio summaries
+	Summary AccountSummaryData `json:"summary"`
+	// Holdings contains every holding in the portfolio
+	Holdings []Holding `json:"holdings"`
+}
+
+// IsEmpty reports whether the account had no holdings, cash or investments
+// when the snapshot was taken, as for a brand-new account.
+func (s *Snapshot) IsEmpty() bool {
+	return len(s.Holdings) == 0 && s.Summary.IsEmpty()
+}
+
+// TakeSnapshot fetches the account summary and portfolio detail and combines them
+// into a Snapshot.
+func TakeSnapshot(ctx context.Context, client PortfolioReader) (*Snapshot, error) {
+	summary, err := client.Account().Summary(ctx)
+	if err != nil {
+		return nil, err
+	}
+	portfolio, err := client.Portfolio().Detail(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Snapshot{
+		TakenAt:  time.Now().UTC(),
+		Summary:  summary.Data,
+		Holdings: portfolio.Data.Holdings,
+	}, nil
+}
@@ -0,0 +1,292 @@
+package stockal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// StackReader is implemented by clients that can read the stacks Stockal
+// offers: curated portfolios invested in as one.
+type StackReader interface {
+	GetStacks(ctx context.Context) (*StacksResponse, error)
+	GetStackDetail(ctx context.Context, stackID string) (*StackDetailResponse, error)
+}
+
+// StackTrader is implemented by clients that can invest in stacks and redeem
+// those investments.
+type StackTrader interface {
+	InvestInStack(ctx context.Context, stackID string, amount float64) (*StackOrderResponse, error)
+	RedeemStack(ctx context.Context, stackID string, opts RedeemOptions) (*StackOrderResponse, error)
+}
+
+// Stack represents a curated portfolio offered by Stockal.
+type Stack struct {
+	// ID is the stack identifier
+	ID string `json:"stackID"`
+	// Name is the stack's display name
+	Name string `json:"name"`
+	// Description is the stack's investment theme
+	Description string `json:"description,omitempty"`
+	// Creator is the manager who curates the stack
+	Creator string `json:"creator,omitempty"`
+	// MinimumInvestment is the smallest amount, in US dollars, that can be invested
+	MinimumInvestment float64 `json:"minimumInvestment"`
+	// Fees are the fees charged on investments in the stack
+	Fees StackFees `json:"fees"`
+	// CAGR is the compound annual growth rate since inception, in percent
+	CAGR float64 `json:"cagr"`
+	// Logo is the URL to the stack's logo image (optional)
+	Logo string `json:"logo,omitempty"`
+	// Extra holds the fields the API sent that this struct does not model
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// StackFees represents the fees charged on investments in a stack.
+type StackFees struct {
+	// ManagementFee is the yearly fee, in percent of the amount invested
+	ManagementFee float64 `json:"managementFee"`
+	// TransactionFee is the fee, in US dollars, charged on each investment or redemption
+	TransactionFee float64 `json:"transactionFee"`
+}
+
+// StackConstituent represents one security in a stack.
+type StackConstituent struct {
+	// Symbol is the stock symbol (e.g., "AAPL")
+	Symbol string `json:"symbol"`
+	// Company is the full company or fund name
+	Company string `json:"company,omitempty"`
+	// Weight is the share of the stack held in the security, in percent
+	Weight float64 `json:"weight"`
+}
+
+// StackPerformance represents the value of a stack at one time.
+type StackPerformance struct {
+	// Date is when the stack had the value
+	Date Time `json:"date"`
+	// Value is the value of 100 US dollars invested at inception
+	Value float64 `json:"value"`
+}
+
+// StackDetail represents a stack with its composition and history.
+type StackDetail struct {
+	// Stack is the stack as listed
+	Stack Stack `json:"stack"`
+	// Composition contains the securities in the stack, largest weight first
+	Composition []StackConstituent `json:"composition"`
+	// Performance contains the stack's historical values, oldest first
+	Performance []StackPerformance `json:"performance"`
+	// RebalancedAt is when the composition last changed
+	RebalancedAt Time `json:"rebalancedAt,omitzero"`
+	// Extra holds the fields the API sent that this struct does not model
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// Return returns the stack's return over its performance history, in percent,
+// or zero if the history has fewer than two values.
+func (d StackDetail) Return() float64 {
+	if len(d.Performance) < 2 || d.Performance[0].Value == 0 {
+		return 0
+	}
+	first, last := d.Performance[0].Value, d.Performance[len(d.Performance)-1].Value
+	return (last - first) / first * 100
+}
+
+// StacksResponse represents the response from the stack list API.
+type StacksResponse struct {
+	// Code is the HTTP response code
+	Code int `json:"code"`
+	// Message is the response message (usually "Success")
+	Message string `json:"message"`
+	// Data contains the stacks on offer
+	Data []Stack `json:"data"`
+}
+
+func (r *StacksResponse) normalize() {
+	if r.Data == nil {
+		r.Data = []Stack{}
+	}
+}
+
+// StackDetailResponse represents the response from the stack detail API.
+type StackDetailResponse struct {
+	// Code is the HTTP response code
+	Code int `json:"code"`
+	// Message is the response message (usually "Success")
+	Message string `json:"message"`
+	// Data contains the stack
+	Data StackDetail `json:"data"`
+}
+
+func (r *StackDetailResponse) normalize() {
+	if r.Data.Composition == nil {
+		r.Data.Composition = []StackConstituent{}
+	}
+	if r.Data.Performance == nil {
+		r.Data.Performance = []StackPerformance{}
+	}
+}
+
+func (r *StackDetailResponse) validate() []string {
+	if r.Data.Stack.ID == "" {
+		return []string{"missing stack ID"}
+	}
+	return nil
+}
+
+// GetStacks retrieves the stacks on offer, with their fees, minimum
+// investment and growth. Use GetStackDetail for a stack's composition.
+//
+// Example:
+//
+//	stacks, err := client.GetStacks(ctx)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	for _, s := range stacks.Data {
+//		fmt.Printf("%-30s min $%.0f, %.2f%%/yr\n", s.Name, s.MinimumInvestment, s.Fees.ManagementFee)
+//	}
+func (c *Client) GetStacks(ctx context.Context) (*StacksResponse, error) {
+	var stacksResp StacksResponse
+	if err := c.do(ctx, "GET", "/v2/stacks", nil, &stacksResp, "list stacks"); err != nil {
+		return nil, err
+	}
+	return &stacksResp, nil
+}
+
+// GetStackDetail retrieves a stack with the securities it holds and its
+// historical performance.
+func (c *Client) GetStackDetail(ctx context.Context, stackID string) (*StackDetailResponse, error) {
+	if stackID == "" {
+		return nil, fmt.Errorf("%w: a stack ID is required", ErrInvalidParams)
+	}
+
+	var detailResp StackDetailResponse
+	if err := c.do(ctx, "GET", "/v2/stacks/"+url.PathEscape(stackID), nil, &detailResp, "stack detail"); err != nil {
+		return nil, err
+	}
+	return &detailResp, nil
+}
+
+// stackInvestRequest represents the request payload for investing in a stack.
+type stackInvestRequest struct {
+	// Amount is the amount to invest, in US dollars
+	Amount float64 `json:"amount"`
+}
+
+// RedeemOptions represents the request payload for redeeming a stack
+// investment. Exactly one of the amount and all must be set.
+type RedeemOptions struct {
+	// Amount is the amount to redeem, in US dollars
+	Amount float64 `json:"amount,omitempty"`
+	// All redeems the whole investment in the stack
+	All bool `json:"all,omitempty"`
+}
+
+// Validate checks the options before they are sent. The returned error wraps
+// ErrInvalidOrder.
+func (o RedeemOptions) Validate() error {
+	switch {
+	case o.Amount < 0:
+		return fmt.Errorf("%w: negative amount %g", ErrInvalidOrder, o.Amount)
+	case o.All && o.Amount > 0:
+		return fmt.Errorf("%w: both an amount and all given", ErrInvalidOrder)
+	case !o.All && !(o.Amount > 0):
+		return fmt.Errorf("%w: an amount or all is required", ErrInvalidOrder)
+	}
+	return nil
+}
+
+// StackOrder represents an investment in or redemption of a stack and its
+// execution state.
+type StackOrder struct {
+	// ID is the order identifier
+	ID string `json:"orderID"`
+	// StackID is the stack invested in or redeemed
+	StackID string `json:"stackID"`
+	// Side is buy for investments and sell for redemptions
+	Side OrderSide `json:"side"`
+	// Amount is the amount invested or redeemed, in US dollars
+	Amount float64 `json:"amount"`
+	// Status is the execution status of the order
+	Status OrderStatus `json:"status"`
+	// CreatedAt is when the order was placed
+	CreatedAt Time `json:"createdAt,omitzero"`
+	// Extra holds the fields the API sent that this struct does not model
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// StackOrderResponse represents the response from the stack invest and
+// redeem APIs.
+type StackOrderResponse struct {
+	// Code is the HTTP response code
+	Code int `json:"code"`
+	// Message is the response message (usually "Success")
+	Message string `json:"message"`
+	// Data contains the order
+	Data StackOrder `json:"data"`
+}
+
+func (r *StackOrderResponse) validate() []string {
+	if r.Data.ID == "" {
+		return []string{"missing order ID"}
+	}
+	return nil
+}
+
+// InvestInStack invests amount US dollars in a stack, spread across its
+// securities by their weights. Stockal rejects amounts below the stack's
+// MinimumInvestment, and the stack's TransactionFee is charged on top.
+//
+// Investments are real: they are executed against the account's cash.
+//
+// Example:
+//
+//	resp, err := client.InvestInStack(ctx, stackID, 500)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	fmt.Println("order", resp.Data.ID, resp.Data.Status)
+func (c *Client) InvestInStack(ctx context.Context, stackID string, amount float64) (*StackOrderResponse, error) {
+	var problems []string
+	if stackID == "" {
+		problems = append(problems, "stack ID is required")
+	}
+	if !(amount > 0) {
+		problems = append(problems, "amount must be positive")
+	}
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidOrder, strings.Join(problems, "; "))
+	}
+
+	var orderResp StackOrderResponse
+	endpoint := "/v2/stacks/" + url.PathEscape(stackID) + "/invest"
+	if err := c.do(ctx, "POST", endpoint, stackInvestRequest{Amount: amount}, &orderResp, "invest in stack"); err != nil {
+		return nil, err
+	}
+	return &orderResp, nil
+}
+
+// RedeemStack sells part or all of the account's investment in a stack, as
+// opts selects. The options are validated before they are sent.
+//
+// Example:
+//
+//	resp, err := client.RedeemStack(ctx, stackID, stockal.RedeemOptions{All: true})
+func (c *Client) RedeemStack(ctx context.Context, stackID string, opts RedeemOptions) (*StackOrderResponse, error) {
+	if stackID == "" {
+		return nil, fmt.Errorf("%w: stack ID is required", ErrInvalidOrder)
+	}
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	var orderResp StackOrderResponse
+	endpoint := "/v2/stacks/" + url.PathEscape(stackID) + "/redeem"
+	if err := c.do(ctx, "POST", endpoint, opts, &orderResp, "redeem stack"); err != nil {
+		return nil, err
+	}
+	return &orderResp, nil
+}
@@ -0,0 +1,113 @@
+package stockal_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+)
+
+func TestGetStacks(t *testing.T) {
+	client := newTestClient(t, map[string]string{
+		"/v2/stacks": `{"code":200,"message":"Success","data":[{"stackID":"s1","name":"Tech Titans","minimumInvestment":100,` +
+			`"fees":{"managementFee":1.5,"transactionFee":0},"cagr":18.2,"riskLevel":"high"}]}`,
+		"/v2/stacks/s1": `{"code":200,"message":"Success","data":{"stack":{"stackID":"s1","name":"Tech Titans","minimumInvestment":100},` +
+			`"composition":[{"symbol":"AAPL","weight":60},{"symbol":"MSFT","weight":40}],` +
+			`"performance":[{"date":"2023-01-01","value":100},{"date":"2024-01-01","value":125}],` +
+			`"rebalancedAt":"2024-03-31T00:00:00Z","benchmark":"QQQ"}}`,
+	})
+
+	stacks, err := client.GetStacks(context.Background())
+	if err != nil {
+		t.Fatalf("GetStacks: %v", err)
+	}
+	if len(stacks.Data) != 1 {
+		t.Fatalf("got %+v", stacks.Data)
+	}
+	s := stacks.Data[0]
+	if s.ID != "s1" || s.MinimumInvestment != 100 || s.Fees.ManagementFee != 1.5 || string(s.Extra["riskLevel"]) != `"high"` {
+		t.Errorf("got %+v", s)
+	}
+
+	detail, err := client.GetStackDetail(context.Background(), "s1")
+	if err != nil {
+		t.Fatalf("GetStackDetail: %v", err)
+	}
+	d := detail.Data
+	if d.Stack.Name != "Tech Titans" || len(d.Composition) != 2 || d.Composition[1].Weight != 40 || string(d.Extra["benchmark"]) != `"QQQ"` {
+		t.Errorf("got %+v", d)
+	}
+	if math.Abs(d.Return()-25) > 1e-9 || !d.Performance[1].Date.Equal(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Return() = %v, performance %+v", d.Return(), d.Performance)
+	}
+	if !d.RebalancedAt.Time.Equal(time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("RebalancedAt = %v", d.RebalancedAt.Time)
+	}
+
+	if _, err := client.GetStackDetail(context.Background(), ""); !errors.Is(err, stockal.ErrInvalidParams) {
+		t.Errorf("GetStackDetail without an ID: error = %v, want ErrInvalidParams", err)
+	}
+}
+
+func TestStackOrders(t *testing.T) {
+	var requests []string
+	client := newHandlerClient(t, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests = append(requests, r.Method+" "+r.URL.Path+" "+string(body))
+		side := "buy"
+		if r.URL.Path == "/v2/stacks/s1/redeem" {
+			side = "sell"
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"code":200,"message":"Success","data":{"orderID":"so1","stackID":"s1","side":"` + side +
+			`","amount":500,"status":"New","createdAt":"2024-03-01T14:30:00Z"}}`))
+	})
+	ctx := context.Background()
+
+	resp, err := client.InvestInStack(ctx, "s1", 500)
+	if err != nil {
+		t.Fatalf("InvestInStack: %v", err)
+	}
+	order := resp.Data
+	if order.ID != "so1" || order.Side != stockal.OrderSideBuy || order.Status != stockal.OrderStatusNew ||
+		!order.CreatedAt.Time.Equal(time.Date(2024, 3, 1, 14, 30, 0, 0, time.UTC)) {
+		t.Errorf("InvestInStack = %+v", order)
+	}
+	if resp, err := client.RedeemStack(ctx, "s1", stockal.RedeemOptions{Amount: 200}); err != nil || resp.Data.Side != stockal.OrderSideSell {
+		t.Errorf("RedeemStack = %+v, %v", resp, err)
+	}
+	if _, err := client.RedeemStack(ctx, "s1", stockal.RedeemOptions{All: true}); err != nil {
+		t.Errorf("RedeemStack(all): %v", err)
+	}
+	want := []string{
+		`POST /v2/stacks/s1/invest {"amount":500}`,
+		`POST /v2/stacks/s1/redeem {"amount":200}`,
+		`POST /v2/stacks/s1/redeem {"all":true}`,
+	}
+	if !slices.Equal(requests, want) {
+		t.Errorf("sent %q, want %q", requests, want)
+	}
+
+	requests = nil
+	for name, err := range map[string]error{
+		"InvestInStack without an ID":      second(client.InvestInStack(ctx, "", 500)),
+		"InvestInStack of nothing":         second(client.InvestInStack(ctx, "s1", 0)),
+		"InvestInStack of NaN":             second(client.InvestInStack(ctx, "s1", math.NaN())),
+		"RedeemStack of nothing":           second(client.RedeemStack(ctx, "s1", stockal.RedeemOptions{})),
+		"RedeemStack of an amount and all": second(client.RedeemStack(ctx, "s1", stockal.RedeemOptions{Amount: 1, All: true})),
+		"RedeemStack of a negative amount": second(client.RedeemStack(ctx, "s1", stockal.RedeemOptions{Amount: -1})),
+	} {
+		if !errors.Is(err, stockal.ErrInvalidOrder) {
+			t.Errorf("%s: error = %v, want ErrInvalidOrder", name, err)
+		}
+	}
+	if len(requests) > 0 {
+		t.Errorf("invalid orders sent %q", requests)
+	}
+}
@@ -0,0 +1,137 @@
+package stockal
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// AccountFetcher is implemented by clients that can fetch the whole state of
+// an account at once.
+type AccountFetcher interface {
+	FetchAll(ctx context.Context, opts ...FetchOption) (*AccountState, error)
+}
+
+var _ AccountFetcher = (*Client)(nil)
+
+// AccountState combines the account summary, portfolio detail and, if
+// requested, orders fetched by FetchAll.
+type AccountState struct {
+	// FetchedAt is when the requests were made
+	FetchedAt time.Time `json:"fetchedAt"`
+	// Summary contains the account and portfolio summaries
+	Summary AccountSummaryData `json:"summary"`
+	// Holdings contains every holding in the portfolio
+	Holdings []Holding `json:"holdings"`
+	// Orders contains the open and recent orders, or nil if they were not requested
+	Orders []Order `json:"orders,omitempty"`
+}
+
+// Snapshot returns the summary and holdings as a Snapshot.
+func (s *AccountState) Snapshot() *Snapshot {
+	return &Snapshot{TakenAt: s.FetchedAt, Summary: s.Summary, Holdings: s.Holdings}
+}
+
+// FetchOption configures FetchAll.
+type FetchOption func(*fetchConfig)
+
+type fetchConfig struct {
+	orders bool
+}
+
+// IncludeOrders makes FetchAll fetch the open and recent orders as well.
+func IncludeOrders() FetchOption {
+	return func(c *fetchConfig) {
+		c.orders = true
+	}
+}
+
+// FetchAll fetches the account summary and portfolio detail, and the orders
+// with IncludeOrders, concurrently, taking about as long as the slowest of
+// the requests rather than their sum. It fails if any request fails. If ctx
+// is cancelled first, it returns the parts fetched so far along with a
+// *PartialError naming them.
+//
+// Example:
+//
+//	state, err := client.FetchAll(ctx, stockal.IncludeOrders())
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	fmt.Printf("%d holdings, %d orders\n", len(state.Holdings), len(state.Orders))
+func (c *Client) FetchAll(ctx context.Context, opts ...FetchOption) (*AccountState, error) {
+	var cfg fetchConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	parts := []string{"account summary", "portfolio detail"}
+	if cfg.orders {
+		parts = append(parts, "orders")
+	}
+	var (
+		mu        sync.Mutex
+		completed []string
+	)
+	complete := func(part string) {
+		mu.Lock()
+		defer mu.Unlock()
+		completed = append(completed, part)
+	}
+	state := &AccountState{FetchedAt: time.Now().UTC()}
+	partial := func() (*AccountState, error) {
+		remaining := slices.DeleteFunc(slices.Clone(parts), func(part string) bool {
+			return slices.Contains(completed, part)
+		})
+		return state, stopped(ctx, "fetch account", completed, remaining)
+	}
+
+	// Load or refresh the session once, rather than in every request
+	if err := c.authenticate(ctx); err != nil {
+		if ctx.Err() != nil {
+			return partial()
+		}
+		return nil, err
+	}
+
+	g, gctx := errgroup.WithContext(ctx)
+	g.Go(func() error {
+		summary, err := c.Account().Summary(gctx)
+		if err != nil {
+			return err
+		}
+		state.Summary = summary.Data
+		complete(parts[0])
+		return nil
+	})
+	g.Go(func() error {
+		portfolio, err := c.Portfolio().Detail(gctx)
+		if err != nil {
+			return err
+		}
+		state.Holdings = portfolio.Data.Holdings
+		complete(parts[1])
+		return nil
+	})
+	if cfg.orders {
+		g.Go(func() error {
+			orders, err := c.Orders().List(gctx)
+			if err != nil {
+				return err
+			}
+			state.Orders = orders.Data.Orders
+			complete(parts[2])
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		if ctx.Err() != nil {
+			return partial()
+		}
+		return nil, err
+	}
+	return state, nil
+}
@@ -0,0 +1,94 @@
+package stockal_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+)
+
+func TestFetchAll(t *testing.T) {
+	bodies := map[string]string{
+		"/v2/users/accountSummary/summary": `{"code":200,"message":"Success","data":{"accountSummary":{"cashBalance":25}}}`,
+		"/v2/users/portfolio/detail":       `{"code":200,"message":"Success","data":{"holdings":[{"symbol":"VOO"}],"totalRecords":1}}`,
+		"/v2/orders":                       `{"code":200,"message":"Success","data":{"orders":[{"orderID":"o1","symbol":"AAPL"}],"totalRecords":1}}`,
+	}
+	// Each request waits for the others, so the test only passes if they
+	// are made concurrently
+	var arrived sync.WaitGroup
+	arrived.Add(len(bodies))
+	client := newHandlerClient(t, func(w http.ResponseWriter, r *http.Request) {
+		arrived.Done()
+		done := make(chan struct{})
+		go func() { arrived.Wait(); close(done) }()
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			http.Error(w, "requests were not concurrent", http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(bodies[r.URL.Path]))
+	})
+
+	state, err := client.FetchAll(context.Background(), stockal.IncludeOrders())
+	if err != nil {
+		t.Fatalf("FetchAll: %v", err)
+	}
+	if state.Summary.AccountSummary.CashBalance != 25 || len(state.Holdings) != 1 || len(state.Orders) != 1 {
+		t.Errorf("got %+v", state)
+	}
+	if snapshot := state.Snapshot(); snapshot.IsEmpty() || !snapshot.TakenAt.Equal(state.FetchedAt) {
+		t.Errorf("Snapshot() = %+v", snapshot)
+	}
+}
+
+func TestFetchAllFails(t *testing.T) {
+	client := newHandlerClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/v2/users/portfolio/detail" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"code":401,"message":"Unauthorized"}`))
+			return
+		}
+		w.Write([]byte(`{"code":200,"message":"Success","data":{}}`))
+	})
+
+	state, err := client.FetchAll(context.Background())
+	var apiErr *stockal.APIError
+	if !errors.As(err, &apiErr) || apiErr.Code != 401 || state != nil {
+		t.Errorf("FetchAll = %+v, %v; want a 401 APIError", state, err)
+	}
+}
+
+func TestFetchAllCancelled(t *testing.T) {
+	// The summary arrives, then the call is cancelled while the portfolio
+	// detail is outstanding
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	client := newHandlerClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v2/users/portfolio/detail" {
+			<-r.Context().Done()
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"code":200,"message":"Success","data":{"accountSummary":{"cashBalance":25}}}`))
+		go func() { time.Sleep(50 * time.Millisecond); cancel() }()
+	})
+
+	state, err := client.FetchAll(ctx)
+	var partial *stockal.PartialError
+	if !errors.As(err, &partial) || !errors.Is(err, context.Canceled) {
+		t.Fatalf("FetchAll error = %v, want a PartialError wrapping context.Canceled", err)
+	}
+	if state == nil || state.Summary.AccountSummary.CashBalance != 25 {
+		t.Errorf("got state %+v, want the summary", state)
+	}
+	if len(partial.Completed) != 1 || len(partial.Remaining) != 1 || partial.Remaining[0] != "portfolio detail" {
+		t.Errorf("got completed %q, remaining %q", partial.Completed, partial.Remaining)
+	}
+}
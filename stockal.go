@@ -2,60 +2,100 @@
 //
 // Stockal is a platform that allows trading in the US stock market. This library
 // provides a simple interface to interact with Stockal's REST API for authentication,
-// account management, and portfolio operations.
+// account management, portfolio operations, and order management.
 //
 // # Basic Usage
 //
 //	client := stockal.NewClient()
 //
 //	// Login to get access token
-//	resp, err := client.Login("username", "password")
+//	resp, err := client.Auth().Login(ctx, "username", "password")
 //	if err != nil {
 //		log.Fatal(err)
 //	}
 //
 //	// Get account summary
-//	summary, err := client.GetAccountSummary()
+//	summary, err := client.Account().Summary(ctx)
 //	if err != nil {
 //		log.Fatal(err)
 //	}
 //
 //	// Get portfolio details
-//	portfolio, err := client.GetPortfolioDetail()
+//	portfolio, err := client.Portfolio().Detail(ctx)
 //	if err != nil {
 //		log.Fatal(err)
 //	}
 //
+// Operations are grouped into services the Client returns: Auth, Account,
+// Portfolio and Orders. The rest are methods of the Client itself. The Client
+// also implements StockalClient and the smaller interfaces it is made of, for
+// code that should not depend on the concrete type.
+//
 // # Authentication
 //
 // All API calls except Login require authentication. The Client automatically
 // stores and includes the access token from a successful login in subsequent
-// requests.
+// requests, and refreshes it shortly before it expires.
+//
+// Sessions can be persisted across processes with WithTokenStore; the client
+// then resumes a saved session without credentials:
+//
+//	client := stockal.NewClient(
+//		stockal.WithTokenStore(keyring.New("my-app", "default")),
+//	)
+//
+// The keyring store is in package tokenstore/keyring, so that programs that
+// do not use it need not link the keyring libraries. NewFileTokenStore suits
+// machines without a keyring, such as servers running short-lived jobs, and
+// NewMemoryTokenStore shares a session between clients in one process. A
+// session obtained elsewhere can be resumed with WithAccessToken.
 //
 // # Error Handling
 //
 // All methods return detailed error information. Network errors, JSON parsing
 // errors, and API errors are wrapped with descriptive messages.
+//
+// Failure classes are exposed as typed errors for use with errors.Is and errors.As:
+// *APIError for error envelopes, *UpstreamError (ErrUpstreamUnavailable) for
+// non-JSON pages such as Cloudflare challenges, *RateLimitError for 429 responses,
+// and *MalformedResponseError (ErrMalformedResponse) for partial payloads.
+//
+// # WebAssembly
+//
+// The package builds for GOOS=js GOARCH=wasm, for dashboards running in a
+// browser. Requests then go through the browser's fetch API, so the API must
+// accept cross-origin requests from the page; in practice that means serving
+// the page behind a reverse proxy to the API and pointing WithBaseURL at it.
+// The OS keyring is unavailable there; use NewLocalStorageTokenStore instead
+// of the keyring store. See example/wasm.
 package stockal
 
 import (
 	"bytes"
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"log/slog"
+	"mime"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
+//go:generate go run ./internal/openapigen openapi.yaml docs/openapi.yaml
+
 // BaseURL is the base URL for the Stockal API v2.
 const (
 	BaseURL = "https://api-v2.stockal.com"
 	DefaultTimeout = 30 * time.Second
 	DefaultUserAgent = "unofficial-stockal-api/1.0"
+	DefaultOrigin = "https://globalinvesting.in"
 )
 
 // Common errors
@@ -64,37 +104,339 @@ var (
 	ErrInvalidCredentials = errors.New("invalid credentials")
 	ErrEmptyUsername = errors.New("username cannot be empty")
 	ErrEmptyPassword = errors.New("password cannot be empty")
+	ErrUpstreamUnavailable = errors.New("upstream unavailable: non-JSON response")
+	ErrMalformedResponse = errors.New("malformed response")
+	ErrLoginIncomplete = errors.New("login incomplete")
+)
+
+// Failure classes. Errors from API calls match these with errors.Is according
+// to the HTTP status or API error code they carry, whatever their type: an
+// *APIError, an *UpstreamError, a *RateLimitError or a *StatusError.
+var (
+	// ErrTokenExpired means the API rejected the session (401 Unauthorized), or
+	// the client could not refresh an expiring access token; log in again
+	ErrTokenExpired = errors.New("access token expired")
+	// ErrForbidden means the API refused the request (403 Forbidden)
+	ErrForbidden = errors.New("forbidden")
+	// ErrRateLimited means the API rejected the request for exceeding its rate limit (429 Too Many Requests)
+	ErrRateLimited = errors.New("rate limited")
+	// ErrServerError means the API failed to handle the request (5xx)
+	ErrServerError = errors.New("server error")
+	// ErrMaintenance means the API is down for maintenance (503 Service Unavailable, or a response saying so);
+	// such errors match ErrServerError as well
+	ErrMaintenance = errors.New("under maintenance")
 )
 
+// statusIs reports whether an HTTP status or API error code falls in the
+// failure class target.
+func statusIs(code int, target error) bool {
+	switch target {
+	case ErrTokenExpired:
+		return code == http.StatusUnauthorized
+	case ErrForbidden:
+		return code == http.StatusForbidden
+	case ErrRateLimited:
+		return code == http.StatusTooManyRequests
+	case ErrServerError:
+		return code >= 500 && code <= 599
+	case ErrMaintenance:
+		return code == http.StatusServiceUnavailable
+	}
+	return false
+}
+
+// mentionsMaintenance reports whether any of texts says the API is under
+// maintenance, as its error messages and maintenance pages do.
+func mentionsMaintenance(texts ...string) bool {
+	for _, text := range texts {
+		if strings.Contains(strings.ToLower(text), "maintenance") {
+			return true
+		}
+	}
+	return false
+}
+
+// maxBodyExcerpt is the maximum number of response body bytes kept in errors.
+const maxBodyExcerpt = 256
+
 // APIError represents an error response from the Stockal API.
 type APIError struct {
 	Code    int    `json:"code"`
 	Message string `json:"message"`
 	Err     string `json:"error,omitempty"`
+	// StatusCode is the HTTP status code of the response. It differs from
+	// Code when the API reports a failure with HTTP 200.
+	StatusCode int `json:"-"`
 }
 
 func (e *APIError) Error() string {
+	msg := fmt.Sprintf("API error %d: %s", e.Code, e.Message)
 	if e.Err != "" {
-		return fmt.Sprintf("API error %d: %s - %s", e.Code, e.Message, e.Err)
+		msg += " - " + e.Err
+	}
+	if e.StatusCode != 0 && e.StatusCode != e.Code {
+		msg += fmt.Sprintf(" (HTTP status %d)", e.StatusCode)
 	}
-	return fmt.Sprintf("API error %d: %s", e.Code, e.Message)
+	return msg
 }
 
-// StockalClient defines the interface for Stockal API operations.
-type StockalClient interface {
+// Is reports whether the error falls in the failure class target, by its API
+// error code or HTTP status, or for maintenance by its message.
+func (e *APIError) Is(target error) bool {
+	if (target == ErrMaintenance || target == ErrServerError) && mentionsMaintenance(e.Message, e.Err) {
+		return true
+	}
+	return statusIs(e.Code, target) || statusIs(e.StatusCode, target)
+}
+
+// UpstreamError is returned when the API responds with a non-JSON body, such as
+// a Cloudflare challenge or a maintenance page. It matches ErrUpstreamUnavailable
+// with errors.Is.
+type UpstreamError struct {
+	StatusCode  int
+	ContentType string
+	// Body is a short excerpt of the response body
+	Body string
+}
+
+func (e *UpstreamError) Error() string {
+	if e.Body != "" {
+		return fmt.Sprintf("%s (status %d, content type %q): %s", ErrUpstreamUnavailable, e.StatusCode, e.ContentType, e.Body)
+	}
+	return fmt.Sprintf("%s (status %d, content type %q)", ErrUpstreamUnavailable, e.StatusCode, e.ContentType)
+}
+
+func (e *UpstreamError) Unwrap() error {
+	return ErrUpstreamUnavailable
+}
+
+// Is reports whether the error falls in the failure class target, by its HTTP
+// status or, for maintenance, the page it carries.
+func (e *UpstreamError) Is(target error) bool {
+	if (target == ErrMaintenance || target == ErrServerError) && mentionsMaintenance(e.Body) {
+		return true
+	}
+	return statusIs(e.StatusCode, target)
+}
+
+// StatusError is returned when the API fails a call with an HTTP status and a
+// JSON body that carries no error code.
+type StatusError struct {
+	// Operation is the API operation that failed (e.g. "get quotes")
+	Operation string
+	// StatusCode is the HTTP status code of the response
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%s failed with status code: %d", e.Operation, e.StatusCode)
+}
+
+// Is reports whether the error falls in the failure class target, by its HTTP
+// status.
+func (e *StatusError) Is(target error) bool {
+	return statusIs(e.StatusCode, target)
+}
+
+// MalformedResponseError is returned when a successful response decodes but fails
+// validation, e.g. a partial payload. It matches ErrMalformedResponse with errors.Is.
+type MalformedResponseError struct {
+	// Operation is the API operation that returned the response (e.g. "login")
+	Operation string
+	// Problems describes each validation failure
+	Problems []string
+}
+
+func (e *MalformedResponseError) Error() string {
+	return fmt.Sprintf("%s %s: %s", e.Operation, ErrMalformedResponse, strings.Join(e.Problems, "; "))
+}
+
+func (e *MalformedResponseError) Unwrap() error {
+	return ErrMalformedResponse
+}
+
+// LoginIncompleteError is returned when a login or token refresh succeeds but
+// the response carries no access token, e.g. because the account needs a
+// one-time password. The client keeps its previous session. It matches
+// ErrLoginIncomplete with errors.Is.
+type LoginIncompleteError struct {
+	// Operation is the API operation that returned the response (e.g. "login")
+	Operation string
+	// Missing lists the session fields the response lacks, such as "access token"
+	Missing []string
+	// Message is the API's message, which may say what else it needs
+	Message string
+}
+
+func (e *LoginIncompleteError) Error() string {
+	msg := fmt.Sprintf("%s %s: missing %s", e.Operation, ErrLoginIncomplete, strings.Join(e.Missing, ", "))
+	if e.Message != "" {
+		msg += fmt.Sprintf(" (API message: %q)", e.Message)
+	}
+	return msg
+}
+
+func (e *LoginIncompleteError) Unwrap() error {
+	return ErrLoginIncomplete
+}
+
+// validator is implemented by response types that can check their own consistency.
+// validate returns a description of each problem found, or nil if the response is usable.
+type validator interface {
+	validate() []string
+}
+
+// normalizer is implemented by response types that replace the nulls the API
+// returns for empty lists, e.g. for brand-new accounts, with empty lists, so
+// that re-encoded responses and snapshots keep their shape.
+type normalizer interface {
+	normalize()
+}
+
+// RateLimitError is returned when the API responds with 429 Too Many Requests.
+// Limit, Remaining and Reset are populated from X-RateLimit-* headers when present.
+// It matches ErrRateLimited with errors.Is.
+type RateLimitError struct {
+	// RetryAfter is the server-requested wait before the next attempt (zero if not provided)
+	RetryAfter time.Duration
+	// Limit is the request quota for the current window (-1 if not provided)
+	Limit int
+	// Remaining is the number of requests left in the current window (-1 if not provided)
+	Remaining int
+	// Reset is when the current window resets (zero if not provided)
+	Reset time.Time
+}
+
+func (e *RateLimitError) Error() string {
+	if e.RetryAfter > 0 {
+		return fmt.Sprintf("rate limited: retry after %s", e.RetryAfter)
+	}
+	return "rate limited"
+}
+
+func (e *RateLimitError) Unwrap() error {
+	return ErrRateLimited
+}
+
+// Authenticator establishes and ends a session. AuthService implements it.
+type Authenticator interface {
 	Login(ctx context.Context, username, password string) (*LoginResponse, error)
-	GetAccountSummary(ctx context.Context) (*AccountSummaryResponse, error)
-	GetPortfolioDetail(ctx context.Context) (*PortfolioDetailResponse, error)
+	Refresh(ctx context.Context) (*LoginResponse, error)
+	Logout(ctx context.Context) error
+}
+
+// AccountReader reads account-level data. AccountService implements it.
+type AccountReader interface {
+	Summary(ctx context.Context) (*AccountSummaryResponse, error)
 }
 
+// PortfolioReader is implemented by clients that can read account and portfolio data.
+type PortfolioReader interface {
+	Account() AccountReader
+	Portfolio() PortfolioQuerier
+}
+
+// Caller is implemented by clients that can call API endpoints they have no
+// method for, such as those added by extensions (see package registry).
+type Caller interface {
+	Call(ctx context.Context, method, endpoint string, payload, result interface{}) error
+}
+
+// StockalClient defines the full set of Stockal API operations. Downstream code
+// should prefer depending on the smaller capability interfaces it actually uses,
+// so that new operations added here do not break its mocks.
+type StockalClient interface {
+	Auth() Authenticator
+	PortfolioReader
+	PortfolioStreamer
+	Orders() OrderManager
+	TransactionReader
+	DividendReader
+	MarketData
+	CandleReader
+	InstrumentSearcher
+	StackReader
+	StackTrader
+	DepositManager
+	WithdrawalManager
+	DocumentReader
+	TaxDocumentReader
+	WatchlistManager
+}
+
+var _ StockalClient = (*Client)(nil)
+var _ Caller = (*Client)(nil)
+
 // ClientOption is a function that configures a Client.
 type ClientOption func(*clientConfig)
 
 // clientConfig holds configuration for the client.
 type clientConfig struct {
-	baseURL    string
-	httpClient *http.Client
-	userAgent  string
+	baseURL       string
+	httpClient    *http.Client
+	userAgent     string
+	redirect      *RedirectPolicy
+	deadline      time.Duration
+	origin        string
+	tokenStore    TokenStore
+	// quoteFallback supplies quotes Stockal cannot
+	quoteFallback QuoteProvider
+	// rateLimitWait bounds the wait before retrying a rate-limited request
+	rateLimitWait time.Duration
+	rateLimitHook func(RateLimitEvent)
+	// retry is the policy for transient failures, or nil to not retry them
+	retry         *RetryPolicy
+	// logger receives a debug message for every request
+	logger        *slog.Logger
+	// rawHoldings leaves holdings unmerged and unsorted
+	rawHoldings   bool
+	// session is a session obtained elsewhere, set by WithAccessToken
+	session       *LoginData
+}
+
+// RedirectPolicy controls how the client follows HTTP redirects.
+//
+// The Authorization header is only forwarded when a redirect stays on the
+// original host or targets one of TrustedHosts; it is stripped everywhere else.
+type RedirectPolicy struct {
+	// MaxRedirects is the maximum number of redirects to follow; zero disables
+	// following, and the redirect response is handled like any other
+	MaxRedirects int
+	// TrustedHosts lists additional hosts (e.g. partner-brand API hosts) that may receive the Authorization header
+	TrustedHosts []string
+}
+
+// checkRedirect implements http.Client.CheckRedirect for the policy.
+func (p RedirectPolicy) checkRedirect(req *http.Request, via []*http.Request) error {
+	if p.MaxRedirects == 0 {
+		return http.ErrUseLastResponse
+	}
+	if len(via) > p.MaxRedirects {
+		return fmt.Errorf("stopped after %d redirects", p.MaxRedirects)
+	}
+
+	original := via[0]
+	if p.trusts(original.URL, req.URL) {
+		if auth := original.Header.Get("Authorization"); auth != "" {
+			req.Header.Set("Authorization", auth)
+		}
+	} else {
+		req.Header.Del("Authorization")
+	}
+	return nil
+}
+
+// trusts reports whether the Authorization header may follow a redirect from one URL to another.
+func (p RedirectPolicy) trusts(from, to *url.URL) bool {
+	if strings.EqualFold(from.Host, to.Host) {
+		return true
+	}
+	for _, host := range p.TrustedHosts {
+		if strings.EqualFold(host, to.Host) || strings.EqualFold(host, to.Hostname()) {
+			return true
+		}
+	}
+	return false
 }
 
 // WithBaseURL sets a custom base URL for the API.
@@ -118,6 +460,15 @@ func WithUserAgent(userAgent string) ClientOption {
 	}
 }
 
+// WithOrigin sets the web origin sent in the Origin and Referer headers.
+// Partner brands (tenants) of the Stockal platform are served from their own
+// origin; the default is DefaultOrigin.
+func WithOrigin(origin string) ClientOption {
+	return func(c *clientConfig) {
+		c.origin = strings.TrimSuffix(origin, "/")
+	}
+}
+
 // WithTimeout sets a custom timeout for requests.
 func WithTimeout(timeout time.Duration) ClientOption {
 	return func(c *clientConfig) {
@@ -128,12 +479,79 @@ func WithTimeout(timeout time.Duration) ClientOption {
 	}
 }
 
+// WithRedirectPolicy sets how redirects are followed and which hosts receive the
+// Authorization header. Without this option the HTTP client's own policy applies.
+func WithRedirectPolicy(policy RedirectPolicy) ClientOption {
+	return func(c *clientConfig) {
+		c.redirect = &policy
+	}
+}
+
+// WithQuoteFallback sets a provider used by GetQuotes for symbols Stockal has
+// no quote for, and for every symbol when Stockal fails or cannot be reached.
+//
+//	client := stockal.NewClient(stockal.WithQuoteFallback(yahoo.New()))
+func WithQuoteFallback(provider QuoteProvider) ClientOption {
+	return func(c *clientConfig) {
+		c.quoteFallback = provider
+	}
+}
+
+// WithAccessToken resumes a session obtained elsewhere, such as by another
+// process, instead of logging in. With a refresh token the client refreshes
+// the session when the access token expires, as far as it can tell when that
+// is from the token; without one, calls fail once it has. The session takes
+// precedence over one saved in the token store, but is not saved there until
+// it is refreshed.
+//
+//	client := stockal.NewClient(stockal.WithAccessToken(token, refreshToken))
+func WithAccessToken(accessToken, refreshToken string) ClientOption {
+	return func(c *clientConfig) {
+		c.session = &LoginData{AccessToken: accessToken, RefreshToken: refreshToken}
+	}
+}
+
+// WithDefaultDeadline sets a per-call deadline applied when the caller's context
+// has none, so calls made with context.Background() cannot hang indefinitely.
+// Contexts that already carry a deadline are left untouched.
+func WithDefaultDeadline(d time.Duration) ClientOption {
+	return func(c *clientConfig) {
+		c.deadline = d
+	}
+}
+
 // Client represents a Stockal API client with authentication and HTTP configuration.
+//
+// A Client is safe for concurrent use by multiple goroutines. Calls share one
+// session: when it must be loaded from the token store or refreshed, one call
+// does so while the others wait for it, and a Login or Logout takes effect for
+// the calls that start after it.
+//
+// Operations are grouped into services, such as client.Auth().Login and
+// client.Portfolio().Detail, which share the client's transport and session.
 type Client struct {
-	baseURL     string
-	httpClient  *http.Client
-	userAgent   string
-	accessToken string
+	// common is the service the others share
+	common        service
+	baseURL       string
+	httpClient    *http.Client
+	userAgent     string
+	// mu guards the session tokens
+	mu            sync.Mutex
+	accessToken   string
+	refreshToken  string
+	tokenExpiry   time.Time
+	// authMu serializes loading and refreshing the session
+	authMu        sync.Mutex
+	tokenStore    TokenStore
+	deadline      time.Duration
+	origin        string
+	quoteFallback QuoteProvider
+	rateLimitWait time.Duration
+	rateLimitHook func(RateLimitEvent)
+	retry         *RetryPolicy
+	retryBudget   *retryBudget
+	logger        *slog.Logger
+	rawHoldings   bool
 }
 
 // LoginRequest represents the request payload for user authentication.
@@ -151,9 +569,21 @@ type LoginData struct {
 	// RefreshToken is used to refresh the access token when it expires
 	RefreshToken          string `json:"refreshToken"`
 	// ExpiryAccessToken is the access token expiration time
-	ExpiryAccessToken     string `json:"expiryAccessToken"`
+	ExpiryAccessToken     Time   `json:"expiryAccessToken"`
 	// ExpiryRefreshToken is the refresh token expiration time
-	ExpiryRefreshToken    string `json:"expiryRefreshToken"`
+	ExpiryRefreshToken    Time   `json:"expiryRefreshToken"`
+}
+
+// AccessTokenExpiry returns when the access token expires, or the zero time
+// if the expiry is missing or not recognised.
+func (d LoginData) AccessTokenExpiry() time.Time {
+	return d.ExpiryAccessToken.Time
+}
+
+// RefreshTokenExpiry returns when the refresh token expires, or the zero time
+// if the expiry is missing or not recognised.
+func (d LoginData) RefreshTokenExpiry() time.Time {
+	return d.ExpiryRefreshToken.Time
 }
 
 // LoginResponse represents the response from the login API endpoint.
@@ -168,14 +598,35 @@ type LoginResponse struct {
 	Error   string    `json:"error,omitempty"`
 }
 
+// incomplete returns a *LoginIncompleteError if r has no access token.
+func (r *LoginResponse) incomplete(operation string) error {
+	if r.Data.AccessToken != "" {
+		return nil
+	}
+	missing := []string{"access token"}
+	if r.Data.RefreshToken == "" {
+		missing = append(missing, "refresh token")
+	}
+	if r.Data.ExpiryAccessToken.Raw == "" {
+		missing = append(missing, "access token expiry")
+	}
+	return &LoginIncompleteError{Operation: operation, Missing: missing, Message: r.Message}
+}
+
 // CashSettlement represents a scheduled cash settlement in the account.
 type CashSettlement struct {
 	// UTCTime is the settlement date and time in UTC
-	UTCTime string  `json:"utcTime"`
+	UTCTime Time    `json:"utcTime"`
 	// Cash is the amount to be settled
 	Cash    float64 `json:"cash"`
 }
 
+// Time returns when the settlement happens, from UTCTime, or the zero time if
+// the API did not say.
+func (s CashSettlement) Time() time.Time {
+	return s.UTCTime.Time
+}
+
 // AccountSummary represents the user's account summary including cash balances and restrictions.
 type AccountSummary struct {
 	// CashAvailableForTrade is the cash available for placing new trades
@@ -190,6 +641,8 @@ type AccountSummary struct {
 	Restricted                  bool             `json:"restricted"`
 	// CashSettlement contains scheduled cash settlements
 	CashSettlement              []CashSettlement `json:"cashSettlement"`
+	// Extra holds the fields the API sent that this struct does not model
+	Extra                       map[string]json.RawMessage `json:"-"`
 }
 
 // Portfolio represents a portfolio category with current value and investment amount.
@@ -214,16 +667,40 @@ type PortfolioSummary struct {
 	TotalInvestmentAmount float64   `json:"totalInvestmentAmount"`
 }
 
+// IsEmpty reports whether nothing is invested in any portfolio, as for a
+// brand-new account.
+func (s PortfolioSummary) IsEmpty() bool {
+	return s.TotalCurrentValue == 0 && s.TotalInvestmentAmount == 0 &&
+		s.StockPortfolio == Portfolio{} && s.StackPortfolio == Portfolio{} && s.ETFPortfolio == Portfolio{}
+}
+
+// TotalGain returns the gain, or loss if negative, across all portfolios:
+// their current value less the amount invested.
+func (s PortfolioSummary) TotalGain() float64 {
+	return s.TotalCurrentValue - s.TotalInvestmentAmount
+}
+
+// TotalGainPercent returns the total gain as a percentage of the amount
+// invested, or zero if nothing was invested.
+func (s PortfolioSummary) TotalGainPercent() float64 {
+	if s.TotalInvestmentAmount == 0 {
+		return 0
+	}
+	return s.TotalGain() / s.TotalInvestmentAmount * 100
+}
+
 // AccountSummaryData represents the data payload of an account summary response.
 type AccountSummaryData struct {
 	// UTCTime is the timestamp when the summary was generated
-	UTCTime          string           `json:"utcTime"`
+	UTCTime          Time             `json:"utcTime"`
 	// AccountSummary contains account-level information
 	AccountSummary   AccountSummary   `json:"accountSummary"`
 	// UnsettledAmount is the amount of unsettled funds
 	UnsettledAmount  float64          `json:"unsettledAmount"`
 	// PortfolioSummary contains portfolio-level summaries
 	PortfolioSummary PortfolioSummary `json:"portfolioSummary"`
+	// Extra holds the fields the API sent that this struct does not model
+	Extra            map[string]json.RawMessage `json:"-"`
 }
 
 // AccountSummaryResponse represents the complete response from the account summary API.
@@ -236,6 +713,26 @@ type AccountSummaryResponse struct {
 	Data    AccountSummaryData `json:"data"`
 }
 
+// IsEmpty reports whether the account holds no cash and no investments, as a
+// brand-new account does.
+func (d AccountSummaryData) IsEmpty() bool {
+	a := d.AccountSummary
+	return a.CashBalance == 0 && a.CashAvailableForTrade == 0 && a.CashAvailableForWithdrawal == 0 &&
+		len(a.CashSettlement) == 0 && d.UnsettledAmount == 0 && d.PortfolioSummary.IsEmpty()
+}
+
+// AsOf returns when the summary was generated, from UTCTime, or the zero
+// time if the API did not say.
+func (d AccountSummaryData) AsOf() time.Time {
+	return d.UTCTime.Time
+}
+
+func (r *AccountSummaryResponse) normalize() {
+	if r.Data.AccountSummary.CashSettlement == nil {
+		r.Data.AccountSummary.CashSettlement = []CashSettlement{}
+	}
+}
+
 // Holding represents a single stock or asset holding in the portfolio.
 type Holding struct {
 	// Symbol is the stock symbol (e.g., "AAPL")
@@ -245,13 +742,13 @@ type Holding struct {
 	// UserID is the user's unique identifier
 	UserID           string  `json:"userID"`
 	// Date is the last update date for this holding
-	Date             string  `json:"Date"`
+	Date             Time    `json:"Date"`
 	// V is the version field from MongoDB
 	V                int     `json:"__v"`
 	// Category is the asset category (e.g., "stock")
-	Category         string  `json:"category"`
+	Category         Category `json:"category"`
 	// Status is the holding status (e.g., "successful")
-	Status           string  `json:"status"`
+	Status           HoldingStatus `json:"status"`
 	// Timestamp is the Unix timestamp of the last update
 	Timestamp        int64   `json:"timestamp"`
 	// TotalInvestment is the total amount invested in this holding
@@ -259,7 +756,7 @@ type Holding struct {
 	// TotalUnit is the number of shares/units owned
 	TotalUnit        float64 `json:"totalUnit"`
 	// Type is the asset type (e.g., "stock")
-	Type             string  `json:"type"`
+	Type             AssetType `json:"type"`
 	// Code is the asset code
 	Code             string  `json:"code"`
 	// Company is the full company name
@@ -276,6 +773,8 @@ type Holding struct {
 	Logo             string  `json:"logo,omitempty"`
 	// SellOnly indicates if only sell operations are allowed (optional)
 	SellOnly         bool    `json:"sellOnly,omitempty"`
+	// Extra holds the fields the API sent that this struct does not model
+	Extra            map[string]json.RawMessage `json:"-"`
 }
 
 // PortfolioDetailData represents the data payload of a portfolio detail response.
@@ -288,6 +787,20 @@ type PortfolioDetailData struct {
 	Timestamp    int64         `json:"timestamp"`
 	// TotalRecords is the total number of holdings
 	TotalRecords int           `json:"totalRecords"`
+	// Extra holds the fields the API sent that this struct does not model
+	Extra        map[string]json.RawMessage `json:"-"`
+}
+
+// IsEmpty reports whether the portfolio has no holdings and nothing pending,
+// as for a brand-new account.
+func (d PortfolioDetailData) IsEmpty() bool {
+	return len(d.Holdings) == 0 && len(d.PendingData) == 0
+}
+
+// AsOf returns when the portfolio data was generated, from Timestamp, or the
+// zero time if the API did not say.
+func (d PortfolioDetailData) AsOf() time.Time {
+	return unixTime(d.Timestamp)
 }
 
 // PortfolioDetailResponse represents the complete response from the portfolio detail API.
@@ -298,6 +811,32 @@ type PortfolioDetailResponse struct {
 	Message string              `json:"message"`
 	// Data contains the actual portfolio detail data
 	Data    PortfolioDetailData `json:"data"`
+
+	// partial is set when the request asked for part of the portfolio
+	partial bool
+}
+
+func (r *PortfolioDetailResponse) normalize() {
+	if r.Data.Holdings == nil {
+		r.Data.Holdings = []Holding{}
+	}
+	if r.Data.PendingData == nil {
+		r.Data.PendingData = []interface{}{}
+	}
+}
+
+func (r *PortfolioDetailResponse) validate() []string {
+	var problems []string
+	// Part of the portfolio holds at most all the holdings; otherwise it must hold exactly them
+	if n := len(r.Data.Holdings); n > r.Data.TotalRecords || !r.partial && n != r.Data.TotalRecords {
+		problems = append(problems, fmt.Sprintf("totalRecords is %d but %d holdings were returned", r.Data.TotalRecords, len(r.Data.Holdings)))
+	}
+	for i, holding := range r.Data.Holdings {
+		if holding.Symbol == "" {
+			problems = append(problems, fmt.Sprintf("holding %d has no symbol", i))
+		}
+	}
+	return problems
 }
 
 // NewClient creates a new Stockal API client with the given options.
@@ -306,11 +845,14 @@ type PortfolioDetailResponse struct {
 //   - BaseURL: Official Stockal API endpoint
 //   - Timeout: 30 seconds
 //   - UserAgent: unofficial-stockal-api/1.0
+//   - Origin: https://globalinvesting.in
+//   - Rate-limited requests retried once after up to 30 seconds
+//   - Other failures not retried (see WithRetry)
 //
 // Example:
 //
 //	client := stockal.NewClient()
-//	resp, err := client.Login(ctx, "username", "password")
+//	resp, err := client.Auth().Login(ctx, "username", "password")
 //
 // With custom options:
 //
@@ -318,10 +860,12 @@ type PortfolioDetailResponse struct {
 //		stockal.WithTimeout(60*time.Second),
 //		stockal.WithUserAgent("my-app/1.0"),
 //	)
-func NewClient(options ...ClientOption) StockalClient {
+func NewClient(options ...ClientOption) *Client {
 	config := &clientConfig{
-		baseURL:   BaseURL,
-		userAgent: DefaultUserAgent,
+		baseURL:       BaseURL,
+		userAgent:     DefaultUserAgent,
+		origin:        DefaultOrigin,
+		rateLimitWait: DefaultRateLimitWait,
 		httpClient: &http.Client{
 			Timeout: DefaultTimeout,
 		},
@@ -331,25 +875,69 @@ func NewClient(options ...ClientOption) StockalClient {
 		option(config)
 	}
 
-	return &Client{
-		baseURL:    config.baseURL,
-		httpClient: config.httpClient,
-		userAgent:  config.userAgent,
+	if config.redirect != nil {
+		// Copy so a caller-supplied http.Client is not modified
+		httpClient := *config.httpClient
+		httpClient.CheckRedirect = config.redirect.checkRedirect
+		config.httpClient = &httpClient
+	}
+
+	c := &Client{
+		baseURL:       config.baseURL,
+		httpClient:    config.httpClient,
+		userAgent:     config.userAgent,
+		deadline:      config.deadline,
+		origin:        config.origin,
+		tokenStore:    config.tokenStore,
+		quoteFallback: config.quoteFallback,
+		rateLimitWait: config.rateLimitWait,
+		rateLimitHook: config.rateLimitHook,
+		rawHoldings:   config.rawHoldings,
+		logger:        config.logger,
 	}
+	if config.retry != nil {
+		c.retry = config.retry
+		c.retryBudget = &retryBudget{limit: config.retry.Budget}
+	}
+	c.common.client = c
+	if config.session != nil {
+		c.restoreSession(*config.session)
+	}
+	return c
+}
+
+// withDeadline applies the client's default deadline to ctx if it has none.
+// The returned cancel function must always be called.
+func (c *Client) withDeadline(ctx context.Context) (context.Context, context.CancelFunc) {
+	if c.deadline <= 0 {
+		return ctx, func() {}
+	}
+	if _, ok := ctx.Deadline(); ok {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, c.deadline)
 }
 
 // makeRequest is an internal helper method that handles HTTP request creation and execution.
 // It automatically adds all necessary headers including authentication and browser simulation.
 func (c *Client) makeRequest(ctx context.Context, method, endpoint string, payload interface{}) (*http.Response, error) {
-	// Validate URL
-	apiURL, err := url.JoinPath(c.baseURL, endpoint)
+	// Validate URL; endpoint may carry a query string
+	ref, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid endpoint URL: %w", err)
+	}
+	apiURL, err := url.JoinPath(c.baseURL, ref.Path)
 	if err != nil {
 		return nil, fmt.Errorf("invalid endpoint URL: %w", err)
 	}
+	if ref.RawQuery != "" {
+		apiURL += "?" + ref.RawQuery
+	}
 
 	var body io.Reader
+	var jsonData []byte
 	if payload != nil {
-		jsonData, err := json.Marshal(payload)
+		jsonData, err = json.Marshal(payload)
 		if err != nil {
 			return nil, fmt.Errorf("failed to marshal request body: %w", err)
 		}
@@ -362,32 +950,76 @@ func (c *Client) makeRequest(ctx context.Context, method, endpoint string, paylo
 	}
 
 	// Set headers similar to browser request
-	req.Header.Set("User-Agent", c.userAgent)
 	req.Header.Set("Accept", "application/json, text/plain, */*")
 	req.Header.Set("Accept-Language", "en-US,en;q=0.5")
 	// Removed Accept-Encoding to avoid compression issues
 	if payload != nil {
 		req.Header.Set("Content-Type", "application/json")
 	}
-	req.Header.Set("Origin", "https://globalinvesting.in")
-	req.Header.Set("Referer", "https://globalinvesting.in/")
-	req.Header.Set("Sec-Fetch-Dest", "empty")
-	req.Header.Set("Sec-Fetch-Mode", "cors")
-	req.Header.Set("Sec-Fetch-Site", "cross-site")
+	// In a browser, fetch sets these itself and refuses to override them
+	if !inBrowser {
+		req.Header.Set("User-Agent", c.userAgent)
+		req.Header.Set("Origin", c.origin)
+		req.Header.Set("Referer", c.origin+"/")
+		req.Header.Set("Sec-Fetch-Dest", "empty")
+		req.Header.Set("Sec-Fetch-Mode", "cors")
+		req.Header.Set("Sec-Fetch-Site", "cross-site")
+	}
 	req.Header.Set("Cache-Control", "no-cache")
 	req.Header.Set("Pragma", "no-cache")
 
 	// Add Authorization header if access token is available
-	if c.accessToken != "" {
-		req.Header.Set("Authorization", c.accessToken)
+	accessToken, refreshToken, _ := c.tokens()
+	if accessToken != "" {
+		req.Header.Set("Authorization", accessToken)
 	}
 
-	resp, err := c.httpClient.Do(req)
+	// A rate-limited request is retried after the wait the server asks for,
+	// and other failures as the retry policy allows
+	start := time.Now()
+	for attempt := 1; ; attempt++ {
+		resp, err := c.httpClient.Do(req)
+		retry := c.retryTransient(ctx, req, resp, err, attempt)
+		if !retry && err != nil {
+			// Transport errors quote the URL, which may carry credentials
+			err = RedactError(fmt.Errorf("failed to execute request: %w", err), accessToken, refreshToken)
+			c.logRequest(ctx, req, jsonData, start, attempt, nil, err)
+			return nil, err
+		}
+		if !retry && !c.rateLimited(ctx, req, resp, attempt >= c.rateLimitAttempts()) {
+			c.logRequest(ctx, req, jsonData, start, attempt, resp, nil)
+			return resp, nil
+		}
+		if req.GetBody != nil {
+			if req.Body, err = req.GetBody(); err != nil {
+				return nil, fmt.Errorf("failed to create request: %w", err)
+			}
+		}
+	}
+}
+
+// do performs an authenticated API call and decodes the response into result.
+func (c *Client) do(ctx context.Context, method, endpoint string, payload, result interface{}, operation string) error {
+	if err := c.authenticate(ctx); err != nil {
+		return err
+	}
+
+	ctx, cancel := c.withDeadline(ctx)
+	defer cancel()
+
+	resp, err := c.makeRequest(ctx, method, endpoint, payload)
 	if err != nil {
-		return nil, fmt.Errorf("failed to execute request: %w", err)
+		return fmt.Errorf("%s request failed: %w", operation, err)
 	}
+	return c.handleResponse(resp, result, operation)
+}
 
-	return resp, nil
+// Call performs an authenticated request to an endpoint the client has no
+// method for and decodes the JSON response into result. endpoint is relative
+// to the base URL and may carry a query string; a non-nil payload is sent as
+// the JSON request body.
+func (c *Client) Call(ctx context.Context, method, endpoint string, payload, result interface{}) error {
+	return c.do(ctx, method, endpoint, payload, result, method+" "+endpoint)
 }
 
 // handleResponse is an internal helper method that processes HTTP responses.
@@ -400,29 +1032,69 @@ func (c *Client) handleResponse(resp *http.Response, result interface{}, operati
 		return fmt.Errorf("failed to read response body: %w", err)
 	}
 
-	// Try to parse as JSON first
-	if err := json.Unmarshal(body, result); err != nil {
-		return fmt.Errorf("failed to parse response: %w", err)
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return parseRateLimit(resp.Header, time.Now())
 	}
 
-	// Check for non-200 status codes
-	if resp.StatusCode != http.StatusOK {
-		// Try to extract API error details
-		var apiErr APIError
-		if json.Unmarshal(body, &apiErr) == nil && apiErr.Code != 0 {
-			return &apiErr
+	// Non-JSON bodies (HTML challenges, maintenance pages) cannot be decoded
+	if !isJSONResponse(resp.Header.Get("Content-Type"), body) {
+		return &UpstreamError{
+			StatusCode:  resp.StatusCode,
+			ContentType: resp.Header.Get("Content-Type"),
+			Body:        Redact(bodyExcerpt(body)),
+		}
+	}
+
+	// Decode before checking for errors, so callers can inspect error
+	// details in result; an error body that does not fit result is still
+	// reported as the API error it is
+	decodeErr := json.Unmarshal(body, result)
+	if err := checkEnvelope(resp.StatusCode, body, operation); err != nil {
+		return err
+	}
+	if decodeErr != nil {
+		return fmt.Errorf("failed to parse response: %w", decodeErr)
+	}
+
+	if n, ok := result.(normalizer); ok {
+		n.normalize()
+	}
+	if v, ok := result.(validator); ok {
+		if problems := v.validate(); len(problems) > 0 {
+			return &MalformedResponseError{Operation: operation, Problems: problems}
 		}
-		return fmt.Errorf("%s failed with status code: %d", operation, resp.StatusCode)
 	}
 
 	return nil
 }
 
+// checkEnvelope reconciles the HTTP status with the code in the response
+// envelope: the call failed if either says so. When the envelope carries a
+// code, the error is an *APIError.
+func checkEnvelope(status int, body []byte, operation string) error {
+	var apiErr APIError
+	hasCode := json.Unmarshal(body, &apiErr) == nil && apiErr.Code != 0
+	apiErr.StatusCode = status
+	// Error bodies sometimes echo the request, credentials included
+	apiErr.Message, apiErr.Err = Redact(apiErr.Message), Redact(apiErr.Err)
+	switch {
+	case status != http.StatusOK && hasCode:
+		return &apiErr
+	case status != http.StatusOK:
+		return &StatusError{Operation: operation, StatusCode: status}
+	case hasCode && (apiErr.Code < 200 || apiErr.Code > 299):
+		return &apiErr
+	}
+	return nil
+}
+
 // Login authenticates the user with Stockal and stores the access token for subsequent requests.
 //
 // The method sends a POST request to the authentication endpoint with the provided
 // credentials. On successful authentication, the access token is automatically stored
-// in the client and will be included in all subsequent API calls.
+// in the client and will be included in all subsequent API calls. A response without
+// an access token, e.g. when the account needs a one-time password, returns a
+// *LoginIncompleteError and leaves the previous session in place.
 //
 // Parameters:
 //   - ctx: Context for cancellation and timeouts
@@ -437,12 +1109,12 @@ func (c *Client) handleResponse(resp *http.Response, result interface{}, operati
 //
 //	ctx := context.Background()
 //	client := stockal.NewClient()
-//	resp, err := client.Login(ctx, "myusername", "mypassword")
+//	resp, err := client.Auth().Login(ctx, "myusername", "mypassword")
 //	if err != nil {
 //		log.Fatal(err)
 //	}
 //	fmt.Printf("Logged in successfully. Token expires: %s\n", resp.Data.ExpiryAccessToken)
-func (c *Client) Login(ctx context.Context, username, password string) (*LoginResponse, error) {
+func (s *AuthService) Login(ctx context.Context, username, password string) (*LoginResponse, error) {
 	// Input validation
 	if strings.TrimSpace(username) == "" {
 		return nil, ErrEmptyUsername
@@ -451,34 +1123,193 @@ func (c *Client) Login(ctx context.Context, username, password string) (*LoginRe
 		return nil, ErrEmptyPassword
 	}
 
+	ctx, cancel := s.client.withDeadline(ctx)
+	defer cancel()
+
 	loginReq := LoginRequest{
 		Username: username,
 		Password: password,
 	}
 
-	resp, err := c.makeRequest(ctx, "POST", "/v3/auth/login", loginReq)
+	resp, err := s.client.makeRequest(ctx, "POST", "/v3/auth/login", loginReq)
 	if err != nil {
-		return nil, fmt.Errorf("login request failed: %w", err)
+		return nil, RedactError(fmt.Errorf("login request failed: %w", err), password)
 	}
 
 	var loginResp LoginResponse
 
 	// Handle response parsing
-	if err := c.handleResponse(resp, &loginResp, "login"); err != nil {
+	if err := s.client.handleResponse(resp, &loginResp, "login"); err != nil {
 		// Check for specific login error messages
 		if loginResp.Error != "" {
 			return &loginResp, ErrInvalidCredentials
 		}
+		return &loginResp, RedactError(err, password)
+	}
+
+	// A response without a token must not replace the session
+	if err := loginResp.incomplete("login"); err != nil {
 		return &loginResp, err
 	}
 
-	// Store access token in client for subsequent requests
-	c.accessToken = loginResp.Data.AccessToken
+	// Store access token in client (and token store) for subsequent requests
+	if err := s.client.setSession(ctx, loginResp.Data); err != nil {
+		return &loginResp, err
+	}
 
 	return &loginResp, nil
 }
 
-// GetAccountSummary retrieves a comprehensive summary of the user's account.
+// Refresh exchanges the stored refresh token for a new access token.
+//
+// Authenticated calls refresh automatically shortly before the access token
+// expires, so most callers never need to call Refresh directly.
+func (s *AuthService) Refresh(ctx context.Context) (*LoginResponse, error) {
+	_, refreshToken, _ := s.client.tokens()
+	if refreshToken == "" {
+		return nil, ErrNotAuthenticated
+	}
+
+	ctx, cancel := s.client.withDeadline(ctx)
+	defer cancel()
+
+	resp, err := s.client.makeRequest(ctx, "POST", "/v3/auth/refresh", refreshRequest{RefreshToken: refreshToken})
+	if err != nil {
+		return nil, fmt.Errorf("token refresh request failed: %w", err)
+	}
+
+	var refreshResp LoginResponse
+	if err := s.client.handleResponse(resp, &refreshResp, "token refresh"); err != nil {
+		return &refreshResp, err
+	}
+	if err := refreshResp.incomplete("token refresh"); err != nil {
+		return &refreshResp, err
+	}
+
+	// The API may not rotate the refresh token
+	if refreshResp.Data.RefreshToken == "" {
+		refreshResp.Data.RefreshToken = refreshToken
+	}
+	if err := s.client.setSession(ctx, refreshResp.Data); err != nil {
+		return &refreshResp, err
+	}
+
+	return &refreshResp, nil
+}
+
+// Logout forgets the current session and clears it from the token store, if any.
+func (s *AuthService) Logout(ctx context.Context) error {
+	s.client.clearSession()
+	if s.client.tokenStore == nil {
+		return nil
+	}
+	if err := s.client.tokenStore.Clear(ctx); err != nil {
+		return fmt.Errorf("failed to clear token store: %w", err)
+	}
+	return nil
+}
+
+// refreshRequest represents the request payload for refreshing an access token.
+type refreshRequest struct {
+	// RefreshToken is the refresh token from the last login or refresh
+	RefreshToken string `json:"refreshToken"`
+}
+
+// tokenRefreshSkew is how long before expiry an access token is refreshed.
+const tokenRefreshSkew = time.Minute
+
+// setSession stores session tokens in the client and persists them to the token store.
+func (c *Client) setSession(ctx context.Context, data LoginData) error {
+	c.restoreSession(data)
+	if c.tokenStore == nil {
+		return nil
+	}
+	if err := c.tokenStore.Save(ctx, &data); err != nil {
+		return fmt.Errorf("failed to save token: %w", err)
+	}
+	return nil
+}
+
+// restoreSession stores session tokens in the client without persisting them.
+func (c *Client) restoreSession(data LoginData) {
+	expiry := data.ExpiryAccessToken.Time
+	if expiry.IsZero() {
+		expiry = jwtExpiry(data.AccessToken)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.accessToken, c.refreshToken, c.tokenExpiry = data.AccessToken, data.RefreshToken, expiry
+}
+
+// clearSession forgets the session tokens held by the client.
+func (c *Client) clearSession() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.accessToken, c.refreshToken, c.tokenExpiry = "", "", time.Time{}
+}
+
+// tokens returns the session tokens held by the client.
+func (c *Client) tokens() (accessToken, refreshToken string, expiry time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.accessToken, c.refreshToken, c.tokenExpiry
+}
+
+// jwtExpiry returns the expiry time claimed by token if it is a JWT, or the
+// zero time. The signature is not verified; the time only decides when to
+// refresh.
+func jwtExpiry(token string) time.Time {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return time.Time{}
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return time.Time{}
+	}
+	var claims struct {
+		Exp int64 `json:"exp"`
+	}
+	if json.Unmarshal(payload, &claims) != nil {
+		return time.Time{}
+	}
+	return unixTime(claims.Exp)
+}
+
+// authenticate ensures the client holds a usable access token before an
+// authenticated call, loading it from the token store and refreshing it
+// when it is about to expire.
+func (c *Client) authenticate(ctx context.Context) error {
+	// Concurrent calls wait for one of them to load or refresh the session
+	// rather than each doing so
+	c.authMu.Lock()
+	defer c.authMu.Unlock()
+
+	accessToken, refreshToken, expiry := c.tokens()
+	if accessToken == "" && c.tokenStore != nil {
+		data, err := c.tokenStore.Load(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to load token: %w", err)
+		}
+		if data != nil {
+			c.restoreSession(*data)
+			accessToken, refreshToken, expiry = c.tokens()
+		}
+	}
+	if accessToken == "" {
+		return ErrNotAuthenticated
+	}
+
+	expiring := !expiry.IsZero() && time.Now().Add(tokenRefreshSkew).After(expiry)
+	if expiring && refreshToken != "" {
+		if _, err := c.Auth().Refresh(ctx); err != nil {
+			return fmt.Errorf("%w: %w", ErrTokenExpired, err)
+		}
+	}
+	return nil
+}
+
+// Summary retrieves a comprehensive summary of the user's account.
 //
 // This method fetches account-level information including cash balances, trading restrictions,
 // portfolio summaries, and unsettled amounts. The user must be authenticated (logged in)
@@ -496,31 +1327,34 @@ func (c *Client) Login(ctx context.Context, username, password string) (*LoginRe
 //
 // Example:
 //
-//	summary, err := client.GetAccountSummary()
+//	summary, err := client.Account().Summary(ctx)
 //	if err != nil {
 //		log.Fatal(err)
 //	}
 //	fmt.Printf("Cash available: $%.2f\n", summary.Data.AccountSummary.CashAvailableForTrade)
 //	fmt.Printf("Total portfolio value: $%.2f\n", summary.Data.PortfolioSummary.TotalCurrentValue)
-func (c *Client) GetAccountSummary(ctx context.Context) (*AccountSummaryResponse, error) {
-	if c.accessToken == "" {
-		return nil, ErrNotAuthenticated
+func (s *AccountService) Summary(ctx context.Context) (*AccountSummaryResponse, error) {
+	if err := s.client.authenticate(ctx); err != nil {
+		return nil, err
 	}
 
-	resp, err := c.makeRequest(ctx, "GET", "/v2/users/accountSummary/summary", nil)
+	ctx, cancel := s.client.withDeadline(ctx)
+	defer cancel()
+
+	resp, err := s.client.makeRequest(ctx, "GET", "/v2/users/accountSummary/summary", nil)
 	if err != nil {
 		return nil, fmt.Errorf("account summary request failed: %w", err)
 	}
 
 	var summaryResp AccountSummaryResponse
-	if err := c.handleResponse(resp, &summaryResp, "account summary"); err != nil {
+	if err := s.client.handleResponse(resp, &summaryResp, "account summary"); err != nil {
 		return &summaryResp, err
 	}
 
 	return &summaryResp, nil
 }
 
-// GetPortfolioDetail retrieves detailed information about all holdings in the user's portfolio.
+// Detail retrieves detailed information about all holdings in the user's portfolio.
 //
 // This method fetches comprehensive details for each individual holding including current prices,
 // investment amounts, units owned, gain/loss information, and trading restrictions. The user
@@ -539,31 +1373,75 @@ func (c *Client) GetAccountSummary(ctx context.Context) (*AccountSummaryResponse
 //
 // Example:
 //
-//	portfolio, err := client.GetPortfolioDetail()
+//	portfolio, err := client.Portfolio().Detail(ctx)
 //	if err != nil {
 //		log.Fatal(err)
 //	}
 //	fmt.Printf("Total holdings: %d\n", portfolio.Data.TotalRecords)
 //	for _, holding := range portfolio.Data.Holdings {
-//		currentValue := holding.TotalUnit * holding.Price
-//		gainLoss := currentValue - holding.TotalInvestment
 //		fmt.Printf("%s: $%.2f (%.2f%% gain/loss)\n",
-//			holding.Symbol, currentValue, (gainLoss/holding.TotalInvestment)*100)
+//			holding.Symbol, holding.CurrentValue(), holding.GainPercent())
 //	}
-func (c *Client) GetPortfolioDetail(ctx context.Context) (*PortfolioDetailResponse, error) {
-	if c.accessToken == "" {
-		return nil, ErrNotAuthenticated
+//
+// To read a large portfolio a page at a time, use DetailWithParams.
+func (s *PortfolioService) Detail(ctx context.Context) (*PortfolioDetailResponse, error) {
+	return s.DetailWithParams(ctx, PortfolioParams{})
+}
+
+// isJSONResponse reports whether a response body should be decoded as JSON.
+// A JSON Content-Type is trusted; anything else falls back to sniffing the body,
+// since the API does not always label its JSON responses correctly.
+func isJSONResponse(contentType string, body []byte) bool {
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+		if mediaType == "application/json" || strings.HasSuffix(mediaType, "+json") {
+			return true
+		}
+	}
+	trimmed := bytes.TrimSpace(body)
+	return len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[')
+}
+
+// bodyExcerpt returns a trimmed, length-limited excerpt of a response body for error messages.
+func bodyExcerpt(body []byte) string {
+	excerpt := strings.Join(strings.Fields(string(body)), " ")
+	if len(excerpt) > maxBodyExcerpt {
+		excerpt = strings.ToValidUTF8(excerpt[:maxBodyExcerpt], "") + "..."
 	}
+	return excerpt
+}
 
-	resp, err := c.makeRequest(ctx, "GET", "/v2/users/portfolio/detail", nil)
-	if err != nil {
-		return nil, fmt.Errorf("portfolio detail request failed: %w", err)
+// parseRateLimit builds a RateLimitError from Retry-After and X-RateLimit-* headers.
+// Retry-After may be given in seconds or as an HTTP date.
+func parseRateLimit(header http.Header, now time.Time) *RateLimitError {
+	rlErr := &RateLimitError{
+		Limit:     headerInt(header, "X-RateLimit-Limit"),
+		Remaining: headerInt(header, "X-RateLimit-Remaining"),
 	}
 
-	var portfolioResp PortfolioDetailResponse
-	if err := c.handleResponse(resp, &portfolioResp, "portfolio detail"); err != nil {
-		return &portfolioResp, err
+	if v := strings.TrimSpace(header.Get("Retry-After")); v != "" {
+		if secs, err := strconv.Atoi(v); err == nil && secs > 0 {
+			rlErr.RetryAfter = time.Duration(secs) * time.Second
+		} else if at, err := http.ParseTime(v); err == nil && at.After(now) {
+			rlErr.RetryAfter = at.Sub(now)
+		}
+	}
+
+	// X-RateLimit-Reset is a Unix timestamp in seconds
+	if reset := headerInt(header, "X-RateLimit-Reset"); reset > 0 {
+		rlErr.Reset = time.Unix(int64(reset), 0)
+		if rlErr.RetryAfter == 0 && rlErr.Reset.After(now) {
+			rlErr.RetryAfter = rlErr.Reset.Sub(now)
+		}
 	}
 
-	return &portfolioResp, nil
+	return rlErr
+}
+
+// headerInt parses an integer header value, returning -1 if it is missing or invalid.
+func headerInt(header http.Header, key string) int {
+	n, err := strconv.Atoi(strings.TrimSpace(header.Get(key)))
+	if err != nil {
+		return -1
+	}
+	return n
 }
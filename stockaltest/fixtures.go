@@ -0,0 +1,83 @@
+package stockaltest
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+)
+
+// fixtures holds API responses as Stockal sends them, for a small account
+// holding AAPL, MSFT and VOO.
+//
+//go:embed fixtures/*.json
+var fixtures embed.FS
+
+// Fixture returns the named JSON fixture, such as "portfolio_detail.json", as
+// the API would send it.
+func Fixture(name string) []byte {
+	data, err := fixtures.ReadFile("fixtures/" + name)
+	if err != nil {
+		panic(fmt.Sprintf("stockaltest: no fixture %q", name))
+	}
+	return data
+}
+
+// decode returns the named fixture decoded into a new T. Each call returns a
+// fresh copy, so callers may change it.
+func decode[T any](name string) *T {
+	var v T
+	if err := json.Unmarshal(Fixture(name), &v); err != nil {
+		panic(fmt.Sprintf("stockaltest: fixture %s: %v", name, err))
+	}
+	return &v
+}
+
+// Session returns a successful login, with tokens that expire in 2100.
+func Session() *stockal.LoginResponse {
+	return decode[stockal.LoginResponse]("login.json")
+}
+
+// AccountSummary returns the account summary: cash, a pending settlement and
+// portfolio totals matching Portfolio.
+func AccountSummary() *stockal.AccountSummaryResponse {
+	return decode[stockal.AccountSummaryResponse]("account_summary.json")
+}
+
+// Portfolio returns the holdings: two stocks and an ETF.
+func Portfolio() *stockal.PortfolioDetailResponse {
+	return decode[stockal.PortfolioDetailResponse]("portfolio_detail.json")
+}
+
+// Orders returns the order history, newest first: an open limit order and
+// two filled market orders.
+func Orders() *stockal.OrderListResponse {
+	return decode[stockal.OrderListResponse]("orders.json")
+}
+
+// Transactions returns the account activity, newest first: a deposit, the
+// filled buys and a dividend.
+func Transactions() *stockal.TransactionListResponse {
+	return decode[stockal.TransactionListResponse]("transactions.json")
+}
+
+// Dividends returns the dividends credited, newest first.
+func Dividends() *stockal.DividendsResponse {
+	return decode[stockal.DividendsResponse]("dividends.json")
+}
+
+// Quotes returns quotes for the symbols held.
+func Quotes() *stockal.QuotesResponse {
+	return decode[stockal.QuotesResponse]("quotes.json")
+}
+
+// FxRate returns Stockal's USD/INR rate.
+func FxRate() *stockal.FxRateResponse {
+	return decode[stockal.FxRateResponse]("fx_rate.json")
+}
+
+// Watchlists returns the account's watchlists.
+func Watchlists() *stockal.WatchlistsResponse {
+	return decode[stockal.WatchlistsResponse]("watchlists.json")
+}
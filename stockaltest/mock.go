@@ -0,0 +1,526 @@
+// Package stockaltest helps test code that uses the Stockal client without
+// calling the real API.
+//
+// MockClient implements stockal.StockalClient. Each method, including those
+// of the services MockClient.Orders and the like return, calls the function
+// set for it, or else returns a fixture: a small account holding AAPL, MSFT
+// and VOO, with orders, transactions, dividends and quotes to match. Every
+// call is recorded.
+//
+//	mock := &stockaltest.MockClient{
+//		OrdersPlaceFunc: func(ctx context.Context, order stockal.OrderRequest) (*stockal.OrderResponse, error) {
+//			return &stockal.OrderResponse{Code: 200, Data: stockal.Order{ID: "o1", Symbol: order.Symbol}}, nil
+//		},
+//	}
+//	rebalance(ctx, mock) // the code under test
+//	if calls := mock.CallsTo("Orders.Place"); len(calls) != 2 {
+//		t.Errorf("placed %d orders, want 2", len(calls))
+//	}
+//
+// Server serves the same fixtures over HTTP, for tests that run whole
+// requests through a stockal.Client.
+package stockaltest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+)
+
+// ErrNotMocked is returned by MockClient methods that have neither a function
+// set nor a fixture, such as those that place orders or move money.
+var ErrNotMocked = errors.New("stockaltest: method not mocked")
+
+// Call is a call made to a MockClient.
+type Call struct {
+	// Method is the name of the method called, e.g. "Orders.Place" or
+	// "GetQuotes"
+	Method string
+	// Args are the arguments after the context, in order
+	Args []any
+}
+
+// MockClient is a stockal.StockalClient for tests. A method whose function
+// field is set calls it; the field of a service method is named after the
+// service and the method, as OrdersPlaceFunc is for Orders().Place. Otherwise
+// the read methods return the fixtures of this package, Auth().Login and
+// Auth().Refresh return Session, Auth().Logout and the downloads succeed
+// without doing anything, and the rest return ErrNotMocked. The zero value is
+// ready to use, and a MockClient is safe for concurrent use as long as its
+// functions are not changed during calls.
+type MockClient struct {
+	AuthLoginFunc                 func(ctx context.Context, username, password string) (*stockal.LoginResponse, error)
+	AuthRefreshFunc               func(ctx context.Context) (*stockal.LoginResponse, error)
+	AuthLogoutFunc                func(ctx context.Context) error
+	AccountSummaryFunc            func(ctx context.Context) (*stockal.AccountSummaryResponse, error)
+	PortfolioDetailFunc           func(ctx context.Context) (*stockal.PortfolioDetailResponse, error)
+	PortfolioDetailWithParamsFunc func(ctx context.Context, params stockal.PortfolioParams) (*stockal.PortfolioDetailResponse, error)
+	StreamPortfolioDetailFunc     func(ctx context.Context, params stockal.PortfolioParams, fn func(stockal.Holding) error) (*stockal.PortfolioDetailResponse, error)
+	OrdersPlaceFunc               func(ctx context.Context, order stockal.OrderRequest) (*stockal.OrderResponse, error)
+	OrdersCancelFunc              func(ctx context.Context, orderID string) (*stockal.OrderResponse, error)
+	OrdersGetFunc                 func(ctx context.Context, orderID string) (*stockal.OrderResponse, error)
+	OrdersListFunc                func(ctx context.Context) (*stockal.OrderListResponse, error)
+	OrdersModifyFunc              func(ctx context.Context, orderID string, changes stockal.OrderChanges) (*stockal.OrderResponse, error)
+	OrdersListWithOptionsFunc     func(ctx context.Context, opts stockal.OrderListOptions) (*stockal.OrderListResponse, error)
+	GetTransactionsFunc           func(ctx context.Context, opts stockal.TransactionOptions) (*stockal.TransactionListResponse, error)
+	GetDividendsFunc              func(ctx context.Context, opts stockal.DividendOptions) (*stockal.DividendsResponse, error)
+	GetQuotesFunc                 func(ctx context.Context, symbols ...string) (*stockal.QuotesResponse, error)
+	GetFxRateFunc                 func(ctx context.Context) (*stockal.FxRateResponse, error)
+	GetCandlesFunc                func(ctx context.Context, symbol string, interval stockal.Interval, from, to time.Time) (*stockal.CandlesResponse, error)
+	SearchInstrumentsFunc         func(ctx context.Context, query string, opts stockal.SearchOptions) (*stockal.InstrumentsResponse, error)
+	GetStacksFunc                 func(ctx context.Context) (*stockal.StacksResponse, error)
+	GetStackDetailFunc            func(ctx context.Context, stackID string) (*stockal.StackDetailResponse, error)
+	InvestInStackFunc             func(ctx context.Context, stackID string, amount float64) (*stockal.StackOrderResponse, error)
+	RedeemStackFunc               func(ctx context.Context, stackID string, opts stockal.RedeemOptions) (*stockal.StackOrderResponse, error)
+	GetFundingInstructionsFunc    func(ctx context.Context) (*stockal.FundingInstructionsResponse, error)
+	CreateDepositFunc             func(ctx context.Context, deposit stockal.DepositRequest) (*stockal.DepositResponse, error)
+	GetDepositStatusFunc          func(ctx context.Context, depositID string) (*stockal.DepositResponse, error)
+	RequestWithdrawalFunc         func(ctx context.Context, amount float64, bankAccountID string) (*stockal.WithdrawalResponse, error)
+	GetWithdrawalsFunc            func(ctx context.Context) (*stockal.WithdrawalsResponse, error)
+	GetStatementsFunc             func(ctx context.Context, year int, month time.Month) (*stockal.DocumentsResponse, error)
+	DownloadStatementFunc         func(ctx context.Context, id string, w io.Writer) error
+	GetTradeConfirmationsFunc     func(ctx context.Context, year int, month time.Month) (*stockal.DocumentsResponse, error)
+	DownloadTradeConfirmationFunc func(ctx context.Context, id string, w io.Writer) error
+	GetTaxDocumentsFunc           func(ctx context.Context, year int) (*stockal.DocumentsResponse, error)
+	DownloadTaxDocumentFunc       func(ctx context.Context, id string, w io.Writer) error
+	GetWatchlistsFunc             func(ctx context.Context) (*stockal.WatchlistsResponse, error)
+	CreateWatchlistFunc           func(ctx context.Context, name string, symbols ...string) (*stockal.WatchlistResponse, error)
+	AddToWatchlistFunc            func(ctx context.Context, watchlistID string, symbols ...string) (*stockal.WatchlistResponse, error)
+	RemoveFromWatchlistFunc       func(ctx context.Context, watchlistID string, symbols ...string) (*stockal.WatchlistResponse, error)
+	DeleteWatchlistFunc           func(ctx context.Context, watchlistID string) error
+
+	mu    sync.Mutex
+	calls []Call
+}
+
+var _ stockal.StockalClient = (*MockClient)(nil)
+
+// Calls returns the calls made so far, oldest first.
+func (m *MockClient) Calls() []Call {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return slices.Clone(m.calls)
+}
+
+// CallsTo returns the calls made so far to the named method, oldest first.
+func (m *MockClient) CallsTo(method string) []Call {
+	var calls []Call
+	for _, c := range m.Calls() {
+		if c.Method == method {
+			calls = append(calls, c)
+		}
+	}
+	return calls
+}
+
+// Reset forgets the calls made so far.
+func (m *MockClient) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = nil
+}
+
+// record records a call to method with args.
+func (m *MockClient) record(method string, args ...any) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = append(m.calls, Call{Method: method, Args: args})
+}
+
+// notMocked returns the error of a method with neither a function nor a fixture.
+func notMocked(method string) error {
+	return fmt.Errorf("%w: set %sFunc", ErrNotMocked, method)
+}
+
+// Auth returns the Auth service of the mock, whose calls are recorded as
+// "Auth.Login" and so on.
+func (m *MockClient) Auth() stockal.Authenticator {
+	return mockAuth{m}
+}
+
+// Account returns the Account service of the mock.
+func (m *MockClient) Account() stockal.AccountReader {
+	return mockAccount{m}
+}
+
+// Portfolio returns the Portfolio service of the mock.
+func (m *MockClient) Portfolio() stockal.PortfolioQuerier {
+	return mockPortfolio{m}
+}
+
+// Orders returns the Orders service of the mock.
+func (m *MockClient) Orders() stockal.OrderManager {
+	return mockOrders{m}
+}
+
+// mockAuth, mockAccount, mockPortfolio and mockOrders are the services of a
+// MockClient.
+type (
+	mockAuth      struct{ m *MockClient }
+	mockAccount   struct{ m *MockClient }
+	mockPortfolio struct{ m *MockClient }
+	mockOrders    struct{ m *MockClient }
+)
+
+func (s mockAuth) Login(ctx context.Context, username, password string) (*stockal.LoginResponse, error) {
+	s.m.record("Auth.Login", username, password)
+	if s.m.AuthLoginFunc != nil {
+		return s.m.AuthLoginFunc(ctx, username, password)
+	}
+	return Session(), nil
+}
+
+func (s mockAuth) Refresh(ctx context.Context) (*stockal.LoginResponse, error) {
+	s.m.record("Auth.Refresh")
+	if s.m.AuthRefreshFunc != nil {
+		return s.m.AuthRefreshFunc(ctx)
+	}
+	return Session(), nil
+}
+
+func (s mockAuth) Logout(ctx context.Context) error {
+	s.m.record("Auth.Logout")
+	if s.m.AuthLogoutFunc != nil {
+		return s.m.AuthLogoutFunc(ctx)
+	}
+	return nil
+}
+
+func (s mockAccount) Summary(ctx context.Context) (*stockal.AccountSummaryResponse, error) {
+	s.m.record("Account.Summary")
+	if s.m.AccountSummaryFunc != nil {
+		return s.m.AccountSummaryFunc(ctx)
+	}
+	return AccountSummary(), nil
+}
+
+func (s mockPortfolio) Detail(ctx context.Context) (*stockal.PortfolioDetailResponse, error) {
+	s.m.record("Portfolio.Detail")
+	if s.m.PortfolioDetailFunc != nil {
+		return s.m.PortfolioDetailFunc(ctx)
+	}
+	return Portfolio(), nil
+}
+
+// DetailWithParams returns the Portfolio fixture, ignoring params, unless
+// PortfolioDetailWithParamsFunc is set.
+func (s mockPortfolio) DetailWithParams(ctx context.Context, params stockal.PortfolioParams) (*stockal.PortfolioDetailResponse, error) {
+	s.m.record("Portfolio.DetailWithParams", params)
+	if s.m.PortfolioDetailWithParamsFunc != nil {
+		return s.m.PortfolioDetailWithParamsFunc(ctx, params)
+	}
+	return Portfolio(), nil
+}
+
+// StreamPortfolioDetail calls fn with each holding of the Portfolio fixture,
+// as the client does, unless StreamPortfolioDetailFunc is set.
+func (m *MockClient) StreamPortfolioDetail(ctx context.Context, params stockal.PortfolioParams, fn func(stockal.Holding) error) (*stockal.PortfolioDetailResponse, error) {
+	m.record("StreamPortfolioDetail", params)
+	if m.StreamPortfolioDetailFunc != nil {
+		return m.StreamPortfolioDetailFunc(ctx, params, fn)
+	}
+	portfolio := Portfolio()
+	if fn == nil {
+		return portfolio, nil
+	}
+	for _, h := range portfolio.Data.Holdings {
+		if err := fn(h); err != nil {
+			return nil, err
+		}
+	}
+	portfolio.Data.Holdings = []stockal.Holding{}
+	return portfolio, nil
+}
+
+func (s mockOrders) Place(ctx context.Context, order stockal.OrderRequest) (*stockal.OrderResponse, error) {
+	s.m.record("Orders.Place", order)
+	if s.m.OrdersPlaceFunc != nil {
+		return s.m.OrdersPlaceFunc(ctx, order)
+	}
+	return nil, notMocked("OrdersPlace")
+}
+
+func (s mockOrders) Cancel(ctx context.Context, orderID string) (*stockal.OrderResponse, error) {
+	s.m.record("Orders.Cancel", orderID)
+	if s.m.OrdersCancelFunc != nil {
+		return s.m.OrdersCancelFunc(ctx, orderID)
+	}
+	return nil, notMocked("OrdersCancel")
+}
+
+// Get returns the order with orderID from the Orders fixture, unless
+// OrdersGetFunc is set.
+func (s mockOrders) Get(ctx context.Context, orderID string) (*stockal.OrderResponse, error) {
+	s.m.record("Orders.Get", orderID)
+	if s.m.OrdersGetFunc != nil {
+		return s.m.OrdersGetFunc(ctx, orderID)
+	}
+	orders := Orders()
+	for _, o := range orders.Data.Orders {
+		if o.ID == orderID {
+			return &stockal.OrderResponse{Code: orders.Code, Message: orders.Message, Data: o}, nil
+		}
+	}
+	return nil, &stockal.APIError{Code: 404, Message: "Order not found", StatusCode: 404}
+}
+
+func (s mockOrders) List(ctx context.Context) (*stockal.OrderListResponse, error) {
+	s.m.record("Orders.List")
+	if s.m.OrdersListFunc != nil {
+		return s.m.OrdersListFunc(ctx)
+	}
+	return Orders(), nil
+}
+
+func (s mockOrders) Modify(ctx context.Context, orderID string, changes stockal.OrderChanges) (*stockal.OrderResponse, error) {
+	s.m.record("Orders.Modify", orderID, changes)
+	if s.m.OrdersModifyFunc != nil {
+		return s.m.OrdersModifyFunc(ctx, orderID, changes)
+	}
+	return nil, notMocked("OrdersModify")
+}
+
+// ListWithOptions returns the Orders fixture, ignoring opts, unless
+// OrdersListWithOptionsFunc is set.
+func (s mockOrders) ListWithOptions(ctx context.Context, opts stockal.OrderListOptions) (*stockal.OrderListResponse, error) {
+	s.m.record("Orders.ListWithOptions", opts)
+	if s.m.OrdersListWithOptionsFunc != nil {
+		return s.m.OrdersListWithOptionsFunc(ctx, opts)
+	}
+	return Orders(), nil
+}
+
+// GetTransactions returns the Transactions fixture, ignoring opts, unless
+// GetTransactionsFunc is set.
+func (m *MockClient) GetTransactions(ctx context.Context, opts stockal.TransactionOptions) (*stockal.TransactionListResponse, error) {
+	m.record("GetTransactions", opts)
+	if m.GetTransactionsFunc != nil {
+		return m.GetTransactionsFunc(ctx, opts)
+	}
+	return Transactions(), nil
+}
+
+// GetDividends returns the Dividends fixture, ignoring opts, unless
+// GetDividendsFunc is set.
+func (m *MockClient) GetDividends(ctx context.Context, opts stockal.DividendOptions) (*stockal.DividendsResponse, error) {
+	m.record("GetDividends", opts)
+	if m.GetDividendsFunc != nil {
+		return m.GetDividendsFunc(ctx, opts)
+	}
+	return Dividends(), nil
+}
+
+// GetQuotes returns the quotes of the Quotes fixture for symbols, unless
+// GetQuotesFunc is set. Symbols without a fixture are left out, as Stockal
+// leaves out symbols it does not know.
+func (m *MockClient) GetQuotes(ctx context.Context, symbols ...string) (*stockal.QuotesResponse, error) {
+	m.record("GetQuotes", stringArgs(symbols)...)
+	if m.GetQuotesFunc != nil {
+		return m.GetQuotesFunc(ctx, symbols...)
+	}
+	quotes := Quotes()
+	quotes.Data = slices.DeleteFunc(quotes.Data, func(q stockal.Quote) bool {
+		return !slices.ContainsFunc(symbols, func(s string) bool { return strings.EqualFold(s, q.Symbol) })
+	})
+	return quotes, nil
+}
+
+func (m *MockClient) GetFxRate(ctx context.Context) (*stockal.FxRateResponse, error) {
+	m.record("GetFxRate")
+	if m.GetFxRateFunc != nil {
+		return m.GetFxRateFunc(ctx)
+	}
+	return FxRate(), nil
+}
+
+func (m *MockClient) GetCandles(ctx context.Context, symbol string, interval stockal.Interval, from, to time.Time) (*stockal.CandlesResponse, error) {
+	m.record("GetCandles", symbol, interval, from, to)
+	if m.GetCandlesFunc != nil {
+		return m.GetCandlesFunc(ctx, symbol, interval, from, to)
+	}
+	return nil, notMocked("GetCandles")
+}
+
+func (m *MockClient) SearchInstruments(ctx context.Context, query string, opts stockal.SearchOptions) (*stockal.InstrumentsResponse, error) {
+	m.record("SearchInstruments", query, opts)
+	if m.SearchInstrumentsFunc != nil {
+		return m.SearchInstrumentsFunc(ctx, query, opts)
+	}
+	return nil, notMocked("SearchInstruments")
+}
+
+func (m *MockClient) GetStacks(ctx context.Context) (*stockal.StacksResponse, error) {
+	m.record("GetStacks")
+	if m.GetStacksFunc != nil {
+		return m.GetStacksFunc(ctx)
+	}
+	return nil, notMocked("GetStacks")
+}
+
+func (m *MockClient) GetStackDetail(ctx context.Context, stackID string) (*stockal.StackDetailResponse, error) {
+	m.record("GetStackDetail", stackID)
+	if m.GetStackDetailFunc != nil {
+		return m.GetStackDetailFunc(ctx, stackID)
+	}
+	return nil, notMocked("GetStackDetail")
+}
+
+func (m *MockClient) InvestInStack(ctx context.Context, stackID string, amount float64) (*stockal.StackOrderResponse, error) {
+	m.record("InvestInStack", stackID, amount)
+	if m.InvestInStackFunc != nil {
+		return m.InvestInStackFunc(ctx, stackID, amount)
+	}
+	return nil, notMocked("InvestInStack")
+}
+
+func (m *MockClient) RedeemStack(ctx context.Context, stackID string, opts stockal.RedeemOptions) (*stockal.StackOrderResponse, error) {
+	m.record("RedeemStack", stackID, opts)
+	if m.RedeemStackFunc != nil {
+		return m.RedeemStackFunc(ctx, stackID, opts)
+	}
+	return nil, notMocked("RedeemStack")
+}
+
+func (m *MockClient) GetFundingInstructions(ctx context.Context) (*stockal.FundingInstructionsResponse, error) {
+	m.record("GetFundingInstructions")
+	if m.GetFundingInstructionsFunc != nil {
+		return m.GetFundingInstructionsFunc(ctx)
+	}
+	return nil, notMocked("GetFundingInstructions")
+}
+
+func (m *MockClient) CreateDeposit(ctx context.Context, deposit stockal.DepositRequest) (*stockal.DepositResponse, error) {
+	m.record("CreateDeposit", deposit)
+	if m.CreateDepositFunc != nil {
+		return m.CreateDepositFunc(ctx, deposit)
+	}
+	return nil, notMocked("CreateDeposit")
+}
+
+func (m *MockClient) GetDepositStatus(ctx context.Context, depositID string) (*stockal.DepositResponse, error) {
+	m.record("GetDepositStatus", depositID)
+	if m.GetDepositStatusFunc != nil {
+		return m.GetDepositStatusFunc(ctx, depositID)
+	}
+	return nil, notMocked("GetDepositStatus")
+}
+
+func (m *MockClient) RequestWithdrawal(ctx context.Context, amount float64, bankAccountID string) (*stockal.WithdrawalResponse, error) {
+	m.record("RequestWithdrawal", amount, bankAccountID)
+	if m.RequestWithdrawalFunc != nil {
+		return m.RequestWithdrawalFunc(ctx, amount, bankAccountID)
+	}
+	return nil, notMocked("RequestWithdrawal")
+}
+
+func (m *MockClient) GetWithdrawals(ctx context.Context) (*stockal.WithdrawalsResponse, error) {
+	m.record("GetWithdrawals")
+	if m.GetWithdrawalsFunc != nil {
+		return m.GetWithdrawalsFunc(ctx)
+	}
+	return nil, notMocked("GetWithdrawals")
+}
+
+func (m *MockClient) GetStatements(ctx context.Context, year int, month time.Month) (*stockal.DocumentsResponse, error) {
+	m.record("GetStatements", year, month)
+	if m.GetStatementsFunc != nil {
+		return m.GetStatementsFunc(ctx, year, month)
+	}
+	return nil, notMocked("GetStatements")
+}
+
+func (m *MockClient) DownloadStatement(ctx context.Context, id string, w io.Writer) error {
+	m.record("DownloadStatement", id)
+	if m.DownloadStatementFunc != nil {
+		return m.DownloadStatementFunc(ctx, id, w)
+	}
+	return nil
+}
+
+func (m *MockClient) GetTradeConfirmations(ctx context.Context, year int, month time.Month) (*stockal.DocumentsResponse, error) {
+	m.record("GetTradeConfirmations", year, month)
+	if m.GetTradeConfirmationsFunc != nil {
+		return m.GetTradeConfirmationsFunc(ctx, year, month)
+	}
+	return nil, notMocked("GetTradeConfirmations")
+}
+
+func (m *MockClient) DownloadTradeConfirmation(ctx context.Context, id string, w io.Writer) error {
+	m.record("DownloadTradeConfirmation", id)
+	if m.DownloadTradeConfirmationFunc != nil {
+		return m.DownloadTradeConfirmationFunc(ctx, id, w)
+	}
+	return nil
+}
+
+func (m *MockClient) GetTaxDocuments(ctx context.Context, year int) (*stockal.DocumentsResponse, error) {
+	m.record("GetTaxDocuments", year)
+	if m.GetTaxDocumentsFunc != nil {
+		return m.GetTaxDocumentsFunc(ctx, year)
+	}
+	return nil, notMocked("GetTaxDocuments")
+}
+
+func (m *MockClient) DownloadTaxDocument(ctx context.Context, id string, w io.Writer) error {
+	m.record("DownloadTaxDocument", id)
+	if m.DownloadTaxDocumentFunc != nil {
+		return m.DownloadTaxDocumentFunc(ctx, id, w)
+	}
+	return nil
+}
+
+func (m *MockClient) GetWatchlists(ctx context.Context) (*stockal.WatchlistsResponse, error) {
+	m.record("GetWatchlists")
+	if m.GetWatchlistsFunc != nil {
+		return m.GetWatchlistsFunc(ctx)
+	}
+	return Watchlists(), nil
+}
+
+func (m *MockClient) CreateWatchlist(ctx context.Context, name string, symbols ...string) (*stockal.WatchlistResponse, error) {
+	m.record("CreateWatchlist", append([]any{name}, stringArgs(symbols)...)...)
+	if m.CreateWatchlistFunc != nil {
+		return m.CreateWatchlistFunc(ctx, name, symbols...)
+	}
+	return nil, notMocked("CreateWatchlist")
+}
+
+func (m *MockClient) AddToWatchlist(ctx context.Context, watchlistID string, symbols ...string) (*stockal.WatchlistResponse, error) {
+	m.record("AddToWatchlist", append([]any{watchlistID}, stringArgs(symbols)...)...)
+	if m.AddToWatchlistFunc != nil {
+		return m.AddToWatchlistFunc(ctx, watchlistID, symbols...)
+	}
+	return nil, notMocked("AddToWatchlist")
+}
+
+func (m *MockClient) RemoveFromWatchlist(ctx context.Context, watchlistID string, symbols ...string) (*stockal.WatchlistResponse, error) {
+	m.record("RemoveFromWatchlist", append([]any{watchlistID}, stringArgs(symbols)...)...)
+	if m.RemoveFromWatchlistFunc != nil {
+		return m.RemoveFromWatchlistFunc(ctx, watchlistID, symbols...)
+	}
+	return nil, notMocked("RemoveFromWatchlist")
+}
+
+func (m *MockClient) DeleteWatchlist(ctx context.Context, watchlistID string) error {
+	m.record("DeleteWatchlist", watchlistID)
+	if m.DeleteWatchlistFunc != nil {
+		return m.DeleteWatchlistFunc(ctx, watchlistID)
+	}
+	return notMocked("DeleteWatchlist")
+}
+
+// stringArgs returns symbols as call arguments.
+func stringArgs(symbols []string) []any {
+	args := make([]any, len(symbols))
+	for i, s := range symbols {
+		args[i] = s
+	}
+	return args
+}
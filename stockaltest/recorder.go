@@ -0,0 +1,232 @@
+package stockaltest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+)
+
+// ErrNotRecorded is returned by a replaying Recorder for requests its
+// cassette does not hold.
+var ErrNotRecorded = errors.New("stockaltest: request not recorded")
+
+// Mode is whether a Recorder records or replays.
+type Mode int
+
+const (
+	// Replay answers requests from the cassette without using the network.
+	Replay Mode = iota
+	// Record sends requests to the API and saves them to the cassette.
+	Record
+)
+
+// Recorder is an http.RoundTripper that records requests and their responses
+// to a cassette file, or replays them from it, so tests can run against real
+// API responses without credentials or a network.
+//
+// Cassettes hold no secrets: request headers are not recorded, and request
+// and response bodies, URLs and response headers are passed through
+// stockal.Redact along with the request's Authorization value and Secrets.
+// Tokens in a recorded login are therefore replayed as stockal.Redacted,
+// with no expiry, which the client accepts as a session.
+//
+// Replay matches each request to the first unused interaction with the same
+// method, redacted URL and redacted body, so calls may be replayed in a
+// different order than they were recorded, but each only once.
+type Recorder struct {
+	// Transport sends requests while recording. If nil, http.DefaultTransport
+	// is used.
+	Transport http.RoundTripper
+	// Secrets are removed wherever they appear in what is recorded, e.g. the
+	// username, account ID or bank details of the account recorded.
+	Secrets []string
+
+	path string
+	mode Mode
+
+	mu           sync.Mutex
+	interactions []interaction
+	used         []bool
+}
+
+// interaction is a request and its response, as saved in a cassette.
+type interaction struct {
+	Request  recordedRequest  `json:"request"`
+	Response recordedResponse `json:"response"`
+}
+
+type recordedRequest struct {
+	Method string `json:"method"`
+	URL    string `json:"url"`
+	Body   string `json:"body,omitempty"`
+}
+
+type recordedResponse struct {
+	Status int         `json:"status"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+	// BinaryBody holds bodies that are not text, such as PDF statements,
+	// unredacted.
+	BinaryBody []byte `json:"binaryBody,omitempty"`
+}
+
+// cassette is the file a Recorder saves to.
+type cassette struct {
+	Interactions []interaction `json:"interactions"`
+}
+
+// NewRecorder returns a Recorder using the cassette at path. To replay, the
+// cassette must exist; to record, it is replaced by Save.
+//
+// Example:
+//
+//	mode := stockaltest.Replay
+//	if os.Getenv("STOCKAL_RECORD") != "" {
+//		mode = stockaltest.Record
+//	}
+//	rec, err := stockaltest.NewRecorder("testdata/portfolio.json", mode)
+//	if err != nil {
+//		t.Fatal(err)
+//	}
+//	defer rec.Save()
+//	client := stockal.NewClient(stockal.WithHTTPClient(rec.HTTPClient()))
+func NewRecorder(path string, mode Mode) (*Recorder, error) {
+	r := &Recorder{path: path, mode: mode}
+	if mode == Record {
+		return r, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cassette: %w", err)
+	}
+	var c cassette
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("failed to parse cassette %s: %w", path, err)
+	}
+	r.interactions = c.Interactions
+	r.used = make([]bool, len(c.Interactions))
+	return r, nil
+}
+
+// HTTPClient returns an HTTP client using the recorder, for
+// stockal.WithHTTPClient.
+func (r *Recorder) HTTPClient() *http.Client {
+	return &http.Client{Transport: r}
+}
+
+// RoundTrip records or replays req.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	secrets := append([]string{req.Header.Get("Authorization")}, r.Secrets...)
+	var body []byte
+	if req.Body != nil {
+		var err error
+		if body, err = io.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		req.Body.Close()
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	recorded := recordedRequest{
+		Method: req.Method,
+		URL:    stockal.Redact(req.URL.RequestURI(), secrets...),
+		Body:   stockal.Redact(string(body), secrets...),
+	}
+
+	if r.mode == Replay {
+		return r.replay(req, recorded)
+	}
+
+	transport := r.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	response := recordedResponse{Status: resp.StatusCode, Header: http.Header{}}
+	for name, values := range resp.Header {
+		if strings.EqualFold(name, "Set-Cookie") {
+			continue
+		}
+		for _, v := range values {
+			response.Header.Add(name, stockal.Redact(v, secrets...))
+		}
+	}
+	if utf8.Valid(respBody) {
+		response.Body = stockal.Redact(string(respBody), secrets...)
+	} else {
+		response.BinaryBody = respBody
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.interactions = append(r.interactions, interaction{Request: recorded, Response: response})
+	return resp, nil
+}
+
+// replay answers req with the first unused interaction recorded for it.
+func (r *Recorder) replay(req *http.Request, recorded recordedRequest) (*http.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, in := range r.interactions {
+		if r.used[i] || in.Request != recorded {
+			continue
+		}
+		r.used[i] = true
+		body := in.Response.BinaryBody
+		if body == nil {
+			body = []byte(in.Response.Body)
+		}
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", in.Response.Status, http.StatusText(in.Response.Status)),
+			StatusCode:    in.Response.Status,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        in.Response.Header.Clone(),
+			Body:          io.NopCloser(bytes.NewReader(body)),
+			ContentLength: int64(len(body)),
+			Request:       req,
+		}, nil
+	}
+	return nil, fmt.Errorf("%w: %s %s", ErrNotRecorded, recorded.Method, recorded.URL)
+}
+
+// Save writes the interactions recorded to the cassette, creating its
+// directory if need be. It does nothing when replaying.
+func (r *Recorder) Save() error {
+	if r.mode != Record {
+		return nil
+	}
+	r.mu.Lock()
+	data, err := json.MarshalIndent(cassette{Interactions: r.interactions}, "", "  ")
+	r.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to encode cassette: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(r.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create cassette directory: %w", err)
+	}
+	if err := os.WriteFile(r.path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write cassette: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,160 @@
+package stockaltest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+)
+
+// Server is a local Stockal API answering with the fixtures of this package,
+// for tests that run whole requests through a stockal.Client. Any username and
+// password log in, and requests must then carry the fixture session's access
+// token, as they do from a client logged in to the server. Endpoints without
+// a fixture answer 404 unless given a handler with Handle.
+type Server struct {
+	*httptest.Server
+
+	mux       *http.ServeMux
+	overrides *http.ServeMux
+}
+
+// NewServer starts and returns a Server. The caller should call Close when
+// finished, to shut it down.
+//
+// Example:
+//
+//	srv := stockaltest.NewServer()
+//	defer srv.Close()
+//	client := srv.NewClient()
+//	if _, err := client.Auth().Login(ctx, "alice", "secret"); err != nil {
+//		t.Fatal(err)
+//	}
+//	portfolio, err := client.Portfolio().Detail(ctx) // the Portfolio fixture
+func NewServer() *Server {
+	s := &Server{mux: http.NewServeMux(), overrides: http.NewServeMux()}
+	s.mux.HandleFunc("POST /v3/auth/login", s.login)
+	s.mux.HandleFunc("POST /v3/auth/refresh", s.refresh)
+	s.mux.Handle("GET /v2/users/accountSummary/summary", authorized(serveFixture("account_summary.json")))
+	s.mux.Handle("GET /v2/users/portfolio/detail", authorized(serveFixture("portfolio_detail.json")))
+	s.mux.Handle("GET /v2/orders", authorized(serveFixture("orders.json")))
+	s.mux.Handle("GET /v2/orders/{id}", authorized(http.HandlerFunc(serveOrder)))
+	s.mux.Handle("GET /v2/users/transactions", authorized(serveFixture("transactions.json")))
+	s.mux.Handle("GET /v2/users/dividends", authorized(serveFixture("dividends.json")))
+	s.mux.Handle("GET /v2/market/quotes", authorized(http.HandlerFunc(serveQuotes)))
+	s.mux.Handle("GET /v2/market/fx/USDINR", authorized(serveFixture("fx_rate.json")))
+	s.mux.Handle("GET /v2/watchlists", authorized(serveFixture("watchlists.json")))
+	s.mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, http.StatusNotFound, "Not found")
+	})
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// NewClient returns a client for the server, with opts applied after the
+// base URL. It is not logged in.
+func (s *Server) NewClient(opts ...stockal.ClientOption) *stockal.Client {
+	return stockal.NewClient(append([]stockal.ClientOption{stockal.WithBaseURL(s.URL)}, opts...)...)
+}
+
+// Handle answers requests matching pattern, as http.ServeMux patterns do
+// (e.g. "POST /v2/orders"), with handler instead of a fixture or 404. It
+// must be called before the requests it answers, and panics if pattern was
+// already given a handler.
+func (s *Server) Handle(pattern string, handler http.Handler) {
+	s.overrides.Handle(pattern, handler)
+}
+
+// HandleFunc is Handle for a handler function.
+func (s *Server) HandleFunc(pattern string, handler func(http.ResponseWriter, *http.Request)) {
+	s.overrides.HandleFunc(pattern, handler)
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if h, pattern := s.overrides.Handler(r); pattern != "" {
+		h.ServeHTTP(w, r)
+		return
+	}
+	s.mux.ServeHTTP(w, r)
+}
+
+// login answers any username and password with the Session fixture.
+func (s *Server) login(w http.ResponseWriter, r *http.Request) {
+	var creds stockal.LoginRequest
+	if err := json.NewDecoder(r.Body).Decode(&creds); err != nil || creds.Username == "" || creds.Password == "" {
+		writeError(w, http.StatusBadRequest, "Username and password are required")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(Fixture("login.json"))
+}
+
+// refresh answers the refresh token of the Session fixture with the fixture.
+func (s *Server) refresh(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		RefreshToken string `json:"refreshToken"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.RefreshToken != Session().Data.RefreshToken {
+		writeError(w, http.StatusUnauthorized, "Invalid refresh token")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(Fixture("login.json"))
+}
+
+// authorized answers 401 to requests without the Session fixture's access
+// token, and passes the rest to next.
+func authorized(next http.Handler) http.Handler {
+	token := Session().Data.AccessToken
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != token {
+			writeError(w, http.StatusUnauthorized, "Unauthorized")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// serveFixture answers with the named fixture.
+func serveFixture(name string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(Fixture(name))
+	})
+}
+
+// serveOrder answers with the order of the Orders fixture named in the path.
+func serveOrder(w http.ResponseWriter, r *http.Request) {
+	orders := Orders()
+	for _, o := range orders.Data.Orders {
+		if o.ID == r.PathValue("id") {
+			writeJSON(w, http.StatusOK, stockal.OrderResponse{Code: orders.Code, Message: orders.Message, Data: o})
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, "Order not found")
+}
+
+// serveQuotes answers with the quotes of the Quotes fixture for the symbols
+// requested, leaving out those without one as Stockal does.
+func serveQuotes(w http.ResponseWriter, r *http.Request) {
+	symbols := strings.Split(strings.ToUpper(r.URL.Query().Get("symbols")), ",")
+	quotes := Quotes()
+	quotes.Data = slices.DeleteFunc(quotes.Data, func(q stockal.Quote) bool {
+		return !slices.Contains(symbols, strings.ToUpper(q.Symbol))
+	})
+	writeJSON(w, http.StatusOK, quotes)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, stockal.APIError{Code: status, Message: message})
+}
@@ -0,0 +1,234 @@
+package stockal
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// streamPath is the path of the socket Stockal's web app keeps open for live
+// prices and order updates.
+const streamPath = "/v2/stream"
+
+const (
+	// streamHeartbeat is how often the client pings the socket.
+	streamHeartbeat = 15 * time.Second
+	// streamTimeout is how long the socket may stay silent, pongs included,
+	// before the client gives up on it and reconnects.
+	streamTimeout = 3 * streamHeartbeat
+)
+
+// streamMessage is a message on the socket. The client sends "auth" and
+// "subscribe" messages; the server sends "quote" and "order" messages with
+// the update in Data, "heartbeat" messages while idle, and "error" messages
+// before closing the socket.
+type streamMessage struct {
+	Type    string          `json:"type"`
+	Token   string          `json:"token,omitempty"`
+	Channel string          `json:"channel,omitempty"`
+	Symbols []string        `json:"symbols,omitempty"`
+	Code    int             `json:"code,omitempty"`
+	Message string          `json:"message,omitempty"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+// StreamQuotes sends live quotes for symbols to ch until ctx is done, then
+// returns the context's cause. It does not close ch.
+//
+// The quotes come over the socket Stockal's web app uses, which the client
+// pings every 15 seconds. When the socket drops or goes silent, the client
+// reconnects, waiting as the retry policy (WithRetry, or DefaultRetryPolicy)
+// says between attempts; quotes sent while it was down are lost. If the
+// server rate-limits the connection, the client waits at least as long as it
+// asks (see RateLimitError) and reports it to the WithRateLimitHook hook. If
+// the server rejects the session, the client refreshes it once and reconnects.
+// Other errors from the server, and failures to authenticate, are returned.
+// Streaming is not available in browsers (GOOS=js).
+//
+// Example:
+//
+//	quotes := make(chan stockal.Quote)
+//	go func() {
+//		for q := range quotes {
+//			fmt.Printf("%s: $%.2f\n", q.Symbol, q.Price)
+//		}
+//	}()
+//	err := client.StreamQuotes(ctx, []string{"AAPL", "TSLA"}, quotes)
+func (c *Client) StreamQuotes(ctx context.Context, symbols []string, ch chan<- Quote) error {
+	if len(symbols) == 0 {
+		return errors.New("at least one symbol is required")
+	}
+	upper := make([]string, len(symbols))
+	for i, s := range symbols {
+		upper[i] = strings.ToUpper(strings.TrimSpace(s))
+	}
+	return stream(ctx, c, streamMessage{Type: "subscribe", Channel: "quotes", Symbols: upper}, "quote", ch)
+}
+
+// StreamOrderUpdates sends the account's orders to ch whenever they change,
+// as when they are placed, filled, partially filled or cancelled, until ctx
+// is done, then returns the context's cause. It does not close ch. The
+// connection is kept up as StreamQuotes describes.
+func (c *Client) StreamOrderUpdates(ctx context.Context, ch chan<- Order) error {
+	return stream(ctx, c, streamMessage{Type: "subscribe", Channel: "orders"}, "order", ch)
+}
+
+// stream subscribes to a channel of the socket and sends the data of its
+// messages of type kind to ch, reconnecting until ctx is done.
+func stream[T any](ctx context.Context, c *Client, subscribe streamMessage, kind string, ch chan<- T) error {
+	if inBrowser {
+		return errors.New("streaming is not supported in browsers")
+	}
+	policy := DefaultRetryPolicy
+	if c.retry != nil {
+		policy = *c.retry
+	}
+	failures := 0
+	refreshed := false
+	for {
+		received, err := streamOnce(ctx, c, subscribe, kind, ch)
+		if ctx.Err() != nil {
+			return context.Cause(ctx)
+		}
+		if received {
+			failures, refreshed = 0, false
+		}
+
+		var apiErr *APIError
+		switch {
+		case errors.Is(err, ErrTokenExpired) && !refreshed:
+			// Refresh the session the server rejected, and try it straight away
+			refreshed = true
+			if _, refreshToken, _ := c.tokens(); refreshToken == "" {
+				return err
+			}
+			if _, err := c.Auth().Refresh(ctx); err != nil {
+				return fmt.Errorf("%w: %w", ErrTokenExpired, err)
+			}
+			continue
+		case errors.As(err, &apiErr), errors.Is(err, ErrNotAuthenticated), errors.Is(err, ErrTokenExpired):
+			return err
+		}
+
+		failures++
+		wait := policy.backoff(failures)
+		var rlErr *RateLimitError
+		if errors.As(err, &rlErr) {
+			wait = max(wait, rlErr.RetryAfter)
+			if c.rateLimitHook != nil {
+				c.rateLimitHook(RateLimitEvent{Method: http.MethodGet, Endpoint: streamPath, Err: rlErr, Wait: wait})
+			}
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return context.Cause(ctx)
+		case <-timer.C:
+		}
+	}
+}
+
+// streamOnce connects to the socket, subscribes, and sends updates to ch
+// until the connection fails or ctx is done. It reports whether any update
+// arrived.
+func streamOnce[T any](ctx context.Context, c *Client, subscribe streamMessage, kind string, ch chan<- T) (bool, error) {
+	conn, err := c.dialStream(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+
+	accessToken, _, _ := c.tokens()
+	conn.SetReadDeadline(time.Now().Add(streamTimeout))
+	if err := conn.WriteJSON(streamMessage{Type: "auth", Token: accessToken}); err != nil {
+		return false, fmt.Errorf("failed to authenticate stream: %w", err)
+	}
+	if err := conn.WriteJSON(subscribe); err != nil {
+		return false, fmt.Errorf("failed to subscribe to %s: %w", subscribe.Channel, err)
+	}
+
+	// Ping while the socket is open, and close it when ctx is done to end
+	// the read below
+	done := make(chan struct{})
+	defer close(done)
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(streamTimeout))
+	})
+	go func() {
+		ticker := time.NewTicker(streamHeartbeat)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ctx.Done():
+				conn.Close()
+				return
+			case <-ticker.C:
+				conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(streamHeartbeat))
+			}
+		}
+	}()
+
+	received := false
+	for {
+		var msg streamMessage
+		if err := conn.ReadJSON(&msg); err != nil {
+			return received, fmt.Errorf("stream closed: %w", err)
+		}
+		conn.SetReadDeadline(time.Now().Add(streamTimeout))
+		switch msg.Type {
+		case kind:
+			var update T
+			if err := json.Unmarshal(msg.Data, &update); err != nil {
+				return received, fmt.Errorf("%w: %s update: %w", ErrMalformedResponse, kind, err)
+			}
+			select {
+			case ch <- update:
+				received = true
+			case <-ctx.Done():
+				return received, context.Cause(ctx)
+			}
+		case "error":
+			return received, &APIError{Code: msg.Code, Message: msg.Message}
+		}
+	}
+}
+
+// dialStream opens the socket, authenticating the client first. A handshake
+// the server rate-limits fails with a *RateLimitError, and other refusals with
+// an *APIError.
+func (c *Client) dialStream(ctx context.Context) (*websocket.Conn, error) {
+	if err := c.authenticate(ctx); err != nil {
+		return nil, err
+	}
+	streamURL, err := url.JoinPath(c.baseURL, streamPath)
+	if err != nil {
+		return nil, fmt.Errorf("invalid stream URL: %w", err)
+	}
+	streamURL = strings.Replace(strings.Replace(streamURL, "https://", "wss://", 1), "http://", "ws://", 1)
+
+	header := http.Header{}
+	header.Set("User-Agent", c.userAgent)
+	header.Set("Origin", c.origin)
+	dialer := websocket.Dialer{Proxy: http.ProxyFromEnvironment, HandshakeTimeout: streamTimeout}
+	conn, resp, err := dialer.DialContext(ctx, streamURL, header)
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusTooManyRequests {
+			return nil, parseRateLimit(resp.Header, time.Now())
+		}
+		if resp != nil && resp.StatusCode >= 400 && resp.StatusCode < 500 {
+			return nil, &APIError{Code: resp.StatusCode, Message: "stream refused: " + resp.Status, StatusCode: resp.StatusCode}
+		}
+		return nil, fmt.Errorf("failed to connect to stream: %w", err)
+	}
+	return conn, nil
+}
@@ -0,0 +1,149 @@
+package stockal_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+	"github.com/gorilla/websocket"
+)
+
+func TestStreamQuotes(t *testing.T) {
+	// The client sends Stockal's web origin, as the web app does
+	upgrader := websocket.Upgrader{CheckOrigin: func(*http.Request) bool { return true }}
+	var connections atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v2/stream" {
+			http.NotFound(w, r)
+			return
+		}
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		n := connections.Add(1)
+
+		var auth, subscribe map[string]any
+		if conn.ReadJSON(&auth) != nil || conn.ReadJSON(&subscribe) != nil {
+			return
+		}
+		if auth["type"] != "auth" || auth["token"] != "token" {
+			conn.WriteJSON(map[string]any{"type": "error", "code": 401, "message": "Unauthorized"})
+			return
+		}
+		if subscribe["channel"] != "quotes" || !slices.Equal(subscribe["symbols"].([]any), []any{"AAPL", "TSLA"}) {
+			conn.WriteJSON(map[string]any{"type": "error", "code": 400, "message": "Bad subscription"})
+			return
+		}
+		conn.WriteJSON(map[string]any{"type": "heartbeat"})
+		if n == 1 {
+			// Drop the first connection after one quote
+			conn.WriteJSON(map[string]any{"type": "quote", "data": map[string]any{"symbol": "AAPL", "price": 213.49}})
+			return
+		}
+		conn.WriteJSON(map[string]any{"type": "quote", "data": map[string]any{"symbol": "TSLA", "price": 248.71}})
+		conn.ReadMessage() // Until the client hangs up
+	}))
+	defer srv.Close()
+
+	client := newLoggedInClient(srv.URL, stockal.WithRetry(stockal.RetryPolicy{InitialBackoff: time.Millisecond}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	quotes := make(chan stockal.Quote)
+	done := make(chan error, 1)
+	go func() { done <- client.StreamQuotes(ctx, []string{"aapl", "TSLA"}, quotes) }()
+
+	for _, want := range []string{"AAPL", "TSLA"} {
+		select {
+		case q := <-quotes:
+			if q.Symbol != want {
+				t.Errorf("got a quote for %s, want %s", q.Symbol, want)
+			}
+		case err := <-done:
+			t.Fatalf("StreamQuotes returned early: %v", err)
+		}
+	}
+	if n := connections.Load(); n != 2 {
+		t.Errorf("connected %d times, want 2", n)
+	}
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("StreamQuotes after cancel = %v, want context.Canceled", err)
+	}
+
+	// Errors from the server end the stream
+	stale := stockal.NewMemoryTokenStore(&stockal.LoginData{AccessToken: "stale"})
+	client = newLoggedInClient(srv.URL, stockal.WithTokenStore(stale))
+	err := client.StreamOrderUpdates(context.Background(), make(chan stockal.Order))
+	if !errors.Is(err, stockal.ErrTokenExpired) {
+		t.Errorf("StreamOrderUpdates with a rejected session = %v, want ErrTokenExpired", err)
+	}
+}
+
+func TestStreamRateLimited(t *testing.T) {
+	upgrader := websocket.Upgrader{CheckOrigin: func(*http.Request) bool { return true }}
+	var (
+		connections atomic.Int32
+		refusedAt   atomic.Int64
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if connections.Add(1) == 1 {
+			refusedAt.Store(time.Now().UnixNano())
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		var auth, subscribe map[string]any
+		if conn.ReadJSON(&auth) != nil || conn.ReadJSON(&subscribe) != nil {
+			return
+		}
+		conn.WriteJSON(map[string]any{"type": "quote", "data": map[string]any{"symbol": "AAPL", "price": 213.49}})
+		conn.ReadMessage() // Until the client hangs up
+	}))
+	defer srv.Close()
+
+	events := make(chan stockal.RateLimitEvent, 1)
+	client := newLoggedInClient(srv.URL, stockal.WithRetry(stockal.RetryPolicy{InitialBackoff: time.Millisecond}),
+		stockal.WithRateLimitHook(func(e stockal.RateLimitEvent) { events <- e }))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	quotes := make(chan stockal.Quote)
+	done := make(chan error, 1)
+	go func() { done <- client.StreamQuotes(ctx, []string{"AAPL"}, quotes) }()
+
+	select {
+	case q := <-quotes:
+		if waited := time.Since(time.Unix(0, refusedAt.Load())); waited < time.Second {
+			t.Errorf("reconnected after %v, want at least the 1s the server asked for", waited)
+		}
+		if q.Symbol != "AAPL" {
+			t.Errorf("got a quote for %s, want AAPL", q.Symbol)
+		}
+	case err := <-done:
+		t.Fatalf("StreamQuotes returned early: %v", err)
+	}
+	select {
+	case e := <-events:
+		if e.Method != http.MethodGet || e.Endpoint != "/v2/stream" || e.Err.RetryAfter != time.Second || e.Wait != time.Second {
+			t.Errorf("rate limit event %+v, want the stream's 1s wait", e)
+		}
+	default:
+		t.Error("the rate limit hook was not called")
+	}
+	cancel()
+	<-done
+}
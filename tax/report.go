@@ -0,0 +1,265 @@
+package tax
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+)
+
+// Report holds the schedules for one financial year.
+type Report struct {
+	// Year is the financial year reported
+	Year FinancialYear
+	// Gains are the capital gains for Schedule CG
+	Gains []Gain
+	// Dividends are the dividends for Schedule OS
+	Dividends []Dividend
+	// Assets are the foreign assets for Schedule FA
+	Assets []ForeignAsset
+
+	// rates holds the USD/INR rates looked up by ConvertINR, keyed by date
+	rates map[string]stockal.FxRate
+}
+
+// NewReport builds all schedules for fy from the complete trade and dividend history.
+func NewReport(fy FinancialYear, trades []Trade, dividends []Dividend) (*Report, error) {
+	gains, err := CapitalGains(trades, fy)
+	if err != nil {
+		return nil, err
+	}
+	assets, err := ForeignAssets(trades, dividends, fy.StartYear)
+	if err != nil {
+		return nil, err
+	}
+	return &Report{
+		Year:      fy,
+		Gains:     gains,
+		Dividends: DividendIncome(dividends, fy),
+		Assets:    assets,
+	}, nil
+}
+
+// ConvertINR looks up the exchange rates for the report's dates with fx, after
+// which Tables adds rupee columns alongside the dollar ones.
+//
+// Capital gains convert the cost at the acquisition date's rate and the
+// proceeds at the sale date's. Dividends use the rate on the last day of the
+// month before they were credited (Rule 115). Foreign asset values use the
+// acquisition date's rate for the initial value and 31 December's for the
+// rest, as the Schedule FA instructions prescribe.
+func (r *Report) ConvertINR(ctx context.Context, fx stockal.FxProvider) error {
+	rates := map[string]stockal.FxRate{}
+	lookup := func(t time.Time) error {
+		key := date(t)
+		if _, ok := rates[key]; ok {
+			return nil
+		}
+		rate, err := fx.USDINR(ctx, t.In(indiaLocation()))
+		if err != nil {
+			return fmt.Errorf("converting to INR: %w", err)
+		}
+		rates[key] = rate
+		return nil
+	}
+
+	for _, g := range r.Gains {
+		if err := lookup(g.Acquired); err != nil {
+			return err
+		}
+		if err := lookup(g.Sold); err != nil {
+			return err
+		}
+	}
+	for _, d := range r.Dividends {
+		if err := lookup(monthEndBefore(d.Time)); err != nil {
+			return err
+		}
+	}
+	if len(r.Assets) > 0 {
+		if err := lookup(r.yearEnd()); err != nil {
+			return err
+		}
+	}
+	for _, a := range r.Assets {
+		if err := lookup(a.Acquired); err != nil {
+			return err
+		}
+	}
+	r.rates = rates
+	return nil
+}
+
+// inr converts v to rupees at the rate looked up for t.
+func (r *Report) inr(t time.Time, v float64) string {
+	return amount(v * r.rates[date(t)].Rate)
+}
+
+// yearEnd returns 31 December of the calendar year Schedule FA reports.
+func (r *Report) yearEnd() time.Time {
+	return time.Date(r.Year.StartYear, time.December, 31, 0, 0, 0, 0, indiaLocation())
+}
+
+// monthEndBefore returns the last day of the month before t's, in Indian time.
+func monthEndBefore(t time.Time) time.Time {
+	t = t.In(indiaLocation())
+	return time.Date(t.Year(), t.Month(), 0, 0, 0, 0, 0, indiaLocation())
+}
+
+// Table is one schedule laid out as rows of cells.
+type Table struct {
+	// Name is a short name for the schedule, usable as a file or sheet name
+	Name string
+	// Header holds the column titles
+	Header []string
+	// Rows holds the formatted cells
+	Rows [][]string
+}
+
+// Tables lays out the schedules in the column order of the ITR forms.
+func (r *Report) Tables() []Table {
+	cg := Table{
+		Name: "schedule-cg",
+		Header: []string{"Symbol", "Name", "Term", "Date of acquisition", "Date of transfer",
+			"Quantity", "Cost of acquisition (USD)", "Full value of consideration (USD)", "Gain/loss (USD)"},
+	}
+	for _, g := range r.Gains {
+		term := "STCG"
+		if g.LongTerm {
+			term = "LTCG"
+		}
+		row := []string{g.Symbol, g.Name, term, date(g.Acquired), date(g.Sold),
+			quantity(g.Quantity), amount(g.Cost), amount(g.Proceeds), amount(g.Gain)}
+		if r.rates != nil {
+			cost, proceeds := g.Cost*r.rates[date(g.Acquired)].Rate, g.Proceeds*r.rates[date(g.Sold)].Rate
+			row = append(row, amount(cost), amount(proceeds), amount(proceeds-cost))
+		}
+		cg.Rows = append(cg.Rows, row)
+	}
+	if r.rates != nil {
+		cg.Header = append(cg.Header, "Cost of acquisition (INR)", "Full value of consideration (INR)", "Gain/loss (INR)")
+	}
+
+	os := Table{
+		Name:   "schedule-os",
+		Header: []string{"Symbol", "Date credited", "Gross dividend (USD)", "Tax withheld (USD)", "Net dividend (USD)"},
+	}
+	for _, d := range r.Dividends {
+		row := []string{d.Symbol, date(d.Time), amount(d.Gross), amount(d.Withheld), amount(d.Gross - d.Withheld)}
+		if r.rates != nil {
+			at := monthEndBefore(d.Time)
+			row = append(row, r.inr(at, d.Gross), r.inr(at, d.Withheld), r.inr(at, d.Gross-d.Withheld))
+		}
+		os.Rows = append(os.Rows, row)
+	}
+	if r.rates != nil {
+		os.Header = append(os.Header, "Gross dividend (INR)", "Tax withheld (INR)", "Net dividend (INR)")
+	}
+
+	fa := Table{
+		Name: "schedule-fa",
+		Header: []string{"Country/Region name", "Country code", "Name of entity", "Nature of entity",
+			"Date of acquiring the interest", "Initial value of the investment (USD)", "Peak value during the period (USD)",
+			"Closing balance (USD)", "Total gross amount paid/credited (USD)", "Total gross proceeds from sale (USD)"},
+	}
+	for _, a := range r.Assets {
+		name := a.Name
+		if name == "" {
+			name = a.Symbol
+		}
+		row := []string{"United States of America", "2", name, "Company", date(a.Acquired),
+			amount(a.InitialValue), amount(a.PeakValue), amount(a.ClosingValue), amount(a.GrossIncome), amount(a.GrossProceeds)}
+		if r.rates != nil {
+			end := r.yearEnd()
+			row = append(row, r.inr(a.Acquired, a.InitialValue), r.inr(end, a.PeakValue), r.inr(end, a.ClosingValue),
+				r.inr(end, a.GrossIncome), r.inr(end, a.GrossProceeds))
+		}
+		fa.Rows = append(fa.Rows, row)
+	}
+	if r.rates != nil {
+		fa.Header = append(fa.Header, "Initial value of the investment (INR)", "Peak value during the period (INR)",
+			"Closing balance (INR)", "Total gross amount paid/credited (INR)", "Total gross proceeds from sale (INR)")
+	}
+
+	return []Table{cg, os, fa}
+}
+
+// WriteCSV writes a table as CSV with a header row.
+func (t Table) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(t.Header); err != nil {
+		return err
+	}
+	if err := cw.WriteAll(t.Rows); err != nil {
+		return err
+	}
+	return cw.Error()
+}
+
+// WriteXLSX writes the report as an Excel workbook with one sheet per schedule.
+func (r *Report) WriteXLSX(w io.Writer) error {
+	f := excelize.NewFile()
+	defer f.Close()
+
+	bold, err := f.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+	if err != nil {
+		return err
+	}
+	for i, t := range r.Tables() {
+		sheet := t.Name
+		if i == 0 {
+			if err := f.SetSheetName("Sheet1", sheet); err != nil {
+				return err
+			}
+		} else if _, err := f.NewSheet(sheet); err != nil {
+			return err
+		}
+
+		if err := f.SetSheetRow(sheet, "A1", &t.Header); err != nil {
+			return err
+		}
+		last, _ := excelize.CoordinatesToCellName(len(t.Header), 1)
+		if err := f.SetCellStyle(sheet, "A1", last, bold); err != nil {
+			return err
+		}
+		for j, row := range t.Rows {
+			cells := make([]any, len(row))
+			for k, v := range row {
+				// Store numbers as numbers so the sheet can sum them.
+				if n, err := strconv.ParseFloat(v, 64); err == nil {
+					cells[k] = n
+				} else {
+					cells[k] = v
+				}
+			}
+			cell, _ := excelize.CoordinatesToCellName(1, j+2)
+			if err := f.SetSheetRow(sheet, cell, &cells); err != nil {
+				return err
+			}
+		}
+	}
+	return f.Write(w)
+}
+
+// date formats t as DD/MM/YYYY in Indian time, as the ITR utilities expect.
+func date(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.In(indiaLocation()).Format("02/01/2006")
+}
+
+func amount(v float64) string {
+	return strconv.FormatFloat(v, 'f', 2, 64)
+}
+
+func quantity(v float64) string {
+	return strconv.FormatFloat(math.Round(v*1e6)/1e6, 'f', -1, 64)
+}
@@ -0,0 +1,125 @@
+package tax
+
+import (
+	"cmp"
+	"slices"
+	"time"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+)
+
+// ForeignAsset is a row of Schedule FA (table A3, foreign equity and debt
+// interest) for one stock held at any time during a calendar year.
+//
+// The API provides no price history, so PeakValue and ClosingValue are
+// estimated from trade prices: the peak is the largest position value at any
+// trade during the year, and the closing value uses the last trade price
+// before the end of the year.
+type ForeignAsset struct {
+	Symbol string
+	Name   string
+	// Acquired is the date of the earliest purchase still held during the year
+	Acquired time.Time
+	// InitialValue is the cost of the shares held during the year
+	InitialValue float64
+	// PeakValue is the estimated peak value during the year
+	PeakValue float64
+	// ClosingValue is the estimated value at 31 December
+	ClosingValue float64
+	// GrossIncome is the dividends credited during the year
+	GrossIncome float64
+	// GrossProceeds is the proceeds from sales during the year
+	GrossProceeds float64
+}
+
+// ForeignAssets builds Schedule FA rows for calendar year. For returns filed
+// for financial year fy, Schedule FA covers the calendar year fy.StartYear.
+func ForeignAssets(trades []Trade, dividends []Dividend, year int) ([]ForeignAsset, error) {
+	loc := indiaLocation()
+	start := time.Date(year, time.January, 1, 0, 0, 0, 0, loc)
+	end := time.Date(year+1, time.January, 1, 0, 0, 0, 0, loc)
+
+	l := ledger{}
+	lastPrice := map[string]float64{}
+	sorted := byTime(trades)
+
+	// Replay the trades before the year to find the holdings carried into it.
+	i := 0
+	for ; i < len(sorted) && sorted[i].Time.Before(start); i++ {
+		if _, err := l.apply(sorted[i]); err != nil {
+			return nil, err
+		}
+		lastPrice[sorted[i].Symbol] = sorted[i].Price
+	}
+
+	assets := map[string]*ForeignAsset{}
+	for symbol, lots := range l {
+		if len(lots) > 0 {
+			a := &ForeignAsset{Symbol: symbol, PeakValue: l.quantity(symbol) * lastPrice[symbol]}
+			for _, lt := range lots {
+				a.addLot(lt)
+			}
+			assets[symbol] = a
+		}
+	}
+
+	for ; i < len(sorted) && sorted[i].Time.Before(end); i++ {
+		t := sorted[i]
+		a, ok := assets[t.Symbol]
+		if !ok {
+			a = &ForeignAsset{Symbol: t.Symbol}
+			assets[t.Symbol] = a
+		}
+		if a.Name == "" {
+			a.Name = t.Name
+		}
+
+		before := l.quantity(t.Symbol)
+		if _, err := l.apply(t); err != nil {
+			return nil, err
+		}
+		lastPrice[t.Symbol] = t.Price
+		if t.Side == stockal.OrderSideBuy {
+			a.addLot(lot{quantity: t.Quantity, price: t.Price, time: t.Time})
+		} else {
+			a.GrossProceeds += t.Quantity * t.Price
+		}
+		a.PeakValue = max(a.PeakValue, before*t.Price, l.quantity(t.Symbol)*t.Price)
+	}
+
+	for _, d := range dividends {
+		if a, ok := assets[d.Symbol]; ok && !d.Time.Before(start) && d.Time.Before(end) {
+			a.GrossIncome += d.Gross
+		}
+	}
+
+	rows := make([]ForeignAsset, 0, len(assets))
+	for symbol, a := range assets {
+		a.ClosingValue = l.quantity(symbol) * lastPrice[symbol]
+		rows = append(rows, *a)
+	}
+	slices.SortFunc(rows, func(a, b ForeignAsset) int {
+		return cmp.Or(a.Acquired.Compare(b.Acquired), cmp.Compare(a.Symbol, b.Symbol))
+	})
+	return rows, nil
+}
+
+// addLot adds shares held during the year to the asset's initial value.
+func (a *ForeignAsset) addLot(l lot) {
+	if a.Acquired.IsZero() || l.time.Before(a.Acquired) {
+		a.Acquired = l.time
+	}
+	a.InitialValue += l.quantity * l.price
+}
+
+// DividendIncome returns the dividends credited during fy, for Schedule OS.
+func DividendIncome(dividends []Dividend, fy FinancialYear) []Dividend {
+	var rows []Dividend
+	for _, d := range dividends {
+		if fy.Contains(d.Time) {
+			rows = append(rows, d)
+		}
+	}
+	slices.SortFunc(rows, func(a, b Dividend) int { return a.Time.Compare(b.Time) })
+	return rows
+}
@@ -0,0 +1,319 @@
+// Package tax prepares capital gains, dividend income and foreign asset
+// schedules for Indian income tax returns (ITR Schedules CG, OS and FA) from
+// US stock trades.
+//
+// Amounts are in US dollars. Report.ConvertINR adds rupee amounts using any
+// stockal.FxProvider, such as the RBI reference rates in providers/rbi; the
+// rules prescribe the SBI TT buying rate for the relevant dates, so check the
+// rates used. Review every figure before filing: the schedules are only as
+// complete as the trade history they are built from.
+//
+// # Basic Usage
+//
+//	fy, err := tax.ParseFinancialYear("2024-25")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	transactions, err := stockal.AllTransactions(ctx, client, stockal.TransactionOptions{
+//		Types: []stockal.TransactionType{stockal.TransactionBuy, stockal.TransactionSell},
+//	})
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	trades, err := tax.TradesFromTransactions(transactions)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	report, err := tax.NewReport(fy, trades, nil)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	err = report.WriteXLSX(f)
+package tax
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+)
+
+// longTermMonths is the holding period after which gains on foreign shares,
+// which are not listed in India, are long-term.
+const longTermMonths = 24
+
+// ErrInsufficientLots is returned when a sale exceeds the shares bought before
+// it, which usually means the trade history is incomplete.
+var ErrInsufficientLots = errors.New("sale exceeds shares held")
+
+// indiaLocation returns the Asia/Kolkata time zone, falling back to a fixed
+// IST offset when the system has no time zone database.
+var indiaLocation = sync.OnceValue(func() *time.Location {
+	loc, err := time.LoadLocation("Asia/Kolkata")
+	if err != nil {
+		return time.FixedZone("IST", 5*60*60+30*60)
+	}
+	return loc
+})
+
+// FinancialYear is an Indian financial year, April to March.
+type FinancialYear struct {
+	// StartYear is the calendar year in which the financial year begins
+	StartYear int
+}
+
+// ParseFinancialYear parses a financial year written as "2024-25" or "2024-2025".
+func ParseFinancialYear(s string) (FinancialYear, error) {
+	start, end, ok := strings.Cut(s, "-")
+	startYear, err := strconv.Atoi(start)
+	if !ok || err != nil || len(start) != 4 {
+		return FinancialYear{}, fmt.Errorf("invalid financial year %q (want e.g. 2024-25)", s)
+	}
+	endYear, err := strconv.Atoi(end)
+	if err != nil || (len(end) == 2 && endYear != (startYear+1)%100) || (len(end) == 4 && endYear != startYear+1) || (len(end) != 2 && len(end) != 4) {
+		return FinancialYear{}, fmt.Errorf("invalid financial year %q (want e.g. 2024-25)", s)
+	}
+	return FinancialYear{StartYear: startYear}, nil
+}
+
+// String returns the year in "2024-25" form.
+func (fy FinancialYear) String() string {
+	return fmt.Sprintf("%d-%02d", fy.StartYear, (fy.StartYear+1)%100)
+}
+
+// Start returns 1 April of the start year, in Indian time.
+func (fy FinancialYear) Start() time.Time {
+	return time.Date(fy.StartYear, time.April, 1, 0, 0, 0, 0, indiaLocation())
+}
+
+// End returns 1 April of the following year, the exclusive end of the year.
+func (fy FinancialYear) End() time.Time {
+	return time.Date(fy.StartYear+1, time.April, 1, 0, 0, 0, 0, indiaLocation())
+}
+
+// Contains reports whether t falls within the financial year.
+func (fy FinancialYear) Contains(t time.Time) bool {
+	return !t.Before(fy.Start()) && t.Before(fy.End())
+}
+
+// Trade is an executed purchase or sale.
+type Trade struct {
+	// Symbol is the stock symbol
+	Symbol string
+	// Name is the company name, if known
+	Name string
+	// Side is buy or sell
+	Side stockal.OrderSide
+	// Quantity is the number of shares traded
+	Quantity float64
+	// Price is the price per share
+	Price float64
+	// Time is when the trade was executed
+	Time time.Time
+}
+
+// TradesFromTransactions returns the purchases and sales among transactions
+// as trades, at the time each was executed. Other transactions are skipped.
+func TradesFromTransactions(transactions []stockal.Transaction) ([]Trade, error) {
+	var trades []Trade
+	for _, tx := range transactions {
+		var side stockal.OrderSide
+		switch tx.Type {
+		case stockal.TransactionBuy:
+			side = stockal.OrderSideBuy
+		case stockal.TransactionSell:
+			side = stockal.OrderSideSell
+		default:
+			continue
+		}
+		t := tx.Date.Time
+		if t.IsZero() {
+			return nil, fmt.Errorf("transaction %s: invalid date %q", tx.ID, tx.Date.Raw)
+		}
+		price := tx.Price
+		if price == 0 && tx.Quantity > 0 {
+			price = math.Abs(tx.Amount) / tx.Quantity
+		}
+		trades = append(trades, Trade{
+			Symbol:   tx.Symbol,
+			Side:     side,
+			Quantity: tx.Quantity,
+			Price:    price,
+			Time:     t,
+		})
+	}
+	return trades, nil
+}
+
+// TradesFromOrders returns the filled part of each order as a trade, at the
+// time it was filled. Orders filled in part carry no fill time, and are taken
+// at their last update. TradesFromTransactions is more precise, since an
+// order may be filled in several trades.
+func TradesFromOrders(orders []stockal.Order) ([]Trade, error) {
+	var trades []Trade
+	for _, o := range orders {
+		if o.FilledQuantity <= 0 {
+			continue
+		}
+		filled := o.FilledAt
+		if filled.IsZero() {
+			filled = o.UpdatedAt
+		}
+		t := filled.Time
+		if t.IsZero() {
+			return nil, fmt.Errorf("order %s: invalid fill time %q", o.ID, filled.Raw)
+		}
+		trades = append(trades, Trade{
+			Symbol:   o.Symbol,
+			Side:     o.Side,
+			Quantity: o.FilledQuantity,
+			Price:    o.AveragePrice,
+			Time:     t,
+		})
+	}
+	return trades, nil
+}
+
+// Dividend is a dividend credited to the account.
+type Dividend struct {
+	// Symbol is the stock that paid the dividend
+	Symbol string
+	// Time is when the dividend was credited
+	Time time.Time
+	// Gross is the dividend before withholding tax
+	Gross float64
+	// Withheld is the US tax withheld, which may be claimed as a foreign tax credit
+	Withheld float64
+}
+
+// DividendsFromCredits returns the dividends credited to the account, as
+// GetDividends reports them.
+func DividendsFromCredits(credits []stockal.Dividend) ([]Dividend, error) {
+	dividends := make([]Dividend, 0, len(credits))
+	for _, c := range credits {
+		t := c.PayDate.Time
+		if t.IsZero() {
+			return nil, fmt.Errorf("dividend %s: invalid pay date %q", c.ID, c.PayDate.Raw)
+		}
+		dividends = append(dividends, Dividend{
+			Symbol:   c.Symbol,
+			Time:     t,
+			Gross:    c.Amount,
+			Withheld: c.Withholding,
+		})
+	}
+	return dividends, nil
+}
+
+// Gain is the capital gain or loss from selling one purchase lot, or part of it.
+type Gain struct {
+	Symbol   string
+	Name     string
+	Quantity float64
+	Acquired time.Time
+	Sold     time.Time
+	// Cost is the purchase cost of the shares sold
+	Cost float64
+	// Proceeds is the sale value of the shares sold
+	Proceeds float64
+	// Gain is Proceeds minus Cost
+	Gain float64
+	// LongTerm reports whether the shares were held for more than 24 months
+	LongTerm bool
+}
+
+// CapitalGains matches sales in fy against earlier purchases, first in first
+// out. trades must include every purchase of the shares sold, including those
+// from earlier years.
+func CapitalGains(trades []Trade, fy FinancialYear) ([]Gain, error) {
+	var gains []Gain
+	l := ledger{}
+	for _, t := range byTime(trades) {
+		consumed, err := l.apply(t)
+		if err != nil {
+			return nil, err
+		}
+		if !fy.Contains(t.Time) {
+			continue
+		}
+		for _, c := range consumed {
+			g := Gain{
+				Symbol:   t.Symbol,
+				Name:     t.Name,
+				Quantity: c.quantity,
+				Acquired: c.time,
+				Sold:     t.Time,
+				Cost:     c.quantity * c.price,
+				Proceeds: c.quantity * t.Price,
+				LongTerm: t.Time.After(c.time.AddDate(0, longTermMonths, 0)),
+			}
+			g.Gain = g.Proceeds - g.Cost
+			gains = append(gains, g)
+		}
+	}
+	return gains, nil
+}
+
+// quantityEpsilon absorbs rounding in fractional share quantities.
+const quantityEpsilon = 1e-6
+
+// lot is shares bought together and not yet sold.
+type lot struct {
+	quantity float64
+	price    float64
+	time     time.Time
+}
+
+// ledger tracks the unsold purchase lots of each symbol.
+type ledger map[string][]lot
+
+// apply records a trade. For a sale it returns the lots consumed, oldest
+// first; a sale of more than is held consumes nothing.
+func (l ledger) apply(t Trade) ([]lot, error) {
+	switch t.Side {
+	case stockal.OrderSideBuy:
+		l[t.Symbol] = append(l[t.Symbol], lot{quantity: t.Quantity, price: t.Price, time: t.Time})
+		return nil, nil
+	case stockal.OrderSideSell:
+		if t.Quantity-l.quantity(t.Symbol) > quantityEpsilon {
+			return nil, fmt.Errorf("%s on %s: %w", t.Symbol, t.Time.Format(time.DateOnly), ErrInsufficientLots)
+		}
+		held := l[t.Symbol]
+		var consumed []lot
+		remaining := t.Quantity
+		for remaining > quantityEpsilon && len(held) > 0 {
+			take := min(remaining, held[0].quantity)
+			consumed = append(consumed, lot{quantity: take, price: held[0].price, time: held[0].time})
+			remaining -= take
+			held[0].quantity -= take
+			if held[0].quantity <= quantityEpsilon {
+				held = held[1:]
+			}
+		}
+		l[t.Symbol] = held
+		return consumed, nil
+	}
+	return nil, fmt.Errorf("%s on %s: unknown side %q", t.Symbol, t.Time.Format(time.DateOnly), t.Side)
+}
+
+// quantity returns the number of shares of symbol held.
+func (l ledger) quantity(symbol string) float64 {
+	var q float64
+	for _, lt := range l[symbol] {
+		q += lt.quantity
+	}
+	return q
+}
+
+// byTime returns the trades sorted by execution time.
+func byTime(trades []Trade) []Trade {
+	sorted := slices.Clone(trades)
+	slices.SortStableFunc(sorted, func(a, b Trade) int { return a.Time.Compare(b.Time) })
+	return sorted
+}
@@ -0,0 +1,252 @@
+package tax
+
+import (
+	"context"
+	"errors"
+	"math"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+	"github.com/adjaecent/unofficial-stockal-api/providers/rbi"
+)
+
+func day(s string) time.Time {
+	t, err := time.Parse(time.DateOnly, s)
+	if err != nil {
+		panic(err)
+	}
+	return t.Add(12 * time.Hour)
+}
+
+func buy(symbol string, quantity, price float64, date string) Trade {
+	return Trade{Symbol: symbol, Side: stockal.OrderSideBuy, Quantity: quantity, Price: price, Time: day(date)}
+}
+
+func sell(symbol string, quantity, price float64, date string) Trade {
+	return Trade{Symbol: symbol, Side: stockal.OrderSideSell, Quantity: quantity, Price: price, Time: day(date)}
+}
+
+func TestLedger(t *testing.T) {
+	tests := []struct {
+		name     string
+		trades   []Trade
+		consumed []lot
+		held     float64
+		err      error
+	}{
+		{
+			name:     "whole lot",
+			trades:   []Trade{buy("AAPL", 10, 100, "2023-01-02"), sell("AAPL", 10, 150, "2024-01-02")},
+			consumed: []lot{{quantity: 10, price: 100, time: day("2023-01-02")}},
+		},
+		{
+			name: "partial lot",
+			trades: []Trade{
+				buy("AAPL", 10, 100, "2023-01-02"), buy("AAPL", 5, 120, "2023-02-01"),
+				sell("AAPL", 12, 150, "2024-01-02"),
+			},
+			consumed: []lot{{quantity: 10, price: 100, time: day("2023-01-02")}, {quantity: 2, price: 120, time: day("2023-02-01")}},
+			held:     3,
+		},
+		{
+			name:     "fractional shares",
+			trades:   []Trade{buy("VOO", 0.3, 400, "2023-01-02"), buy("VOO", 0.4, 410, "2023-01-03"), sell("VOO", 0.7, 420, "2024-01-02")},
+			consumed: []lot{{quantity: 0.3, price: 400, time: day("2023-01-02")}, {quantity: 0.4, price: 410, time: day("2023-01-03")}},
+		},
+		{
+			name:   "more than held",
+			trades: []Trade{buy("AAPL", 5, 100, "2023-01-02"), sell("AAPL", 6, 150, "2024-01-02")},
+			held:   5,
+			err:    ErrInsufficientLots,
+		},
+		{
+			name:   "other symbol",
+			trades: []Trade{buy("AAPL", 5, 100, "2023-01-02"), sell("TSLA", 1, 150, "2024-01-02")},
+			held:   5,
+			err:    ErrInsufficientLots,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := ledger{}
+			var consumed []lot
+			var err error
+			for _, trade := range tt.trades {
+				if consumed, err = l.apply(trade); err != nil {
+					break
+				}
+			}
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("apply = %v, want %v", err, tt.err)
+			}
+			if tt.err == nil && !sameLots(consumed, tt.consumed) {
+				t.Errorf("consumed %+v, want %+v", consumed, tt.consumed)
+			}
+			if held := l.quantity(tt.trades[0].Symbol); math.Abs(held-tt.held) > quantityEpsilon {
+				t.Errorf("%g shares held, want %g", held, tt.held)
+			}
+		})
+	}
+}
+
+func sameLots(a, b []lot) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if math.Abs(a[i].quantity-b[i].quantity) > quantityEpsilon || a[i].price != b[i].price || !a[i].time.Equal(b[i].time) {
+			return false
+		}
+	}
+	return true
+}
+
+func TestLedgerUnknownSide(t *testing.T) {
+	if _, err := (ledger{}).apply(Trade{Symbol: "AAPL", Side: "short", Time: day("2024-01-02")}); err == nil {
+		t.Error("apply accepted an unknown side")
+	}
+}
+
+func TestCapitalGains(t *testing.T) {
+	fy := FinancialYear{StartYear: 2024}
+	type gain struct {
+		quantity float64
+		gain     float64
+		longTerm bool
+	}
+	tests := []struct {
+		name   string
+		trades []Trade
+		gains  []gain
+		err    error
+	}{
+		{
+			name:   "exactly 24 months is short-term",
+			trades: []Trade{buy("AAPL", 1, 100, "2022-06-10"), sell("AAPL", 1, 150, "2024-06-10")},
+			gains:  []gain{{1, 50, false}},
+		},
+		{
+			name:   "a day over 24 months is long-term",
+			trades: []Trade{buy("AAPL", 1, 100, "2022-06-10"), sell("AAPL", 1, 150, "2024-06-11")},
+			gains:  []gain{{1, 50, true}},
+		},
+		{
+			name: "sale across the boundary",
+			trades: []Trade{
+				buy("AAPL", 4, 100, "2022-01-03"), buy("AAPL", 4, 200, "2023-09-01"),
+				sell("AAPL", 6, 150, "2024-07-01"),
+			},
+			gains: []gain{{4, 200, true}, {2, -100, false}},
+		},
+		{
+			name: "earlier sales use up the oldest lots",
+			trades: []Trade{
+				buy("AAPL", 2, 100, "2021-01-04"), buy("AAPL", 2, 120, "2023-01-04"),
+				sell("AAPL", 2, 130, "2023-06-01"), sell("AAPL", 1, 150, "2024-06-03"),
+			},
+			gains: []gain{{1, 30, false}},
+		},
+		{
+			name:   "trades out of order",
+			trades: []Trade{sell("AAPL", 1, 150, "2024-06-03"), buy("AAPL", 1, 100, "2021-01-04")},
+			gains:  []gain{{1, 50, true}},
+		},
+		{
+			name:   "sale after the year",
+			trades: []Trade{buy("AAPL", 1, 100, "2024-06-03"), sell("AAPL", 1, 150, "2025-04-02")},
+		},
+		{
+			name:   "more than held",
+			trades: []Trade{buy("AAPL", 1, 100, "2024-06-03"), sell("AAPL", 2, 150, "2024-07-01")},
+			err:    ErrInsufficientLots,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gains, err := CapitalGains(tt.trades, fy)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("CapitalGains = %v, want %v", err, tt.err)
+			}
+			if len(gains) != len(tt.gains) {
+				t.Fatalf("got %d gains, want %d: %+v", len(gains), len(tt.gains), gains)
+			}
+			for i, g := range gains {
+				want := tt.gains[i]
+				if g.Quantity != want.quantity || math.Abs(g.Gain-want.gain) > 1e-9 || g.LongTerm != want.longTerm {
+					t.Errorf("gain %d = %+v, want %+v", i, g, want)
+				}
+			}
+		})
+	}
+}
+
+func TestTradesFromTransactions(t *testing.T) {
+	trades, err := TradesFromTransactions([]stockal.Transaction{
+		{ID: "1", Type: stockal.TransactionBuy, Symbol: "AAPL", Quantity: 2, Price: 150, Amount: -300, Date: stockal.NewTime("2024-05-01T14:30:00Z")},
+		{ID: "2", Type: stockal.TransactionDividend, Symbol: "AAPL", Amount: 1.2, Date: stockal.NewTime("2024-05-02T00:00:00Z")},
+		{ID: "3", Type: stockal.TransactionSell, Symbol: "AAPL", Quantity: 1, Amount: 175, Date: stockal.NewTime("2024-06-01 10:00:00")},
+	})
+	if err != nil {
+		t.Fatalf("TradesFromTransactions: %v", err)
+	}
+	if len(trades) != 2 || trades[0].Side != stockal.OrderSideBuy || trades[1].Side != stockal.OrderSideSell {
+		t.Fatalf("got %+v, want the purchase and the sale", trades)
+	}
+	if trades[1].Price != 175 || trades[1].Time.IsZero() {
+		t.Errorf("sale = %+v, want its price from the amount and its time parsed", trades[1])
+	}
+
+	if _, err := TradesFromTransactions([]stockal.Transaction{{ID: "4", Type: stockal.TransactionBuy, Date: stockal.NewTime("soon")}}); err == nil {
+		t.Error("TradesFromTransactions accepted an invalid date")
+	}
+}
+
+func TestTradesFromOrders(t *testing.T) {
+	trades, err := TradesFromOrders([]stockal.Order{
+		{ID: "1", Symbol: "AAPL", Side: stockal.OrderSideBuy, FilledQuantity: 2, AveragePrice: 150,
+			CreatedAt: stockal.NewTime("2024-03-29T20:00:00Z"), FilledAt: stockal.NewTime("2024-04-01T13:30:00Z")},
+		{ID: "2", Symbol: "TSLA", Side: stockal.OrderSideBuy, FilledQuantity: 0, CreatedAt: stockal.NewTime("2024-04-01T13:30:00Z")},
+	})
+	if err != nil {
+		t.Fatalf("TradesFromOrders: %v", err)
+	}
+	if len(trades) != 1 || !trades[0].Time.Equal(time.Date(2024, time.April, 1, 13, 30, 0, 0, time.UTC)) {
+		t.Errorf("got %+v, want the filled order at its fill time", trades)
+	}
+}
+
+func TestConvertINR(t *testing.T) {
+	rates := &rbi.Rates{}
+	rates.Add(day("2023-06-15"), 82)
+	rates.Add(day("2024-04-30"), 83.4425)
+	rates.Add(day("2024-05-03"), 83.3895)
+
+	r := &Report{
+		Gains: []Gain{{Symbol: "AAPL", Name: "Apple Inc.", Quantity: 10, Acquired: day("2023-06-15"), Sold: day("2024-05-03"),
+			Cost: 1800, Proceeds: 2000, Gain: 200}},
+		// Credited on 16 May, so converted at the rate of 30 April
+		Dividends: []Dividend{{Symbol: "AAPL", Time: day("2024-05-16"), Gross: 2.5, Withheld: 0.625}},
+	}
+	if err := r.ConvertINR(context.Background(), rates); err != nil {
+		t.Fatalf("ConvertINR: %v", err)
+	}
+	tables := r.Tables()
+	cg, os := tables[0], tables[1]
+	if want := []string{"Cost of acquisition (INR)", "Full value of consideration (INR)", "Gain/loss (INR)"}; !slices.Equal(cg.Header[len(cg.Header)-3:], want) {
+		t.Errorf("Schedule CG header %q, want the rupee columns last", cg.Header)
+	}
+	if got, want := cg.Rows[0][len(cg.Header)-3:], []string{"147600.00", "166779.00", "19179.00"}; !slices.Equal(got, want) {
+		t.Errorf("Schedule CG rupees %q, want %q", got, want)
+	}
+	if got, want := os.Rows[0][len(os.Header)-3:], []string{"208.61", "52.15", "156.45"}; !slices.Equal(got, want) {
+		t.Errorf("Schedule OS rupees %q, want %q", got, want)
+	}
+
+	// A date without a rate fails the conversion
+	r.Gains[0].Acquired = day("2023-01-02")
+	if err := r.ConvertINR(context.Background(), rates); !errors.Is(err, stockal.ErrRateUnavailable) {
+		t.Errorf("ConvertINR without a rate = %v, want ErrRateUnavailable", err)
+	}
+}
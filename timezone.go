@@ -0,0 +1,112 @@
+package stockal
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// timeLayouts are the layouts ParseTime accepts besides Unix timestamps.
+// Layouts without a zone are read as UTC.
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999",
+	time.DateOnly,
+}
+
+// ParseTime parses a timestamp as the API sends them: RFC 3339, with or
+// without a zone (UTC is assumed), a date, or a Unix timestamp in seconds or
+// milliseconds. The result is in UTC; convert it with ExchangeTime or
+// time.Time.In for display.
+func ParseTime(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil && n > 0 {
+		return unixTime(n), nil
+	}
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.UTC(), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognised time %q", s)
+}
+
+// Time is a timestamp the API sends as a string. It holds the time ParseTime
+// reads from the string, or the zero time if the string is empty or not
+// recognised, and the string as sent, which it marshals back to so that data
+// round-trips unchanged.
+type Time struct {
+	time.Time
+	// Raw is the timestamp as the API sent it
+	Raw string
+}
+
+// NewTime returns the Time the API would send as raw.
+func NewTime(raw string) Time {
+	t, _ := ParseTime(raw)
+	return Time{Time: t, Raw: raw}
+}
+
+// String returns the timestamp as the API sent it, or formatted as RFC 3339
+// if it was not received from the API.
+func (t Time) String() string {
+	if t.Raw == "" && !t.Time.IsZero() {
+		return t.Time.Format(time.RFC3339Nano)
+	}
+	return t.Raw
+}
+
+// UnmarshalJSON decodes a timestamp sent as a string or as a Unix timestamp.
+// Timestamps that are not recognised leave the time zero and are not an
+// error, so that one odd value does not fail the whole response.
+func (t *Time) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		*t = Time{}
+		return nil
+	}
+	raw := string(data)
+	if len(data) > 0 && data[0] == '"' {
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return err
+		}
+	}
+	*t = NewTime(raw)
+	return nil
+}
+
+// MarshalJSON encodes the timestamp as String returns it, as a JSON string
+// even if the API sent a number.
+func (t Time) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.String())
+}
+
+// unixTime converts a Unix timestamp in seconds or milliseconds to a UTC
+// time, returning the zero time for zero.
+func unixTime(ts int64) time.Time {
+	switch {
+	case ts <= 0:
+		return time.Time{}
+	case ts > 1e12:
+		return time.UnixMilli(ts).UTC()
+	}
+	return time.Unix(ts, 0).UTC()
+}
+
+// ExchangeLocation returns the time zone of the US exchanges,
+// America/New_York, or a fixed EST offset when the system has no time zone
+// database.
+func ExchangeLocation() *time.Location {
+	return exchangeLocation()
+}
+
+// ExchangeTime returns t in the time zone of the US exchanges, as market
+// hours and closing prices are quoted. For the user's own zone, such as IST,
+// use t.Local() or t.In.
+func ExchangeTime(t time.Time) time.Time {
+	return t.In(exchangeLocation())
+}
@@ -0,0 +1,94 @@
+package stockal_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+	_ "time/tzdata" // for America/New_York on systems without a zone database
+
+	"github.com/adjaecent/unofficial-stockal-api"
+)
+
+func TestParseTime(t *testing.T) {
+	want := time.Date(2025, 3, 14, 13, 30, 0, 0, time.UTC)
+	for _, s := range []string{
+		"2025-03-14T13:30:00Z",
+		"2025-03-14T13:30:00.000Z",
+		"2025-03-14T19:00:00+05:30",
+		"2025-03-14T13:30:00",
+		"2025-03-14 13:30:00",
+		" 1741959000 ",
+		"1741959000000",
+	} {
+		got, err := stockal.ParseTime(s)
+		if err != nil || !got.Equal(want) || got.Location() != time.UTC {
+			t.Errorf("ParseTime(%q) = %v, %v; want %v", s, got, err, want)
+		}
+	}
+	if got, err := stockal.ParseTime("2025-03-14"); err != nil || !got.Equal(time.Date(2025, 3, 14, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("ParseTime(date) = %v, %v", got, err)
+	}
+	for _, s := range []string{"", "yesterday", "0", "14/03/2025"} {
+		if _, err := stockal.ParseTime(s); err == nil {
+			t.Errorf("ParseTime(%q) succeeded", s)
+		}
+	}
+}
+
+func TestExchangeTime(t *testing.T) {
+	data := stockal.AccountSummaryData{UTCTime: stockal.NewTime("2025-03-14T13:30:00Z")}
+	asOf := data.AsOf()
+
+	// 13:30 UTC is the opening bell in New York and 19:00 in India
+	if got := stockal.ExchangeTime(asOf).Format("15:04"); got != "09:30" {
+		t.Errorf("ExchangeTime = %s, want 09:30", got)
+	}
+	ist := time.FixedZone("IST", 5*60*60+30*60)
+	if got := asOf.In(ist).Format("15:04"); got != "19:00" {
+		t.Errorf("IST time = %s, want 19:00", got)
+	}
+
+	if !(stockal.AccountSummaryData{}).AsOf().IsZero() {
+		t.Error("AsOf without UTCTime is not zero")
+	}
+	h := stockal.Holding{Timestamp: 1741959000000, Date: stockal.NewTime("2020-01-01")}
+	if !h.UpdatedAt().Equal(asOf) {
+		t.Errorf("UpdatedAt() = %v, want %v", h.UpdatedAt(), asOf)
+	}
+	h.Timestamp = 0
+	if h.UpdatedAt().Year() != 2020 {
+		t.Errorf("UpdatedAt() = %v, want Date", h.UpdatedAt())
+	}
+}
+
+func TestTimeJSON(t *testing.T) {
+	var data stockal.LoginData
+	body := `{"accessToken":"a","expiryAccessToken":"2025-03-14 13:30:00","expiryRefreshToken":"soon"}`
+	if err := json.Unmarshal([]byte(body), &data); err != nil {
+		t.Fatal(err)
+	}
+	if !data.ExpiryAccessToken.Equal(time.Date(2025, 3, 14, 13, 30, 0, 0, time.UTC)) || data.ExpiryAccessToken.Raw != "2025-03-14 13:30:00" {
+		t.Errorf("ExpiryAccessToken = %+v", data.ExpiryAccessToken)
+	}
+	if !data.ExpiryRefreshToken.IsZero() || data.ExpiryRefreshToken.String() != "soon" {
+		t.Errorf("ExpiryRefreshToken = %+v, want zero time with the string kept", data.ExpiryRefreshToken)
+	}
+
+	// The timestamps are sent back as received
+	out, err := json.Marshal(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(out), `"expiryAccessToken":"2025-03-14 13:30:00","expiryRefreshToken":"soon"`) {
+		t.Errorf("Marshal = %s", out)
+	}
+
+	var h stockal.Holding
+	if err := json.Unmarshal([]byte(`{"Date":1741959000}`), &h); err != nil {
+		t.Fatal(err)
+	}
+	if h.Date.Raw != "1741959000" || !h.UpdatedAt().Equal(time.Date(2025, 3, 14, 13, 30, 0, 0, time.UTC)) {
+		t.Errorf("Date = %+v", h.Date)
+	}
+}
@@ -0,0 +1,145 @@
+package stockal
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// TokenStore persists session tokens so a client can resume a session
+// without logging in again.
+//
+// Load returns nil and no error when no token has been saved.
+type TokenStore interface {
+	Load(ctx context.Context) (*LoginData, error)
+	Save(ctx context.Context, token *LoginData) error
+	Clear(ctx context.Context) error
+}
+
+// WithTokenStore sets a TokenStore used to resume a saved session and to
+// persist tokens obtained by Login and Refresh.
+func WithTokenStore(store TokenStore) ClientOption {
+	return func(c *clientConfig) {
+		c.tokenStore = store
+	}
+}
+
+// MemoryTokenStore keeps the token in memory, for sharing one session among
+// clients in a process, and for tests. It is safe for concurrent use.
+type MemoryTokenStore struct {
+	mu    sync.Mutex
+	token *LoginData
+}
+
+// NewMemoryTokenStore creates a MemoryTokenStore holding token, which may be
+// nil.
+func NewMemoryTokenStore(token *LoginData) *MemoryTokenStore {
+	s := &MemoryTokenStore{}
+	if token != nil {
+		copied := *token
+		s.token = &copied
+	}
+	return s
+}
+
+// Load returns a copy of the token.
+func (s *MemoryTokenStore) Load(ctx context.Context) (*LoginData, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.token == nil {
+		return nil, nil
+	}
+	token := *s.token
+	return &token, nil
+}
+
+// Save keeps a copy of the token, replacing any previous token.
+func (s *MemoryTokenStore) Save(ctx context.Context, token *LoginData) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	copied := *token
+	s.token = &copied
+	return nil
+}
+
+// Clear forgets the token.
+func (s *MemoryTokenStore) Clear(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.token = nil
+	return nil
+}
+
+// FileTokenStore stores the token as JSON in a file readable only by its
+// owner, for short-lived jobs on machines without an OS keyring, such as
+// servers and containers. Anyone who can read the file can use the session,
+// so keep it out of shared and backed-up directories.
+type FileTokenStore struct {
+	path string
+}
+
+// NewFileTokenStore creates a FileTokenStore that keeps the token in the file
+// at path, creating its directory when saving if needed.
+func NewFileTokenStore(path string) *FileTokenStore {
+	return &FileTokenStore{path: path}
+}
+
+// Load reads the token from the file.
+func (s *FileTokenStore) Load(ctx context.Context) (*LoginData, error) {
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("token file: %w", err)
+	}
+
+	var token LoginData
+	if err := json.Unmarshal(data, &token); err != nil {
+		return nil, fmt.Errorf("token file %s: invalid token: %w", s.path, err)
+	}
+	return &token, nil
+}
+
+// Save writes the token to the file, replacing any previous token. The file
+// is replaced atomically, so that a concurrent Load never reads half a token.
+func (s *FileTokenStore) Save(ctx context.Context, token *LoginData) error {
+	data, err := json.Marshal(token)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return fmt.Errorf("token file: %w", err)
+	}
+	// CreateTemp makes the file readable only by its owner
+	f, err := os.CreateTemp(filepath.Dir(s.path), ".stockal-token-*")
+	if err != nil {
+		return fmt.Errorf("token file: %w", err)
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return fmt.Errorf("token file: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("token file: %w", err)
+	}
+	if err := os.Rename(f.Name(), s.path); err != nil {
+		return fmt.Errorf("token file: %w", err)
+	}
+	return nil
+}
+
+// Clear removes the file.
+func (s *FileTokenStore) Clear(ctx context.Context) error {
+	if err := os.Remove(s.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("token file: %w", err)
+	}
+	return nil
+}
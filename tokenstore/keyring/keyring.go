@@ -0,0 +1,73 @@
+//go:build !(js && wasm)
+
+// Package keyring stores Stockal sessions in the operating system keyring
+// (macOS Keychain, Windows Credential Manager, or the Secret Service on
+// Linux) as a stockal.TokenStore. It is a separate package so that programs
+// which do not use it need not link the keyring libraries, nor D-Bus on
+// Linux:
+//
+//	client := stockal.NewClient(
+//		stockal.WithTokenStore(keyring.New("my-app", "default")),
+//	)
+package keyring
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/zalando/go-keyring"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+)
+
+// TokenStore stores tokens in the operating system keyring. It implements
+// stockal.TokenStore.
+type TokenStore struct {
+	service string
+	account string
+}
+
+// New creates a TokenStore that keeps the token under the given keyring
+// service and account names.
+func New(service, account string) *TokenStore {
+	return &TokenStore{service: service, account: account}
+}
+
+// Load reads the token from the keyring.
+func (s *TokenStore) Load(ctx context.Context) (*stockal.LoginData, error) {
+	secret, err := keyring.Get(s.service, s.account)
+	if errors.Is(err, keyring.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("keyring: %w", err)
+	}
+
+	var token stockal.LoginData
+	if err := json.Unmarshal([]byte(secret), &token); err != nil {
+		return nil, fmt.Errorf("keyring: invalid token: %w", err)
+	}
+	return &token, nil
+}
+
+// Save writes the token to the keyring, replacing any previous token.
+func (s *TokenStore) Save(ctx context.Context, token *stockal.LoginData) error {
+	secret, err := json.Marshal(token)
+	if err != nil {
+		return err
+	}
+	if err := keyring.Set(s.service, s.account, string(secret)); err != nil {
+		return fmt.Errorf("keyring: %w", err)
+	}
+	return nil
+}
+
+// Clear removes the token from the keyring.
+func (s *TokenStore) Clear(ctx context.Context) error {
+	if err := keyring.Delete(s.service, s.account); err != nil && !errors.Is(err, keyring.ErrNotFound) {
+		return fmt.Errorf("keyring: %w", err)
+	}
+	return nil
+}
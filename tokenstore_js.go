@@ -0,0 +1,90 @@
+//go:build js && wasm
+
+package stockal
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"syscall/js"
+)
+
+// LocalStorageTokenStore stores tokens in the browser's localStorage, for
+// clients compiled to WebAssembly. Anything running on the page's origin can
+// read localStorage, so only use it where that is acceptable.
+type LocalStorageTokenStore struct {
+	key string
+}
+
+// NewLocalStorageTokenStore creates a LocalStorageTokenStore that keeps the
+// token under the given localStorage key.
+func NewLocalStorageTokenStore(key string) *LocalStorageTokenStore {
+	return &LocalStorageTokenStore{key: key}
+}
+
+// Load reads the token from localStorage.
+func (s *LocalStorageTokenStore) Load(ctx context.Context) (*LoginData, error) {
+	storage, err := localStorage()
+	if err != nil {
+		return nil, err
+	}
+	secret := storage.Call("getItem", s.key)
+	if secret.IsNull() {
+		return nil, nil
+	}
+
+	var token LoginData
+	if err := json.Unmarshal([]byte(secret.String()), &token); err != nil {
+		return nil, fmt.Errorf("localStorage: invalid token: %w", err)
+	}
+	return &token, nil
+}
+
+// Save writes the token to localStorage, replacing any previous token.
+func (s *LocalStorageTokenStore) Save(ctx context.Context, token *LoginData) error {
+	storage, err := localStorage()
+	if err != nil {
+		return err
+	}
+	secret, err := json.Marshal(token)
+	if err != nil {
+		return err
+	}
+	return jsCall(func() { storage.Call("setItem", s.key, string(secret)) })
+}
+
+// Clear removes the token from localStorage.
+func (s *LocalStorageTokenStore) Clear(ctx context.Context) error {
+	storage, err := localStorage()
+	if err != nil {
+		return err
+	}
+	return jsCall(func() { storage.Call("removeItem", s.key) })
+}
+
+// localStorage returns window.localStorage, which is missing outside
+// browsers and may be blocked by privacy settings.
+func localStorage() (storage js.Value, err error) {
+	err = jsCall(func() { storage = js.Global().Get("localStorage") })
+	if err == nil && (storage.IsUndefined() || storage.IsNull()) {
+		err = errors.New("localStorage: not available")
+	}
+	return storage, err
+}
+
+// jsCall runs f, converting a thrown JavaScript exception (e.g. a full
+// storage quota) to an error.
+func jsCall(f func()) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			if jsErr, ok := r.(js.Error); ok {
+				err = fmt.Errorf("localStorage: %w", jsErr)
+				return
+			}
+			panic(r)
+		}
+	}()
+	f()
+	return nil
+}
@@ -0,0 +1,165 @@
+package stockal_test
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+)
+
+func TestFileTokenStore(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "sessions", "default.json")
+	store := stockal.NewFileTokenStore(path)
+
+	if token, err := store.Load(ctx); token != nil || err != nil {
+		t.Fatalf("Load before Save = %+v, %v; want nil, nil", token, err)
+	}
+	saved := &stockal.LoginData{AccessToken: "access", RefreshToken: "refresh", ExpiryAccessToken: stockal.NewTime("2030-01-01T00:00:00Z")}
+	if err := store.Save(ctx, saved); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	if info, err := os.Stat(path); err != nil || runtime.GOOS != "windows" && info.Mode().Perm() != 0o600 {
+		t.Errorf("token file: %v, %v; want mode 0600", info.Mode(), err)
+	}
+	if token, err := store.Load(ctx); err != nil || *token != *saved {
+		t.Errorf("Load = %+v, %v; want %+v", token, err, saved)
+	}
+
+	if err := store.Clear(ctx); err != nil {
+		t.Fatalf("Clear: %v", err)
+	}
+	if err := store.Clear(ctx); err != nil {
+		t.Errorf("Clear without a token: %v", err)
+	}
+	if token, err := store.Load(ctx); token != nil || err != nil {
+		t.Errorf("Load after Clear = %+v, %v; want nil, nil", token, err)
+	}
+}
+
+func TestMemoryTokenStore(t *testing.T) {
+	ctx := context.Background()
+	token := &stockal.LoginData{AccessToken: "access"}
+	store := stockal.NewMemoryTokenStore(token)
+
+	// The store keeps copies, so callers cannot change the saved token
+	token.AccessToken = "changed"
+	loaded, err := store.Load(ctx)
+	if err != nil || loaded.AccessToken != "access" {
+		t.Fatalf("Load = %+v, %v", loaded, err)
+	}
+	loaded.AccessToken = "changed"
+	if again, _ := store.Load(ctx); again.AccessToken != "access" {
+		t.Errorf("Load = %+v after changing a loaded token", again)
+	}
+
+	if err := store.Clear(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if loaded, err := store.Load(ctx); loaded != nil || err != nil {
+		t.Errorf("Load after Clear = %+v, %v; want nil, nil", loaded, err)
+	}
+}
+
+func TestResumeSessionFromFile(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "saved" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"code":200,"message":"Success","data":{"accountSummary":{"cashBalance":25}}}`))
+	}))
+	defer srv.Close()
+	store := stockal.NewFileTokenStore(filepath.Join(t.TempDir(), "token.json"))
+	if err := store.Save(context.Background(), &stockal.LoginData{AccessToken: "saved"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// A new process resumes the session without credentials
+	client := stockal.NewClient(stockal.WithBaseURL(srv.URL), stockal.WithTokenStore(store))
+	summary, err := client.Account().Summary(context.Background())
+	if err != nil || summary.Data.AccountSummary.CashBalance != 25 {
+		t.Errorf("GetAccountSummary = %+v, %v", summary, err)
+	}
+}
+
+func TestWithAccessToken(t *testing.T) {
+	var authorizations []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/v3/auth/refresh" {
+			w.Write([]byte(`{"code":200,"message":"Success","data":{"accessToken":"fresh","refreshToken":"refresh2"}}`))
+			return
+		}
+		authorizations = append(authorizations, r.Header.Get("Authorization"))
+		w.Write([]byte(`{"code":200,"message":"Success","data":{}}`))
+	}))
+	defer srv.Close()
+	store := stockal.NewMemoryTokenStore(&stockal.LoginData{AccessToken: "stored"})
+
+	// The session given takes precedence over the saved one
+	client := stockal.NewClient(stockal.WithBaseURL(srv.URL), stockal.WithTokenStore(store), stockal.WithAccessToken("given", ""))
+	if _, err := client.Account().Summary(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	// A JWT that has expired is refreshed before it is used, and the fresh
+	// session saved
+	expired := "eyJhbGciOiJIUzI1NiJ9." + base64.RawURLEncoding.EncodeToString([]byte(`{"exp":1000000000}`)) + ".c2ln"
+	client = stockal.NewClient(stockal.WithBaseURL(srv.URL), stockal.WithTokenStore(store), stockal.WithAccessToken(expired, "refresh"))
+	if _, err := client.Account().Summary(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(authorizations, []string{"given", "fresh"}) {
+		t.Errorf("requests sent Authorization %q", authorizations)
+	}
+	if saved, _ := store.Load(context.Background()); saved.AccessToken != "fresh" {
+		t.Errorf("saved %+v, want the refreshed session", saved)
+	}
+}
+
+func TestConcurrentRefresh(t *testing.T) {
+	var refreshes atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/v3/auth/refresh" {
+			refreshes.Add(1)
+			w.Write([]byte(`{"code":200,"message":"Success","data":{"accessToken":"fresh","refreshToken":"refresh2"}}`))
+			return
+		}
+		if r.Header.Get("Authorization") != "fresh" {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+		w.Write([]byte(`{"code":200,"message":"Success","data":{}}`))
+	}))
+	defer srv.Close()
+
+	// Calls made together with an expired session refresh it once, and all
+	// use the fresh one
+	expired := "eyJhbGciOiJIUzI1NiJ9." + base64.RawURLEncoding.EncodeToString([]byte(`{"exp":1000000000}`)) + ".c2ln"
+	client := stockal.NewClient(stockal.WithBaseURL(srv.URL), stockal.WithAccessToken(expired, "refresh"))
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := client.Account().Summary(context.Background()); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := refreshes.Load(); n != 1 {
+		t.Errorf("session refreshed %d times, want once", n)
+	}
+}
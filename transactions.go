@@ -0,0 +1,267 @@
+package stockal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TransactionReader is implemented by clients that can read the account's
+// activity: trades, dividends, fees and fund movements.
+type TransactionReader interface {
+	GetTransactions(ctx context.Context, opts TransactionOptions) (*TransactionListResponse, error)
+}
+
+// TransactionType is the kind of an account transaction. Stockal may add
+// types, so other values should be expected.
+type TransactionType string
+
+// Transaction types.
+const (
+	TransactionBuy        TransactionType = "buy"
+	TransactionSell       TransactionType = "sell"
+	TransactionDividend   TransactionType = "dividend"
+	TransactionFee        TransactionType = "fee"
+	TransactionTax        TransactionType = "tax"
+	TransactionDeposit    TransactionType = "deposit"
+	TransactionWithdrawal TransactionType = "withdrawal"
+)
+
+// IsKnown reports whether t is one of the declared transaction types.
+func (t TransactionType) IsKnown() bool {
+	switch t {
+	case TransactionBuy, TransactionSell, TransactionDividend, TransactionFee,
+		TransactionTax, TransactionDeposit, TransactionWithdrawal:
+		return true
+	}
+	return false
+}
+
+// IsDebit reports whether transactions of type t take cash out of the
+// account.
+func (t TransactionType) IsDebit() bool {
+	switch t {
+	case TransactionBuy, TransactionFee, TransactionTax, TransactionWithdrawal:
+		return true
+	}
+	return false
+}
+
+// UnmarshalJSON decodes a transaction type, matching the declared ones
+// regardless of case and surrounding space. Unknown types are kept as sent.
+func (t *TransactionType) UnmarshalJSON(data []byte) error {
+	v, err := decodeEnum(data, TransactionBuy, TransactionSell, TransactionDividend, TransactionFee,
+		TransactionTax, TransactionDeposit, TransactionWithdrawal)
+	*t = v
+	return err
+}
+
+// Transaction represents one entry in the account's activity.
+type Transaction struct {
+	// ID is the transaction identifier
+	ID string `json:"transactionID"`
+	// Type is the kind of transaction, e.g. "buy" or "dividend"
+	Type TransactionType `json:"type"`
+	// Symbol is the stock symbol traded or paying a dividend; empty for fund movements
+	Symbol string `json:"symbol,omitempty"`
+	// Quantity is the number of shares traded; zero for other transactions
+	Quantity float64 `json:"quantity,omitempty"`
+	// Price is the price per share traded; zero for other transactions
+	Price float64 `json:"price,omitempty"`
+	// Amount is the cash amount of the transaction in US dollars, negative for money out when the API signs it
+	Amount float64 `json:"amount"`
+	// Description is the transaction's description, as the API gives it
+	Description string `json:"description,omitempty"`
+	// OrderID is the order a trade filled; empty for other transactions
+	OrderID string `json:"orderID,omitempty"`
+	// Date is when the transaction happened
+	Date Time `json:"date"`
+	// Extra holds the fields the API sent that this struct does not model
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// CashFlow returns the transaction's effect on the cash balance: positive for
+// money in, such as sales, dividends and deposits, and negative for money
+// out, such as purchases, fees and withdrawals. Amounts the API sends
+// unsigned are signed by Type.
+func (t Transaction) CashFlow() float64 {
+	if t.Amount > 0 && t.Type.IsDebit() {
+		return -t.Amount
+	}
+	return t.Amount
+}
+
+// TransactionListData represents the data payload of a transaction list response.
+type TransactionListData struct {
+	// Transactions contains the transactions, newest first
+	Transactions []Transaction `json:"transactions"`
+	// TotalRecords is the total number of transactions
+	TotalRecords int `json:"totalRecords"`
+}
+
+// TransactionListResponse represents the response from the transaction list API.
+type TransactionListResponse struct {
+	// Code is the HTTP response code
+	Code int `json:"code"`
+	// Message is the response message (usually "Success")
+	Message string `json:"message"`
+	// Data contains the transactions
+	Data TransactionListData `json:"data"`
+}
+
+func (r *TransactionListResponse) normalize() {
+	if r.Data.Transactions == nil {
+		r.Data.Transactions = []Transaction{}
+	}
+}
+
+// TransactionOptions selects transactions from the account's activity. The
+// zero value selects the most recent transactions of every type.
+type TransactionOptions struct {
+	// Types, if set, selects the transactions of these types
+	Types []TransactionType
+	// Symbol, if set, selects the transactions for one symbol
+	Symbol string
+	// From, if set, selects the transactions at or after it
+	From time.Time
+	// To, if set, selects the transactions before it
+	To time.Time
+
+	// Limit is the maximum number of transactions to return; zero means the
+	// API's default
+	Limit int
+	// Offset is the number of transactions to skip
+	Offset int
+}
+
+// Validate checks the options before they are sent. The returned error wraps
+// ErrInvalidParams.
+func (o TransactionOptions) Validate() error {
+	switch {
+	case o.Limit < 0:
+		return fmt.Errorf("%w: negative limit %d", ErrInvalidParams, o.Limit)
+	case o.Offset < 0:
+		return fmt.Errorf("%w: negative offset %d", ErrInvalidParams, o.Offset)
+	case !o.From.IsZero() && !o.To.IsZero() && !o.From.Before(o.To):
+		return fmt.Errorf("%w: empty date range %s to %s", ErrInvalidParams,
+			o.From.Format(time.RFC3339), o.To.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// matches reports whether t passes the type, symbol and date filters.
+// Transactions without a date pass the date filters.
+func (o TransactionOptions) matches(t Transaction) bool {
+	if len(o.Types) > 0 && !slices.Contains(o.Types, t.Type) {
+		return false
+	}
+	if o.Symbol != "" && !strings.EqualFold(t.Symbol, strings.TrimSpace(o.Symbol)) {
+		return false
+	}
+	at := t.Date.Time
+	if at.IsZero() {
+		return true
+	}
+	return (o.From.IsZero() || !at.Before(o.From)) && (o.To.IsZero() || at.Before(o.To))
+}
+
+// query returns the options as a query string, without the leading "?".
+func (o TransactionOptions) query() string {
+	query := url.Values{}
+	if len(o.Types) > 0 {
+		types := make([]string, len(o.Types))
+		for i, t := range o.Types {
+			types[i] = string(t)
+		}
+		query.Set("type", strings.Join(types, ","))
+	}
+	if symbol := strings.TrimSpace(o.Symbol); symbol != "" {
+		query.Set("symbol", strings.ToUpper(symbol))
+	}
+	if !o.From.IsZero() {
+		query.Set("from", o.From.UTC().Format(time.RFC3339))
+	}
+	if !o.To.IsZero() {
+		query.Set("to", o.To.UTC().Format(time.RFC3339))
+	}
+	if o.Limit > 0 {
+		query.Set("limit", strconv.Itoa(o.Limit))
+	}
+	if o.Offset > 0 {
+		query.Set("offset", strconv.Itoa(o.Offset))
+	}
+	return query.Encode()
+}
+
+// GetTransactions retrieves the account's activity that opts select: buys,
+// sells, dividends, fees, taxes and fund movements, newest first.
+// Data.TotalRecords is the number of transactions matching the filters.
+//
+// The filters are applied by the API. The client applies them to the response
+// as well, so that a filtered call never returns other transactions.
+//
+// Example:
+//
+//	dividends, err := client.GetTransactions(ctx, stockal.TransactionOptions{
+//		Types: []stockal.TransactionType{stockal.TransactionDividend},
+//		From:  time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC),
+//	})
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	for _, t := range dividends.Data.Transactions {
+//		fmt.Printf("%s %s $%.2f\n", t.Date, t.Symbol, t.Amount)
+//	}
+func (c *Client) GetTransactions(ctx context.Context, opts TransactionOptions) (*TransactionListResponse, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	endpoint := "/v2/users/transactions"
+	if query := opts.query(); query != "" {
+		endpoint += "?" + query
+	}
+	var listResp TransactionListResponse
+	if err := c.do(ctx, "GET", endpoint, nil, &listResp, "list transactions"); err != nil {
+		return nil, err
+	}
+
+	transactions := listResp.Data.Transactions[:0]
+	for _, t := range listResp.Data.Transactions {
+		if opts.matches(t) {
+			transactions = append(transactions, t)
+		}
+	}
+	listResp.Data.Transactions = transactions
+	return &listResp, nil
+}
+
+// TransactionIterator returns an Iterator over every transaction opts select,
+// reading Limit transactions per request (100 if unset) from Offset on.
+func TransactionIterator(r TransactionReader, opts TransactionOptions) *Iterator[Transaction] {
+	if opts.Limit == 0 {
+		opts.Limit = defaultPageSize
+	}
+	return newIterator("read transactions", func(t Transaction) string { return t.ID }, func(ctx context.Context) ([]Transaction, bool, error) {
+		page, err := r.GetTransactions(ctx, opts)
+		if err != nil {
+			return nil, false, err
+		}
+		transactions := page.Data.Transactions
+		opts.Offset += len(transactions)
+		return transactions, len(transactions) > 0 && opts.Offset < page.Data.TotalRecords, nil
+	})
+}
+
+// AllTransactions reads every transaction opts select, as TransactionIterator
+// does. If ctx is cancelled between pages, it returns the transactions read so
+// far with a *PartialError naming their IDs; to resume, advance Offset by the
+// number of transactions returned and call it again.
+func AllTransactions(ctx context.Context, r TransactionReader, opts TransactionOptions) ([]Transaction, error) {
+	return TransactionIterator(r, opts).All(ctx)
+}
@@ -0,0 +1,118 @@
+package stockal_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+)
+
+func TestGetTransactions(t *testing.T) {
+	var path, query string
+	client := newHandlerClient(t, func(w http.ResponseWriter, r *http.Request) {
+		path, query = r.URL.Path, r.URL.RawQuery
+		// Every transaction, as from an API that ignores the filters
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"code":200,"message":"Success","data":{"transactions":[` +
+			`{"transactionID":"t1","type":"Deposit","amount":1000,"date":"2024-01-10T09:00:00Z"},` +
+			`{"transactionID":"t2","type":"buy","symbol":"AAPL","quantity":2,"price":150,"amount":-300,"orderID":"o1","date":"2024-01-15T10:00:00Z"},` +
+			`{"transactionID":"t3","type":"dividend","symbol":"AAPL","amount":0.48,"date":"2024-02-15T00:00:00Z","exDate":"2024-02-09"},` +
+			`{"transactionID":"t4","type":"adr_fee","symbol":"TSM","amount":-0.1,"date":"2024-03-01T00:00:00Z"}],"totalRecords":4}}`))
+	})
+
+	feb := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		opts  stockal.TransactionOptions
+		query string
+		ids   []string
+	}{
+		{stockal.TransactionOptions{}, "", []string{"t1", "t2", "t3", "t4"}},
+		{stockal.TransactionOptions{Symbol: "aapl"}, "symbol=AAPL", []string{"t2", "t3"}},
+		{
+			stockal.TransactionOptions{Types: []stockal.TransactionType{stockal.TransactionDeposit, stockal.TransactionBuy}, Limit: 10},
+			"limit=10&type=deposit%2Cbuy", []string{"t1", "t2"},
+		},
+		{stockal.TransactionOptions{From: feb}, "from=2024-02-01T00%3A00%3A00Z", []string{"t3", "t4"}},
+	}
+	for _, tt := range tests {
+		resp, err := client.GetTransactions(context.Background(), tt.opts)
+		if err != nil {
+			t.Fatalf("GetTransactions(%+v): %v", tt.opts, err)
+		}
+		if path != "/v2/users/transactions" || query != tt.query {
+			t.Errorf("GetTransactions(%+v) sent %s?%s, want query %q", tt.opts, path, query, tt.query)
+		}
+		var ids []string
+		for _, tx := range resp.Data.Transactions {
+			ids = append(ids, tx.ID)
+		}
+		if !slices.Equal(ids, tt.ids) {
+			t.Errorf("GetTransactions(%+v) returned %v, want %v", tt.opts, ids, tt.ids)
+		}
+	}
+
+	resp, err := client.GetTransactions(context.Background(), stockal.TransactionOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	txs := resp.Data.Transactions
+	if txs[0].Type != stockal.TransactionDeposit || txs[3].Type.IsKnown() {
+		t.Errorf("types %q and %q", txs[0].Type, txs[3].Type)
+	}
+	if !txs[1].Date.Equal(time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)) || txs[1].CashFlow() != -300 {
+		t.Errorf("got %+v", txs[1])
+	}
+	if string(txs[2].Extra["exDate"]) != `"2024-02-09"` {
+		t.Errorf("Extra = %v", txs[2].Extra)
+	}
+
+	for _, opts := range []stockal.TransactionOptions{
+		{Limit: -1},
+		{Offset: -1},
+		{From: feb, To: feb},
+	} {
+		if _, err := client.GetTransactions(context.Background(), opts); !errors.Is(err, stockal.ErrInvalidParams) {
+			t.Errorf("GetTransactions(%+v) error = %v, want ErrInvalidParams", opts, err)
+		}
+	}
+}
+
+func TestTransactionCashFlow(t *testing.T) {
+	tests := []struct {
+		json string
+		flow float64
+	}{
+		{`{"type":"sell","amount":250}`, 250},
+		{`{"type":"withdrawal","amount":100}`, -100},
+		{`{"type":"withdrawal","amount":-100}`, -100},
+		{`{"type":"tax","amount":0.12}`, -0.12},
+		{`{"type":"interest","amount":0.5}`, 0.5},
+	}
+	for _, tt := range tests {
+		var tx stockal.Transaction
+		if err := json.Unmarshal([]byte(tt.json), &tx); err != nil {
+			t.Fatal(err)
+		}
+		if tx.CashFlow() != tt.flow {
+			t.Errorf("CashFlow() of %s = %v, want %v", tt.json, tx.CashFlow(), tt.flow)
+		}
+	}
+}
+
+func TestAllTransactions(t *testing.T) {
+	client := newTestClientFunc(t, func(r *http.Request) string {
+		if r.URL.Query().Get("offset") == "" {
+			return `{"code":200,"data":{"transactions":[{"transactionID":"t1"},{"transactionID":"t2"}],"totalRecords":3}}`
+		}
+		return `{"code":200,"data":{"transactions":[{"transactionID":"t3"}],"totalRecords":3}}`
+	})
+	transactions, err := stockal.AllTransactions(context.Background(), client, stockal.TransactionOptions{Limit: 2})
+	if err != nil || len(transactions) != 3 || transactions[2].ID != "t3" {
+		t.Errorf("AllTransactions = %+v, %v", transactions, err)
+	}
+}
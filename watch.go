@@ -0,0 +1,170 @@
+package stockal
+
+import (
+	"context"
+	"slices"
+	"time"
+)
+
+// ChangeKind is the kind of a PortfolioChange.
+type ChangeKind string
+
+const (
+	// ChangeHoldingAdded means a symbol was bought that was not held before
+	ChangeHoldingAdded ChangeKind = "holding_added"
+	// ChangeHoldingRemoved means a holding was sold in full
+	ChangeHoldingRemoved ChangeKind = "holding_removed"
+	// ChangeUnitsChanged means more units of a holding were bought, or some
+	// sold
+	ChangeUnitsChanged ChangeKind = "units_changed"
+	// ChangePriceMoved means the price of a holding changed
+	ChangePriceMoved ChangeKind = "price_moved"
+	// ChangeCashChanged means the cash balance changed
+	ChangeCashChanged ChangeKind = "cash_changed"
+	// ChangeError means the portfolio could not be read; watching goes on
+	ChangeError ChangeKind = "error"
+)
+
+// PortfolioChange is a difference between two snapshots of an account, or a
+// failure to take one.
+type PortfolioChange struct {
+	// Kind is what changed
+	Kind ChangeKind
+	// At is when the snapshot showing the change was taken
+	At time.Time
+	// Symbol is the symbol of the holding that changed
+	Symbol string
+	// Before is the holding in the earlier snapshot; nil if it was added
+	Before *Holding
+	// After is the holding in the later snapshot; nil if it was removed
+	After *Holding
+	// CashBefore and CashAfter are the cash balances, for ChangeCashChanged
+	CashBefore, CashAfter float64
+	// Err is why the portfolio could not be read, for ChangeError
+	Err error
+}
+
+// DiffSnapshots returns the changes from prev to next: the cash balance
+// first, then the holdings added, removed, bought or sold and repriced, in
+// symbol order. A holding whose units and price both changed has one change
+// of each kind.
+func DiffSnapshots(prev, next *Snapshot) []PortfolioChange {
+	var changes []PortfolioChange
+	if before, after := prev.Summary.AccountSummary.CashBalance, next.Summary.AccountSummary.CashBalance; before != after {
+		changes = append(changes, PortfolioChange{Kind: ChangeCashChanged, At: next.TakenAt, CashBefore: before, CashAfter: after})
+	}
+
+	before := holdingsBySymbol(prev.Holdings)
+	after := holdingsBySymbol(next.Holdings)
+	symbols := make([]string, 0, len(before)+len(after))
+	for symbol := range before {
+		symbols = append(symbols, symbol)
+	}
+	for symbol := range after {
+		if _, ok := before[symbol]; !ok {
+			symbols = append(symbols, symbol)
+		}
+	}
+	slices.Sort(symbols)
+
+	for _, symbol := range symbols {
+		b, a := before[symbol], after[symbol]
+		change := PortfolioChange{At: next.TakenAt, Symbol: symbol, Before: b, After: a}
+		switch {
+		case b == nil:
+			change.Kind = ChangeHoldingAdded
+			changes = append(changes, change)
+		case a == nil:
+			change.Kind = ChangeHoldingRemoved
+			changes = append(changes, change)
+		default:
+			if a.TotalUnit != b.TotalUnit {
+				change.Kind = ChangeUnitsChanged
+				changes = append(changes, change)
+			}
+			if a.Price != b.Price {
+				change.Kind = ChangePriceMoved
+				changes = append(changes, change)
+			}
+		}
+	}
+	return changes
+}
+
+// holdingsBySymbol indexes holdings by symbol.
+func holdingsBySymbol(holdings []Holding) map[string]*Holding {
+	bySymbol := make(map[string]*Holding, len(holdings))
+	for i := range holdings {
+		bySymbol[holdings[i].Symbol] = &holdings[i]
+	}
+	return bySymbol
+}
+
+// WatchPortfolio takes a snapshot of the account every interval and sends
+// the changes since the last one to the returned channel, which is closed
+// once ctx is done. The first snapshot, taken straight away, is the baseline
+// and sends nothing. A snapshot that fails is sent as a ChangeError, and the
+// next is compared with the last that succeeded. It panics if interval is
+// not positive.
+//
+// This suits accounts where streaming is not available; see StreamQuotes
+// and StreamOrderUpdates for live updates.
+//
+// Example:
+//
+//	for change := range client.WatchPortfolio(ctx, time.Minute) {
+//		switch change.Kind {
+//		case stockal.ChangePriceMoved:
+//			fmt.Printf("%s: $%.2f -> $%.2f\n", change.Symbol, change.Before.Price, change.After.Price)
+//		case stockal.ChangeError:
+//			log.Print(change.Err)
+//		}
+//	}
+func (c *Client) WatchPortfolio(ctx context.Context, interval time.Duration) <-chan PortfolioChange {
+	if interval <= 0 {
+		panic("stockal: non-positive interval for WatchPortfolio")
+	}
+	ch := make(chan PortfolioChange)
+	go func() {
+		defer close(ch)
+		send := func(changes ...PortfolioChange) bool {
+			for _, change := range changes {
+				select {
+				case ch <- change:
+				case <-ctx.Done():
+					return false
+				}
+			}
+			return true
+		}
+
+		var last *Snapshot
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			snapshot, err := TakeSnapshot(ctx, c)
+			switch {
+			case ctx.Err() != nil:
+				return
+			case err != nil:
+				if !send(PortfolioChange{Kind: ChangeError, At: time.Now().UTC(), Err: err}) {
+					return
+				}
+			case last == nil:
+				last = snapshot
+			default:
+				if !send(DiffSnapshots(last, snapshot)...) {
+					return
+				}
+				last = snapshot
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return ch
+}
@@ -0,0 +1,71 @@
+package stockal_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+)
+
+func TestWatchPortfolio(t *testing.T) {
+	var polls atomic.Int32
+	client := newHandlerClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		poll := polls.Load()
+		switch r.URL.Path {
+		case "/v2/users/accountSummary/summary":
+			poll = polls.Add(1)
+			cash := map[int32]int{1: 100, 2: 50}[poll]
+			fmt.Fprintf(w, `{"code":200,"data":{"utcTime":"2025-03-14T20:00:00Z","accountSummary":{"cashBalance":%d}}}`, cash)
+		case "/v2/users/portfolio/detail":
+			switch poll {
+			case 1:
+				w.Write([]byte(`{"code":200,"data":{"holdings":[{"symbol":"AAPL","totalUnit":10,"price":200},{"symbol":"VOO","totalUnit":2,"price":500}],"totalRecords":2}}`))
+			case 2:
+				w.Write([]byte(`{"code":200,"data":{"holdings":[{"symbol":"AAPL","totalUnit":12,"price":210},{"symbol":"MSFT","totalUnit":1,"price":400}],"totalRecords":2}}`))
+			default:
+				w.WriteHeader(http.StatusBadGateway)
+				w.Write([]byte(`{"code":502,"message":"Bad gateway"}`))
+			}
+		}
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	changes := client.WatchPortfolio(ctx, 10*time.Millisecond)
+
+	var got []string
+	for change := range changes {
+		got = append(got, fmt.Sprintf("%s %s", change.Kind, change.Symbol))
+		if change.Kind == stockal.ChangeError {
+			cancel()
+		}
+	}
+	want := []string{
+		"cash_changed ",
+		"units_changed AAPL",
+		"price_moved AAPL",
+		"holding_added MSFT",
+		"holding_removed VOO",
+		"error ",
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("changes = %q, want %q", got, want)
+	}
+}
+
+func TestDiffSnapshots(t *testing.T) {
+	prev := &stockal.Snapshot{Holdings: []stockal.Holding{{Symbol: "AAPL", TotalUnit: 10, Price: 200}}}
+	next := &stockal.Snapshot{Holdings: []stockal.Holding{{Symbol: "AAPL", TotalUnit: 10, Price: 200}}}
+	if changes := stockal.DiffSnapshots(prev, next); len(changes) != 0 {
+		t.Errorf("DiffSnapshots of equal snapshots = %+v, want none", changes)
+	}
+	next.Holdings[0].Price = 190
+	changes := stockal.DiffSnapshots(prev, next)
+	if len(changes) != 1 || changes[0].Kind != stockal.ChangePriceMoved || changes[0].Before.Price != 200 || changes[0].After.Price != 190 {
+		t.Errorf("DiffSnapshots after a price move = %+v", changes)
+	}
+}
@@ -0,0 +1,233 @@
+package stockal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// WatchlistManager is implemented by clients that can read and change the
+// account's watchlists.
+type WatchlistManager interface {
+	GetWatchlists(ctx context.Context) (*WatchlistsResponse, error)
+	CreateWatchlist(ctx context.Context, name string, symbols ...string) (*WatchlistResponse, error)
+	AddToWatchlist(ctx context.Context, watchlistID string, symbols ...string) (*WatchlistResponse, error)
+	RemoveFromWatchlist(ctx context.Context, watchlistID string, symbols ...string) (*WatchlistResponse, error)
+	DeleteWatchlist(ctx context.Context, watchlistID string) error
+}
+
+// Watchlist represents a named list of symbols the user follows.
+type Watchlist struct {
+	// ID is the watchlist identifier
+	ID string `json:"watchlistID"`
+	// Name is the name the user gave the watchlist
+	Name string `json:"name"`
+	// Items contains the symbols on the watchlist, in the user's order
+	Items []WatchlistItem `json:"items"`
+	// Extra holds the fields the API sent that this struct does not model
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// Symbols returns the symbols on the watchlist, in order.
+func (w Watchlist) Symbols() []string {
+	symbols := make([]string, len(w.Items))
+	for i, item := range w.Items {
+		symbols[i] = item.Symbol
+	}
+	return symbols
+}
+
+// Contains reports whether symbol is on the watchlist, ignoring case.
+func (w Watchlist) Contains(symbol string) bool {
+	return slices.ContainsFunc(w.Items, func(item WatchlistItem) bool {
+		return strings.EqualFold(item.Symbol, strings.TrimSpace(symbol))
+	})
+}
+
+// WatchlistItem represents one symbol on a watchlist.
+type WatchlistItem struct {
+	// Symbol is the stock symbol (e.g., "AAPL")
+	Symbol string `json:"symbol"`
+	// Name is the company or fund name
+	Name string `json:"name,omitempty"`
+	// Extra holds the fields the API sent that this struct does not model
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// WatchlistsResponse represents the response from the watchlist list API.
+type WatchlistsResponse struct {
+	// Code is the HTTP response code
+	Code int `json:"code"`
+	// Message is the response message (usually "Success")
+	Message string `json:"message"`
+	// Data contains the watchlists of the account
+	Data []Watchlist `json:"data"`
+}
+
+func (r *WatchlistsResponse) normalize() {
+	if r.Data == nil {
+		r.Data = []Watchlist{}
+	}
+	for i := range r.Data {
+		r.Data[i].normalize()
+	}
+}
+
+// Watchlist returns the watchlist called name, ignoring case, if present in
+// the response.
+func (r *WatchlistsResponse) Watchlist(name string) (Watchlist, bool) {
+	for _, w := range r.Data {
+		if strings.EqualFold(w.Name, strings.TrimSpace(name)) {
+			return w, true
+		}
+	}
+	return Watchlist{}, false
+}
+
+// WatchlistResponse represents the response from the watchlist APIs that
+// return a single watchlist.
+type WatchlistResponse struct {
+	// Code is the HTTP response code
+	Code int `json:"code"`
+	// Message is the response message (usually "Success")
+	Message string `json:"message"`
+	// Data contains the watchlist as changed
+	Data Watchlist `json:"data"`
+}
+
+func (r *WatchlistResponse) normalize() {
+	r.Data.normalize()
+}
+
+func (r *WatchlistResponse) validate() []string {
+	if r.Data.ID == "" {
+		return []string{"missing watchlist ID"}
+	}
+	return nil
+}
+
+func (w *Watchlist) normalize() {
+	if w.Items == nil {
+		w.Items = []WatchlistItem{}
+	}
+}
+
+// watchlistRequest represents the request payload for creating a watchlist.
+type watchlistRequest struct {
+	// Name is the name of the new watchlist
+	Name string `json:"name"`
+	// Symbols are the symbols to put on it, in order
+	Symbols []string `json:"symbols"`
+}
+
+// watchlistSymbolsRequest represents the request payload for adding symbols
+// to a watchlist.
+type watchlistSymbolsRequest struct {
+	// Symbols are the symbols to add, in order
+	Symbols []string `json:"symbols"`
+}
+
+// watchlistSymbols returns symbols trimmed, upper-cased and without
+// duplicates, in order.
+func watchlistSymbols(symbols []string) []string {
+	cleaned := make([]string, 0, len(symbols))
+	for _, s := range symbols {
+		s = strings.ToUpper(strings.TrimSpace(s))
+		if s != "" && !slices.Contains(cleaned, s) {
+			cleaned = append(cleaned, s)
+		}
+	}
+	return cleaned
+}
+
+// GetWatchlists retrieves the account's watchlists and their symbols.
+//
+// Example:
+//
+//	lists, err := client.GetWatchlists(ctx)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	if w, ok := lists.Watchlist("Tech"); ok {
+//		quotes, err := client.GetQuotes(ctx, w.Symbols()...)
+//		// ...
+//	}
+func (c *Client) GetWatchlists(ctx context.Context) (*WatchlistsResponse, error) {
+	var listResp WatchlistsResponse
+	if err := c.do(ctx, "GET", "/v2/watchlists", nil, &listResp, "list watchlists"); err != nil {
+		return nil, err
+	}
+	return &listResp, nil
+}
+
+// CreateWatchlist creates a watchlist called name holding symbols, which may
+// be none. The response holds the new watchlist and its ID.
+func (c *Client) CreateWatchlist(ctx context.Context, name string, symbols ...string) (*WatchlistResponse, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, fmt.Errorf("%w: a watchlist name is required", ErrInvalidParams)
+	}
+
+	payload := watchlistRequest{Name: name, Symbols: watchlistSymbols(symbols)}
+	var watchlistResp WatchlistResponse
+	if err := c.do(ctx, "POST", "/v2/watchlists", payload, &watchlistResp, "create watchlist"); err != nil {
+		return nil, err
+	}
+	return &watchlistResp, nil
+}
+
+// AddToWatchlist adds symbols to the end of a watchlist. Symbols already on it
+// stay where they are. The response holds the watchlist as changed.
+func (c *Client) AddToWatchlist(ctx context.Context, watchlistID string, symbols ...string) (*WatchlistResponse, error) {
+	symbols = watchlistSymbols(symbols)
+	switch {
+	case watchlistID == "":
+		return nil, fmt.Errorf("%w: a watchlist ID is required", ErrInvalidParams)
+	case len(symbols) == 0:
+		return nil, fmt.Errorf("%w: at least one symbol is required", ErrInvalidParams)
+	}
+
+	payload := watchlistSymbolsRequest{Symbols: symbols}
+	var watchlistResp WatchlistResponse
+	endpoint := "/v2/watchlists/" + url.PathEscape(watchlistID) + "/symbols"
+	if err := c.do(ctx, "POST", endpoint, payload, &watchlistResp, "add to watchlist"); err != nil {
+		return nil, err
+	}
+	return &watchlistResp, nil
+}
+
+// RemoveFromWatchlist removes symbols from a watchlist. Symbols not on it are
+// ignored. The response holds the watchlist as changed.
+func (c *Client) RemoveFromWatchlist(ctx context.Context, watchlistID string, symbols ...string) (*WatchlistResponse, error) {
+	symbols = watchlistSymbols(symbols)
+	switch {
+	case watchlistID == "":
+		return nil, fmt.Errorf("%w: a watchlist ID is required", ErrInvalidParams)
+	case len(symbols) == 0:
+		return nil, fmt.Errorf("%w: at least one symbol is required", ErrInvalidParams)
+	}
+
+	query := url.Values{"symbols": {strings.Join(symbols, ",")}}
+	var watchlistResp WatchlistResponse
+	endpoint := "/v2/watchlists/" + url.PathEscape(watchlistID) + "/symbols?" + query.Encode()
+	if err := c.do(ctx, "DELETE", endpoint, nil, &watchlistResp, "remove from watchlist"); err != nil {
+		return nil, err
+	}
+	return &watchlistResp, nil
+}
+
+// DeleteWatchlist deletes a watchlist. Other watchlists holding the same
+// symbols keep them.
+func (c *Client) DeleteWatchlist(ctx context.Context, watchlistID string) error {
+	if watchlistID == "" {
+		return fmt.Errorf("%w: a watchlist ID is required", ErrInvalidParams)
+	}
+	var deleteResp struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	}
+	return c.do(ctx, "DELETE", "/v2/watchlists/"+url.PathEscape(watchlistID), nil, &deleteResp, "delete watchlist")
+}
@@ -0,0 +1,88 @@
+package stockal_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/adjaecent/unofficial-stockal-api"
+)
+
+func TestWatchlists(t *testing.T) {
+	var requests []string
+	client := newHandlerClient(t, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests = append(requests, r.Method+" "+r.URL.RequestURI()+" "+string(body))
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodGet:
+			w.Write([]byte(`{"code":200,"message":"Success","data":[` +
+				`{"watchlistID":"w1","name":"Tech","items":[{"symbol":"AAPL","name":"Apple Inc","lastPrice":180}]},` +
+				`{"watchlistID":"w2","name":"Empty","items":null}]}`))
+		case r.Method == http.MethodDelete && r.URL.Path == "/v2/watchlists/w1":
+			w.Write([]byte(`{"code":200,"message":"Success"}`))
+		default:
+			w.Write([]byte(`{"code":200,"message":"Success","data":{"watchlistID":"w1","name":"Tech","items":[{"symbol":"AAPL"},{"symbol":"MSFT"}]}}`))
+		}
+	})
+	ctx := context.Background()
+
+	lists, err := client.GetWatchlists(ctx)
+	if err != nil {
+		t.Fatalf("GetWatchlists: %v", err)
+	}
+	tech, ok := lists.Watchlist("tech")
+	if !ok || !slices.Equal(tech.Symbols(), []string{"AAPL"}) || !tech.Contains("aapl") || string(tech.Items[0].Extra["lastPrice"]) != "180" {
+		t.Errorf("Watchlist(tech) = %+v, %v", tech, ok)
+	}
+	if empty, _ := lists.Watchlist("Empty"); empty.Items == nil {
+		t.Errorf("Watchlist(Empty) has nil items")
+	}
+
+	if _, err := client.CreateWatchlist(ctx, " Tech ", "aapl", "msft", "AAPL"); err != nil {
+		t.Fatalf("CreateWatchlist: %v", err)
+	}
+	resp, err := client.AddToWatchlist(ctx, "w1", "msft")
+	if err != nil || !resp.Data.Contains("MSFT") {
+		t.Fatalf("AddToWatchlist = %+v, %v", resp, err)
+	}
+	if _, err := client.RemoveFromWatchlist(ctx, "w1", "tsla", "nvda"); err != nil {
+		t.Fatalf("RemoveFromWatchlist: %v", err)
+	}
+	if err := client.DeleteWatchlist(ctx, "w1"); err != nil {
+		t.Fatalf("DeleteWatchlist: %v", err)
+	}
+	want := []string{
+		"GET /v2/watchlists ",
+		`POST /v2/watchlists {"name":"Tech","symbols":["AAPL","MSFT"]}`,
+		`POST /v2/watchlists/w1/symbols {"symbols":["MSFT"]}`,
+		"DELETE /v2/watchlists/w1/symbols?symbols=TSLA%2CNVDA ",
+		"DELETE /v2/watchlists/w1 ",
+	}
+	if !slices.Equal(requests, want) {
+		t.Errorf("sent %q, want %q", requests, want)
+	}
+
+	requests = nil
+	for name, err := range map[string]error{
+		"CreateWatchlist without a name":    second(client.CreateWatchlist(ctx, " ")),
+		"AddToWatchlist without symbols":    second(client.AddToWatchlist(ctx, "w1", " ")),
+		"RemoveFromWatchlist without an ID": second(client.RemoveFromWatchlist(ctx, "", "AAPL")),
+		"DeleteWatchlist without an ID":     client.DeleteWatchlist(ctx, ""),
+	} {
+		if !errors.Is(err, stockal.ErrInvalidParams) {
+			t.Errorf("%s: error = %v, want ErrInvalidParams", name, err)
+		}
+	}
+	if len(requests) > 0 {
+		t.Errorf("invalid calls sent %q", requests)
+	}
+}
+
+// second returns the error of a call returning a value and an error.
+func second[T any](_ T, err error) error {
+	return err
+}